@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/Kargones/apk-ci/internal/command"
@@ -72,6 +73,7 @@ func run() int {
 
 	logAdapter := logging.NewSlogAdapter(l)
 	metricsCollector := di.ProvideMetricsCollector(cfg, logAdapter)
+	cfg.MetricsCollector = metricsCollector
 
 	// Инициализация alerter для отправки алертов при ошибках
 	alerter := di.ProvideAlerter(cfg, logAdapter)
@@ -132,6 +134,12 @@ func run() int {
 
 	l.Debug("Выполнение команды через registry", slog.String("command", cfg.Command))
 
+	// Shadow-run не поддерживается: legacy-имена — DeprecatedBridge к тем же NR-обработчикам,
+	// отдельной legacy-реализации для сравнения нет (см. README, «Обратная совместимость»).
+	if shadow, _ := strconv.ParseBool(os.Getenv(constants.EnvShadowRun)); shadow {
+		l.Warn("BR_SHADOW_RUN не поддерживается: legacy-команды выполняются NR-обработчиками, команда выполняется без shadow-run")
+	}
+
 	// Выполнение через registry
 	execErr := handler.Execute(ctx, cfg)
 
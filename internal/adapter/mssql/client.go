@@ -38,6 +38,9 @@ type ClientOptions struct {
 	Database string
 	// Timeout — таймаут подключения
 	Timeout time.Duration
+	// RestoreStrategy — способ восстановления: RestoreStrategyProcedure (по умолчанию)
+	// или RestoreStrategyNative
+	RestoreStrategy string
 	// Encrypt — использовать TLS шифрование (по умолчанию true для безопасности)
 	// ВАЖНО: Если Encrypt=false и encryptSet=false, будет использовано значение true по умолчанию.
 	// Для явного отключения шифрования используйте NewClientWithEncrypt(opts, false).
@@ -52,6 +55,8 @@ type ClientOptions struct {
 type client struct {
 	db   *sql.DB
 	opts ClientOptions
+	// openDB — опциональная функция подключения к другому серверу (nil в production, sqlmock в тестах)
+	openDB func(ctx context.Context, server, database string) (*sql.DB, error)
 }
 
 // NewClient создаёт новый MSSQL клиент с указанными параметрами.
@@ -75,6 +80,12 @@ func NewClient(opts ClientOptions) (Client, error) {
 	if opts.Timeout == 0 {
 		opts.Timeout = 30 * time.Second
 	}
+	if opts.RestoreStrategy == "" {
+		opts.RestoreStrategy = RestoreStrategyProcedure
+	}
+	if opts.RestoreStrategy != RestoreStrategyProcedure && opts.RestoreStrategy != RestoreStrategyNative {
+		return nil, fmt.Errorf("%s: unknown restore strategy %q", ErrMSSQLConnect, opts.RestoreStrategy)
+	}
 	// По умолчанию включаем шифрование для безопасности (H3 fix)
 	// Если encryptSet=false, значит Encrypt не был явно задан — используем true
 	if !opts.encryptSet {
@@ -96,6 +107,21 @@ func NewClientWithEncrypt(opts ClientOptions, encrypt bool) (Client, error) {
 
 // Connect устанавливает соединение с сервером MSSQL.
 func (c *client) Connect(ctx context.Context) error {
+	db, err := c.open(ctx, c.opts.Server, c.opts.Database)
+	if err != nil {
+		return err
+	}
+	c.db = db
+	return nil
+}
+
+// open подключается к базе database сервера server с параметрами клиента
+// (порт, учётные данные, таймаут, шифрование) и проверяет соединение.
+func (c *client) open(ctx context.Context, server, database string) (*sql.DB, error) {
+	if c.openDB != nil {
+		return c.openDB(ctx, server, database)
+	}
+
 	// Определяем режим шифрования (H3 fix)
 	encryptMode := "true"
 	if !c.opts.Encrypt {
@@ -106,18 +132,18 @@ func (c *client) Connect(ctx context.Context) error {
 	// Используем url.QueryEscape для безопасного экранирования специальных символов
 	connString := fmt.Sprintf(
 		"server=%s;user id=%s;password=%s;port=%d;database=%s;encrypt=%s;connection timeout=%d",
-		escapeConnStringParam(c.opts.Server),
+		escapeConnStringParam(server),
 		escapeConnStringParam(c.opts.User),
 		escapeConnStringParam(c.opts.Password),
 		c.opts.Port,
-		escapeConnStringParam(c.opts.Database),
+		escapeConnStringParam(database),
 		encryptMode,
 		int(c.opts.Timeout.Seconds()),
 	)
 
 	db, err := sql.Open("sqlserver", connString)
 	if err != nil {
-		return fmt.Errorf("%s: %w", ErrMSSQLConnect, err)
+		return nil, fmt.Errorf("%s: %w", ErrMSSQLConnect, err)
 	}
 
 	// Проверяем подключение
@@ -127,13 +153,12 @@ func (c *client) Connect(ctx context.Context) error {
 			// best-effort close; original error is more important
 		}
 		if ctx.Err() != nil {
-			return fmt.Errorf("%s: context cancelled during ping: %w", ErrMSSQLConnect, ctx.Err())
+			return nil, fmt.Errorf("%s: context cancelled during ping: %w", ErrMSSQLConnect, ctx.Err())
 		}
-		return fmt.Errorf("%s: ping failed: %w", ErrMSSQLConnect, err)
+		return nil, fmt.Errorf("%s: ping failed: %w", ErrMSSQLConnect, err)
 	}
 
-	c.db = db
-	return nil
+	return db, nil
 }
 
 // escapeConnStringParam экранирует параметр для безопасного использования в connection string.
@@ -166,7 +191,8 @@ func (c *client) Ping(ctx context.Context) error {
 }
 
 // Restore выполняет восстановление базы данных из резервной копии
-// через хранимую процедуру sp_DBRestorePSFromHistoryD.
+// через хранимую процедуру sp_DBRestorePSFromHistoryD или, при
+// RestoreStrategyNative, цепочкой RESTORE по истории msdb (restoreNative).
 func (c *client) Restore(ctx context.Context, opts RestoreOptions) error {
 	if c.db == nil {
 		return fmt.Errorf("%s: connection not established", ErrMSSQLRestore)
@@ -180,6 +206,16 @@ func (c *client) Restore(ctx context.Context, opts RestoreOptions) error {
 		defer cancel()
 	}
 
+	if c.opts.RestoreStrategy == RestoreStrategyNative {
+		if err := c.restoreNative(execCtx, opts); err != nil {
+			if execCtx.Err() == context.DeadlineExceeded {
+				return fmt.Errorf("%s: operation timed out after %v", ErrMSSQLTimeout, opts.Timeout)
+			}
+			return err
+		}
+		return nil
+	}
+
 	query := `
 	USE master;
 	EXEC sp_DBRestorePSFromHistoryD
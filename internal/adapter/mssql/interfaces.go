@@ -2,7 +2,9 @@
 // Пакет предоставляет абстракцию над MSSQL операциями, разделённую по принципу ISP
 // (Interface Segregation Principle) на сфокусированные интерфейсы:
 // DatabaseConnector, DatabaseRestorer, BackupInfoProvider.
-// Композитный интерфейс Client объединяет все вышеперечисленные.
+// Композитный интерфейс Client объединяет все вышеперечисленные;
+// необязательные RestoreProgressProvider, RestoreHistoryProvider, DataSanitizer
+// и SnapshotManager проверяются через type assertion.
 package mssql
 
 import (
@@ -20,6 +22,18 @@ const (
 	ErrMSSQLQuery = "MSSQL.QUERY_FAILED"
 	// ErrMSSQLTimeout — превышено время ожидания операции
 	ErrMSSQLTimeout = "MSSQL.TIMEOUT"
+	// ErrMSSQLSnapshot — ошибка операции со снимком базы данных
+	ErrMSSQLSnapshot = "MSSQL.SNAPSHOT_FAILED"
+)
+
+// Способы восстановления базы данных (ClientOptions.RestoreStrategy).
+const (
+	// RestoreStrategyProcedure — хранимая процедура sp_DBRestorePSFromHistoryD,
+	// установленная на целевом сервере (по умолчанию)
+	RestoreStrategyProcedure = "procedure"
+	// RestoreStrategyNative — цепочка RESTORE DATABASE/LOG, построенная по истории
+	// резервного копирования msdb сервера-источника
+	RestoreStrategyNative = "native"
 )
 
 // RestoreOptions содержит параметры для восстановления базы данных.
@@ -62,6 +76,142 @@ type RestoreStats struct {
 	HasData bool
 }
 
+// RestoreProgress содержит ход выполняющейся команды RESTORE (sys.dm_exec_requests).
+type RestoreProgress struct {
+	// Command — выполняемая команда (RESTORE DATABASE, RESTORE LOG)
+	Command string
+	// PercentComplete — выполнено, % (0–100)
+	PercentComplete float64
+	// EstimatedRemaining — оценка оставшегося времени по данным сервера (0 — неизвестно)
+	EstimatedRemaining time.Duration
+	// Elapsed — время выполнения команды
+	Elapsed time.Duration
+	// WaitType — текущее ожидание запроса (пусто — выполняется)
+	WaitType string
+}
+
+// RestoreHistoryOptions содержит фильтры журнала запросов на восстановление.
+type RestoreHistoryOptions struct {
+	// DstDB — целевая база данных (пусто — все)
+	DstDB string
+	// User — подстрока имени пользователя, запросившего восстановление (пусто — все)
+	User string
+	// From — начало периода по времени запроса (нулевое — без ограничения).
+	// Сравнивается с временем сервера по часам значения (без учёта смещения)
+	From time.Time
+	// To — окончание периода, не включая (нулевое — без ограничения)
+	To time.Time
+	// Limit — максимальное число записей (0 — по умолчанию)
+	Limit int
+}
+
+// RestoreRecord — запись журнала запросов на восстановление (BackupRequestJournal).
+type RestoreRecord struct {
+	// RequestedAt — время запроса (время сервера)
+	RequestedAt time.Time
+	// CompletedAt — время завершения (нулевое — восстановление не завершено)
+	CompletedAt time.Time
+	// User — пользователь, запросивший восстановление
+	User string
+	// Description — описание операции
+	Description string
+	// SrcServer — сервер-источник
+	SrcServer string
+	// SrcDB — база-источник
+	SrcDB string
+	// DstServer — целевой сервер
+	DstServer string
+	// DstDB — целевая база
+	DstDB string
+	// RestorePoint — запрошенный момент восстановления (пусто — последнее состояние)
+	RestorePoint string
+}
+
+// LastRestore описывает последнее восстановление базы по msdb.dbo.restorehistory.
+type LastRestore struct {
+	// Database — восстановленная база
+	Database string
+	// RestoredAt — время последнего RESTORE (время сервера)
+	RestoredAt time.Time
+	// DataAsOf — момент, на который восстановлены данные: STOPAT или
+	// время завершения последней применённой копии (время сервера-источника)
+	DataAsOf time.Time
+	// SrcServer — сервер, на котором создана резервная копия
+	SrcServer string
+	// SrcDB — база, из копии которой выполнено восстановление
+	SrcDB string
+}
+
+// Действия обезличивания (SanitizeAction.Action).
+const (
+	// SanitizeActionMask — замена значений реквизита
+	SanitizeActionMask = "mask"
+	// SanitizeActionExchangeQueue — очистка очереди изменений плана обмена
+	SanitizeActionExchangeQueue = "exchange_queue"
+	// SanitizeActionScheduledJobs — отключение регламентных заданий
+	SanitizeActionScheduledJobs = "scheduled_jobs"
+	// SanitizeActionSQL — произвольная инструкция T-SQL
+	SanitizeActionSQL = "sql"
+)
+
+// SanitizeOptions содержит профили обезличивания восстановленной базы 1С.
+type SanitizeOptions struct {
+	// Database — восстановленная база данных
+	Database string
+	// Profiles — профили в порядке применения
+	Profiles []SanitizeProfile
+}
+
+// SanitizeProfile — набор операций обезличивания.
+type SanitizeProfile struct {
+	// Name — имя профиля
+	Name string
+	// Masks — маскируемые реквизиты
+	Masks []SanitizeMask
+	// ExchangePlans — планы обмена ("ПланОбмена.Имя"), очереди которых очищаются; "*" — все
+	ExchangePlans []string
+	// DisableScheduledJobs — отключить использование регламентных заданий
+	DisableScheduledJobs bool
+	// SQL — инструкции T-SQL, выполняемые в контексте базы
+	SQL []string
+}
+
+// SanitizeMask описывает маскирование реквизита объекта 1С.
+type SanitizeMask struct {
+	// Object — объект метаданных ("Справочник.Контрагенты")
+	Object string
+	// Attribute — реквизит или "ТабличнаяЧасть.Реквизит"
+	Attribute string
+	// Value — записываемое значение
+	Value string
+}
+
+// SanitizeAction описывает выполненное действие обезличивания.
+type SanitizeAction struct {
+	// Profile — профиль, к которому относится действие
+	Profile string
+	// Action — вид действия (SanitizeAction*)
+	Action string
+	// Target — объект действия: реквизит, план обмена, инструкция
+	Target string
+	// Table — изменённая таблица (для нескольких таблиц — через запятую)
+	Table string
+	// Column — изменённая колонка
+	Column string
+	// Rows — число изменённых или удалённых строк
+	Rows int64
+}
+
+// DatabaseSnapshot описывает моментальный снимок базы данных (database snapshot).
+type DatabaseSnapshot struct {
+	// Name — имя снимка
+	Name string
+	// Database — база-источник снимка
+	Database string
+	// CreatedAt — время создания снимка
+	CreatedAt time.Time
+}
+
 // BackupInfo содержит информацию о резервной копии.
 // M-1 note: Структура определена для будущего расширения интерфейса.
 // В текущей версии интерфейс BackupInfoProvider.GetBackupSize возвращает только int64.
@@ -99,6 +249,47 @@ type BackupInfoProvider interface {
 	GetBackupSize(ctx context.Context, database string) (int64, error)
 }
 
+// RestoreProgressProvider предоставляет ход восстановления базы данных.
+// Не входит в Client (проверяется через type assertion); опрашивается
+// через отдельное подключение, пока основное выполняет Restore.
+type RestoreProgressProvider interface {
+	// GetRestoreProgress возвращает ход RESTORE в базу database (nil — RESTORE не выполняется).
+	GetRestoreProgress(ctx context.Context, database string) (*RestoreProgress, error)
+}
+
+// RestoreHistoryProvider предоставляет историю восстановлений баз данных.
+// Не входит в Client (проверяется через type assertion).
+type RestoreHistoryProvider interface {
+	// GetRestoreHistory возвращает записи журнала запросов на восстановление, новые первыми.
+	GetRestoreHistory(ctx context.Context, opts RestoreHistoryOptions) ([]RestoreRecord, error)
+	// GetLastRestore возвращает последнее восстановление базы database (nil — не восстанавливалась).
+	GetLastRestore(ctx context.Context, database string) (*LastRestore, error)
+}
+
+// DataSanitizer обезличивает данные восстановленной базы 1С.
+// Не входит в Client (проверяется через type assertion).
+type DataSanitizer interface {
+	// Sanitize применяет профили к базе opts.Database.
+	// При ошибке возвращает также действия, выполненные до неё.
+	Sanitize(ctx context.Context, opts SanitizeOptions) ([]SanitizeAction, error)
+}
+
+// SnapshotManager управляет моментальными снимками баз данных SQL Server.
+// Не входит в Client (проверяется через type assertion).
+type SnapshotManager interface {
+	// CreateSnapshot создаёт снимок snapshot базы database; разреженные файлы
+	// снимка размещаются рядом с файлами данных базы.
+	CreateSnapshot(ctx context.Context, database, snapshot string) error
+	// RevertSnapshot возвращает базу database к состоянию снимка snapshot.
+	// Другие снимки базы должны быть удалены заранее.
+	RevertSnapshot(ctx context.Context, database, snapshot string) error
+	// DropSnapshot удаляет снимок snapshot. Отсутствующий снимок — не ошибка;
+	// обычная база данных с таким именем не удаляется.
+	DropSnapshot(ctx context.Context, snapshot string) error
+	// ListSnapshots возвращает снимки базы database в порядке создания.
+	ListSnapshots(ctx context.Context, database string) ([]DatabaseSnapshot, error)
+}
+
 // Client — композитный интерфейс, объединяющий все операции MSSQL.
 type Client interface {
 	DatabaseConnector
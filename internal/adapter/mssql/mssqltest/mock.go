@@ -10,10 +10,14 @@ import (
 
 // Compile-time проверки реализации интерфейсов
 var (
-	_ mssql.Client             = (*MockMSSQLClient)(nil)
-	_ mssql.DatabaseConnector  = (*MockMSSQLClient)(nil)
-	_ mssql.DatabaseRestorer   = (*MockMSSQLClient)(nil)
-	_ mssql.BackupInfoProvider = (*MockMSSQLClient)(nil)
+	_ mssql.Client                  = (*MockMSSQLClient)(nil)
+	_ mssql.DatabaseConnector       = (*MockMSSQLClient)(nil)
+	_ mssql.DatabaseRestorer        = (*MockMSSQLClient)(nil)
+	_ mssql.BackupInfoProvider      = (*MockMSSQLClient)(nil)
+	_ mssql.RestoreProgressProvider = (*MockMSSQLClient)(nil)
+	_ mssql.RestoreHistoryProvider  = (*MockMSSQLClient)(nil)
+	_ mssql.DataSanitizer           = (*MockMSSQLClient)(nil)
+	_ mssql.SnapshotManager         = (*MockMSSQLClient)(nil)
 )
 
 // MockMSSQLClient — мок-реализация mssql.Client для тестирования.
@@ -31,6 +35,22 @@ type MockMSSQLClient struct {
 	GetRestoreStatsFunc func(ctx context.Context, opts mssql.StatsOptions) (*mssql.RestoreStats, error)
 	// GetBackupSizeFunc — пользовательская реализация GetBackupSize
 	GetBackupSizeFunc func(ctx context.Context, database string) (int64, error)
+	// GetRestoreProgressFunc — пользовательская реализация GetRestoreProgress
+	GetRestoreProgressFunc func(ctx context.Context, database string) (*mssql.RestoreProgress, error)
+	// GetRestoreHistoryFunc — пользовательская реализация GetRestoreHistory
+	GetRestoreHistoryFunc func(ctx context.Context, opts mssql.RestoreHistoryOptions) ([]mssql.RestoreRecord, error)
+	// GetLastRestoreFunc — пользовательская реализация GetLastRestore
+	GetLastRestoreFunc func(ctx context.Context, database string) (*mssql.LastRestore, error)
+	// SanitizeFunc — пользовательская реализация Sanitize
+	SanitizeFunc func(ctx context.Context, opts mssql.SanitizeOptions) ([]mssql.SanitizeAction, error)
+	// CreateSnapshotFunc — пользовательская реализация CreateSnapshot
+	CreateSnapshotFunc func(ctx context.Context, database, snapshot string) error
+	// RevertSnapshotFunc — пользовательская реализация RevertSnapshot
+	RevertSnapshotFunc func(ctx context.Context, database, snapshot string) error
+	// DropSnapshotFunc — пользовательская реализация DropSnapshot
+	DropSnapshotFunc func(ctx context.Context, snapshot string) error
+	// ListSnapshotsFunc — пользовательская реализация ListSnapshots
+	ListSnapshotsFunc func(ctx context.Context, database string) ([]mssql.DatabaseSnapshot, error)
 }
 
 // Connect устанавливает соединение с сервером MSSQL.
@@ -93,6 +113,78 @@ func (m *MockMSSQLClient) GetBackupSize(ctx context.Context, database string) (i
 	return 500 * 1024 * 1024, nil
 }
 
+// GetRestoreProgress возвращает ход выполняющегося RESTORE.
+// При отсутствии пользовательской функции возвращает nil (RESTORE не выполняется).
+func (m *MockMSSQLClient) GetRestoreProgress(ctx context.Context, database string) (*mssql.RestoreProgress, error) {
+	if m.GetRestoreProgressFunc != nil {
+		return m.GetRestoreProgressFunc(ctx, database)
+	}
+	return nil, nil
+}
+
+// GetRestoreHistory возвращает записи журнала восстановлений.
+// При отсутствии пользовательской функции возвращает nil (журнал пуст).
+func (m *MockMSSQLClient) GetRestoreHistory(ctx context.Context, opts mssql.RestoreHistoryOptions) ([]mssql.RestoreRecord, error) {
+	if m.GetRestoreHistoryFunc != nil {
+		return m.GetRestoreHistoryFunc(ctx, opts)
+	}
+	return nil, nil
+}
+
+// GetLastRestore возвращает последнее восстановление базы.
+// При отсутствии пользовательской функции возвращает nil (база не восстанавливалась).
+func (m *MockMSSQLClient) GetLastRestore(ctx context.Context, database string) (*mssql.LastRestore, error) {
+	if m.GetLastRestoreFunc != nil {
+		return m.GetLastRestoreFunc(ctx, database)
+	}
+	return nil, nil
+}
+
+// Sanitize обезличивает данные восстановленной базы.
+// При отсутствии пользовательской функции возвращает nil (нет выполненных действий).
+func (m *MockMSSQLClient) Sanitize(ctx context.Context, opts mssql.SanitizeOptions) ([]mssql.SanitizeAction, error) {
+	if m.SanitizeFunc != nil {
+		return m.SanitizeFunc(ctx, opts)
+	}
+	return nil, nil
+}
+
+// CreateSnapshot создаёт снимок базы данных.
+// При отсутствии пользовательской функции возвращает nil.
+func (m *MockMSSQLClient) CreateSnapshot(ctx context.Context, database, snapshot string) error {
+	if m.CreateSnapshotFunc != nil {
+		return m.CreateSnapshotFunc(ctx, database, snapshot)
+	}
+	return nil
+}
+
+// RevertSnapshot возвращает базу к состоянию снимка.
+// При отсутствии пользовательской функции возвращает nil.
+func (m *MockMSSQLClient) RevertSnapshot(ctx context.Context, database, snapshot string) error {
+	if m.RevertSnapshotFunc != nil {
+		return m.RevertSnapshotFunc(ctx, database, snapshot)
+	}
+	return nil
+}
+
+// DropSnapshot удаляет снимок базы данных.
+// При отсутствии пользовательской функции возвращает nil.
+func (m *MockMSSQLClient) DropSnapshot(ctx context.Context, snapshot string) error {
+	if m.DropSnapshotFunc != nil {
+		return m.DropSnapshotFunc(ctx, snapshot)
+	}
+	return nil
+}
+
+// ListSnapshots возвращает снимки базы данных.
+// При отсутствии пользовательской функции возвращает nil (снимков нет).
+func (m *MockMSSQLClient) ListSnapshots(ctx context.Context, database string) ([]mssql.DatabaseSnapshot, error) {
+	if m.ListSnapshotsFunc != nil {
+		return m.ListSnapshotsFunc(ctx, database)
+	}
+	return nil, nil
+}
+
 // NewMockMSSQLClient создаёт MockMSSQLClient с дефолтными значениями.
 func NewMockMSSQLClient() *MockMSSQLClient {
 	return &MockMSSQLClient{}
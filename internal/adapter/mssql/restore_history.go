@@ -0,0 +1,136 @@
+package mssql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Compile-time проверка реализации интерфейса
+var _ RestoreHistoryProvider = (*client)(nil)
+
+// DefaultRestoreHistoryLimit — число записей журнала по умолчанию.
+const DefaultRestoreHistoryLimit = 50
+
+// historyTimeLayout — формат границ периода (стиль 126 CONVERT, не зависит от DATEFORMAT).
+const historyTimeLayout = "2006-01-02T15:04:05"
+
+// lastRestoreQuery выбирает последнюю команду RESTORE в базу @p1. При восстановлении
+// цепочкой (полная, разностная, журналы) последней является копия журнала,
+// поэтому момент данных — её STOPAT или время завершения.
+const lastRestoreQuery = `
+	SELECT TOP 1
+		rh.restore_date,
+		ISNULL(rh.stop_at, bs.backup_finish_date),
+		ISNULL(bs.server_name, ''),
+		ISNULL(bs.database_name, '')
+	FROM msdb.dbo.restorehistory rh
+	JOIN msdb.dbo.backupset bs ON bs.backup_set_id = rh.backup_set_id
+	WHERE rh.destination_database_name = @p1
+	ORDER BY rh.restore_date DESC, rh.restore_history_id DESC;
+	`
+
+// GetRestoreHistory читает журнал запросов на восстановление [DBA].[dbo].[BackupRequestJournal],
+// который ведёт процедура sp_DBRestorePSFromHistoryD; колонки журнала соответствуют
+// её параметрам. Восстановления RestoreStrategyNative в журнал не записываются.
+func (c *client) GetRestoreHistory(ctx context.Context, opts RestoreHistoryOptions) ([]RestoreRecord, error) {
+	if c.db == nil {
+		return nil, fmt.Errorf("%s: connection not established", ErrMSSQLQuery)
+	}
+
+	var conds []string
+	var args []any
+	add := func(cond string, value any) {
+		args = append(args, value)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if opts.DstDB != "" {
+		add("DstDB = @p%d", opts.DstDB)
+	}
+	if opts.User != "" {
+		add("CHARINDEX(@p%d, DomainUser) > 0", opts.User)
+	}
+	// RequestDate хранит время сервера без смещения: границы передаются строкой
+	// ISO 8601 в часовом поясе значения, без преобразования драйвером в datetimeoffset
+	if !opts.From.IsZero() {
+		add("RequestDate >= @p%d", opts.From.Format(historyTimeLayout))
+	}
+	if !opts.To.IsZero() {
+		add("RequestDate < @p%d", opts.To.Format(historyTimeLayout))
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultRestoreHistoryLimit
+	}
+	args = append(args, limit)
+
+	where := ""
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ")
+	}
+	// dbaDatabase — compile-time константа (см. SECURITY NOTE в client.go)
+	query := fmt.Sprintf(`
+	SELECT TOP (@p%d)
+		RequestDate,
+		CompliteTime,
+		ISNULL(DomainUser, ''),
+		ISNULL(Description, ''),
+		ISNULL(SrcServer, ''),
+		ISNULL(SrcDB, ''),
+		ISNULL(DstServer, ''),
+		ISNULL(DstDB, ''),
+		ISNULL(CONVERT(nvarchar(50), DayToRestore, 120), '')
+	FROM [%s].[dbo].[BackupRequestJournal]
+	%s
+	ORDER BY RequestDate DESC;
+	`, len(args), dbaDatabase, where)
+
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrMSSQLQuery, err)
+	}
+	defer rows.Close()
+
+	var records []RestoreRecord
+	for rows.Next() {
+		var r RestoreRecord
+		var completed sql.NullTime
+		if err := rows.Scan(&r.RequestedAt, &completed, &r.User, &r.Description,
+			&r.SrcServer, &r.SrcDB, &r.DstServer, &r.DstDB, &r.RestorePoint); err != nil {
+			return nil, fmt.Errorf("%s: %w", ErrMSSQLQuery, err)
+		}
+		if completed.Valid {
+			r.CompletedAt = completed.Time
+		}
+		records = append(records, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrMSSQLQuery, err)
+	}
+	return records, nil
+}
+
+// GetLastRestore возвращает последнее восстановление базы database по msdb.dbo.restorehistory:
+// история SQL Server учитывает восстановления любым способом.
+func (c *client) GetLastRestore(ctx context.Context, database string) (*LastRestore, error) {
+	if c.db == nil {
+		return nil, fmt.Errorf("%s: connection not established", ErrMSSQLQuery)
+	}
+
+	last := &LastRestore{Database: database}
+	var dataAsOf sql.NullTime
+	err := c.db.QueryRowContext(ctx, lastRestoreQuery, database).
+		Scan(&last.RestoredAt, &dataAsOf, &last.SrcServer, &last.SrcDB)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%s: %w", ErrMSSQLQuery, err)
+	}
+	if dataAsOf.Valid {
+		last.DataAsOf = dataAsOf.Time
+	}
+	return last, nil
+}
@@ -0,0 +1,148 @@
+package mssql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var journalColumns = []string{"RequestDate", "CompliteTime", "DomainUser", "Description",
+	"SrcServer", "SrcDB", "DstServer", "DstDB", "DayToRestore"}
+
+// TestClient_GetRestoreHistory проверяет фильтры и чтение журнала BackupRequestJournal
+func TestClient_GetRestoreHistory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка создания sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	requested := time.Date(2026, 10, 15, 3, 0, 0, 0, time.UTC)
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows(journalColumns).
+		AddRow(requested, requested.Add(2*time.Hour), `CORP\ivanov`, "nightly", "srv-prod", "ERP", "srv-test", "ERP_TEST", "2026-10-14 23:00:00").
+		AddRow(requested.Add(-24*time.Hour), nil, `CORP\ivanov`, "", "srv-prod", "ERP", "srv-test", "ERP_TEST", "")
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT TOP (@p4)")+
+		"(?s).*"+regexp.QuoteMeta("FROM [DBA].[dbo].[BackupRequestJournal]")+
+		"(?s).*"+regexp.QuoteMeta("WHERE DstDB = @p1 AND CHARINDEX(@p2, DomainUser) > 0 AND RequestDate >= @p3")).
+		WithArgs("ERP_TEST", "ivanov", "2026-10-01T00:00:00", 10).
+		WillReturnRows(rows)
+
+	cli := &client{db: db}
+	got, err := cli.GetRestoreHistory(context.Background(), RestoreHistoryOptions{
+		DstDB: "ERP_TEST", User: "ivanov", From: from, Limit: 10,
+	})
+	if err != nil {
+		t.Fatalf("GetRestoreHistory() error = %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("GetRestoreHistory() вернул %d записей, ожидалось 2", len(got))
+	}
+	if !got[0].CompletedAt.Equal(requested.Add(2*time.Hour)) || got[0].RestorePoint != "2026-10-14 23:00:00" {
+		t.Errorf("запись 0 = %+v", got[0])
+	}
+	if !got[1].CompletedAt.IsZero() {
+		t.Errorf("незавершённая запись: CompletedAt = %v", got[1].CompletedAt)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("невыполненные ожидания: %v", err)
+	}
+}
+
+// TestClient_GetRestoreHistory_DefaultLimit проверяет запрос без фильтров
+func TestClient_GetRestoreHistory_DefaultLimit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка создания sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT TOP (@p1)")).
+		WithArgs(DefaultRestoreHistoryLimit).
+		WillReturnRows(sqlmock.NewRows(journalColumns))
+
+	cli := &client{db: db}
+	got, err := cli.GetRestoreHistory(context.Background(), RestoreHistoryOptions{})
+	if err != nil || len(got) != 0 {
+		t.Fatalf("GetRestoreHistory() = %v, %v", got, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("невыполненные ожидания: %v", err)
+	}
+
+	if _, err := (&client{}).GetRestoreHistory(context.Background(), RestoreHistoryOptions{}); err == nil {
+		t.Error("GetRestoreHistory() без соединения должен вернуть ошибку")
+	}
+}
+
+// TestClient_GetLastRestore проверяет чтение последнего восстановления из msdb
+func TestClient_GetLastRestore(t *testing.T) {
+	restored := time.Date(2026, 10, 15, 5, 0, 0, 0, time.UTC)
+	dataAsOf := time.Date(2026, 10, 14, 23, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		setupMock func(mock sqlmock.Sqlmock)
+		want      *LastRestore
+		wantErr   bool
+	}{
+		{
+			name: "база восстанавливалась",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("FROM msdb.dbo.restorehistory").
+					WithArgs("ERP_TEST").
+					WillReturnRows(sqlmock.NewRows([]string{"restore_date", "data_as_of", "server_name", "database_name"}).
+						AddRow(restored, dataAsOf, "srv-prod", "ERP"))
+			},
+			want: &LastRestore{Database: "ERP_TEST", RestoredAt: restored, DataAsOf: dataAsOf, SrcServer: "srv-prod", SrcDB: "ERP"},
+		},
+		{
+			name: "база не восстанавливалась",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("FROM msdb.dbo.restorehistory").
+					WithArgs("ERP_TEST").
+					WillReturnError(sql.ErrNoRows)
+			},
+		},
+		{
+			name: "ошибка запроса",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("FROM msdb.dbo.restorehistory").
+					WithArgs("ERP_TEST").
+					WillReturnError(errors.New("permission denied"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("ошибка создания sqlmock: %v", err)
+			}
+			defer db.Close()
+			tt.setupMock(mock)
+
+			cli := &client{db: db}
+			got, err := cli.GetLastRestore(context.Background(), "ERP_TEST")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetLastRestore() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("GetLastRestore() = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil || *got != *tt.want {
+				t.Errorf("GetLastRestore() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,525 @@
+package mssql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// restoreTimeLayout — формат RestoreOptions.TimeToRestore и параметров STOPAT.
+const restoreTimeLayout = "2006-01-02T15:04:05"
+
+// restoreTimeMax — момент восстановления, если TimeToRestore не задан (последнее состояние).
+const restoreTimeMax = "9999-12-31T23:59:59"
+
+// backupHistoryQuery выбирает резервные копии базы @p1 на сервере-источнике,
+// начиная с последней полной копии, завершённой не позднее @p2.
+// Учитываются только собственные (server_name = @@SERVERNAME), не copy-only
+// копии на диске; одна строка — одно устройство (семейство) набора.
+const backupHistoryQuery = `
+	SELECT
+		bs.backup_set_id,
+		bs.type,
+		bs.position,
+		CAST(bs.first_lsn AS varchar(32)),
+		CAST(bs.last_lsn AS varchar(32)),
+		CAST(bs.checkpoint_lsn AS varchar(32)),
+		CAST(ISNULL(bs.database_backup_lsn, 0) AS varchar(32)),
+		bs.backup_finish_date,
+		bmf.physical_device_name
+	FROM msdb.dbo.backupset bs
+	JOIN msdb.dbo.backupmediafamily bmf ON bmf.media_set_id = bs.media_set_id
+	WHERE bs.database_name = @p1
+		AND bs.server_name = @@SERVERNAME
+		AND bs.is_copy_only = 0
+		AND bs.type IN ('D', 'I', 'L')
+		AND bmf.device_type = 2
+		AND bmf.mirror = 0
+		AND bs.backup_finish_date >= ISNULL((
+			SELECT MAX(f.backup_finish_date)
+			FROM msdb.dbo.backupset f
+			WHERE f.database_name = @p1
+				AND f.server_name = @@SERVERNAME
+				AND f.is_copy_only = 0
+				AND f.type = 'D'
+				AND f.backup_finish_date <= @p2
+		), '19000101')
+	ORDER BY bs.backup_set_id, bmf.family_sequence_number;
+	`
+
+// backupFilesQuery выбирает файлы базы данных, входящие в полную резервную копию @p1.
+const backupFilesQuery = `
+	SELECT bf.logical_name, bf.physical_name, bf.file_type
+	FROM msdb.dbo.backupfile bf
+	WHERE bf.backup_set_id = @p1
+		AND bf.is_present = 1
+	ORDER BY bf.file_number;
+	`
+
+// targetStateQuery возвращает состояние целевой базы (нет строк — база отсутствует).
+const targetStateQuery = `SELECT state_desc FROM sys.databases WHERE name = @p1;`
+
+// targetFilesQuery возвращает файлы существующей целевой базы.
+const targetFilesQuery = `
+	SELECT mf.type, mf.physical_name
+	FROM sys.master_files mf
+	WHERE mf.database_id = DB_ID(@p1)
+	ORDER BY mf.file_id;
+	`
+
+// singleUserRestoreBatch переводит базу %[1]s в SINGLE_USER и выполняет первую
+// команду RESTORE (%[2]s) тем же пакетом; если RESTORE не выполнен и база осталась
+// в сети, она возвращается в MULTI_USER.
+const singleUserRestoreBatch = `ALTER DATABASE %[1]s SET SINGLE_USER WITH ROLLBACK IMMEDIATE;
+BEGIN TRY
+	%[2]s
+END TRY
+BEGIN CATCH
+	IF DATABASEPROPERTYEX(%[3]s, 'Status') = 'ONLINE' ALTER DATABASE %[1]s SET MULTI_USER;
+	THROW;
+END CATCH;`
+
+// multiUserResetBatch возвращает базу %[1]s в MULTI_USER, если она осталась в сети
+// (после частично выполненного RESTORE база находится в состоянии RESTORING).
+const multiUserResetBatch = `BEGIN TRY
+	IF DATABASEPROPERTYEX(%[2]s, 'Status') = 'ONLINE' ALTER DATABASE %[1]s SET MULTI_USER;
+END TRY
+BEGIN CATCH
+END CATCH;`
+
+// defaultPathsQuery возвращает каталоги данных и журналов экземпляра по умолчанию.
+const defaultPathsQuery = `
+	SELECT
+		CAST(SERVERPROPERTY('InstanceDefaultDataPath') AS nvarchar(260)),
+		CAST(SERVERPROPERTY('InstanceDefaultLogPath') AS nvarchar(260));
+	`
+
+// backupSet — набор резервной копии из истории msdb.
+type backupSet struct {
+	id int64
+	// kind — тип: D — полная, I — разностная, L — журнал транзакций
+	kind              string
+	position          int
+	firstLSN          *big.Int
+	lastLSN           *big.Int
+	checkpointLSN     *big.Int
+	databaseBackupLSN *big.Int
+	finishedAt        time.Time
+	// devices — файлы резервной копии (несколько при записи с чередованием)
+	devices []string
+}
+
+// backupFile — файл базы данных в резервной копии.
+type backupFile struct {
+	logicalName  string
+	physicalName string
+	// fileType — D — данные, L — журнал, F — полнотекстовый каталог, S — FILESTREAM
+	fileType string
+}
+
+// targetFile — файл существующей целевой базы (sys.master_files).
+type targetFile struct {
+	isLog        bool
+	physicalName string
+}
+
+// fileMove — перенос логического файла резервной копии (RESTORE ... WITH MOVE).
+type fileMove struct {
+	logicalName string
+	target      string
+}
+
+// restoreStatement — команда плана восстановления с параметрами.
+type restoreStatement struct {
+	query string
+	args  []any
+}
+
+// restoreChain — цепочка резервных копий для восстановления на момент времени.
+type restoreChain struct {
+	full *backupSet
+	diff *backupSet
+	logs []*backupSet
+	// stopAt — момент восстановления (STOPAT) для последней копии журнала; пусто — до конца журнала
+	stopAt string
+}
+
+// restoreNative восстанавливает базу без серверных процедур: строит цепочку
+// полная → разностная → журналы по истории msdb сервера-источника, переносит
+// логические файлы на целевом сервере (MOVE) и выполняет RESTORE ... NORECOVERY
+// с остановкой на TimeToRestore (STOPAT). Файлы резервных копий должны быть
+// доступны целевому серверу по путям, записанным в msdb (как правило, сетевой каталог).
+// Все команды на целевом сервере выполняются в одном соединении: SINGLE_USER
+// закрепляет базу за соединением, которое её перевело.
+func (c *client) restoreNative(ctx context.Context, opts RestoreOptions) error {
+	if opts.SrcDB == "" || opts.DstDB == "" {
+		return fmt.Errorf("%s: source and destination databases are required", ErrMSSQLRestore)
+	}
+
+	stopAt := restoreTimeMax
+	var stopTime *time.Time
+	if opts.TimeToRestore != "" {
+		t, err := time.Parse(restoreTimeLayout, opts.TimeToRestore)
+		if err != nil {
+			return fmt.Errorf("%s: invalid time to restore %q: %w", ErrMSSQLRestore, opts.TimeToRestore, err)
+		}
+		stopAt = opts.TimeToRestore
+		stopTime = &t
+	}
+
+	source, closeSource, err := c.sourceDB(ctx, opts.SrcServer)
+	if err != nil {
+		return err
+	}
+	defer closeSource()
+
+	sets, err := queryBackupHistory(ctx, source, opts.SrcDB, stopAt)
+	if err != nil {
+		return err
+	}
+	chain, err := buildRestoreChain(sets, stopTime)
+	if err != nil {
+		return fmt.Errorf("%s: %s on %s: %w", ErrMSSQLRestore, opts.SrcDB, opts.SrcServer, err)
+	}
+	files, err := queryBackupFiles(ctx, source, chain.full.id)
+	if err != nil {
+		return err
+	}
+
+	conn, err := c.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrMSSQLConnect, err)
+	}
+	defer conn.Close() //nolint:errcheck // соединение возвращается в пул
+
+	state, existing, dataDir, logDir, err := queryTarget(ctx, conn, opts.DstDB)
+	if err != nil {
+		return err
+	}
+	moves := relocateFiles(opts.DstDB, files, existing, dataDir, logDir)
+
+	statements := chain.statements(opts.DstDB, moves)
+	singleUser := strings.EqualFold(state, "ONLINE")
+	if singleUser {
+		// Отключаем пользователей целевой базы: RESTORE ... REPLACE требует монопольного доступа
+		statements[0].query = fmt.Sprintf(singleUserRestoreBatch,
+			quoteIdentifier(opts.DstDB), statements[0].query, quoteString(opts.DstDB))
+	}
+
+	for _, st := range statements {
+		if _, err := conn.ExecContext(ctx, st.query, st.args...); err != nil {
+			if singleUser {
+				// Пакет мог прерваться до CATCH (отмена контекста): база не должна остаться в SINGLE_USER
+				resetCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), multiUserResetTimeout)
+				_, _ = conn.ExecContext(resetCtx, fmt.Sprintf(multiUserResetBatch, //nolint:errcheck // основная ошибка важнее
+					quoteIdentifier(opts.DstDB), quoteString(opts.DstDB)))
+				cancel()
+			}
+			return fmt.Errorf("%s: %w", ErrMSSQLRestore, err)
+		}
+	}
+	return nil
+}
+
+// sourceDB возвращает соединение с msdb сервера-источника: текущее, если источник
+// совпадает с сервером клиента, иначе новое (закрывается возвращённой функцией).
+func (c *client) sourceDB(ctx context.Context, server string) (*sql.DB, func(), error) {
+	if server == "" || strings.EqualFold(server, c.opts.Server) {
+		return c.db, func() {}, nil
+	}
+	db, err := c.open(ctx, server, "msdb")
+	if err != nil {
+		return nil, nil, err
+	}
+	return db, func() { _ = db.Close() }, nil //nolint:errcheck // best-effort close
+}
+
+// queryBackupHistory читает историю резервного копирования базы database.
+func queryBackupHistory(ctx context.Context, db *sql.DB, database, stopAt string) ([]*backupSet, error) {
+	rows, err := db.QueryContext(ctx, backupHistoryQuery, database, stopAt)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrMSSQLQuery, err)
+	}
+	defer rows.Close()
+
+	var sets []*backupSet
+	var last *backupSet
+	for rows.Next() {
+		var (
+			id                                      int64
+			kind, device                            string
+			position                                int
+			firstLSN, lastLSN, checkpointLSN, dbLSN string
+			finishedAt                              time.Time
+		)
+		if err := rows.Scan(&id, &kind, &position, &firstLSN, &lastLSN, &checkpointLSN, &dbLSN, &finishedAt, &device); err != nil {
+			return nil, fmt.Errorf("%s: %w", ErrMSSQLQuery, err)
+		}
+		if last != nil && last.id == id {
+			last.devices = append(last.devices, device)
+			continue
+		}
+		last = &backupSet{
+			id:                id,
+			kind:              strings.TrimSpace(kind),
+			position:          position,
+			firstLSN:          parseLSN(firstLSN),
+			lastLSN:           parseLSN(lastLSN),
+			checkpointLSN:     parseLSN(checkpointLSN),
+			databaseBackupLSN: parseLSN(dbLSN),
+			finishedAt:        finishedAt,
+			devices:           []string{device},
+		}
+		sets = append(sets, last)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrMSSQLQuery, err)
+	}
+	return sets, nil
+}
+
+// queryBackupFiles читает логические файлы полной резервной копии.
+func queryBackupFiles(ctx context.Context, db *sql.DB, backupSetID int64) ([]backupFile, error) {
+	rows, err := db.QueryContext(ctx, backupFilesQuery, backupSetID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrMSSQLQuery, err)
+	}
+	defer rows.Close()
+
+	var files []backupFile
+	for rows.Next() {
+		var f backupFile
+		if err := rows.Scan(&f.logicalName, &f.physicalName, &f.fileType); err != nil {
+			return nil, fmt.Errorf("%s: %w", ErrMSSQLQuery, err)
+		}
+		f.fileType = strings.TrimSpace(f.fileType)
+		files = append(files, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrMSSQLQuery, err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%s: backup set %d has no files in msdb.dbo.backupfile", ErrMSSQLQuery, backupSetID)
+	}
+	return files, nil
+}
+
+// queryTarget возвращает состояние и файлы целевой базы (пусто, если её нет)
+// и каталоги данных и журналов экземпляра по умолчанию.
+func queryTarget(ctx context.Context, conn *sql.Conn, database string) (state string, files []targetFile, dataDir, logDir string, err error) {
+	err = conn.QueryRowContext(ctx, targetStateQuery, database).Scan(&state)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", nil, "", "", fmt.Errorf("%s: %w", ErrMSSQLQuery, err)
+	}
+
+	if state != "" {
+		rows, qErr := conn.QueryContext(ctx, targetFilesQuery, database)
+		if qErr != nil {
+			return "", nil, "", "", fmt.Errorf("%s: %w", ErrMSSQLQuery, qErr)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var fileType int
+			var f targetFile
+			if sErr := rows.Scan(&fileType, &f.physicalName); sErr != nil {
+				return "", nil, "", "", fmt.Errorf("%s: %w", ErrMSSQLQuery, sErr)
+			}
+			// sys.master_files.type: 0 — данные, 1 — журнал, 2 — FILESTREAM, 4 — полнотекстовый каталог
+			f.isLog = fileType == 1
+			files = append(files, f)
+		}
+		if rErr := rows.Err(); rErr != nil {
+			return "", nil, "", "", fmt.Errorf("%s: %w", ErrMSSQLQuery, rErr)
+		}
+	}
+
+	var data, log sql.NullString
+	if err := conn.QueryRowContext(ctx, defaultPathsQuery).Scan(&data, &log); err != nil {
+		return "", nil, "", "", fmt.Errorf("%s: %w", ErrMSSQLQuery, err)
+	}
+	return state, files, data.String, log.String, nil
+}
+
+// buildRestoreChain выбирает цепочку восстановления на момент stopAt (nil — последнее состояние):
+// последнюю полную копию, завершённую не позднее stopAt, последнюю разностную копию
+// от неё и непрерывную цепочку журналов до первой копии журнала, покрывающей stopAt.
+func buildRestoreChain(sets []*backupSet, stopAt *time.Time) (*restoreChain, error) {
+	before := func(s *backupSet) bool { return stopAt == nil || !s.finishedAt.After(*stopAt) }
+
+	chain := &restoreChain{}
+	for _, s := range sets {
+		if s.kind == "D" && before(s) {
+			chain.full = s
+		}
+	}
+	if chain.full == nil {
+		return nil, errors.New("no full backup found for the requested point in time")
+	}
+	for _, s := range sets {
+		if s.kind == "I" && s.id > chain.full.id && before(s) && s.databaseBackupLSN.Cmp(chain.full.checkpointLSN) == 0 {
+			chain.diff = s
+		}
+	}
+
+	base := chain.full
+	if chain.diff != nil {
+		base = chain.diff
+	}
+	lastLSN := base.lastLSN
+	for _, s := range sets {
+		if s.kind != "L" || s.lastLSN.Cmp(lastLSN) <= 0 {
+			continue
+		}
+		if s.firstLSN.Cmp(lastLSN) > 0 {
+			return nil, fmt.Errorf("log backup chain is broken before backup set %d (first LSN %s, expected %s)",
+				s.id, s.firstLSN, lastLSN)
+		}
+		chain.logs = append(chain.logs, s)
+		lastLSN = s.lastLSN
+		if stopAt != nil && !s.finishedAt.Before(*stopAt) {
+			// Копия журнала покрывает момент восстановления — останавливаемся на нём
+			chain.stopAt = stopAt.Format(restoreTimeLayout)
+			break
+		}
+	}
+	return chain, nil
+}
+
+// statements возвращает команды RESTORE цепочки для базы database.
+func (ch *restoreChain) statements(database string, moves []fileMove) []restoreStatement {
+	statements := make([]restoreStatement, 0, len(ch.logs)+3)
+
+	full := restoreFrom("RESTORE DATABASE @p1", database, ch.full)
+	for _, m := range moves {
+		n := len(full.args)
+		full.query += fmt.Sprintf(", MOVE @p%d TO @p%d", n+1, n+2)
+		full.args = append(full.args, m.logicalName, m.target)
+	}
+	full.query += ", REPLACE, NORECOVERY;"
+	statements = append(statements, full)
+
+	if ch.diff != nil {
+		diff := restoreFrom("RESTORE DATABASE @p1", database, ch.diff)
+		diff.query += ", NORECOVERY;"
+		statements = append(statements, diff)
+	}
+
+	for i, s := range ch.logs {
+		log := restoreFrom("RESTORE LOG @p1", database, s)
+		log.query += ", NORECOVERY"
+		if ch.stopAt != "" && i == len(ch.logs)-1 {
+			log.query += fmt.Sprintf(", STOPAT = @p%d", len(log.args)+1)
+			log.args = append(log.args, ch.stopAt)
+		}
+		log.query += ";"
+		statements = append(statements, log)
+	}
+
+	statements = append(statements, restoreStatement{
+		query: "RESTORE DATABASE @p1 WITH RECOVERY;",
+		args:  []any{database},
+	})
+	return statements
+}
+
+// restoreFrom начинает команду RESTORE из устройств набора s (FROM DISK = ... WITH FILE = n).
+func restoreFrom(prefix, database string, s *backupSet) restoreStatement {
+	st := restoreStatement{query: prefix + " FROM ", args: []any{database}}
+	for i, device := range s.devices {
+		if i > 0 {
+			st.query += ", "
+		}
+		st.args = append(st.args, device)
+		st.query += fmt.Sprintf("DISK = @p%d", len(st.args))
+	}
+	st.query += fmt.Sprintf(" WITH FILE = %d", s.position)
+	return st
+}
+
+// relocateFiles определяет расположение файлов восстанавливаемой базы database:
+// i-й файл данных (журнала) резервной копии занимает место i-го файла данных
+// (журнала) существующей базы; остальные размещаются рядом с ними или в каталогах
+// экземпляра по умолчанию под именами database.mdf, database_<logical>.ndf,
+// database_log.ldf, database_<logical>.ldf.
+func relocateFiles(database string, files []backupFile, existing []targetFile, dataDir, logDir string) []fileMove {
+	var existingData, existingLog []string
+	for _, f := range existing {
+		if f.isLog {
+			existingLog = append(existingLog, f.physicalName)
+		} else {
+			existingData = append(existingData, f.physicalName)
+		}
+	}
+	if len(existingData) > 0 {
+		dataDir = serverDir(existingData[0])
+	}
+	if len(existingLog) > 0 {
+		logDir = serverDir(existingLog[0])
+	}
+
+	moves := make([]fileMove, 0, len(files))
+	var dataIndex, logIndex int
+	for _, f := range files {
+		var target string
+		switch {
+		case f.fileType == "L" && logIndex < len(existingLog):
+			target = existingLog[logIndex]
+		case f.fileType == "L" && logIndex == 0:
+			target = joinServerPath(logDir, database+"_log.ldf")
+		case f.fileType == "L":
+			target = joinServerPath(logDir, database+"_"+f.logicalName+".ldf")
+		case dataIndex < len(existingData):
+			target = existingData[dataIndex]
+		case f.fileType == "S":
+			target = joinServerPath(dataDir, database+"_"+f.logicalName)
+		case dataIndex == 0:
+			target = joinServerPath(dataDir, database+".mdf")
+		default:
+			target = joinServerPath(dataDir, database+"_"+f.logicalName+".ndf")
+		}
+		if f.fileType == "L" {
+			logIndex++
+		} else {
+			dataIndex++
+		}
+		moves = append(moves, fileMove{logicalName: f.logicalName, target: target})
+	}
+	return moves
+}
+
+// serverDir возвращает каталог пути на сервере MSSQL (Windows или Linux) с завершающим разделителем.
+func serverDir(path string) string {
+	if i := strings.LastIndexAny(path, `\/`); i >= 0 {
+		return path[:i+1]
+	}
+	return ""
+}
+
+// joinServerPath соединяет каталог и имя файла разделителем, принятым в каталоге.
+func joinServerPath(dir, name string) string {
+	if dir == "" || strings.HasSuffix(dir, `\`) || strings.HasSuffix(dir, "/") {
+		return dir + name
+	}
+	if strings.Contains(dir, `\`) {
+		return dir + `\` + name
+	}
+	return dir + "/" + name
+}
+
+// parseLSN разбирает LSN (numeric(25,0)); пустое или некорректное значение — 0.
+func parseLSN(v string) *big.Int {
+	n, ok := new(big.Int).SetString(strings.TrimSpace(v), 10)
+	if !ok {
+		return new(big.Int)
+	}
+	return n
+}
+
+// quoteIdentifier экранирует имя объекта SQL Server ([name]).
+func quoteIdentifier(name string) string {
+	return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
+}
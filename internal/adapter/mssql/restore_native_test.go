@@ -0,0 +1,414 @@
+package mssql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// historyColumns — колонки backupHistoryQuery.
+var historyColumns = []string{
+	"backup_set_id", "type", "position", "first_lsn", "last_lsn",
+	"checkpoint_lsn", "database_backup_lsn", "backup_finish_date", "physical_device_name",
+}
+
+func at(hhmm string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04", "2026-03-14 "+hhmm)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func set(id int64, kind string, first, last, checkpoint, dbLSN string, finished string) *backupSet {
+	return &backupSet{
+		id:                id,
+		kind:              kind,
+		position:          1,
+		firstLSN:          parseLSN(first),
+		lastLSN:           parseLSN(last),
+		checkpointLSN:     parseLSN(checkpoint),
+		databaseBackupLSN: parseLSN(dbLSN),
+		finishedAt:        at(finished),
+		devices:           []string{`\\backup\erp\` + kind + ".bak"},
+	}
+}
+
+// history — полная копия в 01:00, разностная в 12:00, журналы каждые 4 часа.
+// LSN превышают int64, как в реальных базах.
+func history() []*backupSet {
+	return []*backupSet{
+		set(10, "D", "41000000010000001", "41000000020000001", "41000000015000001", "0", "01:00"),
+		set(11, "L", "41000000005000001", "41000000030000001", "0", "41000000015000001", "04:00"),
+		set(12, "L", "41000000030000001", "41000000040000001", "0", "41000000015000001", "08:00"),
+		set(13, "I", "41000000045000001", "41000000050000001", "41000000045000001", "41000000015000001", "12:00"),
+		set(14, "L", "41000000040000001", "41000000055000001", "0", "41000000015000001", "12:30"),
+		set(15, "L", "41000000055000001", "41000000060000001", "0", "41000000015000001", "16:00"),
+		set(16, "L", "41000000060000001", "41000000070000001", "0", "41000000015000001", "20:00"),
+	}
+}
+
+func chainIDs(ch *restoreChain) []int64 {
+	ids := []int64{ch.full.id}
+	if ch.diff != nil {
+		ids = append(ids, ch.diff.id)
+	}
+	for _, l := range ch.logs {
+		ids = append(ids, l.id)
+	}
+	return ids
+}
+
+func TestBuildRestoreChain(t *testing.T) {
+	tests := []struct {
+		name       string
+		sets       []*backupSet
+		stopAt     string
+		wantIDs    []int64
+		wantStopAt string
+		wantErr    string
+	}{
+		{
+			name:       "полная, разностная и журнал до момента восстановления",
+			sets:       history(),
+			stopAt:     "13:00",
+			wantIDs:    []int64{10, 13, 14, 15},
+			wantStopAt: "2026-03-14T13:00:00",
+		},
+		{
+			name:    "последнее состояние — все журналы без STOPAT",
+			sets:    history(),
+			wantIDs: []int64{10, 13, 14, 15, 16},
+		},
+		{
+			name:       "до разностной копии — только журналы от полной",
+			sets:       history(),
+			stopAt:     "06:00",
+			wantIDs:    []int64{10, 11, 12},
+			wantStopAt: "2026-03-14T06:00:00",
+		},
+		{
+			name: "разностная копия от другой полной не используется",
+			sets: []*backupSet{
+				set(10, "D", "100", "200", "150", "0", "01:00"),
+				set(11, "I", "300", "400", "300", "999", "02:00"),
+				set(12, "L", "150", "500", "0", "150", "03:00"),
+			},
+			wantIDs: []int64{10, 12},
+		},
+		{
+			name: "разрыв цепочки журналов",
+			sets: []*backupSet{
+				set(10, "D", "100", "200", "150", "0", "01:00"),
+				set(11, "L", "150", "300", "0", "150", "02:00"),
+				set(12, "L", "350", "400", "0", "150", "03:00"),
+			},
+			wantErr: "log backup chain is broken before backup set 12",
+		},
+		{
+			name:    "нет полной копии на момент восстановления",
+			sets:    history(),
+			stopAt:  "00:30",
+			wantErr: "no full backup found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stopAt *time.Time
+			if tt.stopAt != "" {
+				s := at(tt.stopAt)
+				stopAt = &s
+			}
+			chain, err := buildRestoreChain(tt.sets, stopAt)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("buildRestoreChain() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("buildRestoreChain() error = %v", err)
+			}
+			if got := chainIDs(chain); !equalIDs(got, tt.wantIDs) {
+				t.Errorf("chain = %v, want %v", got, tt.wantIDs)
+			}
+			if chain.stopAt != tt.wantStopAt {
+				t.Errorf("stopAt = %q, want %q", chain.stopAt, tt.wantStopAt)
+			}
+		})
+	}
+}
+
+func equalIDs(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestRelocateFiles(t *testing.T) {
+	files := []backupFile{
+		{logicalName: "erp", fileType: "D"},
+		{logicalName: "erp_log", fileType: "L"},
+		{logicalName: "erp_data2", fileType: "D"},
+	}
+
+	t.Run("существующая база — её файлы и каталоги", func(t *testing.T) {
+		existing := []targetFile{
+			{physicalName: `E:\Data\erp_test.mdf`},
+			{isLog: true, physicalName: `F:\Log\erp_test_log.ldf`},
+		}
+		got := relocateFiles("erp_test", files, existing, `D:\Default\`, `D:\Default\`)
+		want := []fileMove{
+			{"erp", `E:\Data\erp_test.mdf`},
+			{"erp_log", `F:\Log\erp_test_log.ldf`},
+			{"erp_data2", `E:\Data\erp_test_erp_data2.ndf`},
+		}
+		assertMoves(t, got, want)
+	})
+
+	t.Run("новая база — каталоги экземпляра по умолчанию", func(t *testing.T) {
+		got := relocateFiles("erp_test", files, nil, "/var/opt/mssql/data", "/var/opt/mssql/log/")
+		want := []fileMove{
+			{"erp", "/var/opt/mssql/data/erp_test.mdf"},
+			{"erp_log", "/var/opt/mssql/log/erp_test_log.ldf"},
+			{"erp_data2", "/var/opt/mssql/data/erp_test_erp_data2.ndf"},
+		}
+		assertMoves(t, got, want)
+	})
+}
+
+func assertMoves(t *testing.T, got, want []fileMove) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("moves = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("move[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+// expectHistory настраивает mock истории резервного копирования (полная, разностная, журнал).
+func expectHistory(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(regexp.QuoteMeta("FROM msdb.dbo.backupset bs")).
+		WithArgs("erp", "2026-03-14T13:00:00").
+		WillReturnRows(sqlmock.NewRows(historyColumns).
+			AddRow(10, "D", 1, "100", "200", "150", "0", at("01:00"), `\\backup\erp\full_1.bak`).
+			AddRow(10, "D", 1, "100", "200", "150", "0", at("01:00"), `\\backup\erp\full_2.bak`).
+			AddRow(13, "I", 2, "250", "300", "250", "150", at("12:00"), `\\backup\erp\diff.bak`).
+			AddRow(14, "L", 1, "200", "400", "0", "150", at("14:00"), `\\backup\erp\log.trn`))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM msdb.dbo.backupfile bf")).
+		WithArgs(int64(10)).
+		WillReturnRows(sqlmock.NewRows([]string{"logical_name", "physical_name", "file_type"}).
+			AddRow("erp", `D:\Data\erp.mdf`, "D").
+			AddRow("erp_log", `D:\Log\erp_log.ldf`, "L"))
+}
+
+func TestClient_RestoreNative(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка создания sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	expectHistory(mock)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM sys.databases")).
+		WithArgs("erp_test").
+		WillReturnRows(sqlmock.NewRows([]string{"state_desc"}).AddRow("ONLINE"))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM sys.master_files")).
+		WithArgs("erp_test").
+		WillReturnRows(sqlmock.NewRows([]string{"type", "physical_name"}).
+			AddRow(0, `E:\Data\erp_test.mdf`).
+			AddRow(1, `E:\Log\erp_test_log.ldf`))
+	mock.ExpectQuery(regexp.QuoteMeta("InstanceDefaultDataPath")).
+		WillReturnRows(sqlmock.NewRows([]string{"data", "log"}).AddRow(`D:\Data\`, `D:\Log\`))
+	mock.ExpectExec(regexp.QuoteMeta("ALTER DATABASE [erp_test] SET SINGLE_USER WITH ROLLBACK IMMEDIATE;\nBEGIN TRY\n\t"+
+		"RESTORE DATABASE @p1 FROM DISK = @p2, DISK = @p3 WITH FILE = 1, MOVE @p4 TO @p5, MOVE @p6 TO @p7, REPLACE, NORECOVERY;")).
+		WithArgs("erp_test", `\\backup\erp\full_1.bak`, `\\backup\erp\full_2.bak`,
+			"erp", `E:\Data\erp_test.mdf`, "erp_log", `E:\Log\erp_test_log.ldf`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("RESTORE DATABASE @p1 FROM DISK = @p2 WITH FILE = 2, NORECOVERY;")).
+		WithArgs("erp_test", `\\backup\erp\diff.bak`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("RESTORE LOG @p1 FROM DISK = @p2 WITH FILE = 1, NORECOVERY, STOPAT = @p3;")).
+		WithArgs("erp_test", `\\backup\erp\log.trn`, "2026-03-14T13:00:00").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("RESTORE DATABASE @p1 WITH RECOVERY;")).
+		WithArgs("erp_test").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	cli := &client{
+		db:   db,
+		opts: ClientOptions{Server: "srv-sql", RestoreStrategy: RestoreStrategyNative},
+	}
+	err = cli.Restore(context.Background(), RestoreOptions{
+		TimeToRestore: "2026-03-14T13:00:00",
+		SrcServer:     "SRV-SQL",
+		SrcDB:         "erp",
+		DstServer:     "srv-sql",
+		DstDB:         "erp_test",
+	})
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("невыполненные ожидания: %v", err)
+	}
+}
+
+func TestClient_RestoreNative_ResetsMultiUser(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка создания sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	expectHistory(mock)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM sys.databases")).
+		WithArgs("erp_test").
+		WillReturnRows(sqlmock.NewRows([]string{"state_desc"}).AddRow("ONLINE"))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM sys.master_files")).
+		WithArgs("erp_test").
+		WillReturnRows(sqlmock.NewRows([]string{"type", "physical_name"}))
+	mock.ExpectQuery(regexp.QuoteMeta("InstanceDefaultDataPath")).
+		WillReturnRows(sqlmock.NewRows([]string{"data", "log"}).AddRow(`D:\Data\`, `D:\Log\`))
+	mock.ExpectExec(regexp.QuoteMeta("SET SINGLE_USER WITH ROLLBACK IMMEDIATE;")).
+		WillReturnError(errors.New("Cannot open backup device"))
+	mock.ExpectExec(regexp.QuoteMeta("IF DATABASEPROPERTYEX(N'erp_test', 'Status') = 'ONLINE' ALTER DATABASE [erp_test] SET MULTI_USER;")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	cli := &client{
+		db:   db,
+		opts: ClientOptions{Server: "srv-sql", RestoreStrategy: RestoreStrategyNative},
+	}
+	err = cli.Restore(context.Background(), RestoreOptions{
+		TimeToRestore: "2026-03-14T13:00:00",
+		SrcDB:         "erp",
+		DstDB:         "erp_test",
+	})
+	if err == nil || !strings.Contains(err.Error(), "Cannot open backup device") {
+		t.Fatalf("Restore() error = %v, want backup device error", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("невыполненные ожидания: %v", err)
+	}
+}
+
+func TestClient_RestoreNative_SourceServer(t *testing.T) {
+	srcDB, srcMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка создания sqlmock: %v", err)
+	}
+	dstDB, dstMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка создания sqlmock: %v", err)
+	}
+	defer dstDB.Close()
+
+	expectHistory(srcMock)
+	srcMock.ExpectClose()
+	dstMock.ExpectQuery(regexp.QuoteMeta("FROM sys.databases")).
+		WithArgs("erp_test").
+		WillReturnRows(sqlmock.NewRows([]string{"state_desc"}))
+	dstMock.ExpectQuery(regexp.QuoteMeta("InstanceDefaultDataPath")).
+		WillReturnRows(sqlmock.NewRows([]string{"data", "log"}).AddRow("/var/opt/mssql/data/", "/var/opt/mssql/data/"))
+	dstMock.ExpectExec(regexp.QuoteMeta("RESTORE DATABASE @p1 FROM DISK = @p2, DISK = @p3 WITH FILE = 1, MOVE @p4 TO @p5, MOVE @p6 TO @p7, REPLACE, NORECOVERY;")).
+		WithArgs("erp_test", `\\backup\erp\full_1.bak`, `\\backup\erp\full_2.bak`,
+			"erp", "/var/opt/mssql/data/erp_test.mdf", "erp_log", "/var/opt/mssql/data/erp_test_log.ldf").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	dstMock.ExpectExec("RESTORE DATABASE @p1 FROM").WillReturnResult(sqlmock.NewResult(0, 0))
+	dstMock.ExpectExec("RESTORE LOG @p1 FROM").WillReturnResult(sqlmock.NewResult(0, 0))
+	dstMock.ExpectExec("WITH RECOVERY").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	var opened []string
+	cli := &client{
+		db:   dstDB,
+		opts: ClientOptions{Server: "srv-sql-test", RestoreStrategy: RestoreStrategyNative},
+		openDB: func(_ context.Context, server, database string) (*sql.DB, error) {
+			opened = append(opened, server+"/"+database)
+			return srcDB, nil
+		},
+	}
+	err = cli.Restore(context.Background(), RestoreOptions{
+		TimeToRestore: "2026-03-14T13:00:00",
+		SrcServer:     "srv-sql-prod",
+		SrcDB:         "erp",
+		DstServer:     "srv-sql-test",
+		DstDB:         "erp_test",
+	})
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if len(opened) != 1 || opened[0] != "srv-sql-prod/msdb" {
+		t.Errorf("opened = %v, want [srv-sql-prod/msdb]", opened)
+	}
+	if err := srcMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("источник: невыполненные ожидания: %v", err)
+	}
+	if err := dstMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("приёмник: невыполненные ожидания: %v", err)
+	}
+}
+
+func TestClient_RestoreNative_Errors(t *testing.T) {
+	t.Run("нет полной копии — RESTORE не выполняется", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("ошибка создания sqlmock: %v", err)
+		}
+		defer db.Close()
+		mock.ExpectQuery(regexp.QuoteMeta("FROM msdb.dbo.backupset bs")).
+			WillReturnRows(sqlmock.NewRows(historyColumns))
+
+		cli := &client{db: db, opts: ClientOptions{Server: "srv-sql", RestoreStrategy: RestoreStrategyNative}}
+		err = cli.Restore(context.Background(), RestoreOptions{SrcDB: "erp", DstDB: "erp_test"})
+		if err == nil || !strings.Contains(err.Error(), ErrMSSQLRestore) {
+			t.Fatalf("Restore() error = %v, want %s", err, ErrMSSQLRestore)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("невыполненные ожидания: %v", err)
+		}
+	})
+
+	t.Run("некорректный момент восстановления", func(t *testing.T) {
+		cli := &client{db: &sql.DB{}, opts: ClientOptions{Server: "srv-sql", RestoreStrategy: RestoreStrategyNative}}
+		err := cli.Restore(context.Background(), RestoreOptions{SrcDB: "erp", DstDB: "erp_test", TimeToRestore: "14.03.2026"})
+		if err == nil || !strings.Contains(err.Error(), "invalid time to restore") {
+			t.Fatalf("Restore() error = %v", err)
+		}
+	})
+}
+
+func TestNewClient_RestoreStrategy(t *testing.T) {
+	c, err := NewClient(ClientOptions{Server: "srv-sql"})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if got := c.(*client).opts.RestoreStrategy; got != RestoreStrategyProcedure {
+		t.Errorf("RestoreStrategy = %q, want %q", got, RestoreStrategyProcedure)
+	}
+
+	if _, err := NewClient(ClientOptions{Server: "srv-sql", RestoreStrategy: "sqlcmd"}); err == nil {
+		t.Error("NewClient() с неизвестной стратегией должен вернуть ошибку")
+	}
+}
+
+func TestQuoteIdentifier(t *testing.T) {
+	if got := quoteIdentifier("erp]test"); got != "[erp]]test]" {
+		t.Errorf("quoteIdentifier() = %q", got)
+	}
+}
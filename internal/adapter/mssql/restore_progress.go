@@ -0,0 +1,67 @@
+package mssql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Compile-time проверка реализации интерфейса
+var _ RestoreProgressProvider = (*client)(nil)
+
+// restoreProgressQuery выбирает выполняющуюся команду RESTORE в базу @p1.
+// Сеанс RESTORE работает в контексте master, поэтому база определяется
+// по блокировке DATABASE, которую RESTORE удерживает на восстанавливаемой базе.
+const restoreProgressQuery = `
+	SELECT TOP 1
+		r.command,
+		r.percent_complete,
+		r.estimated_completion_time,
+		r.total_elapsed_time,
+		ISNULL(r.wait_type, '')
+	FROM sys.dm_exec_requests r
+	WHERE r.command LIKE 'RESTORE%'
+		AND (r.database_id = DB_ID(@p1)
+			OR EXISTS (
+				SELECT 1
+				FROM sys.dm_tran_locks l
+				WHERE l.request_session_id = r.session_id
+					AND l.resource_type = 'DATABASE'
+					AND l.resource_database_id = DB_ID(@p1)))
+	ORDER BY r.start_time DESC;
+	`
+
+// GetRestoreProgress возвращает ход RESTORE в базу database по sys.dm_exec_requests.
+// Требует права VIEW SERVER STATE.
+func (c *client) GetRestoreProgress(ctx context.Context, database string) (*RestoreProgress, error) {
+	if c.db == nil {
+		return nil, fmt.Errorf("%s: connection not established", ErrMSSQLQuery)
+	}
+
+	var (
+		command     string
+		percent     float64
+		remainingMs int64
+		elapsedMs   int64
+		waitType    string
+	)
+	err := c.db.QueryRowContext(ctx, restoreProgressQuery, database).
+		Scan(&command, &percent, &remainingMs, &elapsedMs, &waitType)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%s: %w", ErrMSSQLQuery, err)
+	}
+
+	return &RestoreProgress{
+		Command:            strings.TrimSpace(command),
+		PercentComplete:    percent,
+		EstimatedRemaining: time.Duration(remainingMs) * time.Millisecond,
+		Elapsed:            time.Duration(elapsedMs) * time.Millisecond,
+		WaitType:           strings.TrimSpace(waitType),
+	}, nil
+}
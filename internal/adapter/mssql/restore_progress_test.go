@@ -0,0 +1,101 @@
+package mssql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// TestClient_GetRestoreProgress проверяет чтение хода RESTORE из sys.dm_exec_requests
+func TestClient_GetRestoreProgress(t *testing.T) {
+	columns := []string{"command", "percent_complete", "estimated_completion_time", "total_elapsed_time", "wait_type"}
+	tests := []struct {
+		name      string
+		setupMock func(mock sqlmock.Sqlmock)
+		noConnect bool
+		want      *RestoreProgress
+		wantErr   bool
+	}{
+		{
+			name: "RESTORE выполняется",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows(columns).
+					AddRow("RESTORE DATABASE ", 42.5, int64(90000), int64(60000), "BACKUPIO")
+				mock.ExpectQuery("FROM sys.dm_exec_requests").
+					WithArgs("ERP_TEST").
+					WillReturnRows(rows)
+			},
+			want: &RestoreProgress{
+				Command:            "RESTORE DATABASE",
+				PercentComplete:    42.5,
+				EstimatedRemaining: 90 * time.Second,
+				Elapsed:            time.Minute,
+				WaitType:           "BACKUPIO",
+			},
+		},
+		{
+			name: "RESTORE не выполняется",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("FROM sys.dm_exec_requests").
+					WithArgs("ERP_TEST").
+					WillReturnError(sql.ErrNoRows)
+			},
+			want: nil,
+		},
+		{
+			name: "нет права VIEW SERVER STATE",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("FROM sys.dm_exec_requests").
+					WithArgs("ERP_TEST").
+					WillReturnError(errors.New("VIEW SERVER STATE permission was denied"))
+			},
+			wantErr: true,
+		},
+		{
+			name:      "нет соединения",
+			noConnect: true,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli := &client{
+				opts: ClientOptions{Server: "test"},
+			}
+
+			if !tt.noConnect {
+				db, mock, err := sqlmock.New()
+				if err != nil {
+					t.Fatalf("ошибка создания sqlmock: %v", err)
+				}
+				defer db.Close()
+
+				tt.setupMock(mock)
+				cli.db = db
+			}
+
+			got, err := cli.GetRestoreProgress(context.Background(), "ERP_TEST")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetRestoreProgress() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("GetRestoreProgress() = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil || *got != *tt.want {
+				t.Errorf("GetRestoreProgress() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
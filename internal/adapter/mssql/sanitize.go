@@ -0,0 +1,413 @@
+package mssql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Kargones/apk-ci/internal/adapter/onec/dbstruct"
+)
+
+// Compile-time проверка реализации интерфейса
+var _ DataSanitizer = (*client)(nil)
+
+// sanitizeBatchSize — число UUID в одном запросе описаний объектов (лимит параметров — 2100).
+const sanitizeBatchSize = 500
+
+// allExchangePlans — значение ExchangePlans, очищающее очереди всех планов обмена.
+const allExchangePlans = "*"
+
+const (
+	// dbNamesQuery читает DBNames: в 8.3 запись хранится в Params, в ранних версиях — в Config.
+	dbNamesQuery = `
+	SELECT TOP 1 n.BinaryData
+	FROM (
+		SELECT 1 AS src, BinaryData FROM %[1]s.dbo.Params WHERE FileName = 'DBNames'
+		UNION ALL
+		SELECT 2 AS src, BinaryData FROM %[1]s.dbo.Config WHERE FileName = 'DBNames'
+	) n
+	ORDER BY n.src;
+	`
+
+	// descriptorsQuery читает описания объектов метаданных по UUID.
+	descriptorsQuery = `SELECT FileName, BinaryData FROM %s.dbo.Config WHERE FileName IN (%s);`
+
+	// columnsQuery проверяет наличие колонок в таблице.
+	columnsQuery = `SELECT c.name FROM %s.sys.columns c WHERE c.object_id = OBJECT_ID(@p1) AND c.name IN (@p2, @p3);`
+
+	// changeTablesQuery выбирает таблицы регистрации изменений планов обмена.
+	changeTablesQuery = `
+	SELECT t.name
+	FROM %[1]s.sys.tables t
+	JOIN %[1]s.sys.columns c ON c.object_id = t.object_id AND c.name = '_NodeTRef'
+	WHERE t.name LIKE '[_]%%ChngR%%'
+	ORDER BY t.name;
+	`
+
+	// scheduledJobsTablesQuery выбирает таблицы регламентных заданий.
+	scheduledJobsTablesQuery = `
+	SELECT t.name
+	FROM %[1]s.sys.tables t
+	JOIN %[1]s.sys.columns c ON c.object_id = t.object_id AND c.name = '_Use'
+	WHERE t.name LIKE '[_]ScheduledJobs%%'
+	ORDER BY t.name;
+	`
+)
+
+// stringTypeCode — код строкового типа в колонке _TYPE реквизита составного типа.
+const stringTypeCode = 0x05
+
+// Sanitize применяет профили обезличивания к восстановленной базе 1С.
+// Реквизиты находятся по именам метаданных через DBNames и описания объектов в Config.
+func (c *client) Sanitize(ctx context.Context, opts SanitizeOptions) ([]SanitizeAction, error) {
+	if c.db == nil {
+		return nil, fmt.Errorf("%s: connection not established", ErrMSSQLQuery)
+	}
+
+	s := &sanitizer{
+		db:          c.db,
+		database:    quoteIdentifier(opts.Database),
+		descriptors: make(map[string]*dbstruct.Descriptor),
+	}
+
+	var actions []SanitizeAction
+	for _, p := range opts.Profiles {
+		profileActions, err := s.apply(ctx, p)
+		actions = append(actions, profileActions...)
+		if err != nil {
+			return actions, fmt.Errorf("%s: профиль %q: %w", ErrMSSQLQuery, p.Name, err)
+		}
+	}
+	return actions, nil
+}
+
+// sanitizer выполняет профили обезличивания в одной базе, кэшируя структуру хранения.
+type sanitizer struct {
+	db          *sql.DB
+	database    string
+	names       *dbstruct.DBNames
+	descriptors map[string]*dbstruct.Descriptor // "вид.имя" → описание
+}
+
+// apply выполняет операции профиля: маскирование, очистку очередей обмена,
+// отключение регламентных заданий, инструкции SQL.
+func (s *sanitizer) apply(ctx context.Context, p SanitizeProfile) ([]SanitizeAction, error) {
+	var actions []SanitizeAction
+
+	for _, m := range p.Masks {
+		action, err := s.mask(ctx, m)
+		if err != nil {
+			return actions, fmt.Errorf("маскирование %s.%s: %w", m.Object, m.Attribute, err)
+		}
+		action.Profile = p.Name
+		actions = append(actions, *action)
+	}
+
+	for _, plan := range p.ExchangePlans {
+		action, err := s.clearExchangeQueue(ctx, plan)
+		if err != nil {
+			return actions, fmt.Errorf("очистка очереди обмена %s: %w", plan, err)
+		}
+		action.Profile = p.Name
+		actions = append(actions, *action)
+	}
+
+	if p.DisableScheduledJobs {
+		action, err := s.disableScheduledJobs(ctx)
+		if err != nil {
+			return actions, fmt.Errorf("отключение регламентных заданий: %w", err)
+		}
+		action.Profile = p.Name
+		actions = append(actions, *action)
+	}
+
+	for _, stmt := range p.SQL {
+		// sp_executesql базы выполняет инструкцию в её контексте
+		res, err := s.db.ExecContext(ctx, fmt.Sprintf("EXEC %s.sys.sp_executesql @p1;", s.database), stmt)
+		if err != nil {
+			return actions, fmt.Errorf("инструкция %q: %w", stmt, err)
+		}
+		rows, _ := res.RowsAffected() //nolint:errcheck // число строк справочное
+		actions = append(actions, SanitizeAction{Profile: p.Name, Action: SanitizeActionSQL, Target: stmt, Rows: rows})
+	}
+
+	return actions, nil
+}
+
+// mask заменяет значения реквизита m.Attribute объекта m.Object на m.Value.
+func (s *sanitizer) mask(ctx context.Context, m SanitizeMask) (*SanitizeAction, error) {
+	kind, name, err := dbstruct.ParseObjectName(m.Object)
+	if err != nil {
+		return nil, err
+	}
+	d, err := s.descriptor(ctx, kind, name)
+	if err != nil {
+		return nil, err
+	}
+	number, err := s.number(d.UUID, kind)
+	if err != nil {
+		return nil, err
+	}
+	table := dbstruct.TableName(kind, number)
+
+	tabularUUID, fieldUUID, err := d.Field(m.Attribute, s.names)
+	if err != nil {
+		return nil, err
+	}
+	if tabularUUID != "" {
+		vt, err := s.number(tabularUUID, dbstruct.KindVT)
+		if err != nil {
+			return nil, err
+		}
+		table = dbstruct.TabularTableName(table, vt)
+	}
+	fld, err := s.number(fieldUUID, dbstruct.KindFld)
+	if err != nil {
+		return nil, err
+	}
+	column, composite, err := s.stringColumn(ctx, table, dbstruct.FieldColumn(fld))
+	if err != nil {
+		return nil, err
+	}
+
+	qColumn := quoteIdentifier(column)
+	query := fmt.Sprintf("UPDATE %s SET %s = @p1 WHERE %s <> @p1", s.table(table), qColumn, qColumn)
+	if composite {
+		// Составной тип: меняем только строки, где хранится строковое значение
+		query += fmt.Sprintf(" AND %s = 0x%02X", quoteIdentifier(dbstruct.FieldColumn(fld)+"_TYPE"), stringTypeCode)
+	}
+	res, err := s.db.ExecContext(ctx, query+";", m.Value)
+	if err != nil {
+		return nil, err
+	}
+	rows, _ := res.RowsAffected() //nolint:errcheck // число строк справочное
+
+	return &SanitizeAction{
+		Action: SanitizeActionMask,
+		Target: m.Object + "." + m.Attribute,
+		Table:  table,
+		Column: column,
+		Rows:   rows,
+	}, nil
+}
+
+// stringColumn возвращает колонку реквизита: _FldN, для составного типа — _FldN_S.
+func (s *sanitizer) stringColumn(ctx context.Context, table, column string) (string, bool, error) {
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(columnsQuery, s.database),
+		s.database+".dbo."+quoteIdentifier(table), column, column+"_S")
+	if err != nil {
+		return "", false, err
+	}
+	defer rows.Close()
+
+	found := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return "", false, err
+		}
+		found[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return "", false, err
+	}
+
+	switch {
+	case found[column]:
+		return column, false, nil
+	case found[column+"_S"]:
+		return column + "_S", true, nil
+	default:
+		return "", false, fmt.Errorf("в таблице %s нет колонки %s: маскируются только строковые и числовые реквизиты", table, column)
+	}
+}
+
+// clearExchangeQueue очищает регистрацию изменений плана обмена plan ("*" — всех планов).
+func (s *sanitizer) clearExchangeQueue(ctx context.Context, plan string) (*SanitizeAction, error) {
+	tables, err := s.tableNames(ctx, changeTablesQuery)
+	if err != nil {
+		return nil, err
+	}
+	action := &SanitizeAction{Action: SanitizeActionExchangeQueue, Target: plan, Table: strings.Join(tables, ", ")}
+
+	if plan == allExchangePlans {
+		for _, t := range tables {
+			var count int64
+			if err := s.db.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT_BIG(*) FROM %s;", s.table(t))).Scan(&count); err != nil {
+				return nil, err
+			}
+			if _, err := s.db.ExecContext(ctx, fmt.Sprintf("TRUNCATE TABLE %s;", s.table(t))); err != nil {
+				return nil, err
+			}
+			action.Rows += count
+		}
+		return action, nil
+	}
+
+	kind, name, err := dbstruct.ParseObjectName(plan)
+	if err != nil {
+		return nil, err
+	}
+	if kind != dbstruct.KindNode {
+		return nil, fmt.Errorf("%s не является планом обмена", plan)
+	}
+	d, err := s.descriptor(ctx, kind, name)
+	if err != nil {
+		return nil, err
+	}
+	number, err := s.number(d.UUID, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	// _NodeTRef — номер таблицы плана обмена, binary(4)
+	nodeType := make([]byte, 4)
+	binary.BigEndian.PutUint32(nodeType, uint32(number)) //nolint:gosec // номер таблицы положительный
+	for _, t := range tables {
+		res, err := s.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE _NodeTRef = @p1;", s.table(t)), nodeType)
+		if err != nil {
+			return nil, err
+		}
+		rows, _ := res.RowsAffected() //nolint:errcheck // число строк справочное
+		action.Rows += rows
+	}
+	return action, nil
+}
+
+// disableScheduledJobs снимает признак использования со всех регламентных заданий.
+func (s *sanitizer) disableScheduledJobs(ctx context.Context) (*SanitizeAction, error) {
+	tables, err := s.tableNames(ctx, scheduledJobsTablesQuery)
+	if err != nil {
+		return nil, err
+	}
+	action := &SanitizeAction{
+		Action: SanitizeActionScheduledJobs,
+		Target: "регламентные задания",
+		Table:  strings.Join(tables, ", "),
+		Column: "_Use",
+	}
+	for _, t := range tables {
+		res, err := s.db.ExecContext(ctx, fmt.Sprintf("UPDATE %s SET _Use = 0x00 WHERE _Use <> 0x00;", s.table(t)))
+		if err != nil {
+			return nil, err
+		}
+		rows, _ := res.RowsAffected() //nolint:errcheck // число строк справочное
+		action.Rows += rows
+	}
+	return action, nil
+}
+
+// descriptor находит описание объекта вида kind с именем name.
+func (s *sanitizer) descriptor(ctx context.Context, kind, name string) (*dbstruct.Descriptor, error) {
+	key := kind + "." + strings.ToLower(name)
+	if d, ok := s.descriptors[key]; ok {
+		return d, nil
+	}
+	if err := s.loadNames(ctx); err != nil {
+		return nil, err
+	}
+
+	candidates := s.names.UUIDs(kind)
+	for start := 0; start < len(candidates); start += sanitizeBatchSize {
+		batch := candidates[start:min(start+sanitizeBatchSize, len(candidates))]
+		d, err := s.findDescriptor(ctx, batch, name)
+		if err != nil {
+			return nil, err
+		}
+		if d != nil {
+			s.descriptors[key] = d
+			return d, nil
+		}
+	}
+	return nil, fmt.Errorf("объект %s не найден в конфигурации базы", name)
+}
+
+// findDescriptor читает описания объектов batch и возвращает объект с именем name.
+func (s *sanitizer) findDescriptor(ctx context.Context, batch []string, name string) (*dbstruct.Descriptor, error) {
+	placeholders := make([]string, len(batch))
+	args := make([]any, len(batch))
+	for i, id := range batch {
+		placeholders[i] = fmt.Sprintf("@p%d", i+1)
+		args[i] = id
+	}
+
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(descriptorsQuery, s.database, strings.Join(placeholders, ", ")), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var fileName string
+		var data []byte
+		if err := rows.Scan(&fileName, &data); err != nil {
+			return nil, err
+		}
+		d, parseErr := dbstruct.ParseDescriptor(fileName, data)
+		if parseErr != nil {
+			// Описания других форматов не участвуют в поиске
+			continue
+		}
+		if strings.EqualFold(d.Name, name) {
+			return d, nil
+		}
+	}
+	return nil, rows.Err()
+}
+
+// loadNames читает DBNames при первом обращении.
+func (s *sanitizer) loadNames(ctx context.Context) error {
+	if s.names != nil {
+		return nil
+	}
+	var data []byte
+	if err := s.db.QueryRowContext(ctx, fmt.Sprintf(dbNamesQuery, s.database)).Scan(&data); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("запись DBNames не найдена: база не является информационной базой 1С")
+		}
+		return err
+	}
+	names, err := dbstruct.ParseDBNames(data)
+	if err != nil {
+		return err
+	}
+	s.names = names
+	return nil
+}
+
+// number возвращает номер таблицы или поля uuid вида kind из DBNames.
+// Отсутствие записи означает, что описание объекта не соответствует структуре хранения.
+func (s *sanitizer) number(uuid, kind string) (int, error) {
+	n, ok := s.names.Number(uuid, kind)
+	if !ok {
+		return 0, fmt.Errorf("в DBNames нет записи %s для %s", kind, uuid)
+	}
+	return n, nil
+}
+
+// tableNames выполняет запрос списка таблиц базы.
+func (s *sanitizer) tableNames(ctx context.Context, query string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(query, s.database))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tables = append(tables, name)
+	}
+	return tables, rows.Err()
+}
+
+// table возвращает полное имя таблицы базы: [db].dbo.[table].
+func (s *sanitizer) table(name string) string {
+	return s.database + ".dbo." + quoteIdentifier(name)
+}
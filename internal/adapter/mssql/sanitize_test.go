@@ -0,0 +1,397 @@
+package mssql
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/Kargones/apk-ci/internal/adapter/onec/dbstruct"
+)
+
+const (
+	testCatalogUUID = "aaaaaaaa-0000-0000-0000-000000000001"
+	testNodeUUID    = "dddddddd-0000-0000-0000-000000000001"
+)
+
+// testDBNames — DBNames: справочник Контрагенты (реквизит Телефон,
+// табличная часть КонтактнаяИнформация с реквизитом Представление) и план обмена.
+const testDBNames = `{6,
+{aaaaaaaa-0000-0000-0000-000000000001,"Reference",42},
+{bbbbbbbb-0000-0000-0000-000000000001,"Fld",101},
+{cccccccc-0000-0000-0000-000000000001,"VT",7},
+{bbbbbbbb-0000-0000-0000-000000000002,"Fld",102},
+{eeeeeeee-0000-0000-0000-000000000001,"Reference",43},
+{dddddddd-0000-0000-0000-000000000001,"Node",5}
+}`
+
+const testCatalogDescriptor = `{1,
+{70,{1,{0,{0,0,aaaaaaaa-0000-0000-0000-000000000001},"Контрагенты",{1,"ru","Контрагенты"},""}}},
+{888744e1-b616-11d4-9436-004095e12fc7,1,
+{{0,{0,{1,0,bbbbbbbb-0000-0000-0000-000000000001},"Телефон",{1,"ru","Телефон"},""}}}
+},
+{932159f9-95b2-4e76-a8dd-8849fe5c5ded,1,
+{{0,{0,{1,0,cccccccc-0000-0000-0000-000000000001},"КонтактнаяИнформация",{1,"ru",""},""}},
+{888744e1-b616-11d4-9436-004095e12fc7,1,
+{{0,{0,{1,0,bbbbbbbb-0000-0000-0000-000000000002},"Представление",{1,"ru",""},""}}}
+}
+}
+}
+}`
+
+const testNodeDescriptor = `{1,{0,{0,0,dddddddd-0000-0000-0000-000000000001},"ОбменССайтом",{1,"ru",""},""}}`
+
+const testOtherDescriptor = `{1,{0,{0,0,eeeeeeee-0000-0000-0000-000000000001},"Номенклатура",{1,"ru",""},""}}`
+
+func expectDBNames(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(regexp.QuoteMeta("FROM [ERP_TEST].dbo.Params WHERE FileName = 'DBNames'")).
+		WillReturnRows(sqlmock.NewRows([]string{"BinaryData"}).AddRow([]byte(testDBNames)))
+}
+
+func expectColumns(mock sqlmock.Sqlmock, table, column string, found ...string) {
+	rows := sqlmock.NewRows([]string{"name"})
+	for _, f := range found {
+		rows.AddRow(f)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("FROM [ERP_TEST].sys.columns c")).
+		WithArgs("[ERP_TEST].dbo.["+table+"]", column, column+"_S").
+		WillReturnRows(rows)
+}
+
+// TestClient_Sanitize_Mask проверяет маскирование реквизитов объекта и табличной части
+func TestClient_Sanitize_Mask(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка создания sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	expectDBNames(mock)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT FileName, BinaryData FROM [ERP_TEST].dbo.Config WHERE FileName IN (@p1, @p2)")).
+		WithArgs(testCatalogUUID, "eeeeeeee-0000-0000-0000-000000000001").
+		WillReturnRows(sqlmock.NewRows([]string{"FileName", "BinaryData"}).
+			AddRow("eeeeeeee-0000-0000-0000-000000000001", []byte(testOtherDescriptor)).
+			AddRow(testCatalogUUID, []byte(testCatalogDescriptor)))
+
+	// Реквизит объекта: строковая колонка
+	expectColumns(mock, "_Reference42", "_Fld101", "_Fld101")
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE [ERP_TEST].dbo.[_Reference42] SET [_Fld101] = @p1 WHERE [_Fld101] <> @p1;")).
+		WithArgs("+7 000 000-00-00").
+		WillReturnResult(sqlmock.NewResult(0, 120))
+
+	// Реквизит табличной части составного типа: описание берётся из кэша
+	expectColumns(mock, "_Reference42_VT7", "_Fld102", "_Fld102_S")
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE [ERP_TEST].dbo.[_Reference42_VT7] SET [_Fld102_S] = @p1 WHERE [_Fld102_S] <> @p1 AND [_Fld102_TYPE] = 0x05;")).
+		WithArgs("").
+		WillReturnResult(sqlmock.NewResult(0, 30))
+
+	cli := &client{opts: ClientOptions{Server: "test"}, db: db}
+	actions, err := cli.Sanitize(context.Background(), SanitizeOptions{
+		Database: "ERP_TEST",
+		Profiles: []SanitizeProfile{{
+			Name: "personal-data",
+			Masks: []SanitizeMask{
+				{Object: "Справочник.Контрагенты", Attribute: "Телефон", Value: "+7 000 000-00-00"},
+				{Object: "Справочник.Контрагенты", Attribute: "КонтактнаяИнформация.Представление"},
+			},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+
+	want := []SanitizeAction{
+		{Profile: "personal-data", Action: SanitizeActionMask, Target: "Справочник.Контрагенты.Телефон", Table: "_Reference42", Column: "_Fld101", Rows: 120},
+		{Profile: "personal-data", Action: SanitizeActionMask, Target: "Справочник.Контрагенты.КонтактнаяИнформация.Представление", Table: "_Reference42_VT7", Column: "_Fld102_S", Rows: 30},
+	}
+	if len(actions) != len(want) {
+		t.Fatalf("Sanitize() actions = %+v, want %+v", actions, want)
+	}
+	for i := range want {
+		if actions[i] != want[i] {
+			t.Errorf("actions[%d] = %+v, want %+v", i, actions[i], want[i])
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("невыполненные ожидания: %v", err)
+	}
+}
+
+// readSanitizeFixture читает сжатую запись Params/Config из testdata/sanitize
+// (BinaryData в том виде, как её хранит СУБД: raw deflate, текст с BOM).
+func readSanitizeFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "sanitize", name))
+	if err != nil {
+		t.Fatalf("чтение %s: %v", name, err)
+	}
+	return data
+}
+
+// TestClient_Sanitize_Fixture проверяет разрешение имён по сжатым DBNames и описаниям
+// в формате 8.3: служебные записи DBNames, реквизиты-ссылки, одноимённые реквизиты
+// объекта, табличной части и другого справочника.
+func TestClient_Sanitize_Fixture(t *testing.T) {
+	const (
+		catalog     = "1c5b1fd8-3f8e-4ad5-9d6e-0a5b8c7a2e11"
+		bankAccount = "6d0c3a52-91b7-4e2a-b0d4-f1e8a4c3b9d2"
+		plan        = "9a4e7c13-52d8-4b6f-8e0a-3d7c1b5f2a64"
+	)
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка создания sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM [ERP_TEST].dbo.Params WHERE FileName = 'DBNames'")).
+		WillReturnRows(sqlmock.NewRows([]string{"BinaryData"}).AddRow(readSanitizeFixture(t, "DBNames")))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM [ERP_TEST].dbo.Config WHERE FileName IN (@p1, @p2)")).
+		WithArgs(catalog, bankAccount).
+		WillReturnRows(sqlmock.NewRows([]string{"FileName", "BinaryData"}).
+			AddRow(bankAccount, readSanitizeFixture(t, bankAccount)).
+			AddRow(catalog, readSanitizeFixture(t, catalog)))
+	expectColumns(mock, "_Reference42", "_Fld1101", "_Fld1101")
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE [ERP_TEST].dbo.[_Reference42] SET [_Fld1101] = @p1")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectColumns(mock, "_Reference42_VT1104", "_Fld1107", "_Fld1107")
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE [ERP_TEST].dbo.[_Reference42_VT1104] SET [_Fld1107] = @p1")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectColumns(mock, "_Reference42_VT1104", "_Fld1106", "_Fld1106_S")
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE [ERP_TEST].dbo.[_Reference42_VT1104] SET [_Fld1106_S] = @p1 WHERE [_Fld1106_S] <> @p1 AND [_Fld1106_TYPE] = 0x05;")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// Описания читаются заново: кэшируется только найденный объект
+	mock.ExpectQuery(regexp.QuoteMeta("FROM [ERP_TEST].dbo.Config WHERE FileName IN (@p1, @p2)")).
+		WithArgs(catalog, bankAccount).
+		WillReturnRows(sqlmock.NewRows([]string{"FileName", "BinaryData"}).
+			AddRow(bankAccount, readSanitizeFixture(t, bankAccount)))
+	expectColumns(mock, "_Reference57", "_Fld1201", "_Fld1201")
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE [ERP_TEST].dbo.[_Reference57] SET [_Fld1201] = @p1")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE t.name LIKE '[_]%ChngR%'")).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("_ReferenceChngR43"))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM [ERP_TEST].dbo.Config WHERE FileName IN (@p1)")).
+		WithArgs(plan).
+		WillReturnRows(sqlmock.NewRows([]string{"FileName", "BinaryData"}).AddRow(plan, readSanitizeFixture(t, plan)))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM [ERP_TEST].dbo.[_ReferenceChngR43] WHERE _NodeTRef = @p1;")).
+		WithArgs([]byte{0, 0, 0, 12}).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	cli := &client{opts: ClientOptions{Server: "test"}, db: db}
+	actions, err := cli.Sanitize(context.Background(), SanitizeOptions{
+		Database: "ERP_TEST",
+		Profiles: []SanitizeProfile{{
+			Name: "personal-data",
+			Masks: []SanitizeMask{
+				{Object: "Справочник.Контрагенты", Attribute: "Телефон"},
+				{Object: "Справочник.Контрагенты", Attribute: "КонтактнаяИнформация.Телефон"},
+				{Object: "Справочник.Контрагенты", Attribute: "КонтактнаяИнформация.Представление"},
+				{Object: "Справочник.БанковскиеСчета", Attribute: "Телефон"},
+			},
+			ExchangePlans: []string{"ПланОбмена.ОбменССайтом"},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+	if len(actions) != 5 {
+		t.Errorf("Sanitize() actions = %+v", actions)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("невыполненные ожидания: %v", err)
+	}
+}
+
+// TestSanitizer_Number проверяет ошибку при отсутствии записи в DBNames
+func TestSanitizer_Number(t *testing.T) {
+	names, err := dbstruct.ParseDBNames([]byte(testDBNames))
+	if err != nil {
+		t.Fatalf("ParseDBNames() error = %v", err)
+	}
+	s := &sanitizer{names: names}
+
+	if n, err := s.number(testCatalogUUID, dbstruct.KindReference); err != nil || n != 42 {
+		t.Errorf("number() = %d, %v, want 42", n, err)
+	}
+	if _, err := s.number(testCatalogUUID, dbstruct.KindVT); err == nil || !strings.Contains(err.Error(), "нет записи VT") {
+		t.Errorf("number() error = %v, want missing VT", err)
+	}
+}
+
+// TestClient_Sanitize_ExchangeAndJobs проверяет очистку очередей обмена,
+// отключение регламентных заданий и произвольные инструкции
+func TestClient_Sanitize_ExchangeAndJobs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка создания sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	changeTables := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"name"}).AddRow("_ReferenceChngR43").AddRow("_DocumentChngR50")
+	}
+
+	// Все планы обмена
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE t.name LIKE '[_]%ChngR%'")).WillReturnRows(changeTables())
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT_BIG(*) FROM [ERP_TEST].dbo.[_ReferenceChngR43];")).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(int64(10)))
+	mock.ExpectExec(regexp.QuoteMeta("TRUNCATE TABLE [ERP_TEST].dbo.[_ReferenceChngR43];")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT_BIG(*) FROM [ERP_TEST].dbo.[_DocumentChngR50];")).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(int64(5)))
+	mock.ExpectExec(regexp.QuoteMeta("TRUNCATE TABLE [ERP_TEST].dbo.[_DocumentChngR50];")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Конкретный план обмена: _NodeTRef = номер таблицы плана
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE t.name LIKE '[_]%ChngR%'")).WillReturnRows(changeTables())
+	expectDBNames(mock)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM [ERP_TEST].dbo.Config WHERE FileName IN (@p1)")).
+		WithArgs(testNodeUUID).
+		WillReturnRows(sqlmock.NewRows([]string{"FileName", "BinaryData"}).AddRow(testNodeUUID, []byte(testNodeDescriptor)))
+	nodeType := []byte{0, 0, 0, 5}
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM [ERP_TEST].dbo.[_ReferenceChngR43] WHERE _NodeTRef = @p1;")).
+		WithArgs(nodeType).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM [ERP_TEST].dbo.[_DocumentChngR50] WHERE _NodeTRef = @p1;")).
+		WithArgs(nodeType).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+
+	// Регламентные задания
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE t.name LIKE '[_]ScheduledJobs%'")).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("_ScheduledJobs123"))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE [ERP_TEST].dbo.[_ScheduledJobs123] SET _Use = 0x00 WHERE _Use <> 0x00;")).
+		WillReturnResult(sqlmock.NewResult(0, 17))
+
+	// Произвольная инструкция
+	mock.ExpectExec(regexp.QuoteMeta("EXEC [ERP_TEST].sys.sp_executesql @p1;")).
+		WithArgs("DELETE FROM _InfoRg900").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	cli := &client{opts: ClientOptions{Server: "test"}, db: db}
+	actions, err := cli.Sanitize(context.Background(), SanitizeOptions{
+		Database: "ERP_TEST",
+		Profiles: []SanitizeProfile{{
+			Name:                 "integrations",
+			ExchangePlans:        []string{"*", "ПланОбмена.ОбменССайтом"},
+			DisableScheduledJobs: true,
+			SQL:                  []string{"DELETE FROM _InfoRg900"},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Sanitize() error = %v", err)
+	}
+
+	wantRows := map[string]int64{
+		"*": 15,
+		"ПланОбмена.ОбменССайтом": 7,
+		"регламентные задания":    17,
+		"DELETE FROM _InfoRg900":  2,
+	}
+	if len(actions) != len(wantRows) {
+		t.Fatalf("Sanitize() actions = %+v", actions)
+	}
+	for _, a := range actions {
+		if a.Rows != wantRows[a.Target] {
+			t.Errorf("%s: rows = %d, want %d", a.Target, a.Rows, wantRows[a.Target])
+		}
+	}
+	if actions[0].Table != "_ReferenceChngR43, _DocumentChngR50" {
+		t.Errorf("actions[0].Table = %q", actions[0].Table)
+	}
+	if actions[2].Action != SanitizeActionScheduledJobs || actions[3].Action != SanitizeActionSQL {
+		t.Errorf("порядок действий: %+v", actions)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("невыполненные ожидания: %v", err)
+	}
+}
+
+// TestClient_Sanitize_Errors проверяет ошибки разрешения имён и выполнения
+func TestClient_Sanitize_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		profiles  []SanitizeProfile
+		setupMock func(mock sqlmock.Sqlmock)
+		wantErr   string
+		wantDone  int
+	}{
+		{
+			name: "объект не найден после выполненного действия",
+			profiles: []SanitizeProfile{
+				{Name: "jobs", DisableScheduledJobs: true},
+				{Name: "p", Masks: []SanitizeMask{{Object: "Справочник.Партнеры", Attribute: "Телефон"}}},
+			},
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("ScheduledJobs").WillReturnRows(sqlmock.NewRows([]string{"name"}))
+				expectDBNames(mock)
+				mock.ExpectQuery("FROM \\[ERP_TEST\\].dbo.Config").
+					WillReturnRows(sqlmock.NewRows([]string{"FileName", "BinaryData"}).
+						AddRow(testCatalogUUID, []byte(testCatalogDescriptor)))
+			},
+			wantErr:  "объект Партнеры не найден",
+			wantDone: 1,
+		},
+		{
+			name:     "ссылочный реквизит",
+			profiles: []SanitizeProfile{{Name: "p", Masks: []SanitizeMask{{Object: "Справочник.Контрагенты", Attribute: "Телефон"}}}},
+			setupMock: func(mock sqlmock.Sqlmock) {
+				expectDBNames(mock)
+				mock.ExpectQuery("FROM \\[ERP_TEST\\].dbo.Config").
+					WillReturnRows(sqlmock.NewRows([]string{"FileName", "BinaryData"}).
+						AddRow(testCatalogUUID, []byte(testCatalogDescriptor)))
+				expectColumns(mock, "_Reference42", "_Fld101")
+			},
+			wantErr: "нет колонки _Fld101",
+		},
+		{
+			name:     "не информационная база 1С",
+			profiles: []SanitizeProfile{{Name: "p", ExchangePlans: []string{"ПланОбмена.ОбменССайтом"}}},
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("ChngR").WillReturnRows(sqlmock.NewRows([]string{"name"}))
+				mock.ExpectQuery("DBNames").WillReturnRows(sqlmock.NewRows([]string{"BinaryData"}))
+			},
+			wantErr: "запись DBNames не найдена",
+		},
+		{
+			name:     "ошибка инструкции",
+			profiles: []SanitizeProfile{{Name: "p", SQL: []string{"DELETE FROM missing"}}},
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("sp_executesql").WillReturnError(errors.New("Invalid object name 'missing'"))
+			},
+			wantErr: "Invalid object name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("ошибка создания sqlmock: %v", err)
+			}
+			defer db.Close()
+
+			tt.setupMock(mock)
+
+			cli := &client{opts: ClientOptions{Server: "test"}, db: db}
+			actions, err := cli.Sanitize(context.Background(), SanitizeOptions{Database: "ERP_TEST", Profiles: tt.profiles})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Sanitize() error = %v, want %q", err, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), ErrMSSQLQuery) {
+				t.Errorf("Sanitize() error = %v, want code %s", err, ErrMSSQLQuery)
+			}
+			if len(actions) != tt.wantDone {
+				t.Errorf("Sanitize() actions = %+v, want %d", actions, tt.wantDone)
+			}
+		})
+	}
+
+	cli := &client{opts: ClientOptions{Server: "test"}}
+	if _, err := cli.Sanitize(context.Background(), SanitizeOptions{Database: "ERP_TEST"}); err == nil {
+		t.Error("Sanitize() без соединения должен вернуть ошибку")
+	}
+}
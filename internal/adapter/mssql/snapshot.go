@@ -0,0 +1,185 @@
+package mssql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Compile-time проверка реализации интерфейса
+var _ SnapshotManager = (*client)(nil)
+
+// multiUserResetTimeout — время на возврат базы в MULTI_USER после ошибки или отмены контекста.
+const multiUserResetTimeout = 30 * time.Second
+
+// snapshotDataFilesQuery выбирает файлы данных базы @p1: для каждого
+// создаётся разреженный файл снимка (журнал в снимок не входит).
+const snapshotDataFilesQuery = `
+	SELECT name, physical_name
+	FROM sys.master_files
+	WHERE database_id = DB_ID(@p1) AND type = 0
+	ORDER BY file_id;
+	`
+
+// snapshotListQuery выбирает снимки базы @p1.
+const snapshotListQuery = `
+	SELECT name, create_date
+	FROM sys.databases
+	WHERE source_database_id = DB_ID(@p1)
+	ORDER BY create_date;
+	`
+
+// snapshotSourceQuery возвращает базу-источник для базы @p1 (NULL — это не снимок).
+const snapshotSourceQuery = `
+	SELECT DB_NAME(source_database_id)
+	FROM sys.databases
+	WHERE name = @p1;
+	`
+
+// CreateSnapshot создаёт снимок snapshot базы database (CREATE DATABASE ... AS SNAPSHOT OF).
+// Разреженный файл снимка для каждого файла данных: <каталог файла>/<snapshot>_<логическое имя>.ss.
+func (c *client) CreateSnapshot(ctx context.Context, database, snapshot string) error {
+	if c.db == nil {
+		return fmt.Errorf("%s: connection not established", ErrMSSQLQuery)
+	}
+
+	rows, err := c.db.QueryContext(ctx, snapshotDataFilesQuery, database)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrMSSQLQuery, err)
+	}
+	defer rows.Close()
+
+	var files []string
+	for rows.Next() {
+		var logicalName, physicalName string
+		if err := rows.Scan(&logicalName, &physicalName); err != nil {
+			return fmt.Errorf("%s: %w", ErrMSSQLQuery, err)
+		}
+		sparse := joinServerPath(serverDir(physicalName), snapshot+"_"+logicalName+".ss")
+		files = append(files, fmt.Sprintf("(NAME = %s, FILENAME = %s)", quoteIdentifier(logicalName), quoteString(sparse)))
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("%s: %w", ErrMSSQLQuery, err)
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("%s: база данных %s не найдена", ErrMSSQLSnapshot, database)
+	}
+
+	stmt := fmt.Sprintf("CREATE DATABASE %s ON %s AS SNAPSHOT OF %s;",
+		quoteIdentifier(snapshot), strings.Join(files, ", "), quoteIdentifier(database))
+	if _, err := c.db.ExecContext(ctx, stmt); err != nil {
+		return fmt.Errorf("%s: создание снимка %s: %w", ErrMSSQLSnapshot, snapshot, err)
+	}
+	return nil
+}
+
+// RevertSnapshot возвращает базу database к состоянию снимка snapshot
+// (RESTORE DATABASE ... FROM DATABASE_SNAPSHOT). Подключения к базе
+// разрываются переводом в SINGLE_USER; база возвращается в MULTI_USER
+// и при ошибке восстановления. Журнал транзакций базы пересоздаётся,
+// цепочка резервных копий журналов прерывается.
+func (c *client) RevertSnapshot(ctx context.Context, database, snapshot string) error {
+	if c.db == nil {
+		return fmt.Errorf("%s: connection not established", ErrMSSQLQuery)
+	}
+
+	snapshots, err := c.ListSnapshots(ctx, database)
+	if err != nil {
+		return err
+	}
+	found := false
+	var others []string
+	for _, s := range snapshots {
+		if s.Name == snapshot {
+			found = true
+			continue
+		}
+		others = append(others, s.Name)
+	}
+	if !found {
+		return fmt.Errorf("%s: снимок %s базы %s не найден", ErrMSSQLSnapshot, snapshot, database)
+	}
+	// SQL Server возвращает базу к снимку, только если он единственный
+	if len(others) > 0 {
+		return fmt.Errorf("%s: у базы %s есть другие снимки (%s), их нужно удалить перед возвратом",
+			ErrMSSQLSnapshot, database, strings.Join(others, ", "))
+	}
+
+	db := quoteIdentifier(database)
+	stmt := fmt.Sprintf(`ALTER DATABASE %[1]s SET SINGLE_USER WITH ROLLBACK IMMEDIATE;
+BEGIN TRY
+	RESTORE DATABASE %[1]s FROM DATABASE_SNAPSHOT = %[2]s;
+END TRY
+BEGIN CATCH
+	ALTER DATABASE %[1]s SET MULTI_USER;
+	THROW;
+END CATCH;
+ALTER DATABASE %[1]s SET MULTI_USER;`, db, quoteString(snapshot))
+	if _, err := c.db.ExecContext(ctx, stmt); err != nil {
+		// Пакет мог прерваться до CATCH (отмена контекста): база не должна остаться в SINGLE_USER
+		resetCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), multiUserResetTimeout)
+		defer cancel()
+		_, _ = c.db.ExecContext(resetCtx, fmt.Sprintf("ALTER DATABASE %s SET MULTI_USER;", db)) //nolint:errcheck // основная ошибка важнее
+		return fmt.Errorf("%s: возврат базы %s к снимку %s: %w", ErrMSSQLSnapshot, database, snapshot, err)
+	}
+	return nil
+}
+
+// DropSnapshot удаляет снимок snapshot. Перед удалением проверяется,
+// что база с этим именем является снимком.
+func (c *client) DropSnapshot(ctx context.Context, snapshot string) error {
+	if c.db == nil {
+		return fmt.Errorf("%s: connection not established", ErrMSSQLQuery)
+	}
+
+	var source sql.NullString
+	err := c.db.QueryRowContext(ctx, snapshotSourceQuery, snapshot).Scan(&source)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrMSSQLQuery, err)
+	}
+	if !source.Valid {
+		return fmt.Errorf("%s: база данных %s не является снимком, удаление запрещено", ErrMSSQLSnapshot, snapshot)
+	}
+
+	if _, err := c.db.ExecContext(ctx, fmt.Sprintf("DROP DATABASE %s;", quoteIdentifier(snapshot))); err != nil {
+		return fmt.Errorf("%s: удаление снимка %s: %w", ErrMSSQLSnapshot, snapshot, err)
+	}
+	return nil
+}
+
+// ListSnapshots возвращает снимки базы database по sys.databases.
+func (c *client) ListSnapshots(ctx context.Context, database string) ([]DatabaseSnapshot, error) {
+	if c.db == nil {
+		return nil, fmt.Errorf("%s: connection not established", ErrMSSQLQuery)
+	}
+
+	rows, err := c.db.QueryContext(ctx, snapshotListQuery, database)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrMSSQLQuery, err)
+	}
+	defer rows.Close()
+
+	var snapshots []DatabaseSnapshot
+	for rows.Next() {
+		s := DatabaseSnapshot{Database: database}
+		if err := rows.Scan(&s.Name, &s.CreatedAt); err != nil {
+			return nil, fmt.Errorf("%s: %w", ErrMSSQLQuery, err)
+		}
+		snapshots = append(snapshots, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrMSSQLQuery, err)
+	}
+	return snapshots, nil
+}
+
+// quoteString экранирует строковый литерал T-SQL (N'...').
+func quoteString(s string) string {
+	return "N'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,194 @@
+package mssql
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func expectSnapshots(mock sqlmock.Sqlmock, names ...string) {
+	rows := sqlmock.NewRows([]string{"name", "create_date"})
+	for _, n := range names {
+		rows.AddRow(n, time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC))
+	}
+	mock.ExpectQuery("WHERE source_database_id = DB_ID").WithArgs("ERP_TEST").WillReturnRows(rows)
+}
+
+// TestClient_CreateSnapshot проверяет размещение разреженных файлов рядом с файлами данных
+func TestClient_CreateSnapshot(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка создания sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("FROM sys.master_files").
+		WithArgs("ERP_TEST").
+		WillReturnRows(sqlmock.NewRows([]string{"name", "physical_name"}).
+			AddRow("ERP_TEST", `D:\SQLData\ERP_TEST.mdf`).
+			AddRow("ERP_TEST_2", "/var/opt/mssql/data/ERP_TEST_2.ndf"))
+	mock.ExpectExec(regexp.QuoteMeta(
+		`CREATE DATABASE [ERP_TEST_snapshot] ON ` +
+			`(NAME = [ERP_TEST], FILENAME = N'D:\SQLData\ERP_TEST_snapshot_ERP_TEST.ss'), ` +
+			`(NAME = [ERP_TEST_2], FILENAME = N'/var/opt/mssql/data/ERP_TEST_snapshot_ERP_TEST_2.ss') ` +
+			`AS SNAPSHOT OF [ERP_TEST];`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	cli := &client{db: db}
+	if err := cli.CreateSnapshot(context.Background(), "ERP_TEST", "ERP_TEST_snapshot"); err != nil {
+		t.Fatalf("CreateSnapshot() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("невыполненные ожидания: %v", err)
+	}
+}
+
+// TestClient_CreateSnapshot_NoDatabase проверяет ошибку для отсутствующей базы
+func TestClient_CreateSnapshot_NoDatabase(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка создания sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("FROM sys.master_files").
+		WithArgs("ERP_TEST").
+		WillReturnRows(sqlmock.NewRows([]string{"name", "physical_name"}))
+
+	cli := &client{db: db}
+	err = cli.CreateSnapshot(context.Background(), "ERP_TEST", "ERP_TEST_snapshot")
+	if err == nil || !strings.Contains(err.Error(), ErrMSSQLSnapshot) {
+		t.Errorf("CreateSnapshot() error = %v, want %s", err, ErrMSSQLSnapshot)
+	}
+}
+
+// TestClient_RevertSnapshot проверяет возврат базы к снимку
+func TestClient_RevertSnapshot(t *testing.T) {
+	tests := []struct {
+		name      string
+		snapshots []string
+		execErr   error
+		wantErr   string
+	}{
+		{name: "единственный снимок", snapshots: []string{"ERP_TEST_snapshot"}},
+		{name: "снимок не найден", wantErr: "не найден"},
+		{name: "есть другие снимки", snapshots: []string{"ERP_TEST_old", "ERP_TEST_snapshot"}, wantErr: "ERP_TEST_old"},
+		{name: "ошибка RESTORE", snapshots: []string{"ERP_TEST_snapshot"}, execErr: errors.New("database is in use"), wantErr: "database is in use"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("ошибка создания sqlmock: %v", err)
+			}
+			defer db.Close()
+
+			expectSnapshots(mock, tt.snapshots...)
+			if tt.wantErr == "" || tt.execErr != nil {
+				exec := mock.ExpectExec(regexp.QuoteMeta(
+					"RESTORE DATABASE [ERP_TEST] FROM DATABASE_SNAPSHOT = N'ERP_TEST_snapshot';"))
+				if tt.execErr != nil {
+					exec.WillReturnError(tt.execErr)
+					mock.ExpectExec(regexp.QuoteMeta("ALTER DATABASE [ERP_TEST] SET MULTI_USER;")).
+						WillReturnResult(sqlmock.NewResult(0, 0))
+				} else {
+					exec.WillReturnResult(sqlmock.NewResult(0, 0))
+				}
+			}
+
+			cli := &client{db: db}
+			err = cli.RevertSnapshot(context.Background(), "ERP_TEST", "ERP_TEST_snapshot")
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("RevertSnapshot() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("RevertSnapshot() error = %v, want %q", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("невыполненные ожидания: %v", err)
+			}
+		})
+	}
+}
+
+// TestClient_DropSnapshot проверяет, что удаляются только снимки
+func TestClient_DropSnapshot(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  any // nil — обычная база (source_database_id IS NULL)
+		noRows  bool
+		wantErr bool
+		drop    bool
+	}{
+		{name: "снимок", source: "ERP_TEST", drop: true},
+		{name: "снимка нет", noRows: true},
+		{name: "обычная база", source: nil, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("ошибка создания sqlmock: %v", err)
+			}
+			defer db.Close()
+
+			rows := sqlmock.NewRows([]string{"source"})
+			if !tt.noRows {
+				rows.AddRow(tt.source)
+			}
+			mock.ExpectQuery("SELECT DB_NAME\\(source_database_id\\)").
+				WithArgs("ERP_TEST_snapshot").
+				WillReturnRows(rows)
+			if tt.drop {
+				mock.ExpectExec(regexp.QuoteMeta("DROP DATABASE [ERP_TEST_snapshot];")).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			}
+
+			cli := &client{db: db}
+			err = cli.DropSnapshot(context.Background(), "ERP_TEST_snapshot")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DropSnapshot() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("невыполненные ожидания: %v", err)
+			}
+		})
+	}
+}
+
+// TestClient_ListSnapshots проверяет чтение снимков базы
+func TestClient_ListSnapshots(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка создания sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	expectSnapshots(mock, "ERP_TEST_snapshot")
+
+	cli := &client{db: db}
+	got, err := cli.ListSnapshots(context.Background(), "ERP_TEST")
+	if err != nil {
+		t.Fatalf("ListSnapshots() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Name != "ERP_TEST_snapshot" || got[0].Database != "ERP_TEST" || got[0].CreatedAt.IsZero() {
+		t.Errorf("ListSnapshots() = %+v", got)
+	}
+
+	if _, err := (&client{}).ListSnapshots(context.Background(), "ERP_TEST"); err == nil {
+		t.Error("ListSnapshots() без соединения должен вернуть ошибку")
+	}
+}
+
+func TestQuoteString(t *testing.T) {
+	if got := quoteString("O'Brien"); got != "N'O''Brien'" {
+		t.Errorf("quoteString() = %s", got)
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"strings"
 	"time"
 
 	"github.com/Kargones/apk-ci/internal/util/runner"
@@ -58,7 +57,8 @@ func (c *Creator1cv8) CreateDB(ctx context.Context, opts CreateDBOptions) (*Crea
 	// Отключаем GUI диалоги
 	addDisableParam(&r)
 
-	// Перенаправляем вывод в файл
+	// Код завершения и вывод платформы пишутся в файлы
+	r.Params = append(r.Params, "/DumpResult")
 	r.Params = append(r.Params, "/Out")
 
 	// Устанавливаем таймаут
@@ -86,20 +86,14 @@ func (c *Creator1cv8) CreateDB(ctx context.Context, opts CreateDBOptions) (*Crea
 	// Извлекаем сообщения из вывода
 	output := string(r.FileOut)
 
-	// Проверка успеха по отсутствию ошибок и сообщениям платформы
-	if err == nil && (strings.Contains(output, "Информационная база создана") ||
-		strings.Contains(output, "Infobase has been created") ||
-		strings.Contains(output, "База данных создана") ||
-		len(output) < 100) {
+	// Успех определяется кодом завершения и /DumpResult
+	if err == nil {
 		result.Success = true
 		log.Info("Информационная база создана успешно",
 			slog.String("db_path", opts.DbPath),
 			slog.Int64("duration_ms", result.DurationMs))
 	} else {
 		result.Success = false
-		if err == nil {
-			err = fmt.Errorf("создание базы не завершено успешно: %s", trimOutput(output))
-		}
 		log.Error("Ошибка создания информационной базы",
 			slog.String("output", trimOutput(output)),
 			slog.Int64("duration_ms", result.DurationMs))
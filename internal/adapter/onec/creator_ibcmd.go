@@ -5,10 +5,8 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"strings"
 	"time"
 
-	"github.com/Kargones/apk-ci/internal/constants"
 	"github.com/Kargones/apk-ci/internal/util/runner"
 )
 
@@ -84,19 +82,15 @@ func (c *CreatorIbcmd) CreateDB(ctx context.Context, opts CreateDBOptions) (*Cre
 		DurationMs:    time.Since(start).Milliseconds(),
 	}
 
-	// Проверка успеха по сообщениям
+	// ibcmd успешен если код завершения нулевой
 	output := string(r.ConsoleOut)
-	if err == nil && (strings.Contains(output, constants.SearchMsgBaseCreateOk) ||
-		strings.Contains(output, constants.SearchMsgBaseCreateOkEn)) {
+	if err == nil {
 		result.Success = true
 		log.Info("Информационная база через ibcmd создана успешно",
 			slog.String("db_path", opts.DbPath),
 			slog.Int64("duration_ms", result.DurationMs))
 	} else {
 		result.Success = false
-		if err == nil {
-			err = fmt.Errorf("создание базы не завершено успешно: %s", trimOutput(output))
-		}
 		log.Error("Ошибка создания информационной базы через ibcmd",
 			slog.String("output", trimOutput(output)),
 			slog.Int64("duration_ms", result.DurationMs))
@@ -0,0 +1,134 @@
+package dbstruct
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Node — элемент скобочного формата 1С: список {a,b,{c}} либо значение.
+type Node struct {
+	// Value — значение атома или строки (для строки — без кавычек)
+	Value string
+	// Quoted — значение было строкой в кавычках
+	Quoted bool
+	// List — элементы списка (nil для значения)
+	List []*Node
+	// IsList — узел является списком
+	IsList bool
+
+	parent *Node
+}
+
+// ParseBraces разбирает текст в скобочном формате 1С.
+// Строки в кавычках могут содержать переводы строк, кавычка экранируется удвоением.
+func ParseBraces(data []byte) (*Node, error) {
+	data = bytes.TrimPrefix(data, utf8BOM)
+	p := &braceParser{data: data}
+	p.skipSpace()
+	if p.pos >= len(p.data) || p.data[p.pos] != '{' {
+		return nil, fmt.Errorf("ожидается '{' в позиции %d", p.pos)
+	}
+	root, err := p.parseList(nil)
+	if err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+type braceParser struct {
+	data []byte
+	pos  int
+}
+
+func (p *braceParser) skipSpace() {
+	for p.pos < len(p.data) {
+		switch p.data[p.pos] {
+		case ' ', '\t', '\r', '\n':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+// parseList разбирает список, начиная с открывающей скобки.
+func (p *braceParser) parseList(parent *Node) (*Node, error) {
+	node := &Node{IsList: true, parent: parent}
+	p.pos++ // '{'
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.data) {
+			return nil, fmt.Errorf("неожиданный конец данных: не закрыт список")
+		}
+		switch c := p.data[p.pos]; c {
+		case '}':
+			p.pos++
+			return node, nil
+		case ',':
+			p.pos++
+			continue
+		case '{':
+			child, err := p.parseList(node)
+			if err != nil {
+				return nil, err
+			}
+			node.List = append(node.List, child)
+		case '"':
+			value, err := p.parseString()
+			if err != nil {
+				return nil, err
+			}
+			node.List = append(node.List, &Node{Value: value, Quoted: true, parent: node})
+		default:
+			start := p.pos
+			for p.pos < len(p.data) && p.data[p.pos] != ',' && p.data[p.pos] != '}' {
+				p.pos++
+			}
+			value := strings.TrimSpace(string(p.data[start:p.pos]))
+			node.List = append(node.List, &Node{Value: value, parent: node})
+		}
+	}
+}
+
+// parseString разбирает строку в кавычках с экранированием "".
+func (p *braceParser) parseString() (string, error) {
+	var sb strings.Builder
+	p.pos++ // открывающая кавычка
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		p.pos++
+		if c != '"' {
+			sb.WriteByte(c)
+			continue
+		}
+		if p.pos < len(p.data) && p.data[p.pos] == '"' {
+			sb.WriteByte('"')
+			p.pos++
+			continue
+		}
+		return sb.String(), nil
+	}
+	return "", fmt.Errorf("неожиданный конец данных: не закрыта строка")
+}
+
+// Walk обходит дерево в порядке следования элементов; fn вызывается для каждого списка.
+func (n *Node) Walk(fn func(list *Node)) {
+	if !n.IsList {
+		return
+	}
+	fn(n)
+	for _, child := range n.List {
+		child.Walk(fn)
+	}
+}
+
+// Contains сообщает, является ли n предком other (или совпадает с ним).
+func (n *Node) Contains(other *Node) bool {
+	for cur := other; cur != nil; cur = cur.parent {
+		if cur == n {
+			return true
+		}
+	}
+	return false
+}
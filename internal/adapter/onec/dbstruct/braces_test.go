@@ -0,0 +1,46 @@
+package dbstruct
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBraces(t *testing.T) {
+	data := append([]byte{0xEF, 0xBB, 0xBF}, "{1,\r\n{\"Строка, с запятой\",\"кавычка \"\"x\"\"\"},{},\n ab-c }"...)
+
+	root, err := ParseBraces(data)
+	require.NoError(t, err)
+	require.True(t, root.IsList)
+	require.Len(t, root.List, 4)
+
+	assert.Equal(t, "1", root.List[0].Value)
+	assert.False(t, root.List[0].Quoted)
+
+	inner := root.List[1]
+	require.Len(t, inner.List, 2)
+	assert.Equal(t, "Строка, с запятой", inner.List[0].Value)
+	assert.True(t, inner.List[0].Quoted)
+	assert.Equal(t, `кавычка "x"`, inner.List[1].Value)
+
+	assert.True(t, root.List[2].IsList)
+	assert.Empty(t, root.List[2].List)
+	assert.Equal(t, "ab-c", root.List[3].Value)
+
+	assert.True(t, root.Contains(inner.List[1]))
+	assert.False(t, inner.Contains(root.List[3]))
+}
+
+func TestParseBraces_Errors(t *testing.T) {
+	for name, data := range map[string]string{
+		"не список":         `"a"`,
+		"не закрыт список":  `{1,{2}`,
+		"не закрыта строка": `{"abc}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseBraces([]byte(data))
+			assert.Error(t, err)
+		})
+	}
+}
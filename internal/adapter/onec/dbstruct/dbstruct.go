@@ -0,0 +1,157 @@
+// Package dbstruct разбирает структуру хранения информационной базы 1С:Предприятие 8
+// в СУБД без использования платформы:
+//   - DBNames (таблица Params) — соответствие UUID объектов метаданных
+//     и номеров таблиц/полей: {uuid,"Reference",42} → _Reference42,
+//     {uuid,"VT",56} → _Reference42_VT56, {uuid,"Fld",1234} → _Fld1234;
+//   - описания объектов метаданных (таблица Config, FileName = UUID объекта) —
+//     имена объекта, его реквизитов и табличных частей.
+//
+// Данные хранятся в скобочном формате, сжатом raw deflate.
+package dbstruct
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Виды записей DBNames, используемые при обезличивании.
+const (
+	// KindReference — справочник (_Reference)
+	KindReference = "Reference"
+	// KindDocument — документ (_Document)
+	KindDocument = "Document"
+	// KindNode — план обмена (_Node)
+	KindNode = "Node"
+	// KindInfoRg — регистр сведений (_InfoRg)
+	KindInfoRg = "InfoRg"
+	// KindVT — табличная часть (_VT)
+	KindVT = "VT"
+	// KindFld — реквизит, измерение или ресурс (_Fld)
+	KindFld = "Fld"
+)
+
+// utf8BOM — маркер порядка байтов, которым начинаются тексты 1С.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// uuidPattern — UUID объекта метаданных.
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// objectKinds сопоставляет виды объектов метаданных (рус./англ.) видам DBNames.
+var objectKinds = map[string]string{
+	"справочник":          KindReference,
+	"catalog":             KindReference,
+	"документ":            KindDocument,
+	"document":            KindDocument,
+	"планобмена":          KindNode,
+	"exchangeplan":        KindNode,
+	"регистрсведений":     KindInfoRg,
+	"informationregister": KindInfoRg,
+}
+
+// Decode распаковывает содержимое BinaryData таблиц Params/Config.
+// Несжатые данные возвращаются как есть; BOM удаляется.
+func Decode(data []byte) []byte {
+	// Результат распаковки принимается, только если это текст в скобочном формате:
+	// несжатый текст иногда распаковывается без ошибки в мусор
+	if inflated, err := io.ReadAll(flate.NewReader(bytes.NewReader(data))); err == nil && isBraceText(inflated) {
+		data = inflated
+	}
+	return bytes.TrimPrefix(data, utf8BOM)
+}
+
+// isBraceText проверяет, начинается ли текст (после BOM и пробелов) с '{'.
+func isBraceText(data []byte) bool {
+	data = bytes.TrimLeft(bytes.TrimPrefix(data, utf8BOM), " \t\r\n")
+	return len(data) > 0 && data[0] == '{'
+}
+
+// ParseObjectName разбирает имя объекта метаданных вида "Справочник.Контрагенты"
+// и возвращает вид DBNames и имя объекта.
+func ParseObjectName(fullName string) (kind, name string, err error) {
+	prefix, name, ok := strings.Cut(strings.TrimSpace(fullName), ".")
+	if !ok || name == "" {
+		return "", "", fmt.Errorf("объект %q должен иметь вид Вид.Имя", fullName)
+	}
+	kind, ok = objectKinds[strings.ToLower(prefix)]
+	if !ok {
+		return "", "", fmt.Errorf("вид объекта %q не поддерживается (справочник, документ, план обмена, регистр сведений)", prefix)
+	}
+	return kind, name, nil
+}
+
+// TableName возвращает имя основной таблицы объекта: _Reference42.
+func TableName(kind string, number int) string {
+	return "_" + kind + strconv.Itoa(number)
+}
+
+// TabularTableName возвращает имя таблицы табличной части: _Reference42_VT56.
+func TabularTableName(ownerTable string, number int) string {
+	return ownerTable + "_VT" + strconv.Itoa(number)
+}
+
+// FieldColumn возвращает имя колонки реквизита: _Fld1234.
+// Для составного типа строковая часть хранится в колонке с суффиксом _S.
+func FieldColumn(number int) string {
+	return "_Fld" + strconv.Itoa(number)
+}
+
+// DBNames — разобранная запись DBNames.
+type DBNames struct {
+	numbers map[string]map[string]int // uuid → вид → номер
+	byKind  map[string][]string       // вид → UUID в порядке следования
+}
+
+// ParseDBNames разбирает содержимое записи DBNames (сжатое или распакованное).
+func ParseDBNames(data []byte) (*DBNames, error) {
+	root, err := ParseBraces(Decode(data))
+	if err != nil {
+		return nil, fmt.Errorf("разбор DBNames: %w", err)
+	}
+
+	names := &DBNames{
+		numbers: make(map[string]map[string]int),
+		byKind:  make(map[string][]string),
+	}
+	root.Walk(func(list *Node) {
+		// Запись: {uuid,"Вид",номер}
+		if len(list.List) != 3 {
+			return
+		}
+		id, kind, num := list.List[0], list.List[1], list.List[2]
+		if id.IsList || !uuidPattern.MatchString(id.Value) || !kind.Quoted || num.IsList {
+			return
+		}
+		n, convErr := strconv.Atoi(num.Value)
+		if convErr != nil {
+			return
+		}
+		uuid := strings.ToLower(id.Value)
+		if names.numbers[uuid] == nil {
+			names.numbers[uuid] = make(map[string]int)
+		}
+		if _, dup := names.numbers[uuid][kind.Value]; !dup {
+			names.numbers[uuid][kind.Value] = n
+			names.byKind[kind.Value] = append(names.byKind[kind.Value], uuid)
+		}
+	})
+	if len(names.numbers) == 0 {
+		return nil, fmt.Errorf("разбор DBNames: записи не найдены")
+	}
+	return names, nil
+}
+
+// Number возвращает номер таблицы или поля объекта uuid вида kind.
+func (d *DBNames) Number(uuid, kind string) (int, bool) {
+	n, ok := d.numbers[strings.ToLower(uuid)][kind]
+	return n, ok
+}
+
+// UUIDs возвращает UUID объектов вида kind.
+func (d *DBNames) UUIDs(kind string) []string {
+	return d.byKind[kind]
+}
@@ -0,0 +1,103 @@
+package dbstruct
+
+import (
+	"bytes"
+	"compress/flate"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testDBNames — запись DBNames справочника Контрагенты с табличной частью.
+const testDBNames = `{8,
+{aaaaaaaa-0000-0000-0000-000000000001,"Reference",42},
+{bbbbbbbb-0000-0000-0000-000000000001,"Fld",101},
+{bbbbbbbb-0000-0000-0000-000000000002,"Fld",102},
+{cccccccc-0000-0000-0000-000000000001,"VT",7},
+{bbbbbbbb-0000-0000-0000-000000000003,"Fld",103},
+{bbbbbbbb-0000-0000-0000-000000000004,"Fld",104},
+{dddddddd-0000-0000-0000-000000000001,"Node",5},
+{AAAAAAAA-0000-0000-0000-000000000001,"ReferenceChngR",43}
+}`
+
+func deflate(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.DefaultCompression)
+	require.NoError(t, err)
+	_, err = w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestDecode(t *testing.T) {
+	text := append([]byte{0xEF, 0xBB, 0xBF}, "{1,2}"...)
+	assert.Equal(t, "{1,2}", string(Decode(deflate(t, text))), "сжатые данные")
+	assert.Equal(t, "{1,2}", string(Decode(text)), "несжатые данные")
+}
+
+func TestParseDBNames(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"сжатые":   deflate(t, []byte(testDBNames)),
+		"несжатые": []byte(testDBNames),
+	} {
+		t.Run(name, func(t *testing.T) {
+			names, err := ParseDBNames(data)
+			require.NoError(t, err)
+
+			n, ok := names.Number("aaaaaaaa-0000-0000-0000-000000000001", KindReference)
+			require.True(t, ok)
+			assert.Equal(t, 42, n)
+
+			n, ok = names.Number("AAAAAAAA-0000-0000-0000-000000000001", "ReferenceChngR")
+			require.True(t, ok, "UUID сравниваются без учёта регистра")
+			assert.Equal(t, 43, n)
+
+			_, ok = names.Number("aaaaaaaa-0000-0000-0000-000000000001", KindDocument)
+			assert.False(t, ok)
+
+			assert.Equal(t, []string{"dddddddd-0000-0000-0000-000000000001"}, names.UUIDs(KindNode))
+			assert.Len(t, names.UUIDs(KindFld), 4)
+		})
+	}
+
+	_, err := ParseDBNames([]byte("{0}"))
+	assert.Error(t, err)
+}
+
+func TestParseObjectName(t *testing.T) {
+	tests := []struct {
+		in       string
+		wantKind string
+		wantName string
+		wantErr  bool
+	}{
+		{in: "Справочник.Контрагенты", wantKind: KindReference, wantName: "Контрагенты"},
+		{in: "Document.SalesOrder", wantKind: KindDocument, wantName: "SalesOrder"},
+		{in: "ПланОбмена.ОбменССайтом", wantKind: KindNode, wantName: "ОбменССайтом"},
+		{in: "РегистрСведений.КонтактнаяИнформация", wantKind: KindInfoRg, wantName: "КонтактнаяИнформация"},
+		{in: "Контрагенты", wantErr: true},
+		{in: "РегистрНакопления.Остатки", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			kind, name, err := ParseObjectName(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantKind, kind)
+			assert.Equal(t, tt.wantName, name)
+		})
+	}
+}
+
+func TestTableNames(t *testing.T) {
+	table := TableName(KindReference, 42)
+	assert.Equal(t, "_Reference42", table)
+	assert.Equal(t, "_Reference42_VT7", TabularTableName(table, 7))
+	assert.Equal(t, "_Fld101", FieldColumn(101))
+}
@@ -0,0 +1,133 @@
+package dbstruct
+
+import (
+	"fmt"
+	"strings"
+)
+
+// declaration — объявление объекта метаданных в описании: список, в котором
+// за узлом, оканчивающимся UUID объявляемого объекта, следует его имя:
+// {0,{3,0,<uuid>},"Имя",{1,"ru","Синоним"},...}.
+type declaration struct {
+	uuid string
+	name string
+	node *Node
+}
+
+// Descriptor — описание объекта метаданных (запись таблицы Config).
+type Descriptor struct {
+	// UUID — идентификатор объекта
+	UUID string
+	// Name — имя объекта (без вида)
+	Name string
+
+	decls []declaration
+	self  *Node // объявление самого объекта
+}
+
+// ParseDescriptor разбирает описание объекта uuid (сжатое или распакованное).
+// Имя объекта — имя объявления с собственным UUID.
+func ParseDescriptor(uuid string, data []byte) (*Descriptor, error) {
+	root, err := ParseBraces(Decode(data))
+	if err != nil {
+		return nil, fmt.Errorf("разбор описания %s: %w", uuid, err)
+	}
+
+	d := &Descriptor{UUID: strings.ToLower(uuid)}
+	seen := make(map[string]bool)
+	root.Walk(func(list *Node) {
+		for i := 0; i+1 < len(list.List); i++ {
+			id, name := list.List[i], list.List[i+1]
+			if !id.IsList || len(id.List) == 0 || !name.Quoted || name.Value == "" {
+				continue
+			}
+			last := id.List[len(id.List)-1]
+			if last.IsList || !uuidPattern.MatchString(last.Value) {
+				continue
+			}
+			declUUID := strings.ToLower(last.Value)
+			if seen[declUUID] {
+				continue
+			}
+			seen[declUUID] = true
+			d.decls = append(d.decls, declaration{uuid: declUUID, name: name.Value, node: list})
+			if declUUID == d.UUID {
+				d.Name, d.self = name.Value, list
+			}
+		}
+	})
+	if d.Name == "" {
+		return nil, fmt.Errorf("разбор описания %s: не найдено имя объекта", uuid)
+	}
+	return d, nil
+}
+
+// Field находит реквизит по пути "Реквизит" или "ТабличнаяЧасть.Реквизит".
+// Возвращает UUID табличной части (пусто для реквизита объекта) и UUID реквизита.
+func (d *Descriptor) Field(path string, names *DBNames) (tabularUUID, fieldUUID string, err error) {
+	tabular, attribute, nested := strings.Cut(path, ".")
+	if !nested {
+		attribute, tabular = tabular, ""
+	}
+
+	// Блок табличной части — ближайший предок объявления, содержащий объявления реквизитов:
+	// заголовок табличной части реквизитов не содержит
+	var blocks []*Node
+	var tabularBlock *Node
+	for _, vt := range d.decls {
+		if _, ok := names.Number(vt.uuid, KindVT); !ok {
+			continue
+		}
+		block := d.fieldsBlock(vt.node, names)
+		if block == nil {
+			continue
+		}
+		blocks = append(blocks, block)
+		if tabular != "" && strings.EqualFold(vt.name, tabular) {
+			tabularUUID, tabularBlock = vt.uuid, block
+		}
+	}
+	if tabular != "" && tabularBlock == nil {
+		return "", "", fmt.Errorf("табличная часть %s.%s не найдена", d.Name, tabular)
+	}
+
+	for _, fld := range d.decls {
+		if _, ok := names.Number(fld.uuid, KindFld); !ok || !strings.EqualFold(fld.name, attribute) {
+			continue
+		}
+		if tabularBlock != nil {
+			if tabularBlock.Contains(fld.node) {
+				return tabularUUID, fld.uuid, nil
+			}
+			continue
+		}
+		if !containedInAny(blocks, fld.node) {
+			return "", fld.uuid, nil
+		}
+	}
+	return "", "", fmt.Errorf("реквизит %s.%s не найден", d.Name, path)
+}
+
+// fieldsBlock возвращает ближайшего предка node, содержащего объявление реквизита.
+// Предки, включающие объявление самого объекта, не рассматриваются:
+// у табличной части без реквизитов блока нет.
+func (d *Descriptor) fieldsBlock(node *Node, names *DBNames) *Node {
+	for block := node.parent; block != nil && !block.Contains(d.self); block = block.parent {
+		for _, fld := range d.decls {
+			if _, ok := names.Number(fld.uuid, KindFld); ok && block.Contains(fld.node) {
+				return block
+			}
+		}
+	}
+	return nil
+}
+
+// containedInAny сообщает, лежит ли node внутри одного из blocks.
+func containedInAny(blocks []*Node, node *Node) bool {
+	for _, b := range blocks {
+		if b.Contains(node) {
+			return true
+		}
+	}
+	return false
+}
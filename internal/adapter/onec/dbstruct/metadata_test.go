@@ -0,0 +1,72 @@
+package dbstruct
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testDescriptor — описание справочника Контрагенты: реквизиты Телефон и ИНН,
+// табличная часть КонтактнаяИнформация с реквизитами Телефон и Представление.
+const testDescriptor = `{1,
+{70,{1,{1,{0,{0,0,aaaaaaaa-0000-0000-0000-000000000001},"Контрагенты",{1,"ru","Контрагенты"},""}}}},
+{888744e1-b616-11d4-9436-004095e12fc7,2,
+{{0,{0,{1,0,bbbbbbbb-0000-0000-0000-000000000001},"Телефон",{1,"ru","Телефон"},""}},{"Pattern",{"S",20}}},
+{{0,{0,{1,0,bbbbbbbb-0000-0000-0000-000000000002},"ИНН",{1,"ru","ИНН"},""}},{"Pattern",{"S",12}}}
+},
+{932159f9-95b2-4e76-a8dd-8849fe5c5ded,1,
+{{0,{0,{1,0,cccccccc-0000-0000-0000-000000000001},"КонтактнаяИнформация",{1,"ru",""},""}},
+{888744e1-b616-11d4-9436-004095e12fc7,2,
+{{0,{0,{1,0,bbbbbbbb-0000-0000-0000-000000000003},"Телефон",{1,"ru",""},""}}},
+{{0,{0,{1,0,bbbbbbbb-0000-0000-0000-000000000004},"Представление",{1,"ru",""},""}}}
+}
+}
+}
+}`
+
+func TestParseDescriptor(t *testing.T) {
+	d, err := ParseDescriptor("AAAAAAAA-0000-0000-0000-000000000001", []byte(testDescriptor))
+	require.NoError(t, err)
+	assert.Equal(t, "Контрагенты", d.Name)
+
+	_, err = ParseDescriptor("eeeeeeee-0000-0000-0000-000000000001", []byte(testDescriptor))
+	assert.ErrorContains(t, err, "не найдено имя объекта")
+}
+
+func TestDescriptor_Field(t *testing.T) {
+	names, err := ParseDBNames([]byte(testDBNames))
+	require.NoError(t, err)
+	d, err := ParseDescriptor("aaaaaaaa-0000-0000-0000-000000000001", []byte(testDescriptor))
+	require.NoError(t, err)
+
+	tests := []struct {
+		path        string
+		wantTabular string
+		wantField   string
+		wantErr     string
+	}{
+		{path: "Телефон", wantField: "bbbbbbbb-0000-0000-0000-000000000001"},
+		{path: "инн", wantField: "bbbbbbbb-0000-0000-0000-000000000002"},
+		{
+			path:        "КонтактнаяИнформация.Телефон",
+			wantTabular: "cccccccc-0000-0000-0000-000000000001",
+			wantField:   "bbbbbbbb-0000-0000-0000-000000000003",
+		},
+		{path: "Представление", wantErr: "реквизит Контрагенты.Представление не найден"},
+		{path: "КонтактнаяИнформация.ИНН", wantErr: "не найден"},
+		{path: "Адреса.Телефон", wantErr: "табличная часть Контрагенты.Адреса не найдена"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			tabular, field, err := d.Field(tt.path, names)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantTabular, tabular)
+			assert.Equal(t, tt.wantField, field)
+		})
+	}
+}
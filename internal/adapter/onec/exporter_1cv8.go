@@ -5,7 +5,6 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"strings"
 	"time"
 
 	"github.com/Kargones/apk-ci/internal/util/runner"
@@ -63,7 +62,8 @@ func (e *Exporter1cv8) Export(ctx context.Context, opts ExportOptions) (*ExportR
 	// Отключаем GUI диалоги
 	addDisableParam(&r)
 
-	// Перенаправляем вывод в файл
+	// Код завершения и вывод платформы пишутся в файлы
+	r.Params = append(r.Params, "/DumpResult")
 	r.Params = append(r.Params, "/Out")
 
 	// Устанавливаем таймаут
@@ -90,19 +90,13 @@ func (e *Exporter1cv8) Export(ctx context.Context, opts ExportOptions) (*ExportR
 	output := string(r.FileOut)
 	result.Messages = extractMessages(output)
 
-	// Проверка успеха по сообщениям (русский и английский варианты)
-	if err == nil && (strings.Contains(output, "Выгрузка конфигурации завершена") ||
-		strings.Contains(output, "Configuration dump completed") ||
-		strings.Contains(output, "Выгрузка завершена") ||
-		len(output) < 100) { // Успешный вывод обычно минимален
+	// Успех определяется кодом завершения и /DumpResult
+	if err == nil {
 		result.Success = true
 		log.Info("Выгрузка конфигурации завершена успешно",
 			slog.Int64("duration_ms", result.DurationMs))
 	} else {
 		result.Success = false
-		if err == nil {
-			err = fmt.Errorf("выгрузка не завершена успешно: %s", trimOutput(output))
-		}
 		log.Error("Ошибка выгрузки конфигурации",
 			slog.String("output", trimOutput(output)),
 			slog.Int64("duration_ms", result.DurationMs))
@@ -12,6 +12,7 @@ import (
 	"fmt"
 
 	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/pkg/platform"
 )
 
 // Константы для значений реализаций (goconst fix).
@@ -20,7 +21,7 @@ const (
 	Impl1cv8 = "1cv8"
 	// ImplIbcmd — реализация через ibcmd.
 	ImplIbcmd = "ibcmd"
-	// ImplNative — нативная реализация (не реализовано).
+	// ImplNative — нативная реализация (для config_export не поддерживается).
 	ImplNative = "native"
 )
 
@@ -32,8 +33,12 @@ const (
 //	factory := onec.NewFactory(cfg)
 //	exporter, err := factory.NewConfigExporter()
 //	creator, err := factory.NewDatabaseCreator()
+//
+// Исполняемые файлы 1cv8/ibcmd выбираются через cfg.PlatformTool: при включённом
+// выборе версии платформы (config.PlatformConfig) — по требованию WithRequirement.
 type Factory struct {
 	cfg *config.Config
+	req platform.Requirement
 }
 
 // NewFactory создаёт новую фабрику операций.
@@ -41,6 +46,21 @@ func NewFactory(cfg *config.Config) *Factory {
 	return &Factory{cfg: cfg}
 }
 
+// WithRequirement возвращает фабрику, выбирающую версию платформы по требованию req
+// (режим совместимости конфигурации или версия сервера 1С).
+func (f *Factory) WithRequirement(req platform.Requirement) *Factory {
+	return &Factory{cfg: f.cfg, req: req}
+}
+
+// toolPath возвращает путь к исполняемому файлу платформы tool.
+func (f *Factory) toolPath(tool platform.Tool) (string, error) {
+	path, err := f.cfg.PlatformTool(tool, f.req)
+	if err != nil {
+		return "", fmt.Errorf("не удалось выбрать версию платформы для %s: %w", tool, err)
+	}
+	return path, nil
+}
+
 // NewConfigExporter возвращает реализацию ConfigExporter на основе конфигурации.
 // Выбор определяется config.AppConfig.Implementations.ConfigExport.
 //
@@ -56,18 +76,26 @@ func (f *Factory) NewConfigExporter() (ConfigExporter, error) {
 
 	switch impl {
 	case Impl1cv8:
+		bin, err := f.toolPath(platform.Tool1cv8)
+		if err != nil {
+			return nil, err
+		}
 		return NewExporter1cv8(
-			f.cfg.AppConfig.Paths.Bin1cv8,
+			bin,
 			f.cfg.AppConfig.WorkDir,
 			f.cfg.AppConfig.TmpDir,
 		), nil
 	case ImplIbcmd:
-		return NewExporterIbcmd(
-			f.cfg.AppConfig.Paths.BinIbcmd,
-		), nil
+		bin, err := f.toolPath(platform.ToolIbcmd)
+		if err != nil {
+			return nil, err
+		}
+		return NewExporterIbcmd(bin), nil
 	case ImplNative:
-		// TODO(#42): реализовать native exporter в будущем (Epic 4 Story 4.5 nr-convert)
-		return nil, fmt.Errorf("%w: native config_export not implemented yet", ErrInvalidImplementation)
+		// Выгрузка в раскладку /DumpCfg требует разбора внутренней сериализации
+		// метаданных платформы; без 1cv8/ibcmd побайтово совпадающий результат
+		// не получить, поэтому native exporter не реализуется.
+		return nil, fmt.Errorf("%w: native config_export not implemented: use 1cv8 or ibcmd", ErrInvalidImplementation)
 	default:
 		return nil, fmt.Errorf("%w: unknown config_export implementation '%s', valid: 1cv8, ibcmd, native",
 			ErrInvalidImplementation, impl)
@@ -88,15 +116,21 @@ func (f *Factory) NewDatabaseCreator() (DatabaseCreator, error) {
 
 	switch impl {
 	case Impl1cv8:
+		bin, err := f.toolPath(platform.Tool1cv8)
+		if err != nil {
+			return nil, err
+		}
 		return NewCreator1cv8(
-			f.cfg.AppConfig.Paths.Bin1cv8,
+			bin,
 			f.cfg.AppConfig.WorkDir,
 			f.cfg.AppConfig.TmpDir,
 		), nil
 	case ImplIbcmd:
-		return NewCreatorIbcmd(
-			f.cfg.AppConfig.Paths.BinIbcmd,
-		), nil
+		bin, err := f.toolPath(platform.ToolIbcmd)
+		if err != nil {
+			return nil, err
+		}
+		return NewCreatorIbcmd(bin), nil
 	default:
 		return nil, fmt.Errorf("%w: unknown db_create implementation '%s', valid: 1cv8, ibcmd",
 			ErrInvalidImplementation, impl)
@@ -3,6 +3,8 @@ package onec_test
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -10,6 +12,7 @@ import (
 
 	"github.com/Kargones/apk-ci/internal/adapter/onec"
 	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/pkg/platform"
 )
 
 // makeConfig создаёт конфигурацию с заданными параметрами для тестов.
@@ -229,3 +232,27 @@ func TestFactory_Integration_DatabaseCreator_EmptyBinPath(t *testing.T) {
 		})
 	}
 }
+
+// TestOneCFactory_PlatformResolver проверяет выбор версии платформы по требованию.
+func TestOneCFactory_PlatformResolver(t *testing.T) {
+	base := t.TempDir()
+	for _, v := range []string{"8.3.21.1895", "8.3.24.1467"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(base, v), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(base, v, "ibcmd"), []byte("#!/bin/sh\n"), 0o755)) //nolint:gosec // тестовая заглушка
+	}
+	cfg := makeConfig("ibcmd", "", "", "/usr/bin/ibcmd")
+	cfg.PlatformConfig = &config.PlatformConfig{AutoDetect: true, BasePath: base}
+
+	exporter, err := onec.NewFactory(cfg).
+		WithRequirement(platform.Requirement{Minimum: platform.Version{Major: 8, Minor: 3, Release: 21}}).
+		NewConfigExporter()
+	require.NoError(t, err)
+	assert.Equal(t, onec.NewExporterIbcmd(filepath.Join(base, "8.3.24.1467", "ibcmd")), exporter)
+
+	_, err = onec.NewFactory(cfg).
+		WithRequirement(platform.Requirement{Minimum: platform.Version{Major: 8, Minor: 3, Release: 27}, Source: "Configuration.xml"}).
+		NewConfigExporter()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), platform.ErrPlatformNoMatch)
+	assert.Contains(t, err.Error(), "Configuration.xml")
+}
@@ -6,11 +6,12 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
-	"os/exec"
 	"strings"
 	"time"
 
 	"github.com/Kargones/apk-ci/internal/pkg/apperrors"
+	"github.com/Kargones/apk-ci/internal/pkg/platform"
+	"github.com/Kargones/apk-ci/internal/util/runner"
 )
 
 // Коды ошибок RAC клиента.
@@ -33,6 +34,9 @@ type ClientOptions struct {
 	Port string
 	// Timeout — таймаут выполнения команд (по умолчанию 30s)
 	Timeout time.Duration
+	// Retries — количество попыток выполнения команды (по умолчанию 1 — без повторов).
+	// Повторяются только ошибки запуска и таймауты rac, отмена контекста прерывает повторы.
+	Retries int
 	// ClusterUser — администратор кластера (опционально)
 	ClusterUser string
 	// ClusterPass — пароль администратора кластера (опционально)
@@ -43,6 +47,11 @@ type ClientOptions struct {
 	InfobasePass string
 	// Logger — логгер (если nil — slog.Default())
 	Logger *slog.Logger
+	// Platform — выбор rac из установленных версий платформы (опционально).
+	// Если задан, RACPath определяется по PlatformRequirement.
+	Platform *platform.Resolver
+	// PlatformRequirement — требование к версии платформы для выбора rac
+	PlatformRequirement platform.Requirement
 }
 
 // racClient — реализация интерфейса Client для работы с RAC CLI.
@@ -51,6 +60,8 @@ type racClient struct {
 	server       string
 	port         string
 	timeout      time.Duration
+	retries      int
+	retryDelay   time.Duration
 	clusterUser  string
 	clusterPass  string
 	infobaseUser string
@@ -59,10 +70,20 @@ type racClient struct {
 }
 
 // Compile-time проверка интерфейса.
-var _ Client = (*racClient)(nil)
+var (
+	_ Client          = (*racClient)(nil)
+	_ VersionProvider = (*racClient)(nil)
+)
 
 // NewClient создаёт новый RAC клиент с валидацией параметров.
 func NewClient(opts ClientOptions) (Client, error) {
+	if opts.Platform != nil {
+		racPath, err := opts.Platform.ResolvePath(platform.ToolRac, opts.PlatformRequirement)
+		if err != nil {
+			return nil, apperrors.NewAppError(ErrRACExec, "не удалось выбрать версию rac", err)
+		}
+		opts.RACPath = racPath
+	}
 	if opts.RACPath == "" {
 		return nil, apperrors.NewAppError(ErrRACExec, "путь к rac не указан", nil)
 	}
@@ -84,6 +105,9 @@ func NewClient(opts ClientOptions) (Client, error) {
 	if opts.Timeout == 0 {
 		opts.Timeout = 30 * time.Second
 	}
+	if opts.Retries < 1 {
+		opts.Retries = 1
+	}
 	if opts.Logger == nil {
 		opts.Logger = slog.Default()
 	}
@@ -92,6 +116,8 @@ func NewClient(opts ClientOptions) (Client, error) {
 		server:       opts.Server,
 		port:         opts.Port,
 		timeout:      opts.Timeout,
+		retries:      opts.Retries,
+		retryDelay:   time.Second,
 		clusterUser:  opts.ClusterUser,
 		clusterPass:  opts.ClusterPass,
 		infobaseUser: opts.InfobaseUser,
@@ -100,11 +126,35 @@ func NewClient(opts ClientOptions) (Client, error) {
 	}, nil
 }
 
-// executeRAC запускает RAC как subprocess с таймаутом.
+// executeRAC запускает RAC с повторами: до c.retries попыток с линейно
+// растущей паузой (retryDelay, 2*retryDelay, ...). Повторы прекращаются
+// при отмене родительского контекста.
 func (c *racClient) executeRAC(ctx context.Context, args []string) (string, error) {
-	ctx, cancel := context.WithTimeout(ctx, c.timeout)
-	defer cancel()
+	var lastErr error
+	for attempt := 1; attempt <= c.retries; attempt++ {
+		output, err := c.executeRACOnce(ctx, args)
+		if err == nil {
+			return output, nil
+		}
+		lastErr = err
+		if ctx.Err() != nil || attempt == c.retries {
+			break
+		}
+		c.logger.Warn("Команда RAC завершилась ошибкой, повтор",
+			"attempt", attempt, "retries", c.retries, "error", err)
+		select {
+		case <-ctx.Done():
+			return "", lastErr
+		case <-time.After(c.retryDelay * time.Duration(attempt)):
+		}
+	}
+	return "", lastErr
+}
 
+// executeRACOnce запускает RAC через runner.Supervisor с таймаутом: процесс
+// изолируется в группе процессов, успех определяется кодом завершения.
+// Вывод rac на Windows приходит в кодировке консоли и перекодируется в UTF-8 (см. decodeOutput).
+func (c *racClient) executeRACOnce(ctx context.Context, args []string) (string, error) {
 	serverAddr := c.server + ":" + c.port
 	fullArgs := make([]string, 0, len(args)+1)
 	fullArgs = append(fullArgs, serverAddr)
@@ -115,28 +165,27 @@ func (c *racClient) executeRAC(ctx context.Context, args []string) (string, erro
 		"args", sanitizeArgs(fullArgs),
 	)
 
-	cmd := exec.CommandContext(ctx, c.racPath, fullArgs...) //nolint:gosec // аргументы формируются программно, не из пользовательского ввода
-	output, err := cmd.Output()
+	res, err := (&runner.Supervisor{}).Run(ctx, c.logger, runner.Spec{
+		Bin:     c.racPath,
+		Args:    fullArgs,
+		Timeout: c.timeout,
+	})
 	if err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
+		if errors.Is(err, runner.ErrTimeout) {
 			return "", apperrors.NewAppError(ErrRACTimeout,
 				fmt.Sprintf("таймаут выполнения команды (%s)", c.timeout), err)
 		}
-		if ctx.Err() == context.Canceled {
+		if errors.Is(err, runner.ErrCancelled) {
 			return "", apperrors.NewAppError(ErrRACExec, "выполнение отменено", err)
 		}
-		// Извлекаем stderr из ExitError для диагностики (stdout и stderr разделены)
+		// stderr сохраняется отдельно от stdout для диагностики.
 		// Применяем sanitizeString для предотвращения утечки credentials в сообщениях об ошибках
 		errMsg := "ошибка выполнения RAC"
-		var exitErr *exec.ExitError
-		if errors.As(err, &exitErr) {
-			stderr := sanitizeString(strings.TrimSpace(string(exitErr.Stderr)))
-			if stderr != "" {
-				errMsg = fmt.Sprintf("ошибка выполнения RAC: %s", stderr)
-			}
+		if stderr := sanitizeString(strings.TrimSpace(decodeOutput(res.Stderr))); stderr != "" {
+			errMsg = fmt.Sprintf("ошибка выполнения RAC: %s", stderr)
 		}
 		return "", apperrors.NewAppError(ErrRACExec, errMsg, err)
 	}
 
-	return string(output), nil
+	return decodeOutput(res.Stdout), nil
 }
@@ -2,12 +2,18 @@ package rac
 
 import (
 	"context"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/Kargones/apk-ci/internal/pkg/platform"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/charmap"
 )
 
 // TODO(H-3): Отсутствуют интеграционные тесты для EnableServiceMode/DisableServiceMode.
@@ -22,6 +28,13 @@ import (
 
 func TestRacClientImplementsClientInterface(_ *testing.T) {
 	var _ Client = (*racClient)(nil)
+	var _ ServiceModeScheduler = (*racClient)(nil)
+	var _ InventoryProvider = (*racClient)(nil)
+	var _ LicenseProvider = (*racClient)(nil)
+	var _ ScheduledJobsController = (*racClient)(nil)
+	var _ ServiceModeLeaser = (*racClient)(nil)
+	var _ InfobaseManager = (*racClient)(nil)
+	var _ LockProvider = (*racClient)(nil)
 }
 
 // === Task 7.3: Тесты конструктора ===
@@ -40,6 +53,7 @@ func TestNewClient_Defaults(t *testing.T) {
 	assert.Equal(t, "server-1c", rc.server)
 	assert.Equal(t, "1545", rc.port)
 	assert.Equal(t, 30*time.Second, rc.timeout)
+	assert.Equal(t, 1, rc.retries)
 	assert.NotNil(t, rc.logger)
 }
 
@@ -93,6 +107,32 @@ func TestNewClient_ErrorOnNonExistentRACPath(t *testing.T) {
 	assert.Contains(t, err.Error(), "не найден")
 }
 
+func TestNewClient_PlatformResolver(t *testing.T) {
+	base := t.TempDir()
+	for _, v := range []string{"8.3.24.1467", "8.3.27.1606"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(base, v), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(base, v, "rac"), []byte("#!/bin/sh\n"), 0o755)) //nolint:gosec // тестовая заглушка
+	}
+
+	c, err := NewClient(ClientOptions{
+		RACPath:             "/nonexistent/rac",
+		Server:              "server-1c",
+		Platform:            platform.NewResolver(base, platform.Version{}),
+		PlatformRequirement: platform.Requirement{Exact: platform.Version{Major: 8, Minor: 3, Release: 24, Build: 1467}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(base, "8.3.24.1467", "rac"), c.(*racClient).racPath)
+
+	_, err = NewClient(ClientOptions{
+		Server:              "server-1c",
+		Platform:            platform.NewResolver(base, platform.Version{}),
+		PlatformRequirement: platform.Requirement{Exact: platform.Version{Major: 8, Minor: 3, Release: 25, Build: 1}},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "RAC.EXEC")
+	assert.Contains(t, err.Error(), platform.ErrPlatformNoMatch)
+}
+
 // === Task 7.1: Тесты парсинга вывода RAC ===
 
 func TestParseBlocks_SingleBlock(t *testing.T) {
@@ -281,6 +321,28 @@ func TestExecuteRAC_CancelledContext(t *testing.T) {
 	require.Error(t, execErr)
 }
 
+func TestExecuteRAC_ExitCode(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "rac")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\n"+
+		"echo 'cluster : 1'\n"+
+		"echo 'Ошибка соединения с сервером' >&2\n"+
+		"exit \"${RAC_EXIT:-0}\"\n"), 0o755))
+
+	c, err := NewClient(ClientOptions{RACPath: script, Server: "localhost"})
+	require.NoError(t, err)
+
+	out, err := c.(*racClient).executeRAC(context.Background(), []string{"cluster", "list"})
+	require.NoError(t, err, "нулевой код завершения — успех, stderr не анализируется")
+	assert.Equal(t, "cluster : 1\n", out)
+
+	t.Setenv("RAC_EXIT", "2")
+	_, err = c.(*racClient).executeRAC(context.Background(), []string{"cluster", "list"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), ErrRACExec)
+	assert.Contains(t, err.Error(), "Ошибка соединения с сервером")
+}
+
 func TestParseBlocks_MalformedLine(t *testing.T) {
 	// Строка без разделителя ":"
 	input := "this is not a valid line\n"
@@ -392,12 +454,14 @@ func TestSanitizeArgs(t *testing.T) {
 		"--cluster-pwd=secret123",
 		"--infobase-user=dbadmin",
 		"--infobase-pwd=dbsecret",
+		"--db-pwd=sqlsecret",
 	}
 	sanitized := sanitizeArgs(args)
 	joined := strings.Join(sanitized, " ")
 	// Пароли должны быть замаскированы
 	assert.NotContains(t, joined, "secret123")
 	assert.NotContains(t, joined, "dbsecret")
+	assert.NotContains(t, joined, "sqlsecret")
 	// Имена пользователей НЕ маскируются — они полезны для отладки
 	assert.Contains(t, joined, "--cluster-user=admin")
 	assert.Contains(t, joined, "--infobase-user=dbadmin")
@@ -551,3 +615,99 @@ func TestVerifyServiceMode_ErrorCode(t *testing.T) {
 	assert.NotEqual(t, ErrRACExec, ErrRACVerify)
 	assert.Equal(t, "RAC.VERIFY_FAILED", ErrRACVerify)
 }
+
+// createFlakyRAC создаёт mock RAC, завершающийся ошибкой первые failures вызовов.
+func createFlakyRAC(t *testing.T, failures int) (racPath, counter string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	racPath = filepath.Join(tmpDir, "flaky-rac")
+	counter = filepath.Join(tmpDir, "calls")
+	script := "#!/bin/sh\necho x >> " + counter + "\n" +
+		"if [ $(wc -l < " + counter + ") -le " + strconv.Itoa(failures) + " ]; then echo 'server unavailable' >&2; exit 1; fi\n" +
+		"echo 'cluster : 11111111-2222-3333-4444-555555555555'\n"
+	require.NoError(t, os.WriteFile(racPath, []byte(script), 0755)) //nolint:gosec // тестовый скрипт
+	return racPath, counter
+}
+
+func callCount(t *testing.T, counter string) int {
+	t.Helper()
+	data, err := os.ReadFile(counter) //nolint:gosec // тестовый файл
+	require.NoError(t, err)
+	return strings.Count(string(data), "\n")
+}
+
+func TestExecuteRAC_Retries(t *testing.T) {
+	tests := []struct {
+		name      string
+		retries   int
+		failures  int
+		wantErr   bool
+		wantCalls int
+	}{
+		{"success_after_failures", 3, 2, false, 3},
+		{"exhausted", 2, 5, true, 2},
+		{"default_single_attempt", 0, 1, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			racPath, counter := createFlakyRAC(t, tt.failures)
+			c, err := NewClient(ClientOptions{RACPath: racPath, Server: "localhost", Retries: tt.retries})
+			require.NoError(t, err)
+			rc := c.(*racClient)
+			rc.retryDelay = time.Millisecond
+
+			output, err := rc.executeRAC(context.Background(), []string{"cluster", "list"})
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), ErrRACExec)
+				assert.Contains(t, err.Error(), "server unavailable")
+			} else {
+				require.NoError(t, err)
+				assert.Contains(t, output, "cluster")
+			}
+			assert.Equal(t, tt.wantCalls, callCount(t, counter))
+		})
+	}
+}
+
+func TestExecuteRAC_RetriesStopOnCancel(t *testing.T) {
+	racPath, counter := createFlakyRAC(t, 10)
+	c, err := NewClient(ClientOptions{RACPath: racPath, Server: "localhost", Retries: 5})
+	require.NoError(t, err)
+	rc := c.(*racClient)
+	rc.retryDelay = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	_, err = rc.executeRAC(ctx, []string{"cluster", "list"})
+	require.Error(t, err)
+	assert.Equal(t, 1, callCount(t, counter), "пауза между попытками прерывается отменой контекста")
+}
+
+func TestDecodeOutput(t *testing.T) {
+	const text = "Администратор кластера не аутентифицирован"
+	cp866, err := charmap.CodePage866.NewEncoder().String(text)
+	require.NoError(t, err)
+	cp1251, err := charmap.Windows1251.NewEncoder().String(text)
+	require.NoError(t, err)
+
+	assert.Equal(t, text, decodeOutput([]byte(text)), "UTF-8 не перекодируется")
+	assert.Equal(t, text, decodeOutput([]byte(cp866)))
+	assert.Equal(t, text, decodeOutput([]byte(cp1251)))
+	assert.Equal(t, "", decodeOutput(nil))
+}
+
+func TestExecuteRAC_DecodesCP866(t *testing.T) {
+	const text = "Информационная база не найдена"
+	cp866, err := charmap.CodePage866.NewEncoder().String(text)
+	require.NoError(t, err)
+	racPath := filepath.Join(t.TempDir(), "cp866-rac")
+	script := "#!/bin/sh\nprintf '" + cp866 + "' >&2\nexit 1\n"
+	require.NoError(t, os.WriteFile(racPath, []byte(script), 0755)) //nolint:gosec // тестовый скрипт
+
+	c, err := NewClient(ClientOptions{RACPath: racPath, Server: "localhost"})
+	require.NoError(t, err)
+	_, err = c.(*racClient).executeRAC(context.Background(), []string{"infobase", "info"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), text)
+}
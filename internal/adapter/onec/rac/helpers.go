@@ -1,6 +1,11 @@
 package rac
 
-import "strings"
+import (
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
 
 // sanitizeArgs маскирует пароли в аргументах для логирования.
 // Имена пользователей НЕ маскируются — они не являются конфиденциальными данными
@@ -9,7 +14,8 @@ func sanitizeArgs(args []string) []string {
 	sanitized := make([]string, len(args))
 	for i, arg := range args {
 		if strings.HasPrefix(arg, "--cluster-pwd=") ||
-			strings.HasPrefix(arg, "--infobase-pwd=") {
+			strings.HasPrefix(arg, "--infobase-pwd=") ||
+			strings.HasPrefix(arg, "--db-pwd=") {
 			eqIdx := strings.Index(arg, "=")
 			sanitized[i] = arg[:eqIdx+1] + "***"
 		} else {
@@ -22,10 +28,10 @@ func sanitizeArgs(args []string) []string {
 // sanitizeString маскирует пароли в произвольной строке (например, stderr от RAC).
 // Используется для предотвращения утечки credentials в сообщениях об ошибках.
 func sanitizeString(s string) string {
-	// Маскируем значения после --cluster-pwd= и --infobase-pwd=
+	// Маскируем значения после --cluster-pwd=, --infobase-pwd= и --db-pwd=
 	// Паттерн: --xxx-pwd=VALUE где VALUE продолжается до пробела, кавычки или конца строки
 	result := s
-	for _, prefix := range []string{"--cluster-pwd=", "--infobase-pwd="} {
+	for _, prefix := range []string{"--cluster-pwd=", "--infobase-pwd=", "--db-pwd="} {
 		offset := 0
 		for {
 			idx := strings.Index(result[offset:], prefix)
@@ -77,3 +83,25 @@ func (c *racClient) infobaseAuthArgs() []string {
 	}
 	return args
 }
+
+// decodeOutput перекодирует вывод rac в UTF-8.
+// Корректный UTF-8 возвращается без изменений. Иначе вывод считается
+// OEM-кодировкой консоли Windows (CP866); если встречаются байты 0xB0–0xDF,
+// которые в CP866 заняты псевдографикой, вывод декодируется как CP1251.
+func decodeOutput(b []byte) string {
+	if utf8.Valid(b) {
+		return string(b)
+	}
+	decoder := charmap.CodePage866.NewDecoder()
+	for _, ch := range b {
+		if ch >= 0xB0 && ch <= 0xDF {
+			decoder = charmap.Windows1251.NewDecoder()
+			break
+		}
+	}
+	decoded, err := decoder.Bytes(b)
+	if err != nil {
+		return string(b)
+	}
+	return string(decoded)
+}
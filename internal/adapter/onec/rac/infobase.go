@@ -0,0 +1,178 @@
+package rac
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Kargones/apk-ci/internal/pkg/apperrors"
+)
+
+// Значения по умолчанию для регистрации информационной базы.
+const (
+	defaultInfobaseDBMS   = "MSSQLServer"
+	defaultInfobaseLocale = "ru"
+)
+
+// CreateInfobase регистрирует информационную базу в кластере (rac infobase create).
+// Без params.CreateDatabase база данных СУБД должна существовать.
+func (c *racClient) CreateInfobase(ctx context.Context, clusterUUID string, params InfobaseParams) (*InfobaseInfo, error) {
+	c.logger.Debug("Регистрация информационной базы",
+		"cluster", clusterUUID, "infobase", params.Name, "db-server", params.DBServer, "db-name", params.DBName)
+
+	if params.Name == "" || params.DBServer == "" || params.DBName == "" {
+		return nil, apperrors.NewAppError(ErrRACExec,
+			"для регистрации информационной базы требуются имя, сервер и имя базы данных СУБД", nil)
+	}
+
+	dbms := params.DBMS
+	if dbms == "" {
+		dbms = defaultInfobaseDBMS
+	}
+	locale := params.Locale
+	if locale == "" {
+		locale = defaultInfobaseLocale
+	}
+
+	args := []string{
+		"infobase", "create",
+		"--cluster=" + clusterUUID,
+		"--name=" + params.Name,
+		"--dbms=" + dbms,
+		"--db-server=" + params.DBServer,
+		"--db-name=" + params.DBName,
+		"--locale=" + locale,
+		"--license-distribution=allow",
+	}
+	args = append(args, dbAuthArgs(params)...)
+	if params.Description != "" {
+		args = append(args, "--descr="+params.Description)
+	}
+	if params.CreateDatabase {
+		args = append(args, "--create-database")
+	}
+	if params.ScheduledJobsDeny {
+		args = append(args, "--scheduled-jobs-deny=on")
+	}
+	args = append(args, c.clusterAuthArgs()...)
+
+	output, err := c.executeRAC(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := parseBlocks(output)
+	if len(blocks) == 0 {
+		return nil, apperrors.NewAppError(ErrRACParse, "UUID созданной информационной базы отсутствует в выводе RAC", nil)
+	}
+	info, err := parseInfobaseInfo(blocks[0])
+	if err != nil {
+		return nil, err
+	}
+	info.Name = params.Name
+	info.Description = params.Description
+	return info, nil
+}
+
+// GetInfobaseParams возвращает параметры СУБД информационной базы (rac infobase info).
+func (c *racClient) GetInfobaseParams(ctx context.Context, clusterUUID, infobaseUUID string) (*InfobaseParams, error) {
+	c.logger.Debug("Получение параметров информационной базы",
+		"cluster", clusterUUID, "infobase", infobaseUUID)
+
+	args := []string{"infobase", "info", "--cluster=" + clusterUUID, "--infobase=" + infobaseUUID} //nolint:prealloc // dynamic append based on auth
+	args = append(args, c.clusterAuthArgs()...)
+	args = append(args, c.infobaseAuthArgs()...)
+
+	output, err := c.executeRAC(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := parseBlocks(output)
+	if len(blocks) == 0 {
+		return nil, apperrors.NewAppError(ErrRACNotFound,
+			fmt.Sprintf("информационная база '%s' не найдена", infobaseUUID), nil)
+	}
+	block := blocks[0]
+	return &InfobaseParams{
+		Name:              block["name"],
+		Description:       trimQuotes(block["descr"]),
+		DBMS:              block["dbms"],
+		DBServer:          block["db-server"],
+		DBName:            block["db-name"],
+		DBUser:            block["db-user"],
+		Locale:            block["locale"],
+		ScheduledJobsDeny: block["scheduled-jobs-deny"] == "on",
+	}, nil
+}
+
+// UpdateInfobaseDBMS изменяет параметры СУБД информационной базы (rac infobase update).
+func (c *racClient) UpdateInfobaseDBMS(ctx context.Context, clusterUUID, infobaseUUID string, params InfobaseParams) error {
+	c.logger.Debug("Изменение параметров СУБД информационной базы",
+		"cluster", clusterUUID, "infobase", infobaseUUID, "db-server", params.DBServer, "db-name", params.DBName)
+
+	args := []string{
+		"infobase", "update",
+		"--cluster=" + clusterUUID,
+		"--infobase=" + infobaseUUID,
+	}
+	if params.DBMS != "" {
+		args = append(args, "--dbms="+params.DBMS)
+	}
+	if params.DBServer != "" {
+		args = append(args, "--db-server="+params.DBServer)
+	}
+	if params.DBName != "" {
+		args = append(args, "--db-name="+params.DBName)
+	}
+	args = append(args, dbAuthArgs(params)...)
+	if params.Description != "" {
+		args = append(args, "--descr="+params.Description)
+	}
+	if len(args) == 4 {
+		return apperrors.NewAppError(ErrRACExec, "не заданы изменяемые параметры СУБД", nil)
+	}
+	args = append(args, c.clusterAuthArgs()...)
+	args = append(args, c.infobaseAuthArgs()...)
+
+	_, err := c.executeRAC(ctx, args)
+	return err
+}
+
+// DropInfobase удаляет информационную базу из кластера (rac infobase drop).
+func (c *racClient) DropInfobase(ctx context.Context, clusterUUID, infobaseUUID string, mode InfobaseDropMode) error {
+	c.logger.Debug("Удаление информационной базы",
+		"cluster", clusterUUID, "infobase", infobaseUUID, "mode", string(mode))
+
+	args := []string{
+		"infobase", "drop",
+		"--cluster=" + clusterUUID,
+		"--infobase=" + infobaseUUID,
+	}
+	switch mode {
+	case InfobaseDropKeepDatabase, "":
+	case InfobaseDropDatabase:
+		args = append(args, "--drop-database")
+	case InfobaseDropClearDatabase:
+		args = append(args, "--clear-database")
+	default:
+		return apperrors.NewAppError(ErrRACExec,
+			fmt.Sprintf("неизвестный режим удаления информационной базы '%s'", mode), nil)
+	}
+	args = append(args, c.clusterAuthArgs()...)
+	args = append(args, c.infobaseAuthArgs()...)
+
+	_, err := c.executeRAC(ctx, args)
+	return err
+}
+
+// dbAuthArgs возвращает аргументы аутентификации СУБД.
+func dbAuthArgs(params InfobaseParams) []string {
+	var args []string
+	if params.DBUser != "" {
+		args = append(args, "--db-user="+params.DBUser)
+	}
+	if params.DBPassword != "" {
+		args = append(args, "--db-pwd="+params.DBPassword)
+	}
+	return args
+}
@@ -0,0 +1,154 @@
+package rac
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newInfobaseManager(t *testing.T, output string) (InfobaseManager, string) {
+	t.Helper()
+	racPath, argsLog := createRecordingRAC(t, output)
+	c, err := NewClient(ClientOptions{
+		RACPath:      racPath,
+		Server:       "localhost",
+		ClusterUser:  "admin",
+		ClusterPass:  "cluster-secret",
+		InfobaseUser: "ibadmin",
+		InfobasePass: "ib-secret",
+	})
+	require.NoError(t, err)
+	return c.(InfobaseManager), argsLog
+}
+
+func TestCreateInfobase(t *testing.T) {
+	m, argsLog := newInfobaseManager(t, "infobase : 9b1c2d3e-0000-4000-8000-000000000001")
+
+	info, err := m.CreateInfobase(context.Background(), "cluster-uuid", InfobaseParams{
+		Name:              "erp_test",
+		DBServer:          "sql-01",
+		DBName:            "erp_test_db",
+		DBUser:            "sa",
+		DBPassword:        "db-secret",
+		ScheduledJobsDeny: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "9b1c2d3e-0000-4000-8000-000000000001", info.UUID)
+	assert.Equal(t, "erp_test", info.Name)
+
+	call := lastCall(t, argsLog)
+	assert.Contains(t, call, "infobase create --cluster=cluster-uuid --name=erp_test --dbms=MSSQLServer")
+	assert.Contains(t, call, "--db-server=sql-01 --db-name=erp_test_db --locale=ru")
+	assert.Contains(t, call, "--db-user=sa --db-pwd=db-secret")
+	assert.Contains(t, call, "--scheduled-jobs-deny=on")
+	assert.Contains(t, call, "--cluster-user=admin")
+	assert.NotContains(t, call, "--create-database")
+	assert.NotContains(t, call, "--infobase-user", "базы ещё нет — аутентификация только в кластере")
+}
+
+func TestCreateInfobase_CreateDatabase(t *testing.T) {
+	m, argsLog := newInfobaseManager(t, "infobase : ib-uuid")
+
+	_, err := m.CreateInfobase(context.Background(), "cluster-uuid", InfobaseParams{
+		Name: "erp_test", DBMS: "PostgreSQL", DBServer: "pg-01", DBName: "erp_test", CreateDatabase: true,
+	})
+	require.NoError(t, err)
+	call := lastCall(t, argsLog)
+	assert.Contains(t, call, "--dbms=PostgreSQL")
+	assert.Contains(t, call, "--create-database")
+}
+
+func TestCreateInfobase_Validation(t *testing.T) {
+	m, _ := newInfobaseManager(t, "")
+
+	_, err := m.CreateInfobase(context.Background(), "cluster-uuid", InfobaseParams{Name: "erp_test"})
+	require.Error(t, err)
+}
+
+func TestCreateInfobase_NoUUID(t *testing.T) {
+	m, _ := newInfobaseManager(t, "")
+
+	_, err := m.CreateInfobase(context.Background(), "cluster-uuid", InfobaseParams{
+		Name: "erp_test", DBServer: "sql-01", DBName: "erp_test",
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), ErrRACParse)
+}
+
+func TestGetInfobaseParams(t *testing.T) {
+	output := "infobase            : ib-uuid\n" +
+		"name                : erp_test\n" +
+		"dbms                : MSSQLServer\n" +
+		"db-server           : sql-01\n" +
+		"db-name             : erp_test_db\n" +
+		"db-user             : sa\n" +
+		"locale              : ru\n" +
+		"descr               : \"Тестовая ERP\"\n" +
+		"scheduled-jobs-deny : on"
+	m, argsLog := newInfobaseManager(t, output)
+
+	params, err := m.GetInfobaseParams(context.Background(), "cluster-uuid", "ib-uuid")
+	require.NoError(t, err)
+	assert.Equal(t, "MSSQLServer", params.DBMS)
+	assert.Equal(t, "sql-01", params.DBServer)
+	assert.Equal(t, "erp_test_db", params.DBName)
+	assert.Equal(t, "sa", params.DBUser)
+	assert.Equal(t, "Тестовая ERP", params.Description)
+	assert.True(t, params.ScheduledJobsDeny)
+	assert.Contains(t, lastCall(t, argsLog), "infobase info --cluster=cluster-uuid --infobase=ib-uuid")
+}
+
+func TestUpdateInfobaseDBMS(t *testing.T) {
+	m, argsLog := newInfobaseManager(t, "")
+
+	require.NoError(t, m.UpdateInfobaseDBMS(context.Background(), "cluster-uuid", "ib-uuid", InfobaseParams{
+		DBServer: "sql-02",
+		DBUser:   "sa",
+	}))
+	call := lastCall(t, argsLog)
+	assert.Contains(t, call, "infobase update --cluster=cluster-uuid --infobase=ib-uuid --db-server=sql-02 --db-user=sa")
+	assert.NotContains(t, call, "--db-name")
+	assert.NotContains(t, call, "--dbms")
+	assert.Contains(t, call, "--infobase-user=ibadmin")
+}
+
+func TestUpdateInfobaseDBMS_Empty(t *testing.T) {
+	m, _ := newInfobaseManager(t, "")
+
+	require.Error(t, m.UpdateInfobaseDBMS(context.Background(), "cluster-uuid", "ib-uuid", InfobaseParams{}))
+}
+
+func TestDropInfobase(t *testing.T) {
+	tests := []struct {
+		mode    InfobaseDropMode
+		want    string
+		notWant string
+	}{
+		{mode: InfobaseDropKeepDatabase, notWant: "-database"},
+		{mode: InfobaseDropDatabase, want: "--drop-database"},
+		{mode: InfobaseDropClearDatabase, want: "--clear-database"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			m, argsLog := newInfobaseManager(t, "")
+
+			require.NoError(t, m.DropInfobase(context.Background(), "cluster-uuid", "ib-uuid", tt.mode))
+			call := lastCall(t, argsLog)
+			assert.Contains(t, call, "infobase drop --cluster=cluster-uuid --infobase=ib-uuid")
+			if tt.want != "" {
+				assert.Contains(t, call, tt.want)
+			}
+			if tt.notWant != "" {
+				assert.NotContains(t, call, tt.notWant)
+			}
+		})
+	}
+}
+
+func TestDropInfobase_UnknownMode(t *testing.T) {
+	m, _ := newInfobaseManager(t, "")
+
+	require.Error(t, m.DropInfobase(context.Background(), "cluster-uuid", "ib-uuid", "purge"))
+}
@@ -42,6 +42,80 @@ type ServiceModeStatus struct {
 	ScheduledJobsBlocked bool
 	// ActiveSessions — количество активных сессий
 	ActiveSessions int
+	// DeniedFrom — начало окна блокировки сеансов (нулевое — не задано)
+	DeniedFrom time.Time
+	// DeniedTo — окончание окна блокировки сеансов (нулевое — без ограничения)
+	DeniedTo time.Time
+	// DeniedParameter — параметр блокировки (--denied-parameter), хранит аренду (см. Lease)
+	DeniedParameter string
+}
+
+// Active сообщает, действует ли блокировка сеансов в момент now:
+// блокировка включена и now попадает в окно DeniedFrom..DeniedTo.
+func (s *ServiceModeStatus) Active(now time.Time) bool {
+	if !s.Enabled {
+		return false
+	}
+	if !s.DeniedFrom.IsZero() && now.Before(s.DeniedFrom) {
+		return false
+	}
+	return s.DeniedTo.IsZero() || now.Before(s.DeniedTo)
+}
+
+// Ended сообщает, завершилось ли окно блокировки сеансов к моменту now
+// (DeniedTo задан и наступил). Блокировка заданий при этом остаётся до отключения.
+func (s *ServiceModeStatus) Ended(now time.Time) bool {
+	return s.Enabled && !s.DeniedTo.IsZero() && !now.Before(s.DeniedTo)
+}
+
+// Scheduled сообщает, запланирована ли блокировка сеансов на будущее (DeniedFrom после now).
+func (s *ServiceModeStatus) Scheduled(now time.Time) bool {
+	return s.Enabled && s.DeniedFrom.After(now)
+}
+
+// ServiceModeWindow — окно сервисного режима (RAC --denied-from/--denied-to).
+// Время задаётся в часовом поясе сервера 1С (RAC передаёт время без пояса).
+type ServiceModeWindow struct {
+	// From — начало блокировки сеансов (нулевое — немедленно)
+	From time.Time
+	// To — окончание блокировки сеансов (нулевое — до явного отключения)
+	To time.Time
+	// Message — сообщение блокировки (пустое — constants.DefaultServiceModeMessage)
+	Message string
+}
+
+// ServerInfo содержит информацию о рабочем сервере кластера 1C.
+type ServerInfo struct {
+	// UUID — уникальный идентификатор рабочего сервера
+	UUID string
+	// Name — имя рабочего сервера
+	Name string
+	// AgentHost — хост агента сервера
+	AgentHost string
+	// AgentPort — порт агента сервера
+	AgentPort int
+	// PortRange — диапазон портов рабочих процессов (например, 1560:1591)
+	PortRange string
+}
+
+// ProcessInfo содержит информацию о рабочем процессе кластера 1C.
+type ProcessInfo struct {
+	// UUID — уникальный идентификатор рабочего процесса
+	UUID string
+	// Host — хост рабочего процесса
+	Host string
+	// Port — порт рабочего процесса
+	Port int
+	// PID — идентификатор процесса ОС
+	PID string
+	// Running — процесс запущен
+	Running bool
+	// StartedAt — время запуска процесса
+	StartedAt time.Time
+	// Connections — количество соединений
+	Connections int
+	// MemoryKB — занимаемая память, КБ
+	MemoryKB int64
 }
 
 // SessionInfo содержит информацию о сессии пользователя.
@@ -60,6 +134,74 @@ type SessionInfo struct {
 	LastActiveAt time.Time
 }
 
+// SessionLicense содержит сведения о лицензии, занятой сессией (rac session list --licenses).
+type SessionLicense struct {
+	// SessionID — идентификатор сессии
+	SessionID string
+	// UserName — имя пользователя
+	UserName string
+	// AppID — идентификатор приложения
+	AppID string
+	// LicenseType — тип лицензии (soft, HASP)
+	LicenseType string
+	// Series — серия лицензии
+	Series string
+	// IssuedByServer — лицензия выдана сервером (а не получена клиентом)
+	IssuedByServer bool
+	// Presentation — краткое представление лицензии
+	Presentation string
+}
+
+// LockInfo содержит сведения о блокировке объекта информационной базы (rac lock list).
+type LockInfo struct {
+	// SessionID — сессия, удерживающая блокировку (пустой UUID для служебных соединений)
+	SessionID string
+	// ConnectionID — соединение, удерживающее блокировку
+	ConnectionID string
+	// Object — заблокированный объект
+	Object string
+	// LockedAt — время установки блокировки
+	LockedAt time.Time
+	// Description — описание блокировки (например, "БД(сеанс ,монопольная)")
+	Description string
+}
+
+// InfobaseDropMode — режим удаления информационной базы (rac infobase drop).
+type InfobaseDropMode string
+
+const (
+	// InfobaseDropKeepDatabase — удаляется только регистрация базы в кластере, база данных СУБД сохраняется
+	InfobaseDropKeepDatabase InfobaseDropMode = "keep"
+	// InfobaseDropDatabase — удаляется регистрация и база данных СУБД (--drop-database)
+	InfobaseDropDatabase InfobaseDropMode = "drop"
+	// InfobaseDropClearDatabase — регистрация удаляется, база данных СУБД очищается (--clear-database)
+	InfobaseDropClearDatabase InfobaseDropMode = "clear"
+)
+
+// InfobaseParams содержит параметры регистрации информационной базы в кластере.
+type InfobaseParams struct {
+	// Name — имя информационной базы в кластере
+	Name string
+	// Description — описание информационной базы
+	Description string
+	// DBMS — тип СУБД (MSSQLServer, PostgreSQL, IBMDB2, OracleDatabase)
+	DBMS string
+	// DBServer — сервер СУБД
+	DBServer string
+	// DBName — имя базы данных СУБД
+	DBName string
+	// DBUser — пользователь СУБД
+	DBUser string
+	// DBPassword — пароль пользователя СУБД (не возвращается GetInfobaseParams)
+	DBPassword string
+	// Locale — национальные настройки (по умолчанию ru)
+	Locale string
+	// CreateDatabase — создать базу данных СУБД, если она отсутствует
+	CreateDatabase bool
+	// ScheduledJobsDeny — заблокировать регламентные задания созданной базы
+	ScheduledJobsDeny bool
+}
+
 // ClusterProvider предоставляет операции для получения информации о кластере.
 type ClusterProvider interface {
 	// GetClusterInfo возвращает информацию о кластере 1C.
@@ -99,6 +241,83 @@ type ServiceModeManager interface {
 	VerifyServiceMode(ctx context.Context, clusterUUID, infobaseUUID string, expectedEnabled bool) error
 }
 
+// ServiceModeScheduler предоставляет включение сервисного режима по расписанию.
+// Не входит в Client (проверяется через type assertion), как и VersionProvider.
+type ServiceModeScheduler interface {
+	// ScheduleServiceMode устанавливает окно блокировки сеансов window.
+	// Регламентные задания блокируются, только если окно уже началось: RAC не
+	// поддерживает блокировку заданий по расписанию. Для будущего окна задания
+	// блокирует nr-service-mode-reaper после начала окна; он же отключает
+	// сервисный режим после окончания окна.
+	ScheduleServiceMode(ctx context.Context, clusterUUID, infobaseUUID string, window ServiceModeWindow) error
+}
+
+// ScheduledJobsController управляет блокировкой регламентных заданий
+// информационной базы независимо от сервисного режима (--scheduled-jobs-deny).
+// Текущее состояние возвращает ServiceModeStatus.ScheduledJobsBlocked.
+// Не входит в Client (проверяется через type assertion).
+type ScheduledJobsController interface {
+	// SetScheduledJobsDeny включает (deny=true) или снимает блокировку регламентных заданий.
+	SetScheduledJobsDeny(ctx context.Context, clusterUUID, infobaseUUID string, deny bool) error
+}
+
+// ServiceModeLeaser записывает аренду сервисного режима в параметр блокировки
+// (--denied-parameter), не изменяя остальные параметры. DisableServiceMode
+// очищает параметр. Не входит в Client (проверяется через type assertion).
+type ServiceModeLeaser interface {
+	// SetServiceModeLease записывает параметр блокировки parameter (пустой — очистка).
+	SetServiceModeLease(ctx context.Context, clusterUUID, infobaseUUID, parameter string) error
+}
+
+// InventoryProvider предоставляет перечень объектов сервера 1С:
+// кластеры, информационные базы, рабочие серверы и процессы.
+// Не входит в Client (проверяется через type assertion).
+type InventoryProvider interface {
+	// ListClusters возвращает все кластеры сервера 1С.
+	ListClusters(ctx context.Context) ([]ClusterInfo, error)
+	// ListInfobases возвращает все информационные базы кластера.
+	ListInfobases(ctx context.Context, clusterUUID string) ([]InfobaseInfo, error)
+	// ListServers возвращает рабочие серверы кластера.
+	ListServers(ctx context.Context, clusterUUID string) ([]ServerInfo, error)
+	// ListProcesses возвращает рабочие процессы кластера.
+	ListProcesses(ctx context.Context, clusterUUID string) ([]ProcessInfo, error)
+}
+
+// LicenseProvider предоставляет сведения о лицензиях, занятых сессиями.
+// Не входит в Client (проверяется через type assertion).
+type LicenseProvider interface {
+	// GetSessionLicenses возвращает лицензии сессий информационной базы.
+	GetSessionLicenses(ctx context.Context, clusterUUID, infobaseUUID string) ([]SessionLicense, error)
+}
+
+// LockProvider предоставляет сведения о блокировках информационной базы.
+// Не входит в Client (проверяется через type assertion).
+type LockProvider interface {
+	// GetInfobaseLocks возвращает блокировки, удерживаемые на информационной базе.
+	GetInfobaseLocks(ctx context.Context, clusterUUID, infobaseUUID string) ([]LockInfo, error)
+}
+
+// InfobaseManager регистрирует, изменяет и удаляет информационные базы кластера.
+// Не входит в Client (проверяется через type assertion).
+type InfobaseManager interface {
+	// CreateInfobase регистрирует информационную базу на существующей (или создаваемой) базе данных СУБД.
+	CreateInfobase(ctx context.Context, clusterUUID string, params InfobaseParams) (*InfobaseInfo, error)
+	// GetInfobaseParams возвращает параметры СУБД информационной базы (без пароля).
+	GetInfobaseParams(ctx context.Context, clusterUUID, infobaseUUID string) (*InfobaseParams, error)
+	// UpdateInfobaseDBMS изменяет параметры СУБД информационной базы; пустые поля params не изменяются.
+	UpdateInfobaseDBMS(ctx context.Context, clusterUUID, infobaseUUID string, params InfobaseParams) error
+	// DropInfobase удаляет информационную базу из кластера в режиме mode.
+	DropInfobase(ctx context.Context, clusterUUID, infobaseUUID string, mode InfobaseDropMode) error
+}
+
+// VersionProvider предоставляет версию платформы сервера 1С.
+// Не входит в Client: используется для выбора версии платформы
+// (internal/pkg/platform) и проверяется через type assertion.
+type VersionProvider interface {
+	// GetAgentVersion возвращает версию агента сервера 1С (например, 8.3.27.1606).
+	GetAgentVersion(ctx context.Context) (string, error)
+}
+
 // Client — композитный интерфейс, объединяющий все операции RAC.
 type Client interface {
 	ClusterProvider
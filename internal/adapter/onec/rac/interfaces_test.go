@@ -18,6 +18,13 @@ func TestCompileTimeInterfaceChecks(_ *testing.T) {
 	var _ rac.InfobaseProvider = (*ractest.MockRACClient)(nil)
 	var _ rac.SessionProvider = (*ractest.MockRACClient)(nil)
 	var _ rac.ServiceModeManager = (*ractest.MockRACClient)(nil)
+	var _ rac.ServiceModeScheduler = (*ractest.MockRACClient)(nil)
+	var _ rac.InventoryProvider = (*ractest.MockRACClient)(nil)
+	var _ rac.LicenseProvider = (*ractest.MockRACClient)(nil)
+	var _ rac.ScheduledJobsController = (*ractest.MockRACClient)(nil)
+	var _ rac.ServiceModeLeaser = (*ractest.MockRACClient)(nil)
+	var _ rac.InfobaseManager = (*ractest.MockRACClient)(nil)
+	var _ rac.LockProvider = (*ractest.MockRACClient)(nil)
 }
 
 // TestMockRACClient_DefaultBehavior проверяет дефолтное поведение MockRACClient
@@ -0,0 +1,119 @@
+package rac
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// ListClusters возвращает все кластеры сервера 1С (rac cluster list).
+func (c *racClient) ListClusters(ctx context.Context) ([]ClusterInfo, error) {
+	c.logger.Debug("Получение списка кластеров")
+
+	output, err := c.executeRAC(ctx, []string{"cluster", "list"})
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := parseBlocks(output)
+	clusters := make([]ClusterInfo, 0, len(blocks))
+	for _, block := range blocks {
+		cl, err := parseClusterInfo(block)
+		if err != nil {
+			c.logger.Warn("Пропуск невалидного кластера при парсинге", "error", err)
+			continue
+		}
+		clusters = append(clusters, *cl)
+	}
+	return clusters, nil
+}
+
+// ListInfobases возвращает все информационные базы кластера (rac infobase summary list).
+func (c *racClient) ListInfobases(ctx context.Context, clusterUUID string) ([]InfobaseInfo, error) {
+	c.logger.Debug("Получение списка информационных баз", "cluster", clusterUUID)
+
+	args := []string{"infobase", "summary", "list", "--cluster=" + clusterUUID} //nolint:prealloc // dynamic append based on auth
+	args = append(args, c.clusterAuthArgs()...)
+
+	output, err := c.executeRAC(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := parseBlocks(output)
+	infobases := make([]InfobaseInfo, 0, len(blocks))
+	for _, block := range blocks {
+		ib, err := parseInfobaseInfo(block)
+		if err != nil {
+			c.logger.Warn("Пропуск невалидной информационной базы при парсинге", "error", err)
+			continue
+		}
+		infobases = append(infobases, *ib)
+	}
+	return infobases, nil
+}
+
+// ListServers возвращает рабочие серверы кластера (rac server list).
+func (c *racClient) ListServers(ctx context.Context, clusterUUID string) ([]ServerInfo, error) {
+	c.logger.Debug("Получение списка рабочих серверов", "cluster", clusterUUID)
+
+	args := []string{"server", "list", "--cluster=" + clusterUUID} //nolint:prealloc // dynamic append based on auth
+	args = append(args, c.clusterAuthArgs()...)
+
+	output, err := c.executeRAC(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := parseBlocks(output)
+	servers := make([]ServerInfo, 0, len(blocks))
+	for _, block := range blocks {
+		if block["server"] == "" {
+			continue
+		}
+		port, _ := strconv.Atoi(block["agent-port"])
+		servers = append(servers, ServerInfo{
+			UUID:      block["server"],
+			Name:      trimQuotes(block["name"]),
+			AgentHost: block["agent-host"],
+			AgentPort: port,
+			PortRange: block["port-range"],
+		})
+	}
+	return servers, nil
+}
+
+// ListProcesses возвращает рабочие процессы кластера (rac process list).
+func (c *racClient) ListProcesses(ctx context.Context, clusterUUID string) ([]ProcessInfo, error) {
+	c.logger.Debug("Получение списка рабочих процессов", "cluster", clusterUUID)
+
+	args := []string{"process", "list", "--cluster=" + clusterUUID} //nolint:prealloc // dynamic append based on auth
+	args = append(args, c.clusterAuthArgs()...)
+
+	output, err := c.executeRAC(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := parseBlocks(output)
+	processes := make([]ProcessInfo, 0, len(blocks))
+	for _, block := range blocks {
+		if block["process"] == "" {
+			continue
+		}
+		port, _ := strconv.Atoi(block["port"])
+		connections, _ := strconv.Atoi(block["connections"])
+		memory, _ := strconv.ParseInt(block["memory-size"], 10, 64)
+		processes = append(processes, ProcessInfo{
+			UUID:        block["process"],
+			Host:        block["host"],
+			Port:        port,
+			PID:         block["pid"],
+			Running:     strings.EqualFold(block["running"], "yes"),
+			StartedAt:   parseRACLocalTime(block["started-at"]),
+			Connections: connections,
+			MemoryKB:    memory,
+		})
+	}
+	return processes, nil
+}
@@ -0,0 +1,108 @@
+package rac
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListClusters(t *testing.T) {
+	output := `cluster : 11111111-1111-1111-1111-111111111111
+host    : srv-app
+port    : 1541
+name    : "Главный кластер"
+
+cluster : 22222222-2222-2222-2222-222222222222
+host    : srv-app
+port    : 1641
+name    : "Тестовый кластер"`
+	racPath, cleanup := createMockRAC(t, output)
+	defer cleanup()
+
+	c, err := NewClient(ClientOptions{RACPath: racPath, Server: "localhost"})
+	require.NoError(t, err)
+
+	clusters, err := c.(InventoryProvider).ListClusters(context.Background())
+	require.NoError(t, err)
+	require.Len(t, clusters, 2)
+	assert.Equal(t, "Тестовый кластер", clusters[1].Name)
+	assert.Equal(t, 1641, clusters[1].Port)
+}
+
+func TestListInfobases(t *testing.T) {
+	output := `infobase : aaaaaaaa-0000-0000-0000-000000000001
+name     : erp_prod
+descr    : "ERP"
+
+infobase : aaaaaaaa-0000-0000-0000-000000000002
+name     : erp_test
+descr    :`
+	racPath, cleanup := createMockRAC(t, output)
+	defer cleanup()
+
+	c, err := NewClient(ClientOptions{RACPath: racPath, Server: "localhost"})
+	require.NoError(t, err)
+
+	infobases, err := c.(InventoryProvider).ListInfobases(context.Background(), "cluster-uuid")
+	require.NoError(t, err)
+	require.Len(t, infobases, 2)
+	assert.Equal(t, "erp_prod", infobases[0].Name)
+	assert.Equal(t, "ERP", infobases[0].Description)
+	assert.Equal(t, "erp_test", infobases[1].Name)
+}
+
+func TestListServers(t *testing.T) {
+	output := `server     : bbbbbbbb-0000-0000-0000-000000000001
+agent-host : srv-app
+agent-port : 1540
+port-range : 1560:1591
+name       : "Центральный сервер"
+using      : main`
+	racPath, cleanup := createMockRAC(t, output)
+	defer cleanup()
+
+	c, err := NewClient(ClientOptions{RACPath: racPath, Server: "localhost"})
+	require.NoError(t, err)
+
+	servers, err := c.(InventoryProvider).ListServers(context.Background(), "cluster-uuid")
+	require.NoError(t, err)
+	require.Len(t, servers, 1)
+	assert.Equal(t, ServerInfo{
+		UUID:      "bbbbbbbb-0000-0000-0000-000000000001",
+		Name:      "Центральный сервер",
+		AgentHost: "srv-app",
+		AgentPort: 1540,
+		PortRange: "1560:1591",
+	}, servers[0])
+}
+
+func TestListProcesses(t *testing.T) {
+	output := `process     : cccccccc-0000-0000-0000-000000000001
+host        : srv-app
+port        : 1560
+pid         : 4242
+turned-on   : yes
+running     : yes
+started-at  : 2026-03-14T08:00:00
+connections : 17
+memory-size : 2097152`
+	racPath, cleanup := createMockRAC(t, output)
+	defer cleanup()
+
+	c, err := NewClient(ClientOptions{RACPath: racPath, Server: "localhost"})
+	require.NoError(t, err)
+
+	processes, err := c.(InventoryProvider).ListProcesses(context.Background(), "cluster-uuid")
+	require.NoError(t, err)
+	require.Len(t, processes, 1)
+	p := processes[0]
+	assert.Equal(t, "4242", p.PID)
+	assert.True(t, p.Running)
+	assert.Equal(t, 1560, p.Port)
+	assert.Equal(t, 17, p.Connections)
+	assert.Equal(t, int64(2097152), p.MemoryKB)
+	assert.Equal(t, time.Date(2026, 3, 14, 8, 0, 0, 0, time.Local), p.StartedAt)
+}
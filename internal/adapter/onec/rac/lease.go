@@ -0,0 +1,54 @@
+package rac
+
+import (
+	"net/url"
+	"strings"
+	"time"
+)
+
+// leasePrefix — признак аренды в параметре блокировки (--denied-parameter).
+const leasePrefix = "apk-ci-lease:"
+
+// Lease — аренда сервисного режима: кто включил блокировку и до какого момента
+// она считается удерживаемой. Хранится в параметре блокировки информационной
+// базы, поэтому не зависит от файлов на агенте, упавшем вместе с пайплайном.
+type Lease struct {
+	// Owner — владелец аренды (запуск пайплайна)
+	Owner string
+	// TraceID — trace_id команды, включившей сервисный режим
+	TraceID string
+	// Expires — окончание аренды
+	Expires time.Time
+}
+
+// String возвращает аренду в формате параметра блокировки.
+func (l *Lease) String() string {
+	v := url.Values{}
+	v.Set("owner", l.Owner)
+	v.Set("trace", l.TraceID)
+	v.Set("expires", l.Expires.UTC().Format(time.RFC3339))
+	return leasePrefix + v.Encode()
+}
+
+// Expired сообщает, истекла ли аренда в момент now.
+func (l *Lease) Expired(now time.Time) bool {
+	return !now.Before(l.Expires)
+}
+
+// ParseLease разбирает аренду из параметра блокировки.
+// Возвращает false, если параметр не содержит аренды (блокировка установлена вручную).
+func ParseLease(parameter string) (*Lease, bool) {
+	raw, ok := strings.CutPrefix(strings.TrimSpace(parameter), leasePrefix)
+	if !ok {
+		return nil, false
+	}
+	v, err := url.ParseQuery(raw)
+	if err != nil {
+		return nil, false
+	}
+	expires, err := time.Parse(time.RFC3339, v.Get("expires"))
+	if err != nil || v.Get("owner") == "" {
+		return nil, false
+	}
+	return &Lease{Owner: v.Get("owner"), TraceID: v.Get("trace"), Expires: expires}, true
+}
@@ -0,0 +1,50 @@
+package rac
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLease_RoundTrip(t *testing.T) {
+	expires := time.Date(2026, 3, 14, 20, 0, 0, 0, time.UTC)
+	lease := &Lease{Owner: "deploy erp#1542", TraceID: "abc123", Expires: expires}
+
+	parsed, ok := ParseLease(lease.String())
+	require.True(t, ok)
+	assert.Equal(t, "deploy erp#1542", parsed.Owner)
+	assert.Equal(t, "abc123", parsed.TraceID)
+	assert.True(t, expires.Equal(parsed.Expires))
+}
+
+func TestLease_Expired(t *testing.T) {
+	expires := time.Date(2026, 3, 14, 20, 0, 0, 0, time.UTC)
+	lease := &Lease{Owner: "ci", Expires: expires}
+
+	assert.False(t, lease.Expired(expires.Add(-time.Second)))
+	assert.True(t, lease.Expired(expires))
+	assert.True(t, lease.Expired(expires.Add(time.Hour)))
+}
+
+func TestParseLease_NotLease(t *testing.T) {
+	for _, parameter := range []string{
+		"",
+		"ручная блокировка",
+		"apk-ci-lease:owner=ci",
+		"apk-ci-lease:expires=2026-03-14T20:00:00Z",
+		"apk-ci-lease:owner=ci&expires=завтра",
+	} {
+		_, ok := ParseLease(parameter)
+		assert.False(t, ok, parameter)
+	}
+}
+
+func TestParseServiceModeStatus_DeniedParameter(t *testing.T) {
+	status := parseServiceModeStatus(map[string]string{
+		"sessions-deny":    "on",
+		"denied-parameter": `"apk-ci-lease:owner=ci"`,
+	})
+	assert.Equal(t, "apk-ci-lease:owner=ci", status.DeniedParameter)
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/Kargones/apk-ci/internal/constants"
 	"github.com/Kargones/apk-ci/internal/pkg/apperrors"
+	"github.com/Kargones/apk-ci/internal/pkg/platform"
 )
 
 // GetClusterInfo возвращает информацию о первом кластере 1C.
@@ -27,6 +28,39 @@ func (c *racClient) GetClusterInfo(ctx context.Context) (*ClusterInfo, error) {
 	return parseClusterInfo(blocks[0])
 }
 
+// GetAgentVersion возвращает версию агента сервера 1С (rac agent version).
+func (c *racClient) GetAgentVersion(ctx context.Context) (string, error) {
+	c.logger.Debug("Получение версии агента сервера 1С")
+
+	output, err := c.executeRAC(ctx, []string{"agent", "version"})
+	if err != nil {
+		return "", err
+	}
+	version := strings.TrimSpace(output)
+	if version == "" {
+		return "", apperrors.NewAppError(ErrRACParse, "версия агента отсутствует в выводе RAC", nil)
+	}
+	return version, nil
+}
+
+// ServerRequirement возвращает требование к версии платформы для баз сервера 1С
+// server: точную версию агента, запрошенную через client (VersionProvider).
+func ServerRequirement(ctx context.Context, client Client, server string) (platform.Requirement, error) {
+	vp, ok := client.(VersionProvider)
+	if !ok {
+		return platform.Requirement{}, apperrors.NewAppError(ErrRACExec, "RAC клиент не поддерживает запрос версии", nil)
+	}
+	raw, err := vp.GetAgentVersion(ctx)
+	if err != nil {
+		return platform.Requirement{}, err
+	}
+	version, err := platform.ParseVersion(raw)
+	if err != nil {
+		return platform.Requirement{}, err
+	}
+	return platform.Requirement{Exact: version, Source: "сервер 1С " + server}, nil
+}
+
 // GetInfobaseInfo возвращает информацию об информационной базе по имени.
 func (c *racClient) GetInfobaseInfo(ctx context.Context, clusterUUID, infobaseName string) (*InfobaseInfo, error) {
 	c.logger.Debug("Получение информации об информационной базе",
@@ -78,6 +112,62 @@ func (c *racClient) GetSessions(ctx context.Context, clusterUUID, infobaseUUID s
 	return sessions, nil
 }
 
+// GetSessionLicenses возвращает лицензии, занятые сессиями информационной базы.
+func (c *racClient) GetSessionLicenses(ctx context.Context, clusterUUID, infobaseUUID string) ([]SessionLicense, error) {
+	c.logger.Debug("Получение лицензий сессий",
+		"cluster", clusterUUID, "infobase", infobaseUUID)
+
+	args := []string{"session", "list", "--cluster=" + clusterUUID, "--infobase=" + infobaseUUID, "--licenses"} //nolint:prealloc // dynamic append based on auth
+	args = append(args, c.clusterAuthArgs()...)
+
+	output, err := c.executeRAC(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := parseBlocks(output)
+	licenses := make([]SessionLicense, 0, len(blocks))
+	for _, block := range blocks {
+		if block["session"] == "" {
+			continue
+		}
+		licenses = append(licenses, parseSessionLicense(block))
+	}
+
+	return licenses, nil
+}
+
+// GetInfobaseLocks возвращает блокировки, удерживаемые на информационной базе.
+func (c *racClient) GetInfobaseLocks(ctx context.Context, clusterUUID, infobaseUUID string) ([]LockInfo, error) {
+	c.logger.Debug("Получение блокировок информационной базы",
+		"cluster", clusterUUID, "infobase", infobaseUUID)
+
+	args := []string{"lock", "list", "--cluster=" + clusterUUID, "--infobase=" + infobaseUUID} //nolint:prealloc // dynamic append based on auth
+	args = append(args, c.clusterAuthArgs()...)
+
+	output, err := c.executeRAC(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := parseBlocks(output)
+	locks := make([]LockInfo, 0, len(blocks))
+	for _, block := range blocks {
+		if block["connection"] == "" && block["session"] == "" {
+			continue
+		}
+		locks = append(locks, LockInfo{
+			SessionID:    block["session"],
+			ConnectionID: block["connection"],
+			Object:       block["object"],
+			LockedAt:     parseRACLocalTime(block["locked"]),
+			Description:  trimQuotes(block["descr"]),
+		})
+	}
+
+	return locks, nil
+}
+
 // TerminateSession завершает конкретную сессию.
 func (c *racClient) TerminateSession(ctx context.Context, clusterUUID, sessionID string) error {
 	c.logger.Info("Завершение сессии", "cluster", clusterUUID, "session", sessionID)
@@ -130,49 +220,105 @@ func (c *racClient) EnableServiceMode(ctx context.Context, clusterUUID, infobase
 	c.logger.Debug("Включение сервисного режима",
 		"cluster", clusterUUID, "infobase", infobaseUUID)
 
+	if err := c.updateServiceMode(ctx, clusterUUID, infobaseUUID, ServiceModeWindow{From: time.Now()}, true); err != nil {
+		return err
+	}
+
+	c.logger.Debug("Сервисный режим включён")
+
+	if terminateSessions {
+		// TODO(#42): Рассмотреть возврат ошибки или partial success статуса вместо swallow.
+		// Текущее поведение: сервисный режим уже включён, ошибка завершения сессий
+		// не должна приводить к откату - это legacy паттерн для обратной совместимости.
+		if err := c.TerminateAllSessions(ctx, clusterUUID, infobaseUUID); err != nil {
+			c.logger.Warn("Ошибка завершения сессий после включения сервисного режима", "error", err)
+		}
+	}
+
+	return nil
+}
+
+// ScheduleServiceMode устанавливает окно блокировки сеансов --denied-from/--denied-to.
+// Регламентные задания блокируются, только если окно уже началось: у
+// --scheduled-jobs-deny нет окна, и блокировка будущего окна остановила бы задания
+// до его начала (см. rac.ServiceModeScheduler).
+func (c *racClient) ScheduleServiceMode(ctx context.Context, clusterUUID, infobaseUUID string, window ServiceModeWindow) error {
+	if window.From.IsZero() {
+		window.From = time.Now()
+	}
+	c.logger.Debug("Планирование сервисного режима",
+		"cluster", clusterUUID, "infobase", infobaseUUID,
+		"from", formatRACTime(window.From), "to", formatRACTime(window.To))
+
+	if err := c.updateServiceMode(ctx, clusterUUID, infobaseUUID, window, !window.From.After(time.Now())); err != nil {
+		return err
+	}
+	c.logger.Debug("Окно сервисного режима установлено")
+	return nil
+}
+
+// updateServiceMode включает блокировку сеансов в окне window и,
+// если blockJobs, блокировку регламентных заданий.
+func (c *racClient) updateServiceMode(ctx context.Context, clusterUUID, infobaseUUID string, window ServiceModeWindow, blockJobs bool) error {
 	// Проверяем, были ли регламентные задания заблокированы отдельно до сервисного режима.
 	// Если да — добавляем маркер "." в denied-message, чтобы DisableServiceMode
 	// не разблокировал их автоматически (legacy-паттерн).
-	deniedMessage := constants.DefaultServiceModeMessage
 	currentStatus, statusErr := c.getInfobaseRawStatus(ctx, clusterUUID, infobaseUUID)
 	if statusErr != nil {
 		c.logger.Warn("Не удалось проверить статус регламентных заданий, маркер не будет добавлен",
 			"error", statusErr)
-	} else if currentStatus.ScheduledJobsBlocked {
-		deniedMessage += "."
-		c.logger.Debug("Регламентные задания уже заблокированы, добавлен маркер в denied-message")
 	}
+	deniedMessage := ServiceModeMessage(currentStatus, window.Message)
 
 	args := []string{ //nolint:prealloc // dynamic append based on auth
 		"infobase", "update",
 		"--cluster=" + clusterUUID,
 		"--infobase=" + infobaseUUID,
 		"--sessions-deny=on",
-		"--scheduled-jobs-deny=on",
-		"--denied-from=" + time.Now().Format("2006-01-02T15:04:05"),
-		"--denied-message=" + deniedMessage,
-		"--permission-code=ServiceMode",
 	}
+	if blockJobs {
+		args = append(args, "--scheduled-jobs-deny=on")
+	}
+	args = append(args,
+		"--denied-from="+formatRACTime(window.From),
+		"--denied-to="+formatRACTime(window.To),
+		"--denied-message="+deniedMessage,
+		"--permission-code=ServiceMode",
+	)
 	args = append(args, c.clusterAuthArgs()...)
 	args = append(args, c.infobaseAuthArgs()...)
 
 	_, err := c.executeRAC(ctx, args)
-	if err != nil {
-		return err
-	}
-
-	c.logger.Debug("Сервисный режим включён")
+	return err
+}
 
-	if terminateSessions {
-		// TODO(#42): Рассмотреть возврат ошибки или partial success статуса вместо swallow.
-		// Текущее поведение: сервисный режим уже включён, ошибка завершения сессий
-		// не должна приводить к откату - это legacy паттерн для обратной совместимости.
-		if err := c.TerminateAllSessions(ctx, clusterUUID, infobaseUUID); err != nil {
-			c.logger.Warn("Ошибка завершения сессий после включения сервисного режима", "error", err)
-		}
+// ServiceModeMessage возвращает denied-message для включения блокировки сеансов.
+// Завершающие точки message отбрасываются: точка зарезервирована под маркер и
+// не должна появляться из текста вызывающего. Пустое message заменяется
+// на constants.DefaultServiceModeMessage. Если регламентные
+// задания были заблокированы отдельно до сервисного режима (current), добавляется
+// маркер "." — DisableServiceMode не разблокирует их (legacy-паттерн). Повторная
+// установка окна сохраняет маркер. current == nil — статус неизвестен, маркер не добавляется.
+func ServiceModeMessage(current *ServiceModeStatus, message string) string {
+	message = strings.TrimRight(strings.TrimSpace(message), ".")
+	if message == "" {
+		message = constants.DefaultServiceModeMessage
 	}
+	if current == nil {
+		return message
+	}
+	marked := strings.HasSuffix(current.Message, ".")
+	if (current.ScheduledJobsBlocked && !marked && !current.Enabled) || (current.Enabled && marked) {
+		message += "."
+	}
+	return message
+}
 
-	return nil
+// KeepScheduledJobsBlocked сообщает, что при отключении сервисного режима блокировку
+// регламентных заданий снимать не нужно: denied-message оканчивается маркером ".".
+// status == nil — статус неизвестен, блокировка снимается (fail-open).
+func KeepScheduledJobsBlocked(status *ServiceModeStatus) bool {
+	return status != nil && strings.HasSuffix(status.Message, ".")
 }
 
 // DisableServiceMode отключает сервисный режим для информационной базы.
@@ -196,14 +342,16 @@ func (c *racClient) DisableServiceMode(ctx context.Context, clusterUUID, infobas
 		"--infobase=" + infobaseUUID,
 		"--sessions-deny=off",
 		"--denied-from=",
+		"--denied-to=",
 		"--denied-message=",
+		"--denied-parameter=",
 		"--permission-code=",
 	}
 
 	// Снимаем блокировку регламентных заданий только если сообщение
 	// не оканчивается на "." (legacy-паттерн: точка в конце означает
 	// что задания были заблокированы отдельно от сервисного режима)
-	if status == nil || !strings.HasSuffix(status.Message, ".") {
+	if !KeepScheduledJobsBlocked(status) {
 		args = append(args, "--scheduled-jobs-deny=off")
 	} else {
 		c.logger.Debug("Блокировка регламентных заданий не снята (обнаружен маркер отдельной блокировки)")
@@ -221,6 +369,47 @@ func (c *racClient) DisableServiceMode(ctx context.Context, clusterUUID, infobas
 	return nil
 }
 
+// SetScheduledJobsDeny устанавливает блокировку регламентных заданий,
+// не изменяя остальные параметры сервисного режима.
+func (c *racClient) SetScheduledJobsDeny(ctx context.Context, clusterUUID, infobaseUUID string, deny bool) error {
+	c.logger.Debug("Изменение блокировки регламентных заданий",
+		"cluster", clusterUUID, "infobase", infobaseUUID, "deny", deny)
+
+	value := "off"
+	if deny {
+		value = "on"
+	}
+	args := []string{
+		"infobase", "update",
+		"--cluster=" + clusterUUID,
+		"--infobase=" + infobaseUUID,
+		"--scheduled-jobs-deny=" + value,
+	}
+	args = append(args, c.clusterAuthArgs()...)
+	args = append(args, c.infobaseAuthArgs()...)
+
+	_, err := c.executeRAC(ctx, args)
+	return err
+}
+
+// SetServiceModeLease записывает аренду сервисного режима в --denied-parameter.
+func (c *racClient) SetServiceModeLease(ctx context.Context, clusterUUID, infobaseUUID, parameter string) error {
+	c.logger.Debug("Запись аренды сервисного режима",
+		"cluster", clusterUUID, "infobase", infobaseUUID, "parameter", parameter)
+
+	args := []string{
+		"infobase", "update",
+		"--cluster=" + clusterUUID,
+		"--infobase=" + infobaseUUID,
+		"--denied-parameter=" + parameter,
+	}
+	args = append(args, c.clusterAuthArgs()...)
+	args = append(args, c.infobaseAuthArgs()...)
+
+	_, err := c.executeRAC(ctx, args)
+	return err
+}
+
 // getInfobaseRawStatus получает статус информационной базы без запроса сессий.
 // Используется для внутренних проверок перед изменением состояния.
 func (c *racClient) getInfobaseRawStatus(ctx context.Context, clusterUUID, infobaseUUID string) (*ServiceModeStatus, error) {
@@ -4,8 +4,11 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/Kargones/apk-ci/internal/constants"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -60,6 +63,36 @@ func TestGetClusterInfo_NoCluster(t *testing.T) {
 	assert.Contains(t, err.Error(), ErrRACNotFound)
 }
 
+// === Tests for GetAgentVersion ===
+
+func TestGetAgentVersion_Success(t *testing.T) {
+	racPath, cleanup := createMockRAC(t, "8.3.27.1606")
+	defer cleanup()
+
+	c, err := NewClient(ClientOptions{
+		RACPath: racPath,
+		Server:  "localhost",
+	})
+	require.NoError(t, err)
+
+	version, err := c.(VersionProvider).GetAgentVersion(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "8.3.27.1606", version)
+}
+
+func TestServerRequirement(t *testing.T) {
+	racPath, cleanup := createMockRAC(t, "8.3.24.1467")
+	defer cleanup()
+
+	c, err := NewClient(ClientOptions{RACPath: racPath, Server: "srv-app"})
+	require.NoError(t, err)
+
+	req, err := ServerRequirement(context.Background(), c, "srv-app")
+	require.NoError(t, err)
+	assert.Equal(t, "8.3.24.1467", req.Exact.String())
+	assert.Equal(t, "сервер 1С srv-app", req.Source)
+}
+
 // === Tests for GetInfobaseInfo ===
 
 func TestGetInfobaseInfo_Success(t *testing.T) {
@@ -133,6 +166,53 @@ func TestGetSessions_Empty(t *testing.T) {
 	assert.Empty(t, sessions)
 }
 
+func TestGetSessionLicenses_Success(t *testing.T) {
+	output := "session            : a1b2c3d4-e5f6-7890-abcd-ef1234567890\nuser-name          : User1\napp-id             : 1CV8C\nseries             : \"ORG8A\"\nissued-by-server   : yes\nlicense-type       : soft\nshort-presentation : \"Серв. 8100000000, 50 польз.\"\n\nsession            : b2c3d4e5-f6a7-8901-bcde-f12345678901\nuser-name          : User2\napp-id             : Designer\nissued-by-server   : no\nlicense-type       : HASP"
+	racPath, argsLog := createRecordingRAC(t, output)
+
+	c, err := NewClient(ClientOptions{
+		RACPath: racPath,
+		Server:  "localhost",
+	})
+	require.NoError(t, err)
+
+	licenses, err := c.(LicenseProvider).GetSessionLicenses(context.Background(), "cluster-uuid", "infobase-uuid")
+	require.NoError(t, err)
+	assert.Contains(t, lastCall(t, argsLog), "session list --cluster=cluster-uuid --infobase=infobase-uuid --licenses")
+	require.Len(t, licenses, 2)
+	assert.Equal(t, SessionLicense{
+		SessionID:      "a1b2c3d4-e5f6-7890-abcd-ef1234567890",
+		UserName:       "User1",
+		AppID:          "1CV8C",
+		LicenseType:    "soft",
+		Series:         "ORG8A",
+		IssuedByServer: true,
+		Presentation:   "Серв. 8100000000, 50 польз.",
+	}, licenses[0])
+	assert.False(t, licenses[1].IssuedByServer)
+	assert.Equal(t, "HASP", licenses[1].LicenseType)
+}
+
+func TestGetInfobaseLocks_Success(t *testing.T) {
+	output := "connection : 11111111-0000-0000-0000-000000000001\nsession    : 22222222-0000-0000-0000-000000000001\nobject     : 00000000-0000-0000-0000-000000000000\nlocked     : 2026-03-14T10:15:00\ndescr      : \"БД(сеанс ,монопольная)\"\n\nconnection : 11111111-0000-0000-0000-000000000002\nsession    : 00000000-0000-0000-0000-000000000000\nobject     : 33333333-0000-0000-0000-000000000001\nlocked     : 2026-03-14T10:16:00\ndescr      : \"БД(соединение ,разделяемая)\""
+	racPath, argsLog := createRecordingRAC(t, output)
+
+	c, err := NewClient(ClientOptions{
+		RACPath: racPath,
+		Server:  "localhost",
+	})
+	require.NoError(t, err)
+
+	locks, err := c.(LockProvider).GetInfobaseLocks(context.Background(), "cluster-uuid", "infobase-uuid")
+	require.NoError(t, err)
+	assert.Contains(t, lastCall(t, argsLog), "lock list --cluster=cluster-uuid --infobase=infobase-uuid")
+	require.Len(t, locks, 2)
+	assert.Equal(t, "22222222-0000-0000-0000-000000000001", locks[0].SessionID)
+	assert.Equal(t, "БД(сеанс ,монопольная)", locks[0].Description)
+	assert.Equal(t, 10, locks[0].LockedAt.Hour())
+	assert.Equal(t, "33333333-0000-0000-0000-000000000001", locks[1].Object)
+}
+
 // === Tests for TerminateSession ===
 
 func TestTerminateSession_Success(t *testing.T) {
@@ -239,6 +319,144 @@ func TestEnableServiceMode_WithScheduledJobsBlocked(t *testing.T) {
 	require.NoError(t, err)
 }
 
+// === Tests for ScheduleServiceMode ===
+
+// createRecordingRAC создаёт mock RAC, который выводит output и записывает аргументы вызовов в файл.
+func createRecordingRAC(t *testing.T, output string) (racPath, argsLog string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	racPath = filepath.Join(tmpDir, "mock-rac")
+	argsLog = filepath.Join(tmpDir, "args.log")
+
+	script := "#!/bin/sh\necho \"$@\" >> " + argsLog + "\ncat << 'EOF'\n" + output + "\nEOF\n"
+	require.NoError(t, os.WriteFile(racPath, []byte(script), 0755)) //nolint:gosec // тестовый скрипт
+	return racPath, argsLog
+}
+
+// lastCall возвращает аргументы последнего вызова mock RAC.
+func lastCall(t *testing.T, argsLog string) string {
+	t.Helper()
+	data, err := os.ReadFile(argsLog) //nolint:gosec // тестовый файл
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	return lines[len(lines)-1]
+}
+
+func TestScheduleServiceMode_FutureWindow(t *testing.T) {
+	racPath, argsLog := createRecordingRAC(t, "infobase            : test-uuid\nsessions-deny       : off\nscheduled-jobs-deny : off")
+
+	c, err := NewClient(ClientOptions{RACPath: racPath, Server: "localhost"})
+	require.NoError(t, err)
+
+	from := time.Now().Add(2 * time.Hour).Truncate(time.Second)
+	window := ServiceModeWindow{From: from, To: from.Add(time.Hour)}
+	err = c.(ServiceModeScheduler).ScheduleServiceMode(context.Background(), "cluster-uuid", "infobase-uuid", window)
+	require.NoError(t, err)
+
+	call := lastCall(t, argsLog)
+	assert.Contains(t, call, "--sessions-deny=on")
+	assert.Contains(t, call, "--denied-from="+from.Format(racTimeLayout))
+	assert.Contains(t, call, "--denied-to="+from.Add(time.Hour).Format(racTimeLayout))
+	assert.NotContains(t, call, "--scheduled-jobs-deny", "задания будущего окна не блокируются заранее")
+}
+
+func TestScheduleServiceMode_ActiveWindow(t *testing.T) {
+	racPath, argsLog := createRecordingRAC(t, "infobase            : test-uuid\nsessions-deny       : off\nscheduled-jobs-deny : off")
+
+	c, err := NewClient(ClientOptions{RACPath: racPath, Server: "localhost"})
+	require.NoError(t, err)
+
+	to := time.Now().Add(time.Hour).Truncate(time.Second)
+	err = c.(ServiceModeScheduler).ScheduleServiceMode(context.Background(), "cluster-uuid", "infobase-uuid", ServiceModeWindow{To: to})
+	require.NoError(t, err)
+
+	call := lastCall(t, argsLog)
+	assert.Contains(t, call, "--scheduled-jobs-deny=on")
+	assert.Contains(t, call, "--denied-to="+to.Format(racTimeLayout))
+	assert.Contains(t, call, "--denied-message="+constants.DefaultServiceModeMessage)
+}
+
+func TestScheduleServiceMode_Message(t *testing.T) {
+	racPath, argsLog := createRecordingRAC(t, "infobase            : test-uuid\nsessions-deny       : off\nscheduled-jobs-deny : off")
+
+	c, err := NewClient(ClientOptions{RACPath: racPath, Server: "localhost"})
+	require.NoError(t, err)
+
+	window := ServiceModeWindow{From: time.Now().Add(time.Hour), Message: "Обновление в 18:00"}
+	require.NoError(t, c.(ServiceModeScheduler).ScheduleServiceMode(context.Background(), "cluster-uuid", "infobase-uuid", window))
+	assert.Contains(t, lastCall(t, argsLog), "--denied-message=Обновление в 18:00 ")
+}
+
+func TestServiceModeMessage(t *testing.T) {
+	jobsBlocked := &ServiceModeStatus{ScheduledJobsBlocked: true}
+	tests := []struct {
+		name    string
+		current *ServiceModeStatus
+		message string
+		want    string
+	}{
+		{"пустое сообщение", nil, "", constants.DefaultServiceModeMessage},
+		{"точка вызывающего отбрасывается", nil, "Закройте программу.", "Закройте программу"},
+		{"только точки", nil, "...", constants.DefaultServiceModeMessage},
+		{"маркер отдельной блокировки", jobsBlocked, "Закройте программу.", "Закройте программу."},
+		{"маркер сохраняется", &ServiceModeStatus{Enabled: true, Message: "Работы."}, "Окно", "Окно."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ServiceModeMessage(tt.current, tt.message))
+		})
+	}
+}
+
+func TestEnableServiceMode_ClearsDeniedTo(t *testing.T) {
+	racPath, argsLog := createRecordingRAC(t, "infobase            : test-uuid\nsessions-deny       : off\nscheduled-jobs-deny : off")
+
+	c, err := NewClient(ClientOptions{RACPath: racPath, Server: "localhost"})
+	require.NoError(t, err)
+
+	require.NoError(t, c.EnableServiceMode(context.Background(), "cluster-uuid", "infobase-uuid", false))
+	assert.Contains(t, lastCall(t, argsLog), "--denied-to= ")
+}
+
+func TestSetScheduledJobsDeny(t *testing.T) {
+	racPath, argsLog := createRecordingRAC(t, "")
+
+	c, err := NewClient(ClientOptions{RACPath: racPath, Server: "localhost"})
+	require.NoError(t, err)
+	jobs := c.(ScheduledJobsController)
+
+	require.NoError(t, jobs.SetScheduledJobsDeny(context.Background(), "cluster-uuid", "infobase-uuid", true))
+	call := lastCall(t, argsLog)
+	assert.Contains(t, call, "infobase update")
+	assert.Contains(t, call, "--scheduled-jobs-deny=on")
+	assert.NotContains(t, call, "--sessions-deny", "сервисный режим не изменяется")
+
+	require.NoError(t, jobs.SetScheduledJobsDeny(context.Background(), "cluster-uuid", "infobase-uuid", false))
+	assert.Contains(t, lastCall(t, argsLog), "--scheduled-jobs-deny=off")
+}
+
+func TestSetServiceModeLease(t *testing.T) {
+	racPath, argsLog := createRecordingRAC(t, "")
+
+	c, err := NewClient(ClientOptions{RACPath: racPath, Server: "localhost"})
+	require.NoError(t, err)
+
+	require.NoError(t, c.(ServiceModeLeaser).SetServiceModeLease(context.Background(), "cluster-uuid", "infobase-uuid", "apk-ci-lease:owner=ci"))
+	call := lastCall(t, argsLog)
+	assert.Contains(t, call, "--denied-parameter=apk-ci-lease:owner=ci")
+	assert.NotContains(t, call, "--sessions-deny")
+}
+
+func TestDisableServiceMode_ClearsLease(t *testing.T) {
+	racPath, argsLog := createRecordingRAC(t, "infobase            : test-uuid\nsessions-deny       : on\nscheduled-jobs-deny : on")
+
+	c, err := NewClient(ClientOptions{RACPath: racPath, Server: "localhost"})
+	require.NoError(t, err)
+
+	require.NoError(t, c.DisableServiceMode(context.Background(), "cluster-uuid", "infobase-uuid"))
+	assert.Contains(t, lastCall(t, argsLog), "--denied-parameter= ")
+}
+
 // === Tests for DisableServiceMode ===
 
 func TestDisableServiceMode_Success(t *testing.T) {
@@ -291,6 +509,36 @@ func TestGetServiceModeStatus_Enabled(t *testing.T) {
 	assert.Equal(t, "Database update", status.Message)
 }
 
+func TestGetServiceModeStatus_Window(t *testing.T) {
+	output := "infobase            : test-uuid\nsessions-deny       : on\nscheduled-jobs-deny : off\ndenied-from         : 2026-03-14T22:00:00\ndenied-to           : 2026-03-15T02:00:00\ndenied-message      : \"Update\""
+	racPath, cleanup := createMockRAC(t, output)
+	defer cleanup()
+
+	c, err := NewClient(ClientOptions{
+		RACPath: racPath,
+		Server:  "localhost",
+	})
+	require.NoError(t, err)
+
+	status, err := c.GetServiceModeStatus(context.Background(), "cluster-uuid", "infobase-uuid")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 3, 14, 22, 0, 0, 0, time.Local), status.DeniedFrom)
+	assert.Equal(t, time.Date(2026, 3, 15, 2, 0, 0, 0, time.Local), status.DeniedTo)
+
+	assert.True(t, status.Scheduled(time.Date(2026, 3, 14, 12, 0, 0, 0, time.Local)))
+	assert.False(t, status.Active(time.Date(2026, 3, 14, 12, 0, 0, 0, time.Local)))
+	assert.True(t, status.Active(time.Date(2026, 3, 14, 23, 0, 0, 0, time.Local)))
+	assert.False(t, status.Active(time.Date(2026, 3, 15, 3, 0, 0, 0, time.Local)), "окно завершилось")
+	assert.False(t, status.Ended(time.Date(2026, 3, 14, 23, 0, 0, 0, time.Local)))
+	assert.True(t, status.Ended(time.Date(2026, 3, 15, 3, 0, 0, 0, time.Local)))
+}
+
+func TestParseRACLocalTime_Empty(t *testing.T) {
+	assert.True(t, parseRACLocalTime("").IsZero())
+	assert.True(t, parseRACLocalTime("0001-01-01T00:00:00").IsZero())
+	assert.Empty(t, formatRACTime(time.Time{}))
+}
+
 func TestGetServiceModeStatus_Disabled(t *testing.T) {
 	output := "infobase            : test-uuid\nsessions-deny       : off\nscheduled-jobs-deny : off"
 	racPath, cleanup := createMockRAC(t, output)
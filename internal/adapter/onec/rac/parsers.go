@@ -89,20 +89,26 @@ func parseSessionInfo(block map[string]string) (*SessionInfo, error) {
 		UserName:  block["user-name"],
 		AppID:     block["app-id"],
 		Host:      block["host"],
-	}
-	if v, ok := block["started-at"]; ok && v != "" {
-		if t, err := time.Parse("2006-01-02T15:04:05", v); err == nil {
-			info.StartedAt = t
-		}
-	}
-	if v, ok := block["last-active-at"]; ok && v != "" {
-		if t, err := time.Parse("2006-01-02T15:04:05", v); err == nil {
-			info.LastActiveAt = t
-		}
+		// RAC выводит время сервера 1C без часового пояса — сравнимо с time.Now() только как локальное
+		StartedAt:    parseRACLocalTime(block["started-at"]),
+		LastActiveAt: parseRACLocalTime(block["last-active-at"]),
 	}
 	return info, nil
 }
 
+// parseSessionLicense парсит блок key-value вывода session list --licenses в SessionLicense.
+func parseSessionLicense(block map[string]string) SessionLicense {
+	return SessionLicense{
+		SessionID:      block["session"],
+		UserName:       block["user-name"],
+		AppID:          block["app-id"],
+		LicenseType:    block["license-type"],
+		Series:         trimQuotes(block["series"]),
+		IssuedByServer: block["issued-by-server"] == "yes",
+		Presentation:   trimQuotes(block["short-presentation"]),
+	}
+}
+
 // parseServiceModeStatus парсит блок key-value в ServiceModeStatus.
 func parseServiceModeStatus(block map[string]string) *ServiceModeStatus {
 	status := &ServiceModeStatus{}
@@ -115,5 +121,33 @@ func parseServiceModeStatus(block map[string]string) *ServiceModeStatus {
 	if v, ok := block["denied-message"]; ok {
 		status.Message = trimQuotes(v)
 	}
+	status.DeniedFrom = parseRACLocalTime(block["denied-from"])
+	status.DeniedTo = parseRACLocalTime(block["denied-to"])
+	status.DeniedParameter = trimQuotes(block["denied-parameter"])
 	return status
 }
+
+// racTimeLayout — формат времени RAC (без часового пояса).
+const racTimeLayout = "2006-01-02T15:04:05"
+
+// parseRACLocalTime разбирает время окна блокировки в локальном часовом поясе.
+// Пустое значение и нулевая дата RAC (0001-01-01T00:00:00) возвращают нулевое время.
+func parseRACLocalTime(v string) time.Time {
+	v = trimQuotes(strings.TrimSpace(v))
+	if v == "" {
+		return time.Time{}
+	}
+	t, err := time.ParseInLocation(racTimeLayout, v, time.Local)
+	if err != nil || t.Year() <= 1 {
+		return time.Time{}
+	}
+	return t
+}
+
+// formatRACTime форматирует время для аргументов RAC (пусто для нулевого времени).
+func formatRACTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.In(time.Local).Format(racTimeLayout)
+}
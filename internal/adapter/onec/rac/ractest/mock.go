@@ -17,6 +17,7 @@ var (
 	_ rac.InfobaseProvider   = (*MockRACClient)(nil)
 	_ rac.SessionProvider    = (*MockRACClient)(nil)
 	_ rac.ServiceModeManager = (*MockRACClient)(nil)
+	_ rac.VersionProvider    = (*MockRACClient)(nil)
 )
 
 // MockRACClient — мок-реализация rac.Client для тестирования.
@@ -40,6 +41,34 @@ type MockRACClient struct {
 	GetServiceModeStatusFunc func(ctx context.Context, clusterUUID, infobaseUUID string) (*rac.ServiceModeStatus, error)
 	// VerifyServiceModeFunc — пользовательская реализация VerifyServiceMode
 	VerifyServiceModeFunc func(ctx context.Context, clusterUUID, infobaseUUID string, expectedEnabled bool) error
+	// GetAgentVersionFunc — пользовательская реализация GetAgentVersion
+	GetAgentVersionFunc func(ctx context.Context) (string, error)
+	// ScheduleServiceModeFunc — пользовательская реализация ScheduleServiceMode
+	ScheduleServiceModeFunc func(ctx context.Context, clusterUUID, infobaseUUID string, window rac.ServiceModeWindow) error
+	// ListClustersFunc — пользовательская реализация ListClusters
+	ListClustersFunc func(ctx context.Context) ([]rac.ClusterInfo, error)
+	// ListInfobasesFunc — пользовательская реализация ListInfobases
+	ListInfobasesFunc func(ctx context.Context, clusterUUID string) ([]rac.InfobaseInfo, error)
+	// ListServersFunc — пользовательская реализация ListServers
+	ListServersFunc func(ctx context.Context, clusterUUID string) ([]rac.ServerInfo, error)
+	// ListProcessesFunc — пользовательская реализация ListProcesses
+	ListProcessesFunc func(ctx context.Context, clusterUUID string) ([]rac.ProcessInfo, error)
+	// GetSessionLicensesFunc — пользовательская реализация GetSessionLicenses
+	GetSessionLicensesFunc func(ctx context.Context, clusterUUID, infobaseUUID string) ([]rac.SessionLicense, error)
+	// SetScheduledJobsDenyFunc — пользовательская реализация SetScheduledJobsDeny
+	SetScheduledJobsDenyFunc func(ctx context.Context, clusterUUID, infobaseUUID string, deny bool) error
+	// SetServiceModeLeaseFunc — пользовательская реализация SetServiceModeLease
+	SetServiceModeLeaseFunc func(ctx context.Context, clusterUUID, infobaseUUID, parameter string) error
+	// CreateInfobaseFunc — пользовательская реализация CreateInfobase
+	CreateInfobaseFunc func(ctx context.Context, clusterUUID string, params rac.InfobaseParams) (*rac.InfobaseInfo, error)
+	// GetInfobaseParamsFunc — пользовательская реализация GetInfobaseParams
+	GetInfobaseParamsFunc func(ctx context.Context, clusterUUID, infobaseUUID string) (*rac.InfobaseParams, error)
+	// UpdateInfobaseDBMSFunc — пользовательская реализация UpdateInfobaseDBMS
+	UpdateInfobaseDBMSFunc func(ctx context.Context, clusterUUID, infobaseUUID string, params rac.InfobaseParams) error
+	// DropInfobaseFunc — пользовательская реализация DropInfobase
+	DropInfobaseFunc func(ctx context.Context, clusterUUID, infobaseUUID string, mode rac.InfobaseDropMode) error
+	// GetInfobaseLocksFunc — пользовательская реализация GetInfobaseLocks
+	GetInfobaseLocksFunc func(ctx context.Context, clusterUUID, infobaseUUID string) ([]rac.LockInfo, error)
 }
 
 // GetClusterInfo возвращает информацию о кластере.
@@ -140,6 +169,143 @@ func (m *MockRACClient) VerifyServiceMode(ctx context.Context, clusterUUID, info
 	return nil
 }
 
+// GetAgentVersion возвращает версию агента сервера 1С.
+// При отсутствии пользовательской функции возвращает тестовую версию.
+func (m *MockRACClient) GetAgentVersion(ctx context.Context) (string, error) {
+	if m.GetAgentVersionFunc != nil {
+		return m.GetAgentVersionFunc(ctx)
+	}
+	return "8.3.27.1606", nil
+}
+
+// ScheduleServiceMode устанавливает окно сервисного режима.
+// При отсутствии пользовательской функции возвращает nil.
+func (m *MockRACClient) ScheduleServiceMode(ctx context.Context, clusterUUID, infobaseUUID string, window rac.ServiceModeWindow) error {
+	if m.ScheduleServiceModeFunc != nil {
+		return m.ScheduleServiceModeFunc(ctx, clusterUUID, infobaseUUID, window)
+	}
+	return nil
+}
+
+// ListClusters возвращает кластеры сервера.
+// При отсутствии пользовательской функции возвращает кластер из GetClusterInfo.
+func (m *MockRACClient) ListClusters(ctx context.Context) ([]rac.ClusterInfo, error) {
+	if m.ListClustersFunc != nil {
+		return m.ListClustersFunc(ctx)
+	}
+	cluster, err := m.GetClusterInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return []rac.ClusterInfo{*cluster}, nil
+}
+
+// ListInfobases возвращает информационные базы кластера.
+// При отсутствии пользовательской функции возвращает пустой список.
+func (m *MockRACClient) ListInfobases(ctx context.Context, clusterUUID string) ([]rac.InfobaseInfo, error) {
+	if m.ListInfobasesFunc != nil {
+		return m.ListInfobasesFunc(ctx, clusterUUID)
+	}
+	return []rac.InfobaseInfo{}, nil
+}
+
+// ListServers возвращает рабочие серверы кластера.
+// При отсутствии пользовательской функции возвращает пустой список.
+func (m *MockRACClient) ListServers(ctx context.Context, clusterUUID string) ([]rac.ServerInfo, error) {
+	if m.ListServersFunc != nil {
+		return m.ListServersFunc(ctx, clusterUUID)
+	}
+	return []rac.ServerInfo{}, nil
+}
+
+// ListProcesses возвращает рабочие процессы кластера.
+// При отсутствии пользовательской функции возвращает пустой список.
+func (m *MockRACClient) ListProcesses(ctx context.Context, clusterUUID string) ([]rac.ProcessInfo, error) {
+	if m.ListProcessesFunc != nil {
+		return m.ListProcessesFunc(ctx, clusterUUID)
+	}
+	return []rac.ProcessInfo{}, nil
+}
+
+// GetSessionLicenses возвращает лицензии сессий.
+// При отсутствии пользовательской функции возвращает пустой список.
+func (m *MockRACClient) GetSessionLicenses(ctx context.Context, clusterUUID, infobaseUUID string) ([]rac.SessionLicense, error) {
+	if m.GetSessionLicensesFunc != nil {
+		return m.GetSessionLicensesFunc(ctx, clusterUUID, infobaseUUID)
+	}
+	return []rac.SessionLicense{}, nil
+}
+
+// SetScheduledJobsDeny изменяет блокировку регламентных заданий.
+// При отсутствии пользовательской функции возвращает nil.
+func (m *MockRACClient) SetScheduledJobsDeny(ctx context.Context, clusterUUID, infobaseUUID string, deny bool) error {
+	if m.SetScheduledJobsDenyFunc != nil {
+		return m.SetScheduledJobsDenyFunc(ctx, clusterUUID, infobaseUUID, deny)
+	}
+	return nil
+}
+
+// SetServiceModeLease записывает аренду сервисного режима.
+// При отсутствии пользовательской функции возвращает nil.
+func (m *MockRACClient) SetServiceModeLease(ctx context.Context, clusterUUID, infobaseUUID, parameter string) error {
+	if m.SetServiceModeLeaseFunc != nil {
+		return m.SetServiceModeLeaseFunc(ctx, clusterUUID, infobaseUUID, parameter)
+	}
+	return nil
+}
+
+// CreateInfobase регистрирует информационную базу.
+// При отсутствии пользовательской функции возвращает тестовые данные.
+func (m *MockRACClient) CreateInfobase(ctx context.Context, clusterUUID string, params rac.InfobaseParams) (*rac.InfobaseInfo, error) {
+	if m.CreateInfobaseFunc != nil {
+		return m.CreateInfobaseFunc(ctx, clusterUUID, params)
+	}
+	return &rac.InfobaseInfo{UUID: "test-infobase-uuid", Name: params.Name, Description: params.Description}, nil
+}
+
+// GetInfobaseParams возвращает параметры СУБД информационной базы.
+// При отсутствии пользовательской функции возвращает тестовые данные.
+func (m *MockRACClient) GetInfobaseParams(ctx context.Context, clusterUUID, infobaseUUID string) (*rac.InfobaseParams, error) {
+	if m.GetInfobaseParamsFunc != nil {
+		return m.GetInfobaseParamsFunc(ctx, clusterUUID, infobaseUUID)
+	}
+	return &rac.InfobaseParams{
+		Name:     "test-infobase",
+		DBMS:     "MSSQLServer",
+		DBServer: "test-db-server",
+		DBName:   "test-infobase",
+		DBUser:   "sa",
+		Locale:   "ru",
+	}, nil
+}
+
+// UpdateInfobaseDBMS изменяет параметры СУБД информационной базы.
+// При отсутствии пользовательской функции возвращает nil.
+func (m *MockRACClient) UpdateInfobaseDBMS(ctx context.Context, clusterUUID, infobaseUUID string, params rac.InfobaseParams) error {
+	if m.UpdateInfobaseDBMSFunc != nil {
+		return m.UpdateInfobaseDBMSFunc(ctx, clusterUUID, infobaseUUID, params)
+	}
+	return nil
+}
+
+// DropInfobase удаляет информационную базу.
+// При отсутствии пользовательской функции возвращает nil.
+func (m *MockRACClient) DropInfobase(ctx context.Context, clusterUUID, infobaseUUID string, mode rac.InfobaseDropMode) error {
+	if m.DropInfobaseFunc != nil {
+		return m.DropInfobaseFunc(ctx, clusterUUID, infobaseUUID, mode)
+	}
+	return nil
+}
+
+// GetInfobaseLocks возвращает блокировки информационной базы.
+// При отсутствии пользовательской функции возвращает пустой список.
+func (m *MockRACClient) GetInfobaseLocks(ctx context.Context, clusterUUID, infobaseUUID string) ([]rac.LockInfo, error) {
+	if m.GetInfobaseLocksFunc != nil {
+		return m.GetInfobaseLocksFunc(ctx, clusterUUID, infobaseUUID)
+	}
+	return []rac.LockInfo{}, nil
+}
+
 // NewMockRACClient создаёт MockRACClient с дефолтными тестовыми данными.
 func NewMockRACClient() *MockRACClient {
 	return &MockRACClient{}
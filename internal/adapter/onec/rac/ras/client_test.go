@@ -0,0 +1,388 @@
+package ras
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
+	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/pkg/apperrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	clusterUUID  = "6d6a2f3a-1b1c-11ee-8b0f-0050569f3d6a"
+	infobaseUUID = "0b2bd6a4-2a3e-4d5f-9a8b-1c2d3e4f5a6b"
+	sessionUUID  = "a1b2c3d4-e5f6-7890-abcd-ef1234567890"
+)
+
+func TestCodec_Size(t *testing.T) {
+	for _, n := range []int{0, 1, 63, 64, 8191, 8192, 1 << 20} {
+		var e encoder
+		e.size(n)
+		d := newDecoder(e.bytes())
+		assert.Equal(t, n, d.size())
+		require.NoError(t, d.err)
+	}
+
+	var e encoder
+	e.size(300)
+	assert.Equal(t, []byte{0x6c, 0x04}, e.bytes(), "300 = 0b100_101100: 6 бит + флаг 0x40, затем 4")
+}
+
+func TestCodec_TimeRoundTrip(t *testing.T) {
+	at := time.Date(2026, 3, 14, 18, 5, 0, 0, time.Local)
+	var e encoder
+	e.time(at)
+	e.time(time.Time{})
+
+	d := newDecoder(e.bytes())
+	assert.True(t, at.Equal(d.time()))
+	assert.True(t, d.time().IsZero())
+	require.NoError(t, d.err)
+}
+
+func TestCodec_TruncatedMessage(t *testing.T) {
+	var e encoder
+	e.size(10)
+	e.buf.WriteString("abc")
+	d := newDecoder(e.bytes())
+	d.string()
+	assert.Error(t, d.err)
+}
+
+// TestHandshake_Golden фиксирует байты согласования протокола и открытия endpoint.
+func TestHandshake_Golden(t *testing.T) {
+	client, server := net.Pipe()
+	defer func() { _ = server.Close() }()
+
+	go func() {
+		cn := &conn{nc: client, r: bufio.NewReader(client)}
+		_ = cn.handshake()
+	}()
+
+	negotiate := make([]byte, 11)
+	_, err := io.ReadFull(server, negotiate)
+	require.NoError(t, err)
+	assert.Equal(t, "1c535750"+"0100"+"0100"+"01"+"01"+"00", hex.EncodeToString(negotiate),
+		"сигнатура, версии протокола, пакет Connect с пустыми параметрами")
+
+	_, err = server.Write([]byte{byte(packetConnectAck), 0})
+	require.NoError(t, err)
+
+	open := make([]byte, 2+1+len(serviceName)+1+len(serviceVersion)+1)
+	_, err = io.ReadFull(server, open)
+	require.NoError(t, err)
+	assert.Equal(t, byte(packetEndpointOpen), open[0])
+	assert.Equal(t, byte(len(open)-2), open[1])
+	assert.Equal(t, serviceName, string(open[3:3+len(serviceName)]))
+	assert.Equal(t, serviceVersion, string(open[4+len(serviceName):4+len(serviceName)+len(serviceVersion)]))
+}
+
+func TestClient_GetClusterInfo(t *testing.T) {
+	s := newStandIn(t, map[messageKind]func(*decoder) reply{
+		kindGetClusters: func(*decoder) reply {
+			var e encoder
+			e.size(2)
+			encodeCluster(&e, rac.ClusterInfo{UUID: clusterUUID, Name: "Локальный кластер", Host: "srv-app", Port: 1541})
+			encodeCluster(&e, rac.ClusterInfo{UUID: "7d6a2f3a-1b1c-11ee-8b0f-0050569f3d6a", Name: "second", Host: "srv-app", Port: 2541})
+			return reply{kind: kindGetClustersResponse, body: e.bytes()}
+		},
+	})
+
+	c := s.client(t)
+	cl, err := c.GetClusterInfo(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &rac.ClusterInfo{UUID: clusterUUID, Name: "Локальный кластер", Host: "srv-app", Port: 1541}, cl)
+
+	// Повторный запрос использует то же соединение
+	_, err = c.GetClusterInfo(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, s.accepted)
+}
+
+func TestClient_GetInfobaseInfo(t *testing.T) {
+	s := newStandIn(t, map[messageKind]func(*decoder) reply{
+		kindGetInfobasesShort: func(*decoder) reply {
+			var e encoder
+			e.size(2)
+			encodeInfobaseShort(&e, rac.InfobaseInfo{UUID: "1b2bd6a4-2a3e-4d5f-9a8b-1c2d3e4f5a6b", Name: "zup"})
+			encodeInfobaseShort(&e, rac.InfobaseInfo{UUID: infobaseUUID, Name: "erp", Description: "ERP"})
+			return reply{kind: kindGetInfobasesShortResp, body: e.bytes()}
+		},
+	})
+
+	c := s.client(t)
+	ib, err := c.GetInfobaseInfo(context.Background(), clusterUUID, "erp")
+	require.NoError(t, err)
+	assert.Equal(t, &rac.InfobaseInfo{UUID: infobaseUUID, Name: "erp", Description: "ERP"}, ib)
+	assert.Equal(t, []messageKind{kindAuthenticateCluster, kindGetInfobasesShort}, s.kinds())
+
+	auth := s.last(kindAuthenticateCluster)
+	assert.Equal(t, clusterUUID, auth.uuid())
+	assert.Equal(t, "admin", auth.string())
+	assert.Equal(t, "secret", auth.string())
+
+	_, err = c.GetInfobaseInfo(context.Background(), clusterUUID, "missing")
+	require.Error(t, err)
+	assert.Equal(t, rac.ErrRACNotFound, appCode(err))
+}
+
+func TestClient_GetSessions(t *testing.T) {
+	started := time.Date(2026, 3, 14, 9, 0, 0, 0, time.Local)
+	active := time.Date(2026, 3, 14, 10, 30, 0, 0, time.Local)
+	s := newStandIn(t, map[messageKind]func(*decoder) reply{
+		kindGetInfobaseSessions: func(d *decoder) reply {
+			var e encoder
+			e.size(2)
+			encodeSession(&e, rac.SessionInfo{SessionID: sessionUUID, UserName: "Иванов", AppID: "1CV8C",
+				Host: "ws-01", StartedAt: started, LastActiveAt: active}, 1)
+			encodeSession(&e, rac.SessionInfo{SessionID: "b2c3d4e5-f6a7-8901-bcde-f12345678901", UserName: "deploy",
+				AppID: "Designer", Host: "ci"}, 0)
+			return reply{kind: kindGetInfobaseSessResp, body: e.bytes()}
+		},
+	})
+
+	sessions, err := s.client(t).GetSessions(context.Background(), clusterUUID, infobaseUUID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, sessionUUID, sessions[0].SessionID)
+	assert.Equal(t, "Иванов", sessions[0].UserName)
+	assert.Equal(t, "ws-01", sessions[0].Host)
+	assert.True(t, started.Equal(sessions[0].StartedAt))
+	assert.True(t, active.Equal(sessions[0].LastActiveAt))
+	assert.Equal(t, "Designer", sessions[1].AppID)
+
+	req := s.last(kindGetInfobaseSessions)
+	assert.Equal(t, clusterUUID, req.uuid())
+	assert.Equal(t, infobaseUUID, req.uuid())
+}
+
+// infobaseStandIn — stand-in сервер с одной информационной базой, хранящий её описание.
+func infobaseStandIn(t *testing.T, info *infobaseInfo) *standIn {
+	t.Helper()
+	return newStandIn(t, map[messageKind]func(*decoder) reply{
+		kindGetInfobaseInfo: func(*decoder) reply {
+			var e encoder
+			require.NoError(t, info.encode(&e))
+			return reply{kind: kindGetInfobaseInfoResp, body: e.bytes()}
+		},
+		kindUpdateInfobase: func(d *decoder) reply {
+			d.uuid()
+			*info = *decodeInfobaseInfo(d)
+			return reply{}
+		},
+		kindGetInfobaseSessions: func(*decoder) reply {
+			var e encoder
+			e.size(1)
+			encodeSession(&e, rac.SessionInfo{SessionID: sessionUUID, UserName: "Иванов", AppID: "1CV8C"}, 0)
+			return reply{kind: kindGetInfobaseSessResp, body: e.bytes()}
+		},
+	})
+}
+
+func TestClient_ServiceMode(t *testing.T) {
+	info := &infobaseInfo{uuid: infobaseUUID, name: "erp", dbms: "MSSQLServer", dbServer: "sql-1", dbName: "erp", locale: "ru_RU"}
+	s := infobaseStandIn(t, info)
+	c := s.client(t)
+	ctx := context.Background()
+
+	require.NoError(t, c.EnableServiceMode(ctx, clusterUUID, infobaseUUID, false))
+	assert.True(t, info.sessionsDeny)
+	assert.True(t, info.scheduledJobsDeny)
+	assert.Equal(t, constants.DefaultServiceModeMessage, info.deniedMessage)
+	assert.Equal(t, "ServiceMode", info.permissionCode)
+	assert.Equal(t, "sql-1", info.dbServer, "остальные поля базы сохраняются")
+
+	auth := s.last(kindAddAuthentication)
+	auth.uuid()
+	assert.Equal(t, "deploy", auth.string())
+
+	status, err := c.GetServiceModeStatus(ctx, clusterUUID, infobaseUUID)
+	require.NoError(t, err)
+	assert.True(t, status.Enabled)
+	assert.Equal(t, 1, status.ActiveSessions)
+	require.NoError(t, c.VerifyServiceMode(ctx, clusterUUID, infobaseUUID, true))
+
+	require.NoError(t, c.DisableServiceMode(ctx, clusterUUID, infobaseUUID))
+	assert.False(t, info.sessionsDeny)
+	assert.False(t, info.scheduledJobsDeny)
+	assert.Empty(t, info.deniedMessage)
+	assert.Empty(t, info.permissionCode)
+
+	err = c.VerifyServiceMode(ctx, clusterUUID, infobaseUUID, true)
+	assert.Equal(t, rac.ErrRACVerify, appCode(err))
+}
+
+func TestClient_ServiceMode_KeepsSeparateJobsBlock(t *testing.T) {
+	info := &infobaseInfo{uuid: infobaseUUID, name: "erp", scheduledJobsDeny: true}
+	c := infobaseStandIn(t, info).client(t)
+	ctx := context.Background()
+
+	require.NoError(t, c.EnableServiceMode(ctx, clusterUUID, infobaseUUID, false))
+	assert.Equal(t, constants.DefaultServiceModeMessage+".", info.deniedMessage)
+
+	require.NoError(t, c.DisableServiceMode(ctx, clusterUUID, infobaseUUID))
+	assert.True(t, info.scheduledJobsDeny, "задания были заблокированы отдельно")
+}
+
+func TestClient_ScheduleServiceMode(t *testing.T) {
+	info := &infobaseInfo{uuid: infobaseUUID, name: "erp"}
+	c := infobaseStandIn(t, info).client(t)
+
+	from := time.Now().Add(2 * time.Hour).Truncate(time.Second)
+	to := from.Add(time.Hour)
+	require.NoError(t, c.ScheduleServiceMode(context.Background(), clusterUUID, infobaseUUID,
+		rac.ServiceModeWindow{From: from, To: to, Message: "Обновление в 20:00"}))
+
+	assert.True(t, info.sessionsDeny)
+	assert.False(t, info.scheduledJobsDeny, "задания будущего окна не блокируются заранее")
+	assert.True(t, from.Equal(info.deniedFrom))
+	assert.True(t, to.Equal(info.deniedTo))
+	assert.Equal(t, "Обновление в 20:00", info.deniedMessage)
+}
+
+func TestClient_ScheduleServiceMode_ActiveWindow(t *testing.T) {
+	info := &infobaseInfo{uuid: infobaseUUID, name: "erp"}
+	c := infobaseStandIn(t, info).client(t)
+
+	to := time.Now().Add(time.Hour).Truncate(time.Second)
+	require.NoError(t, c.ScheduleServiceMode(context.Background(), clusterUUID, infobaseUUID,
+		rac.ServiceModeWindow{To: to}))
+
+	assert.True(t, info.sessionsDeny)
+	assert.True(t, info.scheduledJobsDeny, "окно началось — задания блокируются")
+	assert.True(t, to.Equal(info.deniedTo))
+}
+
+func TestClient_SetScheduledJobsDeny(t *testing.T) {
+	info := &infobaseInfo{uuid: infobaseUUID, name: "erp", sessionsDeny: true, deniedMessage: "Работы"}
+	c := infobaseStandIn(t, info).client(t)
+
+	require.NoError(t, c.SetScheduledJobsDeny(context.Background(), clusterUUID, infobaseUUID, true))
+	assert.True(t, info.scheduledJobsDeny)
+	assert.True(t, info.sessionsDeny, "сервисный режим не изменяется")
+	assert.Equal(t, "Работы", info.deniedMessage)
+
+	require.NoError(t, c.SetScheduledJobsDeny(context.Background(), clusterUUID, infobaseUUID, false))
+	assert.False(t, info.scheduledJobsDeny)
+}
+
+func TestClient_ServiceModeLease(t *testing.T) {
+	info := &infobaseInfo{uuid: infobaseUUID, name: "erp", sessionsDeny: true}
+	c := infobaseStandIn(t, info).client(t)
+	ctx := context.Background()
+
+	require.NoError(t, c.SetServiceModeLease(ctx, clusterUUID, infobaseUUID, "apk-ci-lease:owner=ci"))
+	status, err := c.GetServiceModeStatus(ctx, clusterUUID, infobaseUUID)
+	require.NoError(t, err)
+	assert.Equal(t, "apk-ci-lease:owner=ci", status.DeniedParameter)
+
+	require.NoError(t, c.DisableServiceMode(ctx, clusterUUID, infobaseUUID))
+	assert.Empty(t, info.deniedParameter)
+}
+
+func TestClient_TerminateSession(t *testing.T) {
+	s := newStandIn(t, map[messageKind]func(*decoder) reply{})
+	require.NoError(t, s.client(t).TerminateSession(context.Background(), clusterUUID, sessionUUID))
+
+	req := s.last(kindTerminateSession)
+	assert.Equal(t, clusterUUID, req.uuid())
+	assert.Equal(t, sessionUUID, req.uuid())
+}
+
+func TestClient_ServerException(t *testing.T) {
+	s := newStandIn(t, map[messageKind]func(*decoder) reply{
+		kindAuthenticateCluster: func(*decoder) reply {
+			return reply{exception: "Администратор кластера не аутентифицирован"}
+		},
+	})
+	c := s.client(t)
+
+	_, err := c.GetSessions(context.Background(), clusterUUID, infobaseUUID)
+	require.Error(t, err)
+	assert.Equal(t, rac.ErrRACExec, appCode(err))
+	assert.Contains(t, err.Error(), "Администратор кластера не аутентифицирован")
+
+	// Исключение сервера не разрывает соединение
+	_, err = c.GetSessions(context.Background(), clusterUUID, infobaseUUID)
+	require.Error(t, err)
+	assert.Equal(t, 1, s.accepted)
+}
+
+func TestClient_ReconnectAfterConnectionLoss(t *testing.T) {
+	s := newStandIn(t, map[messageKind]func(*decoder) reply{})
+	c := s.client(t)
+	ctx := context.Background()
+
+	require.NoError(t, c.TerminateSession(ctx, clusterUUID, sessionUUID))
+	s.dropConnections()
+
+	// Первый запрос после разрыва завершается ошибкой, следующий переподключается
+	err := c.TerminateSession(ctx, clusterUUID, sessionUUID)
+	require.Error(t, err)
+	require.NoError(t, c.TerminateSession(ctx, clusterUUID, sessionUUID))
+	assert.Equal(t, 2, s.accepted)
+}
+
+func TestClient_ConnectionRefused(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	require.NoError(t, ln.Close())
+
+	c, err := NewClient(ClientOptions{Server: host, Port: port, Timeout: time.Second})
+	require.NoError(t, err)
+	_, err = c.GetClusterInfo(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, rac.ErrRACExec, appCode(err))
+}
+
+func TestClient_ContextCancelled(t *testing.T) {
+	// Сервер принимает соединение, но не отвечает
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = ln.Close() }()
+	go func() {
+		nc, err := ln.Accept()
+		if err == nil {
+			defer func() { _ = nc.Close() }()
+			time.Sleep(5 * time.Second)
+		}
+	}()
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	c, err := NewClient(ClientOptions{Server: host, Port: port, Timeout: 10 * time.Second})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = c.GetClusterInfo(ctx)
+	require.Error(t, err)
+	assert.Equal(t, rac.ErrRACTimeout, appCode(err))
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestNewClient_Validation(t *testing.T) {
+	_, err := NewClient(ClientOptions{})
+	require.Error(t, err)
+
+	c, err := NewClient(ClientOptions{Server: "srv-app"})
+	require.NoError(t, err)
+	assert.Equal(t, "srv-app:1545", c.addr)
+}
+
+func appCode(err error) string {
+	var appErr *apperrors.AppError
+	if !errors.As(err, &appErr) {
+		return ""
+	}
+	return appErr.Code
+}
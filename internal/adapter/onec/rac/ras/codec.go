@@ -0,0 +1,198 @@
+package ras
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ticksToUnixMillis — количество миллисекунд от 0001-01-01 до 1970-01-01.
+// RAS передаёт время как количество десятых долей миллисекунды от 0001-01-01
+// в часовом поясе сервера 1С (без указания пояса).
+const ticksToUnixMillis = 62135596800000
+
+// encoder сериализует значения в формате RAS (big-endian).
+type encoder struct {
+	buf bytes.Buffer
+}
+
+func (e *encoder) bytes() []byte {
+	return e.buf.Bytes()
+}
+
+func (e *encoder) byte(v byte) {
+	e.buf.WriteByte(v)
+}
+
+func (e *encoder) bool(v bool) {
+	if v {
+		e.buf.WriteByte(1)
+		return
+	}
+	e.buf.WriteByte(0)
+}
+
+func (e *encoder) short(v uint16) {
+	e.buf.Write(binary.BigEndian.AppendUint16(nil, v))
+}
+
+func (e *encoder) int(v uint32) {
+	e.buf.Write(binary.BigEndian.AppendUint32(nil, v))
+}
+
+func (e *encoder) long(v uint64) {
+	e.buf.Write(binary.BigEndian.AppendUint64(nil, v))
+}
+
+func (e *encoder) double(v float64) {
+	e.long(math.Float64bits(v))
+}
+
+// size кодирует размер переменной длины: в первом байте 6 бит значения
+// и флаг продолжения 0x40, в следующих — по 7 бит и флаг продолжения 0x80.
+func (e *encoder) size(v int) {
+	b := byte(v & 0x3f)
+	v >>= 6
+	if v > 0 {
+		b |= 0x40
+	}
+	e.buf.WriteByte(b)
+	for v > 0 {
+		b = byte(v & 0x7f)
+		v >>= 7
+		if v > 0 {
+			b |= 0x80
+		}
+		e.buf.WriteByte(b)
+	}
+}
+
+func (e *encoder) string(v string) {
+	e.size(len(v))
+	e.buf.WriteString(v)
+}
+
+// uuid кодирует UUID (16 байт); пустая строка — нулевой UUID.
+func (e *encoder) uuid(v string) error {
+	id := uuid.Nil
+	if v != "" {
+		var err error
+		if id, err = uuid.Parse(v); err != nil {
+			return fmt.Errorf("некорректный UUID %q: %w", v, err)
+		}
+	}
+	e.buf.Write(id[:])
+	return nil
+}
+
+// time кодирует время сервера 1С; нулевое время — нулевая дата RAS.
+func (e *encoder) time(t time.Time) {
+	if t.IsZero() {
+		e.long(0)
+		return
+	}
+	// Время сервера передаётся без пояса: переносим стенные часы в UTC
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	e.long(uint64((wall.UnixMilli() + ticksToUnixMillis) * 10)) //nolint:gosec // время после 0001-01-01 неотрицательно
+}
+
+// decoder читает значения в формате RAS. Первая ошибка сохраняется,
+// последующие чтения возвращают нулевые значения — проверка err в конце разбора.
+type decoder struct {
+	r   *bytes.Reader
+	err error
+}
+
+func newDecoder(data []byte) *decoder {
+	return &decoder{r: bytes.NewReader(data)}
+}
+
+func (d *decoder) read(n int) []byte {
+	if d.err != nil {
+		return make([]byte, n)
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(d.r, b); err != nil {
+		d.err = fmt.Errorf("неожиданный конец сообщения RAS: %w", err)
+	}
+	return b
+}
+
+func (d *decoder) byte() byte {
+	return d.read(1)[0]
+}
+
+func (d *decoder) bool() bool {
+	return d.byte() != 0
+}
+
+func (d *decoder) short() uint16 {
+	return binary.BigEndian.Uint16(d.read(2))
+}
+
+func (d *decoder) int() uint32 {
+	return binary.BigEndian.Uint32(d.read(4))
+}
+
+func (d *decoder) long() uint64 {
+	return binary.BigEndian.Uint64(d.read(8))
+}
+
+func (d *decoder) double() float64 {
+	return math.Float64frombits(d.long())
+}
+
+func (d *decoder) size() int {
+	b := d.byte()
+	v := int(b & 0x3f)
+	if b&0x40 == 0 {
+		return v
+	}
+	for shift := 6; d.err == nil; shift += 7 {
+		if shift > 62 {
+			d.err = fmt.Errorf("некорректный размер в сообщении RAS")
+			return 0
+		}
+		b = d.byte()
+		v |= int(b&0x7f) << shift
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	return v
+}
+
+func (d *decoder) string() string {
+	n := d.size()
+	if d.err == nil && n > d.r.Len() {
+		d.err = fmt.Errorf("длина строки %d превышает остаток сообщения RAS", n)
+		return ""
+	}
+	return string(d.read(n))
+}
+
+func (d *decoder) uuid() string {
+	id, err := uuid.FromBytes(d.read(16))
+	if err != nil && d.err == nil {
+		d.err = err
+	}
+	return id.String()
+}
+
+// time декодирует время сервера 1С как локальное (аналогично разбору вывода rac).
+func (d *decoder) time() time.Time {
+	ticks := d.long()
+	if ticks == 0 {
+		return time.Time{}
+	}
+	wall := time.UnixMilli(int64(ticks/10) - ticksToUnixMillis).UTC() //nolint:gosec // значение RAS не превышает int64
+	if wall.Year() <= 1 {
+		return time.Time{}
+	}
+	return time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), wall.Nanosecond(), time.Local)
+}
@@ -0,0 +1,204 @@
+package ras
+
+import (
+	"time"
+
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
+)
+
+// decodeCluster разбирает описание кластера (ответ GetClusters).
+func decodeCluster(d *decoder) rac.ClusterInfo {
+	var cl rac.ClusterInfo
+	cl.UUID = d.uuid()
+	d.int() // expiration-timeout
+	cl.Host = d.string()
+	d.int() // lifetime-limit
+	cl.Port = int(d.short())
+	d.int() // max-memory-size
+	d.int() // max-memory-time-limit
+	cl.Name = d.string()
+	d.int()  // security-level
+	d.int()  // session-fault-tolerance-level
+	d.int()  // load-balancing-mode
+	d.int()  // errors-count-threshold
+	d.bool() // kill-problem-processes
+	d.bool() // kill-by-memory-with-dump
+	return cl
+}
+
+// decodeInfobaseShort разбирает краткое описание информационной базы.
+func decodeInfobaseShort(d *decoder) rac.InfobaseInfo {
+	var ib rac.InfobaseInfo
+	ib.UUID = d.uuid()
+	ib.Description = d.string()
+	ib.Name = d.string()
+	return ib
+}
+
+// infobaseInfo — полное описание информационной базы (GetInfobaseInfo/UpdateInfobase).
+// Обновление передаёт описание целиком, поэтому поля сохраняются для обратной записи.
+type infobaseInfo struct {
+	uuid                           string
+	dateOffset                     uint32
+	dbms                           string
+	dbName                         string
+	dbPwd                          string
+	dbServer                       string
+	dbUser                         string
+	deniedFrom                     time.Time
+	deniedMessage                  string
+	deniedParameter                string
+	deniedTo                       time.Time
+	permissionCode                 string
+	externalSessionManagerConnStr  string
+	externalSessionManagerRequired bool
+	licenseDistribution            uint32
+	locale                         string
+	name                           string
+	description                    string
+	scheduledJobsDeny              bool
+	securityLevel                  uint32
+	securityProfileName            string
+	safeModeSecurityProfileName    string
+	sessionsDeny                   bool
+	reserveWorkingProcesses        bool
+}
+
+func decodeInfobaseInfo(d *decoder) *infobaseInfo {
+	return &infobaseInfo{
+		uuid:                           d.uuid(),
+		dateOffset:                     d.int(),
+		dbms:                           d.string(),
+		dbName:                         d.string(),
+		dbPwd:                          d.string(),
+		dbServer:                       d.string(),
+		dbUser:                         d.string(),
+		deniedFrom:                     d.time(),
+		deniedMessage:                  d.string(),
+		deniedParameter:                d.string(),
+		deniedTo:                       d.time(),
+		permissionCode:                 d.string(),
+		externalSessionManagerConnStr:  d.string(),
+		externalSessionManagerRequired: d.bool(),
+		licenseDistribution:            d.int(),
+		locale:                         d.string(),
+		name:                           d.string(),
+		description:                    d.string(),
+		scheduledJobsDeny:              d.bool(),
+		securityLevel:                  d.int(),
+		securityProfileName:            d.string(),
+		safeModeSecurityProfileName:    d.string(),
+		sessionsDeny:                   d.bool(),
+		reserveWorkingProcesses:        d.bool(),
+	}
+}
+
+func (ib *infobaseInfo) encode(e *encoder) error {
+	if err := e.uuid(ib.uuid); err != nil {
+		return err
+	}
+	e.int(ib.dateOffset)
+	e.string(ib.dbms)
+	e.string(ib.dbName)
+	e.string(ib.dbPwd)
+	e.string(ib.dbServer)
+	e.string(ib.dbUser)
+	e.time(ib.deniedFrom)
+	e.string(ib.deniedMessage)
+	e.string(ib.deniedParameter)
+	e.time(ib.deniedTo)
+	e.string(ib.permissionCode)
+	e.string(ib.externalSessionManagerConnStr)
+	e.bool(ib.externalSessionManagerRequired)
+	e.int(ib.licenseDistribution)
+	e.string(ib.locale)
+	e.string(ib.name)
+	e.string(ib.description)
+	e.bool(ib.scheduledJobsDeny)
+	e.int(ib.securityLevel)
+	e.string(ib.securityProfileName)
+	e.string(ib.safeModeSecurityProfileName)
+	e.bool(ib.sessionsDeny)
+	e.bool(ib.reserveWorkingProcesses)
+	return nil
+}
+
+// serviceModeStatus возвращает статус сервисного режима по описанию базы.
+func (ib *infobaseInfo) serviceModeStatus() *rac.ServiceModeStatus {
+	return &rac.ServiceModeStatus{
+		Enabled:              ib.sessionsDeny,
+		Message:              ib.deniedMessage,
+		ScheduledJobsBlocked: ib.scheduledJobsDeny,
+		DeniedFrom:           ib.deniedFrom,
+		DeniedTo:             ib.deniedTo,
+		DeniedParameter:      ib.deniedParameter,
+	}
+}
+
+// decodeSession разбирает описание сессии (ответ GetInfobaseSessions).
+// Лицензии и счётчики производительности сессии пропускаются.
+func decodeSession(d *decoder) rac.SessionInfo {
+	var s rac.SessionInfo
+	s.SessionID = d.uuid()
+	s.AppID = d.string()
+	d.int()    // blocked-by-dbms
+	d.int()    // blocked-by-ls
+	d.long()   // bytes-all
+	d.long()   // bytes-last-5min
+	d.int()    // calls-all
+	d.long()   // calls-last-5min
+	d.long()   // dbms-bytes-all
+	d.long()   // dbms-bytes-last-5min
+	d.string() // db-proc-info
+	d.int()    // db-proc-took
+	d.time()   // db-proc-took-at
+	d.int()    // duration-all
+	d.int()    // duration-all-dbms
+	d.int()    // duration-current
+	d.int()    // duration-current-dbms
+	d.long()   // duration-last-5min
+	d.long()   // duration-last-5min-dbms
+	s.Host = d.string()
+	d.uuid() // infobase
+	s.LastActiveAt = d.time()
+	d.bool() // hibernate
+	d.int()  // passive-session-hibernate-time
+	d.int()  // hibernate-session-terminate-time
+	for n := d.size(); n > 0 && d.err == nil; n-- {
+		skipLicense(d)
+	}
+	d.string() // locale
+	d.uuid()   // process
+	d.int()    // session-id (номер сеанса)
+	s.StartedAt = d.time()
+	s.UserName = d.string()
+	for range 9 {
+		d.long() // memory-*, read-*, write-* (current, last-5min, total)
+	}
+	d.int()    // duration-current-service
+	d.long()   // duration-last-5min-service
+	d.int()    // duration-all-service
+	d.string() // current-service-name
+	d.long()   // cpu-time-current
+	d.long()   // cpu-time-last-5min
+	d.long()   // cpu-time-total
+	d.string() // data-separation
+	d.string() // client-ip
+	return s
+}
+
+// skipLicense пропускает описание лицензии сессии.
+func skipLicense(d *decoder) {
+	d.string() // full-name
+	d.string() // full-presentation
+	d.bool()   // issued-by-server
+	d.int()    // license-type
+	d.int()    // max-users-all
+	d.int()    // max-users-cur
+	d.bool()   // net
+	d.string() // rmngr-address
+	d.string() // rmngr-pid
+	d.int()    // rmngr-port
+	d.string() // series
+	d.string() // short-presentation
+}
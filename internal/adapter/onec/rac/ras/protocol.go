@@ -0,0 +1,264 @@
+package ras
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+)
+
+// Параметры согласования протокола RAS.
+const (
+	// protocolMagic — сигнатура протокола ("\x1cSWP")
+	protocolMagic uint32 = 0x1c535750
+	// protocolVersion — версия транспортного протокола
+	protocolVersion uint16 = 256
+	// commonVersion — версия общего формата сообщений
+	commonVersion uint16 = 256
+
+	// serviceName — сервис администрирования кластера
+	serviceName = "v8.service.Admin.Cluster"
+	// serviceVersion — версия сервиса администрирования (платформа 8.3.15+)
+	serviceVersion = "10.0"
+
+	// maxPacketSize — ограничение размера пакета от сервера (защита от некорректных данных)
+	maxPacketSize = 64 << 20
+)
+
+// packetType — тип пакета транспортного уровня RAS.
+type packetType byte
+
+// Типы пакетов транспортного уровня.
+const (
+	packetNegotiate packetType = iota
+	packetConnect
+	packetConnectAck
+	packetStartTLS
+	packetDisconnect
+	packetSASLNegotiate
+	packetSASLAuth
+	packetSASLChallenge
+	packetSASLSuccess
+	packetSASLFailure
+	packetSASLAbort
+	packetEndpointOpen
+	packetEndpointOpenAck
+	packetEndpointClose
+	packetEndpointMessage
+	packetEndpointFailure
+	packetKeepAlive
+)
+
+// Типы сообщений endpoint.
+const (
+	messageVoid      byte = 0
+	messageMessage   byte = 1
+	messageException byte = 0xff
+)
+
+// messageKind — вид сообщения сервиса администрирования.
+type messageKind byte
+
+// Виды сообщений сервиса администрирования, используемые клиентом.
+const (
+	kindAuthenticateAgent     messageKind = 8
+	kindAuthenticateCluster   messageKind = 9
+	kindAddAuthentication     messageKind = 10
+	kindGetClusters           messageKind = 11
+	kindGetClustersResponse   messageKind = 12
+	kindUpdateInfobase        messageKind = 40
+	kindGetInfobasesShort     messageKind = 42
+	kindGetInfobasesShortResp messageKind = 43
+	kindGetInfobaseInfo       messageKind = 48
+	kindGetInfobaseInfoResp   messageKind = 49
+	kindGetInfobaseSessions   messageKind = 65
+	kindGetInfobaseSessResp   messageKind = 66
+	kindTerminateSession      messageKind = 69
+)
+
+// conn — соединение с RAS с открытым endpoint сервиса администрирования.
+type conn struct {
+	nc       net.Conn
+	r        *bufio.Reader
+	endpoint int
+}
+
+// writePacket отправляет пакет: тип, размер, данные.
+func (c *conn) writePacket(t packetType, payload []byte) error {
+	var e encoder
+	e.byte(byte(t))
+	e.size(len(payload))
+	e.buf.Write(payload)
+	_, err := c.nc.Write(e.bytes())
+	return err
+}
+
+// readPacket читает пакет; KeepAlive пропускается.
+func (c *conn) readPacket() (packetType, []byte, error) {
+	for {
+		t, err := c.r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		n, err := readSize(c.r)
+		if err != nil {
+			return 0, nil, err
+		}
+		if n > maxPacketSize {
+			return 0, nil, fmt.Errorf("размер пакета RAS %d превышает допустимый", n)
+		}
+		payload := make([]byte, n)
+		if _, err := io.ReadFull(c.r, payload); err != nil {
+			return 0, nil, err
+		}
+		if packetType(t) == packetKeepAlive {
+			continue
+		}
+		return packetType(t), payload, nil
+	}
+}
+
+// readSize читает размер переменной длины из потока (см. encoder.size).
+func readSize(r io.ByteReader) (int, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	v := int(b & 0x3f)
+	if b&0x40 == 0 {
+		return v, nil
+	}
+	for shift := 6; ; shift += 7 {
+		if shift > 62 {
+			return 0, fmt.Errorf("некорректный размер пакета RAS")
+		}
+		if b, err = r.ReadByte(); err != nil {
+			return 0, err
+		}
+		v |= int(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return v, nil
+		}
+	}
+}
+
+// handshake выполняет согласование протокола, подключение и открытие endpoint.
+func (c *conn) handshake() error {
+	var e encoder
+	e.int(protocolMagic)
+	e.short(protocolVersion)
+	e.short(commonVersion)
+	if _, err := c.nc.Write(e.bytes()); err != nil {
+		return err
+	}
+
+	// Connect без параметров (пустая карта параметров)
+	var connect encoder
+	connect.size(0)
+	if err := c.writePacket(packetConnect, connect.bytes()); err != nil {
+		return err
+	}
+	t, _, err := c.readPacket()
+	if err != nil {
+		return err
+	}
+	if t != packetConnectAck {
+		return fmt.Errorf("сервер RAS отклонил подключение (пакет %d)", t)
+	}
+
+	var open encoder
+	open.string(serviceName)
+	open.string(serviceVersion)
+	open.size(0)
+	if err := c.writePacket(packetEndpointOpen, open.bytes()); err != nil {
+		return err
+	}
+	t, payload, err := c.readPacket()
+	if err != nil {
+		return err
+	}
+	if t == packetEndpointFailure {
+		return endpointFailure(payload)
+	}
+	if t != packetEndpointOpenAck {
+		return fmt.Errorf("неожиданный ответ RAS на открытие endpoint (пакет %d)", t)
+	}
+	d := newDecoder(payload)
+	d.string() // сервис
+	d.string() // версия
+	c.endpoint = d.size()
+	return d.err
+}
+
+// call отправляет сообщение kind с телом body и возвращает тело ответа.
+// Для запросов без ответа (void) возвращает nil.
+func (c *conn) call(kind messageKind, body []byte) (*decoder, error) {
+	var e encoder
+	e.size(c.endpoint)
+	e.short(0) // формат сообщения
+	e.byte(messageMessage)
+	e.byte(byte(kind))
+	e.buf.Write(body)
+	if err := c.writePacket(packetEndpointMessage, e.bytes()); err != nil {
+		return nil, err
+	}
+
+	t, payload, err := c.readPacket()
+	if err != nil {
+		return nil, err
+	}
+	if t == packetEndpointFailure {
+		return nil, endpointFailure(payload)
+	}
+	if t != packetEndpointMessage {
+		return nil, fmt.Errorf("неожиданный пакет RAS %d", t)
+	}
+
+	d := newDecoder(payload)
+	d.size()  // endpoint
+	d.short() // формат
+	switch mt := d.byte(); mt {
+	case messageVoid:
+		return nil, d.err
+	case messageMessage:
+		d.byte() // вид ответа
+		return d, d.err
+	case messageException:
+		return nil, &ServerError{Service: d.string(), Message: d.string()}
+	default:
+		return nil, fmt.Errorf("неизвестный тип сообщения RAS %d", mt)
+	}
+}
+
+// close закрывает endpoint и соединение.
+func (c *conn) close() error {
+	var e encoder
+	e.size(c.endpoint)
+	_ = c.writePacket(packetEndpointClose, e.bytes()) //nolint:errcheck // соединение закрывается в любом случае
+	_ = c.writePacket(packetDisconnect, nil)          //nolint:errcheck // соединение закрывается в любом случае
+	return c.nc.Close()
+}
+
+// endpointFailure разбирает пакет отказа endpoint.
+func endpointFailure(payload []byte) error {
+	d := newDecoder(payload)
+	d.string() // сервис
+	d.string() // версия
+	d.size()   // endpoint
+	return &ServerError{Service: d.string(), Message: d.string()}
+}
+
+// ServerError — исключение, возвращённое сервером RAS.
+type ServerError struct {
+	// Service — класс исключения на стороне сервера
+	Service string
+	// Message — текст ошибки
+	Message string
+}
+
+func (e *ServerError) Error() string {
+	if e.Message == "" {
+		return "ошибка сервера RAS: " + e.Service
+	}
+	return "ошибка сервера RAS: " + e.Message
+}
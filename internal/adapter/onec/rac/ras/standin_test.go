@@ -0,0 +1,269 @@
+package ras
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
+)
+
+// reply — ответ stand-in сервера на сообщение endpoint.
+type reply struct {
+	// exception — текст исключения (пусто — обычный ответ)
+	exception string
+	// kind — вид ответа (0 — void)
+	kind messageKind
+	// body — тело ответа
+	body []byte
+}
+
+// request — запрос, полученный stand-in сервером.
+type request struct {
+	kind messageKind
+	body []byte
+}
+
+// standIn — stand-in сервер RAS: принимает согласование протокола и отвечает
+// на сообщения endpoint по таблице обработчиков, записывая полученные запросы.
+type standIn struct {
+	t        *testing.T
+	ln       net.Listener
+	handlers map[messageKind]func(d *decoder) reply
+
+	mu       sync.Mutex
+	requests []request
+	accepted int
+	conns    []net.Conn
+}
+
+func newStandIn(t *testing.T, handlers map[messageKind]func(d *decoder) reply) *standIn {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &standIn{t: t, ln: ln, handlers: handlers}
+	t.Cleanup(func() { _ = ln.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *standIn) client(t *testing.T) *Client {
+	t.Helper()
+	host, port, _ := net.SplitHostPort(s.ln.Addr().String())
+	c, err := NewClient(ClientOptions{Server: host, Port: port, Timeout: 5 * time.Second,
+		ClusterUser: "admin", ClusterPass: "secret", InfobaseUser: "deploy", InfobasePass: "ibsecret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+func (s *standIn) kinds() []messageKind {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kinds := make([]messageKind, 0, len(s.requests))
+	for _, r := range s.requests {
+		kinds = append(kinds, r.kind)
+	}
+	return kinds
+}
+
+func (s *standIn) last(kind messageKind) *decoder {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(s.requests) - 1; i >= 0; i-- {
+		if s.requests[i].kind == kind {
+			return newDecoder(s.requests[i].body)
+		}
+	}
+	s.t.Fatalf("запрос %d не получен", kind)
+	return nil
+}
+
+// dropConnections разрывает все принятые соединения (имитация потери связи).
+func (s *standIn) dropConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, nc := range s.conns {
+		_ = nc.Close()
+	}
+	s.conns = nil
+}
+
+func (s *standIn) serve() {
+	for {
+		nc, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.accepted++
+		s.conns = append(s.conns, nc)
+		s.mu.Unlock()
+		go s.handle(nc)
+	}
+}
+
+func (s *standIn) handle(nc net.Conn) {
+	defer func() { _ = nc.Close() }()
+	cn := &conn{nc: nc, r: bufio.NewReader(nc)}
+
+	negotiate := make([]byte, 8)
+	if _, err := io.ReadFull(cn.r, negotiate); err != nil || binary.BigEndian.Uint32(negotiate) != protocolMagic {
+		return
+	}
+	if t, _, err := cn.readPacket(); err != nil || t != packetConnect {
+		return
+	}
+	if err := cn.writePacket(packetConnectAck, nil); err != nil {
+		return
+	}
+	t, payload, err := cn.readPacket()
+	if err != nil || t != packetEndpointOpen {
+		return
+	}
+	d := newDecoder(payload)
+	if d.string() != serviceName {
+		return
+	}
+	var ack encoder
+	ack.string(serviceName)
+	ack.string(serviceVersion)
+	ack.size(1)
+	ack.size(0)
+	if err := cn.writePacket(packetEndpointOpenAck, ack.bytes()); err != nil {
+		return
+	}
+
+	for {
+		t, payload, err := cn.readPacket()
+		if err != nil || t != packetEndpointMessage {
+			return
+		}
+		d := newDecoder(payload)
+		endpoint := d.size()
+		d.short()
+		d.byte()
+		kind := messageKind(d.byte())
+		body := payload[len(payload)-d.r.Len():]
+
+		s.mu.Lock()
+		s.requests = append(s.requests, request{kind: kind, body: body})
+		s.mu.Unlock()
+
+		r := reply{}
+		if h, ok := s.handlers[kind]; ok {
+			r = h(newDecoder(body))
+		}
+		var e encoder
+		e.size(endpoint)
+		e.short(0)
+		switch {
+		case r.exception != "":
+			e.byte(messageException)
+			e.string("com._1c.v8.ibis.admin.AdminException")
+			e.string(r.exception)
+		case r.kind == 0:
+			e.byte(messageVoid)
+		default:
+			e.byte(messageMessage)
+			e.byte(byte(r.kind))
+			e.buf.Write(r.body)
+		}
+		if err := cn.writePacket(packetEndpointMessage, e.bytes()); err != nil {
+			return
+		}
+	}
+}
+
+// Кодировщики ответов stand-in сервера (зеркальны декодерам клиента).
+// Формат сообщений независимо от них проверяют стенограммы testdata (transcript_test.go).
+
+func encodeCluster(e *encoder, cl rac.ClusterInfo) {
+	_ = e.uuid(cl.UUID)
+	e.int(60)
+	e.string(cl.Host)
+	e.int(0)
+	e.short(uint16(cl.Port)) //nolint:gosec // тестовые данные
+	e.int(0)
+	e.int(0)
+	e.string(cl.Name)
+	e.int(0)
+	e.int(0)
+	e.int(0)
+	e.int(0)
+	e.bool(false)
+	e.bool(false)
+}
+
+func encodeInfobaseShort(e *encoder, ib rac.InfobaseInfo) {
+	_ = e.uuid(ib.UUID)
+	e.string(ib.Description)
+	e.string(ib.Name)
+}
+
+func encodeSession(e *encoder, s rac.SessionInfo, licenses int) {
+	_ = e.uuid(s.SessionID)
+	e.string(s.AppID)
+	e.int(0)
+	e.int(0)
+	e.long(0)
+	e.long(0)
+	e.int(0)
+	e.long(0)
+	e.long(0)
+	e.long(0)
+	e.string("")
+	e.int(0)
+	e.time(time.Time{})
+	for range 4 {
+		e.int(0)
+	}
+	e.long(0)
+	e.long(0)
+	e.string(s.Host)
+	_ = e.uuid("")
+	e.time(s.LastActiveAt)
+	e.bool(false)
+	e.int(0)
+	e.int(0)
+	e.size(licenses)
+	for range licenses {
+		e.string("file://C:/ProgramData/1C/licenses/1.lic")
+		e.string("Клиентская лицензия")
+		e.bool(true)
+		e.int(0)
+		e.int(50)
+		e.int(50)
+		e.bool(false)
+		e.string("srv")
+		e.string("1234")
+		e.int(1541)
+		e.string("ORG8A")
+		e.string("Кл. 50 польз.")
+	}
+	e.string("ru_RU")
+	_ = e.uuid("")
+	e.int(1)
+	e.time(s.StartedAt)
+	e.string(s.UserName)
+	for range 9 {
+		e.long(0)
+	}
+	e.int(0)
+	e.long(0)
+	e.int(0)
+	e.string("")
+	e.long(0)
+	e.long(0)
+	e.long(0)
+	e.string("")
+	e.string("10.0.0.15")
+}
@@ -0,0 +1,156 @@
+package ras
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// exchange — непрерывная последовательность байтов одного направления стенограммы.
+type exchange struct {
+	// fromClient — байты отправляет клиент (">"), иначе сервер ("<")
+	fromClient bool
+	data       []byte
+}
+
+// loadTranscript читает стенограмму из testdata: строки "> hex" и "< hex",
+// комментарии после "#". Соседние строки одного направления объединяются.
+func loadTranscript(t *testing.T, name string) []exchange {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	var exchanges []exchange
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		dir, payload := line[0], strings.Join(strings.Fields(line[1:]), "")
+		if dir != '>' && dir != '<' {
+			t.Fatalf("%s:%d: ожидается направление > или <", name, n)
+		}
+		data, err := hex.DecodeString(payload)
+		require.NoError(t, err, "%s:%d", name, n)
+		fromClient := dir == '>'
+		if last := len(exchanges) - 1; last >= 0 && exchanges[last].fromClient == fromClient {
+			exchanges[last].data = append(exchanges[last].data, data...)
+			continue
+		}
+		exchanges = append(exchanges, exchange{fromClient: fromClient, data: data})
+	}
+	require.NoError(t, scanner.Err())
+	return exchanges
+}
+
+// replay запускает сервер, воспроизводящий стенограмму name: байты клиента
+// сверяются побайтно, байты сервера отправляются как есть. Возвращает клиент
+// и функцию ожидания окончания стенограммы.
+func replay(t *testing.T, name string) (*Client, func() error) {
+	t.Helper()
+	exchanges := loadTranscript(t, name)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	done := make(chan error, 1)
+	go func() {
+		done <- func() error {
+			nc, err := ln.Accept()
+			if err != nil {
+				return err
+			}
+			defer func() { _ = nc.Close() }()
+			_ = nc.SetDeadline(time.Now().Add(5 * time.Second))
+			for i, ex := range exchanges {
+				if !ex.fromClient {
+					if _, err := nc.Write(ex.data); err != nil {
+						return fmt.Errorf("обмен %d: %w", i, err)
+					}
+					continue
+				}
+				got := make([]byte, len(ex.data))
+				if _, err := io.ReadFull(nc, got); err != nil {
+					return fmt.Errorf("обмен %d: %w", i, err)
+				}
+				if !bytes.Equal(got, ex.data) {
+					return fmt.Errorf("обмен %d: клиент отправил %x, ожидается %x", i, got, ex.data)
+				}
+			}
+			return nil
+		}()
+	}()
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	c, err := NewClient(ClientOptions{Server: host, Port: port, Timeout: 5 * time.Second,
+		ClusterUser: "admin", ClusterPass: "secret"})
+	require.NoError(t, err)
+	return c, func() error {
+		select {
+		case err := <-done:
+			return err
+		case <-time.After(5 * time.Second):
+			return errors.New("стенограмма не завершена")
+		}
+	}
+}
+
+func TestTranscript_ClusterList(t *testing.T) {
+	c, wait := replay(t, "cluster_list.txt")
+
+	cl, err := c.GetClusterInfo(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &rac.ClusterInfo{UUID: clusterUUID, Name: "Локальный кластер", Host: "srv-app", Port: 1541}, cl)
+
+	require.NoError(t, c.Close())
+	require.NoError(t, wait())
+}
+
+func TestTranscript_SessionList(t *testing.T) {
+	c, wait := replay(t, "session_list.txt")
+
+	sessions, err := c.GetSessions(context.Background(), clusterUUID, infobaseUUID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	s := sessions[0]
+	assert.Equal(t, sessionUUID, s.SessionID)
+	assert.Equal(t, "1CV8C", s.AppID)
+	assert.Equal(t, "ws-015", s.Host)
+	assert.Equal(t, "Иванов", s.UserName)
+	assert.Equal(t, time.Date(2026, 3, 14, 9, 30, 0, 0, time.Local), s.StartedAt, "время сервера без пояса")
+	assert.Equal(t, time.Date(2026, 3, 14, 18, 5, 0, 0, time.Local), s.LastActiveAt)
+
+	require.NoError(t, c.Close())
+	require.NoError(t, wait())
+}
+
+func TestTranscript_Exception(t *testing.T) {
+	c, wait := replay(t, "exception.txt")
+
+	_, err := c.GetClusterInfo(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, rac.ErrRACExec, appCode(err))
+	assert.Contains(t, err.Error(), "Администратор центрального сервера не аутентифицирован")
+
+	// Исключение сервера не разрывает соединение: Close закрывает endpoint
+	require.NoError(t, c.Close())
+	require.NoError(t, wait())
+}
@@ -6,10 +6,8 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
-	"strings"
 	"time"
 
-	"github.com/Kargones/apk-ci/internal/constants"
 	"github.com/Kargones/apk-ci/internal/util/runner"
 )
 
@@ -106,18 +104,12 @@ func (c *TempDbCreator) createInfobase(ctx context.Context, opts CreateTempDBOpt
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, opts.Timeout)
 	defer cancel()
 
+	// Успех определяется кодом завершения ibcmd
 	_, err := r.RunCommand(ctxWithTimeout, log)
 	if err != nil {
 		return err
 	}
 
-	// Проверка успеха по сообщениям (поддерживаем русский и английский варианты)
-	output := string(r.ConsoleOut)
-	if !strings.Contains(output, constants.SearchMsgBaseCreateOk) &&
-		!strings.Contains(output, constants.SearchMsgBaseCreateOkEn) {
-		return fmt.Errorf("неожиданный результат: %s", output)
-	}
-
 	log.Debug("Информационная база успешно создана", slog.String("path", opts.DbPath))
 	return nil
 }
@@ -147,12 +139,6 @@ func (c *TempDbCreator) addExtension(ctx context.Context, opts CreateTempDBOptio
 		return err
 	}
 
-	// Проверка успеха
-	output := string(r.ConsoleOut)
-	if !strings.Contains(output, constants.SearchMsgBaseAddOk) {
-		return fmt.Errorf("неожиданный результат: %s", output)
-	}
-
 	log.Debug("Расширение успешно добавлено",
 		slog.String("extension", extName),
 		slog.String("path", opts.DbPath))
@@ -7,7 +7,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/Kargones/apk-ci/internal/constants"
 	"github.com/Kargones/apk-ci/internal/util/runner"
 )
 
@@ -65,7 +64,8 @@ func (u *Updater) UpdateDBCfg(ctx context.Context, opts UpdateOptions) (*UpdateR
 	// Отключаем GUI параметры
 	addDisableParam(&r)
 
-	// Перенаправляем вывод в файл (runner автоматически создаст temp файл)
+	// Код завершения и вывод платформы пишутся в файлы (runner создаст temp файлы)
+	r.Params = append(r.Params, "/DumpResult")
 	r.Params = append(r.Params, "/Out")
 
 	// Устанавливаем таймаут
@@ -91,17 +91,13 @@ func (u *Updater) UpdateDBCfg(ctx context.Context, opts UpdateOptions) (*UpdateR
 	output := string(r.FileOut)
 	result.Messages = extractMessages(output)
 
-	// Проверка успеха по сообщениям
-	if strings.Contains(output, constants.SearchMsgBaseLoadOk) ||
-		strings.Contains(output, constants.SearchMsgEmptyFile) {
+	// Успех определяется кодом завершения и /DumpResult
+	if err == nil {
 		result.Success = true
 		log.Info("Обновление конфигурации завершено успешно",
 			slog.Int64("duration_ms", result.DurationMs))
 	} else {
 		result.Success = false
-		if err == nil {
-			err = fmt.Errorf("обновление не завершено успешно: %s", trimOutput(output))
-		}
 		log.Error("Ошибка обновления конфигурации",
 			slog.String("output", trimOutput(output)),
 			slog.Int64("duration_ms", result.DurationMs))
@@ -0,0 +1,575 @@
+// Package clusterhealthhandler реализует NR-команду nr-cluster-health
+// для проверки готовности кластера 1C и СУБД перед развёртыванием:
+// доступность RAS, состояние кластера и рабочих серверов, нагрузка rphost,
+// блокировки целевой информационной базы и доступность её базы данных.
+// Пайплайн вызывает команду до включения сервисного режима и прерывается
+// при вердикте fail, а не на середине UpdateDBCfg.
+package clusterhealthhandler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Kargones/apk-ci/internal/adapter/mssql"
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
+	"github.com/Kargones/apk-ci/internal/command"
+	"github.com/Kargones/apk-ci/internal/command/handlers/racutil"
+	errhandler "github.com/Kargones/apk-ci/internal/command/handlers/shared"
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/pkg/dryrun"
+	"github.com/Kargones/apk-ci/internal/pkg/output"
+	"github.com/Kargones/apk-ci/internal/pkg/tracing"
+)
+
+// Compile-time interface check.
+var _ command.Handler = (*ClusterHealthHandler)(nil)
+
+func RegisterCmd() error {
+	return command.Register(&ClusterHealthHandler{})
+}
+
+// Статусы проверок и итоговый вердикт.
+const (
+	StatusPass = "pass"
+	StatusWarn = "warn"
+	StatusFail = "fail"
+	// StatusSkip — проверка не выполнялась (не применима или недоступна); на вердикт не влияет
+	StatusSkip = "skip"
+)
+
+// Имена проверок.
+const (
+	CheckRASConnection     = "ras_connection"
+	CheckCluster           = "cluster"
+	CheckWorkingServers    = "working_servers"
+	CheckRphostMemory      = "rphost_memory"
+	CheckRphostConnections = "rphost_connections"
+	CheckInfobase          = "infobase"
+	CheckInfobaseLocks     = "infobase_locks"
+	CheckMSSQL             = "mssql"
+)
+
+const (
+	// defaultRphostMemoryMB — порог памяти рабочего процесса по умолчанию, МБ
+	defaultRphostMemoryMB = 8192
+	// defaultRphostConnections — порог соединений рабочего процесса по умолчанию
+	defaultRphostConnections = 500
+	// rasDialTimeout — таймаут TCP-подключения к RAS
+	rasDialTimeout = 5 * time.Second
+	// mssqlTimeout — таймаут подключения к MSSQL
+	mssqlTimeout = 15 * time.Second
+)
+
+// CheckResult — результат одной проверки.
+type CheckResult struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	// Details — подробности (процессы сверх порога, блокировки и т.п.)
+	Details []string `json:"details,omitempty"`
+}
+
+// ClusterHealthData содержит данные ответа nr-cluster-health.
+type ClusterHealthData struct {
+	// Verdict — итог: pass, warn или fail (худший статус проверок)
+	Verdict string `json:"verdict"`
+	// Server — сервер 1C (RAS)
+	Server string `json:"server"`
+	// Infobase — проверяемая информационная база (пусто — только кластер)
+	Infobase string `json:"infobase,omitempty"`
+	// DbServer — сервер СУБД информационной базы
+	DbServer string `json:"db_server,omitempty"`
+	// Checks — результаты проверок в порядке выполнения
+	Checks []CheckResult `json:"checks"`
+}
+
+// add добавляет результат проверки.
+func (d *ClusterHealthData) add(name, status, message string, details ...string) {
+	d.Checks = append(d.Checks, CheckResult{Name: name, Status: status, Message: message, Details: details})
+}
+
+// verdict возвращает худший статус проверок (skip не учитывается).
+func (d *ClusterHealthData) verdict() string {
+	verdict := StatusPass
+	for _, c := range d.Checks {
+		switch c.Status {
+		case StatusFail:
+			return StatusFail
+		case StatusWarn:
+			verdict = StatusWarn
+		}
+	}
+	return verdict
+}
+
+// failed возвращает сообщения непройденных проверок одной строкой.
+func (d *ClusterHealthData) failed() string {
+	parts := make([]string, 0, len(d.Checks))
+	for _, c := range d.Checks {
+		if c.Status == StatusFail {
+			parts = append(parts, c.Name+": "+c.Message)
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// writeText выводит результат в человекочитаемом формате.
+func (d *ClusterHealthData) writeText(w io.Writer) error {
+	target := d.Server
+	if d.Infobase != "" {
+		target += ", база " + d.Infobase
+	}
+	if _, err := fmt.Fprintf(w, "Состояние кластера (%s): %s\n", target, strings.ToUpper(d.Verdict)); err != nil {
+		return err
+	}
+	for _, c := range d.Checks {
+		if _, err := fmt.Fprintf(w, "  [%s] %s: %s\n", strings.ToUpper(c.Status), c.Name, c.Message); err != nil {
+			return err
+		}
+		for _, detail := range c.Details {
+			if _, err := fmt.Fprintf(w, "      %s\n", detail); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ClusterHealthHandler обрабатывает команду nr-cluster-health.
+// Проверки выполняются для сервера информационной базы BR_INFOBASE_NAME
+// (или RacConfig, если база не задана); проверки базы при этом пропускаются.
+type ClusterHealthHandler struct {
+	// racClient — опциональный RAC клиент (nil в production, mock в тестах)
+	racClient rac.Client
+	// dial — опциональная функция TCP-подключения к RAS (nil в production)
+	dial func(ctx context.Context, network, address string) (net.Conn, error)
+	// mssqlFactory — опциональная фабрика MSSQL клиента (nil в production, mock в тестах)
+	mssqlFactory func(opts mssql.ClientOptions) (mssql.Client, error)
+}
+
+// Name возвращает имя команды.
+func (h *ClusterHealthHandler) Name() string {
+	return constants.ActNRClusterHealth
+}
+
+// Description возвращает описание команды для вывода в help.
+func (h *ClusterHealthHandler) Description() string {
+	return "Проверка готовности кластера 1C и СУБД к развёртыванию"
+}
+
+// Execute выполняет команду nr-cluster-health.
+func (h *ClusterHealthHandler) Execute(ctx context.Context, cfg *config.Config) error {
+	start := time.Now()
+
+	traceID := tracing.TraceIDFromContext(ctx)
+	if traceID == "" {
+		traceID = tracing.GenerateTraceID()
+	}
+
+	format := os.Getenv("BR_OUTPUT_FORMAT")
+
+	// Plan-only не поддерживается; dry-run не влияет — команда только читает состояние.
+	if !dryrun.IsDryRun() && dryrun.IsPlanOnly() {
+		return dryrun.WritePlanOnlyUnsupported(os.Stdout, constants.ActNRClusterHealth)
+	}
+
+	log := slog.Default().With(slog.String("trace_id", traceID), slog.String("command", constants.ActNRClusterHealth))
+
+	if cfg == nil {
+		return h.writeError(format, traceID, start, nil, "CONFIG.MISSING", "Конфигурация не загружена")
+	}
+
+	server, err := racutil.ResolveServer(cfg)
+	if err != nil {
+		log.Error("Не удалось определить сервер 1C", slog.String("error", err.Error()))
+		return h.writeError(format, traceID, start, nil, "CONFIG.SERVER_MISSING", err.Error())
+	}
+
+	// Проверки процессов и блокировок без этих возможностей были бы пропущены,
+	// а вердикт — ложно положительным
+	if err := racutil.RequireCapabilities(cfg, racutil.CapInventory, racutil.CapLocks, racutil.CapInfobaseManager); err != nil {
+		log.Error("RAC клиент не поддерживает проверки кластера", slog.String("error", err.Error()))
+		return h.writeError(format, traceID, start, nil, racutil.ErrNativeUnsupported, err.Error())
+	}
+
+	data := &ClusterHealthData{
+		Server:   server,
+		Infobase: cfg.InfobaseName,
+		Checks:   make([]CheckResult, 0, 8),
+	}
+
+	thresholds, err := loadThresholds()
+	if err != nil {
+		return h.writeError(format, traceID, start, nil, "CONFIG.INVALID", err.Error())
+	}
+
+	log.Info("Проверка состояния кластера", slog.String("server", server), slog.String("infobase", cfg.InfobaseName))
+
+	rasReachable := h.checkRAS(ctx, cfg, data)
+	infobaseParams := h.checkCluster(ctx, cfg, data, thresholds, rasReachable)
+	h.checkMSSQL(ctx, cfg, data, infobaseParams)
+
+	data.Verdict = data.verdict()
+	log.Info("Проверка состояния кластера завершена", slog.String("verdict", data.Verdict))
+
+	if data.Verdict == StatusFail {
+		return h.writeError(format, traceID, start, data, "HEALTH.FAILED",
+			"Кластер не готов к развёртыванию: "+data.failed())
+	}
+	return h.writeSuccess(format, traceID, start, data)
+}
+
+// thresholds — пороги предупреждений по нагрузке рабочих процессов.
+type thresholds struct {
+	memoryMB    int64
+	connections int
+}
+
+// loadThresholds читает пороги из BR_HEALTH_RPHOST_MEMORY_MB и BR_HEALTH_RPHOST_CONNECTIONS.
+func loadThresholds() (thresholds, error) {
+	t := thresholds{memoryMB: defaultRphostMemoryMB, connections: defaultRphostConnections}
+	if v := strings.TrimSpace(os.Getenv(constants.EnvHealthRphostMemoryMB)); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			return t, fmt.Errorf("недопустимое значение %s: %q", constants.EnvHealthRphostMemoryMB, v)
+		}
+		t.memoryMB = n
+	}
+	if v := strings.TrimSpace(os.Getenv(constants.EnvHealthRphostConnections)); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return t, fmt.Errorf("недопустимое значение %s: %q", constants.EnvHealthRphostConnections, v)
+		}
+		t.connections = n
+	}
+	return t, nil
+}
+
+// checkRAS проверяет TCP-доступность RAS (сервер:порт из конфигурации).
+func (h *ClusterHealthHandler) checkRAS(ctx context.Context, cfg *config.Config, data *ClusterHealthData) bool {
+	dial := h.dial
+	if dial == nil {
+		dial = (&net.Dialer{Timeout: rasDialTimeout}).DialContext
+	}
+	address := net.JoinHostPort(data.Server, racutil.Port(cfg))
+	conn, err := dial(ctx, "tcp", address)
+	if err != nil {
+		data.add(CheckRASConnection, StatusFail, fmt.Sprintf("RAS %s недоступен: %v", address, err))
+		return false
+	}
+	_ = conn.Close() //nolint:errcheck // соединение использовалось только для проверки
+	data.add(CheckRASConnection, StatusPass, "RAS "+address+" доступен")
+	return true
+}
+
+// checkCluster выполняет проверки через RAC: кластер, рабочие серверы и процессы,
+// информационная база и её блокировки. Возвращает параметры СУБД базы, если их
+// удалось получить. При недоступном RAS проверки пропускаются.
+func (h *ClusterHealthHandler) checkCluster(ctx context.Context, cfg *config.Config, data *ClusterHealthData, t thresholds, rasReachable bool) *rac.InfobaseParams {
+	racChecks := []string{CheckCluster, CheckWorkingServers, CheckRphostMemory, CheckRphostConnections, CheckInfobase, CheckInfobaseLocks}
+	skipFrom := func(i int, reason string) {
+		for _, name := range racChecks[i:] {
+			data.add(name, StatusSkip, reason)
+		}
+	}
+
+	if !rasReachable {
+		skipFrom(0, "RAS недоступен")
+		return nil
+	}
+
+	client := h.racClient
+	if client == nil {
+		var err error
+		client, err = racutil.NewClientForServer(cfg, data.Server)
+		if err != nil {
+			data.add(CheckCluster, StatusFail, "не удалось создать RAC клиент: "+err.Error())
+			skipFrom(1, "RAC клиент недоступен")
+			return nil
+		}
+	}
+
+	clusterInfo, err := client.GetClusterInfo(ctx)
+	if err != nil {
+		data.add(CheckCluster, StatusFail, "кластер недоступен: "+err.Error())
+		skipFrom(1, "кластер недоступен")
+		return nil
+	}
+	data.add(CheckCluster, StatusPass, fmt.Sprintf("кластер %q (%s:%d)", clusterInfo.Name, clusterInfo.Host, clusterInfo.Port))
+
+	if inventory, ok := client.(rac.InventoryProvider); ok {
+		checkProcesses(ctx, inventory, clusterInfo.UUID, data, t)
+	} else {
+		for _, name := range racChecks[1:4] {
+			data.add(name, StatusSkip, "RAC клиент не поддерживает перечень серверов и процессов")
+		}
+	}
+
+	if cfg.InfobaseName == "" {
+		skipFrom(4, "информационная база не задана")
+		return nil
+	}
+
+	infobaseInfo, err := client.GetInfobaseInfo(ctx, clusterInfo.UUID, cfg.InfobaseName)
+	if err != nil {
+		data.add(CheckInfobase, StatusFail, "информационная база не найдена в кластере: "+err.Error())
+		skipFrom(5, "информационная база не найдена")
+		return nil
+	}
+	data.add(CheckInfobase, StatusPass, "информационная база "+infobaseInfo.Name+" зарегистрирована в кластере")
+
+	if locker, ok := client.(rac.LockProvider); ok {
+		checkLocks(ctx, locker, clusterInfo.UUID, infobaseInfo.UUID, data)
+	} else {
+		data.add(CheckInfobaseLocks, StatusSkip, "RAC клиент не поддерживает перечень блокировок")
+	}
+
+	manager, ok := client.(rac.InfobaseManager)
+	if !ok {
+		return nil
+	}
+	params, err := manager.GetInfobaseParams(ctx, clusterInfo.UUID, infobaseInfo.UUID)
+	if err != nil {
+		slog.Default().Debug("Не удалось получить параметры СУБД информационной базы", slog.String("error", err.Error()))
+		return nil
+	}
+	return params
+}
+
+// checkProcesses проверяет рабочие серверы кластера и нагрузку их процессов.
+func checkProcesses(ctx context.Context, inventory rac.InventoryProvider, clusterUUID string, data *ClusterHealthData, t thresholds) {
+	servers, err := inventory.ListServers(ctx, clusterUUID)
+	var processes []rac.ProcessInfo
+	if err == nil {
+		processes, err = inventory.ListProcesses(ctx, clusterUUID)
+	}
+	if err != nil {
+		data.add(CheckWorkingServers, StatusFail, "не удалось получить рабочие серверы и процессы: "+err.Error())
+		data.add(CheckRphostMemory, StatusSkip, "рабочие процессы недоступны")
+		data.add(CheckRphostConnections, StatusSkip, "рабочие процессы недоступны")
+		return
+	}
+
+	running := make(map[string]int)
+	total := 0
+	for _, p := range processes {
+		if p.Running {
+			running[strings.ToLower(p.Host)]++
+			total++
+		}
+	}
+	var idle []string
+	for _, s := range servers {
+		if running[strings.ToLower(s.AgentHost)] == 0 {
+			idle = append(idle, fmt.Sprintf("%s (%s): нет запущенных рабочих процессов", s.Name, s.AgentHost))
+		}
+	}
+	switch {
+	case total == 0:
+		data.add(CheckWorkingServers, StatusFail, "в кластере нет запущенных рабочих процессов", idle...)
+	case len(idle) > 0:
+		data.add(CheckWorkingServers, StatusWarn,
+			fmt.Sprintf("рабочих серверов без процессов: %d из %d", len(idle), len(servers)), idle...)
+	default:
+		data.add(CheckWorkingServers, StatusPass,
+			fmt.Sprintf("рабочих серверов: %d, запущенных процессов: %d", len(servers), total))
+	}
+
+	var heavy, busy []string
+	for _, p := range processes {
+		if !p.Running {
+			continue
+		}
+		if memoryMB := p.MemoryKB / 1024; memoryMB > t.memoryMB {
+			heavy = append(heavy, fmt.Sprintf("%s:%d (pid %s): %d МБ", p.Host, p.Port, p.PID, memoryMB))
+		}
+		if p.Connections > t.connections {
+			busy = append(busy, fmt.Sprintf("%s:%d (pid %s): %d соединений", p.Host, p.Port, p.PID, p.Connections))
+		}
+	}
+	if len(heavy) > 0 {
+		data.add(CheckRphostMemory, StatusWarn,
+			fmt.Sprintf("процессов с памятью выше %d МБ: %d", t.memoryMB, len(heavy)), heavy...)
+	} else {
+		data.add(CheckRphostMemory, StatusPass, fmt.Sprintf("память процессов не превышает %d МБ", t.memoryMB))
+	}
+	if len(busy) > 0 {
+		data.add(CheckRphostConnections, StatusWarn,
+			fmt.Sprintf("процессов с числом соединений выше %d: %d", t.connections, len(busy)), busy...)
+	} else {
+		data.add(CheckRphostConnections, StatusPass, fmt.Sprintf("соединений на процесс не более %d", t.connections))
+	}
+}
+
+// checkLocks проверяет блокировки информационной базы: монопольная блокировка
+// (например, открытый конфигуратор) не даст выполнить обновление — fail,
+// остальные — предупреждение.
+func checkLocks(ctx context.Context, locker rac.LockProvider, clusterUUID, infobaseUUID string, data *ClusterHealthData) {
+	locks, err := locker.GetInfobaseLocks(ctx, clusterUUID, infobaseUUID)
+	if err != nil {
+		data.add(CheckInfobaseLocks, StatusWarn, "не удалось получить блокировки: "+err.Error())
+		return
+	}
+	if len(locks) == 0 {
+		data.add(CheckInfobaseLocks, StatusPass, "блокировок нет")
+		return
+	}
+
+	details := make([]string, 0, len(locks))
+	exclusive := 0
+	for _, l := range locks {
+		if isExclusive(l.Description) {
+			exclusive++
+		}
+		details = append(details, fmt.Sprintf("сеанс %s: %s", l.SessionID, l.Description))
+	}
+	if exclusive > 0 {
+		data.add(CheckInfobaseLocks, StatusFail,
+			fmt.Sprintf("монопольных блокировок: %d (всего %d)", exclusive, len(locks)), details...)
+		return
+	}
+	data.add(CheckInfobaseLocks, StatusWarn, fmt.Sprintf("блокировок: %d", len(locks)), details...)
+}
+
+// isExclusive сообщает, является ли блокировка монопольной.
+func isExclusive(description string) bool {
+	d := strings.ToLower(description)
+	return strings.Contains(d, "монопольн") || strings.Contains(d, "exclusive")
+}
+
+// checkMSSQL проверяет подключение к базе данных информационной базы.
+// Сервер и имя базы берутся из параметров кластера, а при их отсутствии —
+// из dbconfig (db-server) и имени информационной базы.
+func (h *ClusterHealthHandler) checkMSSQL(ctx context.Context, cfg *config.Config, data *ClusterHealthData, params *rac.InfobaseParams) {
+	if cfg.InfobaseName == "" {
+		data.add(CheckMSSQL, StatusSkip, "информационная база не задана")
+		return
+	}
+
+	server := cfg.GetDbServer(cfg.InfobaseName)
+	database := cfg.InfobaseName
+	if params != nil {
+		if params.DBMS != "" && !strings.EqualFold(params.DBMS, "MSSQLServer") {
+			data.add(CheckMSSQL, StatusSkip, "СУБД информационной базы — "+params.DBMS)
+			return
+		}
+		if params.DBServer != "" {
+			server = params.DBServer
+		}
+		if params.DBName != "" {
+			database = params.DBName
+		}
+	}
+	if server == "" {
+		data.add(CheckMSSQL, StatusSkip, "сервер СУБД не найден ни в кластере, ни в dbconfig")
+		return
+	}
+	data.DbServer = server
+
+	opts := mssql.ClientOptions{
+		Server:   server,
+		Port:     1433,
+		User:     "gitops",
+		Database: database,
+		Timeout:  mssqlTimeout,
+	}
+	if cfg.AppConfig != nil && cfg.AppConfig.Users.Mssql != "" {
+		opts.User = cfg.AppConfig.Users.Mssql
+	}
+	if cfg.SecretConfig != nil {
+		opts.Password = cfg.SecretConfig.Passwords.Mssql
+	}
+
+	newClient := h.mssqlFactory
+	if newClient == nil {
+		newClient = mssql.NewClient
+	}
+	client, err := newClient(opts)
+	if err != nil {
+		data.add(CheckMSSQL, StatusFail, "не удалось создать MSSQL клиент: "+err.Error())
+		return
+	}
+	pingStart := time.Now()
+	err = client.Connect(ctx)
+	if err == nil {
+		defer func() { _ = client.Close() }() //nolint:errcheck // ошибка закрытия не влияет на результат проверки
+		err = client.Ping(ctx)
+	}
+	if err != nil {
+		data.add(CheckMSSQL, StatusFail, fmt.Sprintf("база данных %s на %s недоступна: %v", database, server, err))
+		return
+	}
+	data.add(CheckMSSQL, StatusPass, fmt.Sprintf("база данных %s на %s доступна (%d мс)",
+		database, server, time.Since(pingStart).Milliseconds()))
+}
+
+// writeSuccess выводит успешный результат (вердикт pass или warn).
+func (h *ClusterHealthHandler) writeSuccess(format, traceID string, start time.Time, data *ClusterHealthData) error {
+	if format != output.FormatJSON {
+		return data.writeText(os.Stdout)
+	}
+
+	result := &output.Result{
+		Status:  output.StatusSuccess,
+		Command: constants.ActNRClusterHealth,
+		Data:    data,
+		Metadata: &output.Metadata{
+			DurationMs: time.Since(start).Milliseconds(),
+			TraceID:    traceID,
+			APIVersion: constants.APIVersion,
+		},
+	}
+
+	writer := output.NewWriter(format)
+	return writer.Write(os.Stdout, result)
+}
+
+// writeError выводит структурированную ошибку и возвращает error.
+// При вердикте fail data содержит результаты проверок и выводится вместе с ошибкой.
+func (h *ClusterHealthHandler) writeError(format, traceID string, start time.Time, data *ClusterHealthData, code, message string) error {
+	// Текстовый формат — человекочитаемый вывод ошибки
+	if format != output.FormatJSON {
+		if data != nil {
+			if err := data.writeText(os.Stdout); err != nil {
+				slog.Default().Error("Не удалось вывести результаты проверок",
+					slog.String("trace_id", traceID),
+					slog.String("error", err.Error()))
+			}
+		}
+		return errhandler.HandleError(message, code)
+	}
+
+	result := &output.Result{
+		Status:  output.StatusError,
+		Command: constants.ActNRClusterHealth,
+		Error: &output.ErrorInfo{
+			Code:    code,
+			Message: message,
+		},
+		Metadata: &output.Metadata{
+			DurationMs: time.Since(start).Milliseconds(),
+			TraceID:    traceID,
+			APIVersion: constants.APIVersion,
+		},
+	}
+	if data != nil {
+		result.Data = data
+	}
+
+	writer := output.NewWriter(format)
+	if writeErr := writer.Write(os.Stdout, result); writeErr != nil {
+		slog.Default().Error("Не удалось записать JSON-ответ об ошибке",
+			slog.String("trace_id", traceID),
+			slog.String("error", writeErr.Error()))
+	}
+
+	return fmt.Errorf("%s: %s", code, message)
+}
@@ -0,0 +1,284 @@
+package clusterhealthhandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/Kargones/apk-ci/internal/adapter/mssql"
+	"github.com/Kargones/apk-ci/internal/adapter/mssql/mssqltest"
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac/ractest"
+	"github.com/Kargones/apk-ci/internal/command"
+	"github.com/Kargones/apk-ci/internal/command/handlers/racutil"
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/pkg/output"
+	"github.com/Kargones/apk-ci/internal/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterHealthHandler_Registration(t *testing.T) {
+	h, ok := command.Get(constants.ActNRClusterHealth)
+	require.True(t, ok)
+	assert.Equal(t, constants.ActNRClusterHealth, h.Name())
+	assert.NotEmpty(t, h.Description())
+}
+
+func healthConfig(infobase string) *config.Config {
+	cfg := &config.Config{
+		InfobaseName: infobase,
+		AppConfig:    &config.AppConfig{},
+		SecretConfig: &config.SecretConfig{},
+		DbConfig: map[string]*config.DatabaseInfo{
+			"erp": {OneServer: "srv-app", DbServer: "srv-sql-dbconfig"},
+		},
+		RacConfig: &config.RacConfig{RacServer: "srv-rac"},
+	}
+	cfg.AppConfig.Users.Mssql = "gitops-health"
+	cfg.SecretConfig.Passwords.Mssql = "secret"
+	return cfg
+}
+
+// newClusterMock создаёт mock здорового кластера: один рабочий сервер с процессом.
+func newClusterMock() *ractest.MockRACClient {
+	mock := ractest.NewMockRACClient()
+	mock.ListServersFunc = func(context.Context, string) ([]rac.ServerInfo, error) {
+		return []rac.ServerInfo{{UUID: "s1", Name: "Центральный", AgentHost: "srv-app", AgentPort: 1540}}, nil
+	}
+	mock.ListProcessesFunc = func(context.Context, string) ([]rac.ProcessInfo, error) {
+		return []rac.ProcessInfo{{UUID: "p1", Host: "srv-app", Port: 1560, PID: "100", Running: true, Connections: 40, MemoryKB: 2 * 1024 * 1024}}, nil
+	}
+	mock.GetInfobaseParamsFunc = func(context.Context, string, string) (*rac.InfobaseParams, error) {
+		return &rac.InfobaseParams{Name: "erp", DBMS: "MSSQLServer", DBServer: "srv-sql", DBName: "erp_db"}, nil
+	}
+	return mock
+}
+
+// dialOK имитирует доступный RAS и запоминает адрес подключения.
+func dialOK(address *string) func(context.Context, string, string) (net.Conn, error) {
+	return func(_ context.Context, _, addr string) (net.Conn, error) {
+		*address = addr
+		client, server := net.Pipe()
+		_ = server.Close()
+		return client, nil
+	}
+}
+
+func newHandler(mock *ractest.MockRACClient, sql *mssqltest.MockMSSQLClient, opts *mssql.ClientOptions) *ClusterHealthHandler {
+	var address string
+	return &ClusterHealthHandler{
+		racClient: mock,
+		dial:      dialOK(&address),
+		mssqlFactory: func(o mssql.ClientOptions) (mssql.Client, error) {
+			if opts != nil {
+				*opts = o
+			}
+			return sql, nil
+		},
+	}
+}
+
+func run(t *testing.T, h *ClusterHealthHandler, cfg *config.Config) (*ClusterHealthData, error) {
+	t.Helper()
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+	t.Setenv(constants.EnvPlanOnly, "")
+	var execErr error
+	out := testutil.CaptureStdout(t, func() {
+		execErr = h.Execute(context.Background(), cfg)
+	})
+	var result struct {
+		output.Result
+		Data ClusterHealthData `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &result), out)
+	return &result.Data, execErr
+}
+
+func statuses(data *ClusterHealthData) map[string]string {
+	m := make(map[string]string, len(data.Checks))
+	for _, c := range data.Checks {
+		m[c.Name] = c.Status
+	}
+	return m
+}
+
+func TestClusterHealthHandler_Pass(t *testing.T) {
+	var opts mssql.ClientOptions
+	var address string
+	h := newHandler(newClusterMock(), &mssqltest.MockMSSQLClient{}, &opts)
+	h.dial = dialOK(&address)
+
+	data, err := run(t, h, healthConfig("erp"))
+	require.NoError(t, err)
+
+	assert.Equal(t, StatusPass, data.Verdict)
+	assert.Equal(t, "srv-app", data.Server)
+	assert.Equal(t, "srv-app:1545", address)
+	assert.Len(t, data.Checks, 8)
+	for _, c := range data.Checks {
+		assert.Equal(t, StatusPass, c.Status, c.Name)
+	}
+	assert.Equal(t, "srv-sql", data.DbServer, "сервер СУБД из параметров кластера")
+	assert.Equal(t, "erp_db", opts.Database)
+	assert.Equal(t, "gitops-health", opts.User)
+	assert.Equal(t, "secret", opts.Password)
+}
+
+func TestClusterHealthHandler_RASUnreachable(t *testing.T) {
+	mock := newClusterMock()
+	mock.GetClusterInfoFunc = func(context.Context) (*rac.ClusterInfo, error) {
+		t.Fatal("RAC не должен вызываться при недоступном RAS")
+		return nil, nil
+	}
+	h := newHandler(mock, &mssqltest.MockMSSQLClient{}, nil)
+	h.dial = func(context.Context, string, string) (net.Conn, error) {
+		return nil, errors.New("connection refused")
+	}
+
+	data, err := run(t, h, healthConfig("erp"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "HEALTH.FAILED")
+	assert.Contains(t, err.Error(), "connection refused")
+
+	assert.Equal(t, StatusFail, data.Verdict)
+	s := statuses(data)
+	assert.Equal(t, StatusFail, s[CheckRASConnection])
+	assert.Equal(t, StatusSkip, s[CheckCluster])
+	assert.Equal(t, StatusSkip, s[CheckInfobaseLocks])
+	assert.Equal(t, StatusPass, s[CheckMSSQL], "СУБД проверяется по dbconfig независимо от RAS")
+	assert.Equal(t, "srv-sql-dbconfig", data.DbServer)
+}
+
+func TestClusterHealthHandler_Warnings(t *testing.T) {
+	t.Setenv(constants.EnvHealthRphostMemoryMB, "1024")
+	mock := newClusterMock()
+	mock.ListServersFunc = func(context.Context, string) ([]rac.ServerInfo, error) {
+		return []rac.ServerInfo{
+			{Name: "Центральный", AgentHost: "srv-app"},
+			{Name: "Резервный", AgentHost: "srv-app2"},
+		}, nil
+	}
+	mock.GetInfobaseLocksFunc = func(context.Context, string, string) ([]rac.LockInfo, error) {
+		return []rac.LockInfo{{SessionID: "sess-1", Description: "БД(сеанс ,разделяемая)"}}, nil
+	}
+
+	data, err := run(t, newHandler(mock, &mssqltest.MockMSSQLClient{}, nil), healthConfig("erp"))
+	require.NoError(t, err, "предупреждения не прерывают развёртывание")
+
+	assert.Equal(t, StatusWarn, data.Verdict)
+	s := statuses(data)
+	assert.Equal(t, StatusWarn, s[CheckWorkingServers])
+	assert.Equal(t, StatusWarn, s[CheckRphostMemory])
+	assert.Equal(t, StatusPass, s[CheckRphostConnections])
+	assert.Equal(t, StatusWarn, s[CheckInfobaseLocks])
+}
+
+func TestClusterHealthHandler_Failures(t *testing.T) {
+	tests := []struct {
+		name  string
+		check string
+		setup func(mock *ractest.MockRACClient, sql *mssqltest.MockMSSQLClient)
+	}{
+		{
+			name:  "нет рабочих процессов",
+			check: CheckWorkingServers,
+			setup: func(mock *ractest.MockRACClient, _ *mssqltest.MockMSSQLClient) {
+				mock.ListProcessesFunc = func(context.Context, string) ([]rac.ProcessInfo, error) {
+					return []rac.ProcessInfo{{Host: "srv-app", Running: false}}, nil
+				}
+			},
+		},
+		{
+			name:  "монопольная блокировка",
+			check: CheckInfobaseLocks,
+			setup: func(mock *ractest.MockRACClient, _ *mssqltest.MockMSSQLClient) {
+				mock.GetInfobaseLocksFunc = func(context.Context, string, string) ([]rac.LockInfo, error) {
+					return []rac.LockInfo{{SessionID: "sess-1", Description: "БД(сеанс ,монопольная)"}}, nil
+				}
+			},
+		},
+		{
+			name:  "база не зарегистрирована",
+			check: CheckInfobase,
+			setup: func(mock *ractest.MockRACClient, _ *mssqltest.MockMSSQLClient) {
+				mock.GetInfobaseInfoFunc = func(context.Context, string, string) (*rac.InfobaseInfo, error) {
+					return nil, errors.New("информационная база не найдена")
+				}
+			},
+		},
+		{
+			name:  "MSSQL недоступен",
+			check: CheckMSSQL,
+			setup: func(_ *ractest.MockRACClient, sql *mssqltest.MockMSSQLClient) {
+				sql.ConnectFunc = func(context.Context) error { return errors.New("login failed") }
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newClusterMock()
+			sql := &mssqltest.MockMSSQLClient{}
+			tt.setup(mock, sql)
+
+			data, err := run(t, newHandler(mock, sql, nil), healthConfig("erp"))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "HEALTH.FAILED")
+			assert.Equal(t, StatusFail, data.Verdict)
+			assert.Equal(t, StatusFail, statuses(data)[tt.check])
+		})
+	}
+}
+
+func TestClusterHealthHandler_WithoutInfobase(t *testing.T) {
+	data, err := run(t, newHandler(newClusterMock(), &mssqltest.MockMSSQLClient{}, nil), healthConfig(""))
+	require.NoError(t, err)
+
+	assert.Equal(t, StatusPass, data.Verdict)
+	assert.Equal(t, "srv-rac", data.Server, "сервер из RacConfig")
+	s := statuses(data)
+	assert.Equal(t, StatusPass, s[CheckCluster])
+	assert.Equal(t, StatusSkip, s[CheckInfobase])
+	assert.Equal(t, StatusSkip, s[CheckInfobaseLocks])
+	assert.Equal(t, StatusSkip, s[CheckMSSQL])
+}
+
+func TestClusterHealthHandler_InvalidThreshold(t *testing.T) {
+	t.Setenv(constants.EnvHealthRphostConnections, "много")
+
+	_, err := run(t, newHandler(newClusterMock(), &mssqltest.MockMSSQLClient{}, nil), healthConfig("erp"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "CONFIG.INVALID")
+}
+
+func TestClusterHealthHandler_NativeUnsupported(t *testing.T) {
+	cfg := healthConfig("erp")
+	cfg.ImplementationsConfig = &config.ImplementationsConfig{RAC: "native"}
+
+	_, err := run(t, newHandler(newClusterMock(), &mssqltest.MockMSSQLClient{}, nil), cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), racutil.ErrNativeUnsupported)
+}
+
+func TestClusterHealthHandler_TextOutput(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "text")
+	t.Setenv(constants.EnvPlanOnly, "")
+	mock := newClusterMock()
+	mock.GetInfobaseLocksFunc = func(context.Context, string, string) ([]rac.LockInfo, error) {
+		return []rac.LockInfo{{SessionID: "sess-1", Description: "БД(сеанс ,монопольная)"}}, nil
+	}
+
+	h := newHandler(mock, &mssqltest.MockMSSQLClient{}, nil)
+	var err error
+	out := testutil.CaptureStdout(t, func() {
+		err = h.Execute(context.Background(), healthConfig("erp"))
+	})
+	require.Error(t, err)
+	assert.Contains(t, out, "Состояние кластера (srv-app, база erp): FAIL")
+	assert.Contains(t, out, "[FAIL] infobase_locks: монопольных блокировок: 1")
+	assert.Contains(t, out, "сеанс sess-1: БД(сеанс ,монопольная)")
+	assert.Contains(t, out, "Код: HEALTH.FAILED")
+}
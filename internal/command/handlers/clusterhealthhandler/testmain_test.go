@@ -0,0 +1,11 @@
+package clusterhealthhandler
+
+import (
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	RegisterCmd()
+	os.Exit(m.Run())
+}
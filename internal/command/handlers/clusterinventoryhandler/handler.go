@@ -0,0 +1,523 @@
+// Package clusterinventoryhandler реализует NR-команду nr-cluster-inventory
+// для инвентаризации кластеров, информационных баз, рабочих серверов и процессов
+// серверов 1C и сверки информационных баз с DbConfig (dbconfig.yaml).
+package clusterinventoryhandler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
+	"github.com/Kargones/apk-ci/internal/command"
+	"github.com/Kargones/apk-ci/internal/command/handlers/racutil"
+	errhandler "github.com/Kargones/apk-ci/internal/command/handlers/shared"
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/pkg/dryrun"
+	"github.com/Kargones/apk-ci/internal/pkg/output"
+	"github.com/Kargones/apk-ci/internal/pkg/tracing"
+)
+
+// Compile-time interface check.
+var _ command.Handler = (*ClusterInventoryHandler)(nil)
+
+func RegisterCmd() error {
+	return command.Register(&ClusterInventoryHandler{})
+}
+
+// Виды расхождений DbConfig с серверами 1C.
+const (
+	// IssueMissingInConfig — база есть на сервере, но отсутствует в DbConfig
+	IssueMissingInConfig = "missing_in_config"
+	// IssueMissingOnServer — база из DbConfig не найдена на сервере one-server
+	IssueMissingOnServer = "missing_on_server"
+	// IssueServerMismatch — база из DbConfig найдена на другом сервере
+	IssueServerMismatch = "server_mismatch"
+	// IssueProdMismatch — признак prod в DbConfig не согласован с project.yaml
+	IssueProdMismatch = "prod_mismatch"
+)
+
+// InfobaseItem — информационная база кластера.
+type InfobaseItem struct {
+	// Name — имя информационной базы
+	Name string `json:"name"`
+	// UUID — идентификатор информационной базы
+	UUID string `json:"uuid"`
+	// Description — описание
+	Description string `json:"description,omitempty"`
+	// InConfig — база присутствует в DbConfig
+	InConfig bool `json:"in_config"`
+	// Prod — признак prod из DbConfig
+	Prod bool `json:"prod"`
+}
+
+// WorkingServerItem — рабочий сервер кластера.
+type WorkingServerItem struct {
+	Name      string `json:"name"`
+	AgentHost string `json:"agent_host"`
+	AgentPort int    `json:"agent_port"`
+	PortRange string `json:"port_range,omitempty"`
+}
+
+// ProcessItem — рабочий процесс кластера.
+type ProcessItem struct {
+	Host        string `json:"host"`
+	Port        int    `json:"port"`
+	PID         string `json:"pid"`
+	Running     bool   `json:"running"`
+	StartedAt   string `json:"started_at,omitempty"`
+	Connections int    `json:"connections"`
+	MemoryKB    int64  `json:"memory_kb"`
+}
+
+// ClusterItem — кластер сервера 1C.
+type ClusterItem struct {
+	UUID           string              `json:"uuid"`
+	Name           string              `json:"name"`
+	Host           string              `json:"host"`
+	Port           int                 `json:"port"`
+	Infobases      []InfobaseItem      `json:"infobases"`
+	WorkingServers []WorkingServerItem `json:"working_servers"`
+	Processes      []ProcessItem       `json:"processes"`
+}
+
+// ServerInventory — результат инвентаризации сервера 1C.
+type ServerInventory struct {
+	// Server — сервер 1C (one-server из DbConfig)
+	Server string `json:"server"`
+	// Clusters — кластеры сервера
+	Clusters []ClusterItem `json:"clusters"`
+	// Error — ошибка опроса сервера (пусто при успехе)
+	Error string `json:"error,omitempty"`
+}
+
+// Issue — расхождение DbConfig с серверами 1C.
+type Issue struct {
+	// Kind — вид расхождения (IssueMissingInConfig и др.)
+	Kind string `json:"kind"`
+	// Infobase — имя информационной базы
+	Infobase string `json:"infobase"`
+	// Server — сервер 1C, к которому относится расхождение
+	Server string `json:"server,omitempty"`
+	// Detail — пояснение
+	Detail string `json:"detail"`
+}
+
+// ClusterInventoryData содержит данные ответа nr-cluster-inventory.
+type ClusterInventoryData struct {
+	// Servers — опрошенные серверы 1C
+	Servers []ServerInventory `json:"servers"`
+	// InfobasesTotal — количество информационных баз на опрошенных серверах
+	InfobasesTotal int `json:"infobases_total"`
+	// Issues — расхождения DbConfig с серверами 1C
+	Issues []Issue `json:"issues"`
+}
+
+// issueTitles — заголовки видов расхождений для текстового вывода.
+var issueTitles = []struct{ kind, title string }{
+	{IssueMissingInConfig, "Есть на сервере, нет в dbconfig.yaml"},
+	{IssueMissingOnServer, "Есть в dbconfig.yaml, нет на сервере"},
+	{IssueServerMismatch, "Найдены на другом сервере"},
+	{IssueProdMismatch, "Расхождение признака prod"},
+}
+
+// writeText выводит результат инвентаризации в человекочитаемом формате.
+func (d *ClusterInventoryData) writeText(w io.Writer) error {
+	for _, srv := range d.Servers {
+		if srv.Error != "" {
+			if _, err := fmt.Fprintf(w, "Сервер %s: ОШИБКА %s\n", srv.Server, srv.Error); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "Сервер %s\n", srv.Server); err != nil {
+			return err
+		}
+		for _, cl := range srv.Clusters {
+			if _, err := fmt.Fprintf(w, "  Кластер %s (%s:%d): баз %d, рабочих серверов %d, процессов %d\n",
+				cl.Name, cl.Host, cl.Port, len(cl.Infobases), len(cl.WorkingServers), len(cl.Processes)); err != nil {
+				return err
+			}
+			for _, ib := range cl.Infobases {
+				mark := ""
+				if !ib.InConfig {
+					mark = " [нет в dbconfig.yaml]"
+				}
+				if _, err := fmt.Fprintf(w, "    %s%s\n", ib.Name, mark); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if _, err := fmt.Fprintf(w, "\nВсего информационных баз: %d, расхождений: %d\n", d.InfobasesTotal, len(d.Issues)); err != nil {
+		return err
+	}
+	for _, t := range issueTitles {
+		first := true
+		for _, is := range d.Issues {
+			if is.Kind != t.kind {
+				continue
+			}
+			if first {
+				if _, err := fmt.Fprintf(w, "%s:\n", t.title); err != nil {
+					return err
+				}
+				first = false
+			}
+			if _, err := fmt.Fprintf(w, "  %s — %s\n", is.Infobase, is.Detail); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ClusterInventoryHandler обрабатывает команду nr-cluster-inventory.
+// Опрашивает все серверы 1C из DbConfig (one-server).
+type ClusterInventoryHandler struct {
+	// clientFactory — опциональная фабрика RAC клиента по серверу (nil в production, mock в тестах)
+	clientFactory func(cfg *config.Config, server string) (rac.Client, error)
+}
+
+// Name возвращает имя команды.
+func (h *ClusterInventoryHandler) Name() string {
+	return constants.ActNRClusterInventory
+}
+
+// Description возвращает описание команды для вывода в help.
+func (h *ClusterInventoryHandler) Description() string {
+	return "Инвентаризация информационных баз серверов 1C и сверка с dbconfig.yaml"
+}
+
+// Execute выполняет команду nr-cluster-inventory.
+func (h *ClusterInventoryHandler) Execute(ctx context.Context, cfg *config.Config) error {
+	start := time.Now()
+
+	traceID := tracing.TraceIDFromContext(ctx)
+	if traceID == "" {
+		traceID = tracing.GenerateTraceID()
+	}
+
+	format := os.Getenv("BR_OUTPUT_FORMAT")
+
+	// Plan-only не поддерживается; dry-run имеет приоритет над plan-only.
+	if !dryrun.IsDryRun() && dryrun.IsPlanOnly() {
+		return dryrun.WritePlanOnlyUnsupported(os.Stdout, constants.ActNRClusterInventory)
+	}
+
+	log := slog.Default().With(slog.String("trace_id", traceID), slog.String("command", constants.ActNRClusterInventory))
+
+	servers := configuredServers(cfg)
+	if len(servers) == 0 {
+		log.Error("В DbConfig нет информационных баз с сервером 1C")
+		return h.writeError(format, traceID, start,
+			"CONFIG.DBCONFIG_MISSING",
+			"В DbConfig нет информационных баз с сервером 1C (one-server)")
+	}
+
+	if err := racutil.RequireCapabilities(cfg, racutil.CapInventory); err != nil {
+		log.Error("RAC клиент не поддерживает инвентаризацию", slog.String("error", err.Error()))
+		return h.writeError(format, traceID, start, racutil.ErrNativeUnsupported, err.Error())
+	}
+
+	newClient := h.clientFactory
+	if newClient == nil {
+		newClient = racutil.NewClientForServer
+	}
+
+	data := &ClusterInventoryData{Servers: make([]ServerInventory, 0, len(servers)), Issues: make([]Issue, 0)}
+	// found — серверы, на которых найдена база (ключ — имя в нижнем регистре: имена баз 1C регистронезависимы)
+	found := make(map[string][]string)
+	scanned := make(map[string]bool)
+	for _, server := range servers {
+		inv, err := h.scanServer(ctx, cfg, newClient, server)
+		if err != nil {
+			log.Warn("Не удалось опросить сервер 1C", slog.String("server", server), slog.String("error", err.Error()))
+			data.Servers = append(data.Servers, ServerInventory{Server: server, Clusters: []ClusterItem{}, Error: err.Error()})
+			continue
+		}
+		scanned[server] = true
+		for _, cl := range inv.Clusters {
+			data.InfobasesTotal += len(cl.Infobases)
+			for _, ib := range cl.Infobases {
+				key := strings.ToLower(ib.Name)
+				found[key] = append(found[key], server)
+			}
+		}
+		data.Servers = append(data.Servers, *inv)
+	}
+
+	if len(scanned) == 0 {
+		log.Error("Не удалось опросить ни один сервер 1C")
+		return h.writeError(format, traceID, start,
+			"RAC.INVENTORY_FAILED",
+			fmt.Sprintf("Не удалось опросить ни один сервер 1C: %s", data.Servers[0].Error))
+	}
+
+	data.Issues = append(data.Issues, compareWithConfig(cfg, data.Servers, found, scanned)...)
+
+	log.Info("Инвентаризация завершена",
+		slog.Int("servers", len(scanned)),
+		slog.Int("infobases", data.InfobasesTotal),
+		slog.Int("issues", len(data.Issues)))
+
+	return h.writeSuccess(format, traceID, start, data)
+}
+
+// scanServer опрашивает кластеры, информационные базы, рабочие серверы и процессы сервера 1C.
+// Ошибки получения рабочих серверов и процессов не критичны — списки остаются пустыми.
+func (h *ClusterInventoryHandler) scanServer(ctx context.Context, cfg *config.Config,
+	newClient func(*config.Config, string) (rac.Client, error), server string) (*ServerInventory, error) {
+	client, err := newClient(cfg, server)
+	if err != nil {
+		return nil, err
+	}
+	inventory, ok := client.(rac.InventoryProvider)
+	if !ok {
+		return nil, fmt.Errorf("RAC клиент не поддерживает инвентаризацию")
+	}
+	clusters, err := inventory.ListClusters(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	inv := &ServerInventory{Server: server, Clusters: make([]ClusterItem, 0, len(clusters))}
+	for _, cl := range clusters {
+		infobases, err := inventory.ListInfobases(ctx, cl.UUID)
+		if err != nil {
+			return nil, fmt.Errorf("кластер %s: %w", cl.Name, err)
+		}
+		item := ClusterItem{
+			UUID:           cl.UUID,
+			Name:           cl.Name,
+			Host:           cl.Host,
+			Port:           cl.Port,
+			Infobases:      make([]InfobaseItem, 0, len(infobases)),
+			WorkingServers: make([]WorkingServerItem, 0),
+			Processes:      make([]ProcessItem, 0),
+		}
+		for _, ib := range infobases {
+			info := dbInfo(cfg, ib.Name)
+			item.Infobases = append(item.Infobases, InfobaseItem{
+				Name:        ib.Name,
+				UUID:        ib.UUID,
+				Description: ib.Description,
+				InConfig:    info != nil,
+				Prod:        info != nil && info.Prod,
+			})
+		}
+		sort.Slice(item.Infobases, func(i, j int) bool { return item.Infobases[i].Name < item.Infobases[j].Name })
+
+		if srvs, err := inventory.ListServers(ctx, cl.UUID); err != nil {
+			slog.Default().Warn("Не удалось получить рабочие серверы кластера",
+				slog.String("server", server), slog.String("cluster", cl.Name), slog.String("error", err.Error()))
+		} else {
+			for _, s := range srvs {
+				item.WorkingServers = append(item.WorkingServers, WorkingServerItem{
+					Name: s.Name, AgentHost: s.AgentHost, AgentPort: s.AgentPort, PortRange: s.PortRange,
+				})
+			}
+		}
+		if procs, err := inventory.ListProcesses(ctx, cl.UUID); err != nil {
+			slog.Default().Warn("Не удалось получить рабочие процессы кластера",
+				slog.String("server", server), slog.String("cluster", cl.Name), slog.String("error", err.Error()))
+		} else {
+			for _, p := range procs {
+				startedAt := ""
+				if !p.StartedAt.IsZero() {
+					startedAt = p.StartedAt.Format(time.RFC3339)
+				}
+				item.Processes = append(item.Processes, ProcessItem{
+					Host: p.Host, Port: p.Port, PID: p.PID, Running: p.Running,
+					StartedAt: startedAt, Connections: p.Connections, MemoryKB: p.MemoryKB,
+				})
+			}
+		}
+		inv.Clusters = append(inv.Clusters, item)
+	}
+	return inv, nil
+}
+
+// configuredServers возвращает отсортированный список серверов 1C (one-server) из DbConfig.
+func configuredServers(cfg *config.Config) []string {
+	if cfg == nil {
+		return nil
+	}
+	set := make(map[string]bool)
+	for _, info := range cfg.DbConfig {
+		if info != nil && info.OneServer != "" {
+			set[info.OneServer] = true
+		}
+	}
+	servers := make([]string, 0, len(set))
+	for s := range set {
+		servers = append(servers, s)
+	}
+	sort.Strings(servers)
+	return servers
+}
+
+// dbInfo возвращает запись DbConfig для базы name без учёта регистра.
+func dbInfo(cfg *config.Config, name string) *config.DatabaseInfo {
+	if info, ok := cfg.DbConfig[name]; ok {
+		return info
+	}
+	for n, info := range cfg.DbConfig {
+		if strings.EqualFold(n, name) {
+			return info
+		}
+	}
+	return nil
+}
+
+// compareWithConfig сверяет найденные на серверах базы с DbConfig и project.yaml.
+// Базы, сервер one-server которых не удалось опросить, не считаются отсутствующими.
+func compareWithConfig(cfg *config.Config, servers []ServerInventory, found map[string][]string, scanned map[string]bool) []Issue {
+	var issues []Issue
+
+	for _, srv := range servers {
+		for _, cl := range srv.Clusters {
+			for _, ib := range cl.Infobases {
+				if !ib.InConfig {
+					issues = append(issues, Issue{
+						Kind: IssueMissingInConfig, Infobase: ib.Name, Server: srv.Server,
+						Detail: fmt.Sprintf("кластер %s", cl.Name),
+					})
+				}
+			}
+		}
+	}
+
+	names := make([]string, 0, len(cfg.DbConfig))
+	for name := range cfg.DbConfig {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		info := cfg.DbConfig[name]
+		if info == nil || info.OneServer == "" || !scanned[info.OneServer] {
+			continue
+		}
+		on := found[strings.ToLower(name)]
+		switch {
+		case containsString(on, info.OneServer):
+		case len(on) > 0:
+			issues = append(issues, Issue{
+				Kind: IssueServerMismatch, Infobase: name, Server: info.OneServer,
+				Detail: fmt.Sprintf("в dbconfig.yaml сервер %s, найдена на %s", info.OneServer, strings.Join(on, ", ")),
+			})
+		default:
+			issues = append(issues, Issue{
+				Kind: IssueMissingOnServer, Infobase: name, Server: info.OneServer,
+				Detail: fmt.Sprintf("не найдена на сервере %s", info.OneServer),
+			})
+		}
+	}
+
+	return append(issues, prodMismatches(cfg)...)
+}
+
+// prodMismatches сверяет признак prod DbConfig с project.yaml:
+// базы секции prod должны быть продуктивными, связанные (related) — тестовыми.
+func prodMismatches(cfg *config.Config) []Issue {
+	if cfg.ProjectConfig == nil {
+		return nil
+	}
+	var issues []Issue
+	prodNames := make([]string, 0, len(cfg.ProjectConfig.Prod))
+	for name := range cfg.ProjectConfig.Prod {
+		prodNames = append(prodNames, name)
+	}
+	sort.Strings(prodNames)
+	for _, prodName := range prodNames {
+		if info := dbInfo(cfg, prodName); info != nil && !info.Prod {
+			issues = append(issues, Issue{
+				Kind: IssueProdMismatch, Infobase: prodName, Server: info.OneServer,
+				Detail: "в project.yaml продуктивная, в dbconfig.yaml prod: false",
+			})
+		}
+		related := make([]string, 0, len(cfg.ProjectConfig.Prod[prodName].Related))
+		for name := range cfg.ProjectConfig.Prod[prodName].Related {
+			related = append(related, name)
+		}
+		sort.Strings(related)
+		for _, name := range related {
+			if info := dbInfo(cfg, name); info != nil && info.Prod {
+				issues = append(issues, Issue{
+					Kind: IssueProdMismatch, Infobase: name, Server: info.OneServer,
+					Detail: fmt.Sprintf("в project.yaml тестовая (related %s), в dbconfig.yaml prod: true", prodName),
+				})
+			}
+		}
+	}
+	return issues
+}
+
+// containsString сообщает, содержит ли list строку s.
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// writeSuccess выводит успешный результат.
+func (h *ClusterInventoryHandler) writeSuccess(format, traceID string, start time.Time, data *ClusterInventoryData) error {
+	if format != output.FormatJSON {
+		return data.writeText(os.Stdout)
+	}
+
+	result := &output.Result{
+		Status:  output.StatusSuccess,
+		Command: constants.ActNRClusterInventory,
+		Data:    data,
+		Metadata: &output.Metadata{
+			DurationMs: time.Since(start).Milliseconds(),
+			TraceID:    traceID,
+			APIVersion: constants.APIVersion,
+		},
+	}
+
+	writer := output.NewWriter(format)
+	return writer.Write(os.Stdout, result)
+}
+
+// writeError выводит структурированную ошибку и возвращает error.
+func (h *ClusterInventoryHandler) writeError(format, traceID string, start time.Time, code, message string) error {
+	// Текстовый формат — человекочитаемый вывод ошибки
+	if format != output.FormatJSON {
+		return errhandler.HandleError(message, code)
+	}
+
+	result := &output.Result{
+		Status:  output.StatusError,
+		Command: constants.ActNRClusterInventory,
+		Error: &output.ErrorInfo{
+			Code:    code,
+			Message: message,
+		},
+		Metadata: &output.Metadata{
+			DurationMs: time.Since(start).Milliseconds(),
+			TraceID:    traceID,
+			APIVersion: constants.APIVersion,
+		},
+	}
+
+	writer := output.NewWriter(format)
+	if writeErr := writer.Write(os.Stdout, result); writeErr != nil {
+		slog.Default().Error("Не удалось записать JSON-ответ об ошибке",
+			slog.String("trace_id", traceID),
+			slog.String("error", writeErr.Error()))
+	}
+
+	return fmt.Errorf("%s: %s", code, message)
+}
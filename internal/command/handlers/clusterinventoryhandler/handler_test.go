@@ -0,0 +1,187 @@
+package clusterinventoryhandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac/ractest"
+	"github.com/Kargones/apk-ci/internal/command"
+	"github.com/Kargones/apk-ci/internal/command/handlers/racutil"
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/pkg/output"
+	"github.com/Kargones/apk-ci/internal/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestClusterInventoryHandler_Registration(t *testing.T) {
+	h, ok := command.Get(constants.ActNRClusterInventory)
+	require.True(t, ok)
+	assert.Equal(t, constants.ActNRClusterInventory, h.Name())
+	assert.NotEmpty(t, h.Description())
+}
+
+// newInventoryMock создаёт mock сервера 1C с одним кластером и базами names.
+func newInventoryMock(names ...string) *ractest.MockRACClient {
+	mock := ractest.NewMockRACClient()
+	mock.ListInfobasesFunc = func(_ context.Context, _ string) ([]rac.InfobaseInfo, error) {
+		infobases := make([]rac.InfobaseInfo, 0, len(names))
+		for _, n := range names {
+			infobases = append(infobases, rac.InfobaseInfo{UUID: n + "-uuid", Name: n})
+		}
+		return infobases, nil
+	}
+	mock.ListServersFunc = func(_ context.Context, _ string) ([]rac.ServerInfo, error) {
+		return []rac.ServerInfo{{UUID: "srv-uuid", Name: "Центральный сервер", AgentHost: "srv", AgentPort: 1540}}, nil
+	}
+	mock.ListProcessesFunc = func(_ context.Context, _ string) ([]rac.ProcessInfo, error) {
+		return []rac.ProcessInfo{{UUID: "proc-uuid", Host: "srv", Port: 1560, PID: "4242", Running: true, Connections: 3}}, nil
+	}
+	return mock
+}
+
+func inventoryConfig(t *testing.T) *config.Config {
+	t.Helper()
+	var pc config.ProjectConfig
+	require.NoError(t, yaml.Unmarshal([]byte(`
+prod:
+  ERP:
+    dbName: ERP
+    related:
+      ERP_test: {}
+      ZUP_test: {}
+`), &pc))
+	return &config.Config{
+		ProjectConfig: &pc,
+		DbConfig: map[string]*config.DatabaseInfo{
+			"ERP":       {OneServer: "srv-a", Prod: true},
+			"ERP_test":  {OneServer: "srv-a"},
+			"ZUP_test":  {OneServer: "srv-b", Prod: true},
+			"moved":     {OneServer: "srv-a"},
+			"lost_test": {OneServer: "srv-b"},
+			"offline":   {OneServer: "srv-c"},
+			"sql_only":  {DbServer: "sql-1"},
+		},
+	}
+}
+
+func run(t *testing.T, h *ClusterInventoryHandler, cfg *config.Config) (string, error) {
+	t.Helper()
+	var execErr error
+	out := testutil.CaptureStdout(t, func() {
+		execErr = h.Execute(context.Background(), cfg)
+	})
+	return out, execErr
+}
+
+func factory(clients map[string]rac.Client) func(*config.Config, string) (rac.Client, error) {
+	return func(_ *config.Config, server string) (rac.Client, error) {
+		if c, ok := clients[server]; ok {
+			return c, nil
+		}
+		return nil, errors.New("connection refused")
+	}
+}
+
+func TestClusterInventoryHandler_Execute_JSONOutput(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+
+	h := &ClusterInventoryHandler{clientFactory: factory(map[string]rac.Client{
+		"srv-a": newInventoryMock("erp", "ERP_test", "orphan"),
+		"srv-b": newInventoryMock("zup_test", "moved"),
+	})}
+	out, err := run(t, h, inventoryConfig(t))
+	require.NoError(t, err)
+
+	var result struct {
+		Status string               `json:"status"`
+		Data   ClusterInventoryData `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &result), out)
+	assert.Equal(t, output.StatusSuccess, result.Status)
+
+	data := result.Data
+	require.Len(t, data.Servers, 3)
+	assert.Equal(t, "srv-a", data.Servers[0].Server)
+	assert.Equal(t, "connection refused", data.Servers[2].Error)
+	assert.Equal(t, 5, data.InfobasesTotal)
+
+	cl := data.Servers[0].Clusters[0]
+	require.Len(t, cl.Infobases, 3)
+	assert.Equal(t, InfobaseItem{Name: "ERP_test", UUID: "ERP_test-uuid", InConfig: true}, cl.Infobases[0])
+	assert.Equal(t, InfobaseItem{Name: "erp", UUID: "erp-uuid", InConfig: true, Prod: true}, cl.Infobases[1])
+	assert.False(t, cl.Infobases[2].InConfig)
+	require.Len(t, cl.WorkingServers, 1)
+	require.Len(t, cl.Processes, 1)
+	assert.Equal(t, "4242", cl.Processes[0].PID)
+
+	assert.Equal(t, []Issue{
+		{Kind: IssueMissingInConfig, Infobase: "orphan", Server: "srv-a", Detail: "кластер test-cluster"},
+		{Kind: IssueMissingOnServer, Infobase: "lost_test", Server: "srv-b", Detail: "не найдена на сервере srv-b"},
+		{Kind: IssueServerMismatch, Infobase: "moved", Server: "srv-a", Detail: "в dbconfig.yaml сервер srv-a, найдена на srv-b"},
+		{Kind: IssueProdMismatch, Infobase: "ZUP_test", Server: "srv-b", Detail: "в project.yaml тестовая (related ERP), в dbconfig.yaml prod: true"},
+	}, data.Issues, "база offline не проверяется: сервер srv-c недоступен")
+}
+
+func TestClusterInventoryHandler_Execute_TextOutput(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "text")
+
+	cfg := &config.Config{DbConfig: map[string]*config.DatabaseInfo{
+		"erp":  {OneServer: "srv-a"},
+		"lost": {OneServer: "srv-a"},
+	}}
+	h := &ClusterInventoryHandler{clientFactory: factory(map[string]rac.Client{
+		"srv-a": newInventoryMock("erp", "orphan"),
+	})}
+	out, err := run(t, h, cfg)
+	require.NoError(t, err)
+
+	assert.Contains(t, out, "Сервер srv-a")
+	assert.Contains(t, out, "orphan [нет в dbconfig.yaml]")
+	assert.Contains(t, out, "Всего информационных баз: 2, расхождений: 2")
+	assert.Contains(t, out, "Есть в dbconfig.yaml, нет на сервере:\n  lost")
+}
+
+func TestClusterInventoryHandler_Execute_NoServers(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+
+	_, err := run(t, &ClusterInventoryHandler{}, &config.Config{DbConfig: map[string]*config.DatabaseInfo{
+		"sql_only": {DbServer: "sql-1"},
+	}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "CONFIG.DBCONFIG_MISSING")
+}
+
+func TestClusterInventoryHandler_Execute_NativeUnsupported(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+
+	h := &ClusterInventoryHandler{clientFactory: func(*config.Config, string) (rac.Client, error) {
+		t.Error("клиент не создаётся, если реализация не поддерживает инвентаризацию")
+		return nil, errors.New("unexpected")
+	}}
+	_, err := run(t, h, &config.Config{
+		DbConfig:              map[string]*config.DatabaseInfo{"erp": {OneServer: "srv-a"}},
+		ImplementationsConfig: &config.ImplementationsConfig{RAC: "native"},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), racutil.ErrNativeUnsupported)
+}
+
+func TestClusterInventoryHandler_Execute_AllServersFailed(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+
+	mock := ractest.NewMockRACClient()
+	mock.ListClustersFunc = func(_ context.Context) ([]rac.ClusterInfo, error) {
+		return nil, errors.New("rac: timeout")
+	}
+	h := &ClusterInventoryHandler{clientFactory: factory(map[string]rac.Client{"srv-a": mock})}
+	out, err := run(t, h, &config.Config{DbConfig: map[string]*config.DatabaseInfo{"erp": {OneServer: "srv-a"}}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "RAC.INVENTORY_FAILED")
+	assert.Contains(t, out, "rac: timeout")
+}
@@ -0,0 +1,338 @@
+package convertpipelinehandler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Kargones/apk-ci/internal/command"
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+)
+
+// stageNamePattern — допустимые имена этапов (используются в ссылках stages.<имя>.*).
+var stageNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// envNamePattern — допустимые имена переменных окружения.
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// configEnvNames — переменные, из которых заполняется config.Config (теги env).
+// Конфигурация загружается до запуска пайплайна, поэтому env этапа на неё не влияет:
+// такие переменные в env запрещены, поля переопределяются через config этапа.
+var configEnvNames = collectEnvTags(reflect.TypeOf(config.Config{}), make(map[string]bool), make(map[reflect.Type]bool))
+
+// collectEnvTags добавляет в names имена переменных из тегов env структуры t и вложенных структур.
+func collectEnvTags(t reflect.Type, names map[string]bool, visited map[reflect.Type]bool) map[string]bool {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || visited[t] {
+		return names
+	}
+	visited[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if tag := f.Tag.Get("env"); tag != "" {
+			for _, name := range strings.Split(tag, ",") {
+				names[strings.TrimSpace(name)] = true
+			}
+		}
+		collectEnvTags(f.Type, names, visited)
+	}
+	return names
+}
+
+// PipelineDefinition — декларативное описание пайплайна (pipeline.yaml).
+//
+// Пример:
+//
+//	name: release
+//	timeout: 2h
+//	env:
+//	  BR_INFOBASE_NAME: MyBase
+//	stages:
+//	  - name: convert
+//	    command: nr-convert
+//	    env:
+//	      BR_SOURCE: src/cfe
+//	      BR_TARGET: /tmp/xml
+//	    outputs:
+//	      xml: ${env.BR_TARGET}
+//	  - name: service-mode
+//	    command: nr-service-mode-enable
+//	  - name: update
+//	    command: nr-dbupdate
+//	    when: stages.convert.outputs.xml != ''
+//	    config:
+//	      extensions: [ExtA, ExtB]
+//	  - name: scan
+//	    command: nr-sq-scan-branch
+//	    continue_on_error: true
+//	  - name: service-mode-off
+//	    command: nr-service-mode-disable
+//	    when: always
+type PipelineDefinition struct {
+	// Name — имя пайплайна для логов и вывода.
+	Name string `yaml:"name"`
+	// Timeout — таймаут всего пайплайна (формат time.ParseDuration).
+	// BR_PIPELINE_TIMEOUT имеет приоритет.
+	Timeout string `yaml:"timeout"`
+	// Env — переменные окружения для всех этапов (env этапа имеет приоритет).
+	// Переменные конфигурации (BR_INFOBASE_NAME и т.п.) не допускаются, см. configEnvNames.
+	Env map[string]string `yaml:"env"`
+	// Stages — этапы в порядке выполнения.
+	Stages []StageDefinition `yaml:"stages"`
+}
+
+// StageDefinition — описание этапа в pipeline.yaml.
+type StageDefinition struct {
+	// Name — уникальное имя этапа.
+	Name string `yaml:"name"`
+	// Command — имя зарегистрированной команды (NR или legacy).
+	Command string `yaml:"command"`
+	// When — условие запуска (см. Condition). Пусто — все предыдущие этапы успешны.
+	When string `yaml:"when"`
+	// ContinueOnError — ошибка этапа не останавливает пайплайн.
+	ContinueOnError bool `yaml:"continue_on_error"`
+	// Env — переменные окружения этапа, значения поддерживают подстановки ${...}.
+	// Действуют на время этапа для переменных, читаемых командами при выполнении.
+	Env map[string]string `yaml:"env"`
+	// Config — переопределения конфигурации для этапа.
+	Config *ConfigOverrides `yaml:"config"`
+	// Outputs — значения, доступные последующим этапам как stages.<имя>.outputs.<key>.
+	Outputs map[string]string `yaml:"outputs"`
+}
+
+// ConfigOverrides — поля config.Config, переопределяемые на время этапа.
+// Строковые значения поддерживают подстановки ${...}.
+type ConfigOverrides struct {
+	InfobaseName      string   `yaml:"infobase_name"`
+	Extensions        []string `yaml:"extensions"`
+	TerminateSessions *bool    `yaml:"terminate_sessions"`
+	ForceUpdate       *bool    `yaml:"force_update"`
+	DryRun            *bool    `yaml:"dry_run"`
+	StartEpf          string   `yaml:"start_epf"`
+}
+
+// apply возвращает копию cfg с переопределёнными полями; исходная конфигурация не изменяется.
+func (o *ConfigOverrides) apply(cfg *config.Config, interpolate func(string) string) *config.Config {
+	var c config.Config
+	if cfg != nil {
+		c = *cfg
+	}
+	if o.InfobaseName != "" {
+		c.InfobaseName = interpolate(o.InfobaseName)
+	}
+	if o.Extensions != nil {
+		c.AddArray = make([]string, len(o.Extensions))
+		for i, ext := range o.Extensions {
+			c.AddArray[i] = interpolate(ext)
+		}
+	}
+	if o.TerminateSessions != nil {
+		c.TerminateSessions = *o.TerminateSessions
+	}
+	if o.ForceUpdate != nil {
+		c.ForceUpdate = *o.ForceUpdate
+	}
+	if o.DryRun != nil {
+		c.DryRun = *o.DryRun
+	}
+	if o.StartEpf != "" {
+		c.StartEpf = interpolate(o.StartEpf)
+	}
+	return &c
+}
+
+// templates возвращает строковые значения с возможными подстановками.
+func (o *ConfigOverrides) templates() []string {
+	return append([]string{o.InfobaseName, o.StartEpf}, o.Extensions...)
+}
+
+// loadDefinition загружает определение пайплайна из BR_PIPELINE_FILE.
+// Файл в рабочем каталоге не подхватывается неявно: pipeline.yaml, случайно
+// оказавшийся в репозитории, не должен менять этапы. Возвращает nil без ошибки,
+// если BR_PIPELINE_FILE не задан — выполняются встроенные этапы (buildStages).
+func loadDefinition() (*PipelineDefinition, string, error) {
+	path := getenv("BR_PIPELINE_FILE")
+	if path == "" {
+		return nil, "", nil
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // путь задаётся пользователем
+	if err != nil {
+		return nil, path, fmt.Errorf("не удалось прочитать %s: %w", path, err)
+	}
+
+	def, err := ParseDefinition(data)
+	if err != nil {
+		return nil, path, fmt.Errorf("%s: %w", path, err)
+	}
+	return def, path, nil
+}
+
+// ParseDefinition разбирает и валидирует YAML-определение пайплайна.
+// Неизвестные поля считаются ошибкой, чтобы опечатки не игнорировались молча.
+func ParseDefinition(data []byte) (*PipelineDefinition, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	var def PipelineDefinition
+	if err := dec.Decode(&def); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("пустое определение пайплайна")
+		}
+		return nil, fmt.Errorf("некорректный YAML: %w", err)
+	}
+	if err := def.Validate(); err != nil {
+		return nil, err
+	}
+	return &def, nil
+}
+
+// Validate проверяет определение: уникальность имён, наличие команд,
+// корректность условий и ссылок (только на предшествующие этапы).
+func (d *PipelineDefinition) Validate() error {
+	if len(d.Stages) == 0 {
+		return fmt.Errorf("не задан ни один этап (stages)")
+	}
+	if d.Timeout != "" {
+		if t, err := time.ParseDuration(d.Timeout); err != nil || t <= 0 {
+			return fmt.Errorf("некорректный timeout %q", d.Timeout)
+		}
+	}
+	if err := validateEnv(d.Env, nil, "env"); err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool, len(d.Stages))
+	for i, s := range d.Stages {
+		where := fmt.Sprintf("stages[%d]", i)
+		if !stageNamePattern.MatchString(s.Name) {
+			return fmt.Errorf("%s: некорректное имя этапа %q (допустимы латиница, цифры, '_' и '-')", where, s.Name)
+		}
+		where = fmt.Sprintf("этап %s", s.Name)
+		if seen[s.Name] {
+			return fmt.Errorf("%s: имя этапа не уникально", where)
+		}
+		if s.Command == "" {
+			return fmt.Errorf("%s: не указана команда (command)", where)
+		}
+		if s.Command == constants.ActNRConvertPipeline {
+			return fmt.Errorf("%s: вложенный запуск %s не поддерживается", where, constants.ActNRConvertPipeline)
+		}
+		if s.When != "" {
+			cond, err := ParseCondition(s.When)
+			if err != nil {
+				return fmt.Errorf("%s: %w", where, err)
+			}
+			if err := checkRefs(cond.refs(), seen); err != nil {
+				return fmt.Errorf("%s: when: %w", where, err)
+			}
+		}
+		if err := validateEnv(s.Env, seen, where+": env"); err != nil {
+			return err
+		}
+		if s.Config != nil {
+			if err := checkTemplates(s.Config.templates(), seen); err != nil {
+				return fmt.Errorf("%s: config: %w", where, err)
+			}
+		}
+		for k, v := range s.Outputs {
+			if !envNamePattern.MatchString(k) {
+				return fmt.Errorf("%s: некорректное имя output %q", where, k)
+			}
+			if err := checkTemplates([]string{v}, seen); err != nil {
+				return fmt.Errorf("%s: outputs.%s: %w", where, k, err)
+			}
+		}
+		seen[s.Name] = true
+	}
+	return nil
+}
+
+// validateEnv проверяет имена переменных и подстановки в значениях.
+func validateEnv(env map[string]string, prev map[string]bool, where string) error {
+	for k, v := range env {
+		if !envNamePattern.MatchString(k) {
+			return fmt.Errorf("%s: некорректное имя переменной %q", where, k)
+		}
+		if configEnvNames[k] {
+			return fmt.Errorf("%s: переменная %s читается при загрузке конфигурации и на этапе не действует (используйте config этапа)", where, k)
+		}
+		if err := checkTemplates([]string{v}, prev); err != nil {
+			return fmt.Errorf("%s.%s: %w", where, k, err)
+		}
+	}
+	return nil
+}
+
+// checkTemplates проверяет ссылки в строках с подстановками.
+func checkTemplates(values []string, prev map[string]bool) error {
+	for _, v := range values {
+		refs, err := templateRefs(v)
+		if err != nil {
+			return err
+		}
+		if err := checkRefs(refs, prev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkRefs проверяет, что ссылки на этапы указывают на предшествующие этапы.
+func checkRefs(refs []ref, prev map[string]bool) error {
+	for _, r := range refs {
+		if r.stage != "" && !prev[r.stage] {
+			return fmt.Errorf("ссылка на этап %q, который не объявлен выше", r.stage)
+		}
+	}
+	return nil
+}
+
+// checkCommands проверяет, что все команды этапов зарегистрированы.
+func checkCommands(stages []Stage) error {
+	for _, s := range stages {
+		if _, ok := command.Get(s.CommandName); !ok {
+			return fmt.Errorf("этап %s: команда %s не зарегистрирована", s.Name, s.CommandName)
+		}
+	}
+	return nil
+}
+
+// toStages преобразует определение в этапы пайплайна.
+// Определение должно быть провалидировано (Validate).
+func (d *PipelineDefinition) toStages() []Stage {
+	stages := make([]Stage, 0, len(d.Stages))
+	for _, s := range d.Stages {
+		env := make(map[string]string, len(d.Env)+len(s.Env))
+		for k, v := range d.Env {
+			env[k] = v
+		}
+		for k, v := range s.Env {
+			env[k] = v
+		}
+		stage := Stage{
+			Name:            s.Name,
+			CommandName:     s.Command,
+			Env:             env,
+			Config:          s.Config,
+			ContinueOnError: s.ContinueOnError,
+			Outputs:         s.Outputs,
+		}
+		if s.When != "" {
+			stage.When, _ = ParseCondition(s.When) //nolint:errcheck // проверено в Validate
+		}
+		stages = append(stages, stage)
+	}
+	return stages
+}
@@ -0,0 +1,298 @@
+package convertpipelinehandler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Kargones/apk-ci/internal/config"
+)
+
+// funcExecutor — исполнитель этапов с пользовательской логикой.
+type funcExecutor struct {
+	calls []string
+	fn    func(stageName string, cfg *config.Config) error
+}
+
+func (e *funcExecutor) ExecuteStage(_ context.Context, stageName string, cfg *config.Config) error {
+	e.calls = append(e.calls, stageName)
+	if e.fn == nil {
+		return nil
+	}
+	return e.fn(stageName, cfg)
+}
+
+// writePipelineFile записывает pipeline.yaml и указывает на него через BR_PIPELINE_FILE.
+func writePipelineFile(t *testing.T, content string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "pipeline.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("BR_PIPELINE_FILE", path)
+}
+
+func TestParseDefinition_Valid(t *testing.T) {
+	def, err := ParseDefinition([]byte(`
+name: release
+timeout: 30m
+env:
+  BR_SOURCE: /tmp/src
+stages:
+  - name: convert
+    command: nr-convert
+    env:
+      BR_TARGET: /tmp/xml
+    outputs:
+      xml: ${env.BR_TARGET}
+  - name: update
+    command: nr-dbupdate
+    when: stages.convert.outputs.xml != ''
+    continue_on_error: true
+    config:
+      extensions: [ExtA]
+      dry_run: true
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stages := def.toStages()
+	if len(stages) != 2 {
+		t.Fatalf("expected 2 stages, got %d", len(stages))
+	}
+	if stages[0].Env["BR_SOURCE"] != "/tmp/src" || stages[0].Env["BR_TARGET"] != "/tmp/xml" {
+		t.Errorf("pipeline env must be merged into stage env: %v", stages[0].Env)
+	}
+	if stages[1].When == nil || !stages[1].ContinueOnError || stages[1].CommandName != "nr-dbupdate" {
+		t.Errorf("unexpected stage: %+v", stages[1])
+	}
+}
+
+func TestParseDefinition_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{"empty", ``, "пустое определение"},
+		{"no stages", `name: x`, "не задан ни один этап"},
+		{"unknown field", "stages:\n  - name: a\n    command: nr-version\n    retry: 3\n", "некорректный YAML"},
+		{"bad name", "stages:\n  - name: a.b\n    command: nr-version\n", "некорректное имя этапа"},
+		{"duplicate", "stages:\n  - name: a\n    command: nr-version\n  - name: a\n    command: nr-version\n", "не уникально"},
+		{"no command", "stages:\n  - name: a\n", "не указана команда"},
+		{"recursion", "stages:\n  - name: a\n    command: nr-convert-pipeline\n", "не поддерживается"},
+		{"bad timeout", "timeout: soon\nstages:\n  - name: a\n    command: nr-version\n", "некорректный timeout"},
+		{"forward ref", "stages:\n  - name: a\n    command: nr-version\n    when: stages.b.status == 'success'\n  - name: b\n    command: nr-version\n", "не объявлен выше"},
+		{"bad ref", "stages:\n  - name: a\n    command: nr-version\n    env:\n      X: ${stages.a}\n", "недопустимая ссылка"},
+		{"bad env name", "stages:\n  - name: a\n    command: nr-version\n    env:\n      1X: y\n", "некорректное имя переменной"},
+		{"config env", "env:\n  BR_INFOBASE_NAME: Base\nstages:\n  - name: a\n    command: nr-version\n", "используйте config этапа"},
+		{"nested config env", "stages:\n  - name: a\n    command: nr-version\n    env:\n      BR_EXTENSION_CONCURRENCY: \"4\"\n", "читается при загрузке конфигурации"},
+		{"unclosed quote", "stages:\n  - name: a\n    command: nr-version\n    when: stages.a.status == 'x\n", "незакрытая кавычка"},
+		{"double compare", "stages:\n  - name: a\n    command: nr-version\n  - name: b\n    command: nr-version\n    when: stages.a.status == 'x' == 'y'\n", "несколько сравнений"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseDefinition([]byte(tc.yaml))
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("error %q does not contain %q", err.Error(), tc.want)
+			}
+		})
+	}
+}
+
+func TestConditionEval(t *testing.T) {
+	pctx := &PipelineContext{}
+	pctx.setResult("build", StatusSuccess, map[string]string{"version": "1.2", "changed": "false"})
+	pctx.setResult("lint", StatusFailure, nil)
+
+	tests := []struct {
+		expr   string
+		failed bool
+		want   bool
+	}{
+		{"always", true, true},
+		{"success", false, true},
+		{"success", true, false},
+		{"failure", true, true},
+		{"failure", false, false},
+		{"stages.build.status == 'success'", false, true},
+		{"${stages.lint.status} == \"failure\"", false, true},
+		{"stages.build.outputs.version != '1.2'", false, false},
+		{"stages.build.outputs.changed", false, false},
+		{"stages.build.outputs.version && stages.lint.status == 'failure'", false, true},
+		{"stages.build.outputs.missing || stages.build.outputs.version == '1.2'", false, true},
+		// Операторы внутри значений в кавычках не разделяют термы.
+		{"stages.build.outputs.version == 'a || b'", false, false},
+		{"stages.build.outputs.version != 'x && y' && stages.lint.status == \"failure\"", false, true},
+		{"stages.build.outputs.version != '1.2 == 1.2'", false, true},
+		// Без always/failure условие не выполняется после ошибки пайплайна.
+		{"stages.build.status == 'success'", true, false},
+		{"failure && stages.lint.status == 'failure'", true, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.expr, func(t *testing.T) {
+			cond, err := ParseCondition(tc.expr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			pctx.Failed = tc.failed
+			if got := cond.Eval(pctx); got != tc.want {
+				t.Errorf("Eval(%q, failed=%v) = %v, want %v", tc.expr, tc.failed, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	t.Setenv("PIPELINE_TEST_VAR", "value")
+	pctx := &PipelineContext{}
+	pctx.setResult("convert", StatusSuccess, map[string]string{"xml": "/out"})
+
+	got := pctx.interpolate("${env.PIPELINE_TEST_VAR}:${ stages.convert.outputs.xml }:${stages.convert.status}:${stages.none.status}")
+	if got != "value:/out:success:" {
+		t.Errorf("unexpected interpolation result: %q", got)
+	}
+}
+
+func TestExecuteDeclarativePipeline(t *testing.T) {
+	setupEnv(t)
+	writePipelineFile(t, `
+name: custom
+stages:
+  - name: first
+    command: nr-version
+    env:
+      PIPELINE_TEST_TARGET: /tmp/out
+    outputs:
+      target: ${env.PIPELINE_TEST_TARGET}
+  - name: second
+    command: nr-version
+    when: stages.first.outputs.target == '/tmp/out'
+    env:
+      PIPELINE_TEST_INPUT: ${stages.first.outputs.target}
+    config:
+      infobase_name: Base-${stages.first.outputs.target}
+      extensions: [ExtA]
+  - name: never
+    command: nr-version
+    when: stages.first.status == 'failure'
+`)
+	var input, infobase string
+	var exts []string
+	exec := &funcExecutor{fn: func(stage string, cfg *config.Config) error {
+		if stage == "second" {
+			input = os.Getenv("PIPELINE_TEST_INPUT")
+			infobase, exts = cfg.InfobaseName, cfg.AddArray
+		}
+		return nil
+	}}
+	cfg := &config.Config{InfobaseName: "Orig"}
+	h := &ConvertPipelineHandler{executor: exec}
+
+	if err := h.Execute(context.Background(), cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fmt.Sprint(exec.calls) != "[first second]" {
+		t.Errorf("unexpected calls: %v", exec.calls)
+	}
+	if input != "/tmp/out" {
+		t.Errorf("output must be passed to later stage env, got %q", input)
+	}
+	if infobase != "Base-/tmp/out" || len(exts) != 1 || exts[0] != "ExtA" {
+		t.Errorf("config overrides not applied: %q %v", infobase, exts)
+	}
+	if cfg.InfobaseName != "Orig" {
+		t.Error("original config must not be modified")
+	}
+	if _, ok := os.LookupEnv("PIPELINE_TEST_INPUT"); ok {
+		t.Error("stage env must be restored after stage")
+	}
+}
+
+func TestExecuteDeclarativeFailureHandling(t *testing.T) {
+	setupEnv(t)
+	writePipelineFile(t, `
+stages:
+  - name: flaky
+    command: nr-version
+    continue_on_error: true
+  - name: main
+    command: nr-version
+  - name: after-main
+    command: nr-version
+  - name: cleanup
+    command: nr-version
+    when: always
+  - name: report
+    command: nr-version
+    when: failure && stages.flaky.status == 'failure'
+`)
+	exec := &funcExecutor{fn: func(stage string, _ *config.Config) error {
+		if stage == "flaky" || stage == "main" {
+			return fmt.Errorf("%s failed", stage)
+		}
+		return nil
+	}}
+	h := &ConvertPipelineHandler{executor: exec}
+
+	err := h.Execute(context.Background(), &config.Config{})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if !strings.Contains(err.Error(), "PIPELINE.MAIN_FAILED") {
+		t.Errorf("error code must point to the first failed stage: %v", err)
+	}
+	if fmt.Sprint(exec.calls) != "[flaky main cleanup report]" {
+		t.Errorf("unexpected calls: %v", exec.calls)
+	}
+}
+
+func TestExecuteIgnoresPipelineFileInWorkDir(t *testing.T) {
+	setupEnv(t)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "pipeline.yaml"), []byte("stages:\n  - name: a\n    command: nr-version\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+	exec := &funcExecutor{}
+	h := &ConvertPipelineHandler{executor: exec}
+
+	if err := h.Execute(context.Background(), &config.Config{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fmt.Sprint(exec.calls) != fmt.Sprint([]string{StageConvert, StageGit2Store}) {
+		t.Errorf("pipeline.yaml без BR_PIPELINE_FILE не должен подхватываться, этапы: %v", exec.calls)
+	}
+}
+
+func TestExecuteInvalidDefinition(t *testing.T) {
+	setupEnv(t)
+	writePipelineFile(t, "stages: []\n")
+	exec := &funcExecutor{}
+	h := &ConvertPipelineHandler{executor: exec}
+
+	err := h.Execute(context.Background(), &config.Config{})
+	if err == nil || !strings.Contains(err.Error(), "PIPELINE.INVALID_DEFINITION") {
+		t.Fatalf("expected PIPELINE.INVALID_DEFINITION, got %v", err)
+	}
+	if len(exec.calls) != 0 {
+		t.Errorf("no stages must run, got %v", exec.calls)
+	}
+}
+
+func TestExecuteUnregisteredCommand(t *testing.T) {
+	setupEnv(t)
+	writePipelineFile(t, "stages:\n  - name: a\n    command: nr-no-such-command\n")
+	h := &ConvertPipelineHandler{}
+
+	err := h.Execute(context.Background(), &config.Config{})
+	if err == nil || !strings.Contains(err.Error(), "не зарегистрирована") {
+		t.Fatalf("expected unregistered command error, got %v", err)
+	}
+}
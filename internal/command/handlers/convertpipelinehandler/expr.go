@@ -0,0 +1,301 @@
+package convertpipelinehandler
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Ссылки на данные пайплайна в подстановках ${...} и условиях when:
+//
+//	env.<NAME>                  — переменная окружения
+//	stages.<этап>.status        — success | failure | skipped
+//	stages.<этап>.outputs.<key> — output ранее выполненного этапа
+var interpolationPattern = regexp.MustCompile(`\$\{\s*([^}]*?)\s*\}`)
+
+// ref — разобранная ссылка на данные пайплайна.
+type ref struct {
+	env    string
+	stage  string
+	output string // пусто — ссылка на status
+}
+
+// parseRef разбирает ссылку вида env.X, stages.S.status или stages.S.outputs.K.
+func parseRef(s string) (ref, error) {
+	parts := strings.Split(s, ".")
+	switch {
+	case len(parts) == 2 && parts[0] == "env" && parts[1] != "":
+		return ref{env: parts[1]}, nil
+	case len(parts) == 3 && parts[0] == "stages" && parts[1] != "" && parts[2] == "status":
+		return ref{stage: parts[1]}, nil
+	case len(parts) == 4 && parts[0] == "stages" && parts[1] != "" && parts[2] == "outputs" && parts[3] != "":
+		return ref{stage: parts[1], output: parts[3]}, nil
+	}
+	return ref{}, fmt.Errorf("недопустимая ссылка %q (ожидается env.<NAME>, stages.<этап>.status или stages.<этап>.outputs.<key>)", s)
+}
+
+// resolve возвращает значение ссылки; для невыполненных этапов и отсутствующих outputs — пустую строку.
+func (pctx *PipelineContext) resolve(r ref) string {
+	if r.env != "" {
+		return getenv(r.env)
+	}
+	res := pctx.Results[r.stage]
+	if res == nil {
+		return ""
+	}
+	if r.output == "" {
+		return res.Status
+	}
+	return res.Outputs[r.output]
+}
+
+// interpolate подставляет значения ссылок ${...} в строку.
+// Ссылки проверяются при загрузке определения, поэтому неразобранные остаются как есть.
+func (pctx *PipelineContext) interpolate(s string) string {
+	return interpolationPattern.ReplaceAllStringFunc(s, func(m string) string {
+		r, err := parseRef(interpolationPattern.FindStringSubmatch(m)[1])
+		if err != nil {
+			return m
+		}
+		return pctx.resolve(r)
+	})
+}
+
+// interpolateMap возвращает копию map с подставленными значениями.
+func (pctx *PipelineContext) interpolateMap(m map[string]string) map[string]string {
+	if len(m) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(m))
+	for k, v := range m {
+		result[k] = pctx.interpolate(v)
+	}
+	return result
+}
+
+// templateRefs возвращает ссылки, используемые в строке с подстановками.
+func templateRefs(s string) ([]ref, error) {
+	var refs []ref
+	for _, m := range interpolationPattern.FindAllStringSubmatch(s, -1) {
+		r, err := parseRef(m[1])
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, r)
+	}
+	return refs, nil
+}
+
+// Condition — условие when этапа.
+//
+// Грамматика: группы, разделённые "||", каждая группа — термы, разделённые "&&".
+// Терм: always | success | failure | <ссылка> | <ссылка> == 'значение' | <ссылка> != 'значение'.
+// Ссылка может быть записана как есть или в форме ${...}. Операторы внутри
+// значений в кавычках и подстановок ${...} не учитываются.
+//
+// Если условие не содержит always или failure, этап дополнительно требует,
+// чтобы предыдущие этапы завершились успешно (аналогично Gitea/GitHub Actions).
+type Condition struct {
+	expr        string
+	groups      [][]term
+	afterFailed bool
+}
+
+// termKind — вид терма условия.
+type termKind int
+
+const (
+	termAlways termKind = iota
+	termSuccess
+	termFailure
+	termTruthy
+	termEqual
+	termNotEqual
+)
+
+// term — элементарное условие.
+type term struct {
+	kind  termKind
+	ref   ref
+	value string
+}
+
+// ParseCondition разбирает выражение when.
+func ParseCondition(expr string) (*Condition, error) {
+	c := &Condition{expr: strings.TrimSpace(expr)}
+	if c.expr == "" {
+		return nil, fmt.Errorf("пустое условие when")
+	}
+	groups, err := splitOutside(c.expr, "||")
+	if err != nil {
+		return nil, fmt.Errorf("условие when %q: %w", c.expr, err)
+	}
+	for _, group := range groups {
+		raws, err := splitOutside(group, "&&")
+		if err != nil {
+			return nil, fmt.Errorf("условие when %q: %w", c.expr, err)
+		}
+		var terms []term
+		for _, raw := range raws {
+			t, err := parseTerm(strings.TrimSpace(raw))
+			if err != nil {
+				return nil, fmt.Errorf("условие when %q: %w", c.expr, err)
+			}
+			if t.kind == termAlways || t.kind == termFailure {
+				c.afterFailed = true
+			}
+			terms = append(terms, t)
+		}
+		c.groups = append(c.groups, terms)
+	}
+	return c, nil
+}
+
+// parseTerm разбирает один терм условия.
+func parseTerm(s string) (term, error) {
+	switch s {
+	case "":
+		return term{}, fmt.Errorf("пустой терм")
+	case "always":
+		return term{kind: termAlways}, nil
+	case "success":
+		return term{kind: termSuccess}, nil
+	case "failure":
+		return term{kind: termFailure}, nil
+	}
+
+	kind, lhs, rhs := termTruthy, s, ""
+	for _, op := range []struct {
+		token string
+		kind  termKind
+	}{{"!=", termNotEqual}, {"==", termEqual}} {
+		parts, err := splitOutside(s, op.token)
+		if err != nil {
+			return term{}, err
+		}
+		if len(parts) > 2 {
+			return term{}, fmt.Errorf("терм %q содержит несколько сравнений", s)
+		}
+		if len(parts) == 2 {
+			kind, lhs, rhs = op.kind, parts[0], parts[1]
+			break
+		}
+	}
+
+	r, err := parseRef(unwrapRef(strings.TrimSpace(lhs)))
+	if err != nil {
+		return term{}, err
+	}
+	return term{kind: kind, ref: r, value: unquote(strings.TrimSpace(rhs))}, nil
+}
+
+// splitOutside разбивает s по разделителю sep, пропуская значения в кавычках
+// и подстановки ${...}. Незакрытая кавычка или подстановка — ошибка.
+func splitOutside(s, sep string) ([]string, error) {
+	var parts []string
+	var quote byte
+	inRef := false
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case inRef:
+			if c == '}' {
+				inRef = false
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case strings.HasPrefix(s[i:], "${"):
+			inRef = true
+			i++
+		case strings.HasPrefix(s[i:], sep):
+			parts = append(parts, s[start:i])
+			i += len(sep) - 1
+			start = i + 1
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("незакрытая кавычка")
+	}
+	if inRef {
+		return nil, fmt.Errorf("незакрытая подстановка ${")
+	}
+	return append(parts, s[start:]), nil
+}
+
+// unwrapRef снимает обёртку ${...} со ссылки.
+func unwrapRef(s string) string {
+	if m := interpolationPattern.FindStringSubmatch(s); m != nil && m[0] == s {
+		return m[1]
+	}
+	return s
+}
+
+// unquote снимает одинарные или двойные кавычки со значения.
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// Eval вычисляет условие в контексте пайплайна.
+func (c *Condition) Eval(pctx *PipelineContext) bool {
+	if !c.afterFailed && pctx.Failed {
+		return false
+	}
+	for _, group := range c.groups {
+		matched := true
+		for _, t := range group {
+			if !t.eval(pctx) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// eval вычисляет терм.
+func (t term) eval(pctx *PipelineContext) bool {
+	switch t.kind {
+	case termAlways:
+		return true
+	case termSuccess:
+		return !pctx.Failed
+	case termFailure:
+		return pctx.Failed
+	case termEqual:
+		return pctx.resolve(t.ref) == t.value
+	case termNotEqual:
+		return pctx.resolve(t.ref) != t.value
+	default:
+		v := pctx.resolve(t.ref)
+		return v != "" && v != "false"
+	}
+}
+
+// refs возвращает ссылки, используемые в условии.
+func (c *Condition) refs() []ref {
+	var refs []ref
+	for _, group := range c.groups {
+		for _, t := range group {
+			if t.kind >= termTruthy {
+				refs = append(refs, t.ref)
+			}
+		}
+	}
+	return refs
+}
+
+// String возвращает исходное выражение условия.
+func (c *Condition) String() string {
+	return c.expr
+}
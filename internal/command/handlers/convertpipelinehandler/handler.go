@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"maps"
 	"os"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/Kargones/apk-ci/internal/command"
@@ -36,10 +39,14 @@ const defaultPipelineTimeout = 3 * time.Hour
 
 // PipelineData — результат всего пайплайна для JSON/text вывода.
 type PipelineData struct {
-	StateChanged bool                  `json:"state_changed"`
-	Stages       []StageOutcome        `json:"stages"`
-	Context      *PipelineContextData  `json:"context,omitempty"`
-	DurationMs   int64                 `json:"duration_ms"`
+	// Pipeline — имя пайплайна из pipeline.yaml (пусто для встроенного пайплайна).
+	Pipeline string `json:"pipeline,omitempty"`
+	// Source — файл определения пайплайна (пусто для встроенного пайплайна).
+	Source       string               `json:"source,omitempty"`
+	StateChanged bool                 `json:"state_changed"`
+	Stages       []StageOutcome       `json:"stages"`
+	Context      *PipelineContextData `json:"context,omitempty"`
+	DurationMs   int64                `json:"duration_ms"`
 }
 
 // PipelineContextData — сериализуемое представление PipelineContext.
@@ -56,7 +63,19 @@ func (d *PipelineData) writeText(w io.Writer) error {
 	if !d.StateChanged {
 		status = "ошибка"
 	}
-	if _, err := fmt.Fprintf(w, "Pipeline: %s\n\nЭтапы:\n", status); err != nil {
+	name := ""
+	if d.Pipeline != "" {
+		name = " " + d.Pipeline
+	}
+	if _, err := fmt.Fprintf(w, "Pipeline%s: %s\n", name, status); err != nil {
+		return err
+	}
+	if d.Source != "" {
+		if _, err := fmt.Fprintf(w, "Определение: %s\n", d.Source); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(w, "\nЭтапы:\n"); err != nil {
 		return err
 	}
 	for _, s := range d.Stages {
@@ -73,9 +92,17 @@ func (d *PipelineData) writeText(w io.Writer) error {
 		if s.Error != "" {
 			line += fmt.Sprintf(" — %s", s.Error)
 		}
+		if s.ContinuedOnError {
+			line += " (continue_on_error)"
+		}
 		if _, err := fmt.Fprintln(w, line); err != nil {
 			return err
 		}
+		for _, k := range slices.Sorted(maps.Keys(s.Outputs)) {
+			if _, err := fmt.Fprintf(w, "      %s = %s\n", k, s.Outputs[k]); err != nil {
+				return err
+			}
+		}
 	}
 
 	// Вывод переданных данных между этапами
@@ -128,20 +155,28 @@ func (h *ConvertPipelineHandler) Name() string {
 
 // Description возвращает описание команды для вывода в help.
 func (h *ConvertPipelineHandler) Description() string {
-	return "Pipeline: конвертация EDT→XML → Git→хранилище → публикация расширений"
+	return "Pipeline: этапы из BR_PIPELINE_FILE или конвертация EDT→XML → Git→хранилище → публикация расширений"
 }
 
 // Execute выполняет пайплайн из атомарных этапов с передачей результатов.
 //
+// Этапы берутся из файла BR_PIPELINE_FILE (см. PipelineDefinition), если он не задан —
+// встроенные convert → git2store → extension-publish.
+//
 // Каждый этап:
-//  1. Проверяет условие запуска (ShouldRun)
-//  2. Получает данные от предыдущих этапов через PipelineContext (BeforeRun)
-//  3. Выполняет NR-команду атомарно
-//  4. Сохраняет результат в PipelineContext (AfterRun)
+//  1. Проверяет условие запуска (when, ShouldRun)
+//  2. Получает данные от предыдущих этапов через PipelineContext (env/config, BeforeRun)
+//  3. Выполняет команду атомарно
+//  4. Сохраняет результат и outputs в PipelineContext (AfterRun)
+//
+// Этап с ошибкой останавливает пайплайн: последующие этапы выполняются
+// только при when: always / failure. Ошибка этапа с continue_on_error
+// фиксируется в выводе, но не влияет на результат пайплайна.
 //
 // Переменные окружения:
+//   - BR_PIPELINE_FILE: путь к определению пайплайна (не задан — встроенные этапы)
 //   - BR_PIPELINE_SKIP_STAGES: этапы для пропуска (через запятую)
-//   - BR_PIPELINE_TIMEOUT: таймаут всего пайплайна (default: 3h)
+//   - BR_PIPELINE_TIMEOUT: таймаут всего пайплайна (default: timeout из определения или 3h)
 func (h *ConvertPipelineHandler) Execute(ctx context.Context, cfg *config.Config) error {
 	start := time.Now()
 
@@ -161,8 +196,32 @@ func (h *ConvertPipelineHandler) Execute(ctx context.Context, cfg *config.Config
 		slog.String("command", constants.ActNRConvertPipeline),
 	)
 
+	data := &PipelineData{Stages: []StageOutcome{}}
+
+	// Определение пайплайна: BR_PIPELINE_FILE или встроенные этапы
+	stages := buildStages()
+	def, source, err := loadDefinition()
+	if err == nil && def != nil {
+		stages = def.toStages()
+		data.Pipeline = def.Name
+		data.Source = source
+		if h.executor == nil {
+			err = checkCommands(stages)
+		}
+	}
+	if err != nil {
+		return h.writeError(format, traceID, start, data, "PIPELINE.INVALID_DEFINITION", err.Error())
+	}
+	if def != nil {
+		log.Info("Загружено определение пайплайна", slog.String("file", source), slog.String("pipeline", def.Name))
+	} else {
+		log.Info("BR_PIPELINE_FILE не задан, выполняются встроенные этапы")
+	}
 	// Таймаут пайплайна
 	timeout := defaultPipelineTimeout
+	if def != nil && def.Timeout != "" {
+		timeout, _ = time.ParseDuration(def.Timeout) //nolint:errcheck // проверено в Validate
+	}
 	if envTimeout := getenv("BR_PIPELINE_TIMEOUT"); envTimeout != "" {
 		if parsed, err := time.ParseDuration(envTimeout); err == nil {
 			timeout = parsed
@@ -181,42 +240,54 @@ func (h *ConvertPipelineHandler) Execute(ctx context.Context, cfg *config.Config
 	// Pipeline context — аккумулятор данных между этапами
 	pctx := &PipelineContext{Cfg: cfg}
 
-	data := &PipelineData{
-		Stages: make([]StageOutcome, 0, 3),
-	}
-
 	log.Info("Запуск pipeline",
+		slog.String("pipeline", data.Pipeline),
+		slog.String("source", data.Source),
+		slog.Int("stages", len(stages)),
 		slog.Duration("timeout", timeout),
 		slog.Any("skip_stages", skipStages))
 
-	// Выполняем этапы последовательно
-	stages := buildStages()
+	// Выполняем этапы последовательно; первая ошибка определяет код ошибки пайплайна
+	var failedStage string
+	var failedErr error
 	for _, stage := range stages {
 		// Проверка skip
 		if skipStages[stage.Name] {
 			outcome := StageOutcome{
 				Name:       stage.Name,
+				Command:    stage.CommandName,
 				Skipped:    true,
 				Success:    true,
 				SkipReason: "пропущен через BR_PIPELINE_SKIP_STAGES",
 			}
 			data.Stages = append(data.Stages, outcome)
+			pctx.setResult(stage.Name, StatusSkipped, nil)
 			log.Info("Этап пропущен (skip list)", slog.String("stage", stage.Name))
 			continue
 		}
 
 		outcome, err := executeStage(pipelineCtx, stage, pctx, log, h.executor)
+		if err != nil && stage.ContinueOnError {
+			outcome.ContinuedOnError = true
+			log.Warn("Ошибка этапа проигнорирована (continue_on_error)", slog.String("stage", stage.Name))
+			err = nil
+		}
 		data.Stages = append(data.Stages, outcome)
 
-		if err != nil {
-			// Этап провалился — останавливаем пайплайн
-			data.Context = buildContextData(pctx)
-			return h.writeError(format, traceID, start, data,
-				fmt.Sprintf("PIPELINE.%s_FAILED", stageToCode(stage.Name)),
-				fmt.Sprintf("Этап %s: %s", stage.Name, err.Error()))
+		if err != nil && failedErr == nil {
+			// Этап провалился — последующие этапы выполняются только при when: always/failure
+			failedStage, failedErr = stage.Name, err
+			pctx.Failed = true
 		}
 	}
 
+	if failedErr != nil {
+		data.Context = buildContextData(pctx)
+		return h.writeError(format, traceID, start, data,
+			fmt.Sprintf("PIPELINE.%s_FAILED", stageToCode(failedStage)),
+			fmt.Sprintf("Этап %s: %s", failedStage, failedErr.Error()))
+	}
+
 	// Успех
 	data.DurationMs = time.Since(start).Milliseconds()
 	data.StateChanged = true
@@ -248,16 +319,10 @@ func buildContextData(pctx *PipelineContext) *PipelineContextData {
 
 // stageToCode возвращает код ошибки этапа (UPPER_SNAKE_CASE).
 func stageToCode(stage string) string {
-	switch stage {
-	case StageConvert:
-		return "CONVERT"
-	case StageGit2Store:
-		return "GIT2STORE"
-	case StageExtensionPublish:
-		return "EXTENSION_PUBLISH"
-	default:
+	if stage == "" {
 		return "UNKNOWN"
 	}
+	return strings.ToUpper(strings.ReplaceAll(stage, "-", "_"))
 }
 
 // parseSkipStages парсит BR_PIPELINE_SKIP_STAGES в map.
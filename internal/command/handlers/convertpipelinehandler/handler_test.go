@@ -74,6 +74,8 @@ func TestStageToCode(t *testing.T) {
 		{StageGit2Store, "GIT2STORE"},
 		{StageExtensionPublish, "EXTENSION_PUBLISH"},
 		{"unknown", "UNKNOWN"},
+		{"post-restore", "POST_RESTORE"},
+		{"", "UNKNOWN"},
 	}
 	for _, tc := range tests {
 		if got := stageToCode(tc.stage); got != tc.want {
@@ -89,6 +91,7 @@ func setupEnv(t *testing.T) {
 	os.Setenv("BR_OUTPUT_FORMAT", "text")
 	os.Unsetenv("BR_PIPELINE_SKIP_STAGES")
 	os.Unsetenv("BR_PIPELINE_TIMEOUT")
+	os.Unsetenv("BR_PIPELINE_FILE")
 	t.Cleanup(func() {
 		os.Unsetenv("BR_OUTPUT_FORMAT")
 		os.Unsetenv("BR_PIPELINE_SKIP_STAGES")
@@ -2,6 +2,11 @@
 // объединяющую nr-convert → nr-git2store → nr-extension-publish
 // в единый пайплайн с атомарными этапами и передачей результатов между ними.
 //
+// Если задан BR_PIPELINE_FILE (например, pipeline.yaml в репозитории проекта),
+// этапы берутся из него: каждый этап вызывает любую зарегистрированную команду
+// через command.Get с собственными env/config overrides, условием when,
+// continue_on_error и outputs, доступными последующим этапам.
+//
 // Архитектура:
 //
 //	PipelineContext хранит аккумулированные данные всех этапов.
@@ -16,6 +21,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"os"
 	"time"
 
 	"github.com/Kargones/apk-ci/internal/command"
@@ -37,6 +43,26 @@ type PipelineContext struct {
 
 	// ExtPublish — результат этапа nr-extension-publish (nil если этап не выполнялся).
 	ExtPublish *ExtPublishStageResult
+
+	// Results — статусы и outputs выполненных этапов по имени этапа.
+	// Используются в условиях when и подстановках ${stages.<имя>.*}.
+	Results map[string]*StageResult
+
+	// Failed — true, если один из этапов завершился с ошибкой без continue_on_error.
+	Failed bool
+}
+
+// Статусы этапа, доступные через ${stages.<имя>.status}.
+const (
+	StatusSuccess = "success"
+	StatusFailure = "failure"
+	StatusSkipped = "skipped"
+)
+
+// StageResult — итог этапа, доступный последующим этапам.
+type StageResult struct {
+	Status  string
+	Outputs map[string]string
 }
 
 // ConvertStageResult — результат этапа конвертации.
@@ -82,6 +108,19 @@ type Stage struct {
 	// Вызывается после успешного выполнения.
 	// Если nil — ничего не делает.
 	AfterRun func(pctx *PipelineContext, log *slog.Logger)
+
+	// Поля декларативных этапов (pipeline.yaml). Для встроенных этапов пустые.
+
+	// Env — переменные окружения на время этапа (значения с подстановками ${...}).
+	Env map[string]string
+	// Config — переопределения конфигурации для этапа.
+	Config *ConfigOverrides
+	// When — условие запуска; nil означает «все предыдущие этапы успешны».
+	When *Condition
+	// ContinueOnError — ошибка этапа не останавливает пайплайн.
+	ContinueOnError bool
+	// Outputs — значения, публикуемые этапом после успешного выполнения.
+	Outputs map[string]string
 }
 
 // buildStages возвращает список этапов пайплайна с логикой передачи данных.
@@ -144,16 +183,20 @@ func buildStages() []Stage {
 
 // StageOutcome — результат выполнения одного этапа.
 type StageOutcome struct {
-	Name       string `json:"name"`
-	Success    bool   `json:"success"`
-	Skipped    bool   `json:"skipped,omitempty"`
-	SkipReason string `json:"skip_reason,omitempty"`
-	DurationMs int64  `json:"duration_ms"`
-	Error      string `json:"error,omitempty"`
+	Name             string            `json:"name"`
+	Command          string            `json:"command,omitempty"`
+	Success          bool              `json:"success"`
+	Skipped          bool              `json:"skipped,omitempty"`
+	SkipReason       string            `json:"skip_reason,omitempty"`
+	ContinuedOnError bool              `json:"continued_on_error,omitempty"`
+	DurationMs       int64             `json:"duration_ms"`
+	Error            string            `json:"error,omitempty"`
+	Outputs          map[string]string `json:"outputs,omitempty"`
 }
 
 // executeStage выполняет один атомарный этап пайплайна.
-// Возвращает StageOutcome и error (non-nil если этап провалился и пайплайн должен остановиться).
+// Возвращает StageOutcome и error (non-nil если этап провалился).
+// Итог этапа записывается в pctx.Results.
 func executeStage(
 	ctx context.Context,
 	stage Stage,
@@ -161,36 +204,47 @@ func executeStage(
 	log *slog.Logger,
 	executor StageExecutor,
 ) (StageOutcome, error) {
-	// Проверяем условие запуска
-	if stage.ShouldRun != nil && !stage.ShouldRun(pctx) {
-		reason := fmt.Sprintf("условие запуска не выполнено для %s", stage.Name)
+	// Проверяем условие запуска: when (или «предыдущие этапы успешны»), затем ShouldRun
+	if reason := skipReason(stage, pctx); reason != "" {
 		log.Info("Этап пропущен", slog.String("stage", stage.Name), slog.String("reason", reason))
+		pctx.setResult(stage.Name, StatusSkipped, nil)
 		return StageOutcome{
 			Name:       stage.Name,
+			Command:    stage.CommandName,
 			Skipped:    true,
 			Success:    true,
 			SkipReason: reason,
 		}, nil
 	}
 
+	// Переменные окружения этапа действуют только на время его выполнения,
+	// включая вычисление outputs.
+	restoreEnv := applyEnv(pctx.interpolateMap(stage.Env))
+	defer restoreEnv()
+
+	stageCfg := pctx.Cfg
+	if stage.Config != nil {
+		stageCfg = stage.Config.apply(pctx.Cfg, pctx.interpolate)
+	}
+
 	// BeforeRun — подготовка (передача данных из предыдущих этапов)
 	if stage.BeforeRun != nil {
 		stage.BeforeRun(pctx, log)
 	}
 
 	stageStart := time.Now()
-	log.Info("Начало этапа", slog.String("stage", stage.Name))
+	log.Info("Начало этапа", slog.String("stage", stage.Name), slog.String("command", stage.CommandName))
 
 	// Выполняем команду
 	var execErr error
 	if executor != nil {
-		execErr = executor.ExecuteStage(ctx, stage.Name, pctx.Cfg)
+		execErr = executor.ExecuteStage(ctx, stage.Name, stageCfg)
 	} else {
 		handler, ok := command.Get(stage.CommandName)
 		if !ok {
 			execErr = fmt.Errorf("команда %s не зарегистрирована", stage.CommandName)
 		} else {
-			execErr = handler.Execute(ctx, pctx.Cfg)
+			execErr = handler.Execute(ctx, stageCfg)
 		}
 	}
 
@@ -201,8 +255,10 @@ func executeStage(
 			slog.String("stage", stage.Name),
 			slog.Int64("duration_ms", durationMs),
 			slog.String("error", execErr.Error()))
+		pctx.setResult(stage.Name, StatusFailure, nil)
 		return StageOutcome{
 			Name:       stage.Name,
+			Command:    stage.CommandName,
 			Success:    false,
 			DurationMs: durationMs,
 			Error:      execErr.Error(),
@@ -213,6 +269,8 @@ func executeStage(
 	if stage.AfterRun != nil {
 		stage.AfterRun(pctx, log)
 	}
+	outputs := pctx.interpolateMap(stage.Outputs)
+	pctx.setResult(stage.Name, StatusSuccess, outputs)
 
 	log.Info("Этап завершён успешно",
 		slog.String("stage", stage.Name),
@@ -220,7 +278,58 @@ func executeStage(
 
 	return StageOutcome{
 		Name:       stage.Name,
+		Command:    stage.CommandName,
 		Success:    true,
 		DurationMs: durationMs,
+		Outputs:    outputs,
 	}, nil
 }
+
+// skipReason возвращает причину пропуска этапа или пустую строку, если этап нужно запускать.
+func skipReason(stage Stage, pctx *PipelineContext) string {
+	if stage.When == nil {
+		if pctx.Failed {
+			return "предыдущий этап завершился с ошибкой"
+		}
+	} else if !stage.When.Eval(pctx) {
+		return fmt.Sprintf("условие when не выполнено: %s", stage.When)
+	}
+	if stage.ShouldRun != nil && !stage.ShouldRun(pctx) {
+		return fmt.Sprintf("условие запуска не выполнено для %s", stage.Name)
+	}
+	return ""
+}
+
+// setResult сохраняет итог этапа в контексте пайплайна.
+func (pctx *PipelineContext) setResult(name, status string, outputs map[string]string) {
+	if pctx.Results == nil {
+		pctx.Results = make(map[string]*StageResult)
+	}
+	pctx.Results[name] = &StageResult{Status: status, Outputs: outputs}
+}
+
+// applyEnv устанавливает переменные окружения и возвращает функцию восстановления прежних значений.
+func applyEnv(env map[string]string) func() {
+	if len(env) == 0 {
+		return func() {}
+	}
+	type prevValue struct {
+		value string
+		set   bool
+	}
+	prev := make(map[string]prevValue, len(env))
+	for k, v := range env {
+		old, ok := os.LookupEnv(k)
+		prev[k] = prevValue{value: old, set: ok}
+		_ = os.Setenv(k, v) //nolint:errcheck // имя проверено при валидации определения
+	}
+	return func() {
+		for k, p := range prev {
+			if p.set {
+				_ = os.Setenv(k, p.value) //nolint:errcheck // восстановление прежнего значения
+			} else {
+				_ = os.Unsetenv(k) //nolint:errcheck // восстановление прежнего значения
+			}
+		}
+	}
+}
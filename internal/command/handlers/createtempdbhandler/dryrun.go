@@ -6,7 +6,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/Kargones/apk-ci/internal/config"
 	"github.com/Kargones/apk-ci/internal/constants"
 	"github.com/Kargones/apk-ci/internal/pkg/dryrun"
 	"github.com/Kargones/apk-ci/internal/pkg/output"
@@ -16,7 +15,7 @@ import (
 // Используется в dry-run, plan-only и verbose режимах.
 // Story 7.3: извлечено из executeDryRun для переиспользования.
 func (h *CreateTempDbHandler) buildPlan(
-	cfg *config.Config,
+	binIbcmd string,
 	dbPath string,
 	extensions []string,
 	timeout time.Duration,
@@ -29,7 +28,7 @@ func (h *CreateTempDbHandler) buildPlan(
 			Order:     1,
 			Operation: "Валидация конфигурации",
 			Parameters: map[string]any{
-				"ibcmd_path": cfg.AppConfig.Paths.BinIbcmd,
+				"ibcmd_path": binIbcmd,
 			},
 			ExpectedChanges: []string{"Нет изменений — только валидация"},
 		},
@@ -97,7 +96,7 @@ func (h *CreateTempDbHandler) buildPlan(
 // AC-2: План содержит операции, параметры, ожидаемые изменения.
 // AC-8: НЕ вызывается client.CreateTempDB().
 func (h *CreateTempDbHandler) executeDryRun(
-	cfg *config.Config,
+	binIbcmd string,
 	dbPath string,
 	extensions []string,
 	timeout time.Duration,
@@ -105,7 +104,7 @@ func (h *CreateTempDbHandler) executeDryRun(
 	format, traceID string,
 	start time.Time,
 ) error {
-	plan := h.buildPlan(cfg, dbPath, extensions, timeout, ttlHours)
+	plan := h.buildPlan(binIbcmd, dbPath, extensions, timeout, ttlHours)
 	return output.WriteDryRunResult(os.Stdout, format, constants.ActNRCreateTempDb, traceID, constants.APIVersion, start, plan)
 }
 
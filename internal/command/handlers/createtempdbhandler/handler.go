@@ -20,6 +20,7 @@ import (
 	"github.com/Kargones/apk-ci/internal/constants"
 	"github.com/Kargones/apk-ci/internal/pkg/dryrun"
 	"github.com/Kargones/apk-ci/internal/pkg/output"
+	"github.com/Kargones/apk-ci/internal/pkg/platform"
 	"github.com/Kargones/apk-ci/internal/pkg/tracing"
 )
 
@@ -143,6 +144,7 @@ type tempDbExecContext struct {
 	format     string
 	log        *slog.Logger
 	dbPath     string
+	binIbcmd   string
 	extensions []string
 	timeout    time.Duration
 	ttlHours   int
@@ -167,14 +169,23 @@ func (h *CreateTempDbHandler) validateTempDbConfig(ctx context.Context, cfg *con
 		ec.log.Error("Конфигурация не указана")
 		return nil, h.writeError(ec.format, ec.traceID, ec.start, ErrCreateTempDbValidation, "конфигурация приложения не указана")
 	}
-	if cfg.AppConfig == nil || cfg.AppConfig.Paths.BinIbcmd == "" {
+	if cfg.AppConfig == nil || (cfg.AppConfig.Paths.BinIbcmd == "" && !cfg.PlatformConfig.Enabled()) {
 		ec.log.Error("Путь к ibcmd не указан в конфигурации")
 		return nil, h.writeError(ec.format, ec.traceID, ec.start, ErrCreateTempDbValidation, "путь к ibcmd не указан в конфигурации (app.yaml:paths.binIbcmd)")
 	}
 
-	if err := h.validateIbcmdBinary(ec, cfg.AppConfig.Paths.BinIbcmd); err != nil {
+	// Временная база файловая: версия сервера 1С не требуется, выбирается
+	// закреплённая (BR_PLATFORM_VERSION) или наиболее новая установленная версия.
+	binIbcmd, err := cfg.PlatformTool(platform.ToolIbcmd, platform.Requirement{})
+	if err != nil {
+		ec.log.Error("Не удалось выбрать версию платформы", slog.String("error", err.Error()))
+		return nil, h.writeError(ec.format, ec.traceID, ec.start, ErrCreateTempDbValidation,
+			"не удалось выбрать версию платформы для ibcmd: "+err.Error())
+	}
+	if err := h.validateIbcmdBinary(ec, binIbcmd); err != nil {
 		return nil, err
 	}
+	ec.binIbcmd = binIbcmd
 
 	var pathErr error
 	ec.dbPath, pathErr = h.generateDbPath(cfg)
@@ -224,16 +235,16 @@ func (h *CreateTempDbHandler) validateIbcmdBinary(ec *tempDbExecContext, binPath
 func (h *CreateTempDbHandler) handleTempDbPreviewModes(ec *tempDbExecContext, cfg *config.Config) (bool, error) {
 	if dryrun.IsDryRun() {
 		ec.log.Info("Dry-run режим: построение плана")
-		return true, h.executeDryRun(cfg, ec.dbPath, ec.extensions, ec.timeout, ec.ttlHours, ec.format, ec.traceID, ec.start)
+		return true, h.executeDryRun(ec.binIbcmd, ec.dbPath, ec.extensions, ec.timeout, ec.ttlHours, ec.format, ec.traceID, ec.start)
 	}
 	if dryrun.IsPlanOnly() {
 		ec.log.Info("Plan-only режим: отображение плана операций")
-		plan := h.buildPlan(cfg, ec.dbPath, ec.extensions, ec.timeout, ec.ttlHours)
+		plan := h.buildPlan(ec.binIbcmd, ec.dbPath, ec.extensions, ec.timeout, ec.ttlHours)
 		return true, output.WritePlanOnlyResult(os.Stdout, ec.format, constants.ActNRCreateTempDb, ec.traceID, constants.APIVersion, ec.start, plan)
 	}
 	if dryrun.IsVerbose() {
 		ec.log.Info("Verbose режим: отображение плана перед выполнением")
-		plan := h.buildPlan(cfg, ec.dbPath, ec.extensions, ec.timeout, ec.ttlHours)
+		plan := h.buildPlan(ec.binIbcmd, ec.dbPath, ec.extensions, ec.timeout, ec.ttlHours)
 		if ec.format != output.FormatJSON {
 			if err := plan.WritePlanText(os.Stdout); err != nil {
 				ec.log.Warn("Не удалось вывести план операций", slog.String("error", err.Error()))
@@ -285,7 +296,7 @@ func (h *CreateTempDbHandler) Execute(ctx context.Context, cfg *config.Config) e
 
 	opts := onec.CreateTempDBOptions{
 		DbPath: ec.dbPath, Extensions: ec.extensions,
-		Timeout: ec.timeout, BinIbcmd: cfg.AppConfig.Paths.BinIbcmd,
+		Timeout: ec.timeout, BinIbcmd: ec.binIbcmd,
 	}
 
 	result, err := client.CreateTempDB(ctx, opts)
@@ -138,6 +138,63 @@ func TestCreateTempDbHandler_Execute_Success_EmptyDB(t *testing.T) {
 	}
 }
 
+// TestCreateTempDbHandler_Execute_PlatformVersion проверяет выбор ibcmd из установленных
+// версий платформы по закреплённой версии (BR_PLATFORM_VERSION).
+func TestCreateTempDbHandler_Execute_PlatformVersion(t *testing.T) {
+	base := t.TempDir()
+	for _, v := range []string{"8.3.24.1467", "8.3.27.1606"} {
+		if err := os.MkdirAll(filepath.Join(base, v), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(base, v, "ibcmd"), []byte("#!/bin/bash\n"), 0755); err != nil { //nolint:gosec // тестовая заглушка
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		version string
+		wantBin string
+		wantErr bool
+	}{
+		{name: "pinned version installed", version: "8.3.24.1467", wantBin: filepath.Join(base, "8.3.24.1467", "ibcmd")},
+		{name: "pinned version not installed", version: "8.3.25.1257", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCreator := onectest.NewMockTempDatabaseCreator()
+			h := &CreateTempDbHandler{dbCreator: mockCreator}
+			cfg := &config.Config{
+				TmpDir:         t.TempDir(),
+				AppConfig:      &config.AppConfig{},
+				PlatformConfig: &config.PlatformConfig{BasePath: base, Version: tt.version},
+			}
+			t.Setenv("BR_EXTENSIONS", "")
+			t.Setenv("BR_TTL_HOURS", "")
+			t.Setenv("BR_OUTPUT_FORMAT", "")
+			t.Setenv("BR_SHOW_PROGRESS", "false")
+
+			err := h.Execute(context.Background(), cfg)
+
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), ErrCreateTempDbValidation) {
+					t.Fatalf("Execute() error = %v, want %s", err, ErrCreateTempDbValidation)
+				}
+				if mockCreator.CreateTempDBCallCount != 0 {
+					t.Error("CreateTempDB не должен вызываться без подходящей версии платформы")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute() error = %v, want nil", err)
+			}
+			if got := mockCreator.LastCreateTempDBOptions.BinIbcmd; got != tt.wantBin {
+				t.Errorf("BinIbcmd = %q, want %q", got, tt.wantBin)
+			}
+		})
+	}
+}
+
 func TestCreateTempDbHandler_Execute_Success_WithExtensions(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -46,6 +46,7 @@ func (h *DbRestoreHandler) buildPlan(cfg *config.Config, srcDB, srcServer, dstSe
 				"src_db":       srcDB,
 				"dst_server":   dstServer,
 				"dst_db":       cfg.InfobaseName,
+				"strategy":     restoreStrategy(cfg),
 				"timeout":      timeout.String(),
 				"auto_timeout": h.getDryRunAutoTimeoutInfo(cfg),
 			},
@@ -56,6 +57,36 @@ func (h *DbRestoreHandler) buildPlan(cfg *config.Config, srcDB, srcServer, dstSe
 		},
 	}
 
+	// Обезличивание выполняется после восстановления, если для базы заданы профили
+	if sanitize := sanitizeOptions(cfg); len(sanitize.Profiles) > 0 {
+		profiles := make([]string, 0, len(sanitize.Profiles))
+		var changes []string
+		for _, p := range sanitize.Profiles {
+			profiles = append(profiles, p.Name)
+			for _, m := range p.Masks {
+				changes = append(changes, fmt.Sprintf("Маскирование %s.%s", m.Object, m.Attribute))
+			}
+			for _, plan := range p.ExchangePlans {
+				changes = append(changes, "Очистка очереди изменений плана обмена "+plan)
+			}
+			if p.DisableScheduledJobs {
+				changes = append(changes, "Отключение регламентных заданий")
+			}
+			for _, stmt := range p.SQL {
+				changes = append(changes, "SQL: "+stmt)
+			}
+		}
+		steps = append(steps, output.PlanStep{
+			Order:     len(steps) + 1,
+			Operation: "Обезличивание данных",
+			Parameters: map[string]any{
+				"database": cfg.InfobaseName,
+				"profiles": profiles,
+			},
+			ExpectedChanges: changes,
+		})
+	}
+
 	return dryrun.BuildPlanWithSummary(
 		constants.ActNRDbrestore,
 		steps,
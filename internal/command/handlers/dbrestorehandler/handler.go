@@ -30,6 +30,7 @@ const (
 	ErrDbRestoreStatsFailed         = "DBRESTORE.STATS_FAILED"
 	ErrDbRestoreRestoreFailed       = "DBRESTORE.RESTORE_FAILED"
 	ErrDbRestoreServerNotFound      = "DBRESTORE.SERVER_NOT_FOUND"
+	ErrDbRestoreSanitizeFailed      = "DBRESTORE.SANITIZE_FAILED"
 
 	// minTimeout — минимальный таймаут если статистика пуста
 	minTimeout = 5 * time.Minute
@@ -60,6 +61,24 @@ type DbRestoreData struct {
 	TimeoutMs int64 `json:"timeout_ms"`
 	// AutoTimeout — был ли таймаут рассчитан автоматически
 	AutoTimeout bool `json:"auto_timeout"`
+	// Sanitize — действия обезличивания данных (project.yaml, секция sanitize)
+	Sanitize []SanitizeActionData `json:"sanitize,omitempty"`
+}
+
+// SanitizeActionData описывает выполненное действие обезличивания.
+type SanitizeActionData struct {
+	// Profile — профиль обезличивания
+	Profile string `json:"profile"`
+	// Action — вид действия: mask, exchange_queue, scheduled_jobs, sql
+	Action string `json:"action"`
+	// Target — реквизит, план обмена или инструкция
+	Target string `json:"target"`
+	// Table — изменённые таблицы
+	Table string `json:"table,omitempty"`
+	// Column — изменённая колонка
+	Column string `json:"column,omitempty"`
+	// Rows — число изменённых строк
+	Rows int64 `json:"rows"`
 }
 
 // writeText выводит результат восстановления в человекочитаемом формате.
@@ -83,13 +102,33 @@ func (d *DbRestoreData) writeText(w io.Writer) error {
 		duration.Round(time.Millisecond),
 		timeout.Round(time.Second),
 		autoTimeoutText)
-	return err
+	if err != nil || len(d.Sanitize) == 0 {
+		return err
+	}
+
+	if _, err = fmt.Fprintln(w, "Обезличивание:"); err != nil {
+		return err
+	}
+	for _, a := range d.Sanitize {
+		target := a.Target
+		if a.Table != "" {
+			target += " (" + a.Table + ")"
+		}
+		if _, err = fmt.Fprintf(w, "  [%s] %s %s: строк %d\n", a.Profile, a.Action, target, a.Rows); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // DbRestoreHandler обрабатывает команду nr-dbrestore.
 type DbRestoreHandler struct {
 	// mssqlClient — опциональный MSSQL клиент (nil в production, mock в тестах)
 	mssqlClient mssql.Client
+	// progressClient — опциональный клиент опроса хода RESTORE (nil в production, mock в тестах)
+	progressClient mssql.Client
+	// progressPollInterval — интервал опроса хода RESTORE (0 — restoreProgressPollInterval)
+	progressPollInterval time.Duration
 	// verbosePlan — план операций для verbose режима (Story 7.3), добавляется в JSON результат
 	verbosePlan *output.DryRunPlan
 }
@@ -193,6 +232,16 @@ func (h *DbRestoreHandler) Execute(ctx context.Context, cfg *config.Config) erro
 		}
 	}
 
+	// Профили обезличивания проверяются до восстановления: без них нельзя оставлять базу с боевыми данными
+	sanitizeOpts := sanitizeOptions(cfg)
+	sanitizer, canSanitize := mssqlClient.(mssql.DataSanitizer)
+	if len(sanitizeOpts.Profiles) > 0 && !canSanitize {
+		log.Error("MSSQL клиент не поддерживает обезличивание данных")
+		return h.writeError(format, traceID, start,
+			ErrDbRestoreSanitizeFailed,
+			"MSSQL клиент не поддерживает обезличивание данных, заданное в project.yaml")
+	}
+
 	// Подключение к серверу
 	if err := mssqlClient.Connect(ctx); err != nil {
 		log.Error("Не удалось подключиться к MSSQL", slog.String("error", err.Error()))
@@ -241,15 +290,22 @@ func (h *DbRestoreHandler) Execute(ctx context.Context, cfg *config.Config) erro
 	log.Info("Начало восстановления базы данных",
 		slog.String("time_to_restore", restoreOpts.TimeToRestore))
 
+	// Реальный ход RESTORE опрашивается через отдельное соединение с целевым сервером;
+	// если опрос недоступен — progress ведётся по оценке из статистики
+	tracker := h.openProgressTracker(ctx, cfg, dstServer, log)
+	if tracker != nil {
+		defer tracker.close(log)
+	}
+
 	// Создаём progress для отображения прогресса восстановления
 	// AC-1: показываем progress только для операций > 30 секунд (timeout — приблизительная оценка)
 	// AC-7: BR_SHOW_PROGRESS=false отключает progress
 	// Task 7.4: если stats недоступна — используем SpinnerProgress (Total=0)
 	// Используем estimatedDuration (реальная оценка) вместо timeout (максимум)
-	prog := h.createProgress(timeout, hasStats, estimatedDuration)
+	prog := h.createProgress(timeout, hasStats, estimatedDuration, tracker != nil)
 	prog.Start("Восстановление базы данных...")
 
-	// Запускаем горутину для обновления progress каждую секунду
+	// Запускаем горутину для обновления progress: опрос сервера или тик каждую секунду
 	// C-2 fix: используем atomic flag для предотвращения race condition
 	// между close(done) и последним вызовом prog.Update()
 	var wg sync.WaitGroup
@@ -258,6 +314,10 @@ func (h *DbRestoreHandler) Execute(ctx context.Context, cfg *config.Config) erro
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
+		if tracker != nil {
+			tracker.run(ctx, done, &stopped, prog, log)
+			return
+		}
 		ticker := time.NewTicker(time.Second)
 		defer ticker.Stop()
 		var elapsed int64
@@ -294,6 +354,29 @@ func (h *DbRestoreHandler) Execute(ctx context.Context, cfg *config.Config) erro
 
 	prog.Finish()
 
+	// Обезличивание восстановленных данных (project.yaml, секция sanitize)
+	var sanitizeData []SanitizeActionData
+	if len(sanitizeOpts.Profiles) > 0 {
+		log.Info("Обезличивание данных", slog.Int("profiles", len(sanitizeOpts.Profiles)))
+		actions, sanitizeErr := sanitizer.Sanitize(ctx, sanitizeOpts)
+		sanitizeData = toSanitizeData(actions)
+		if sanitizeErr != nil {
+			log.Error("Ошибка обезличивания данных",
+				slog.String("error", sanitizeErr.Error()),
+				slog.Int("actions_done", len(actions)))
+			return h.writeError(format, traceID, start,
+				ErrDbRestoreSanitizeFailed,
+				fmt.Sprintf("База восстановлена, но обезличивание не завершено (выполнено действий: %d): %v", len(actions), sanitizeErr))
+		}
+		for _, a := range sanitizeData {
+			log.Info("Действие обезличивания",
+				slog.String("profile", a.Profile),
+				slog.String("action", a.Action),
+				slog.String("target", a.Target),
+				slog.Int64("rows", a.Rows))
+		}
+	}
+
 	duration := time.Since(start)
 	log.Info("Восстановление завершено успешно",
 		slog.Duration("duration", duration))
@@ -307,6 +390,7 @@ func (h *DbRestoreHandler) Execute(ctx context.Context, cfg *config.Config) erro
 		DurationMs:  duration.Milliseconds(),
 		TimeoutMs:   timeout.Milliseconds(),
 		AutoTimeout: autoTimeout,
+		Sanitize:    sanitizeData,
 	}
 
 	// Текстовый формат
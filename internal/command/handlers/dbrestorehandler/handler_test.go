@@ -999,6 +999,9 @@ func TestDbRestoreHandler_DryRun_PlanContainsCorrectData(t *testing.T) {
 		if step3.Parameters["dst_db"] != "TestDB" {
 			t.Errorf("dst_db = %v, want %v", step3.Parameters["dst_db"], "TestDB")
 		}
+		if step3.Parameters["strategy"] != "procedure" {
+			t.Errorf("strategy = %v, want %v", step3.Parameters["strategy"], "procedure")
+		}
 		// AC-2: проверяем ожидаемые изменения
 		if len(step3.ExpectedChanges) == 0 {
 			t.Error("Step 3 ExpectedChanges should not be empty")
@@ -1399,3 +1402,165 @@ func createTestConfig(dbName string) *config.Config {
 	}
 }
 
+
+// TestRestoreStrategy проверяет выбор способа восстановления из implementations.db_restore
+func TestRestoreStrategy(t *testing.T) {
+	cfg := createTestConfig("TestDB")
+	if got := restoreStrategy(cfg); got != mssql.RestoreStrategyProcedure {
+		t.Errorf("restoreStrategy() = %q, want %q", got, mssql.RestoreStrategyProcedure)
+	}
+
+	cfg.ImplementationsConfig = &config.ImplementationsConfig{DBRestore: "native"}
+	if got := restoreStrategy(cfg); got != mssql.RestoreStrategyNative {
+		t.Errorf("restoreStrategy() = %q, want %q", got, mssql.RestoreStrategyNative)
+	}
+}
+
+// withSanitize добавляет в конфигурацию профили обезличивания.
+func withSanitize(cfg *config.Config) *config.Config {
+	cfg.ProjectConfig.Sanitize = &config.SanitizeConfig{Profiles: []config.SanitizeProfile{
+		{
+			Name:                 "personal-data",
+			Mask:                 []config.SanitizeMask{{Object: "Справочник.Контрагенты", Attribute: "Телефон", Value: "+7 000 000-00-00"}},
+			ExchangePlans:        []string{"*"},
+			DisableScheduledJobs: true,
+		},
+		{Name: "other-db", Databases: []string{"OtherDB"}, SQL: []string{"DELETE FROM _InfoRg1"}},
+	}}
+	return cfg
+}
+
+// TestDbRestoreHandler_Execute_Sanitize проверяет обезличивание после восстановления
+func TestDbRestoreHandler_Execute_Sanitize(t *testing.T) {
+	var restored bool
+	var captured mssql.SanitizeOptions
+	mockClient := &mssqltest.MockMSSQLClient{
+		RestoreFunc: func(context.Context, mssql.RestoreOptions) error {
+			restored = true
+			return nil
+		},
+		SanitizeFunc: func(_ context.Context, opts mssql.SanitizeOptions) ([]mssql.SanitizeAction, error) {
+			if !restored {
+				t.Error("Sanitize() должен вызываться после Restore()")
+			}
+			captured = opts
+			return []mssql.SanitizeAction{
+				{Profile: "personal-data", Action: mssql.SanitizeActionMask, Target: "Справочник.Контрагенты.Телефон", Table: "_Reference42", Column: "_Fld101", Rows: 120},
+				{Profile: "personal-data", Action: mssql.SanitizeActionScheduledJobs, Target: "регламентные задания", Rows: 17},
+			}, nil
+		},
+	}
+
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+	h := &DbRestoreHandler{mssqlClient: mockClient}
+
+	var err error
+	out := captureStdout(func() {
+		err = h.Execute(context.Background(), withSanitize(createTestConfig("TestDB")))
+	})
+	if err != nil {
+		t.Fatalf("Execute() unexpected error = %v", err)
+	}
+
+	if captured.Database != "TestDB" {
+		t.Errorf("SanitizeOptions.Database = %q, want %q", captured.Database, "TestDB")
+	}
+	if len(captured.Profiles) != 1 || captured.Profiles[0].Name != "personal-data" {
+		t.Fatalf("SanitizeOptions.Profiles = %+v, want only personal-data", captured.Profiles)
+	}
+	if p := captured.Profiles[0]; len(p.Masks) != 1 || p.Masks[0].Value != "+7 000 000-00-00" || !p.DisableScheduledJobs {
+		t.Errorf("SanitizeOptions.Profiles[0] = %+v", p)
+	}
+
+	var result struct {
+		Data DbRestoreData `json:"data"`
+	}
+	if jsonErr := json.Unmarshal([]byte(out), &result); jsonErr != nil {
+		t.Fatalf("JSON unmarshal error: %v, output: %s", jsonErr, out)
+	}
+	if len(result.Data.Sanitize) != 2 {
+		t.Fatalf("data.sanitize = %+v, want 2 actions", result.Data.Sanitize)
+	}
+	if a := result.Data.Sanitize[0]; a.Action != "mask" || a.Column != "_Fld101" || a.Rows != 120 {
+		t.Errorf("data.sanitize[0] = %+v", a)
+	}
+}
+
+// TestDbRestoreHandler_Execute_SanitizeError проверяет ошибку обезличивания
+func TestDbRestoreHandler_Execute_SanitizeError(t *testing.T) {
+	mockClient := &mssqltest.MockMSSQLClient{
+		SanitizeFunc: func(context.Context, mssql.SanitizeOptions) ([]mssql.SanitizeAction, error) {
+			return []mssql.SanitizeAction{{Action: mssql.SanitizeActionExchangeQueue}}, errors.New("объект Партнеры не найден")
+		},
+	}
+
+	h := &DbRestoreHandler{mssqlClient: mockClient}
+
+	var err error
+	captureStdout(func() {
+		err = h.Execute(context.Background(), withSanitize(createTestConfig("TestDB")))
+	})
+	if err == nil {
+		t.Fatal("Execute() should return error on sanitize failure")
+	}
+	for _, part := range []string{ErrDbRestoreSanitizeFailed, "выполнено действий: 1", "объект Партнеры не найден"} {
+		if !strings.Contains(err.Error(), part) {
+			t.Errorf("Execute() error = %v, want %q", err, part)
+		}
+	}
+}
+
+// TestDbRestoreHandler_DryRun_SanitizeStep проверяет шаг обезличивания в плане
+func TestDbRestoreHandler_DryRun_SanitizeStep(t *testing.T) {
+	t.Setenv("BR_DRY_RUN", "true")
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+
+	h := &DbRestoreHandler{mssqlClient: &FailOnCallMock{t: t}}
+
+	var err error
+	out := captureStdout(func() {
+		err = h.Execute(context.Background(), withSanitize(createTestConfig("TestDB")))
+	})
+	if err != nil {
+		t.Fatalf("DryRun Execute() unexpected error = %v", err)
+	}
+
+	var result output.Result
+	if jsonErr := json.Unmarshal([]byte(out), &result); jsonErr != nil {
+		t.Fatalf("JSON unmarshal error: %v", jsonErr)
+	}
+	if result.Plan == nil || len(result.Plan.Steps) != 4 {
+		t.Fatalf("Plan should have 4 steps, got %+v", result.Plan)
+	}
+	step := result.Plan.Steps[3]
+	if step.Operation != "Обезличивание данных" {
+		t.Errorf("Step 4 operation = %q", step.Operation)
+	}
+	want := []string{
+		"Маскирование Справочник.Контрагенты.Телефон",
+		"Очистка очереди изменений плана обмена *",
+		"Отключение регламентных заданий",
+	}
+	if strings.Join(step.ExpectedChanges, "|") != strings.Join(want, "|") {
+		t.Errorf("Step 4 changes = %v, want %v", step.ExpectedChanges, want)
+	}
+}
+
+// TestDbRestoreData_writeText_Sanitize проверяет вывод действий обезличивания
+func TestDbRestoreData_writeText_Sanitize(t *testing.T) {
+	data := &DbRestoreData{
+		SrcServer: "src-server", SrcDB: "SrcDB", DstServer: "dst-server", DstDB: "DstDB",
+		Sanitize: []SanitizeActionData{
+			{Profile: "personal-data", Action: "mask", Target: "Справочник.Контрагенты.Телефон", Table: "_Reference42", Rows: 120},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := data.writeText(&buf); err != nil {
+		t.Fatalf("writeText() error = %v", err)
+	}
+	want := "  [personal-data] mask Справочник.Контрагенты.Телефон (_Reference42): строк 120\n"
+	if !strings.Contains(buf.String(), "Обезличивание:\n"+want) {
+		t.Errorf("writeText() output = %s", buf.String())
+	}
+}
@@ -122,26 +122,72 @@ func (h *DbRestoreHandler) createMSSQLClient(cfg *config.Config, dstServer strin
 	}
 
 	opts := mssql.ClientOptions{
-		Server:   server,
-		Port:     1433,
-		User:     user,
-		Password: password,
-		Database: database,
-		Timeout:  timeout,
+		Server:          server,
+		Port:            1433,
+		User:            user,
+		Password:        password,
+		Database:        database,
+		Timeout:         timeout,
+		RestoreStrategy: restoreStrategy(cfg),
 	}
 
 	return mssql.NewClient(opts)
 }
 
+// restoreStrategy возвращает способ восстановления из implementations.db_restore.
+func restoreStrategy(cfg *config.Config) string {
+	if cfg.ImplementationsConfig != nil && cfg.ImplementationsConfig.DBRestore != "" {
+		return cfg.ImplementationsConfig.DBRestore
+	}
+	return mssql.RestoreStrategyProcedure
+}
+
+// sanitizeOptions собирает профили обезличивания целевой базы из project.yaml.
+func sanitizeOptions(cfg *config.Config) mssql.SanitizeOptions {
+	opts := mssql.SanitizeOptions{Database: cfg.InfobaseName}
+	if cfg.ProjectConfig == nil {
+		return opts
+	}
+	for _, p := range cfg.ProjectConfig.Sanitize.ProfilesFor(cfg.InfobaseName) {
+		profile := mssql.SanitizeProfile{
+			Name:                 p.Name,
+			ExchangePlans:        p.ExchangePlans,
+			DisableScheduledJobs: p.DisableScheduledJobs,
+			SQL:                  p.SQL,
+		}
+		for _, m := range p.Mask {
+			profile.Masks = append(profile.Masks, mssql.SanitizeMask{Object: m.Object, Attribute: m.Attribute, Value: m.Value})
+		}
+		opts.Profiles = append(opts.Profiles, profile)
+	}
+	return opts
+}
+
+// toSanitizeData преобразует действия обезличивания в данные ответа.
+func toSanitizeData(actions []mssql.SanitizeAction) []SanitizeActionData {
+	data := make([]SanitizeActionData, 0, len(actions))
+	for _, a := range actions {
+		data = append(data, SanitizeActionData{
+			Profile: a.Profile,
+			Action:  a.Action,
+			Target:  a.Target,
+			Table:   a.Table,
+			Column:  a.Column,
+			Rows:    a.Rows,
+		})
+	}
+	return data
+}
+
 // progressMinDuration — минимальная ожидаемая длительность для показа progress bar (AC-1).
 const progressMinDuration = 30 * time.Second
 
 // createProgress создаёт progress bar для отображения прогресса восстановления.
 // timeout используется для проверки порога AC-1 (30 секунд).
 // hasStats указывает, доступна ли статистика для оценки длительности (Task 7.4).
-// createProgress создаёт progress bar для отображения прогресса восстановления.
 // estimatedDuration — реальная оценка времени выполнения (не timeout с множителем).
-func (h *DbRestoreHandler) createProgress(timeout time.Duration, hasStats bool, estimatedDuration time.Duration) progress.Progress {
+// live — ход восстановления опрашивается на сервере, Total = restoreProgressScale.
+func (h *DbRestoreHandler) createProgress(timeout time.Duration, hasStats bool, estimatedDuration time.Duration, live bool) progress.Progress {
 	// AC-1: показываем progress только для операций > 30 секунд
 	// Если ожидаемое время меньше порога — возвращаем NoopProgress
 	if timeout < progressMinDuration {
@@ -150,7 +196,10 @@ func (h *DbRestoreHandler) createProgress(timeout time.Duration, hasStats bool,
 
 	// Task 7.4: если статистика недоступна — используем SpinnerProgress (Total=0)
 	var total int64
-	if hasStats && estimatedDuration > 0 {
+	if live {
+		// Сервер сообщает процент выполнения — статистика для Total не нужна
+		total = restoreProgressScale
+	} else if hasStats && estimatedDuration > 0 {
 		// Total — реальная оценка времени восстановления (не timeout с множителем)
 		total = estimatedDuration.Milliseconds()
 	}
@@ -0,0 +1,158 @@
+package dbrestorehandler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/Kargones/apk-ci/internal/adapter/mssql"
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/pkg/progress"
+)
+
+const (
+	// restoreProgressScale — Total progress при опросе сервера: проценты с точностью до десятых
+	restoreProgressScale = 1000
+	// restoreProgressPollInterval — интервал опроса sys.dm_exec_requests по умолчанию
+	restoreProgressPollInterval = 5 * time.Second
+	// restoreProgressLogInterval — интервал записи хода восстановления в лог
+	restoreProgressLogInterval = time.Minute
+	// restoreFinalPhasePercent — порог, после которого RESTORE обычно долго
+	// выполняет redo/undo и percent_complete почти не меняется
+	restoreFinalPhasePercent = 99
+)
+
+// progressTracker опрашивает ход RESTORE на целевом сервере через отдельное соединение:
+// основное соединение занято самой командой восстановления.
+type progressTracker struct {
+	client   mssql.Client
+	provider mssql.RestoreProgressProvider
+	database string
+	interval time.Duration
+}
+
+// openProgressTracker подключает второй клиент к целевому серверу и проверяет,
+// что сервер отдаёт ход RESTORE (нужно право VIEW SERVER STATE).
+// Возвращает nil, если опрос недоступен — тогда progress ведётся по оценке из статистики.
+func (h *DbRestoreHandler) openProgressTracker(ctx context.Context, cfg *config.Config, dstServer string, log *slog.Logger) *progressTracker {
+	client := h.progressClient
+	if client == nil {
+		// Подменённый в тестах mssqlClient без progressClient — опрос не используется
+		if h.mssqlClient != nil {
+			return nil
+		}
+		var err error
+		client, err = h.createMSSQLClient(cfg, dstServer)
+		if err != nil {
+			log.Warn("Не удалось создать клиент для опроса хода восстановления", slog.String("error", err.Error()))
+			return nil
+		}
+	}
+
+	provider, ok := client.(mssql.RestoreProgressProvider)
+	if !ok {
+		return nil
+	}
+
+	if err := client.Connect(ctx); err != nil {
+		log.Warn("Не удалось подключиться для опроса хода восстановления, используется оценка по статистике",
+			slog.String("error", err.Error()))
+		return nil
+	}
+
+	tracker := &progressTracker{
+		client:   client,
+		provider: provider,
+		database: cfg.InfobaseName,
+		interval: h.progressPollInterval,
+	}
+	if tracker.interval <= 0 {
+		tracker.interval = restoreProgressPollInterval
+	}
+
+	// Пробный запрос до начала восстановления: ошибка здесь означает,
+	// что опрос не будет работать (например, нет VIEW SERVER STATE)
+	if _, err := provider.GetRestoreProgress(ctx, tracker.database); err != nil {
+		log.Warn("Ход восстановления недоступен, используется оценка по статистике",
+			slog.String("error", err.Error()))
+		tracker.close(log)
+		return nil
+	}
+
+	return tracker
+}
+
+// close закрывает соединение опроса.
+func (t *progressTracker) close(log *slog.Logger) {
+	if err := t.client.Close(); err != nil {
+		log.Warn("Ошибка закрытия соединения опроса хода восстановления", slog.String("error", err.Error()))
+	}
+}
+
+// run опрашивает сервер до закрытия done и передаёт percent_complete
+// и estimated_completion_time в prog (Total = restoreProgressScale).
+func (t *progressTracker) run(ctx context.Context, done <-chan struct{}, stopped *atomic.Bool, prog progress.Progress, log *slog.Logger) {
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	var (
+		lastCommand string
+		lastLog     time.Time
+		pollFailed  bool
+	)
+	for {
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			rp, err := t.provider.GetRestoreProgress(ctx, t.database)
+			if stopped.Load() {
+				return
+			}
+			if err != nil {
+				// Одиночные сбои опроса не влияют на восстановление — предупреждаем один раз
+				if !pollFailed {
+					log.Warn("Ошибка опроса хода восстановления", slog.String("error", err.Error()))
+					pollFailed = true
+				}
+				continue
+			}
+			if rp == nil {
+				// RESTORE ещё не начался или идёт переход между шагами цепочки
+				continue
+			}
+
+			if rp.PercentComplete > 0 {
+				progress.SetETA(prog, rp.EstimatedRemaining)
+			}
+			prog.Update(int64(rp.PercentComplete*restoreProgressScale/100), restoreProgressMessage(rp))
+
+			if rp.Command != lastCommand || time.Since(lastLog) >= restoreProgressLogInterval {
+				lastCommand = rp.Command
+				lastLog = time.Now()
+				log.Info("Ход восстановления",
+					slog.String("restore_command", rp.Command),
+					slog.Float64("percent", rp.PercentComplete),
+					slog.Duration("elapsed", rp.Elapsed.Round(time.Second)),
+					slog.Duration("eta", rp.EstimatedRemaining.Round(time.Second)),
+					slog.String("wait_type", rp.WaitType))
+			}
+		}
+	}
+}
+
+// restoreProgressMessage формирует сообщение progress по состоянию RESTORE.
+func restoreProgressMessage(rp *mssql.RestoreProgress) string {
+	msg := fmt.Sprintf("%s: %.1f%%", rp.Command, rp.PercentComplete)
+	if rp.WaitType != "" {
+		msg += ", ожидание " + rp.WaitType
+	}
+	if rp.PercentComplete >= restoreFinalPhasePercent {
+		msg += " — завершающая фаза (redo/undo), сервер продолжает работу"
+	}
+	return msg
+}
@@ -0,0 +1,236 @@
+package dbrestorehandler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Kargones/apk-ci/internal/adapter/mssql"
+	"github.com/Kargones/apk-ci/internal/adapter/mssql/mssqltest"
+	"github.com/Kargones/apk-ci/internal/pkg/progress"
+)
+
+// captureStderr перехватывает stderr (вывод progress) во время выполнения функции
+func captureStderr(fn func()) string {
+	oldStderr := os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stderr = w
+
+	fn()
+
+	_ = w.Close()
+	os.Stderr = oldStderr
+
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+	return buf.String()
+}
+
+// TestDbRestoreHandler_Execute_LiveProgress проверяет JSON streaming хода RESTORE,
+// полученного опросом сервера через отдельное соединение
+func TestDbRestoreHandler_Execute_LiveProgress(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+	t.Setenv("BR_PROGRESS_STREAM", "true")
+	t.Setenv("BR_TIMEOUT_MIN", "600")
+
+	// Восстановление завершается после двух опросов: первый опрос гарантированно обновил progress
+	var polls atomic.Int32
+	polled := make(chan struct{})
+	var polledDB string
+	var progressClosed bool
+	progressClient := &mssqltest.MockMSSQLClient{
+		GetRestoreProgressFunc: func(_ context.Context, database string) (*mssql.RestoreProgress, error) {
+			polledDB = database
+			n := polls.Add(1)
+			if n == 1 {
+				return nil, nil // пробный запрос до начала восстановления
+			}
+			if n == 3 {
+				close(polled)
+			}
+			return &mssql.RestoreProgress{
+				Command:            "RESTORE DATABASE",
+				PercentComplete:    99.5,
+				EstimatedRemaining: time.Hour,
+				Elapsed:            5 * time.Hour,
+			}, nil
+		},
+		CloseFunc: func() error {
+			progressClosed = true
+			return nil
+		},
+	}
+	mockClient := &mssqltest.MockMSSQLClient{
+		RestoreFunc: func(ctx context.Context, _ mssql.RestoreOptions) error {
+			select {
+			case <-polled:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	}
+
+	h := &DbRestoreHandler{
+		mssqlClient:          mockClient,
+		progressClient:       progressClient,
+		progressPollInterval: 5 * time.Millisecond,
+	}
+
+	var err error
+	stderr := captureStderr(func() {
+		captureStdout(func() {
+			err = h.Execute(context.Background(), createTestConfig("TestDB"))
+		})
+	})
+	if err != nil {
+		t.Fatalf("Execute() unexpected error = %v", err)
+	}
+
+	if polledDB != "TestDB" {
+		t.Errorf("GetRestoreProgress() database = %q, want %q", polledDB, "TestDB")
+	}
+	if !progressClosed {
+		t.Error("соединение опроса должно быть закрыто")
+	}
+
+	var found bool
+	scanner := bufio.NewScanner(strings.NewReader(stderr))
+	for scanner.Scan() {
+		var event progress.Event
+		if jsonErr := json.Unmarshal(scanner.Bytes(), &event); jsonErr != nil {
+			t.Fatalf("progress event unmarshal error: %v, line: %s", jsonErr, scanner.Text())
+		}
+		if event.Type != "progress" {
+			continue
+		}
+		found = true
+		if event.Percent == nil || *event.Percent != 99 {
+			t.Errorf("event.Percent = %v, want 99", event.Percent)
+		}
+		if event.ETASeconds == nil || *event.ETASeconds != 3600 {
+			t.Errorf("event.ETASeconds = %v, want 3600", event.ETASeconds)
+		}
+		if !strings.Contains(event.Message, "завершающая фаза") {
+			t.Errorf("event.Message = %q, want final phase note", event.Message)
+		}
+	}
+	if !found {
+		t.Errorf("нет события progress в выводе: %s", stderr)
+	}
+}
+
+// TestDbRestoreHandler_Execute_LiveProgressUnavailable проверяет, что без права
+// VIEW SERVER STATE восстановление выполняется с оценкой по статистике
+func TestDbRestoreHandler_Execute_LiveProgressUnavailable(t *testing.T) {
+	var polls int
+	var progressClosed bool
+	progressClient := &mssqltest.MockMSSQLClient{
+		GetRestoreProgressFunc: func(context.Context, string) (*mssql.RestoreProgress, error) {
+			polls++
+			return nil, errors.New("VIEW SERVER STATE permission was denied")
+		},
+		CloseFunc: func() error {
+			progressClosed = true
+			return nil
+		},
+	}
+	var restored bool
+	mockClient := &mssqltest.MockMSSQLClient{
+		RestoreFunc: func(context.Context, mssql.RestoreOptions) error {
+			restored = true
+			return nil
+		},
+	}
+
+	h := &DbRestoreHandler{
+		mssqlClient:          mockClient,
+		progressClient:       progressClient,
+		progressPollInterval: time.Millisecond,
+	}
+
+	var err error
+	captureStdout(func() {
+		err = h.Execute(context.Background(), createTestConfig("TestDB"))
+	})
+	if err != nil {
+		t.Fatalf("Execute() unexpected error = %v", err)
+	}
+
+	if !restored {
+		t.Error("Restore() должен быть вызван")
+	}
+	if polls != 1 {
+		t.Errorf("GetRestoreProgress() calls = %d, want 1 (только пробный запрос)", polls)
+	}
+	if !progressClosed {
+		t.Error("соединение опроса должно быть закрыто")
+	}
+}
+
+// TestDbRestoreHandler_Execute_LiveProgressConnectError проверяет, что ошибка
+// подключения для опроса не прерывает восстановление
+func TestDbRestoreHandler_Execute_LiveProgressConnectError(t *testing.T) {
+	progressClient := &mssqltest.MockMSSQLClient{
+		ConnectFunc: func(context.Context) error {
+			return errors.New("login failed")
+		},
+		GetRestoreProgressFunc: func(context.Context, string) (*mssql.RestoreProgress, error) {
+			t.Error("GetRestoreProgress() не должен вызываться без соединения")
+			return nil, nil
+		},
+	}
+
+	h := &DbRestoreHandler{
+		mssqlClient:    mssqltest.NewMockMSSQLClient(),
+		progressClient: progressClient,
+	}
+
+	var err error
+	captureStdout(func() {
+		err = h.Execute(context.Background(), createTestConfig("TestDB"))
+	})
+	if err != nil {
+		t.Fatalf("Execute() unexpected error = %v", err)
+	}
+}
+
+// TestRestoreProgressMessage проверяет сообщение progress по состоянию RESTORE
+func TestRestoreProgressMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		rp   mssql.RestoreProgress
+		want string
+	}{
+		{
+			name: "копирование данных",
+			rp:   mssql.RestoreProgress{Command: "RESTORE DATABASE", PercentComplete: 42.3},
+			want: "RESTORE DATABASE: 42.3%",
+		},
+		{
+			name: "ожидание ввода-вывода",
+			rp:   mssql.RestoreProgress{Command: "RESTORE LOG", PercentComplete: 10, WaitType: "BACKUPIO"},
+			want: "RESTORE LOG: 10.0%, ожидание BACKUPIO",
+		},
+		{
+			name: "завершающая фаза",
+			rp:   mssql.RestoreProgress{Command: "RESTORE DATABASE", PercentComplete: 99.9},
+			want: "RESTORE DATABASE: 99.9% — завершающая фаза (redo/undo), сервер продолжает работу",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := restoreProgressMessage(&tt.rp); got != tt.want {
+				t.Errorf("restoreProgressMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
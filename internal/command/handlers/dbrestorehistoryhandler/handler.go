@@ -0,0 +1,449 @@
+// Package dbrestorehistoryhandler реализует NR-команду nr-dbrestore-history
+// для просмотра истории восстановлений тестовых баз без доступа к СУБД:
+// кто, когда, откуда и куда восстанавливал базу, сколько длилось и чем завершилось.
+//
+// Источник истории — журнал запросов [DBA].[dbo].[BackupRequestJournal] серверов
+// тестовых баз. Для базы BR_INFOBASE_NAME дополнительно выводится последнее
+// восстановление по msdb, учитывающее и восстановление без журнала (implementations.db_restore: native).
+package dbrestorehistoryhandler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Kargones/apk-ci/internal/adapter/mssql"
+	"github.com/Kargones/apk-ci/internal/command"
+	"github.com/Kargones/apk-ci/internal/command/handlers/mssqlutil"
+	errhandler "github.com/Kargones/apk-ci/internal/command/handlers/shared"
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/pkg/dryrun"
+	"github.com/Kargones/apk-ci/internal/pkg/output"
+	"github.com/Kargones/apk-ci/internal/pkg/tracing"
+)
+
+// Итоги запроса на восстановление (RestoreRecordData.Outcome).
+const (
+	// OutcomeCompleted — восстановление завершено
+	OutcomeCompleted = "completed"
+	// OutcomeNotCompleted — время завершения не записано: восстановление выполняется или завершилось ошибкой
+	OutcomeNotCompleted = "not_completed"
+)
+
+// Коды ошибок nr-dbrestore-history.
+const (
+	// ErrHistoryQueryFailed — не удалось прочитать журнал ни на одном сервере
+	ErrHistoryQueryFailed = "DBRESTORE_HISTORY.QUERY_FAILED"
+)
+
+// mssqlTimeout — таймаут подключения к серверу СУБД.
+const mssqlTimeout = 15 * time.Second
+
+// Compile-time interface check.
+var _ command.Handler = (*DbRestoreHistoryHandler)(nil)
+
+func RegisterCmd() error {
+	return command.Register(&DbRestoreHistoryHandler{})
+}
+
+// RestoreRecordData — запрос на восстановление в ответе команды.
+type RestoreRecordData struct {
+	// RequestedAt — время запроса
+	RequestedAt time.Time `json:"requested_at"`
+	// CompletedAt — время завершения (nil — не завершено)
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	// DurationSec — длительность восстановления в секундах
+	DurationSec int64 `json:"duration_sec,omitempty"`
+	// Outcome — итог (completed, not_completed)
+	Outcome string `json:"outcome"`
+	// User — пользователь, запросивший восстановление
+	User string `json:"user"`
+	// Description — описание операции
+	Description string `json:"description,omitempty"`
+	// SrcServer — сервер-источник
+	SrcServer string `json:"src_server"`
+	// SrcDB — база-источник
+	SrcDB string `json:"src_db"`
+	// DstServer — целевой сервер
+	DstServer string `json:"dst_server"`
+	// DstDB — целевая база
+	DstDB string `json:"dst_db"`
+	// RestorePoint — запрошенный момент восстановления
+	RestorePoint string `json:"restore_point,omitempty"`
+}
+
+// LastRestoreData — последнее восстановление базы по msdb.
+type LastRestoreData struct {
+	// RestoredAt — время восстановления
+	RestoredAt time.Time `json:"restored_at"`
+	// DataAsOf — момент, на который восстановлены данные
+	DataAsOf *time.Time `json:"data_as_of,omitempty"`
+	// SrcServer — сервер-источник резервной копии
+	SrcServer string `json:"src_server"`
+	// SrcDB — база-источник
+	SrcDB string `json:"src_db"`
+}
+
+// DbRestoreHistoryData содержит данные ответа nr-dbrestore-history.
+type DbRestoreHistoryData struct {
+	// Database — фильтр по целевой базе (пусто — все тестовые базы)
+	Database string `json:"database,omitempty"`
+	// User — фильтр по пользователю
+	User string `json:"user,omitempty"`
+	// From — начало периода
+	From *time.Time `json:"from,omitempty"`
+	// To — окончание периода (не включая)
+	To *time.Time `json:"to,omitempty"`
+	// Servers — опрошенные серверы СУБД
+	Servers []string `json:"servers"`
+	// LastRestore — последнее восстановление базы Database (nil — не восстанавливалась или неизвестно)
+	LastRestore *LastRestoreData `json:"last_restore,omitempty"`
+	// Records — запросы на восстановление, новые первыми
+	Records []RestoreRecordData `json:"records"`
+	// Warnings — серверы, журнал которых прочитать не удалось
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// writeText выводит историю в человекочитаемом формате.
+func (d *DbRestoreHistoryData) writeText(w io.Writer) error {
+	scope := d.Database
+	if scope == "" {
+		scope = "все тестовые базы"
+	}
+	if _, err := fmt.Fprintf(w, "История восстановлений: %s (записей: %d)\n", scope, len(d.Records)); err != nil {
+		return err
+	}
+
+	if d.LastRestore != nil {
+		details := "источник " + d.LastRestore.SrcServer + "/" + d.LastRestore.SrcDB
+		if d.LastRestore.DataAsOf != nil {
+			details = "данные на " + formatTime(*d.LastRestore.DataAsOf) + ", " + details
+		}
+		if _, err := fmt.Fprintf(w, "Последнее восстановление: %s (%s)\n",
+			formatTime(d.LastRestore.RestoredAt), details); err != nil {
+			return err
+		}
+	}
+
+	for _, r := range d.Records {
+		result := "не завершено"
+		if r.Outcome == OutcomeCompleted {
+			result = "завершено за " + (time.Duration(r.DurationSec) * time.Second).String()
+		}
+		if _, err := fmt.Fprintf(w, "  %s  %s@%s → %s@%s  %s  %s\n",
+			formatTime(r.RequestedAt), r.SrcDB, r.SrcServer, r.DstDB, r.DstServer, r.User, result); err != nil {
+			return err
+		}
+	}
+
+	for _, warning := range d.Warnings {
+		if _, err := fmt.Fprintf(w, "⚠ %s\n", warning); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatTime форматирует время сервера для текстового вывода.
+func formatTime(t time.Time) string {
+	return t.Format("2006-01-02 15:04")
+}
+
+// DbRestoreHistoryHandler обрабатывает команду nr-dbrestore-history.
+type DbRestoreHistoryHandler struct {
+	// mssqlFactory — опциональная фабрика MSSQL клиента (nil в production, mock в тестах)
+	mssqlFactory func(opts mssql.ClientOptions) (mssql.Client, error)
+}
+
+// Name возвращает имя команды.
+func (h *DbRestoreHistoryHandler) Name() string {
+	return constants.ActNRDbrestoreHistory
+}
+
+// Description возвращает описание команды для вывода в help.
+func (h *DbRestoreHistoryHandler) Description() string {
+	return "История восстановлений тестовых баз: кто, когда, откуда и с каким итогом"
+}
+
+// Execute выполняет команду nr-dbrestore-history.
+func (h *DbRestoreHistoryHandler) Execute(ctx context.Context, cfg *config.Config) error {
+	start := time.Now()
+
+	traceID := tracing.TraceIDFromContext(ctx)
+	if traceID == "" {
+		traceID = tracing.GenerateTraceID()
+	}
+
+	format := os.Getenv("BR_OUTPUT_FORMAT")
+
+	// Plan-only не поддерживается; dry-run имеет приоритет над plan-only.
+	if !dryrun.IsDryRun() && dryrun.IsPlanOnly() {
+		return dryrun.WritePlanOnlyUnsupported(os.Stdout, constants.ActNRDbrestoreHistory)
+	}
+
+	log := slog.Default().With(slog.String("trace_id", traceID), slog.String("command", constants.ActNRDbrestoreHistory))
+
+	if cfg == nil {
+		return h.writeError(format, traceID, start, "CONFIG.MISSING", "Конфигурация не загружена")
+	}
+
+	loc := mssqlutil.ServerLocation()
+	opts := mssql.RestoreHistoryOptions{
+		DstDB: cfg.InfobaseName,
+		User:  strings.TrimSpace(os.Getenv(constants.EnvDbrestoreHistoryUser)),
+		Limit: mssql.DefaultRestoreHistoryLimit,
+	}
+	var err error
+	if opts.From, err = parseBound(os.Getenv(constants.EnvDbrestoreHistoryFrom), false, loc); err != nil {
+		return h.writeError(format, traceID, start, "CONFIG.INVALID_PARAM",
+			fmt.Sprintf("Недопустимое значение %s: %v", constants.EnvDbrestoreHistoryFrom, err))
+	}
+	if opts.To, err = parseBound(os.Getenv(constants.EnvDbrestoreHistoryTo), true, loc); err != nil {
+		return h.writeError(format, traceID, start, "CONFIG.INVALID_PARAM",
+			fmt.Sprintf("Недопустимое значение %s: %v", constants.EnvDbrestoreHistoryTo, err))
+	}
+	if v := os.Getenv(constants.EnvDbrestoreHistoryLimit); v != "" {
+		if opts.Limit, err = strconv.Atoi(v); err != nil || opts.Limit <= 0 {
+			return h.writeError(format, traceID, start, "CONFIG.INVALID_PARAM",
+				fmt.Sprintf("Недопустимое значение %s: %q", constants.EnvDbrestoreHistoryLimit, v))
+		}
+	}
+
+	servers, err := historyServers(cfg)
+	if err != nil {
+		return h.writeError(format, traceID, start, "CONFIG.DB_SERVER_MISSING", err.Error())
+	}
+
+	log.Info("Запрос истории восстановлений",
+		slog.String("database", opts.DstDB),
+		slog.String("user", opts.User),
+		slog.Any("servers", servers))
+
+	data := &DbRestoreHistoryData{
+		Database: opts.DstDB,
+		User:     opts.User,
+		Servers:  servers,
+		Records:  []RestoreRecordData{},
+	}
+	if !opts.From.IsZero() {
+		data.From = &opts.From
+	}
+	if !opts.To.IsZero() {
+		data.To = &opts.To
+	}
+
+	var records []mssql.RestoreRecord
+	failed := 0
+	for _, server := range servers {
+		serverRecords, last, err := h.queryServer(ctx, cfg, server, opts)
+		if err != nil {
+			log.Warn("Не удалось прочитать историю восстановлений", slog.String("server", server), slog.String("error", err.Error()))
+			data.Warnings = append(data.Warnings, fmt.Sprintf("%s: %v", server, err))
+			failed++
+			continue
+		}
+		records = append(records, serverRecords...)
+		if last != nil {
+			data.LastRestore = toLastRestoreData(last)
+		}
+	}
+	if failed == len(servers) {
+		return h.writeError(format, traceID, start, ErrHistoryQueryFailed,
+			"Не удалось прочитать историю восстановлений: "+strings.Join(data.Warnings, "; "))
+	}
+
+	// Журналы нескольких серверов объединяются: новые первыми, не более Limit
+	sort.SliceStable(records, func(i, j int) bool { return records[i].RequestedAt.After(records[j].RequestedAt) })
+	if len(records) > opts.Limit {
+		records = records[:opts.Limit]
+	}
+	for _, r := range records {
+		data.Records = append(data.Records, toRecordData(r))
+	}
+
+	log.Info("История восстановлений получена", slog.Int("records", len(data.Records)))
+	return h.writeSuccess(format, traceID, start, data)
+}
+
+// queryServer читает журнал сервера server и, если задана база, её последнее восстановление.
+func (h *DbRestoreHistoryHandler) queryServer(ctx context.Context, cfg *config.Config, server string,
+	opts mssql.RestoreHistoryOptions) ([]mssql.RestoreRecord, *mssql.LastRestore, error) {
+	newClient := h.mssqlFactory
+	if newClient == nil {
+		newClient = mssql.NewClient
+	}
+	client, err := newClient(mssqlutil.Options(cfg, server, mssqlTimeout))
+	if err != nil {
+		return nil, nil, err
+	}
+	history, ok := client.(mssql.RestoreHistoryProvider)
+	if !ok {
+		return nil, nil, fmt.Errorf("MSSQL клиент не поддерживает историю восстановлений")
+	}
+
+	if err := client.Connect(ctx); err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = client.Close() }() //nolint:errcheck // ошибка закрытия не влияет на результат чтения
+
+	records, err := history.GetRestoreHistory(ctx, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	if opts.DstDB == "" {
+		return records, nil, nil
+	}
+
+	// Последнее восстановление — дополнительная информация: ошибка не прерывает команду
+	last, err := history.GetLastRestore(ctx, opts.DstDB)
+	if err != nil {
+		slog.Default().Warn("Не удалось получить последнее восстановление из msdb",
+			slog.String("server", server), slog.String("error", err.Error()))
+		return records, nil, nil
+	}
+	if last != nil {
+		last.RestoredAt = mssqlutil.ServerTime(last.RestoredAt)
+		last.DataAsOf = mssqlutil.ServerTime(last.DataAsOf)
+	}
+	return records, last, nil
+}
+
+// historyServers возвращает серверы СУБД для чтения журнала: сервер базы
+// BR_INFOBASE_NAME либо все серверы тестовых баз из dbconfig.
+func historyServers(cfg *config.Config) ([]string, error) {
+	if cfg.InfobaseName != "" {
+		server := cfg.GetDbServer(cfg.InfobaseName)
+		if server == "" {
+			return nil, fmt.Errorf("не найден сервер MSSQL для базы '%s' в dbconfig", cfg.InfobaseName)
+		}
+		return []string{server}, nil
+	}
+
+	var servers []string
+	for _, db := range cfg.GetTestDatabases() {
+		if server := cfg.GetDbServer(db); server != "" && !slices.Contains(servers, server) {
+			servers = append(servers, server)
+		}
+	}
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("в dbconfig нет тестовых баз с сервером MSSQL")
+	}
+	sort.Strings(servers)
+	return servers, nil
+}
+
+// parseBound разбирает границу периода: дата (2006-01-02), дата и время
+// (2006-01-02 15:04) в часовом поясе серверов или RFC3339. Дата окончания
+// периода включается целиком.
+func parseBound(v string, end bool, loc *time.Location) (time.Time, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, v, loc); err == nil {
+		if end {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02 15:04", v, loc); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q: ожидается дата 2006-01-02, дата и время 2006-01-02 15:04 или RFC3339", v)
+	}
+	return t.In(loc), nil
+}
+
+// toRecordData преобразует запись журнала в данные ответа.
+func toRecordData(r mssql.RestoreRecord) RestoreRecordData {
+	d := RestoreRecordData{
+		RequestedAt:  mssqlutil.ServerTime(r.RequestedAt),
+		Outcome:      OutcomeNotCompleted,
+		User:         r.User,
+		Description:  r.Description,
+		SrcServer:    r.SrcServer,
+		SrcDB:        r.SrcDB,
+		DstServer:    r.DstServer,
+		DstDB:        r.DstDB,
+		RestorePoint: r.RestorePoint,
+	}
+	if !r.CompletedAt.IsZero() {
+		completed := mssqlutil.ServerTime(r.CompletedAt)
+		d.CompletedAt = &completed
+		d.DurationSec = int64(r.CompletedAt.Sub(r.RequestedAt).Seconds())
+		d.Outcome = OutcomeCompleted
+	}
+	return d
+}
+
+// toLastRestoreData преобразует последнее восстановление в данные ответа.
+func toLastRestoreData(last *mssql.LastRestore) *LastRestoreData {
+	d := &LastRestoreData{RestoredAt: last.RestoredAt, SrcServer: last.SrcServer, SrcDB: last.SrcDB}
+	if !last.DataAsOf.IsZero() {
+		dataAsOf := last.DataAsOf
+		d.DataAsOf = &dataAsOf
+	}
+	return d
+}
+
+// writeSuccess выводит успешный результат.
+func (h *DbRestoreHistoryHandler) writeSuccess(format, traceID string, start time.Time, data *DbRestoreHistoryData) error {
+	if format != output.FormatJSON {
+		return data.writeText(os.Stdout)
+	}
+
+	result := &output.Result{
+		Status:  output.StatusSuccess,
+		Command: constants.ActNRDbrestoreHistory,
+		Data:    data,
+		Metadata: &output.Metadata{
+			DurationMs: time.Since(start).Milliseconds(),
+			TraceID:    traceID,
+			APIVersion: constants.APIVersion,
+		},
+	}
+
+	writer := output.NewWriter(format)
+	return writer.Write(os.Stdout, result)
+}
+
+// writeError выводит структурированную ошибку и возвращает error.
+func (h *DbRestoreHistoryHandler) writeError(format, traceID string, start time.Time, code, message string) error {
+	// Текстовый формат — человекочитаемый вывод ошибки
+	if format != output.FormatJSON {
+		return errhandler.HandleError(message, code)
+	}
+
+	result := &output.Result{
+		Status:  output.StatusError,
+		Command: constants.ActNRDbrestoreHistory,
+		Error: &output.ErrorInfo{
+			Code:    code,
+			Message: message,
+		},
+		Metadata: &output.Metadata{
+			DurationMs: time.Since(start).Milliseconds(),
+			TraceID:    traceID,
+			APIVersion: constants.APIVersion,
+		},
+	}
+
+	writer := output.NewWriter(format)
+	if writeErr := writer.Write(os.Stdout, result); writeErr != nil {
+		slog.Default().Error("Не удалось записать JSON-ответ об ошибке",
+			slog.String("trace_id", traceID),
+			slog.String("error", writeErr.Error()))
+	}
+
+	return fmt.Errorf("%s: %s", code, message)
+}
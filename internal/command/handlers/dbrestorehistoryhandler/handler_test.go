@@ -0,0 +1,252 @@
+package dbrestorehistoryhandler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Kargones/apk-ci/internal/adapter/mssql"
+	"github.com/Kargones/apk-ci/internal/adapter/mssql/mssqltest"
+	"github.com/Kargones/apk-ci/internal/command"
+	"github.com/Kargones/apk-ci/internal/command/handlers/mssqlutil"
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/pkg/output"
+	"github.com/Kargones/apk-ci/internal/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDbRestoreHistoryHandler_Registration(t *testing.T) {
+	h, ok := command.Get(constants.ActNRDbrestoreHistory)
+	require.True(t, ok)
+	assert.Equal(t, constants.ActNRDbrestoreHistory, h.Name())
+	assert.NotEmpty(t, h.Description())
+}
+
+// serverTime возвращает время в часовом поясе серверов СУБД.
+func serverTime(day, hour, minute int) time.Time {
+	return time.Date(2026, 10, day, hour, minute, 0, 0, mssqlutil.ServerLocation())
+}
+
+// record возвращает запись журнала; драйвер отдаёт время сервера в UTC.
+func record(dst string, day int, completed bool) mssql.RestoreRecord {
+	requested := time.Date(2026, 10, day, 9, 0, 0, 0, time.UTC)
+	r := mssql.RestoreRecord{
+		RequestedAt: requested,
+		User:        `DOMAIN\ivanov`,
+		SrcServer:   "srv-prod",
+		SrcDB:       "ERP",
+		DstServer:   "srv-sql",
+		DstDB:       dst,
+	}
+	if completed {
+		r.CompletedAt = requested.Add(25 * time.Minute)
+	}
+	return r
+}
+
+func historyConfig(infobase string) *config.Config {
+	return &config.Config{
+		InfobaseName: infobase,
+		DbConfig: map[string]*config.DatabaseInfo{
+			"ERP_TEST": {DbServer: "srv-sql"},
+			"ERP_DEV":  {DbServer: "srv-sql2"},
+			"ERP":      {DbServer: "srv-prod", Prod: true},
+		},
+	}
+}
+
+// newHandler возвращает обработчик с mock-клиентами по серверам.
+func newHandler(clients map[string]*mssqltest.MockMSSQLClient) *DbRestoreHistoryHandler {
+	return &DbRestoreHistoryHandler{
+		mssqlFactory: func(opts mssql.ClientOptions) (mssql.Client, error) {
+			client, ok := clients[opts.Server]
+			if !ok {
+				return nil, errors.New("неизвестный сервер " + opts.Server)
+			}
+			return client, nil
+		},
+	}
+}
+
+func run(t *testing.T, h *DbRestoreHistoryHandler, cfg *config.Config) (*DbRestoreHistoryData, error) {
+	t.Helper()
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+
+	var execErr error
+	out := testutil.CaptureStdout(t, func() {
+		execErr = h.Execute(context.Background(), cfg)
+	})
+	if execErr != nil {
+		return nil, execErr
+	}
+
+	var result struct {
+		output.Result
+		Data DbRestoreHistoryData `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+	return &result.Data, nil
+}
+
+func TestDbRestoreHistoryHandler_Infobase(t *testing.T) {
+	t.Setenv(constants.EnvDbrestoreHistoryUser, "ivanov")
+	t.Setenv(constants.EnvDbrestoreHistoryFrom, "2026-10-01")
+	t.Setenv(constants.EnvDbrestoreHistoryTo, "2026-10-15")
+	t.Setenv(constants.EnvDbrestoreHistoryLimit, "10")
+
+	var gotOpts mssql.RestoreHistoryOptions
+	client := mssqltest.NewMockMSSQLClient()
+	client.GetRestoreHistoryFunc = func(_ context.Context, opts mssql.RestoreHistoryOptions) ([]mssql.RestoreRecord, error) {
+		gotOpts = opts
+		return []mssql.RestoreRecord{record("ERP_TEST", 14, false), record("ERP_TEST", 10, true)}, nil
+	}
+	client.GetLastRestoreFunc = func(_ context.Context, database string) (*mssql.LastRestore, error) {
+		return &mssql.LastRestore{
+			Database:   database,
+			RestoredAt: time.Date(2026, 10, 10, 9, 25, 0, 0, time.UTC),
+			DataAsOf:   time.Date(2026, 10, 10, 3, 0, 0, 0, time.UTC),
+			SrcServer:  "srv-prod",
+			SrcDB:      "ERP",
+		}, nil
+	}
+
+	data, err := run(t, newHandler(map[string]*mssqltest.MockMSSQLClient{"srv-sql": client}), historyConfig("ERP_TEST"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "ERP_TEST", gotOpts.DstDB)
+	assert.Equal(t, "ivanov", gotOpts.User)
+	assert.True(t, gotOpts.From.Equal(serverTime(1, 0, 0)))
+	assert.True(t, gotOpts.To.Equal(serverTime(16, 0, 0)), "дата окончания включается целиком")
+	assert.Equal(t, 10, gotOpts.Limit)
+
+	assert.Equal(t, []string{"srv-sql"}, data.Servers)
+	require.Len(t, data.Records, 2)
+	assert.Equal(t, OutcomeNotCompleted, data.Records[0].Outcome)
+	assert.Nil(t, data.Records[0].CompletedAt)
+	assert.Equal(t, OutcomeCompleted, data.Records[1].Outcome)
+	assert.Equal(t, int64(25*60), data.Records[1].DurationSec)
+	assert.True(t, data.Records[1].RequestedAt.Equal(serverTime(10, 9, 0)), "время журнала — время сервера")
+
+	require.NotNil(t, data.LastRestore)
+	assert.True(t, data.LastRestore.RestoredAt.Equal(serverTime(10, 9, 25)))
+	require.NotNil(t, data.LastRestore.DataAsOf)
+	assert.True(t, data.LastRestore.DataAsOf.Equal(serverTime(10, 3, 0)))
+	assert.Equal(t, "ERP", data.LastRestore.SrcDB)
+}
+
+func TestDbRestoreHistoryHandler_AllServers(t *testing.T) {
+	t.Setenv(constants.EnvDbrestoreHistoryLimit, "2")
+
+	sql := mssqltest.NewMockMSSQLClient()
+	sql.GetRestoreHistoryFunc = func(context.Context, mssql.RestoreHistoryOptions) ([]mssql.RestoreRecord, error) {
+		return []mssql.RestoreRecord{record("ERP_TEST", 12, true), record("ERP_TEST", 5, true)}, nil
+	}
+	sql2 := mssqltest.NewMockMSSQLClient()
+	sql2.GetRestoreHistoryFunc = func(context.Context, mssql.RestoreHistoryOptions) ([]mssql.RestoreRecord, error) {
+		return []mssql.RestoreRecord{record("ERP_DEV", 8, true)}, nil
+	}
+	sql2.GetLastRestoreFunc = func(context.Context, string) (*mssql.LastRestore, error) {
+		t.Error("последнее восстановление запрашивается только для BR_INFOBASE_NAME")
+		return nil, nil
+	}
+
+	h := newHandler(map[string]*mssqltest.MockMSSQLClient{"srv-sql": sql, "srv-sql2": sql2})
+	data, err := run(t, h, historyConfig(""))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"srv-sql", "srv-sql2"}, data.Servers, "продуктивный сервер не опрашивается")
+	require.Len(t, data.Records, 2)
+	assert.Equal(t, "ERP_TEST", data.Records[0].DstDB)
+	assert.Equal(t, "ERP_DEV", data.Records[1].DstDB)
+	assert.Nil(t, data.LastRestore)
+}
+
+func TestDbRestoreHistoryHandler_ServerFailures(t *testing.T) {
+	sql := mssqltest.NewMockMSSQLClient()
+	sql.ConnectFunc = func(context.Context) error { return errors.New("login failed") }
+	sql2 := mssqltest.NewMockMSSQLClient()
+
+	h := newHandler(map[string]*mssqltest.MockMSSQLClient{"srv-sql": sql, "srv-sql2": sql2})
+	data, err := run(t, h, historyConfig(""))
+	require.NoError(t, err, "недоступность одного сервера не прерывает команду")
+	require.Len(t, data.Warnings, 1)
+	assert.Contains(t, data.Warnings[0], "srv-sql: login failed")
+
+	sql2.ConnectFunc = sql.ConnectFunc
+	_, err = run(t, h, historyConfig(""))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), ErrHistoryQueryFailed)
+}
+
+func TestDbRestoreHistoryHandler_InvalidParams(t *testing.T) {
+	tests := []struct {
+		name  string
+		env   string
+		value string
+	}{
+		{name: "FROM", env: constants.EnvDbrestoreHistoryFrom, value: "01.10.2026"},
+		{name: "TO", env: constants.EnvDbrestoreHistoryTo, value: "вчера"},
+		{name: "LIMIT", env: constants.EnvDbrestoreHistoryLimit, value: "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(tt.env, tt.value)
+			_, err := run(t, newHandler(nil), historyConfig("ERP_TEST"))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "CONFIG.INVALID_PARAM")
+		})
+	}
+}
+
+func TestDbRestoreHistoryHandler_DbServerMissing(t *testing.T) {
+	_, err := run(t, newHandler(nil), historyConfig("UNKNOWN"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "CONFIG.DB_SERVER_MISSING")
+}
+
+func TestParseBound(t *testing.T) {
+	loc := mssqlutil.ServerLocation()
+
+	got, err := parseBound("2026-10-01 18:30", true, loc)
+	require.NoError(t, err)
+	assert.True(t, got.Equal(time.Date(2026, 10, 1, 18, 30, 0, 0, loc)))
+
+	got, err = parseBound("2026-10-01T12:00:00Z", false, loc)
+	require.NoError(t, err)
+	assert.True(t, got.Equal(time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)))
+
+	got, err = parseBound(" ", false, loc)
+	require.NoError(t, err)
+	assert.True(t, got.IsZero())
+}
+
+func TestDbRestoreHistoryData_WriteText(t *testing.T) {
+	completed := serverTime(10, 9, 25)
+	dataAsOf := serverTime(10, 3, 0)
+	data := &DbRestoreHistoryData{
+		Database: "ERP_TEST",
+		LastRestore: &LastRestoreData{
+			RestoredAt: completed, DataAsOf: &dataAsOf, SrcServer: "srv-prod", SrcDB: "ERP",
+		},
+		Records: []RestoreRecordData{{
+			RequestedAt: serverTime(10, 9, 0), CompletedAt: &completed, DurationSec: 1500,
+			Outcome: OutcomeCompleted, User: "ivanov",
+			SrcServer: "srv-prod", SrcDB: "ERP", DstServer: "srv-sql", DstDB: "ERP_TEST",
+		}},
+		Warnings: []string{"srv-sql2: login failed"},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, data.writeText(&buf))
+	out := buf.String()
+	assert.Contains(t, out, "История восстановлений: ERP_TEST (записей: 1)")
+	assert.Contains(t, out, "Последнее восстановление: 2026-10-10 09:25 (данные на 2026-10-10 03:00, источник srv-prod/ERP)")
+	assert.Contains(t, out, "2026-10-10 09:00  ERP@srv-prod → ERP_TEST@srv-sql  ivanov  завершено за 25m0s")
+	assert.Contains(t, out, "⚠ srv-sql2: login failed")
+}
@@ -0,0 +1,459 @@
+// Package dbsnapshothandler реализует NR-команду nr-db-snapshot для быстрой
+// подготовки тестовых баз через моментальные снимки SQL Server (database snapshot):
+// снимок создаётся после восстановления базы из резервной копии (nr-dbrestore),
+// а перед каждым прогоном тестов база возвращается к нему за секунды.
+//
+// На время возврата к снимку информационная база переводится в сервисный режим
+// с завершением сеансов, если он не был включён ранее.
+//
+// Пока у базы есть снимок, nr-dbrestore в неё невозможен: SQL Server не восстанавливает
+// базу из резервной копии при наличии снимков, поэтому перед восстановлением снимок
+// удаляется действием drop.
+package dbsnapshothandler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Kargones/apk-ci/internal/adapter/mssql"
+	"github.com/Kargones/apk-ci/internal/command"
+	"github.com/Kargones/apk-ci/internal/command/handlers/mssqlutil"
+	"github.com/Kargones/apk-ci/internal/command/handlers/racutil"
+	errhandler "github.com/Kargones/apk-ci/internal/command/handlers/shared"
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/pkg/dryrun"
+	"github.com/Kargones/apk-ci/internal/pkg/output"
+	"github.com/Kargones/apk-ci/internal/pkg/tracing"
+	"github.com/Kargones/apk-ci/internal/servicemode"
+)
+
+// Действия команды (BR_DB_SNAPSHOT_ACTION).
+const (
+	// ActionStatus — вывод снимков базы (по умолчанию)
+	ActionStatus = "status"
+	// ActionCreate — создание снимка (существующий снимок с тем же именем пересоздаётся)
+	ActionCreate = "create"
+	// ActionRevert — возврат базы к снимку
+	ActionRevert = "revert"
+	// ActionDrop — удаление снимка
+	ActionDrop = "drop"
+)
+
+// Коды ошибок nr-db-snapshot.
+const (
+	// ErrDbSnapshotProductionForbidden — операции со снимками production базы запрещены
+	ErrDbSnapshotProductionForbidden = "DBSNAPSHOT.PRODUCTION_FORBIDDEN"
+	// ErrDbSnapshotConnectFailed — ошибка подключения к MSSQL
+	ErrDbSnapshotConnectFailed = "DBSNAPSHOT.CONNECT_FAILED"
+	// ErrDbSnapshotUnsupported — MSSQL клиент не поддерживает снимки
+	ErrDbSnapshotUnsupported = "DBSNAPSHOT.UNSUPPORTED"
+	// ErrDbSnapshotNotFound — снимок для возврата не найден
+	ErrDbSnapshotNotFound = "DBSNAPSHOT.NOT_FOUND"
+	// ErrDbSnapshotFailed — ошибка операции со снимком
+	ErrDbSnapshotFailed = "DBSNAPSHOT.FAILED"
+	// ErrDbSnapshotServiceMode — ошибка управления сервисным режимом
+	ErrDbSnapshotServiceMode = "DBSNAPSHOT.SERVICE_MODE_FAILED"
+)
+
+// Compile-time interface check.
+var _ command.Handler = (*DbSnapshotHandler)(nil)
+
+func RegisterCmd() error {
+	return command.Register(&DbSnapshotHandler{})
+}
+
+// SnapshotInfo — снимок базы данных в ответе команды.
+type SnapshotInfo struct {
+	// Name — имя снимка
+	Name string `json:"name"`
+	// CreatedAt — время создания снимка
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DbSnapshotData содержит данные ответа nr-db-snapshot.
+type DbSnapshotData struct {
+	// Action — выполненное действие
+	Action string `json:"action"`
+	// Database — база данных (имя информационной базы)
+	Database string `json:"database"`
+	// Server — сервер MSSQL
+	Server string `json:"server"`
+	// Snapshot — имя снимка
+	Snapshot string `json:"snapshot"`
+	// Changed — было ли произведено изменение (создание, возврат, удаление)
+	Changed bool `json:"changed"`
+	// Replaced — create пересоздал существующий снимок
+	Replaced bool `json:"replaced,omitempty"`
+	// ServiceMode — сервисный режим включался командой на время возврата
+	ServiceMode bool `json:"service_mode,omitempty"`
+	// DryRun — изменения не выполнялись (BR_DRY_RUN)
+	DryRun bool `json:"dry_run,omitempty"`
+	// Snapshots — снимки базы после выполнения
+	Snapshots []SnapshotInfo `json:"snapshots"`
+}
+
+// writeText выводит результат в человекочитаемом формате.
+func (d *DbSnapshotData) writeText(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "База данных: %s (%s)\nСнимок: %s\n", d.Database, d.Server, d.Snapshot); err != nil {
+		return err
+	}
+
+	if d.Action != ActionStatus {
+		result := "без изменений"
+		switch {
+		case d.DryRun && d.Changed:
+			result = "будет выполнено (dry-run)"
+		case d.Changed:
+			result = "выполнено"
+		}
+		var notes []string
+		if d.Replaced {
+			notes = append(notes, "существующий снимок пересоздан")
+		}
+		if d.ServiceMode {
+			notes = append(notes, "на время операции включался сервисный режим")
+		}
+		if len(notes) > 0 {
+			result += " (" + strings.Join(notes, ", ") + ")"
+		}
+		if _, err := fmt.Fprintf(w, "Действие: %s, %s\n", d.Action, result); err != nil {
+			return err
+		}
+	}
+
+	if len(d.Snapshots) == 0 {
+		_, err := fmt.Fprintln(w, "Снимков нет")
+		return err
+	}
+	if _, err := fmt.Fprintln(w, "Снимки базы:"); err != nil {
+		return err
+	}
+	for _, s := range d.Snapshots {
+		if _, err := fmt.Fprintf(w, "  %s — %s\n", s.Name, s.CreatedAt.Format(time.RFC3339)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DbSnapshotHandler обрабатывает команду nr-db-snapshot.
+type DbSnapshotHandler struct {
+	// mssqlClient — опциональный MSSQL клиент (nil в production, mock в тестах)
+	mssqlClient mssql.Client
+	// serviceMode — опциональный менеджер сервисного режима (nil в production, mock в тестах)
+	serviceMode servicemode.Manager
+}
+
+// Name возвращает имя команды.
+func (h *DbSnapshotHandler) Name() string {
+	return constants.ActNRDbSnapshot
+}
+
+// Description возвращает описание команды для вывода в help.
+func (h *DbSnapshotHandler) Description() string {
+	return "Создание, возврат и удаление моментальных снимков тестовой базы MSSQL"
+}
+
+// Execute выполняет команду nr-db-snapshot.
+func (h *DbSnapshotHandler) Execute(ctx context.Context, cfg *config.Config) error {
+	start := time.Now()
+
+	traceID := tracing.TraceIDFromContext(ctx)
+	if traceID == "" {
+		traceID = tracing.GenerateTraceID()
+	}
+
+	format := os.Getenv("BR_OUTPUT_FORMAT")
+
+	// Plan-only не поддерживается; dry-run имеет приоритет над plan-only.
+	if !dryrun.IsDryRun() && dryrun.IsPlanOnly() {
+		return dryrun.WritePlanOnlyUnsupported(os.Stdout, constants.ActNRDbSnapshot)
+	}
+
+	log := slog.Default().With(slog.String("trace_id", traceID), slog.String("command", constants.ActNRDbSnapshot))
+
+	if cfg == nil || cfg.InfobaseName == "" {
+		log.Error("Не указано имя информационной базы")
+		return h.writeError(format, traceID, start,
+			"CONFIG.INFOBASE_MISSING",
+			"Не указано имя информационной базы (BR_INFOBASE_NAME)")
+	}
+
+	action := strings.ToLower(strings.TrimSpace(os.Getenv(constants.EnvDbSnapshotAction)))
+	if action == "" {
+		action = ActionStatus
+	}
+	switch action {
+	case ActionStatus, ActionCreate, ActionRevert, ActionDrop:
+	default:
+		return h.writeError(format, traceID, start, "CONFIG.INVALID_ACTION",
+			fmt.Sprintf("Недопустимое значение %s: %q, допустимые: status, create, revert, drop",
+				constants.EnvDbSnapshotAction, action))
+	}
+
+	useServiceMode := true
+	if v := os.Getenv(constants.EnvDbSnapshotServiceMode); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			return h.writeError(format, traceID, start, "CONFIG.INVALID_PARAM",
+				fmt.Sprintf("Недопустимое значение %s: %q", constants.EnvDbSnapshotServiceMode, v))
+		}
+		useServiceMode = parsed
+	}
+
+	database := cfg.InfobaseName
+	snapshot := strings.TrimSpace(os.Getenv(constants.EnvDbSnapshotName))
+	if snapshot == "" {
+		snapshot = database + "_snapshot"
+	}
+
+	// Снимок production базы бесполезен, а возврат к нему уничтожает данные
+	if cfg.IsProductionDb(database) {
+		log.Error("Попытка операции со снимком production базы", slog.String("database", database))
+		return h.writeError(format, traceID, start, ErrDbSnapshotProductionForbidden,
+			fmt.Sprintf("Операции со снимками production базы '%s' запрещены", database))
+	}
+
+	server := cfg.GetDbServer(database)
+	if server == "" {
+		return h.writeError(format, traceID, start, "CONFIG.DB_SERVER_MISSING",
+			fmt.Sprintf("Не найден сервер MSSQL для базы '%s' в dbconfig", database))
+	}
+
+	log = log.With(slog.String("database", database), slog.String("snapshot", snapshot), slog.String("action", action))
+	log.Info("Начало обработки команды управления снимками базы данных")
+
+	mssqlClient := h.mssqlClient
+	if mssqlClient == nil {
+		var err error
+		mssqlClient, err = mssqlutil.NewClient(cfg, server, mssqlutil.DefaultTimeout)
+		if err != nil {
+			log.Error("Не удалось создать MSSQL клиент", slog.String("error", err.Error()))
+			return h.writeError(format, traceID, start, ErrDbSnapshotConnectFailed,
+				fmt.Sprintf("Не удалось создать MSSQL клиент: %v", err))
+		}
+	}
+	snapshots, ok := mssqlClient.(mssql.SnapshotManager)
+	if !ok {
+		return h.writeError(format, traceID, start, ErrDbSnapshotUnsupported,
+			"MSSQL клиент не поддерживает снимки баз данных")
+	}
+
+	if err := mssqlClient.Connect(ctx); err != nil {
+		log.Error("Не удалось подключиться к MSSQL", slog.String("error", err.Error()))
+		return h.writeError(format, traceID, start, ErrDbSnapshotConnectFailed,
+			fmt.Sprintf("Не удалось подключиться к MSSQL серверу %s: %v", server, err))
+	}
+	defer func() {
+		if closeErr := mssqlClient.Close(); closeErr != nil {
+			log.Warn("Ошибка закрытия соединения MSSQL", slog.String("error", closeErr.Error()))
+		}
+	}()
+
+	existing, err := snapshots.ListSnapshots(ctx, database)
+	if err != nil {
+		log.Error("Не удалось получить снимки базы", slog.String("error", err.Error()))
+		return h.writeError(format, traceID, start, ErrDbSnapshotFailed,
+			fmt.Sprintf("Не удалось получить снимки базы %s: %v", database, err))
+	}
+	exists := false
+	for _, s := range existing {
+		if s.Name == snapshot {
+			exists = true
+		}
+	}
+
+	data := &DbSnapshotData{
+		Action:   action,
+		Database: database,
+		Server:   server,
+		Snapshot: snapshot,
+		DryRun:   dryrun.IsDryRun(),
+	}
+
+	switch action {
+	case ActionCreate:
+		data.Changed, data.Replaced = true, exists
+		if data.DryRun {
+			break
+		}
+		if exists {
+			log.Info("Удаление существующего снимка перед созданием")
+			if err := snapshots.DropSnapshot(ctx, snapshot); err != nil {
+				log.Error("Не удалось удалить существующий снимок", slog.String("error", err.Error()))
+				return h.writeError(format, traceID, start, ErrDbSnapshotFailed,
+					fmt.Sprintf("Не удалось удалить существующий снимок %s: %v", snapshot, err))
+			}
+		}
+		if err := snapshots.CreateSnapshot(ctx, database, snapshot); err != nil {
+			log.Error("Не удалось создать снимок", slog.String("error", err.Error()))
+			return h.writeError(format, traceID, start, ErrDbSnapshotFailed,
+				fmt.Sprintf("Не удалось создать снимок %s: %v", snapshot, err))
+		}
+
+	case ActionRevert:
+		if !exists {
+			return h.writeError(format, traceID, start, ErrDbSnapshotNotFound,
+				fmt.Sprintf("Снимок %s базы %s не найден: выполните create после восстановления базы", snapshot, database))
+		}
+		data.Changed = true
+		if data.DryRun {
+			break
+		}
+		if code, err := h.revert(ctx, cfg, snapshots, data, useServiceMode, log); err != nil {
+			return h.writeError(format, traceID, start, code, err.Error())
+		}
+
+	case ActionDrop:
+		data.Changed = exists
+		if !exists || data.DryRun {
+			break
+		}
+		if err := snapshots.DropSnapshot(ctx, snapshot); err != nil {
+			log.Error("Не удалось удалить снимок", slog.String("error", err.Error()))
+			return h.writeError(format, traceID, start, ErrDbSnapshotFailed,
+				fmt.Sprintf("Не удалось удалить снимок %s: %v", snapshot, err))
+		}
+	}
+
+	// Итоговый список снимков: после изменения перечитывается
+	if data.Changed && !data.DryRun {
+		if existing, err = snapshots.ListSnapshots(ctx, database); err != nil {
+			log.Warn("Не удалось перечитать снимки базы", slog.String("error", err.Error()))
+			existing = nil
+		}
+	}
+	data.Snapshots = make([]SnapshotInfo, 0, len(existing))
+	for _, s := range existing {
+		data.Snapshots = append(data.Snapshots, SnapshotInfo{Name: s.Name, CreatedAt: s.CreatedAt})
+	}
+
+	log.Info("Команда управления снимками базы данных выполнена",
+		slog.Bool("changed", data.Changed),
+		slog.Bool("service_mode", data.ServiceMode),
+		slog.Int("snapshots", len(data.Snapshots)))
+
+	return h.writeSuccess(format, traceID, start, data)
+}
+
+// revert возвращает базу к снимку, при необходимости включая сервисный режим
+// на время операции. Сервисный режим, включённый до вызова, не изменяется.
+// Возвращает код ошибки и ошибку.
+func (h *DbSnapshotHandler) revert(ctx context.Context, cfg *config.Config, snapshots mssql.SnapshotManager,
+	data *DbSnapshotData, useServiceMode bool, log *slog.Logger) (string, error) {
+	var sm servicemode.Manager
+	if useServiceMode {
+		var err error
+		if sm, err = h.serviceModeManager(cfg, log); err != nil {
+			return ErrDbSnapshotServiceMode, fmt.Errorf("не удалось создать RAC клиент: %w", err)
+		}
+		status, err := sm.GetServiceModeStatus(ctx, cfg.InfobaseName)
+		if err != nil {
+			return ErrDbSnapshotServiceMode, fmt.Errorf("не удалось получить статус сервисного режима: %w", err)
+		}
+		if status.Enabled {
+			log.Info("Сервисный режим уже включён, команда его не изменяет")
+			sm = nil
+		} else {
+			log.Info("Включение сервисного режима на время возврата к снимку")
+			if err := sm.EnableServiceMode(ctx, cfg.InfobaseName, true); err != nil {
+				return ErrDbSnapshotServiceMode, fmt.Errorf("не удалось включить сервисный режим: %w", err)
+			}
+			data.ServiceMode = true
+		}
+	}
+
+	revertStart := time.Now()
+	revertErr := snapshots.RevertSnapshot(ctx, data.Database, data.Snapshot)
+	if revertErr == nil {
+		log.Info("База возвращена к снимку", slog.Duration("duration", time.Since(revertStart)))
+	}
+
+	// Сервисный режим отключается и при ошибке возврата: база не должна остаться заблокированной
+	if sm != nil {
+		log.Info("Отключение сервисного режима")
+		if err := sm.DisableServiceMode(ctx, cfg.InfobaseName); err != nil {
+			log.Error("Не удалось отключить сервисный режим", slog.String("error", err.Error()))
+			if revertErr == nil {
+				return ErrDbSnapshotServiceMode, fmt.Errorf("база возвращена к снимку, но не удалось отключить сервисный режим: %w", err)
+			}
+		}
+	}
+
+	if revertErr != nil {
+		log.Error("Не удалось вернуть базу к снимку", slog.String("error", revertErr.Error()))
+		return ErrDbSnapshotFailed, fmt.Errorf("не удалось вернуть базу %s к снимку %s: %w", data.Database, data.Snapshot, revertErr)
+	}
+	return "", nil
+}
+
+// serviceModeManager возвращает менеджер сервисного режима (в production — через RAC клиент из конфигурации).
+func (h *DbSnapshotHandler) serviceModeManager(cfg *config.Config, log *slog.Logger) (servicemode.Manager, error) {
+	if h.serviceMode != nil {
+		return h.serviceMode, nil
+	}
+	racClient, err := racutil.NewClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return servicemode.NewClientWithRacClient(servicemode.RacConfig{}, racClient, &servicemode.SlogLogger{Logger: log}), nil
+}
+
+// writeSuccess выводит успешный результат.
+func (h *DbSnapshotHandler) writeSuccess(format, traceID string, start time.Time, data *DbSnapshotData) error {
+	if format != output.FormatJSON {
+		return data.writeText(os.Stdout)
+	}
+
+	result := &output.Result{
+		Status:  output.StatusSuccess,
+		Command: constants.ActNRDbSnapshot,
+		Data:    data,
+		Metadata: &output.Metadata{
+			DurationMs: time.Since(start).Milliseconds(),
+			TraceID:    traceID,
+			APIVersion: constants.APIVersion,
+		},
+	}
+
+	writer := output.NewWriter(format)
+	return writer.Write(os.Stdout, result)
+}
+
+// writeError выводит структурированную ошибку и возвращает error.
+func (h *DbSnapshotHandler) writeError(format, traceID string, start time.Time, code, message string) error {
+	// Текстовый формат — человекочитаемый вывод ошибки
+	if format != output.FormatJSON {
+		return errhandler.HandleError(message, code)
+	}
+
+	result := &output.Result{
+		Status:  output.StatusError,
+		Command: constants.ActNRDbSnapshot,
+		Error: &output.ErrorInfo{
+			Code:    code,
+			Message: message,
+		},
+		Metadata: &output.Metadata{
+			DurationMs: time.Since(start).Milliseconds(),
+			TraceID:    traceID,
+			APIVersion: constants.APIVersion,
+		},
+	}
+
+	writer := output.NewWriter(format)
+	if writeErr := writer.Write(os.Stdout, result); writeErr != nil {
+		slog.Default().Error("Не удалось записать JSON-ответ об ошибке",
+			slog.String("trace_id", traceID),
+			slog.String("error", writeErr.Error()))
+	}
+
+	return fmt.Errorf("%s: %s", code, message)
+}
@@ -0,0 +1,313 @@
+package dbsnapshothandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Kargones/apk-ci/internal/adapter/mssql"
+	"github.com/Kargones/apk-ci/internal/adapter/mssql/mssqltest"
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
+	"github.com/Kargones/apk-ci/internal/command"
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/pkg/output"
+	"github.com/Kargones/apk-ci/internal/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var createdAt = time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+func TestDbSnapshotHandler_Registration(t *testing.T) {
+	h, ok := command.Get(constants.ActNRDbSnapshot)
+	require.True(t, ok)
+	assert.Equal(t, constants.ActNRDbSnapshot, h.Name())
+	assert.NotEmpty(t, h.Description())
+}
+
+// snapshotMock — mock MSSQL, хранящий снимки и журнал вызовов.
+type snapshotMock struct {
+	*mssqltest.MockMSSQLClient
+	snapshots []string
+	calls     []string
+}
+
+func newSnapshotMock(snapshots ...string) *snapshotMock {
+	m := &snapshotMock{MockMSSQLClient: mssqltest.NewMockMSSQLClient(), snapshots: snapshots}
+	m.ListSnapshotsFunc = func(_ context.Context, database string) ([]mssql.DatabaseSnapshot, error) {
+		var list []mssql.DatabaseSnapshot
+		for _, s := range m.snapshots {
+			list = append(list, mssql.DatabaseSnapshot{Name: s, Database: database, CreatedAt: createdAt})
+		}
+		return list, nil
+	}
+	m.CreateSnapshotFunc = func(_ context.Context, _, snapshot string) error {
+		m.calls = append(m.calls, "create "+snapshot)
+		m.snapshots = append(m.snapshots, snapshot)
+		return nil
+	}
+	m.DropSnapshotFunc = func(_ context.Context, snapshot string) error {
+		m.calls = append(m.calls, "drop "+snapshot)
+		m.snapshots = nil
+		return nil
+	}
+	m.RevertSnapshotFunc = func(_ context.Context, _, snapshot string) error {
+		m.calls = append(m.calls, "revert "+snapshot)
+		return nil
+	}
+	return m
+}
+
+// serviceModeMock — mock servicemode.Manager, записывающий вызовы в общий журнал.
+type serviceModeMock struct {
+	enabled   bool
+	terminate bool
+	calls     *[]string
+}
+
+func (s *serviceModeMock) EnableServiceMode(_ context.Context, _ string, terminateSessions bool) error {
+	*s.calls = append(*s.calls, "enable")
+	s.enabled, s.terminate = true, terminateSessions
+	return nil
+}
+
+func (s *serviceModeMock) DisableServiceMode(context.Context, string) error {
+	*s.calls = append(*s.calls, "disable")
+	s.enabled = false
+	return nil
+}
+
+func (s *serviceModeMock) GetServiceModeStatus(context.Context, string) (*rac.ServiceModeStatus, error) {
+	return &rac.ServiceModeStatus{Enabled: s.enabled}, nil
+}
+
+func snapshotConfig() *config.Config {
+	return &config.Config{
+		InfobaseName: "ERP_TEST",
+		DbConfig: map[string]*config.DatabaseInfo{
+			"ERP_TEST": {DbServer: "srv-sql"},
+			"ERP":      {DbServer: "srv-sql", Prod: true},
+		},
+	}
+}
+
+func run(t *testing.T, h *DbSnapshotHandler, cfg *config.Config, action string) (*DbSnapshotData, error) {
+	t.Helper()
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+	t.Setenv(constants.EnvDbSnapshotAction, action)
+
+	var execErr error
+	out := testutil.CaptureStdout(t, func() {
+		execErr = h.Execute(context.Background(), cfg)
+	})
+	if execErr != nil {
+		return nil, execErr
+	}
+
+	var result struct {
+		output.Result
+		Data DbSnapshotData `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+	return &result.Data, nil
+}
+
+func TestDbSnapshotHandler_Create(t *testing.T) {
+	t.Setenv(constants.EnvDryRun, "")
+	mock := newSnapshotMock()
+
+	data, err := run(t, &DbSnapshotHandler{mssqlClient: mock}, snapshotConfig(), ActionCreate)
+	require.NoError(t, err)
+	assert.True(t, data.Changed)
+	assert.False(t, data.Replaced)
+	assert.Equal(t, "ERP_TEST_snapshot", data.Snapshot)
+	assert.Equal(t, "srv-sql", data.Server)
+	assert.Equal(t, []string{"create ERP_TEST_snapshot"}, mock.calls)
+	require.Len(t, data.Snapshots, 1)
+	assert.True(t, createdAt.Equal(data.Snapshots[0].CreatedAt))
+}
+
+func TestDbSnapshotHandler_CreateReplaces(t *testing.T) {
+	t.Setenv(constants.EnvDryRun, "")
+	t.Setenv(constants.EnvDbSnapshotName, "ERP_TEST_clean")
+	mock := newSnapshotMock("ERP_TEST_clean")
+
+	data, err := run(t, &DbSnapshotHandler{mssqlClient: mock}, snapshotConfig(), ActionCreate)
+	require.NoError(t, err)
+	assert.True(t, data.Replaced)
+	assert.Equal(t, []string{"drop ERP_TEST_clean", "create ERP_TEST_clean"}, mock.calls)
+}
+
+// TestDbSnapshotHandler_Revert — возврат выполняется внутри сервисного режима.
+func TestDbSnapshotHandler_Revert(t *testing.T) {
+	t.Setenv(constants.EnvDryRun, "")
+	mock := newSnapshotMock("ERP_TEST_snapshot")
+	sm := &serviceModeMock{calls: &mock.calls}
+
+	data, err := run(t, &DbSnapshotHandler{mssqlClient: mock, serviceMode: sm}, snapshotConfig(), ActionRevert)
+	require.NoError(t, err)
+	assert.True(t, data.Changed)
+	assert.True(t, data.ServiceMode)
+	assert.Equal(t, []string{"enable", "revert ERP_TEST_snapshot", "disable"}, mock.calls)
+	assert.True(t, sm.terminate, "сеансы завершаются перед возвратом")
+	assert.False(t, sm.enabled)
+}
+
+// TestDbSnapshotHandler_RevertKeepsServiceMode — сервисный режим, включённый
+// до команды, не отключается.
+func TestDbSnapshotHandler_RevertKeepsServiceMode(t *testing.T) {
+	t.Setenv(constants.EnvDryRun, "")
+	mock := newSnapshotMock("ERP_TEST_snapshot")
+	sm := &serviceModeMock{enabled: true, calls: &mock.calls}
+
+	data, err := run(t, &DbSnapshotHandler{mssqlClient: mock, serviceMode: sm}, snapshotConfig(), ActionRevert)
+	require.NoError(t, err)
+	assert.False(t, data.ServiceMode)
+	assert.Equal(t, []string{"revert ERP_TEST_snapshot"}, mock.calls)
+	assert.True(t, sm.enabled)
+}
+
+func TestDbSnapshotHandler_RevertWithoutServiceMode(t *testing.T) {
+	t.Setenv(constants.EnvDryRun, "")
+	t.Setenv(constants.EnvDbSnapshotServiceMode, "false")
+	mock := newSnapshotMock("ERP_TEST_snapshot")
+	sm := &serviceModeMock{calls: &mock.calls}
+
+	_, err := run(t, &DbSnapshotHandler{mssqlClient: mock, serviceMode: sm}, snapshotConfig(), ActionRevert)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"revert ERP_TEST_snapshot"}, mock.calls)
+}
+
+// TestDbSnapshotHandler_RevertFailure — при ошибке возврата сервисный режим отключается.
+func TestDbSnapshotHandler_RevertFailure(t *testing.T) {
+	t.Setenv(constants.EnvDryRun, "")
+	mock := newSnapshotMock("ERP_TEST_snapshot")
+	mock.RevertSnapshotFunc = func(context.Context, string, string) error {
+		mock.calls = append(mock.calls, "revert")
+		return errors.New("database is in use")
+	}
+	sm := &serviceModeMock{calls: &mock.calls}
+
+	_, err := run(t, &DbSnapshotHandler{mssqlClient: mock, serviceMode: sm}, snapshotConfig(), ActionRevert)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), ErrDbSnapshotFailed)
+	assert.Contains(t, err.Error(), "database is in use")
+	assert.Equal(t, []string{"enable", "revert", "disable"}, mock.calls)
+}
+
+func TestDbSnapshotHandler_RevertNotFound(t *testing.T) {
+	t.Setenv(constants.EnvDryRun, "")
+	mock := newSnapshotMock()
+	sm := &serviceModeMock{calls: &mock.calls}
+
+	_, err := run(t, &DbSnapshotHandler{mssqlClient: mock, serviceMode: sm}, snapshotConfig(), ActionRevert)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), ErrDbSnapshotNotFound)
+	assert.Empty(t, mock.calls)
+}
+
+func TestDbSnapshotHandler_Drop(t *testing.T) {
+	t.Setenv(constants.EnvDryRun, "")
+	mock := newSnapshotMock("ERP_TEST_snapshot")
+
+	data, err := run(t, &DbSnapshotHandler{mssqlClient: mock}, snapshotConfig(), ActionDrop)
+	require.NoError(t, err)
+	assert.True(t, data.Changed)
+	assert.Empty(t, data.Snapshots)
+	assert.Equal(t, []string{"drop ERP_TEST_snapshot"}, mock.calls)
+
+	// Повторное удаление — без изменений
+	data, err = run(t, &DbSnapshotHandler{mssqlClient: mock}, snapshotConfig(), ActionDrop)
+	require.NoError(t, err)
+	assert.False(t, data.Changed)
+	assert.Len(t, mock.calls, 1)
+}
+
+func TestDbSnapshotHandler_DryRun(t *testing.T) {
+	t.Setenv(constants.EnvDryRun, "true")
+	mock := newSnapshotMock("ERP_TEST_snapshot")
+	sm := &serviceModeMock{calls: &mock.calls}
+
+	for _, action := range []string{ActionCreate, ActionRevert, ActionDrop} {
+		data, err := run(t, &DbSnapshotHandler{mssqlClient: mock, serviceMode: sm}, snapshotConfig(), action)
+		require.NoError(t, err, action)
+		assert.True(t, data.DryRun)
+		assert.True(t, data.Changed, action)
+	}
+	assert.Empty(t, mock.calls)
+}
+
+func TestDbSnapshotHandler_Status(t *testing.T) {
+	t.Setenv(constants.EnvDryRun, "")
+	mock := newSnapshotMock("ERP_TEST_snapshot")
+
+	data, err := run(t, &DbSnapshotHandler{mssqlClient: mock}, snapshotConfig(), "")
+	require.NoError(t, err)
+	assert.Equal(t, ActionStatus, data.Action)
+	assert.False(t, data.Changed)
+	require.Len(t, data.Snapshots, 1)
+	assert.Equal(t, "ERP_TEST_snapshot", data.Snapshots[0].Name)
+	assert.Empty(t, mock.calls)
+}
+
+func TestDbSnapshotHandler_TextOutput(t *testing.T) {
+	t.Setenv(constants.EnvDryRun, "")
+	t.Setenv("BR_OUTPUT_FORMAT", "text")
+	t.Setenv(constants.EnvDbSnapshotAction, ActionRevert)
+	mock := newSnapshotMock("ERP_TEST_snapshot")
+
+	h := &DbSnapshotHandler{mssqlClient: mock, serviceMode: &serviceModeMock{calls: &mock.calls}}
+	out := testutil.CaptureStdout(t, func() {
+		require.NoError(t, h.Execute(context.Background(), snapshotConfig()))
+	})
+	assert.Contains(t, out, "База данных: ERP_TEST (srv-sql)")
+	assert.Contains(t, out, "Действие: revert, выполнено (на время операции включался сервисный режим)")
+	assert.Contains(t, out, "ERP_TEST_snapshot — 2026-10-01T09:00:00Z")
+}
+
+func TestDbSnapshotHandler_Errors(t *testing.T) {
+	t.Setenv(constants.EnvDryRun, "")
+
+	tests := []struct {
+		name     string
+		cfg      *config.Config
+		action   string
+		env      map[string]string
+		client   mssql.Client
+		wantCode string
+	}{
+		{name: "нет базы", cfg: &config.Config{}, action: ActionCreate, wantCode: "CONFIG.INFOBASE_MISSING"},
+		{name: "неизвестное действие", cfg: snapshotConfig(), action: "restore", wantCode: "CONFIG.INVALID_ACTION"},
+		{name: "некорректный флаг сервисного режима", cfg: snapshotConfig(), action: ActionRevert,
+			env: map[string]string{constants.EnvDbSnapshotServiceMode: "maybe"}, wantCode: "CONFIG.INVALID_PARAM"},
+		{name: "production база", cfg: func() *config.Config {
+			cfg := snapshotConfig()
+			cfg.InfobaseName = "ERP"
+			return cfg
+		}(), action: ActionCreate, wantCode: ErrDbSnapshotProductionForbidden},
+		{name: "нет сервера", cfg: &config.Config{InfobaseName: "ERP_DEV"}, action: ActionCreate, wantCode: "CONFIG.DB_SERVER_MISSING"},
+		{name: "клиент без снимков", cfg: snapshotConfig(), action: ActionCreate,
+			client: struct{ mssql.Client }{mssqltest.NewMockMSSQLClient()}, wantCode: ErrDbSnapshotUnsupported},
+		{name: "ошибка подключения", cfg: snapshotConfig(), action: ActionCreate,
+			client:   &mssqltest.MockMSSQLClient{ConnectFunc: func(context.Context) error { return errors.New("login failed") }},
+			wantCode: ErrDbSnapshotConnectFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			client := tt.client
+			if client == nil {
+				client = newSnapshotMock()
+			}
+			_, err := run(t, &DbSnapshotHandler{mssqlClient: client}, tt.cfg, tt.action)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantCode)
+		})
+	}
+}
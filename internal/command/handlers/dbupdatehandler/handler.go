@@ -183,7 +183,7 @@ func (h *DbUpdateHandler) validateDbUpdate(ctx context.Context, cfg *config.Conf
 			fmt.Sprintf("информационная база '%s' не найдена в конфигурации", cfg.InfobaseName))
 	}
 
-	if cfg.AppConfig == nil || cfg.AppConfig.Paths.Bin1cv8 == "" {
+	if cfg.AppConfig == nil || (cfg.AppConfig.Paths.Bin1cv8 == "" && !cfg.PlatformConfig.Enabled()) {
 		ec.log.Error("Путь к 1cv8 не указан в конфигурации")
 		return nil, nil, h.writeError(ctx, cfg, ec.format, ec.traceID, ec.start, ErrDbUpdateConfig,
 			"путь к 1cv8 не указан в конфигурации (app.yaml:paths.bin1cv8)")
@@ -288,7 +288,12 @@ func (h *DbUpdateHandler) Execute(ctx context.Context, cfg *config.Config) error
 		return pErr
 	}
 
-	client := h.getOrCreateOneCClient(cfg)
+	bin1cv8, err := h.resolveBin1cv8(ctx, cfg, dbInfo, ec.log)
+	if err != nil {
+		ec.log.Error("Не удалось выбрать версию платформы", slog.String("error", err.Error()))
+		return h.writeError(ctx, cfg, ec.format, ec.traceID, ec.start, ErrDbUpdateConfig, err.Error())
+	}
+	client := h.getOrCreateOneCClient(cfg, bin1cv8)
 
 	autoDeps, weEnabledServiceMode, racClient := h.setupAutoDeps(ctx, cfg, dbInfo, ec.log)
 
@@ -306,7 +311,7 @@ func (h *DbUpdateHandler) Execute(ctx context.Context, cfg *config.Config) error
 
 	opts := onec.UpdateOptions{
 		ConnectString: ec.connectString, Extension: ec.extension,
-		Timeout: ec.timeout, Bin1cv8: cfg.AppConfig.Paths.Bin1cv8,
+		Timeout: ec.timeout, Bin1cv8: bin1cv8,
 	}
 
 	result, err := client.UpdateDBCfg(ctx, opts)
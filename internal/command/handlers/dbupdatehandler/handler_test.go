@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -799,6 +800,68 @@ func TestDbUpdateHandler_Execute_MissingBin1cv8(t *testing.T) {
 	}
 }
 
+// TestDbUpdateHandler_Execute_PlatformVersion проверяет выбор 1cv8 по версии сервера 1С
+// при нескольких установленных версиях платформы (BR_PLATFORM_AUTODETECT).
+func TestDbUpdateHandler_Execute_PlatformVersion(t *testing.T) {
+	base := t.TempDir()
+	for _, v := range []string{"8.3.24.1467", "8.3.27.1606"} {
+		if err := os.MkdirAll(filepath.Join(base, v), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(base, v, "1cv8"), nil, 0o755); err != nil { //nolint:gosec // тестовая заглушка
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name          string
+		serverVersion string
+		wantBin       string
+		wantErr       string
+	}{
+		{name: "server version installed", serverVersion: "8.3.24.1467", wantBin: filepath.Join(base, "8.3.24.1467", "1cv8")},
+		{name: "server version not installed", serverVersion: "8.3.25.1257", wantErr: "PLATFORM.NO_MATCH"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotBin string
+			mockClient := &onectest.MockDatabaseUpdater{
+				UpdateDBCfgFunc: func(ctx context.Context, opts onec.UpdateOptions) (*onec.UpdateResult, error) {
+					gotBin = opts.Bin1cv8
+					return &onec.UpdateResult{Success: true}, nil
+				},
+			}
+			racMock := ractest.NewMockRACClient()
+			racMock.GetAgentVersionFunc = func(context.Context) (string, error) { return tt.serverVersion, nil }
+
+			cfg := createTestConfig("TestDB")
+			cfg.PlatformConfig = &config.PlatformConfig{AutoDetect: true, BasePath: base}
+			h := &DbUpdateHandler{oneCClient: mockClient, racClient: racMock}
+
+			var err error
+			out := captureStdout(func() {
+				err = h.Execute(context.Background(), cfg)
+			})
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(out, tt.wantErr) {
+					t.Fatalf("Execute() error = %v, output = %s, want %s", err, out, tt.wantErr)
+				}
+				if gotBin != "" {
+					t.Errorf("UpdateDBCfg не должен вызываться при несовпадении версий, bin = %s", gotBin)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute() unexpected error = %v", err)
+			}
+			if gotBin != tt.wantBin {
+				t.Errorf("Bin1cv8 = %q, want %q", gotBin, tt.wantBin)
+			}
+		})
+	}
+}
+
 // TestDbUpdateHandler_Execute_SuccessFalseNoError проверяет поведение когда UpdateDBCfg возвращает Success=false без ошибки (M2 fix)
 func TestDbUpdateHandler_Execute_SuccessFalseNoError(t *testing.T) {
 	mockClient := &onectest.MockDatabaseUpdater{
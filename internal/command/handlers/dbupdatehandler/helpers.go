@@ -11,6 +11,7 @@ import (
 	"github.com/Kargones/apk-ci/internal/adapter/onec"
 	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
 	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/pkg/platform"
 	"github.com/Kargones/apk-ci/internal/pkg/progress"
 )
 
@@ -58,11 +59,42 @@ func (h *DbUpdateHandler) getTimeout() time.Duration {
 }
 
 // getOrCreateOneCClient возвращает существующий или создаёт новый 1C клиент.
-func (h *DbUpdateHandler) getOrCreateOneCClient(cfg *config.Config) onec.DatabaseUpdater {
+func (h *DbUpdateHandler) getOrCreateOneCClient(cfg *config.Config, bin1cv8 string) onec.DatabaseUpdater {
 	if h.oneCClient != nil {
 		return h.oneCClient
 	}
-	return onec.NewUpdater(cfg.AppConfig.Paths.Bin1cv8, cfg.WorkDir, cfg.TmpDir)
+	return onec.NewUpdater(bin1cv8, cfg.WorkDir, cfg.TmpDir)
+}
+
+// resolveBin1cv8 возвращает путь к 1cv8 для обновления базы. При включённом выборе
+// версии платформы (config.PlatformConfig) с AutoDetect версия 1cv8 должна совпадать
+// с версией агента сервера 1С, полученной через RAC; без AutoDetect выбирается
+// закреплённая версия. При выключенном выборе — AppConfig.Paths.Bin1cv8.
+func (h *DbUpdateHandler) resolveBin1cv8(ctx context.Context, cfg *config.Config, dbInfo *config.DatabaseInfo, log *slog.Logger) (string, error) {
+	if !cfg.PlatformConfig.Enabled() {
+		return cfg.AppConfig.Paths.Bin1cv8, nil
+	}
+	var req platform.Requirement
+	if cfg.PlatformConfig.AutoDetect {
+		racClient := h.getOrCreateRacClient(cfg, dbInfo, log)
+		if racClient == nil {
+			return "", fmt.Errorf("не удалось определить версию сервера 1С: RAC клиент недоступен")
+		}
+		racCtx, racCancel := context.WithTimeout(ctx, racOperationTimeout)
+		defer racCancel()
+		var err error
+		if req, err = rac.ServerRequirement(racCtx, racClient, dbInfo.GetServer()); err != nil {
+			return "", fmt.Errorf("не удалось определить версию сервера 1С: %w", err)
+		}
+	}
+	bin, err := cfg.PlatformTool(platform.Tool1cv8, req)
+	if err != nil {
+		return "", err
+	}
+	log.Info("Выбрана версия платформы",
+		slog.String("bin", bin),
+		slog.String("requirement", req.String()))
+	return bin, nil
 }
 
 // getOrCreateRacClient возвращает существующий или создаёт новый RAC клиент.
@@ -91,9 +123,10 @@ func (h *DbUpdateHandler) getOrCreateRacClient(cfg *config.Config, dbInfo *confi
 		return nil
 	}
 
-	// Получаем путь к rac
+	// Получаем путь к rac (при выборе версии платформы — из установленных версий)
 	racPath := cfg.AppConfig.Paths.Rac
-	if racPath == "" {
+	resolver := cfg.PlatformResolver()
+	if racPath == "" && resolver == nil {
 		log.Warn("Путь к RAC не указан в конфигурации")
 		return nil
 	}
@@ -112,7 +145,9 @@ func (h *DbUpdateHandler) getOrCreateRacClient(cfg *config.Config, dbInfo *confi
 
 	client, err := rac.NewClient(rac.ClientOptions{
 		RACPath:      racPath,
+		Platform:     resolver,
 		Server:       server,
+		Retries:      cfg.AppConfig.Rac.Retries,
 		ClusterUser:  clusterUser,
 		ClusterPass:  clusterPass,
 		InfobaseUser: infobaseUser,
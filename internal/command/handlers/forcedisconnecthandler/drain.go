@@ -0,0 +1,220 @@
+package forcedisconnecthandler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
+	"github.com/Kargones/apk-ci/internal/constants"
+)
+
+const (
+	// maxDrainSec — максимальный период drain (1 час): дольше не блокируем pipeline.
+	maxDrainSec = 3600
+	// defaultPollSec — интервал опроса сессий по умолчанию.
+	defaultPollSec = 30
+	// releaseTimeout — таймаут снятия блокировки после drain, в том числе после отмены context.
+	releaseTimeout = 2 * time.Minute
+)
+
+// DrainPoll — результат опроса сессий в режиме drain.
+type DrainPoll struct {
+	// ElapsedSec — секунд с начала drain
+	ElapsedSec int `json:"elapsed_sec"`
+	// Remaining — количество оставшихся сессий (без исключённых)
+	Remaining int `json:"remaining"`
+	// Users — пользователи оставшихся сессий
+	Users []string `json:"users"`
+}
+
+// drainOptions — параметры режима drain из переменных окружения.
+type drainOptions struct {
+	// drainSec — период ожидания добровольного завершения сессий (0 — drain выключен)
+	drainSec int
+	// pollSec — интервал опроса сессий
+	pollSec int
+}
+
+// drainOptionsFromEnv читает BR_DISCONNECT_DRAIN_SEC и BR_DISCONNECT_POLL_SEC.
+// В отличие от BR_DISCONNECT_DELAY_SEC некорректное значение — ошибка:
+// откат к немедленному завершению привёл бы к потере несохранённых данных.
+func drainOptionsFromEnv() (drainOptions, error) {
+	opts := drainOptions{pollSec: defaultPollSec}
+	if v := os.Getenv(constants.EnvDisconnectDrainSec); v != "" {
+		d, err := strconv.Atoi(v)
+		if err != nil || d < 0 || d > maxDrainSec {
+			return opts, fmt.Errorf("некорректное значение %s: %q (допустимо 0-%d)", constants.EnvDisconnectDrainSec, v, maxDrainSec)
+		}
+		opts.drainSec = d
+	}
+	if v := os.Getenv(constants.EnvDisconnectPollSec); v != "" {
+		p, err := strconv.Atoi(v)
+		if err != nil || p < 1 {
+			return opts, fmt.Errorf("некорректное значение %s: %q (ожидается положительное число секунд)", constants.EnvDisconnectPollSec, v)
+		}
+		opts.pollSec = p
+	}
+	return opts, nil
+}
+
+// sessionFilter исключает сессии из завершения по AppID или имени пользователя.
+type sessionFilter struct {
+	apps  map[string]bool
+	users map[string]bool
+}
+
+// newSessionFilter создаёт фильтр из списков через запятую (без учёта регистра).
+func newSessionFilter(apps, users string) sessionFilter {
+	return sessionFilter{apps: splitList(apps), users: splitList(users)}
+}
+
+// splitList разбирает список через запятую в множество в нижнем регистре.
+func splitList(v string) map[string]bool {
+	set := make(map[string]bool)
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			set[strings.ToLower(item)] = true
+		}
+	}
+	return set
+}
+
+// keep сообщает, исключена ли сессия из завершения.
+func (f sessionFilter) keep(s rac.SessionInfo) bool {
+	return f.apps[strings.ToLower(s.AppID)] || f.users[strings.ToLower(s.UserName)]
+}
+
+// split разделяет сессии на завершаемые и исключённые.
+func (f sessionFilter) split(sessions []rac.SessionInfo) (target, kept []rac.SessionInfo) {
+	for _, s := range sessions {
+		if f.keep(s) {
+			kept = append(kept, s)
+		} else {
+			target = append(target, s)
+		}
+	}
+	return target, kept
+}
+
+// drainMessage возвращает сообщение блокировки с обратным отсчётом до завершения сессий.
+// Сообщение не оканчивается точкой: точка в denied-message — маркер отдельной
+// блокировки регламентных заданий (см. rac.ServiceModeMessage).
+func drainMessage(deadline time.Time, drainSec int) string {
+	minutes := (drainSec + 59) / 60
+	return fmt.Sprintf("Работа с информационной базой будет завершена в %s (через %d мин). Сохраните документы и закройте программу",
+		deadline.Format("15:04"), minutes)
+}
+
+// sleepCtx ожидает d с поддержкой отмены через context.
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	select {
+	case <-time.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// drain уведомляет пользователей блокировкой сеансов с обратным отсчётом и ожидает
+// добровольного завершения сессий target, опрашивая их каждые opts.pollSec секунд.
+// Возвращает сессии, оставшиеся к концу периода drain. При ошибке возвращает
+// код для writeError; пустой код — отмена context (ошибка возвращается как есть).
+func (h *ForceDisconnectHandler) drain(ctx context.Context, log *slog.Logger, racClient rac.Client,
+	clusterUUID, infobaseUUID string, target []rac.SessionInfo, opts drainOptions, filter sessionFilter,
+	data *ForceDisconnectData) ([]rac.SessionInfo, string, error) {
+	now := time.Now()
+	deadline := now.Add(time.Duration(opts.drainSec) * time.Second)
+
+	// Сообщение блокировки показывается пользователям; если сервисный режим уже действует
+	// или запланирован, блокировку не меняем: её снятие после drain затёрло бы чужое окно.
+	// Без статуса нельзя понять, чья блокировка будет снята, поэтому ошибка прерывает drain.
+	status, err := racClient.GetServiceModeStatus(ctx, clusterUUID, infobaseUUID)
+	if err != nil {
+		return nil, "RAC.STATUS_FAILED", fmt.Errorf("не удалось проверить статус сервисного режима перед drain: %w", err)
+	}
+	if status.Active(now) || status.Scheduled(now) {
+		log.Info("Сервисный режим уже включён или запланирован, сообщение блокировки не изменяется")
+	} else {
+		scheduler, ok := racClient.(rac.ServiceModeScheduler)
+		if !ok {
+			return nil, "RAC.SCHEDULE_UNSUPPORTED", fmt.Errorf("RAC клиент не поддерживает окно сервисного режима (denied-from)")
+		}
+		// Окно начинается в deadline (в будущем), поэтому ScheduleServiceMode включает
+		// только блокировку сеансов: регламентные задания drain не блокирует.
+		data.LockMessage = drainMessage(deadline, opts.drainSec)
+		window := rac.ServiceModeWindow{From: deadline, Message: data.LockMessage}
+		if err := scheduler.ScheduleServiceMode(ctx, clusterUUID, infobaseUUID, window); err != nil {
+			return nil, "RAC.ENABLE_FAILED", fmt.Errorf("не удалось установить сообщение блокировки: %w", err)
+		}
+		log.Info("Установлена блокировка сеансов с обратным отсчётом",
+			slog.Time("denied_from", deadline),
+			slog.String("message", data.LockMessage))
+	}
+
+	sleep := h.sleep
+	if sleep == nil {
+		sleep = sleepCtx
+	}
+	remaining := target
+	for elapsed := 0; elapsed < opts.drainSec && len(remaining) > 0; {
+		step := min(opts.pollSec, opts.drainSec-elapsed)
+		if err := sleep(ctx, time.Duration(step)*time.Second); err != nil {
+			log.Warn("Drain прерван отменой context", slog.String("error", err.Error()))
+			return nil, "", err
+		}
+		elapsed += step
+
+		sessions, err := racClient.GetSessions(ctx, clusterUUID, infobaseUUID)
+		if err != nil {
+			// Опрос не критичен: продолжаем с последним известным списком
+			log.Warn("Не удалось получить список сессий при опросе", slog.String("error", err.Error()))
+			continue
+		}
+		remaining, _ = filter.split(sessions)
+
+		users := make([]string, 0, len(remaining))
+		for _, s := range remaining {
+			users = append(users, s.UserName)
+		}
+		data.Polls = append(data.Polls, DrainPoll{ElapsedSec: elapsed, Remaining: len(remaining), Users: users})
+		log.Info("Подключённые пользователи",
+			slog.Int("elapsed_sec", elapsed),
+			slog.Int("remaining", len(remaining)),
+			slog.String("users", strings.Join(users, ", ")))
+	}
+
+	still := make(map[string]bool, len(remaining))
+	for _, s := range remaining {
+		still[s.SessionID] = true
+	}
+	for _, s := range target {
+		if !still[s.SessionID] {
+			data.LeftSessionsCount++
+		}
+	}
+	return remaining, "", nil
+}
+
+// releaseLock снимает блокировку сеансов, установленную drain (data.LockMessage).
+// Окно drain не имеет окончания и аренды, поэтому после завершения сессий
+// (или при отмене context) оно отключается, а не остаётся до ручного
+// nr-service-mode-disable. Блокировка регламентных заданий снимается, если только
+// они не были заблокированы отдельно до drain (маркер, см. rac.ServiceModeMessage).
+func releaseLock(ctx context.Context, log *slog.Logger, racClient rac.Client,
+	clusterUUID, infobaseUUID string, data *ForceDisconnectData) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), releaseTimeout)
+	defer cancel()
+
+	if err := racClient.DisableServiceMode(ctx, clusterUUID, infobaseUUID); err != nil {
+		log.Error("Не удалось снять блокировку сеансов после drain", slog.String("error", err.Error()))
+		data.LockReleaseError = err.Error()
+		return
+	}
+	data.LockReleased = true
+	log.Info("Блокировка сеансов drain снята")
+}
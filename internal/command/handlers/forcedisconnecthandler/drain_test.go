@@ -0,0 +1,367 @@
+package forcedisconnecthandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac/ractest"
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/pkg/output"
+	"github.com/Kargones/apk-ci/internal/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// drainSessions — сессии бухгалтеров и сессия конфигуратора, выполняющего развёртывание.
+func drainSessions() []rac.SessionInfo {
+	return append(ractest.SessionData(),
+		rac.SessionInfo{SessionID: "session-uuid-3", UserName: "Сидорова", AppID: "1CV8C", Host: "workstation-03"},
+		rac.SessionInfo{SessionID: "session-uuid-4", UserName: "deploy", AppID: "Designer", Host: "ci-runner"},
+	)
+}
+
+// drainMock — mock, возвращающий при каждом вызове GetSessions очередной снимок сессий
+// и записывающий вызовы ScheduleServiceMode, DisableServiceMode и TerminateSession.
+type drainMock struct {
+	*ractest.MockRACClient
+
+	mu         sync.Mutex
+	snapshots  [][]rac.SessionInfo
+	windows    []rac.ServiceModeWindow
+	disabled   int
+	terminated []string
+}
+
+func newDrainMock(snapshots ...[]rac.SessionInfo) *drainMock {
+	m := &drainMock{MockRACClient: ractest.NewMockRACClient(), snapshots: snapshots}
+	m.GetSessionsFunc = func(_ context.Context, _, _ string) ([]rac.SessionInfo, error) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		s := m.snapshots[0]
+		if len(m.snapshots) > 1 {
+			m.snapshots = m.snapshots[1:]
+		}
+		return s, nil
+	}
+	m.ScheduleServiceModeFunc = func(_ context.Context, _, _ string, w rac.ServiceModeWindow) error {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.windows = append(m.windows, w)
+		return nil
+	}
+	m.DisableServiceModeFunc = func(ctx context.Context, _, _ string) error {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.disabled++
+		return ctx.Err()
+	}
+	m.TerminateSessionFunc = func(_ context.Context, _, sessionID string) error {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.terminated = append(m.terminated, sessionID)
+		return nil
+	}
+	return m
+}
+
+// noSleep — ожидание между опросами без задержки.
+func noSleep(ctx context.Context, _ time.Duration) error {
+	return ctx.Err()
+}
+
+func runJSON(t *testing.T, h *ForceDisconnectHandler) (ForceDisconnectData, error) {
+	t.Helper()
+	var execErr error
+	out := testutil.CaptureStdout(t, func() {
+		execErr = h.Execute(context.Background(), &config.Config{InfobaseName: "TestBase"})
+	})
+	var result struct {
+		Data  ForceDisconnectData `json:"data"`
+		Error *output.ErrorInfo   `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &result), out)
+	if result.Error != nil {
+		assert.Contains(t, execErr.Error(), result.Error.Code)
+	}
+	return result.Data, execErr
+}
+
+func TestSessionFilter(t *testing.T) {
+	f := newSessionFilter("designer, COMConnection", "deploy")
+	target, kept := f.split(drainSessions())
+	assert.Len(t, target, 3)
+	require.Len(t, kept, 1)
+	assert.Equal(t, "session-uuid-4", kept[0].SessionID)
+
+	assert.True(t, f.keep(rac.SessionInfo{UserName: "DEPLOY", AppID: "1CV8C"}))
+	assert.False(t, newSessionFilter("", "").keep(rac.SessionInfo{UserName: "deploy", AppID: "Designer"}))
+}
+
+func TestDrainMessage(t *testing.T) {
+	deadline := time.Date(2026, 3, 14, 18, 5, 0, 0, time.Local)
+	msg := drainMessage(deadline, 90)
+	assert.Contains(t, msg, "18:05")
+	assert.Contains(t, msg, "через 2 мин")
+}
+
+func TestForceDisconnectHandler_Execute_KeepApps(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+	t.Setenv(constants.EnvDisconnectKeepApps, "Designer")
+
+	mock := newDrainMock(drainSessions())
+	data, err := runJSON(t, &ForceDisconnectHandler{racClient: mock})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, data.TerminatedSessionsCount)
+	require.Len(t, data.KeptSessions, 1)
+	assert.Equal(t, "deploy", data.KeptSessions[0].UserName)
+	assert.NotContains(t, mock.terminated, "session-uuid-4")
+	assert.False(t, data.Drain)
+	assert.Empty(t, mock.windows, "без drain блокировка не устанавливается")
+}
+
+func TestForceDisconnectHandler_Execute_Drain(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+	t.Setenv(constants.EnvDisconnectDrainSec, "75")
+	t.Setenv(constants.EnvDisconnectPollSec, "30")
+	t.Setenv(constants.EnvDisconnectKeepApps, "Designer")
+
+	all := drainSessions()
+	mock := newDrainMock(
+		all, // исходный список
+		[]rac.SessionInfo{all[0], all[2], all[3]}, // Петров вышел
+		[]rac.SessionInfo{all[2], all[3]},         // Иванов вышел
+		[]rac.SessionInfo{all[2], all[3]},         // Сидорова не успела
+	)
+	var slept []time.Duration
+	h := &ForceDisconnectHandler{racClient: mock, sleep: func(_ context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		return nil
+	}}
+
+	before := time.Now()
+	data, err := runJSON(t, h)
+	require.NoError(t, err)
+
+	assert.Equal(t, []time.Duration{30 * time.Second, 30 * time.Second, 15 * time.Second}, slept)
+	require.Len(t, mock.windows, 1)
+	assert.WithinDuration(t, before.Add(75*time.Second), mock.windows[0].From, 5*time.Second)
+	assert.Equal(t, data.LockMessage, mock.windows[0].Message)
+	assert.Contains(t, data.LockMessage, "через 2 мин")
+
+	assert.True(t, data.Drain)
+	require.Len(t, data.Polls, 3)
+	assert.Equal(t, DrainPoll{ElapsedSec: 30, Remaining: 2, Users: []string{"Иванов", "Сидорова"}}, data.Polls[0])
+	assert.Equal(t, 75, data.Polls[2].ElapsedSec)
+	assert.Equal(t, 2, data.LeftSessionsCount)
+	assert.Equal(t, []string{"session-uuid-3"}, mock.terminated, "завершаются только оставшиеся сессии")
+	assert.Equal(t, 1, data.TerminatedSessionsCount)
+	assert.Equal(t, 1, mock.disabled, "блокировка drain снимается после завершения сессий")
+	assert.True(t, data.LockReleased)
+}
+
+func TestForceDisconnectHandler_Execute_DrainAllLeft_TextOutput(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "text")
+	t.Setenv(constants.EnvDisconnectDrainSec, "600")
+
+	mock := newDrainMock(ractest.SessionData(), nil)
+	h := &ForceDisconnectHandler{racClient: mock, sleep: noSleep}
+
+	var execErr error
+	out := testutil.CaptureStdout(t, func() {
+		execErr = h.Execute(context.Background(), &config.Config{InfobaseName: "TestBase"})
+	})
+	require.NoError(t, execErr)
+	assert.Contains(t, out, "Ожидание завершения работы пользователей: 600 сек")
+	assert.Contains(t, out, "через 30 сек: осталось 0")
+	assert.Contains(t, out, "Завершили работу самостоятельно: 2")
+	assert.Contains(t, out, "Принудительное завершение не потребовалось")
+	assert.Contains(t, out, "Блокировка сеансов снята")
+	assert.Empty(t, mock.terminated)
+}
+
+func TestForceDisconnectHandler_Execute_DrainServiceModeActive(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+	t.Setenv(constants.EnvDisconnectDrainSec, "10")
+
+	mock := newDrainMock(ractest.SessionData())
+	mock.GetServiceModeStatusFunc = func(_ context.Context, _, _ string) (*rac.ServiceModeStatus, error) {
+		return &rac.ServiceModeStatus{Enabled: true}, nil
+	}
+	data, err := runJSON(t, &ForceDisconnectHandler{racClient: mock, sleep: noSleep})
+	require.NoError(t, err)
+
+	assert.Empty(t, mock.windows, "действующая блокировка не изменяется")
+	assert.Empty(t, data.LockMessage)
+	assert.Equal(t, 2, data.TerminatedSessionsCount)
+	assert.Zero(t, mock.disabled, "чужой сервисный режим не отключается")
+}
+
+func TestForceDisconnectHandler_Execute_DrainServiceModeScheduled(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+	t.Setenv(constants.EnvDisconnectDrainSec, "10")
+
+	mock := newDrainMock(ractest.SessionData())
+	mock.GetServiceModeStatusFunc = func(_ context.Context, _, _ string) (*rac.ServiceModeStatus, error) {
+		return &rac.ServiceModeStatus{Enabled: true, DeniedFrom: time.Now().Add(3 * time.Hour)}, nil
+	}
+	_, err := runJSON(t, &ForceDisconnectHandler{racClient: mock, sleep: noSleep})
+	require.NoError(t, err)
+
+	assert.Empty(t, mock.windows, "запланированное окно не изменяется")
+	assert.Zero(t, mock.disabled)
+}
+
+func TestForceDisconnectHandler_Execute_DrainStatusFailed(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+	t.Setenv(constants.EnvDisconnectDrainSec, "10")
+
+	mock := newDrainMock(ractest.SessionData())
+	mock.GetServiceModeStatusFunc = func(_ context.Context, _, _ string) (*rac.ServiceModeStatus, error) {
+		return nil, errors.New("rac недоступен")
+	}
+	_, err := runJSON(t, &ForceDisconnectHandler{racClient: mock, sleep: noSleep})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "RAC.STATUS_FAILED")
+	assert.Empty(t, mock.windows)
+	assert.Empty(t, mock.terminated)
+}
+
+func TestForceDisconnectHandler_Execute_DrainReleaseFailed(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "text")
+	t.Setenv(constants.EnvDisconnectDrainSec, "10")
+
+	mock := newDrainMock(ractest.SessionData())
+	mock.DisableServiceModeFunc = func(context.Context, string, string) error {
+		return errors.New("rac недоступен")
+	}
+	h := &ForceDisconnectHandler{racClient: mock, sleep: noSleep}
+
+	var execErr error
+	out := testutil.CaptureStdout(t, func() {
+		execErr = h.Execute(context.Background(), &config.Config{InfobaseName: "TestBase"})
+	})
+	require.NoError(t, execErr)
+	assert.Contains(t, out, "⚠ Блокировка сеансов не снята: rac недоступен")
+}
+
+// statefulRACScript — mock RAC, хранящий состояние блокировки информационной базы
+// в файлах каталога %[1]s: infobase update изменяет его, infobase info выводит.
+// Сессия Иванова подключена, пока сообщение блокировки не установлено.
+const statefulRACScript = `#!/bin/sh
+state=%[1]s
+shift
+echo "$@" >> "$state/args.log"
+case "$1 $2" in
+"cluster list")
+  printf 'cluster : cluster-uuid\nhost    : localhost\nport    : 1541\nname    : "Local"\n' ;;
+"infobase summary")
+  printf 'infobase : infobase-uuid\nname     : TestBase\n' ;;
+"infobase info")
+  printf 'infobase            : infobase-uuid\nsessions-deny       : %%s\nscheduled-jobs-deny : %%s\ndenied-message      : "%%s"\n' \
+    "$(cat "$state/sessions-deny")" "$(cat "$state/scheduled-jobs-deny")" "$(cat "$state/denied-message")" ;;
+"session list")
+  if [ ! -s "$state/denied-message" ]; then
+    printf 'session   : session-uuid-1\nuser-name : Иванов\napp-id    : 1CV8C\nhost      : workstation-01\n'
+  fi ;;
+"infobase update")
+  for a in "$@"; do
+    case "$a" in
+    --sessions-deny=*|--scheduled-jobs-deny=*|--denied-message=*)
+      k=${a%%%%=*}; printf '%%s' "${a#*=}" > "$state/${k#--}" ;;
+    esac
+  done ;;
+esac
+`
+
+// newStatefulRAC создаёт RAC клиент поверх statefulRACScript с исходным состоянием
+// без блокировок и возвращает его вместе с каталогом состояния.
+func newStatefulRAC(t *testing.T) (rac.Client, string) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, value := range map[string]string{"sessions-deny": "off", "scheduled-jobs-deny": "off", "denied-message": ""} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(value), 0o600))
+	}
+	racPath := filepath.Join(dir, "mock-rac")
+	script := fmt.Sprintf(statefulRACScript, dir)
+	require.NoError(t, os.WriteFile(racPath, []byte(script), 0o755)) //nolint:gosec // тестовый скрипт
+	client, err := rac.NewClient(rac.ClientOptions{RACPath: racPath, Server: "localhost"})
+	require.NoError(t, err)
+	return client, dir
+}
+
+func TestForceDisconnectHandler_Execute_DrainReleasesScheduledJobs(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+	t.Setenv(constants.EnvDisconnectDrainSec, "60")
+
+	client, dir := newStatefulRAC(t)
+	data, err := runJSON(t, &ForceDisconnectHandler{racClient: client, sleep: noSleep})
+	require.NoError(t, err)
+	require.True(t, data.LockReleased)
+	assert.Equal(t, 1, data.LeftSessionsCount)
+
+	raw, err := os.ReadFile(filepath.Join(dir, "args.log")) //nolint:gosec // тестовый файл
+	require.NoError(t, err)
+	var updates []string
+	for _, line := range strings.Split(strings.TrimSpace(string(raw)), "\n") {
+		if strings.HasPrefix(line, "infobase update") {
+			updates = append(updates, line)
+		}
+	}
+	require.Len(t, updates, 2, "установка и снятие блокировки drain")
+	assert.Contains(t, updates[0], "--sessions-deny=on")
+	assert.NotContains(t, updates[0], "--scheduled-jobs-deny", "drain блокирует только сеансы")
+	assert.NotContains(t, updates[0], "программу.", "сообщение drain не содержит маркер")
+	assert.Contains(t, updates[1], "--sessions-deny=off")
+	assert.Contains(t, updates[1], "--scheduled-jobs-deny=off", "маркер не оставляет задания заблокированными")
+}
+
+func TestForceDisconnectHandler_Execute_DrainCancelled(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+	t.Setenv(constants.EnvDisconnectDrainSec, "60")
+
+	mock := newDrainMock(ractest.SessionData())
+	h := &ForceDisconnectHandler{racClient: mock}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var execErr error
+	testutil.CaptureStdout(t, func() {
+		execErr = h.Execute(ctx, &config.Config{InfobaseName: "TestBase"})
+	})
+	require.ErrorIs(t, execErr, context.Canceled)
+	assert.Empty(t, mock.terminated)
+	assert.Equal(t, 1, mock.disabled, "блокировка снимается и при отмене")
+}
+
+func TestForceDisconnectHandler_Execute_InvalidDrain(t *testing.T) {
+	tests := []struct {
+		env, value string
+	}{
+		{constants.EnvDisconnectDrainSec, "-1"},
+		{constants.EnvDisconnectDrainSec, "7200"},
+		{constants.EnvDisconnectDrainSec, "abc"},
+		{constants.EnvDisconnectPollSec, "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.env+"="+tt.value, func(t *testing.T) {
+			t.Setenv("BR_OUTPUT_FORMAT", "json")
+			t.Setenv(tt.env, tt.value)
+
+			_, err := runJSON(t, &ForceDisconnectHandler{racClient: newDrainMock(ractest.SessionData())})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "CONFIG.INVALID_DRAIN")
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"log/slog"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
@@ -59,6 +60,22 @@ type ForceDisconnectData struct {
 	Sessions []DisconnectedSessionInfo `json:"sessions"`
 	// Errors — список ошибок при завершении сессий
 	Errors []string `json:"errors"`
+	// KeptSessions — сессии, исключённые из завершения (BR_DISCONNECT_KEEP_APPS/BR_DISCONNECT_KEEP_USERS)
+	KeptSessions []DisconnectedSessionInfo `json:"kept_sessions"`
+	// Drain — использован режим мягкого завершения (BR_DISCONNECT_DRAIN_SEC)
+	Drain bool `json:"drain"`
+	// DrainSec — период ожидания добровольного завершения сессий в секундах
+	DrainSec int `json:"drain_sec,omitempty"`
+	// LockMessage — установленное сообщение блокировки с обратным отсчётом
+	LockMessage string `json:"lock_message,omitempty"`
+	// LockReleased — блокировка сеансов drain снята после завершения сессий
+	LockReleased bool `json:"lock_released,omitempty"`
+	// LockReleaseError — ошибка снятия блокировки drain (вход в базу остаётся запрещён)
+	LockReleaseError string `json:"lock_release_error,omitempty"`
+	// Polls — результаты опроса сессий в режиме drain
+	Polls []DrainPoll `json:"polls,omitempty"`
+	// LeftSessionsCount — количество сессий, завершённых пользователями за период drain
+	LeftSessionsCount int `json:"left_sessions_count"`
 }
 
 // writeText выводит результат принудительного завершения сессий в человекочитаемом формате.
@@ -68,11 +85,40 @@ func (d *ForceDisconnectData) writeText(w io.Writer) error {
 	}
 
 	if d.NoActiveSessions {
-		_, err := fmt.Fprintln(w, "Активных сессий нет")
-		return err
+		text := "Активных сессий нет"
+		if len(d.KeptSessions) > 0 {
+			text = "Сессий для завершения нет"
+		}
+		if _, err := fmt.Fprintln(w, text); err != nil {
+			return err
+		}
+		return writeSessions(w, "Исключены из завершения:", d.KeptSessions)
+	}
+
+	if d.Drain {
+		if _, err := fmt.Fprintf(w, "Ожидание завершения работы пользователей: %d сек\n", d.DrainSec); err != nil {
+			return err
+		}
+		if d.LockMessage != "" {
+			if _, err := fmt.Fprintf(w, "Сообщение блокировки: \"%s\"\n", d.LockMessage); err != nil {
+				return err
+			}
+		}
+		for _, p := range d.Polls {
+			if _, err := fmt.Fprintf(w, "  через %d сек: осталось %d %s\n", p.ElapsedSec, p.Remaining, strings.Join(p.Users, ", ")); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "Завершили работу самостоятельно: %d\n", d.LeftSessionsCount); err != nil {
+			return err
+		}
 	}
 
 	switch {
+	case d.Drain && len(d.Sessions) == 0 && len(d.Errors) == 0:
+		if _, err := fmt.Fprintln(w, "Принудительное завершение не потребовалось"); err != nil {
+			return err
+		}
 	case !d.StateChanged:
 		if _, err := fmt.Fprintln(w, "Состояние не изменено: не удалось завершить ни одну сессию"); err != nil {
 			return err
@@ -94,6 +140,10 @@ func (d *ForceDisconnectData) writeText(w io.Writer) error {
 		}
 	}
 
+	if err := writeSessions(w, "Исключены из завершения:", d.KeptSessions); err != nil {
+		return err
+	}
+
 	if len(d.Errors) > 0 {
 		if _, err := fmt.Fprintln(w, "Ошибки:"); err != nil {
 			return err
@@ -105,13 +155,60 @@ func (d *ForceDisconnectData) writeText(w io.Writer) error {
 		}
 	}
 
+	switch {
+	case d.LockReleased:
+		_, err := fmt.Fprintln(w, "Блокировка сеансов снята")
+		return err
+	case d.LockReleaseError != "":
+		_, err := fmt.Fprintf(w, "⚠ Блокировка сеансов не снята: %s (отключите nr-service-mode-disable)\n", d.LockReleaseError)
+		return err
+	}
 	return nil
 }
 
+// writeSessions выводит список сессий с заголовком title (ничего для пустого списка).
+func writeSessions(w io.Writer, title string, sessions []DisconnectedSessionInfo) error {
+	if len(sessions) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprintln(w, title); err != nil {
+		return err
+	}
+	for i, s := range sessions {
+		if _, err := fmt.Fprintf(w, "  %d. %s (%s) — %s\n", i+1, s.UserName, s.AppID, s.Host); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sessionInfo преобразует сессию RAC в DisconnectedSessionInfo.
+func sessionInfo(s rac.SessionInfo) DisconnectedSessionInfo {
+	startedAt := ""
+	if !s.StartedAt.IsZero() {
+		startedAt = s.StartedAt.Format(time.RFC3339)
+	}
+	return DisconnectedSessionInfo{
+		UserName:  s.UserName,
+		AppID:     s.AppID,
+		Host:      s.Host,
+		SessionID: s.SessionID,
+		StartedAt: startedAt,
+	}
+}
+
 // ForceDisconnectHandler обрабатывает команду nr-force-disconnect-sessions.
+//
+// Режим drain (BR_DISCONNECT_DRAIN_SEC > 0): устанавливает блокировку сеансов
+// с сообщением об обратном отсчёте, опрашивает сессии каждые BR_DISCONNECT_POLL_SEC,
+// по окончании периода завершает только оставшиеся сессии и снимает блокировку.
+// Сессии приложений BR_DISCONNECT_KEEP_APPS и пользователей BR_DISCONNECT_KEEP_USERS
+// не завершаются в обоих режимах.
 type ForceDisconnectHandler struct {
 	// racClient — опциональный RAC клиент (nil в production, mock в тестах)
 	racClient rac.Client
+	// sleep — опциональная функция ожидания между опросами drain (nil в production, mock в тестах)
+	sleep func(ctx context.Context, d time.Duration) error
 }
 
 // Name возвращает имя команды.
@@ -167,10 +264,16 @@ func (h *ForceDisconnectHandler) Execute(ctx context.Context, cfg *config.Config
 		}
 	}
 
+	drainOpts, err := drainOptionsFromEnv()
+	if err != nil {
+		log.Error("Некорректные параметры drain", slog.String("error", err.Error()))
+		return h.writeError(format, traceID, start, "CONFIG.INVALID_DRAIN", err.Error())
+	}
+	filter := newSessionFilter(os.Getenv(constants.EnvDisconnectKeepApps), os.Getenv(constants.EnvDisconnectKeepUsers))
+
 	// Получение или создание RAC клиента
 	racClient := h.racClient
 	if racClient == nil {
-		var err error
 		racClient, err = racutil.NewClient(cfg)
 		if err != nil {
 			log.Error("Не удалось создать RAC клиент", slog.String("error", err.Error()))
@@ -210,6 +313,16 @@ func (h *ForceDisconnectHandler) Execute(ctx context.Context, cfg *config.Config
 	// Логирование текущего состояния: количество активных сессий
 	log.Info("Текущее состояние: активных сессий", slog.Int("count", len(sessions)))
 
+	sessions, kept := filter.split(sessions)
+	keptSessions := make([]DisconnectedSessionInfo, 0, len(kept))
+	for _, s := range kept {
+		log.Info("Сессия исключена из завершения",
+			slog.String("user", s.UserName),
+			slog.String("app", s.AppID),
+			slog.String("session_id", s.SessionID))
+		keptSessions = append(keptSessions, sessionInfo(s))
+	}
+
 	// Идемпотентность: нет активных сессий
 	if len(sessions) == 0 {
 		log.Info("Активных сессий нет")
@@ -221,10 +334,19 @@ func (h *ForceDisconnectHandler) Execute(ctx context.Context, cfg *config.Config
 			InfobaseName:            cfg.InfobaseName,
 			Sessions:                make([]DisconnectedSessionInfo, 0),
 			Errors:                  make([]string, 0),
+			KeptSessions:            keptSessions,
 		}
 		return h.outputResult(format, data, traceID, start)
 	}
 
+	data := &ForceDisconnectData{
+		DelaySec:     delaySec,
+		InfobaseName: cfg.InfobaseName,
+		KeptSessions: keptSessions,
+		Drain:        drainOpts.drainSec > 0,
+		DrainSec:     drainOpts.drainSec,
+	}
+
 	// Логирование сессий для аудита (перед завершением)
 	log.Info("Найдены активные сессии для завершения", slog.Int("count", len(sessions)))
 	for _, s := range sessions {
@@ -235,8 +357,27 @@ func (h *ForceDisconnectHandler) Execute(ctx context.Context, cfg *config.Config
 			slog.String("session_id", s.SessionID))
 	}
 
-	// Grace period с поддержкой отмены через context
-	if delaySec > 0 {
+	// Drain: уведомление пользователей и ожидание добровольного завершения сессий
+	if data.Drain {
+		log.Info("Мягкое завершение сессий",
+			slog.Int("drain_sec", drainOpts.drainSec),
+			slog.Int("poll_sec", drainOpts.pollSec))
+		var code string
+		sessions, code, err = h.drain(ctx, log, racClient, clusterInfo.UUID, infobaseInfo.UUID, sessions, drainOpts, filter, data)
+		if err != nil && data.LockMessage != "" {
+			releaseLock(ctx, log, racClient, clusterInfo.UUID, infobaseInfo.UUID, data)
+		}
+		if err != nil {
+			if code == "" {
+				return err
+			}
+			log.Error("Ошибка мягкого завершения сессий", slog.String("error", err.Error()))
+			return h.writeError(format, traceID, start, code, err.Error())
+		}
+	}
+
+	// Grace period с поддержкой отмены через context (в режиме drain не используется)
+	if delaySec > 0 && !data.Drain {
 		log.Info("Ожидание grace period", slog.Int("delay_sec", delaySec))
 		select {
 		case <-time.After(time.Duration(delaySec) * time.Second):
@@ -257,17 +398,7 @@ func (h *ForceDisconnectHandler) Execute(ctx context.Context, cfg *config.Config
 				slog.String("user", s.UserName),
 				slog.String("error", err.Error()))
 		} else {
-			startedAt := ""
-			if !s.StartedAt.IsZero() {
-				startedAt = s.StartedAt.Format(time.RFC3339)
-			}
-			terminated = append(terminated, DisconnectedSessionInfo{
-				UserName:  s.UserName,
-				AppID:     s.AppID,
-				Host:      s.Host,
-				SessionID: s.SessionID,
-				StartedAt: startedAt,
-			})
+			terminated = append(terminated, sessionInfo(s))
 		}
 	}
 
@@ -275,17 +406,16 @@ func (h *ForceDisconnectHandler) Execute(ctx context.Context, cfg *config.Config
 		slog.Int("terminated", len(terminated)),
 		slog.Int("errors", len(terminateErrors)))
 
-	data := &ForceDisconnectData{
-		TerminatedSessionsCount: len(terminated),
-		NoActiveSessions:        false,
-		StateChanged:            len(terminated) > 0,
-		DelaySec:                delaySec,
-		InfobaseName:            cfg.InfobaseName,
-		PartialFailure:          len(terminateErrors) > 0 && len(terminated) > 0,
-		Sessions:                terminated,
-		Errors:                  terminateErrors,
+	if data.LockMessage != "" {
+		releaseLock(ctx, log, racClient, clusterInfo.UUID, infobaseInfo.UUID, data)
 	}
 
+	data.TerminatedSessionsCount = len(terminated)
+	data.StateChanged = len(terminated) > 0
+	data.PartialFailure = len(terminateErrors) > 0 && len(terminated) > 0
+	data.Sessions = terminated
+	data.Errors = terminateErrors
+
 	return h.outputResult(format, data, traceID, start)
 }
 
@@ -0,0 +1,293 @@
+// Package git2storehandler — checkpoint для продолжения git2store с последнего завершённого этапа.
+package git2storehandler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+)
+
+// checkpointVersion — версия формата файла checkpoint.
+const checkpointVersion = 1
+
+// checkpointDir — каталог checkpoint-файлов внутри WorkDir.
+const checkpointDir = "checkpoints"
+
+// alwaysRerunStages — этапы без внешних артефактов, которые выполняются при каждом запуске:
+// validating проверяет текущую конфигурацию, loading_config заполняет состояние
+// ConvertConfigOperator в памяти, необходимое последующим этапам.
+var alwaysRerunStages = []string{StageValidating, StageLoadingConfig}
+
+// Checkpoint — сохранённое состояние прогона git2store (ключ: owner/repo/commit).
+//
+// Ведётся только при BR_RESUME=true. Файл обновляется после каждого успешного
+// этапа и удаляется при успешном завершении. После ошибки клон репозитория и
+// временная БД не удаляются, чтобы повторный запуск с BR_RESUME=true мог
+// продолжить с первого незавершённого этапа.
+type Checkpoint struct {
+	Version      int    `json:"version"`
+	Owner        string `json:"owner"`
+	Repo         string `json:"repo"`
+	Commit       string `json:"commit"`
+	InfobaseName string `json:"infobase_name"`
+	// CompletedStages — успешно завершённые этапы в порядке выполнения.
+	CompletedStages []string `json:"completed_stages"`
+	// RepPath — каталог клона репозитория (этап cloning).
+	RepPath string `json:"rep_path,omitempty"`
+	// BackupPath — путь к backup хранилища (этап creating_backup).
+	BackupPath string `json:"backup_path,omitempty"`
+	// TempDbPath — путь к временной файловой БД (этап creating_temp_db).
+	TempDbPath string `json:"temp_db_path,omitempty"`
+	// TempDbConnect — строка подключения к временной БД.
+	TempDbConnect string `json:"temp_db_connect,omitempty"`
+	// UpdatedAt — время последнего обновления.
+	UpdatedAt time.Time `json:"updated_at"`
+
+	path string
+}
+
+// isResume проверяет включён ли режим продолжения (BR_RESUME).
+func isResume() bool {
+	val := os.Getenv(constants.EnvResume)
+	return strings.EqualFold(val, "true") || val == "1"
+}
+
+// checkpointCommit возвращает коммит, к которому привязан checkpoint.
+// Ветка не подходит: между запусками она может сдвинуться, и завершённые этапы
+// относились бы к другому состоянию репозитория — поэтому BR_RESUME требует хеш коммита (INPUT_COMMITHASH).
+func checkpointCommit(cfg *config.Config) string {
+	return cfg.CommitHash
+}
+
+// checkpointPath возвращает путь к файлу checkpoint для owner/repo/commit.
+func checkpointPath(cfg *config.Config) string {
+	sanitize := strings.NewReplacer("/", "_", "\\", "_", "..", "_", ":", "_", " ", "_")
+	name := fmt.Sprintf("git2store_%s_%s_%s.json",
+		sanitize.Replace(cfg.Owner), sanitize.Replace(cfg.Repo), sanitize.Replace(checkpointCommit(cfg)))
+	return filepath.Join(cfg.WorkDir, checkpointDir, name)
+}
+
+// newCheckpoint создаёт пустой checkpoint для текущего прогона.
+func newCheckpoint(cfg *config.Config) *Checkpoint {
+	return &Checkpoint{
+		Version:         checkpointVersion,
+		Owner:           cfg.Owner,
+		Repo:            cfg.Repo,
+		Commit:          checkpointCommit(cfg),
+		InfobaseName:    cfg.InfobaseName,
+		CompletedStages: make([]string, 0, len(allStages)),
+		path:            checkpointPath(cfg),
+	}
+}
+
+// loadCheckpoint читает checkpoint из файла. Возвращает (nil, nil) если файла нет.
+func loadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // путь формируется из WorkDir
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("не удалось прочитать checkpoint: %w", err)
+	}
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("некорректный checkpoint %s: %w", path, err)
+	}
+	cp.path = path
+	return &cp, nil
+}
+
+// save атомарно записывает checkpoint (временный файл + rename).
+func (c *Checkpoint) save() error {
+	c.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("не удалось сериализовать checkpoint: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), constants.DirPermStandard); err != nil {
+		return fmt.Errorf("не удалось создать каталог checkpoint: %w", err)
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, constants.FilePermReadWrite); err != nil {
+		return fmt.Errorf("не удалось записать checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		_ = os.Remove(tmp) //nolint:errcheck // best-effort очистка
+		return fmt.Errorf("не удалось записать checkpoint: %w", err)
+	}
+	return nil
+}
+
+// remove удаляет файл checkpoint.
+func (c *Checkpoint) remove() error {
+	if err := os.Remove(c.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// isDone проверяет завершён ли этап в сохранённом прогоне.
+func (c *Checkpoint) isDone(stage string) bool {
+	return slices.Contains(c.CompletedStages, stage)
+}
+
+// markDone отмечает этап завершённым и сохраняет checkpoint.
+func (c *Checkpoint) markDone(stage string) error {
+	if !c.isDone(stage) {
+		c.CompletedStages = append(c.CompletedStages, stage)
+	}
+	return c.save()
+}
+
+// matches проверяет, что checkpoint относится к тому же репозиторию, коммиту и базе.
+func (c *Checkpoint) matches(cfg *config.Config) error {
+	if c.Version != checkpointVersion {
+		return fmt.Errorf("версия checkpoint %d не поддерживается", c.Version)
+	}
+	if c.Owner != cfg.Owner || c.Repo != cfg.Repo || c.Commit != checkpointCommit(cfg) {
+		return fmt.Errorf("checkpoint относится к %s/%s@%s", c.Owner, c.Repo, c.Commit)
+	}
+	if c.InfobaseName != cfg.InfobaseName {
+		return fmt.Errorf("checkpoint создан для базы %s", c.InfobaseName)
+	}
+	return nil
+}
+
+// truncateInvalid оставляет только завершённые этапы, артефакты которых существуют.
+// Этапы выполняются последовательно, поэтому первый этап с отсутствующим
+// артефактом и все последующие будут выполнены заново.
+// Возвращает имя первого отброшенного этапа и причину (пусто, если все валидны).
+func (c *Checkpoint) truncateInvalid() (string, string) {
+	for i, stage := range c.CompletedStages {
+		if reason := c.artifactMissing(stage); reason != "" {
+			c.CompletedStages = c.CompletedStages[:i]
+			return stage, reason
+		}
+	}
+	return "", ""
+}
+
+// artifactMissing проверяет артефакт этапа и возвращает причину, если он недоступен.
+// Для серверной БД (не LocalBase) наличие привязки к хранилищу не проверяется —
+// она проверяется 1cv8 на следующем этапе.
+func (c *Checkpoint) artifactMissing(stage string) string {
+	switch stage {
+	case StageCreatingBackup:
+		return pathMissing("backup хранилища", c.BackupPath)
+	case StageCloning:
+		return pathMissing("клон репозитория", c.RepPath)
+	case StageCreatingTempDb:
+		if c.TempDbConnect == "" {
+			return "не сохранена строка подключения к временной БД"
+		}
+		return pathMissing("временная БД", c.TempDbPath)
+	case StageBinding, StageUpdatingDb2, StageLocking, StageMerging, StageUpdatingDb3:
+		if c.isDone(StageCreatingTempDb) {
+			return pathMissing("привязанная временная БД", c.TempDbPath)
+		}
+	}
+	return ""
+}
+
+// pathMissing возвращает причину, если путь не задан или не существует.
+func pathMissing(what, path string) string {
+	if path == "" {
+		return what + ": путь не сохранён"
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Sprintf("%s недоступен (%s): %v", what, path, err)
+	}
+	return ""
+}
+
+// prepareCheckpoint возвращает checkpoint для текущего прогона.
+//
+// Без BR_RESUME checkpoint не ведётся (nil): артефакты удаляются и после ошибки,
+// а существующий checkpoint для того же ключа считается устаревшим — его клон
+// и временная БД удаляются. С BR_RESUME=true требуется хеш коммита;
+// checkpoint загружается, проверяется соответствие owner/repo/commit/infobase
+// и наличие артефактов завершённых этапов.
+func (h *Git2StoreHandler) prepareCheckpoint(cfg *config.Config, log *slog.Logger) (*Checkpoint, error) {
+	if isResume() && cfg.CommitHash == "" {
+		return nil, fmt.Errorf("ERR_GIT2STORE_RESUME: %s требует хеш коммита (INPUT_COMMITHASH): "+
+			"без него нельзя проверить, что прогон продолжается для того же состояния репозитория", constants.EnvResume)
+	}
+	if cfg.CommitHash == "" {
+		return nil, nil
+	}
+
+	fresh := newCheckpoint(cfg)
+	prev, err := loadCheckpoint(fresh.path)
+	if !isResume() {
+		if prev != nil {
+			prev.cleanupArtifacts(log)
+		}
+		return nil, nil
+	}
+	if err != nil {
+		log.Warn("Checkpoint не загружен, прогон начинается с начала", slog.String("error", err.Error()))
+		return fresh, nil
+	}
+	if prev == nil {
+		log.Warn("BR_RESUME: checkpoint не найден, прогон начинается с начала",
+			slog.String("checkpoint", fresh.path))
+		return fresh, nil
+	}
+
+	if err := prev.matches(cfg); err != nil {
+		log.Warn("BR_RESUME: checkpoint не подходит, прогон начинается с начала",
+			slog.String("checkpoint", prev.path), slog.String("reason", err.Error()))
+		prev.cleanupArtifacts(log)
+		return fresh, nil
+	}
+	if stage, reason := prev.truncateInvalid(); stage != "" {
+		log.Warn("BR_RESUME: артефакт этапа недоступен, этап и последующие будут выполнены заново",
+			slog.String("stage", stage), slog.String("reason", reason))
+	}
+	log.Info("BR_RESUME: продолжение прогона git2store",
+		slog.String("checkpoint", prev.path),
+		slog.Any("completed_stages", prev.CompletedStages))
+	return prev, nil
+}
+
+// cleanupArtifacts удаляет клон и временную БД устаревшего прогона. Backup не удаляется.
+func (c *Checkpoint) cleanupArtifacts(log *slog.Logger) {
+	for _, path := range []string{c.RepPath, c.TempDbPath} {
+		if path == "" {
+			continue
+		}
+		if err := os.RemoveAll(path); err != nil {
+			log.Warn("Не удалось удалить артефакт устаревшего checkpoint",
+				slog.String("path", path), slog.String("error", err.Error()))
+		}
+	}
+	if err := c.remove(); err != nil {
+		log.Warn("Не удалось удалить устаревший checkpoint",
+			slog.String("path", c.path), slog.String("error", err.Error()))
+	}
+}
+
+// resumeStage проверяет, завершён ли этап в загруженном checkpoint.
+// Для завершённого этапа записывает результат с признаком resumed и возвращает true.
+func (h *Git2StoreHandler) resumeStage(data *Git2StoreData, stageName string, log *slog.Logger) bool {
+	if h.checkpoint == nil || slices.Contains(alwaysRerunStages, stageName) || !h.checkpoint.isDone(stageName) {
+		return false
+	}
+	log.Info(stageName + ": пропущен, завершён в предыдущем прогоне (BR_RESUME)")
+	data.StageCurrent = stageName
+	data.StagesCompleted = append(data.StagesCompleted, StageResult{
+		Name:    stageName,
+		Success: true,
+		Resumed: true,
+	})
+	return true
+}
@@ -0,0 +1,287 @@
+package git2storehandler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+)
+
+// executeQuiet выполняет handler с подавлением вывода в stdout.
+func executeQuiet(t *testing.T, h *Git2StoreHandler, cfg *config.Config) error {
+	t.Helper()
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	done := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(io.Discard, r)
+		close(done)
+	}()
+
+	err := h.Execute(context.Background(), cfg)
+
+	_ = w.Close()
+	<-done
+	os.Stdout = oldStdout
+	return err
+}
+
+// resumeTestEnv — handler с моками, подсчитывающими вызовы.
+type resumeTestEnv struct {
+	h          *Git2StoreHandler
+	clones     int
+	backups    int
+	commits    int
+	loads      int
+	initDbs    int
+	failCommit bool
+}
+
+func newResumeTestEnv(t *testing.T) *resumeTestEnv {
+	t.Helper()
+	env := &resumeTestEnv{}
+	backupDir := t.TempDir()
+	gitOp := &mockGitOperator{
+		cloneFunc: func(ctx context.Context, l *slog.Logger) error {
+			env.clones++
+			return nil
+		},
+	}
+	ccOp := &mockConvertConfigOperator{
+		loadFunc: func(ctx context.Context, l *slog.Logger, cfg *config.Config, infobaseName string) error {
+			env.loads++
+			return nil
+		},
+		initDbFunc: func(ctx context.Context, l *slog.Logger, cfg *config.Config) error {
+			env.initDbs++
+			return nil
+		},
+		storeCommitFunc: func(ctx context.Context, l *slog.Logger, cfg *config.Config) error {
+			env.commits++
+			if env.failCommit {
+				return errors.New("commit failed")
+			}
+			return nil
+		},
+	}
+	env.h = &Git2StoreHandler{
+		gitFactory:           &mockGitFactory{gitOp: gitOp},
+		convertConfigFactory: &mockConvertConfigFactory{ccOp: ccOp},
+		backupCreator: &mockBackupCreator{
+			createBackupFunc: func(cfg *config.Config, storeRoot string) (string, error) {
+				env.backups++
+				return backupDir, nil
+			},
+		},
+	}
+	return env
+}
+
+// TestGit2StoreHandler_Resume_FromFailedStage проверяет продолжение с этапа, на котором прогон упал.
+func TestGit2StoreHandler_Resume_FromFailedStage(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "")
+	t.Setenv(constants.EnvResume, "true")
+	cfg := createTestConfig(t)
+	cfg.CommitHash = "abc123"
+	env := newResumeTestEnv(t)
+	env.failCommit = true
+
+	if err := executeQuiet(t, env.h, cfg); err == nil {
+		t.Fatal("ожидалась ошибка на этапе committing")
+	}
+
+	cp, err := loadCheckpoint(checkpointPath(cfg))
+	if err != nil || cp == nil {
+		t.Fatalf("checkpoint должен быть сохранён после ошибки: %v", err)
+	}
+	// После отката объекты освобождены: этапы с locking выполняются заново.
+	if cp.isDone(StageCommitting) || cp.isDone(StageLocking) || !cp.isDone(StageUpdatingDb2) {
+		t.Errorf("неожиданный список завершённых этапов: %v", cp.CompletedStages)
+	}
+	if slices.Contains(cp.CompletedStages, StageLoadingConfig) {
+		t.Error("loading_config не должен сохраняться в checkpoint")
+	}
+	repPath := cp.RepPath
+	if _, err := os.Stat(repPath); err != nil {
+		t.Fatalf("клон должен сохраняться после ошибки: %v", err)
+	}
+
+	// Повторный запуск с BR_RESUME=true
+	env.failCommit = false
+	cfg.RepPath = ""
+	if err := executeQuiet(t, env.h, cfg); err != nil {
+		t.Fatalf("Execute() с BR_RESUME вернул ошибку: %v", err)
+	}
+
+	if env.clones != 1 || env.backups != 1 || env.initDbs != 1 {
+		t.Errorf("завершённые этапы не должны повторяться: clones=%d backups=%d initDbs=%d",
+			env.clones, env.backups, env.initDbs)
+	}
+	if env.loads != 2 {
+		t.Errorf("loading_config должен выполняться при каждом запуске, got %d", env.loads)
+	}
+	if env.commits != 2 {
+		t.Errorf("committing должен быть выполнен повторно, got %d", env.commits)
+	}
+	if cfg.RepPath != repPath {
+		t.Errorf("должен использоваться клон из checkpoint: %q != %q", cfg.RepPath, repPath)
+	}
+	if _, err := os.Stat(checkpointPath(cfg)); !os.IsNotExist(err) {
+		t.Error("checkpoint должен удаляться после успешного завершения")
+	}
+	if _, err := os.Stat(repPath); !os.IsNotExist(err) {
+		t.Error("клон должен удаляться после успешного завершения")
+	}
+}
+
+// TestGit2StoreHandler_Resume_MissingClone проверяет повторное выполнение этапов при потере клона.
+func TestGit2StoreHandler_Resume_MissingClone(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "")
+	t.Setenv(constants.EnvResume, "true")
+	cfg := createTestConfig(t)
+	cfg.CommitHash = "abc123"
+	env := newResumeTestEnv(t)
+	env.failCommit = true
+	_ = executeQuiet(t, env.h, cfg)
+
+	cp, _ := loadCheckpoint(checkpointPath(cfg))
+	if cp == nil {
+		t.Fatal("checkpoint должен быть сохранён")
+	}
+	if err := os.RemoveAll(cp.RepPath); err != nil {
+		t.Fatal(err)
+	}
+
+	env.failCommit = false
+	if err := executeQuiet(t, env.h, cfg); err != nil {
+		t.Fatalf("Execute() вернул ошибку: %v", err)
+	}
+	if env.backups != 1 {
+		t.Errorf("backup существует и не должен пересоздаваться, got %d", env.backups)
+	}
+	if env.clones != 2 || env.initDbs != 2 {
+		t.Errorf("этапы после cloning должны выполняться заново: clones=%d initDbs=%d", env.clones, env.initDbs)
+	}
+}
+
+// TestGit2StoreHandler_NoResume_DiscardsStaleCheckpoint проверяет, что без BR_RESUME
+// устаревший checkpoint удаляется вместе с клоном.
+func TestGit2StoreHandler_NoResume_DiscardsStaleCheckpoint(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "")
+	t.Setenv(constants.EnvResume, "true")
+	cfg := createTestConfig(t)
+	cfg.CommitHash = "abc123"
+	env := newResumeTestEnv(t)
+	env.failCommit = true
+	_ = executeQuiet(t, env.h, cfg)
+
+	cp, _ := loadCheckpoint(checkpointPath(cfg))
+	if cp == nil {
+		t.Fatal("checkpoint должен быть сохранён")
+	}
+	staleRepPath := cp.RepPath
+
+	t.Setenv(constants.EnvResume, "")
+	env.failCommit = false
+	if err := executeQuiet(t, env.h, cfg); err != nil {
+		t.Fatalf("Execute() вернул ошибку: %v", err)
+	}
+	if env.clones != 2 || env.backups != 2 {
+		t.Errorf("без BR_RESUME прогон должен начинаться с начала: clones=%d backups=%d", env.clones, env.backups)
+	}
+	if _, err := os.Stat(staleRepPath); !os.IsNotExist(err) {
+		t.Error("клон устаревшего прогона должен быть удалён")
+	}
+}
+
+// TestGit2StoreHandler_NoResume_CleansUpAfterFailure проверяет, что без BR_RESUME
+// клон удаляется и после ошибки, а checkpoint не сохраняется.
+func TestGit2StoreHandler_NoResume_CleansUpAfterFailure(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "")
+	t.Setenv(constants.EnvResume, "")
+	cfg := createTestConfig(t)
+	cfg.CommitHash = "abc123"
+	env := newResumeTestEnv(t)
+	env.failCommit = true
+
+	if err := executeQuiet(t, env.h, cfg); err == nil {
+		t.Fatal("ожидалась ошибка на этапе committing")
+	}
+	if _, err := os.Stat(checkpointPath(cfg)); !os.IsNotExist(err) {
+		t.Error("без BR_RESUME checkpoint не должен сохраняться")
+	}
+	if _, err := os.Stat(cfg.RepPath); !os.IsNotExist(err) {
+		t.Errorf("без BR_RESUME клон должен удаляться после ошибки: %s", cfg.RepPath)
+	}
+}
+
+// TestGit2StoreHandler_Resume_RequiresCommitHash проверяет отказ от продолжения без хеша коммита.
+func TestGit2StoreHandler_Resume_RequiresCommitHash(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "")
+	t.Setenv(constants.EnvResume, "true")
+	cfg := createTestConfig(t)
+	cfg.CommitHash = ""
+	env := newResumeTestEnv(t)
+
+	err := executeQuiet(t, env.h, cfg)
+	if err == nil || !strings.Contains(err.Error(), "ERR_GIT2STORE_RESUME") {
+		t.Fatalf("ожидалась ошибка ERR_GIT2STORE_RESUME, got %v", err)
+	}
+	if env.backups != 0 || env.clones != 0 {
+		t.Errorf("этапы не должны выполняться: backups=%d clones=%d", env.backups, env.clones)
+	}
+}
+
+// TestCheckpoint_Matches проверяет привязку checkpoint к owner/repo/commit/infobase.
+func TestCheckpoint_Matches(t *testing.T) {
+	cfg := createTestConfig(t)
+	cfg.CommitHash = "abc"
+	cp := newCheckpoint(cfg)
+
+	if err := cp.matches(cfg); err != nil {
+		t.Errorf("checkpoint должен подходить: %v", err)
+	}
+
+	other := *cfg
+	other.CommitHash = "def"
+	if cp.matches(&other) == nil {
+		t.Error("checkpoint другого коммита не должен подходить")
+	}
+
+	other = *cfg
+	other.InfobaseName = "OtherDB"
+	if cp.matches(&other) == nil {
+		t.Error("checkpoint другой базы не должен подходить")
+	}
+}
+
+// TestCheckpoint_TruncateInvalid проверяет отбрасывание этапов с отсутствующими артефактами.
+func TestCheckpoint_TruncateInvalid(t *testing.T) {
+	dir := t.TempDir()
+	cp := &Checkpoint{
+		CompletedStages: []string{StageCreatingBackup, StageCloning, StageCheckoutEdt, StageCreatingTempDb, StageInitDb, StageBinding},
+		BackupPath:      dir,
+		RepPath:         dir,
+		TempDbPath:      filepath.Join(dir, "missing_db"),
+		TempDbConnect:   "/F " + filepath.Join(dir, "missing_db"),
+	}
+
+	stage, reason := cp.truncateInvalid()
+
+	if stage != StageCreatingTempDb || reason == "" {
+		t.Errorf("ожидался отброшенный этап %s, got %q (%s)", StageCreatingTempDb, stage, reason)
+	}
+	want := []string{StageCreatingBackup, StageCloning, StageCheckoutEdt}
+	if !slices.Equal(cp.CompletedStages, want) {
+		t.Errorf("CompletedStages = %v, want %v", cp.CompletedStages, want)
+	}
+}
@@ -2,15 +2,15 @@
 package git2storehandler
 
 import (
-	"github.com/Kargones/apk-ci/internal/constants"
 	"context"
 	"fmt"
 	"log/slog"
 	"os"
-	"path/filepath"
-	"time"
+	"strconv"
 
 	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/entity/one/store"
 )
 
 // createGit создаёт GitOperator через фабрику или production реализацию.
@@ -47,33 +47,43 @@ func (h *Git2StoreHandler) createTempDb(ctx context.Context, l *slog.Logger, cfg
 
 // createBackupProduction создаёт резервную копию хранилища (production реализация).
 //
-// TODO(#45): Реализовать полноценный backup хранилища 1C.
-// Текущая реализация создаёт только метаданные (backup_info.txt) с информацией
-// для ручного восстановления. Полный backup требует:
-// 1. Вызов 1cv8 DESIGNER /ConfigurationRepositoryDumpCfg для экспорта конфигурации
-// 2. Сохранение версии хранилища через /ConfigurationRepositoryReport
-// 3. Копирование локальных файлов (если хранилище файловое)
-// Это требует расширения convert.Config или отдельного BackupService.
+// Для файлового хранилища копируется его каталог — такой backup используется
+// при автоматическом откате и nr-store-restore-backup.
+// TODO(#45): Для серверного хранилища (tcp://) создаются только метаданные
+// (backup_info.txt) для ручного восстановления. Полный backup требует
+// 1cv8 DESIGNER /ConfigurationRepositoryDumpCfg или доступа к каталогу сервера хранилища.
+//
+// После создания удаляются старые backup этого хранилища сверх BR_STORE_BACKUP_KEEP
+// (по умолчанию store.DefaultBackupKeep): копии каталога хранилища занимают место в TmpDir.
 func createBackupProduction(cfg *config.Config, storeRoot string) (string, error) {
-	backupDir := filepath.Join(cfg.TmpDir, "backup_"+time.Now().Format("20060102_150405"))
-	if err := os.MkdirAll(backupDir, constants.DirPermExec); err != nil {
-		return "", fmt.Errorf("не удалось создать директорию backup: %w", err)
+	keep, err := backupKeep()
+	if err != nil {
+		return "", err
 	}
-
-	backupInfoPath := filepath.Join(backupDir, "backup_info.txt")
-	backupInfo := fmt.Sprintf("=== BACKUP METADATA ===\n"+
-		"Store Root: %s\n"+
-		"Created: %s\n"+
-		"Infobase: %s\n"+
-		"Owner: %s\n"+
-		"Repo: %s\n"+
-		"\nВНИМАНИЕ: Это метаданные для ручного восстановления.\n"+
-		"Для восстановления используйте 1cv8 DESIGNER или ibcmd.\n",
-		storeRoot, time.Now().Format(time.RFC3339),
-		cfg.InfobaseName, cfg.Owner, cfg.Repo)
-	if err := os.WriteFile(backupInfoPath, []byte(backupInfo), constants.FilePermReadWrite); err != nil {
-		return "", fmt.Errorf("не удалось записать информацию о backup: %w", err)
+	backupPath, err := store.CreateBackup(cfg, storeRoot)
+	if err != nil {
+		return "", err
+	}
+	pruned, err := store.PruneBackups(cfg.TmpDir, storeRoot, keep)
+	if err != nil {
+		// Backup создан: ошибка очистки старых копий не прерывает git2store
+		slog.Warn("Не удалось удалить старые backup хранилища", slog.String("error", err.Error()))
 	}
+	for _, path := range pruned {
+		slog.Info("Удалён старый backup хранилища", slog.String("backup_path", path))
+	}
+	return backupPath, nil
+}
 
-	return backupDir, nil
+// backupKeep возвращает количество хранимых backup из BR_STORE_BACKUP_KEEP.
+func backupKeep() (int, error) {
+	val := os.Getenv(constants.EnvStoreBackupKeep)
+	if val == "" {
+		return store.DefaultBackupKeep, nil
+	}
+	keep, err := strconv.Atoi(val)
+	if err != nil || keep < 0 {
+		return 0, fmt.Errorf("некорректное значение %s: %q (ожидается неотрицательное число)", constants.EnvStoreBackupKeep, val)
+	}
+	return keep, nil
 }
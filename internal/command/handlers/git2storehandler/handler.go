@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"slices"
 	"time"
 
 	"github.com/Kargones/apk-ci/internal/command"
@@ -15,6 +16,7 @@ import (
 	"github.com/Kargones/apk-ci/internal/pkg/dryrun"
 	"github.com/Kargones/apk-ci/internal/pkg/output"
 	"github.com/Kargones/apk-ci/internal/pkg/tracing"
+	"github.com/Kargones/apk-ci/internal/pkg/workerpool"
 )
 
 // Compile-time interface check (AC-1).
@@ -80,10 +82,15 @@ type Git2StoreData struct {
 	StageCurrent string `json:"stage_current"`
 	// BackupPath — путь к backup хранилища (AC-8)
 	BackupPath string `json:"backup_path"`
+	// RollbackScope — объём автоматического отката при ошибке фиксации:
+	// RollbackScopeRestore или RollbackScopeUnlockOnly
+	RollbackScope string `json:"rollback_scope,omitempty"`
 	// DurationMs — длительность операции в миллисекундах
 	DurationMs int64 `json:"duration_ms"`
 	// Errors — список ошибок (AC-4). Без omitempty чтобы всегда выводить пустой массив.
 	Errors []string `json:"errors"`
+	// Rollback — результат автоматического отката хранилища (nil если откат не выполнялся)
+	Rollback *RollbackResult `json:"rollback,omitempty"`
 }
 
 // StageResult результат выполнения этапа (AC-3).
@@ -96,6 +103,10 @@ type StageResult struct {
 	DurationMs int64 `json:"duration_ms"`
 	// Error — ошибка этапа (если была)
 	Error string `json:"error,omitempty"`
+	// Resumed — этап не выполнялся, т.к. завершён в предыдущем прогоне (BR_RESUME)
+	Resumed bool `json:"resumed,omitempty"`
+	// Extensions — результаты обработки расширений на этапе (BR_EXTENSION_CONCURRENCY)
+	Extensions []workerpool.Result `json:"extensions,omitempty"`
 }
 
 // GitOperator — интерфейс для Git операций (для тестируемости).
@@ -131,8 +142,12 @@ type ConvertConfigOperator interface {
 	Merge(ctx context.Context, l *slog.Logger, cfg *config.Config) error
 	// StoreCommit фиксирует изменения в хранилище
 	StoreCommit(ctx context.Context, l *slog.Logger, cfg *config.Config) error
+	// StoreUnlock освобождает захваченные объекты хранилища (откат)
+	StoreUnlock(ctx context.Context, l *slog.Logger, cfg *config.Config) error
 	// SetOneDB устанавливает параметры временной БД
 	SetOneDB(dbConnectString, user, pass string)
+	// ExtensionResults возвращает результаты обработки расширений последней операции
+	ExtensionResults() []workerpool.Result
 }
 
 // BackupCreator — интерфейс для создания backup (AC-8, для тестируемости).
@@ -173,8 +188,12 @@ type Git2StoreHandler struct {
 	backupCreator BackupCreator
 	// tempDbCreator — опциональный создатель временной БД (nil в production, mock в тестах)
 	tempDbCreator TempDbCreator
+	// backupRestorer — опциональное восстановление из backup (nil в production, mock в тестах)
+	backupRestorer BackupRestorer
 	// verbosePlan — план операций для verbose режима (Story 7.3), добавляется в JSON результат
 	verbosePlan *output.DryRunPlan
+	// checkpoint — состояние текущего прогона для BR_RESUME (nil если сохранение недоступно)
+	checkpoint *Checkpoint
 }
 
 // Name возвращает имя команды.
@@ -233,25 +252,35 @@ func (h *Git2StoreHandler) handlePreviewModes(cfg *config.Config, format, traceI
 }
 
 // executeConversionStages runs the conversion pipeline stages (loading_config through committing).
+// Этапы, завершённые в предыдущем прогоне, пропускаются в режиме BR_RESUME.
+// Ошибка этапа из rollbackStages после создания backup запускает откат хранилища.
 func (h *Git2StoreHandler) executeConversionStages(ctx context.Context, cfg *config.Config, log *slog.Logger, data *Git2StoreData, gitOp GitOperator, ccOp ConvertConfigOperator, format, traceID string, start time.Time) error {
-	type stageFunc func() error
-	stages := []stageFunc{
-		func() error { return h.executeStageLoadingConfig(ctx, cfg, log, data, ccOp) },
-		func() error { return h.executeStageCheckoutXml(ctx, cfg, log, data, gitOp) },
-		func() error { return h.executeStageInitDb(ctx, cfg, log, data, ccOp) },
-		func() error { return h.executeStageUnbinding(ctx, cfg, log, data, ccOp) },
-		func() error { return h.executeStageLoadingDb(ctx, cfg, log, data, ccOp) },
-		func() error { return h.executeStageUpdatingDb(ctx, cfg, log, data, ccOp, StageUpdatingDb1) },
-		func() error { return h.executeStageDumpingDb(ctx, cfg, log, data, ccOp) },
-		func() error { return h.executeStageBinding(ctx, cfg, log, data, ccOp) },
-		func() error { return h.executeStageUpdatingDb(ctx, cfg, log, data, ccOp, StageUpdatingDb2) },
-		func() error { return h.executeStageLocking(ctx, cfg, log, data, ccOp) },
-		func() error { return h.executeStageMerging(ctx, cfg, log, data, ccOp) },
-		func() error { return h.executeStageUpdatingDb(ctx, cfg, log, data, ccOp, StageUpdatingDb3) },
-		func() error { return h.executeStageCommitting(ctx, cfg, log, data, ccOp) },
+	stages := []struct {
+		name string
+		run  func() error
+	}{
+		{StageLoadingConfig, func() error { return h.executeStageLoadingConfig(ctx, cfg, log, data, ccOp) }},
+		{StageCheckoutXml, func() error { return h.executeStageCheckoutXml(ctx, cfg, log, data, gitOp) }},
+		{StageInitDb, func() error { return h.executeStageInitDb(ctx, cfg, log, data, ccOp) }},
+		{StageUnbinding, func() error { return h.executeStageUnbinding(ctx, cfg, log, data, ccOp) }},
+		{StageLoadingDb, func() error { return h.executeStageLoadingDb(ctx, cfg, log, data, ccOp) }},
+		{StageUpdatingDb1, func() error { return h.executeStageUpdatingDb(ctx, cfg, log, data, ccOp, StageUpdatingDb1) }},
+		{StageDumpingDb, func() error { return h.executeStageDumpingDb(ctx, cfg, log, data, ccOp) }},
+		{StageBinding, func() error { return h.executeStageBinding(ctx, cfg, log, data, ccOp) }},
+		{StageUpdatingDb2, func() error { return h.executeStageUpdatingDb(ctx, cfg, log, data, ccOp, StageUpdatingDb2) }},
+		{StageLocking, func() error { return h.executeStageLocking(ctx, cfg, log, data, ccOp) }},
+		{StageMerging, func() error { return h.executeStageMerging(ctx, cfg, log, data, ccOp) }},
+		{StageUpdatingDb3, func() error { return h.executeStageUpdatingDb(ctx, cfg, log, data, ccOp, StageUpdatingDb3) }},
+		{StageCommitting, func() error { return h.executeStageCommitting(ctx, cfg, log, data, ccOp) }},
 	}
-	for _, fn := range stages {
-		if err := fn(); err != nil {
+	for _, stage := range stages {
+		if h.resumeStage(data, stage.name, log) {
+			continue
+		}
+		if err := stage.run(); err != nil {
+			if data.BackupPath != "" && slices.Contains(rollbackStages, stage.name) {
+				h.executeRollback(ctx, cfg, log, data, ccOp, stage.name, err)
+			}
 			return h.writeStageError(format, traceID, start, data, err)
 		}
 	}
@@ -280,6 +309,7 @@ func (h *Git2StoreHandler) Execute(ctx context.Context, cfg *config.Config) erro
 		StagesCompleted: make([]StageResult, 0),
 		Errors:          make([]string, 0),
 	}
+	h.checkpoint = nil
 
 	// === РЕЖИМЫ ПРЕДПРОСМОТРА ===
 	if handled, err := h.handlePreviewModes(cfg, format, traceID, start, log); handled {
@@ -291,21 +321,46 @@ func (h *Git2StoreHandler) Execute(ctx context.Context, cfg *config.Config) erro
 		return h.writeStageError(format, traceID, start, data, err)
 	}
 
-	storeRoot := constants.StoreRoot + cfg.Owner + "/" + cfg.Repo
-
-	// Stage: creating_backup (AC-8, MANDATORY!)
-	backupPath, err := h.executeStageCreatingBackup(cfg, log, data, storeRoot)
+	// Checkpoint: при BR_RESUME=true сохраняется после каждого этапа,
+	// завершённые этапы предыдущего прогона пропускаются.
+	checkpoint, err := h.prepareCheckpoint(cfg, log)
 	if err != nil {
 		return h.writeStageError(format, traceID, start, data, err)
 	}
-	data.BackupPath = backupPath
+	h.checkpoint = checkpoint
+	completed := false
+	// keepArtifacts — после ошибки клон и временная БД сохраняются только для BR_RESUME.
+	keepArtifacts := func() bool { return !completed && h.checkpoint != nil }
+
+	storeRoot := storeRootFor(cfg)
+
+	// Stage: creating_backup (AC-8, MANDATORY!)
+	if h.resumeStage(data, StageCreatingBackup, log) {
+		data.BackupPath = h.checkpoint.BackupPath
+	} else {
+		backupPath, err := h.executeStageCreatingBackup(cfg, log, data, storeRoot)
+		if err != nil {
+			return h.writeStageError(format, traceID, start, data, err)
+		}
+		data.BackupPath = backupPath
+	}
+	data.RollbackScope = rollbackScopeFor(storeRoot)
 
 	// Stage: cloning (AC-2)
-	gitOp, err := h.executeStageCloning(ctx, cfg, log, data)
-	if err != nil {
+	var gitOp GitOperator
+	if h.resumeStage(data, StageCloning, log) {
+		cfg.RepPath = h.checkpoint.RepPath
+		if gitOp, err = h.createGit(log, cfg); err != nil {
+			return h.writeStageError(format, traceID, start, data, fmt.Errorf("ERR_GIT2STORE_CLONE: %s", err.Error()))
+		}
+	} else if gitOp, err = h.executeStageCloning(ctx, cfg, log, data); err != nil {
 		return h.writeStageError(format, traceID, start, data, err)
 	}
 	defer func() {
+		if keepArtifacts() {
+			log.Info("Клон репозитория сохранён для BR_RESUME", slog.String("path", cfg.RepPath))
+			return
+		}
 		if cfg.RepPath != "" {
 			if removeErr := os.RemoveAll(cfg.RepPath); removeErr != nil {
 				log.Warn("Не удалось удалить временную директорию репозитория",
@@ -317,20 +372,32 @@ func (h *Git2StoreHandler) Execute(ctx context.Context, cfg *config.Config) erro
 	}()
 
 	// Stage: checkout_edt (AC-2)
-	if err := h.executeStageCheckoutEdt(ctx, cfg, log, data, gitOp); err != nil {
-		return h.writeStageError(format, traceID, start, data, err)
+	if !h.resumeStage(data, StageCheckoutEdt, log) {
+		if err := h.executeStageCheckoutEdt(ctx, cfg, log, data, gitOp); err != nil {
+			return h.writeStageError(format, traceID, start, data, err)
+		}
 	}
 
 	// Stage: creating_temp_db (optional)
 	ccOp := h.createConvertConfig()
 	var tempDbPath string
 	if cfg.ProjectConfig != nil && cfg.ProjectConfig.StoreDb == constants.LocalBase {
-		var tempErr error
-		tempDbPath, tempErr = h.executeStageCreatingTempDb(ctx, cfg, log, data, ccOp)
-		if tempErr != nil {
-			return h.writeStageError(format, traceID, start, data, tempErr)
+		if h.resumeStage(data, StageCreatingTempDb, log) {
+			tempDbPath = h.checkpoint.TempDbPath
+			user, pass := oneDBCredentials(cfg)
+			ccOp.SetOneDB(h.checkpoint.TempDbConnect, user, pass)
+		} else {
+			var tempErr error
+			tempDbPath, tempErr = h.executeStageCreatingTempDb(ctx, cfg, log, data, ccOp)
+			if tempErr != nil {
+				return h.writeStageError(format, traceID, start, data, tempErr)
+			}
 		}
 		defer func() {
+			if keepArtifacts() {
+				log.Info("Временная БД сохранена для BR_RESUME", slog.String("path", tempDbPath))
+				return
+			}
 			if tempDbPath != "" {
 				if removeErr := os.RemoveAll(tempDbPath); removeErr != nil {
 					log.Warn("Не удалось удалить временную БД",
@@ -347,6 +414,14 @@ func (h *Git2StoreHandler) Execute(ctx context.Context, cfg *config.Config) erro
 		return err
 	}
 
+	// Прогон завершён: checkpoint больше не нужен, артефакты удаляются
+	completed = true
+	if h.checkpoint != nil {
+		if err := h.checkpoint.remove(); err != nil {
+			log.Warn("Не удалось удалить checkpoint", slog.String("error", err.Error()))
+		}
+	}
+
 	// Формирование результата (AC-4, AC-12)
 	data.DurationMs = time.Since(start).Milliseconds()
 	data.StateChanged = true
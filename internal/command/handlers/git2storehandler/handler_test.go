@@ -17,6 +17,7 @@ import (
 	"github.com/Kargones/apk-ci/internal/config"
 	"github.com/Kargones/apk-ci/internal/constants"
 	"github.com/Kargones/apk-ci/internal/pkg/output"
+	"github.com/Kargones/apk-ci/internal/pkg/workerpool"
 )
 
 // mockGitOperator — мок для GitOperator.
@@ -74,7 +75,9 @@ type mockConvertConfigOperator struct {
 	storeLockFunc   func(ctx context.Context, l *slog.Logger, cfg *config.Config) error
 	mergeFunc       func(ctx context.Context, l *slog.Logger, cfg *config.Config) error
 	storeCommitFunc func(ctx context.Context, l *slog.Logger, cfg *config.Config) error
+	storeUnlockFunc func(ctx context.Context, l *slog.Logger, cfg *config.Config) error
 	setOneDBFunc    func(dbConnectString, user, pass string)
+	extResults      []workerpool.Result
 }
 
 func (m *mockConvertConfigOperator) Load(ctx context.Context, l *slog.Logger, cfg *config.Config, infobaseName string) error {
@@ -147,12 +150,23 @@ func (m *mockConvertConfigOperator) StoreCommit(ctx context.Context, l *slog.Log
 	return nil
 }
 
+func (m *mockConvertConfigOperator) StoreUnlock(ctx context.Context, l *slog.Logger, cfg *config.Config) error {
+	if m.storeUnlockFunc != nil {
+		return m.storeUnlockFunc(ctx, l, cfg)
+	}
+	return nil
+}
+
 func (m *mockConvertConfigOperator) SetOneDB(dbConnectString, user, pass string) {
 	if m.setOneDBFunc != nil {
 		m.setOneDBFunc(dbConnectString, user, pass)
 	}
 }
 
+func (m *mockConvertConfigOperator) ExtensionResults() []workerpool.Result {
+	return m.extResults
+}
+
 // mockConvertConfigFactory — мок для ConvertConfigFactory.
 type mockConvertConfigFactory struct {
 	ccOp *mockConvertConfigOperator
@@ -865,6 +879,58 @@ func TestGit2StoreHandler_Execute_WithExtensions(t *testing.T) {
 	}
 }
 
+// TestGit2StoreHandler_StageExtensionResults проверяет, что результаты обработки
+// расширений прикрепляются к этапу, в том числе при ошибке этапа.
+func TestGit2StoreHandler_StageExtensionResults(t *testing.T) {
+	cfg := createTestConfig(t)
+	ccOp := &mockConvertConfigOperator{
+		storeLockFunc: func(ctx context.Context, l *slog.Logger, cfg *config.Config) error {
+			return errors.New("Extension2: объект захвачен другим пользователем")
+		},
+		extResults: []workerpool.Result{
+			{Name: "Extension1", Success: true, DurationMs: 120},
+			{Name: "Extension2", Error: "объект захвачен другим пользователем", DurationMs: 80},
+			{Name: "Extension3", Skipped: true},
+		},
+	}
+	h := &Git2StoreHandler{}
+	data := &Git2StoreData{}
+
+	err := h.executeStageLocking(context.Background(), cfg, slog.Default(), data, ccOp)
+	if err == nil {
+		t.Fatal("executeStageLocking() должен вернуть ошибку")
+	}
+	if len(data.StagesCompleted) != 1 {
+		t.Fatalf("ожидался 1 этап, получено %d", len(data.StagesCompleted))
+	}
+	stage := data.StagesCompleted[0]
+	if len(stage.Extensions) != 3 {
+		t.Fatalf("ожидалось 3 результата расширений, получено %d", len(stage.Extensions))
+	}
+
+	var buf bytes.Buffer
+	if err := data.writeText(&buf); err != nil {
+		t.Fatalf("writeText() вернул ошибку: %v", err)
+	}
+	for _, expected := range []string{
+		"✓ расширение Extension1 (120 мс)",
+		"✗ расширение Extension2 (80 мс): объект захвачен другим пользователем",
+		"- расширение Extension3: не обработано",
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("writeText() output должен содержать %q, got: %s", expected, buf.String())
+		}
+	}
+
+	raw, err := json.Marshal(stage)
+	if err != nil {
+		t.Fatalf("json.Marshal() вернул ошибку: %v", err)
+	}
+	if !strings.Contains(string(raw), `"extensions":[{"name":"Extension1","success":true,"duration_ms":120}`) {
+		t.Errorf("JSON этапа должен содержать результаты расширений, got: %s", raw)
+	}
+}
+
 // TestGit2StoreHandler_DeprecatedAlias проверяет deprecated alias (AC-6).
 func TestGit2StoreHandler_DeprecatedAlias(t *testing.T) {
 	// Проверяем, что handler зарегистрирован под deprecated alias
@@ -905,9 +971,10 @@ func TestGit2StoreData_writeText(t *testing.T) {
 			{Name: StageCreatingBackup, Success: true, DurationMs: 100},
 			{Name: StageCloning, Success: false, DurationMs: 5000, Error: "clone error"},
 		},
-		StageCurrent: StageCloning,
-		BackupPath:   "/tmp/backup_test",
-		DurationMs:   5110,
+		StageCurrent:  StageCloning,
+		BackupPath:    "/tmp/backup_test",
+		RollbackScope: RollbackScopeUnlockOnly,
+		DurationMs:    5110,
 	}
 
 	var buf bytes.Buffer
@@ -926,6 +993,7 @@ func TestGit2StoreData_writeText(t *testing.T) {
 		"✓", // успешные этапы
 		"✗", // неуспешный этап
 		"clone error",
+		"Откат при ошибке: только освобождение объектов",
 	}
 
 	for _, expected := range expectedContents {
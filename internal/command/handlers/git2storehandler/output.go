@@ -12,6 +12,7 @@ import (
 	"github.com/Kargones/apk-ci/internal/constants"
 	"github.com/Kargones/apk-ci/internal/pkg/dryrun"
 	"github.com/Kargones/apk-ci/internal/pkg/output"
+	"github.com/Kargones/apk-ci/internal/pkg/workerpool"
 )
 
 // writeText выводит результат в человекочитаемом формате (AC-5).
@@ -47,6 +48,17 @@ func (d *Git2StoreData) writeText(w io.Writer) error {
 		}
 	}
 
+	switch d.RollbackScope {
+	case RollbackScopeRestore:
+		if _, err = fmt.Fprintf(w, "  Откат при ошибке: освобождение объектов и восстановление хранилища из backup\n"); err != nil {
+			return err
+		}
+	case RollbackScopeUnlockOnly:
+		if _, err = fmt.Fprintf(w, "  Откат при ошибке: только освобождение объектов (серверное хранилище не восстанавливается из backup)\n"); err != nil {
+			return err
+		}
+	}
+
 	if _, err = fmt.Fprintf(w, "  Текущий этап: %s\n", d.StageCurrent); err != nil {
 		return err
 	}
@@ -64,6 +76,12 @@ func (d *Git2StoreData) writeText(w io.Writer) error {
 		if !stage.Success {
 			status = "✗"
 		}
+		if stage.Resumed {
+			if _, err = fmt.Fprintf(w, "  %s %s (завершён ранее, BR_RESUME)\n", status, stage.Name); err != nil {
+				return err
+			}
+			continue
+		}
 		if _, err = fmt.Fprintf(w, "  %s %s (%d мс)\n", status, stage.Name, stage.DurationMs); err != nil {
 			return err
 		}
@@ -72,11 +90,30 @@ func (d *Git2StoreData) writeText(w io.Writer) error {
 				return err
 			}
 		}
+		for _, ext := range stage.Extensions {
+			if err = writeExtensionResult(w, ext); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
 }
 
+// writeExtensionResult выводит результат обработки одного расширения на этапе.
+func writeExtensionResult(w io.Writer, ext workerpool.Result) error {
+	var err error
+	switch {
+	case ext.Success:
+		_, err = fmt.Fprintf(w, "    ✓ расширение %s (%d мс)\n", ext.Name, ext.DurationMs)
+	case ext.Skipped:
+		_, err = fmt.Fprintf(w, "    - расширение %s: не обработано\n", ext.Name)
+	default:
+		_, err = fmt.Fprintf(w, "    ✗ расширение %s (%d мс): %s\n", ext.Name, ext.DurationMs, ext.Error)
+	}
+	return err
+}
+
 // buildPlan создаёт план операций для предпросмотра.
 // Используется в dry-run, plan-only и verbose режимах.
 // Story 7.3: извлечено из executeDryRun для переиспользования.
@@ -262,6 +299,9 @@ func (h *Git2StoreHandler) writeStageError(format, traceID string, start time.Ti
 	data.DurationMs = time.Since(start).Milliseconds()
 
 	if format != output.FormatJSON {
+		if data.Rollback != nil {
+			return fmt.Errorf("%w (backup: %s; %s)", stageErr, data.BackupPath, data.Rollback.summary())
+		}
 		if data.BackupPath != "" {
 			return fmt.Errorf("%s (backup: %s)", stageErr.Error(), data.BackupPath)
 		}
@@ -285,6 +325,9 @@ func (h *Git2StoreHandler) writeStageError(format, traceID string, start time.Ti
 	}
 
 	data.Errors = []string{message}
+	if data.Rollback != nil {
+		data.Errors = append(data.Errors, data.Rollback.Errors...)
+	}
 
 	result := &output.Result{
 		Status:  output.StatusError,
@@ -13,6 +13,7 @@ import (
 	"github.com/Kargones/apk-ci/internal/entity/one/convert"
 	"github.com/Kargones/apk-ci/internal/entity/one/designer"
 	"github.com/Kargones/apk-ci/internal/git"
+	"github.com/Kargones/apk-ci/internal/pkg/workerpool"
 )
 
 // gitWrapper — обёртка для git.Git, реализующая GitOperator.
@@ -122,6 +123,16 @@ func (c *convertConfigWrapper) StoreCommit(ctx context.Context, l *slog.Logger,
 	return c.cc.StoreCommit(ctx, l, cfg)
 }
 
+// StoreUnlock освобождает захваченные объекты хранилища.
+func (c *convertConfigWrapper) StoreUnlock(ctx context.Context, l *slog.Logger, cfg *config.Config) error {
+	return c.cc.StoreUnlock(ctx, l, cfg)
+}
+
+// ExtensionResults возвращает результаты обработки расширений последней операции.
+func (c *convertConfigWrapper) ExtensionResults() []workerpool.Result {
+	return c.cc.ExtensionResults()
+}
+
 // SetOneDB устанавливает параметры временной БД.
 func (c *convertConfigWrapper) SetOneDB(dbConnectString, user, pass string) {
 	c.cc.OneDB = designer.OneDb{
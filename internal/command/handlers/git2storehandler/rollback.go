@@ -0,0 +1,175 @@
+// Package git2storehandler — откат хранилища из backup при ошибке фиксации.
+package git2storehandler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/entity/one/store"
+)
+
+// StageRollingBack — этап отката хранилища. Выполняется только после ошибки
+// одного из rollbackStages и не входит в allStages.
+const StageRollingBack = "rolling_back"
+
+// Объём автоматического отката (Git2StoreData.RollbackScope).
+const (
+	// RollbackScopeRestore — файловое хранилище: объекты освобождаются,
+	// каталог хранилища восстанавливается из backup
+	RollbackScopeRestore = "restore"
+	// RollbackScopeUnlockOnly — серверное хранилище (tcp://): объекты освобождаются,
+	// версии, зафиксированные до ошибки, остаются в хранилище
+	RollbackScopeUnlockOnly = "unlock_only"
+)
+
+// rollbackStages — этапы, после ошибки которых хранилище может остаться
+// с захваченными объектами или частично зафиксированными изменениями.
+var rollbackStages = []string{StageLocking, StageMerging, StageUpdatingDb3, StageCommitting}
+
+// RollbackResult — результат автоматического отката хранилища.
+type RollbackResult struct {
+	// FailedStage — этап, ошибка которого вызвала откат
+	FailedStage string `json:"failed_stage"`
+	// OriginalError — исходная ошибка этапа
+	OriginalError string `json:"original_error"`
+	// Success — откат выполнен без ошибок
+	Success bool `json:"success"`
+	// LocksReleased — захваченные объекты освобождены
+	LocksReleased bool `json:"locks_released"`
+	// StoreRestored — каталог хранилища восстановлен из backup
+	StoreRestored bool `json:"store_restored"`
+	// UnlockOnly — откат ограничен освобождением объектов: backup не содержит
+	// копии хранилища (tcp://), изменения, зафиксированные до ошибки, не отменены
+	UnlockOnly bool `json:"unlock_only"`
+	// BackupPath — backup, использованный для отката
+	BackupPath string `json:"backup_path"`
+	// Note — пояснение, если восстановление каталога невозможно
+	Note string `json:"note,omitempty"`
+	// Errors — ошибки отката
+	Errors []string `json:"errors,omitempty"`
+	// DurationMs — длительность отката в миллисекундах
+	DurationMs int64 `json:"duration_ms"`
+}
+
+// BackupRestorer — интерфейс для восстановления хранилища из backup (для тестируемости).
+type BackupRestorer interface {
+	// RestoreBackup восстанавливает каталог хранилища из backup
+	RestoreBackup(backupPath, storeRoot string) error
+}
+
+// storeRootFor возвращает корень хранилища для репозитория из cfg.
+func storeRootFor(cfg *config.Config) string {
+	return constants.StoreRoot + cfg.Owner + "/" + cfg.Repo
+}
+
+// rollbackScopeFor возвращает объём отката для хранилища storeRoot.
+// Хранилище git2store — серверное (constants.StoreRoot), поэтому в штатной
+// конфигурации откат ограничен освобождением объектов.
+func rollbackScopeFor(storeRoot string) string {
+	if store.IsLocalStore(storeRoot) {
+		return RollbackScopeRestore
+	}
+	return RollbackScopeUnlockOnly
+}
+
+// restoreBackup восстанавливает хранилище через интерфейс или production реализацию.
+func (h *Git2StoreHandler) restoreBackup(backupPath, storeRoot string) error {
+	if h.backupRestorer != nil {
+		return h.backupRestorer.RestoreBackup(backupPath, storeRoot)
+	}
+	return store.RestoreBackup(backupPath, storeRoot)
+}
+
+// executeRollback освобождает захваченные объекты и восстанавливает хранилище из backup.
+//
+// Ошибки отката не заменяют исходную ошибку этапа: они записываются в
+// data.Rollback, а вызывающий код возвращает stageErr. Для серверного
+// хранилища (tcp://) backup содержит только метаданные — каталог не
+// восстанавливается: откат помечается UnlockOnly, в Note указывается
+// путь для ручного восстановления.
+func (h *Git2StoreHandler) executeRollback(ctx context.Context, cfg *config.Config, log *slog.Logger, data *Git2StoreData, ccOp ConvertConfigOperator, failedStage string, stageErr error) {
+	rollbackStart := time.Now()
+	log.Warn(StageRollingBack+": откат хранилища после ошибки",
+		slog.String("failed_stage", failedStage),
+		slog.String("backup_path", data.BackupPath),
+		slog.String("error", stageErr.Error()))
+
+	result := &RollbackResult{
+		FailedStage:   failedStage,
+		OriginalError: stageErr.Error(),
+		BackupPath:    data.BackupPath,
+	}
+	data.Rollback = result
+
+	if err := ccOp.StoreUnlock(ctx, log, cfg); err != nil {
+		log.Error(StageRollingBack+": не удалось освободить объекты", slog.String("error", err.Error()))
+		result.Errors = append(result.Errors, "освобождение объектов: "+err.Error())
+	} else {
+		result.LocksReleased = true
+	}
+
+	err := h.restoreBackup(data.BackupPath, storeRootFor(cfg))
+	switch {
+	case err == nil:
+		result.StoreRestored = true
+		log.Info(StageRollingBack+": хранилище восстановлено из backup", slog.String("backup_path", data.BackupPath))
+	case errors.Is(err, store.ErrBackupNotRestorable):
+		result.UnlockOnly = true
+		result.Note = "хранилище не восстановлено, изменения до ошибки остались в хранилище " +
+			"(ручное восстановление по " + data.BackupPath + "): " + err.Error()
+		log.Warn(StageRollingBack+": только освобождение объектов, восстановление каталога недоступно",
+			slog.String("reason", err.Error()))
+	default:
+		log.Error(StageRollingBack+": не удалось восстановить хранилище", slog.String("error", err.Error()))
+		result.Errors = append(result.Errors, "восстановление хранилища: "+err.Error())
+	}
+
+	result.Success = len(result.Errors) == 0
+	result.DurationMs = time.Since(rollbackStart).Milliseconds()
+	data.StagesCompleted = append(data.StagesCompleted, StageResult{
+		Name:       StageRollingBack,
+		Success:    result.Success,
+		DurationMs: result.DurationMs,
+		Error:      strings.Join(result.Errors, "; "),
+	})
+
+	// Захват объектов снят: при BR_RESUME этапы с locking выполняются заново.
+	if h.checkpoint != nil && result.LocksReleased {
+		if idx := slices.Index(h.checkpoint.CompletedStages, StageLocking); idx >= 0 {
+			h.checkpoint.CompletedStages = h.checkpoint.CompletedStages[:idx]
+			if err := h.checkpoint.save(); err != nil {
+				log.Warn("Не удалось сохранить checkpoint после отката", slog.String("error", err.Error()))
+			}
+		}
+	}
+}
+
+// summary возвращает краткое описание результата отката для текстового вывода.
+func (r *RollbackResult) summary() string {
+	parts := make([]string, 0, 3)
+	if r.LocksReleased {
+		parts = append(parts, "объекты освобождены")
+	}
+	if r.StoreRestored {
+		parts = append(parts, "хранилище восстановлено из backup")
+	}
+	if r.Note != "" {
+		parts = append(parts, r.Note)
+	}
+	parts = append(parts, r.Errors...)
+	status := "выполнен"
+	switch {
+	case !r.Success:
+		status = "с ошибками"
+	case r.UnlockOnly:
+		status = "только освобождение объектов"
+	}
+	return fmt.Sprintf("откат %s: %s", status, strings.Join(parts, "; "))
+}
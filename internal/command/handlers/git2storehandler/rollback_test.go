@@ -0,0 +1,257 @@
+package git2storehandler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/entity/one/store"
+)
+
+// mockBackupRestorer — мок для BackupRestorer.
+type mockBackupRestorer struct {
+	calls       int
+	restoreFunc func(backupPath, storeRoot string) error
+}
+
+func (m *mockBackupRestorer) RestoreBackup(backupPath, storeRoot string) error {
+	m.calls++
+	if m.restoreFunc != nil {
+		return m.restoreFunc(backupPath, storeRoot)
+	}
+	return nil
+}
+
+// executeJSON выполняет handler в JSON формате и возвращает Git2StoreData из ответа.
+func executeJSON(t *testing.T, h *Git2StoreHandler, cfg *config.Config) (*Git2StoreData, error) {
+	t.Helper()
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	var buf bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(&buf, r)
+		close(done)
+	}()
+
+	execErr := h.Execute(context.Background(), cfg)
+
+	_ = w.Close()
+	<-done
+	os.Stdout = oldStdout
+
+	var resp struct {
+		Data Git2StoreData `json:"data"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &resp); err != nil {
+		t.Fatalf("некорректный JSON ответ: %v\n%s", err, buf.String())
+	}
+	return &resp.Data, execErr
+}
+
+// TestGit2StoreHandler_Rollback_OnFailure проверяет откат после ошибки этапов с захватом объектов.
+func TestGit2StoreHandler_Rollback_OnFailure(t *testing.T) {
+	failing := map[string]func(m *mockConvertConfigOperator){
+		StageLocking: func(m *mockConvertConfigOperator) {
+			m.storeLockFunc = func(context.Context, *slog.Logger, *config.Config) error { return errors.New("lock failed") }
+		},
+		StageMerging: func(m *mockConvertConfigOperator) {
+			m.mergeFunc = func(context.Context, *slog.Logger, *config.Config) error { return errors.New("merge failed") }
+		},
+		StageCommitting: func(m *mockConvertConfigOperator) {
+			m.storeCommitFunc = func(context.Context, *slog.Logger, *config.Config) error { return errors.New("commit failed") }
+		},
+	}
+	for stage, setup := range failing {
+		t.Run(stage, func(t *testing.T) {
+			cfg := createTestConfig(t)
+			unlocks := 0
+			ccOp := &mockConvertConfigOperator{
+				storeUnlockFunc: func(context.Context, *slog.Logger, *config.Config) error {
+					unlocks++
+					return nil
+				},
+			}
+			setup(ccOp)
+			restorer := &mockBackupRestorer{}
+			h := &Git2StoreHandler{
+				gitFactory:           &mockGitFactory{gitOp: &mockGitOperator{}},
+				convertConfigFactory: &mockConvertConfigFactory{ccOp: ccOp},
+				backupCreator:        &mockBackupCreator{},
+				backupRestorer:       restorer,
+			}
+
+			data, err := executeJSON(t, h, cfg)
+
+			if err == nil {
+				t.Fatal("ожидалась исходная ошибка этапа")
+			}
+			if unlocks != 1 || restorer.calls != 1 {
+				t.Errorf("ожидались освобождение объектов и восстановление: unlocks=%d restores=%d", unlocks, restorer.calls)
+			}
+			rb := data.Rollback
+			if rb == nil {
+				t.Fatal("результат отката должен быть в Git2StoreData")
+			}
+			if rb.FailedStage != stage || !strings.Contains(rb.OriginalError, "failed") {
+				t.Errorf("неожиданный результат отката: %+v", rb)
+			}
+			if !rb.Success || !rb.LocksReleased || !rb.StoreRestored || rb.BackupPath != "/tmp/backup_test" {
+				t.Errorf("откат должен быть успешным: %+v", rb)
+			}
+			last := data.StagesCompleted[len(data.StagesCompleted)-1]
+			if last.Name != StageRollingBack || !last.Success {
+				t.Errorf("последним этапом должен быть %s, got %+v", StageRollingBack, last)
+			}
+		})
+	}
+}
+
+// TestGit2StoreHandler_Rollback_NotForEarlierStages проверяет, что ошибки до locking не вызывают откат.
+func TestGit2StoreHandler_Rollback_NotForEarlierStages(t *testing.T) {
+	cfg := createTestConfig(t)
+	unlocks := 0
+	ccOp := &mockConvertConfigOperator{
+		storeBindFunc: func(context.Context, *slog.Logger, *config.Config) error { return errors.New("bind failed") },
+		storeUnlockFunc: func(context.Context, *slog.Logger, *config.Config) error {
+			unlocks++
+			return nil
+		},
+	}
+	restorer := &mockBackupRestorer{}
+	h := &Git2StoreHandler{
+		gitFactory:           &mockGitFactory{gitOp: &mockGitOperator{}},
+		convertConfigFactory: &mockConvertConfigFactory{ccOp: ccOp},
+		backupCreator:        &mockBackupCreator{},
+		backupRestorer:       restorer,
+	}
+
+	data, err := executeJSON(t, h, cfg)
+
+	if err == nil {
+		t.Fatal("ожидалась ошибка binding")
+	}
+	if data.Rollback != nil || unlocks != 0 || restorer.calls != 0 {
+		t.Errorf("откат не должен выполняться до locking: rollback=%+v unlocks=%d restores=%d", data.Rollback, unlocks, restorer.calls)
+	}
+}
+
+// TestGit2StoreHandler_Rollback_PartialFailure проверяет отчёт об ошибках отката
+// и сохранение исходной ошибки.
+func TestGit2StoreHandler_Rollback_PartialFailure(t *testing.T) {
+	cfg := createTestConfig(t)
+	ccOp := &mockConvertConfigOperator{
+		storeCommitFunc: func(context.Context, *slog.Logger, *config.Config) error { return errors.New("commit failed") },
+		storeUnlockFunc: func(context.Context, *slog.Logger, *config.Config) error { return errors.New("unlock failed") },
+	}
+	h := &Git2StoreHandler{
+		gitFactory:           &mockGitFactory{gitOp: &mockGitOperator{}},
+		convertConfigFactory: &mockConvertConfigFactory{ccOp: ccOp},
+		backupCreator:        &mockBackupCreator{},
+		backupRestorer: &mockBackupRestorer{restoreFunc: func(string, string) error {
+			return store.ErrBackupNotRestorable
+		}},
+	}
+
+	data, err := executeJSON(t, h, cfg)
+
+	if err == nil || !strings.Contains(err.Error(), "commit failed") {
+		t.Fatalf("должна возвращаться исходная ошибка, got %v", err)
+	}
+	rb := data.Rollback
+	if rb == nil {
+		t.Fatal("результат отката должен быть в Git2StoreData")
+	}
+	if rb.Success || rb.LocksReleased || rb.StoreRestored || !rb.UnlockOnly || rb.Note == "" {
+		t.Errorf("неожиданный результат отката: %+v", rb)
+	}
+	if len(data.Errors) != 2 || !strings.Contains(data.Errors[1], "unlock failed") {
+		t.Errorf("ошибки отката должны дополнять исходную ошибку: %v", data.Errors)
+	}
+}
+
+// TestGit2StoreHandler_Rollback_UnlockOnly проверяет, что откат серверного хранилища
+// явно отмечается как освобождение объектов без восстановления.
+func TestGit2StoreHandler_Rollback_UnlockOnly(t *testing.T) {
+	cfg := createTestConfig(t)
+	ccOp := &mockConvertConfigOperator{
+		storeCommitFunc: func(context.Context, *slog.Logger, *config.Config) error { return errors.New("commit failed") },
+	}
+	h := &Git2StoreHandler{
+		gitFactory:           &mockGitFactory{gitOp: &mockGitOperator{}},
+		convertConfigFactory: &mockConvertConfigFactory{ccOp: ccOp},
+		backupCreator:        &mockBackupCreator{},
+		backupRestorer: &mockBackupRestorer{restoreFunc: func(string, string) error {
+			return store.ErrBackupNotRestorable
+		}},
+	}
+
+	data, _ := executeJSON(t, h, cfg)
+
+	if data.RollbackScope != RollbackScopeUnlockOnly {
+		t.Errorf("RollbackScope = %q, ожидается %q для %s", data.RollbackScope, RollbackScopeUnlockOnly, storeRootFor(cfg))
+	}
+	rb := data.Rollback
+	if rb == nil {
+		t.Fatal("результат отката должен быть в Git2StoreData")
+	}
+	if !rb.UnlockOnly || rb.StoreRestored || !rb.LocksReleased {
+		t.Errorf("откат tcp:// должен быть только освобождением объектов: %+v", rb)
+	}
+	if got := rb.summary(); !strings.HasPrefix(got, "откат только освобождение объектов: ") ||
+		!strings.Contains(got, "хранилище не восстановлено") {
+		t.Errorf("summary() = %q", got)
+	}
+}
+
+// TestRollbackScopeFor проверяет объём отката для файлового и серверного хранилища.
+func TestRollbackScopeFor(t *testing.T) {
+	tests := []struct {
+		storeRoot string
+		want      string
+	}{
+		{"tcp://server/gitops/owner/repo", RollbackScopeUnlockOnly},
+		{"http://server/repo", RollbackScopeUnlockOnly},
+		{"/var/1c/store", RollbackScopeRestore},
+	}
+	for _, tt := range tests {
+		if got := rollbackScopeFor(tt.storeRoot); got != tt.want {
+			t.Errorf("rollbackScopeFor(%q) = %q, ожидается %q", tt.storeRoot, got, tt.want)
+		}
+	}
+}
+
+// TestGit2StoreHandler_Rollback_TextError проверяет описание отката в текстовой ошибке.
+func TestGit2StoreHandler_Rollback_TextError(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "")
+	cfg := createTestConfig(t)
+	ccOp := &mockConvertConfigOperator{
+		mergeFunc: func(context.Context, *slog.Logger, *config.Config) error { return errors.New("merge failed") },
+	}
+	h := &Git2StoreHandler{
+		gitFactory:           &mockGitFactory{gitOp: &mockGitOperator{}},
+		convertConfigFactory: &mockConvertConfigFactory{ccOp: ccOp},
+		backupCreator:        &mockBackupCreator{},
+		backupRestorer:       &mockBackupRestorer{},
+	}
+
+	err := executeQuiet(t, h, cfg)
+
+	if err == nil {
+		t.Fatal("ожидалась ошибка merging")
+	}
+	for _, want := range []string{"ERR_GIT2STORE_MERGE", "/tmp/backup_test", "откат выполнен", "хранилище восстановлено"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("ошибка %q не содержит %q", err.Error(), want)
+		}
+	}
+}
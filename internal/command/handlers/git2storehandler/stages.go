@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -77,6 +78,9 @@ func (h *Git2StoreHandler) executeStageCreatingBackup(cfg *config.Config, log *s
 	}
 
 	log.Info(stageName+": резервная копия создана", slog.String("backup_path", backupPath))
+	if h.checkpoint != nil {
+		h.checkpoint.BackupPath = backupPath
+	}
 	h.recordStageSuccess(data, stageName, stageStart)
 	return backupPath, nil
 }
@@ -94,6 +98,9 @@ func (h *Git2StoreHandler) executeStageCloning(ctx context.Context, cfg *config.
 		return nil, fmt.Errorf("ERR_GIT2STORE_CLONE: %s", err.Error())
 	}
 	cfg.RepPath = repPath
+	if h.checkpoint != nil {
+		h.checkpoint.RepPath = repPath
+	}
 
 	gitOp, err := h.createGit(log, cfg)
 	if err != nil {
@@ -148,6 +155,20 @@ func (h *Git2StoreHandler) executeStageCreatingTempDb(ctx context.Context, cfg *
 		tempDbPath = strings.TrimPrefix(dbConnectString, "/F ")
 	}
 
+	user, pass := oneDBCredentials(cfg)
+	ccOp.SetOneDB(dbConnectString, user, pass)
+	if h.checkpoint != nil {
+		h.checkpoint.TempDbPath = tempDbPath
+		h.checkpoint.TempDbConnect = dbConnectString
+	}
+
+	log.Info(stageName+": временная БД создана", slog.String("path", tempDbPath))
+	h.recordStageSuccess(data, stageName, stageStart)
+	return tempDbPath, nil
+}
+
+// oneDBCredentials возвращает учётные данные для подключения к временной БД.
+func oneDBCredentials(cfg *config.Config) (string, string) {
 	user := constants.DefaultUser
 	pass := constants.DefaultPass
 	if cfg.AppConfig != nil && cfg.AppConfig.Users.Db != "" {
@@ -156,11 +177,7 @@ func (h *Git2StoreHandler) executeStageCreatingTempDb(ctx context.Context, cfg *
 	if cfg.SecretConfig != nil && cfg.SecretConfig.Passwords.Db != "" {
 		pass = cfg.SecretConfig.Passwords.Db
 	}
-	ccOp.SetOneDB(dbConnectString, user, pass)
-
-	log.Info(stageName+": временная БД создана", slog.String("path", tempDbPath))
-	h.recordStageSuccess(data, stageName, stageStart)
-	return tempDbPath, nil
+	return user, pass
 }
 
 // executeStageLoadingConfig загружает конфигурацию конвертации (AC-2).
@@ -235,6 +252,7 @@ func (h *Git2StoreHandler) executeStageUnbinding(ctx context.Context, cfg *confi
 // executeStageLoadingDb загружает конфигурацию в БД (AC-2).
 func (h *Git2StoreHandler) executeStageLoadingDb(ctx context.Context, cfg *config.Config, log *slog.Logger, data *Git2StoreData, ccOp ConvertConfigOperator) error {
 	stageStart := time.Now()
+	defer attachExtensionResults(data, ccOp)
 	stageName := StageLoadingDb
 	log.Info(stageName + ": загрузка конфигурации в базу данных")
 	data.StageCurrent = stageName
@@ -268,6 +286,7 @@ func (h *Git2StoreHandler) executeStageUpdatingDb(ctx context.Context, cfg *conf
 // executeStageDumpingDb выгружает БД (AC-2).
 func (h *Git2StoreHandler) executeStageDumpingDb(ctx context.Context, cfg *config.Config, log *slog.Logger, data *Git2StoreData, ccOp ConvertConfigOperator) error {
 	stageStart := time.Now()
+	defer attachExtensionResults(data, ccOp)
 	stageName := StageDumpingDb
 	log.Info(stageName + ": выгрузка базы данных")
 	data.StageCurrent = stageName
@@ -285,6 +304,7 @@ func (h *Git2StoreHandler) executeStageDumpingDb(ctx context.Context, cfg *confi
 // executeStageBinding привязывает к хранилищу (AC-2).
 func (h *Git2StoreHandler) executeStageBinding(ctx context.Context, cfg *config.Config, log *slog.Logger, data *Git2StoreData, ccOp ConvertConfigOperator) error {
 	stageStart := time.Now()
+	defer attachExtensionResults(data, ccOp)
 	stageName := StageBinding
 	log.Info(stageName + ": привязка к хранилищу")
 	data.StageCurrent = stageName
@@ -302,6 +322,7 @@ func (h *Git2StoreHandler) executeStageBinding(ctx context.Context, cfg *config.
 // executeStageLocking блокирует объекты в хранилище (AC-2).
 func (h *Git2StoreHandler) executeStageLocking(ctx context.Context, cfg *config.Config, log *slog.Logger, data *Git2StoreData, ccOp ConvertConfigOperator) error {
 	stageStart := time.Now()
+	defer attachExtensionResults(data, ccOp)
 	stageName := StageLocking
 	log.Info(stageName + ": блокировка объектов в хранилище")
 	data.StageCurrent = stageName
@@ -319,6 +340,7 @@ func (h *Git2StoreHandler) executeStageLocking(ctx context.Context, cfg *config.
 // executeStageMerging выполняет слияние (AC-2).
 func (h *Git2StoreHandler) executeStageMerging(ctx context.Context, cfg *config.Config, log *slog.Logger, data *Git2StoreData, ccOp ConvertConfigOperator) error {
 	stageStart := time.Now()
+	defer attachExtensionResults(data, ccOp)
 	stageName := StageMerging
 	log.Info(stageName + ": слияние конфигураций")
 	data.StageCurrent = stageName
@@ -336,6 +358,7 @@ func (h *Git2StoreHandler) executeStageMerging(ctx context.Context, cfg *config.
 // executeStageCommitting фиксирует изменения в хранилище (AC-2).
 func (h *Git2StoreHandler) executeStageCommitting(ctx context.Context, cfg *config.Config, log *slog.Logger, data *Git2StoreData, ccOp ConvertConfigOperator) error {
 	stageStart := time.Now()
+	defer attachExtensionResults(data, ccOp)
 	stageName := StageCommitting
 	log.Info(stageName + ": фиксация изменений в хранилище")
 	data.StageCurrent = stageName
@@ -350,13 +373,30 @@ func (h *Git2StoreHandler) executeStageCommitting(ctx context.Context, cfg *conf
 	return nil
 }
 
-// recordStageSuccess записывает успешный результат этапа.
+// attachExtensionResults добавляет к последнему записанному этапу результаты
+// обработки расширений. Вызывается через defer после recordStageSuccess/recordStageError.
+func attachExtensionResults(data *Git2StoreData, ccOp ConvertConfigOperator) {
+	if len(data.StagesCompleted) == 0 {
+		return
+	}
+	data.StagesCompleted[len(data.StagesCompleted)-1].Extensions = ccOp.ExtensionResults()
+}
+
+// recordStageSuccess записывает успешный результат этапа и обновляет checkpoint.
+// Если checkpoint не удалось сохранить, прогон продолжается без возможности BR_RESUME.
 func (h *Git2StoreHandler) recordStageSuccess(data *Git2StoreData, stageName string, stageStart time.Time) {
 	data.StagesCompleted = append(data.StagesCompleted, StageResult{
 		Name:       stageName,
 		Success:    true,
 		DurationMs: time.Since(stageStart).Milliseconds(),
 	})
+	if h.checkpoint != nil && !slices.Contains(alwaysRerunStages, stageName) {
+		if err := h.checkpoint.markDone(stageName); err != nil {
+			slog.Default().Warn("Не удалось сохранить checkpoint, BR_RESUME для прогона недоступен",
+				slog.String("stage", stageName), slog.String("error", err.Error()))
+			h.checkpoint = nil
+		}
+	}
 }
 
 // recordStageError записывает ошибку этапа и возвращает форматированную ошибку.
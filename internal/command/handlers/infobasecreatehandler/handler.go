@@ -0,0 +1,341 @@
+// Package infobasecreatehandler реализует NR-команду nr-infobase-create
+// для регистрации информационной базы в кластере 1C через RAC на существующей
+// (или создаваемой) базе данных СУБД.
+package infobasecreatehandler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
+	"github.com/Kargones/apk-ci/internal/command"
+	"github.com/Kargones/apk-ci/internal/command/handlers/infobaseutil"
+	"github.com/Kargones/apk-ci/internal/command/handlers/racutil"
+	errhandler "github.com/Kargones/apk-ci/internal/command/handlers/shared"
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/pkg/dryrun"
+	"github.com/Kargones/apk-ci/internal/pkg/output"
+	"github.com/Kargones/apk-ci/internal/pkg/tracing"
+)
+
+// Коды ошибок nr-infobase-create.
+const (
+	ErrInfobaseProductionForbidden = "INFOBASE.PRODUCTION_FORBIDDEN"
+	ErrInfobaseAlreadyExists       = "INFOBASE.ALREADY_EXISTS"
+	ErrInfobaseCreateFailed        = "RAC.INFOBASE_CREATE_FAILED"
+)
+
+// Compile-time interface check.
+var _ command.Handler = (*InfobaseCreateHandler)(nil)
+
+func RegisterCmd() error {
+	return command.Register(&InfobaseCreateHandler{})
+}
+
+// InfobaseCreateData содержит данные ответа nr-infobase-create.
+type InfobaseCreateData struct {
+	// Infobase — имя информационной базы
+	Infobase string `json:"infobase"`
+	// UUID — идентификатор созданной информационной базы
+	UUID string `json:"uuid"`
+	// Server — сервер 1C
+	Server string `json:"server"`
+	// DBMS — тип СУБД
+	DBMS string `json:"dbms"`
+	// DBServer — сервер СУБД
+	DBServer string `json:"db_server"`
+	// DBName — имя базы данных СУБД
+	DBName string `json:"db_name"`
+	// CreatedDatabase — база данных СУБД создана командой (BR_INFOBASE_CREATE_DATABASE)
+	CreatedDatabase bool `json:"created_database"`
+	// StateChanged — информационная база зарегистрирована
+	StateChanged bool `json:"state_changed"`
+	// DbConfig — предложение изменения dbconfig.yaml
+	DbConfig *infobaseutil.DbConfigProposal `json:"dbconfig,omitempty"`
+}
+
+// writeText выводит результат в человекочитаемом формате.
+func (d *InfobaseCreateData) writeText(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "Информационная база %s зарегистрирована на сервере %s (%s)\n", d.Infobase, d.Server, d.UUID); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "База данных: %s/%s (%s)\n", d.DBServer, d.DBName, d.DBMS); err != nil {
+		return err
+	}
+	return d.DbConfig.WriteText(w)
+}
+
+// InfobaseCreateHandler обрабатывает команду nr-infobase-create.
+// Регламентные задания созданной базы блокируются: регистрация обычно
+// выполняется на копии продуктивной базы, и её задания не должны запускаться.
+type InfobaseCreateHandler struct {
+	// racClient — опциональный RAC клиент (nil в production, mock в тестах)
+	racClient rac.Client
+	// proposer — опциональный клиент предложения изменений dbconfig.yaml (nil в production)
+	proposer *infobaseutil.DbConfigProposer
+}
+
+// Name возвращает имя команды.
+func (h *InfobaseCreateHandler) Name() string {
+	return constants.ActNRInfobaseCreate
+}
+
+// Description возвращает описание команды для вывода в help.
+func (h *InfobaseCreateHandler) Description() string {
+	return "Регистрация информационной базы в кластере 1C"
+}
+
+// Execute выполняет команду nr-infobase-create.
+func (h *InfobaseCreateHandler) Execute(ctx context.Context, cfg *config.Config) error {
+	start := time.Now()
+
+	traceID := tracing.TraceIDFromContext(ctx)
+	if traceID == "" {
+		traceID = tracing.GenerateTraceID()
+	}
+
+	format := os.Getenv("BR_OUTPUT_FORMAT")
+
+	log := slog.Default().With(slog.String("trace_id", traceID), slog.String("command", constants.ActNRInfobaseCreate))
+
+	if cfg == nil || cfg.InfobaseName == "" {
+		log.Error("Не указано имя информационной базы")
+		return h.writeError(format, traceID, start,
+			"CONFIG.INFOBASE_MISSING",
+			"Не указано имя информационной базы (BR_INFOBASE_NAME)")
+	}
+	name := cfg.InfobaseName
+	log = log.With(slog.String("infobase", name))
+
+	// КРИТИЧНО: продуктивные базы не регистрируются и не изменяются этой командой
+	if cfg.IsProductionDb(name) {
+		log.Error("Попытка регистрации продуктивной базы")
+		return h.writeError(format, traceID, start, ErrInfobaseProductionForbidden,
+			fmt.Sprintf("Регистрация продуктивной базы '%s' запрещена", name))
+	}
+
+	server, err := infobaseutil.ResolveServer(cfg)
+	if err != nil {
+		return h.writeError(format, traceID, start, "CONFIG.SERVER_MISSING", err.Error())
+	}
+
+	params := rac.InfobaseParams{
+		Name:              name,
+		DBMS:              envOrDefault(constants.EnvInfobaseDBMS, infobaseutil.DefaultDBMS),
+		DBServer:          envOrDefault(constants.EnvInfobaseDbServer, cfg.GetDbServer(name)),
+		DBName:            envOrDefault(constants.EnvInfobaseDbName, name),
+		ScheduledJobsDeny: true,
+	}
+	params.DBUser, params.DBPassword = infobaseutil.DBCredentials(cfg)
+	if params.DBServer == "" {
+		return h.writeError(format, traceID, start, "CONFIG.DB_SERVER_MISSING",
+			fmt.Sprintf("Не удалось определить сервер СУБД для '%s': укажите %s", name, constants.EnvInfobaseDbServer))
+	}
+	if v := os.Getenv(constants.EnvInfobaseCreateDatabase); v != "" {
+		createDatabase, err := strconv.ParseBool(v)
+		if err != nil {
+			return h.writeError(format, traceID, start, "CONFIG.INVALID_PARAM",
+				fmt.Sprintf("Недопустимое значение %s: %q", constants.EnvInfobaseCreateDatabase, v))
+		}
+		params.CreateDatabase = createDatabase
+	}
+
+	if err := racutil.RequireCapabilities(cfg, racutil.CapInfobaseManager); err != nil {
+		log.Error("RAC клиент не поддерживает управление информационными базами", slog.String("error", err.Error()))
+		return h.writeError(format, traceID, start, racutil.ErrNativeUnsupported, err.Error())
+	}
+
+	// Dry-run имеет приоритет над plan-only
+	if dryrun.IsDryRun() {
+		log.Info("Dry-run режим: построение плана")
+		plan := buildPlan(server, params)
+		return output.WriteDryRunResult(os.Stdout, format, constants.ActNRInfobaseCreate, traceID, constants.APIVersion, start, plan)
+	}
+	if dryrun.IsPlanOnly() {
+		log.Info("Plan-only режим: отображение плана операций")
+		plan := buildPlan(server, params)
+		return output.WritePlanOnlyResult(os.Stdout, format, constants.ActNRInfobaseCreate, traceID, constants.APIVersion, start, plan)
+	}
+
+	racClient := h.racClient
+	if racClient == nil {
+		racClient, err = racutil.NewClientForServer(cfg, server)
+		if err != nil {
+			log.Error("Не удалось создать RAC клиент", slog.String("error", err.Error()))
+			return h.writeError(format, traceID, start, "RAC.CLIENT_CREATE_FAILED",
+				fmt.Sprintf("Не удалось создать RAC клиент: %v", err))
+		}
+	}
+	manager, ok := racClient.(rac.InfobaseManager)
+	if !ok {
+		return h.writeError(format, traceID, start, "RAC.INFOBASE_UNSUPPORTED",
+			"RAC клиент не поддерживает управление информационными базами")
+	}
+
+	clusterInfo, err := racClient.GetClusterInfo(ctx)
+	if err != nil {
+		log.Error("Не удалось получить информацию о кластере", slog.String("error", err.Error()))
+		return h.writeError(format, traceID, start, "RAC.CLUSTER_FAILED",
+			fmt.Sprintf("Не удалось получить информацию о кластере: %v", err))
+	}
+
+	if existing, err := racClient.GetInfobaseInfo(ctx, clusterInfo.UUID, name); err == nil {
+		return h.writeError(format, traceID, start, ErrInfobaseAlreadyExists,
+			fmt.Sprintf("Информационная база '%s' уже зарегистрирована на сервере %s (%s)", name, server, existing.UUID))
+	} else if !infobaseutil.IsNotFound(err) {
+		log.Error("Не удалось проверить наличие информационной базы", slog.String("error", err.Error()))
+		return h.writeError(format, traceID, start, "RAC.INFOBASE_FAILED",
+			fmt.Sprintf("Не удалось проверить наличие информационной базы: %v", err))
+	}
+
+	log.Info("Регистрация информационной базы",
+		slog.String("server", server),
+		slog.String("db_server", params.DBServer),
+		slog.String("db_name", params.DBName),
+		slog.Bool("create_database", params.CreateDatabase))
+	info, err := manager.CreateInfobase(ctx, clusterInfo.UUID, params)
+	if err != nil {
+		log.Error("Не удалось зарегистрировать информационную базу", slog.String("error", err.Error()))
+		return h.writeError(format, traceID, start, ErrInfobaseCreateFailed,
+			fmt.Sprintf("Не удалось зарегистрировать информационную базу: %v", err))
+	}
+	log.Info("Информационная база зарегистрирована", slog.String("uuid", info.UUID))
+
+	proposer := h.proposer
+	if proposer == nil {
+		proposer = &infobaseutil.DbConfigProposer{}
+	}
+	proposal := proposer.Propose(ctx, log, cfg, infobaseutil.DbConfigChange{
+		Action:   infobaseutil.DbConfigAdd,
+		Infobase: name,
+		Info:     config.DatabaseInfo{OneServer: server, DbServer: params.DBServer},
+	}, constants.ActNRInfobaseCreate, traceID)
+
+	data := &InfobaseCreateData{
+		Infobase:        name,
+		UUID:            info.UUID,
+		Server:          server,
+		DBMS:            params.DBMS,
+		DBServer:        params.DBServer,
+		DBName:          params.DBName,
+		CreatedDatabase: params.CreateDatabase,
+		StateChanged:    true,
+		DbConfig:        proposal,
+	}
+	return h.writeSuccess(format, traceID, start, data)
+}
+
+// buildPlan создаёт план регистрации информационной базы для dry-run и plan-only.
+func buildPlan(server string, params rac.InfobaseParams) *output.DryRunPlan {
+	parameters := infobaseutil.PlanParams(server, params)
+	parameters["create_database"] = params.CreateDatabase
+	parameters["scheduled_jobs_deny"] = params.ScheduledJobsDeny
+
+	changes := []string{
+		fmt.Sprintf("Информационная база %s будет зарегистрирована на сервере %s", params.Name, server),
+		"Регламентные задания базы будут заблокированы",
+	}
+	if params.CreateDatabase {
+		changes = append(changes, fmt.Sprintf("Будет создана база данных %s на сервере %s", params.DBName, params.DBServer))
+	}
+
+	steps := []output.PlanStep{
+		{
+			Order:      1,
+			Operation:  "Проверка отсутствия информационной базы в кластере",
+			Parameters: map[string]any{"server": server, "infobase": params.Name},
+			ExpectedChanges: []string{
+				"Нет изменений — только проверка",
+			},
+		},
+		{
+			Order:           2,
+			Operation:       "Регистрация информационной базы",
+			Parameters:      parameters,
+			ExpectedChanges: changes,
+		},
+	}
+	if infobaseutil.DbConfigPREnabled() {
+		steps = append(steps, output.PlanStep{
+			Order:     3,
+			Operation: "Предложение изменения dbconfig.yaml",
+			Parameters: map[string]any{
+				"one-server": server,
+				"prod":       false,
+				"dbserver":   params.DBServer,
+			},
+			ExpectedChanges: []string{
+				fmt.Sprintf("Будет создан Pull Request с добавлением записи %s в dbconfig.yaml", params.Name),
+			},
+		})
+	}
+
+	summary := fmt.Sprintf("Регистрация информационной базы %s (%s/%s)", params.Name, params.DBServer, params.DBName)
+	return dryrun.BuildPlanWithSummary(constants.ActNRInfobaseCreate, steps, summary)
+}
+
+// envOrDefault возвращает значение переменной окружения key или def, если она пуста.
+func envOrDefault(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// writeSuccess выводит успешный результат.
+func (h *InfobaseCreateHandler) writeSuccess(format, traceID string, start time.Time, data *InfobaseCreateData) error {
+	if format != output.FormatJSON {
+		return data.writeText(os.Stdout)
+	}
+
+	result := &output.Result{
+		Status:  output.StatusSuccess,
+		Command: constants.ActNRInfobaseCreate,
+		Data:    data,
+		Metadata: &output.Metadata{
+			DurationMs: time.Since(start).Milliseconds(),
+			TraceID:    traceID,
+			APIVersion: constants.APIVersion,
+		},
+	}
+
+	writer := output.NewWriter(format)
+	return writer.Write(os.Stdout, result)
+}
+
+// writeError выводит структурированную ошибку и возвращает error.
+func (h *InfobaseCreateHandler) writeError(format, traceID string, start time.Time, code, message string) error {
+	// Текстовый формат — человекочитаемый вывод ошибки
+	if format != output.FormatJSON {
+		return errhandler.HandleError(message, code)
+	}
+
+	result := &output.Result{
+		Status:  output.StatusError,
+		Command: constants.ActNRInfobaseCreate,
+		Error: &output.ErrorInfo{
+			Code:    code,
+			Message: message,
+		},
+		Metadata: &output.Metadata{
+			DurationMs: time.Since(start).Milliseconds(),
+			TraceID:    traceID,
+			APIVersion: constants.APIVersion,
+		},
+	}
+
+	writer := output.NewWriter(format)
+	if writeErr := writer.Write(os.Stdout, result); writeErr != nil {
+		slog.Default().Error("Не удалось записать JSON-ответ об ошибке",
+			slog.String("trace_id", traceID),
+			slog.String("error", writeErr.Error()))
+	}
+
+	return fmt.Errorf("%s: %s", code, message)
+}
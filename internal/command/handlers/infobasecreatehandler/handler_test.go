@@ -0,0 +1,330 @@
+package infobasecreatehandler
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac/ractest"
+	"github.com/Kargones/apk-ci/internal/command"
+	"github.com/Kargones/apk-ci/internal/command/handlers/infobaseutil"
+	"github.com/Kargones/apk-ci/internal/command/handlers/racutil"
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/entity/gitea"
+	"github.com/Kargones/apk-ci/internal/pkg/apperrors"
+	"github.com/Kargones/apk-ci/internal/pkg/output"
+	"github.com/Kargones/apk-ci/internal/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInfobaseCreateHandler_Registration(t *testing.T) {
+	h, ok := command.Get(constants.ActNRInfobaseCreate)
+	require.True(t, ok)
+	assert.Equal(t, constants.ActNRInfobaseCreate, h.Name())
+	assert.NotEmpty(t, h.Description())
+}
+
+func testConfig() *config.Config {
+	cfg := &config.Config{
+		InfobaseName: "erp_test",
+		AppConfig:    &config.AppConfig{},
+		SecretConfig: &config.SecretConfig{},
+		DbConfig: map[string]*config.DatabaseInfo{
+			"erp":      {OneServer: "srv-1c", Prod: true, DbServer: "sql-prod"},
+			"erp_test": {DbServer: "sql-test"},
+		},
+		RacConfig: &config.RacConfig{RacServer: "srv-1c"},
+	}
+	cfg.AppConfig.Users.Mssql = "sa"
+	cfg.SecretConfig.Passwords.Mssql = "sql-secret"
+	return cfg
+}
+
+// notFoundMock возвращает mock RAC клиента, в кластере которого нет информационной базы.
+func notFoundMock() *ractest.MockRACClient {
+	mock := ractest.NewMockRACClient()
+	mock.GetInfobaseInfoFunc = func(_ context.Context, _, name string) (*rac.InfobaseInfo, error) {
+		return nil, apperrors.NewAppError(rac.ErrRACNotFound, "информационная база '"+name+"' не найдена", nil)
+	}
+	return mock
+}
+
+func setDefaults(t *testing.T) {
+	t.Setenv(constants.EnvDryRun, "")
+	t.Setenv(constants.EnvPlanOnly, "")
+	t.Setenv(constants.EnvInfobaseServer, "")
+	t.Setenv(constants.EnvInfobaseDBMS, "")
+	t.Setenv(constants.EnvInfobaseDbServer, "")
+	t.Setenv(constants.EnvInfobaseDbName, "")
+	t.Setenv(constants.EnvInfobaseCreateDatabase, "")
+	t.Setenv(constants.EnvInfobaseDbConfigPR, "")
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+}
+
+func run(t *testing.T, h *InfobaseCreateHandler, cfg *config.Config) (*InfobaseCreateData, string, error) {
+	t.Helper()
+	var execErr error
+	out := testutil.CaptureStdout(t, func() {
+		execErr = h.Execute(context.Background(), cfg)
+	})
+	if execErr != nil {
+		return nil, out, execErr
+	}
+	var result struct {
+		output.Result
+		Data InfobaseCreateData `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+	return &result.Data, out, nil
+}
+
+func TestInfobaseCreateHandler_Execute_Success(t *testing.T) {
+	setDefaults(t)
+	mock := notFoundMock()
+	var got rac.InfobaseParams
+	mock.CreateInfobaseFunc = func(_ context.Context, _ string, params rac.InfobaseParams) (*rac.InfobaseInfo, error) {
+		got = params
+		return &rac.InfobaseInfo{UUID: "ib-uuid", Name: params.Name}, nil
+	}
+
+	data, _, err := run(t, &InfobaseCreateHandler{racClient: mock}, testConfig())
+	require.NoError(t, err)
+
+	assert.Equal(t, "erp_test", got.Name)
+	assert.Equal(t, "MSSQLServer", got.DBMS)
+	assert.Equal(t, "sql-test", got.DBServer, "сервер СУБД из dbconfig")
+	assert.Equal(t, "erp_test", got.DBName)
+	assert.Equal(t, "sa", got.DBUser)
+	assert.Equal(t, "sql-secret", got.DBPassword)
+	assert.True(t, got.ScheduledJobsDeny)
+	assert.False(t, got.CreateDatabase)
+
+	assert.Equal(t, "ib-uuid", data.UUID)
+	assert.Equal(t, "srv-1c", data.Server)
+	assert.True(t, data.StateChanged)
+	require.NotNil(t, data.DbConfig)
+	assert.Equal(t, "Gitea не настроен", data.DbConfig.Skipped)
+}
+
+func TestInfobaseCreateHandler_Execute_EnvParams(t *testing.T) {
+	setDefaults(t)
+	t.Setenv(constants.EnvInfobaseServer, "srv-new")
+	t.Setenv(constants.EnvInfobaseDBMS, "PostgreSQL")
+	t.Setenv(constants.EnvInfobaseDbServer, "pg-01")
+	t.Setenv(constants.EnvInfobaseDbName, "erp_copy")
+	t.Setenv(constants.EnvInfobaseCreateDatabase, "true")
+	mock := notFoundMock()
+	var got rac.InfobaseParams
+	mock.CreateInfobaseFunc = func(_ context.Context, _ string, params rac.InfobaseParams) (*rac.InfobaseInfo, error) {
+		got = params
+		return &rac.InfobaseInfo{UUID: "ib-uuid"}, nil
+	}
+
+	data, _, err := run(t, &InfobaseCreateHandler{racClient: mock}, testConfig())
+	require.NoError(t, err)
+	assert.Equal(t, "PostgreSQL", got.DBMS)
+	assert.Equal(t, "pg-01", got.DBServer)
+	assert.Equal(t, "erp_copy", got.DBName)
+	assert.True(t, got.CreateDatabase)
+	assert.Equal(t, "srv-new", data.Server)
+	assert.True(t, data.CreatedDatabase)
+}
+
+func TestInfobaseCreateHandler_Execute_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      func() *config.Config
+		env      map[string]string
+		mock     func() *ractest.MockRACClient
+		wantCode string
+	}{
+		{
+			name:     "нет имени базы",
+			cfg:      func() *config.Config { c := testConfig(); c.InfobaseName = ""; return c },
+			wantCode: "CONFIG.INFOBASE_MISSING",
+		},
+		{
+			name:     "продуктивная база",
+			cfg:      func() *config.Config { c := testConfig(); c.InfobaseName = "erp"; return c },
+			wantCode: ErrInfobaseProductionForbidden,
+		},
+		{
+			name:     "нет сервера СУБД",
+			cfg:      func() *config.Config { c := testConfig(); c.InfobaseName = "zup_test"; return c },
+			wantCode: "CONFIG.DB_SERVER_MISSING",
+		},
+		{
+			name:     "некорректный BR_INFOBASE_CREATE_DATABASE",
+			cfg:      testConfig,
+			env:      map[string]string{constants.EnvInfobaseCreateDatabase: "maybe"},
+			wantCode: "CONFIG.INVALID_PARAM",
+		},
+		{
+			name:     "база уже зарегистрирована",
+			cfg:      testConfig,
+			mock:     ractest.NewMockRACClient,
+			wantCode: ErrInfobaseAlreadyExists,
+		},
+		{
+			name: "ошибка проверки базы",
+			cfg:  testConfig,
+			mock: func() *ractest.MockRACClient {
+				m := ractest.NewMockRACClient()
+				m.GetInfobaseInfoFunc = func(context.Context, string, string) (*rac.InfobaseInfo, error) {
+					return nil, apperrors.NewAppError(rac.ErrRACExec, "connection refused", nil)
+				}
+				return m
+			},
+			wantCode: "RAC.INFOBASE_FAILED",
+		},
+		{
+			name: "ошибка регистрации",
+			cfg:  testConfig,
+			mock: func() *ractest.MockRACClient {
+				m := notFoundMock()
+				m.CreateInfobaseFunc = func(context.Context, string, rac.InfobaseParams) (*rac.InfobaseInfo, error) {
+					return nil, errors.New("база данных не найдена")
+				}
+				return m
+			},
+			wantCode: ErrInfobaseCreateFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setDefaults(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			mock := notFoundMock()
+			if tt.mock != nil {
+				mock = tt.mock()
+			}
+			_, out, err := run(t, &InfobaseCreateHandler{racClient: mock}, tt.cfg())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantCode)
+			assert.Contains(t, out, tt.wantCode)
+		})
+	}
+}
+
+// plainClient — RAC клиент без InfobaseManager.
+type plainClient struct{ rac.Client }
+
+func TestInfobaseCreateHandler_Execute_Unsupported(t *testing.T) {
+	setDefaults(t)
+
+	_, _, err := run(t, &InfobaseCreateHandler{racClient: plainClient{notFoundMock()}}, testConfig())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "RAC.INFOBASE_UNSUPPORTED")
+}
+
+func TestInfobaseCreateHandler_Execute_NativeUnsupported(t *testing.T) {
+	setDefaults(t)
+	cfg := testConfig()
+	cfg.ImplementationsConfig = &config.ImplementationsConfig{RAC: "native"}
+
+	_, _, err := run(t, &InfobaseCreateHandler{racClient: notFoundMock()}, cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), racutil.ErrNativeUnsupported)
+}
+
+func TestInfobaseCreateHandler_Execute_DryRun(t *testing.T) {
+	setDefaults(t)
+	t.Setenv(constants.EnvDryRun, "true")
+	mock := notFoundMock()
+	mock.CreateInfobaseFunc = func(context.Context, string, rac.InfobaseParams) (*rac.InfobaseInfo, error) {
+		t.Fatal("CreateInfobase не должен вызываться в dry-run")
+		return nil, nil
+	}
+
+	var execErr error
+	out := testutil.CaptureStdout(t, func() {
+		execErr = (&InfobaseCreateHandler{racClient: mock}).Execute(context.Background(), testConfig())
+	})
+	require.NoError(t, execErr)
+	assert.Contains(t, out, "Регистрация информационной базы")
+	assert.Contains(t, out, "dbconfig.yaml")
+	assert.NotContains(t, out, "sql-secret", "пароль СУБД не выводится в план")
+}
+
+func TestInfobaseCreateHandler_Execute_PlanOnly(t *testing.T) {
+	setDefaults(t)
+	t.Setenv(constants.EnvPlanOnly, "true")
+	t.Setenv(constants.EnvInfobaseDbConfigPR, "false")
+
+	var execErr error
+	out := testutil.CaptureStdout(t, func() {
+		execErr = (&InfobaseCreateHandler{racClient: notFoundMock()}).Execute(context.Background(), testConfig())
+	})
+	require.NoError(t, execErr)
+	assert.Contains(t, out, "Регистрация информационной базы")
+	assert.NotContains(t, out, "dbconfig.yaml", "PR отключён")
+}
+
+// fakeRepository — репозиторий dbconfig.yaml в памяти.
+type fakeRepository struct {
+	content []byte
+	pr      *gitea.CreatePROptions
+}
+
+func (f *fakeRepository) GetConfigData(context.Context, *slog.Logger, string) ([]byte, error) {
+	return []byte("erp_test:\n  dbserver: sql-test\n"), nil
+}
+
+func (f *fakeRepository) GetRepositoryContents(context.Context, string, string) ([]gitea.FileInfo, error) {
+	return []gitea.FileInfo{{Path: "dbconfig.yaml", SHA: "sha"}}, nil
+}
+
+func (f *fakeRepository) SetRepositoryStateWithNewBranch(_ context.Context, _ *slog.Logger, ops []gitea.ChangeFileOperation, _, _, _ string) (string, error) {
+	f.content, _ = base64.StdEncoding.DecodeString(ops[0].Content)
+	return "commit", nil
+}
+
+func (f *fakeRepository) CreatePRWithOptions(_ context.Context, opts gitea.CreatePROptions) (*gitea.PRResponse, error) {
+	f.pr = &opts
+	return &gitea.PRResponse{Number: 7, HTMLURL: "https://gitea.example.com/ops/config/pulls/7"}, nil
+}
+
+func TestInfobaseCreateHandler_Execute_ProposesDbConfig(t *testing.T) {
+	setDefaults(t)
+	cfg := testConfig()
+	cfg.GiteaURL = "https://gitea.example.com"
+	cfg.AccessToken = "token"
+	cfg.ConfigDbData = "https://gitea.example.com/api/v1/repos/ops/config/contents/dbconfig.yaml?ref=main"
+	repo := &fakeRepository{}
+	h := &InfobaseCreateHandler{
+		racClient: notFoundMock(),
+		proposer: &infobaseutil.DbConfigProposer{
+			NewRepository: func(*config.Config, *infobaseutil.DbConfigLocation) infobaseutil.DbConfigRepository { return repo },
+		},
+	}
+
+	data, _, err := run(t, h, cfg)
+	require.NoError(t, err)
+	require.NotNil(t, data.DbConfig)
+	assert.Equal(t, int64(7), data.DbConfig.PRNumber)
+	assert.Contains(t, string(repo.content), "one-server: srv-1c")
+	assert.Contains(t, string(repo.content), "prod: false")
+	require.NotNil(t, repo.pr)
+	assert.Equal(t, "main", repo.pr.Base)
+}
+
+func TestInfobaseCreateHandler_Execute_TextOutput(t *testing.T) {
+	setDefaults(t)
+	t.Setenv("BR_OUTPUT_FORMAT", "text")
+
+	var execErr error
+	out := testutil.CaptureStdout(t, func() {
+		execErr = (&InfobaseCreateHandler{racClient: notFoundMock()}).Execute(context.Background(), testConfig())
+	})
+	require.NoError(t, execErr)
+	assert.Contains(t, out, "Информационная база erp_test зарегистрирована на сервере srv-1c")
+	assert.Contains(t, out, "dbconfig.yaml: Gitea не настроен")
+}
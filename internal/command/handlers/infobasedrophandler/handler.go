@@ -0,0 +1,305 @@
+// Package infobasedrophandler реализует NR-команду nr-infobase-drop
+// для удаления информационной базы из кластера 1C через RAC
+// с сохранением, удалением или очисткой базы данных СУБД.
+package infobasedrophandler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
+	"github.com/Kargones/apk-ci/internal/command"
+	"github.com/Kargones/apk-ci/internal/command/handlers/infobaseutil"
+	"github.com/Kargones/apk-ci/internal/command/handlers/racutil"
+	errhandler "github.com/Kargones/apk-ci/internal/command/handlers/shared"
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/pkg/dryrun"
+	"github.com/Kargones/apk-ci/internal/pkg/output"
+	"github.com/Kargones/apk-ci/internal/pkg/tracing"
+)
+
+// Коды ошибок nr-infobase-drop.
+const (
+	ErrInfobaseProductionForbidden = "INFOBASE.PRODUCTION_FORBIDDEN"
+	ErrInfobaseNotInDbConfig       = "INFOBASE.NOT_IN_DBCONFIG"
+	ErrInfobaseDropFailed          = "RAC.INFOBASE_DROP_FAILED"
+)
+
+// Compile-time interface check.
+var _ command.Handler = (*InfobaseDropHandler)(nil)
+
+func RegisterCmd() error {
+	return command.Register(&InfobaseDropHandler{})
+}
+
+// InfobaseDropData содержит данные ответа nr-infobase-drop.
+type InfobaseDropData struct {
+	// Infobase — имя информационной базы
+	Infobase string `json:"infobase"`
+	// UUID — идентификатор удалённой информационной базы
+	UUID string `json:"uuid,omitempty"`
+	// Server — сервер 1C
+	Server string `json:"server"`
+	// Mode — режим удаления: keep, drop, clear
+	Mode string `json:"mode"`
+	// StateChanged — информационная база удалена (false — её не было в кластере)
+	StateChanged bool `json:"state_changed"`
+	// DbConfig — предложение удаления записи из dbconfig.yaml
+	DbConfig *infobaseutil.DbConfigProposal `json:"dbconfig,omitempty"`
+}
+
+// writeText выводит результат в человекочитаемом формате.
+func (d *InfobaseDropData) writeText(w io.Writer) error {
+	var err error
+	if d.StateChanged {
+		_, err = fmt.Fprintf(w, "Информационная база %s удалена с сервера %s (база данных: %s)\n", d.Infobase, d.Server, modeText(rac.InfobaseDropMode(d.Mode)))
+	} else {
+		_, err = fmt.Fprintf(w, "Информационная база %s не зарегистрирована на сервере %s\n", d.Infobase, d.Server)
+	}
+	if err != nil {
+		return err
+	}
+	return d.DbConfig.WriteText(w)
+}
+
+// modeText возвращает описание судьбы базы данных СУБД в режиме mode.
+func modeText(mode rac.InfobaseDropMode) string {
+	switch mode {
+	case rac.InfobaseDropDatabase:
+		return "удалена"
+	case rac.InfobaseDropClearDatabase:
+		return "очищена"
+	default:
+		return "сохранена"
+	}
+}
+
+// InfobaseDropHandler обрабатывает команду nr-infobase-drop.
+// Отсутствие базы в кластере не считается ошибкой: повторный запуск
+// после сбоя создания PR предлагает удаление записи из dbconfig.yaml снова.
+type InfobaseDropHandler struct {
+	// racClient — опциональный RAC клиент (nil в production, mock в тестах)
+	racClient rac.Client
+	// proposer — опциональный клиент предложения изменений dbconfig.yaml (nil в production)
+	proposer *infobaseutil.DbConfigProposer
+}
+
+// Name возвращает имя команды.
+func (h *InfobaseDropHandler) Name() string {
+	return constants.ActNRInfobaseDrop
+}
+
+// Description возвращает описание команды для вывода в help.
+func (h *InfobaseDropHandler) Description() string {
+	return "Удаление информационной базы из кластера 1C"
+}
+
+// Execute выполняет команду nr-infobase-drop.
+func (h *InfobaseDropHandler) Execute(ctx context.Context, cfg *config.Config) error {
+	start := time.Now()
+
+	traceID := tracing.TraceIDFromContext(ctx)
+	if traceID == "" {
+		traceID = tracing.GenerateTraceID()
+	}
+
+	format := os.Getenv("BR_OUTPUT_FORMAT")
+
+	log := slog.Default().With(slog.String("trace_id", traceID), slog.String("command", constants.ActNRInfobaseDrop))
+
+	if cfg == nil || cfg.InfobaseName == "" {
+		log.Error("Не указано имя информационной базы")
+		return h.writeError(format, traceID, start,
+			"CONFIG.INFOBASE_MISSING",
+			"Не указано имя информационной базы (BR_INFOBASE_NAME)")
+	}
+	name := cfg.InfobaseName
+	log = log.With(slog.String("infobase", name))
+
+	// КРИТИЧНО: удаляются только тестовые базы, описанные в dbconfig;
+	// база без записи в dbconfig считается продуктивной
+	dbInfo := cfg.DbConfig[name]
+	if dbInfo == nil {
+		log.Error("Попытка удаления базы, отсутствующей в dbconfig")
+		return h.writeError(format, traceID, start, ErrInfobaseNotInDbConfig,
+			fmt.Sprintf("База '%s' отсутствует в dbconfig: удаляются только тестовые базы из dbconfig", name))
+	}
+	if dbInfo.Prod {
+		log.Error("Попытка удаления продуктивной базы")
+		return h.writeError(format, traceID, start, ErrInfobaseProductionForbidden,
+			fmt.Sprintf("Удаление продуктивной базы '%s' запрещено", name))
+	}
+
+	mode, err := infobaseutil.ParseDropMode()
+	if err != nil {
+		return h.writeError(format, traceID, start, "CONFIG.INVALID_PARAM", err.Error())
+	}
+
+	server, err := infobaseutil.ResolveServer(cfg)
+	if err != nil {
+		return h.writeError(format, traceID, start, "CONFIG.SERVER_MISSING", err.Error())
+	}
+
+	if err := racutil.RequireCapabilities(cfg, racutil.CapInfobaseManager); err != nil {
+		log.Error("RAC клиент не поддерживает управление информационными базами", slog.String("error", err.Error()))
+		return h.writeError(format, traceID, start, racutil.ErrNativeUnsupported, err.Error())
+	}
+
+	// Dry-run имеет приоритет над plan-only
+	if dryrun.IsDryRun() {
+		log.Info("Dry-run режим: построение плана")
+		plan := buildPlan(name, server, mode)
+		return output.WriteDryRunResult(os.Stdout, format, constants.ActNRInfobaseDrop, traceID, constants.APIVersion, start, plan)
+	}
+	if dryrun.IsPlanOnly() {
+		log.Info("Plan-only режим: отображение плана операций")
+		plan := buildPlan(name, server, mode)
+		return output.WritePlanOnlyResult(os.Stdout, format, constants.ActNRInfobaseDrop, traceID, constants.APIVersion, start, plan)
+	}
+
+	racClient := h.racClient
+	if racClient == nil {
+		racClient, err = racutil.NewClientForServer(cfg, server)
+		if err != nil {
+			log.Error("Не удалось создать RAC клиент", slog.String("error", err.Error()))
+			return h.writeError(format, traceID, start, "RAC.CLIENT_CREATE_FAILED",
+				fmt.Sprintf("Не удалось создать RAC клиент: %v", err))
+		}
+	}
+	manager, ok := racClient.(rac.InfobaseManager)
+	if !ok {
+		return h.writeError(format, traceID, start, "RAC.INFOBASE_UNSUPPORTED",
+			"RAC клиент не поддерживает управление информационными базами")
+	}
+
+	clusterInfo, err := racClient.GetClusterInfo(ctx)
+	if err != nil {
+		log.Error("Не удалось получить информацию о кластере", slog.String("error", err.Error()))
+		return h.writeError(format, traceID, start, "RAC.CLUSTER_FAILED",
+			fmt.Sprintf("Не удалось получить информацию о кластере: %v", err))
+	}
+
+	data := &InfobaseDropData{Infobase: name, Server: server, Mode: string(mode)}
+
+	infobaseInfo, err := racClient.GetInfobaseInfo(ctx, clusterInfo.UUID, name)
+	switch {
+	case infobaseutil.IsNotFound(err):
+		log.Info("Информационная база не зарегистрирована в кластере")
+	case err != nil:
+		log.Error("Не удалось получить информацию об информационной базе", slog.String("error", err.Error()))
+		return h.writeError(format, traceID, start, "RAC.INFOBASE_FAILED",
+			fmt.Sprintf("Не удалось получить информацию об информационной базе: %v", err))
+	default:
+		log.Info("Удаление информационной базы", slog.String("uuid", infobaseInfo.UUID), slog.String("mode", string(mode)))
+		if err := manager.DropInfobase(ctx, clusterInfo.UUID, infobaseInfo.UUID, mode); err != nil {
+			log.Error("Не удалось удалить информационную базу", slog.String("error", err.Error()))
+			return h.writeError(format, traceID, start, ErrInfobaseDropFailed,
+				fmt.Sprintf("Не удалось удалить информационную базу: %v", err))
+		}
+		data.UUID = infobaseInfo.UUID
+		data.StateChanged = true
+		log.Info("Информационная база удалена")
+	}
+
+	proposer := h.proposer
+	if proposer == nil {
+		proposer = &infobaseutil.DbConfigProposer{}
+	}
+	data.DbConfig = proposer.Propose(ctx, log, cfg, infobaseutil.DbConfigChange{
+		Action:   infobaseutil.DbConfigRemove,
+		Infobase: name,
+	}, constants.ActNRInfobaseDrop, traceID)
+
+	return h.writeSuccess(format, traceID, start, data)
+}
+
+// buildPlan создаёт план удаления информационной базы для dry-run и plan-only.
+func buildPlan(name, server string, mode rac.InfobaseDropMode) *output.DryRunPlan {
+	steps := []output.PlanStep{
+		{
+			Order:           1,
+			Operation:       "Поиск информационной базы в кластере",
+			Parameters:      map[string]any{"server": server, "infobase": name},
+			ExpectedChanges: []string{"Нет изменений — только чтение"},
+		},
+		{
+			Order:      2,
+			Operation:  "Удаление информационной базы",
+			Parameters: map[string]any{"server": server, "infobase": name, "mode": string(mode)},
+			ExpectedChanges: []string{
+				fmt.Sprintf("Информационная база %s будет удалена из кластера", name),
+				fmt.Sprintf("База данных СУБД будет %s", modeText(mode)),
+			},
+		},
+	}
+	if infobaseutil.DbConfigPREnabled() {
+		steps = append(steps, output.PlanStep{
+			Order:      3,
+			Operation:  "Предложение изменения dbconfig.yaml",
+			Parameters: map[string]any{"infobase": name},
+			ExpectedChanges: []string{
+				fmt.Sprintf("Будет создан Pull Request с удалением записи %s из dbconfig.yaml", name),
+			},
+		})
+	}
+
+	summary := fmt.Sprintf("Удаление информационной базы %s (база данных СУБД будет %s)", name, modeText(mode))
+	return dryrun.BuildPlanWithSummary(constants.ActNRInfobaseDrop, steps, summary)
+}
+
+// writeSuccess выводит успешный результат.
+func (h *InfobaseDropHandler) writeSuccess(format, traceID string, start time.Time, data *InfobaseDropData) error {
+	if format != output.FormatJSON {
+		return data.writeText(os.Stdout)
+	}
+
+	result := &output.Result{
+		Status:  output.StatusSuccess,
+		Command: constants.ActNRInfobaseDrop,
+		Data:    data,
+		Metadata: &output.Metadata{
+			DurationMs: time.Since(start).Milliseconds(),
+			TraceID:    traceID,
+			APIVersion: constants.APIVersion,
+		},
+	}
+
+	writer := output.NewWriter(format)
+	return writer.Write(os.Stdout, result)
+}
+
+// writeError выводит структурированную ошибку и возвращает error.
+func (h *InfobaseDropHandler) writeError(format, traceID string, start time.Time, code, message string) error {
+	// Текстовый формат — человекочитаемый вывод ошибки
+	if format != output.FormatJSON {
+		return errhandler.HandleError(message, code)
+	}
+
+	result := &output.Result{
+		Status:  output.StatusError,
+		Command: constants.ActNRInfobaseDrop,
+		Error: &output.ErrorInfo{
+			Code:    code,
+			Message: message,
+		},
+		Metadata: &output.Metadata{
+			DurationMs: time.Since(start).Milliseconds(),
+			TraceID:    traceID,
+			APIVersion: constants.APIVersion,
+		},
+	}
+
+	writer := output.NewWriter(format)
+	if writeErr := writer.Write(os.Stdout, result); writeErr != nil {
+		slog.Default().Error("Не удалось записать JSON-ответ об ошибке",
+			slog.String("trace_id", traceID),
+			slog.String("error", writeErr.Error()))
+	}
+
+	return fmt.Errorf("%s: %s", code, message)
+}
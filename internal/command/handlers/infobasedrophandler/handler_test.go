@@ -0,0 +1,224 @@
+package infobasedrophandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac/ractest"
+	"github.com/Kargones/apk-ci/internal/command"
+	"github.com/Kargones/apk-ci/internal/command/handlers/racutil"
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/pkg/apperrors"
+	"github.com/Kargones/apk-ci/internal/pkg/output"
+	"github.com/Kargones/apk-ci/internal/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInfobaseDropHandler_Registration(t *testing.T) {
+	h, ok := command.Get(constants.ActNRInfobaseDrop)
+	require.True(t, ok)
+	assert.Equal(t, constants.ActNRInfobaseDrop, h.Name())
+	assert.NotEmpty(t, h.Description())
+}
+
+func testConfig() *config.Config {
+	return &config.Config{
+		InfobaseName: "erp_test",
+		DbConfig: map[string]*config.DatabaseInfo{
+			"erp":      {OneServer: "srv-1c", Prod: true, DbServer: "sql-prod"},
+			"erp_test": {OneServer: "srv-1c", DbServer: "sql-test"},
+		},
+	}
+}
+
+func setDefaults(t *testing.T) {
+	t.Setenv(constants.EnvDryRun, "")
+	t.Setenv(constants.EnvPlanOnly, "")
+	t.Setenv(constants.EnvInfobaseServer, "")
+	t.Setenv(constants.EnvInfobaseDropMode, "")
+	t.Setenv(constants.EnvInfobaseDbConfigPR, "")
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+}
+
+func run(t *testing.T, h *InfobaseDropHandler, cfg *config.Config) (*InfobaseDropData, string, error) {
+	t.Helper()
+	var execErr error
+	out := testutil.CaptureStdout(t, func() {
+		execErr = h.Execute(context.Background(), cfg)
+	})
+	if execErr != nil {
+		return nil, out, execErr
+	}
+	var result struct {
+		output.Result
+		Data InfobaseDropData `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+	return &result.Data, out, nil
+}
+
+func TestInfobaseDropHandler_Execute_Modes(t *testing.T) {
+	tests := []struct {
+		env  string
+		want rac.InfobaseDropMode
+	}{
+		{env: "", want: rac.InfobaseDropKeepDatabase},
+		{env: "drop", want: rac.InfobaseDropDatabase},
+		{env: "clear", want: rac.InfobaseDropClearDatabase},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.want), func(t *testing.T) {
+			setDefaults(t)
+			t.Setenv(constants.EnvInfobaseDropMode, tt.env)
+			mock := ractest.NewMockRACClient()
+			var gotMode rac.InfobaseDropMode
+			var gotUUID string
+			mock.DropInfobaseFunc = func(_ context.Context, _, uuid string, mode rac.InfobaseDropMode) error {
+				gotUUID, gotMode = uuid, mode
+				return nil
+			}
+
+			data, _, err := run(t, &InfobaseDropHandler{racClient: mock}, testConfig())
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, gotMode)
+			assert.Equal(t, "test-infobase-uuid", gotUUID)
+			assert.True(t, data.StateChanged)
+			assert.Equal(t, string(tt.want), data.Mode)
+			require.NotNil(t, data.DbConfig)
+		})
+	}
+}
+
+func TestInfobaseDropHandler_Execute_NotRegistered(t *testing.T) {
+	setDefaults(t)
+	mock := ractest.NewMockRACClient()
+	mock.GetInfobaseInfoFunc = func(context.Context, string, string) (*rac.InfobaseInfo, error) {
+		return nil, apperrors.NewAppError(rac.ErrRACNotFound, "информационная база не найдена", nil)
+	}
+	mock.DropInfobaseFunc = func(context.Context, string, string, rac.InfobaseDropMode) error {
+		t.Fatal("DropInfobase не должен вызываться для отсутствующей базы")
+		return nil
+	}
+
+	data, _, err := run(t, &InfobaseDropHandler{racClient: mock}, testConfig())
+	require.NoError(t, err)
+	assert.False(t, data.StateChanged)
+	assert.NotNil(t, data.DbConfig, "удаление записи из dbconfig.yaml предлагается и для отсутствующей базы")
+}
+
+func TestInfobaseDropHandler_Execute_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      func() *config.Config
+		env      map[string]string
+		mock     func() *ractest.MockRACClient
+		wantCode string
+	}{
+		{
+			name:     "нет имени базы",
+			cfg:      func() *config.Config { c := testConfig(); c.InfobaseName = ""; return c },
+			wantCode: "CONFIG.INFOBASE_MISSING",
+		},
+		{
+			name:     "продуктивная база",
+			cfg:      func() *config.Config { c := testConfig(); c.InfobaseName = "erp"; return c },
+			wantCode: ErrInfobaseProductionForbidden,
+		},
+		{
+			name:     "база отсутствует в dbconfig",
+			cfg:      func() *config.Config { c := testConfig(); c.InfobaseName = "erp_unknown"; return c },
+			wantCode: ErrInfobaseNotInDbConfig,
+		},
+		{
+			name:     "некорректный режим",
+			cfg:      testConfig,
+			env:      map[string]string{constants.EnvInfobaseDropMode: "purge"},
+			wantCode: "CONFIG.INVALID_PARAM",
+		},
+		{
+			name: "клиент RAS",
+			cfg: func() *config.Config {
+				c := testConfig()
+				c.ImplementationsConfig = &config.ImplementationsConfig{RAC: "native"}
+				return c
+			},
+			wantCode: racutil.ErrNativeUnsupported,
+		},
+		{
+			name: "ошибка поиска базы",
+			cfg:  testConfig,
+			mock: func() *ractest.MockRACClient {
+				m := ractest.NewMockRACClient()
+				m.GetInfobaseInfoFunc = func(context.Context, string, string) (*rac.InfobaseInfo, error) {
+					return nil, errors.New("connection refused")
+				}
+				return m
+			},
+			wantCode: "RAC.INFOBASE_FAILED",
+		},
+		{
+			name: "ошибка удаления",
+			cfg:  testConfig,
+			mock: func() *ractest.MockRACClient {
+				m := ractest.NewMockRACClient()
+				m.DropInfobaseFunc = func(context.Context, string, string, rac.InfobaseDropMode) error {
+					return errors.New("есть активные сеансы")
+				}
+				return m
+			},
+			wantCode: ErrInfobaseDropFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setDefaults(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			mock := ractest.NewMockRACClient()
+			if tt.mock != nil {
+				mock = tt.mock()
+			}
+			_, out, err := run(t, &InfobaseDropHandler{racClient: mock}, tt.cfg())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantCode)
+			assert.Contains(t, out, tt.wantCode)
+		})
+	}
+}
+
+func TestInfobaseDropHandler_Execute_DryRun(t *testing.T) {
+	setDefaults(t)
+	t.Setenv(constants.EnvDryRun, "true")
+	t.Setenv(constants.EnvInfobaseDropMode, "drop")
+	mock := ractest.NewMockRACClient()
+	mock.DropInfobaseFunc = func(context.Context, string, string, rac.InfobaseDropMode) error {
+		t.Fatal("DropInfobase не должен вызываться в dry-run")
+		return nil
+	}
+
+	var execErr error
+	out := testutil.CaptureStdout(t, func() {
+		execErr = (&InfobaseDropHandler{racClient: mock}).Execute(context.Background(), testConfig())
+	})
+	require.NoError(t, execErr)
+	assert.Contains(t, out, "База данных СУБД будет удалена")
+}
+
+func TestInfobaseDropHandler_Execute_TextOutput(t *testing.T) {
+	setDefaults(t)
+	t.Setenv("BR_OUTPUT_FORMAT", "text")
+
+	var execErr error
+	out := testutil.CaptureStdout(t, func() {
+		execErr = (&InfobaseDropHandler{racClient: ractest.NewMockRACClient()}).Execute(context.Background(), testConfig())
+	})
+	require.NoError(t, execErr)
+	assert.Contains(t, out, "Информационная база erp_test удалена с сервера srv-1c (база данных: сохранена)")
+	assert.Contains(t, out, "dbconfig.yaml: Gitea не настроен")
+}
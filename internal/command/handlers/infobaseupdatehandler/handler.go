@@ -0,0 +1,361 @@
+// Package infobaseupdatehandler реализует NR-команду nr-infobase-update
+// для изменения параметров СУБД информационной базы через RAC
+// (перенос на другой сервер СУБД, переименование базы данных).
+package infobaseupdatehandler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
+	"github.com/Kargones/apk-ci/internal/command"
+	"github.com/Kargones/apk-ci/internal/command/handlers/infobaseutil"
+	"github.com/Kargones/apk-ci/internal/command/handlers/racutil"
+	errhandler "github.com/Kargones/apk-ci/internal/command/handlers/shared"
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/pkg/dryrun"
+	"github.com/Kargones/apk-ci/internal/pkg/output"
+	"github.com/Kargones/apk-ci/internal/pkg/tracing"
+)
+
+// Коды ошибок nr-infobase-update.
+const (
+	ErrInfobaseProductionForbidden = "INFOBASE.PRODUCTION_FORBIDDEN"
+	ErrInfobaseNotInDbConfig       = "INFOBASE.NOT_IN_DBCONFIG"
+	ErrInfobaseParamsMissing       = "INFOBASE.PARAMS_MISSING"
+	ErrInfobaseUpdateFailed        = "RAC.INFOBASE_UPDATE_FAILED"
+)
+
+// Compile-time interface check.
+var _ command.Handler = (*InfobaseUpdateHandler)(nil)
+
+func RegisterCmd() error {
+	return command.Register(&InfobaseUpdateHandler{})
+}
+
+// ParamChange — изменение параметра СУБД информационной базы.
+type ParamChange struct {
+	// Param — имя параметра (dbms, db_server, db_name)
+	Param string `json:"param"`
+	// Old — текущее значение (пустое в dry-run: RAC не опрашивается)
+	Old string `json:"old,omitempty"`
+	// New — новое значение
+	New string `json:"new"`
+}
+
+// InfobaseUpdateData содержит данные ответа nr-infobase-update.
+type InfobaseUpdateData struct {
+	// Infobase — имя информационной базы
+	Infobase string `json:"infobase"`
+	// UUID — идентификатор информационной базы
+	UUID string `json:"uuid"`
+	// Server — сервер 1C
+	Server string `json:"server"`
+	// Changes — изменённые параметры (пусто, если база уже соответствует)
+	Changes []ParamChange `json:"changes"`
+	// StateChanged — параметры информационной базы изменены
+	StateChanged bool `json:"state_changed"`
+	// DbConfig — предложение изменения dbconfig.yaml (при смене сервера СУБД)
+	DbConfig *infobaseutil.DbConfigProposal `json:"dbconfig,omitempty"`
+}
+
+// writeText выводит результат в человекочитаемом формате.
+func (d *InfobaseUpdateData) writeText(w io.Writer) error {
+	if !d.StateChanged {
+		_, err := fmt.Fprintf(w, "Параметры СУБД информационной базы %s не изменились\n", d.Infobase)
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "Параметры СУБД информационной базы %s изменены:\n", d.Infobase); err != nil {
+		return err
+	}
+	for _, c := range d.Changes {
+		if _, err := fmt.Fprintf(w, "  %s: %s → %s\n", c.Param, c.Old, c.New); err != nil {
+			return err
+		}
+	}
+	return d.DbConfig.WriteText(w)
+}
+
+// InfobaseUpdateHandler обрабатывает команду nr-infobase-update.
+// Изменяются только параметры, заданные через BR_INFOBASE_DBMS,
+// BR_INFOBASE_DB_SERVER и BR_INFOBASE_DB_NAME.
+type InfobaseUpdateHandler struct {
+	// racClient — опциональный RAC клиент (nil в production, mock в тестах)
+	racClient rac.Client
+	// proposer — опциональный клиент предложения изменений dbconfig.yaml (nil в production)
+	proposer *infobaseutil.DbConfigProposer
+}
+
+// Name возвращает имя команды.
+func (h *InfobaseUpdateHandler) Name() string {
+	return constants.ActNRInfobaseUpdate
+}
+
+// Description возвращает описание команды для вывода в help.
+func (h *InfobaseUpdateHandler) Description() string {
+	return "Изменение параметров СУБД информационной базы"
+}
+
+// Execute выполняет команду nr-infobase-update.
+func (h *InfobaseUpdateHandler) Execute(ctx context.Context, cfg *config.Config) error {
+	start := time.Now()
+
+	traceID := tracing.TraceIDFromContext(ctx)
+	if traceID == "" {
+		traceID = tracing.GenerateTraceID()
+	}
+
+	format := os.Getenv("BR_OUTPUT_FORMAT")
+
+	log := slog.Default().With(slog.String("trace_id", traceID), slog.String("command", constants.ActNRInfobaseUpdate))
+
+	if cfg == nil || cfg.InfobaseName == "" {
+		log.Error("Не указано имя информационной базы")
+		return h.writeError(format, traceID, start,
+			"CONFIG.INFOBASE_MISSING",
+			"Не указано имя информационной базы (BR_INFOBASE_NAME)")
+	}
+	name := cfg.InfobaseName
+	log = log.With(slog.String("infobase", name))
+
+	// КРИТИЧНО: изменяются только тестовые базы, описанные в dbconfig;
+	// база без записи в dbconfig считается продуктивной
+	dbInfo := cfg.DbConfig[name]
+	if dbInfo == nil {
+		log.Error("Попытка изменения базы, отсутствующей в dbconfig")
+		return h.writeError(format, traceID, start, ErrInfobaseNotInDbConfig,
+			fmt.Sprintf("База '%s' отсутствует в dbconfig: изменяются только тестовые базы из dbconfig", name))
+	}
+	if dbInfo.Prod {
+		log.Error("Попытка изменения продуктивной базы")
+		return h.writeError(format, traceID, start, ErrInfobaseProductionForbidden,
+			fmt.Sprintf("Изменение параметров продуктивной базы '%s' запрещено", name))
+	}
+
+	target := []ParamChange{
+		{Param: "dbms", New: os.Getenv(constants.EnvInfobaseDBMS)},
+		{Param: "db_server", New: os.Getenv(constants.EnvInfobaseDbServer)},
+		{Param: "db_name", New: os.Getenv(constants.EnvInfobaseDbName)},
+	}
+	requested := make([]ParamChange, 0, len(target))
+	for _, c := range target {
+		if c.New != "" {
+			requested = append(requested, c)
+		}
+	}
+	if len(requested) == 0 {
+		return h.writeError(format, traceID, start, ErrInfobaseParamsMissing,
+			fmt.Sprintf("Не заданы изменяемые параметры: %s, %s или %s",
+				constants.EnvInfobaseDBMS, constants.EnvInfobaseDbServer, constants.EnvInfobaseDbName))
+	}
+
+	server, err := infobaseutil.ResolveServer(cfg)
+	if err != nil {
+		return h.writeError(format, traceID, start, "CONFIG.SERVER_MISSING", err.Error())
+	}
+
+	if err := racutil.RequireCapabilities(cfg, racutil.CapInfobaseManager); err != nil {
+		log.Error("RAC клиент не поддерживает управление информационными базами", slog.String("error", err.Error()))
+		return h.writeError(format, traceID, start, racutil.ErrNativeUnsupported, err.Error())
+	}
+
+	// Dry-run имеет приоритет над plan-only
+	if dryrun.IsDryRun() {
+		log.Info("Dry-run режим: построение плана")
+		plan := buildPlan(name, server, requested)
+		return output.WriteDryRunResult(os.Stdout, format, constants.ActNRInfobaseUpdate, traceID, constants.APIVersion, start, plan)
+	}
+	if dryrun.IsPlanOnly() {
+		log.Info("Plan-only режим: отображение плана операций")
+		plan := buildPlan(name, server, requested)
+		return output.WritePlanOnlyResult(os.Stdout, format, constants.ActNRInfobaseUpdate, traceID, constants.APIVersion, start, plan)
+	}
+
+	racClient := h.racClient
+	if racClient == nil {
+		racClient, err = racutil.NewClientForServer(cfg, server)
+		if err != nil {
+			log.Error("Не удалось создать RAC клиент", slog.String("error", err.Error()))
+			return h.writeError(format, traceID, start, "RAC.CLIENT_CREATE_FAILED",
+				fmt.Sprintf("Не удалось создать RAC клиент: %v", err))
+		}
+	}
+	manager, ok := racClient.(rac.InfobaseManager)
+	if !ok {
+		return h.writeError(format, traceID, start, "RAC.INFOBASE_UNSUPPORTED",
+			"RAC клиент не поддерживает управление информационными базами")
+	}
+
+	clusterInfo, err := racClient.GetClusterInfo(ctx)
+	if err != nil {
+		log.Error("Не удалось получить информацию о кластере", slog.String("error", err.Error()))
+		return h.writeError(format, traceID, start, "RAC.CLUSTER_FAILED",
+			fmt.Sprintf("Не удалось получить информацию о кластере: %v", err))
+	}
+	infobaseInfo, err := racClient.GetInfobaseInfo(ctx, clusterInfo.UUID, name)
+	if err != nil {
+		log.Error("Не удалось получить информацию об информационной базе", slog.String("error", err.Error()))
+		return h.writeError(format, traceID, start, "RAC.INFOBASE_FAILED",
+			fmt.Sprintf("Не удалось получить информацию об информационной базе: %v", err))
+	}
+	current, err := manager.GetInfobaseParams(ctx, clusterInfo.UUID, infobaseInfo.UUID)
+	if err != nil {
+		log.Error("Не удалось получить параметры информационной базы", slog.String("error", err.Error()))
+		return h.writeError(format, traceID, start, "RAC.INFOBASE_FAILED",
+			fmt.Sprintf("Не удалось получить параметры информационной базы: %v", err))
+	}
+
+	data := &InfobaseUpdateData{
+		Infobase: name,
+		UUID:     infobaseInfo.UUID,
+		Server:   server,
+		Changes:  make([]ParamChange, 0, len(requested)),
+	}
+	// В update передаются только отличающиеся параметры
+	var update rac.InfobaseParams
+	for _, c := range requested {
+		var field *string
+		switch c.Param {
+		case "dbms":
+			c.Old, field = current.DBMS, &update.DBMS
+		case "db_server":
+			c.Old, field = current.DBServer, &update.DBServer
+		case "db_name":
+			c.Old, field = current.DBName, &update.DBName
+		}
+		if !strings.EqualFold(c.Old, c.New) {
+			*field = c.New
+			data.Changes = append(data.Changes, c)
+		}
+	}
+	if len(data.Changes) == 0 {
+		log.Info("Параметры СУБД уже соответствуют заданным")
+		return h.writeSuccess(format, traceID, start, data)
+	}
+
+	update.DBUser, update.DBPassword = infobaseutil.DBCredentials(cfg)
+	log.Info("Изменение параметров СУБД информационной базы", slog.Any("changes", data.Changes))
+	if err := manager.UpdateInfobaseDBMS(ctx, clusterInfo.UUID, infobaseInfo.UUID, update); err != nil {
+		log.Error("Не удалось изменить параметры информационной базы", slog.String("error", err.Error()))
+		return h.writeError(format, traceID, start, ErrInfobaseUpdateFailed,
+			fmt.Sprintf("Не удалось изменить параметры информационной базы: %v", err))
+	}
+	data.StateChanged = true
+	log.Info("Параметры СУБД информационной базы изменены")
+
+	// В dbconfig.yaml хранится только сервер СУБД
+	if update.DBServer != "" {
+		proposer := h.proposer
+		if proposer == nil {
+			proposer = &infobaseutil.DbConfigProposer{}
+		}
+		data.DbConfig = proposer.Propose(ctx, log, cfg, infobaseutil.DbConfigChange{
+			Action:   infobaseutil.DbConfigUpdate,
+			Infobase: name,
+			Info:     config.DatabaseInfo{DbServer: update.DBServer},
+		}, constants.ActNRInfobaseUpdate, traceID)
+	}
+
+	return h.writeSuccess(format, traceID, start, data)
+}
+
+// buildPlan создаёт план изменения параметров СУБД для dry-run и plan-only.
+// Текущие значения не запрашиваются: dry-run не обращается к серверу 1C.
+func buildPlan(name, server string, requested []ParamChange) *output.DryRunPlan {
+	parameters := map[string]any{"server": server, "infobase": name}
+	changes := make([]string, 0, len(requested))
+	dbServer := ""
+	for _, c := range requested {
+		parameters[c.Param] = c.New
+		changes = append(changes, fmt.Sprintf("Параметр %s будет изменён на %s", c.Param, c.New))
+		if c.Param == "db_server" {
+			dbServer = c.New
+		}
+	}
+
+	steps := []output.PlanStep{
+		{
+			Order:           1,
+			Operation:       "Получение текущих параметров информационной базы",
+			Parameters:      map[string]any{"server": server, "infobase": name},
+			ExpectedChanges: []string{"Нет изменений — только чтение"},
+		},
+		{
+			Order:           2,
+			Operation:       "Изменение параметров СУБД",
+			Parameters:      parameters,
+			ExpectedChanges: changes,
+		},
+	}
+	if dbServer != "" && infobaseutil.DbConfigPREnabled() {
+		steps = append(steps, output.PlanStep{
+			Order:      3,
+			Operation:  "Предложение изменения dbconfig.yaml",
+			Parameters: map[string]any{"dbserver": dbServer},
+			ExpectedChanges: []string{
+				fmt.Sprintf("При смене сервера СУБД будет создан Pull Request с изменением записи %s в dbconfig.yaml", name),
+			},
+		})
+	}
+
+	summary := fmt.Sprintf("Изменение параметров СУБД информационной базы %s", name)
+	return dryrun.BuildPlanWithSummary(constants.ActNRInfobaseUpdate, steps, summary)
+}
+
+// writeSuccess выводит успешный результат.
+func (h *InfobaseUpdateHandler) writeSuccess(format, traceID string, start time.Time, data *InfobaseUpdateData) error {
+	if format != output.FormatJSON {
+		return data.writeText(os.Stdout)
+	}
+
+	result := &output.Result{
+		Status:  output.StatusSuccess,
+		Command: constants.ActNRInfobaseUpdate,
+		Data:    data,
+		Metadata: &output.Metadata{
+			DurationMs: time.Since(start).Milliseconds(),
+			TraceID:    traceID,
+			APIVersion: constants.APIVersion,
+		},
+	}
+
+	writer := output.NewWriter(format)
+	return writer.Write(os.Stdout, result)
+}
+
+// writeError выводит структурированную ошибку и возвращает error.
+func (h *InfobaseUpdateHandler) writeError(format, traceID string, start time.Time, code, message string) error {
+	// Текстовый формат — человекочитаемый вывод ошибки
+	if format != output.FormatJSON {
+		return errhandler.HandleError(message, code)
+	}
+
+	result := &output.Result{
+		Status:  output.StatusError,
+		Command: constants.ActNRInfobaseUpdate,
+		Error: &output.ErrorInfo{
+			Code:    code,
+			Message: message,
+		},
+		Metadata: &output.Metadata{
+			DurationMs: time.Since(start).Milliseconds(),
+			TraceID:    traceID,
+			APIVersion: constants.APIVersion,
+		},
+	}
+
+	writer := output.NewWriter(format)
+	if writeErr := writer.Write(os.Stdout, result); writeErr != nil {
+		slog.Default().Error("Не удалось записать JSON-ответ об ошибке",
+			slog.String("trace_id", traceID),
+			slog.String("error", writeErr.Error()))
+	}
+
+	return fmt.Errorf("%s: %s", code, message)
+}
@@ -0,0 +1,262 @@
+package infobaseupdatehandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac/ractest"
+	"github.com/Kargones/apk-ci/internal/command"
+	"github.com/Kargones/apk-ci/internal/command/handlers/racutil"
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/pkg/output"
+	"github.com/Kargones/apk-ci/internal/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInfobaseUpdateHandler_Registration(t *testing.T) {
+	h, ok := command.Get(constants.ActNRInfobaseUpdate)
+	require.True(t, ok)
+	assert.Equal(t, constants.ActNRInfobaseUpdate, h.Name())
+	assert.NotEmpty(t, h.Description())
+}
+
+func testConfig() *config.Config {
+	cfg := &config.Config{
+		InfobaseName: "erp_test",
+		AppConfig:    &config.AppConfig{},
+		SecretConfig: &config.SecretConfig{},
+		DbConfig: map[string]*config.DatabaseInfo{
+			"erp":      {OneServer: "srv-1c", Prod: true, DbServer: "sql-prod"},
+			"erp_test": {OneServer: "srv-1c", DbServer: "sql-test"},
+		},
+	}
+	cfg.AppConfig.Users.Mssql = "sa"
+	cfg.SecretConfig.Passwords.Mssql = "sql-secret"
+	return cfg
+}
+
+// newMock возвращает mock RAC клиента с базой erp_test на сервере СУБД sql-test.
+func newMock(updated *rac.InfobaseParams) *ractest.MockRACClient {
+	mock := ractest.NewMockRACClient()
+	mock.GetInfobaseParamsFunc = func(context.Context, string, string) (*rac.InfobaseParams, error) {
+		return &rac.InfobaseParams{Name: "erp_test", DBMS: "MSSQLServer", DBServer: "sql-test", DBName: "erp_test", DBUser: "sa"}, nil
+	}
+	mock.UpdateInfobaseDBMSFunc = func(_ context.Context, _, _ string, params rac.InfobaseParams) error {
+		*updated = params
+		return nil
+	}
+	return mock
+}
+
+func setDefaults(t *testing.T) {
+	t.Setenv(constants.EnvDryRun, "")
+	t.Setenv(constants.EnvPlanOnly, "")
+	t.Setenv(constants.EnvInfobaseServer, "")
+	t.Setenv(constants.EnvInfobaseDBMS, "")
+	t.Setenv(constants.EnvInfobaseDbServer, "")
+	t.Setenv(constants.EnvInfobaseDbName, "")
+	t.Setenv(constants.EnvInfobaseDbConfigPR, "")
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+}
+
+func run(t *testing.T, h *InfobaseUpdateHandler, cfg *config.Config) (*InfobaseUpdateData, string, error) {
+	t.Helper()
+	var execErr error
+	out := testutil.CaptureStdout(t, func() {
+		execErr = h.Execute(context.Background(), cfg)
+	})
+	if execErr != nil {
+		return nil, out, execErr
+	}
+	var result struct {
+		output.Result
+		Data InfobaseUpdateData `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+	return &result.Data, out, nil
+}
+
+func TestInfobaseUpdateHandler_Execute_ChangesDbServer(t *testing.T) {
+	setDefaults(t)
+	t.Setenv(constants.EnvInfobaseDbServer, "sql-test-2")
+	t.Setenv(constants.EnvInfobaseDbName, "erp_test")
+	var updated rac.InfobaseParams
+
+	data, _, err := run(t, &InfobaseUpdateHandler{racClient: newMock(&updated)}, testConfig())
+	require.NoError(t, err)
+
+	assert.Equal(t, "sql-test-2", updated.DBServer)
+	assert.Empty(t, updated.DBName, "неизменившийся параметр не передаётся")
+	assert.Empty(t, updated.DBMS)
+	assert.Equal(t, "sa", updated.DBUser)
+	assert.Equal(t, "sql-secret", updated.DBPassword)
+
+	assert.True(t, data.StateChanged)
+	require.Len(t, data.Changes, 1)
+	assert.Equal(t, ParamChange{Param: "db_server", Old: "sql-test", New: "sql-test-2"}, data.Changes[0])
+	require.NotNil(t, data.DbConfig, "смена сервера СУБД предлагается в dbconfig.yaml")
+	assert.Equal(t, "Gitea не настроен", data.DbConfig.Skipped)
+}
+
+func TestInfobaseUpdateHandler_Execute_NoChanges(t *testing.T) {
+	setDefaults(t)
+	t.Setenv(constants.EnvInfobaseDbServer, "SQL-TEST")
+	mock := newMock(new(rac.InfobaseParams))
+	mock.UpdateInfobaseDBMSFunc = func(context.Context, string, string, rac.InfobaseParams) error {
+		t.Fatal("UpdateInfobaseDBMS не должен вызываться без изменений")
+		return nil
+	}
+
+	data, _, err := run(t, &InfobaseUpdateHandler{racClient: mock}, testConfig())
+	require.NoError(t, err)
+	assert.False(t, data.StateChanged)
+	assert.Empty(t, data.Changes)
+	assert.Nil(t, data.DbConfig)
+}
+
+func TestInfobaseUpdateHandler_Execute_DbNameOnly(t *testing.T) {
+	setDefaults(t)
+	t.Setenv(constants.EnvInfobaseDbName, "erp_test_new")
+	var updated rac.InfobaseParams
+
+	data, _, err := run(t, &InfobaseUpdateHandler{racClient: newMock(&updated)}, testConfig())
+	require.NoError(t, err)
+	assert.Equal(t, "erp_test_new", updated.DBName)
+	assert.True(t, data.StateChanged)
+	assert.Nil(t, data.DbConfig, "имя базы данных в dbconfig.yaml не хранится")
+}
+
+func TestInfobaseUpdateHandler_Execute_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      func() *config.Config
+		env      map[string]string
+		mock     func() *ractest.MockRACClient
+		wantCode string
+	}{
+		{
+			name:     "нет имени базы",
+			cfg:      func() *config.Config { c := testConfig(); c.InfobaseName = ""; return c },
+			env:      map[string]string{constants.EnvInfobaseDbServer: "sql-test-2"},
+			wantCode: "CONFIG.INFOBASE_MISSING",
+		},
+		{
+			name:     "продуктивная база",
+			cfg:      func() *config.Config { c := testConfig(); c.InfobaseName = "erp"; return c },
+			env:      map[string]string{constants.EnvInfobaseDbServer: "sql-test-2"},
+			wantCode: ErrInfobaseProductionForbidden,
+		},
+		{
+			name:     "база отсутствует в dbconfig",
+			cfg:      func() *config.Config { c := testConfig(); c.InfobaseName = "erp_unknown"; return c },
+			env:      map[string]string{constants.EnvInfobaseDbServer: "sql-test-2"},
+			wantCode: ErrInfobaseNotInDbConfig,
+		},
+		{
+			name:     "нет изменяемых параметров",
+			cfg:      testConfig,
+			wantCode: ErrInfobaseParamsMissing,
+		},
+		{
+			name: "нет сервера 1C",
+			cfg: func() *config.Config {
+				c := testConfig()
+				c.InfobaseName = "zup_test"
+				c.DbConfig["zup_test"] = &config.DatabaseInfo{DbServer: "sql-test"}
+				return c
+			},
+			env:      map[string]string{constants.EnvInfobaseDbServer: "sql-test-2"},
+			wantCode: "CONFIG.SERVER_MISSING",
+		},
+		{
+			name: "клиент RAS",
+			cfg: func() *config.Config {
+				c := testConfig()
+				c.ImplementationsConfig = &config.ImplementationsConfig{RAC: "native"}
+				return c
+			},
+			env:      map[string]string{constants.EnvInfobaseDbServer: "sql-test-2"},
+			wantCode: racutil.ErrNativeUnsupported,
+		},
+		{
+			name: "база не найдена",
+			cfg:  testConfig,
+			env:  map[string]string{constants.EnvInfobaseDbServer: "sql-test-2"},
+			mock: func() *ractest.MockRACClient {
+				m := newMock(new(rac.InfobaseParams))
+				m.GetInfobaseInfoFunc = func(context.Context, string, string) (*rac.InfobaseInfo, error) {
+					return nil, errors.New("информационная база не найдена")
+				}
+				return m
+			},
+			wantCode: "RAC.INFOBASE_FAILED",
+		},
+		{
+			name: "ошибка изменения",
+			cfg:  testConfig,
+			env:  map[string]string{constants.EnvInfobaseDbServer: "sql-test-2"},
+			mock: func() *ractest.MockRACClient {
+				m := newMock(new(rac.InfobaseParams))
+				m.UpdateInfobaseDBMSFunc = func(context.Context, string, string, rac.InfobaseParams) error {
+					return errors.New("недостаточно прав")
+				}
+				return m
+			},
+			wantCode: ErrInfobaseUpdateFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setDefaults(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			mock := newMock(new(rac.InfobaseParams))
+			if tt.mock != nil {
+				mock = tt.mock()
+			}
+			_, out, err := run(t, &InfobaseUpdateHandler{racClient: mock}, tt.cfg())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantCode)
+			assert.Contains(t, out, tt.wantCode)
+		})
+	}
+}
+
+func TestInfobaseUpdateHandler_Execute_DryRun(t *testing.T) {
+	setDefaults(t)
+	t.Setenv(constants.EnvDryRun, "true")
+	t.Setenv(constants.EnvInfobaseDbServer, "sql-test-2")
+	mock := newMock(new(rac.InfobaseParams))
+	mock.GetClusterInfoFunc = func(context.Context) (*rac.ClusterInfo, error) {
+		t.Fatal("dry-run не обращается к серверу 1C")
+		return nil, nil
+	}
+
+	var execErr error
+	out := testutil.CaptureStdout(t, func() {
+		execErr = (&InfobaseUpdateHandler{racClient: mock}).Execute(context.Background(), testConfig())
+	})
+	require.NoError(t, execErr)
+	assert.Contains(t, out, "Изменение параметров СУБД")
+	assert.Contains(t, out, "sql-test-2")
+	assert.Contains(t, out, "dbconfig.yaml")
+}
+
+func TestInfobaseUpdateHandler_Execute_TextOutput(t *testing.T) {
+	setDefaults(t)
+	t.Setenv("BR_OUTPUT_FORMAT", "text")
+	t.Setenv(constants.EnvInfobaseDbServer, "sql-test-2")
+
+	var execErr error
+	out := testutil.CaptureStdout(t, func() {
+		execErr = (&InfobaseUpdateHandler{racClient: newMock(new(rac.InfobaseParams))}).Execute(context.Background(), testConfig())
+	})
+	require.NoError(t, execErr)
+	assert.Contains(t, out, "db_server: sql-test → sql-test-2")
+}
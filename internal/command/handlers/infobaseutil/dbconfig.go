@@ -0,0 +1,178 @@
+package infobaseutil
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+	"gopkg.in/yaml.v3"
+)
+
+// DbConfigAction — вид изменения записи dbconfig.yaml.
+type DbConfigAction string
+
+const (
+	// DbConfigAdd — добавление записи информационной базы
+	DbConfigAdd DbConfigAction = "add"
+	// DbConfigUpdate — изменение серверов в записи информационной базы
+	DbConfigUpdate DbConfigAction = "update"
+	// DbConfigRemove — удаление записи информационной базы
+	DbConfigRemove DbConfigAction = "remove"
+)
+
+// DbConfigChange — изменение записи информационной базы в dbconfig.yaml.
+type DbConfigChange struct {
+	Action   DbConfigAction
+	Infobase string
+	// Info — значения записи; при DbConfigUpdate пустые серверы не изменяются,
+	// prod записывается только при добавлении
+	Info config.DatabaseInfo
+}
+
+// ApplyDbConfigChange применяет change к содержимому dbconfig.yaml.
+// Файл изменяется на уровне узлов YAML: комментарии и порядок записей сохраняются.
+// Возвращает false, если файл уже соответствует изменению.
+func ApplyDbConfigChange(data []byte, change DbConfigChange) ([]byte, bool, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, false, fmt.Errorf("ошибка разбора dbconfig.yaml: %w", err)
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, false, fmt.Errorf("dbconfig.yaml должен содержать словарь информационных баз")
+	}
+
+	idx := -1
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == change.Infobase {
+			idx = i
+			break
+		}
+	}
+
+	changed := false
+	switch change.Action {
+	case DbConfigRemove:
+		if idx < 0 {
+			return data, false, nil
+		}
+		root.Content = append(root.Content[:idx], root.Content[idx+2:]...)
+		changed = true
+	case DbConfigAdd, DbConfigUpdate:
+		if idx < 0 {
+			if change.Action == DbConfigUpdate {
+				return nil, false, fmt.Errorf("информационная база '%s' отсутствует в dbconfig.yaml", change.Infobase)
+			}
+			root.Content = append(root.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: change.Infobase},
+				&yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"})
+			idx = len(root.Content) - 2
+		}
+		entry := root.Content[idx+1]
+		if entry.Kind != yaml.MappingNode {
+			return nil, false, fmt.Errorf("запись '%s' в dbconfig.yaml не является словарём", change.Infobase)
+		}
+		info := change.Info
+		if info.OneServer != "" || change.Action == DbConfigAdd {
+			changed = setScalar(entry, "one-server", "!!str", info.OneServer) || changed
+		}
+		if change.Action == DbConfigAdd {
+			changed = setScalar(entry, "prod", "!!bool", strconv.FormatBool(info.Prod)) || changed
+		}
+		if info.DbServer != "" || change.Action == DbConfigAdd {
+			changed = setScalar(entry, "dbserver", "!!str", info.DbServer) || changed
+		}
+	default:
+		return nil, false, fmt.Errorf("неизвестное изменение dbconfig.yaml '%s'", change.Action)
+	}
+
+	if !changed {
+		return data, false, nil
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, false, fmt.Errorf("ошибка записи dbconfig.yaml: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, false, fmt.Errorf("ошибка записи dbconfig.yaml: %w", err)
+	}
+	return buf.Bytes(), true, nil
+}
+
+// setScalar устанавливает значение ключа key словаря m; возвращает true, если значение изменилось.
+func setScalar(m *yaml.Node, key, tag, value string) bool {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value != key {
+			continue
+		}
+		v := m.Content[i+1]
+		if v.Kind == yaml.ScalarNode && v.Value == value {
+			return false
+		}
+		*v = yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value}
+		return true
+	}
+	m.Content = append(m.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value})
+	return true
+}
+
+// DbConfigLocation — расположение dbconfig.yaml в Gitea.
+type DbConfigLocation struct {
+	Owner  string
+	Repo   string
+	Branch string
+	Path   string
+}
+
+// FullName возвращает имя репозитория в формате owner/repo.
+func (l *DbConfigLocation) FullName() string {
+	return l.Owner + "/" + l.Repo
+}
+
+// ResolveDbConfigLocation определяет расположение dbconfig.yaml по cfg.ConfigDbData:
+// URL contents API Gitea ({GiteaURL}/api/v1/repos/{owner}/{repo}/contents/{path}?ref={branch})
+// или путь в текущем репозитории (cfg.Owner/cfg.Repo, ветка cfg.BaseBranch).
+func ResolveDbConfigLocation(cfg *config.Config) (*DbConfigLocation, error) {
+	source := cfg.ConfigDbData
+	if source == "" {
+		return nil, fmt.Errorf("не задан источник dbconfig.yaml (BR_CONFIG_DBDATA)")
+	}
+
+	if !strings.HasPrefix(source, "https://") && !strings.HasPrefix(source, "http://") {
+		if cfg.Owner == "" || cfg.Repo == "" {
+			return nil, fmt.Errorf("не задан репозиторий для %s", source)
+		}
+		branch := cfg.BaseBranch
+		if branch == "" {
+			branch = constants.BaseBranch
+		}
+		return &DbConfigLocation{Owner: cfg.Owner, Repo: cfg.Repo, Branch: branch, Path: strings.TrimPrefix(source, "/")}, nil
+	}
+
+	u, err := url.Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("некорректный URL dbconfig.yaml: %w", err)
+	}
+	_, rest, ok := strings.Cut(u.Path, "/api/v1/repos/")
+	parts := strings.SplitN(rest, "/", 4)
+	if !ok || len(parts) != 4 || parts[2] != "contents" || parts[3] == "" {
+		return nil, fmt.Errorf("URL dbconfig.yaml не является ссылкой на contents API Gitea: %s", source)
+	}
+	branch := u.Query().Get("ref")
+	if branch == "" {
+		branch = constants.BaseBranch
+	}
+	return &DbConfigLocation{Owner: parts[0], Repo: parts[1], Branch: branch, Path: parts[3]}, nil
+}
@@ -0,0 +1,163 @@
+package infobaseutil
+
+import (
+	"testing"
+
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const sampleDbConfig = `# Базы данных проекта
+erp:
+  one-server: srv-1c
+  prod: true
+  dbserver: sql-prod
+# Тестовая копия
+erp_test:
+  one-server: srv-1c
+  prod: false
+  dbserver: sql-test
+`
+
+func parseDbConfig(t *testing.T, data []byte) map[string]*config.DatabaseInfo {
+	t.Helper()
+	var m map[string]*config.DatabaseInfo
+	require.NoError(t, yaml.Unmarshal(data, &m))
+	return m
+}
+
+func TestApplyDbConfigChange_Add(t *testing.T) {
+	out, changed, err := ApplyDbConfigChange([]byte(sampleDbConfig), DbConfigChange{
+		Action:   DbConfigAdd,
+		Infobase: "zup_test",
+		Info:     config.DatabaseInfo{OneServer: "srv-1c", DbServer: "sql-test"},
+	})
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	m := parseDbConfig(t, out)
+	require.Contains(t, m, "zup_test")
+	assert.Equal(t, config.DatabaseInfo{OneServer: "srv-1c", DbServer: "sql-test"}, *m["zup_test"])
+	assert.True(t, m["erp"].Prod)
+	assert.Contains(t, string(out), "# Тестовая копия", "комментарии сохраняются")
+	assert.Contains(t, string(out), "zup_test:\n  one-server: srv-1c\n  prod: false\n  dbserver: sql-test\n")
+}
+
+func TestApplyDbConfigChange_AddExisting(t *testing.T) {
+	out, changed, err := ApplyDbConfigChange([]byte(sampleDbConfig), DbConfigChange{
+		Action:   DbConfigAdd,
+		Infobase: "erp_test",
+		Info:     config.DatabaseInfo{OneServer: "srv-1c", DbServer: "sql-test"},
+	})
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, sampleDbConfig, string(out))
+}
+
+func TestApplyDbConfigChange_EmptyFile(t *testing.T) {
+	out, changed, err := ApplyDbConfigChange(nil, DbConfigChange{
+		Action:   DbConfigAdd,
+		Infobase: "erp_test",
+		Info:     config.DatabaseInfo{OneServer: "srv-1c", DbServer: "sql-test"},
+	})
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Contains(t, parseDbConfig(t, out), "erp_test")
+}
+
+func TestApplyDbConfigChange_Update(t *testing.T) {
+	out, changed, err := ApplyDbConfigChange([]byte(sampleDbConfig), DbConfigChange{
+		Action:   DbConfigUpdate,
+		Infobase: "erp_test",
+		Info:     config.DatabaseInfo{DbServer: "sql-test-2"},
+	})
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	m := parseDbConfig(t, out)
+	assert.Equal(t, "sql-test-2", m["erp_test"].DbServer)
+	assert.Equal(t, "srv-1c", m["erp_test"].OneServer, "пустой one-server не изменяет запись")
+	assert.Equal(t, "sql-prod", m["erp"].DbServer)
+}
+
+func TestApplyDbConfigChange_UpdateMissing(t *testing.T) {
+	_, _, err := ApplyDbConfigChange([]byte(sampleDbConfig), DbConfigChange{
+		Action:   DbConfigUpdate,
+		Infobase: "zup_test",
+		Info:     config.DatabaseInfo{DbServer: "sql-test-2"},
+	})
+	require.Error(t, err)
+}
+
+func TestApplyDbConfigChange_Remove(t *testing.T) {
+	out, changed, err := ApplyDbConfigChange([]byte(sampleDbConfig), DbConfigChange{
+		Action:   DbConfigRemove,
+		Infobase: "erp_test",
+	})
+	require.NoError(t, err)
+	assert.True(t, changed)
+	m := parseDbConfig(t, out)
+	assert.NotContains(t, m, "erp_test")
+	assert.Contains(t, m, "erp")
+
+	_, changed, err = ApplyDbConfigChange(out, DbConfigChange{Action: DbConfigRemove, Infobase: "erp_test"})
+	require.NoError(t, err)
+	assert.False(t, changed)
+}
+
+func TestApplyDbConfigChange_NotMapping(t *testing.T) {
+	_, _, err := ApplyDbConfigChange([]byte("- erp\n- zup\n"), DbConfigChange{Action: DbConfigRemove, Infobase: "erp"})
+	require.Error(t, err)
+}
+
+func TestResolveDbConfigLocation(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *config.Config
+		want    *DbConfigLocation
+		wantErr bool
+	}{
+		{
+			name: "contents API URL",
+			cfg: &config.Config{
+				ConfigDbData: "https://gitea.example.com/api/v1/repos/gitops-tools/gitops_config/contents/db/dbconfig.yaml?ref=release",
+			},
+			want: &DbConfigLocation{Owner: "gitops-tools", Repo: "gitops_config", Branch: "release", Path: "db/dbconfig.yaml"},
+		},
+		{
+			name: "URL без ref",
+			cfg: &config.Config{
+				ConfigDbData: "https://gitea.example.com/api/v1/repos/gitops-tools/gitops_config/contents/dbconfig.yaml",
+			},
+			want: &DbConfigLocation{Owner: "gitops-tools", Repo: "gitops_config", Branch: "main", Path: "dbconfig.yaml"},
+		},
+		{
+			name:    "raw URL",
+			cfg:     &config.Config{ConfigDbData: "https://gitea.example.com/gitops-tools/gitops_config/raw/branch/main/dbconfig.yaml"},
+			wantErr: true,
+		},
+		{
+			name: "путь в текущем репозитории",
+			cfg:  &config.Config{ConfigDbData: "dbconfig.yaml", Owner: "test", Repo: "erp", BaseBranch: "develop"},
+			want: &DbConfigLocation{Owner: "test", Repo: "erp", Branch: "develop", Path: "dbconfig.yaml"},
+		},
+		{
+			name:    "не задан",
+			cfg:     &config.Config{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc, err := ResolveDbConfigLocation(tt.cfg)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, loc)
+		})
+	}
+}
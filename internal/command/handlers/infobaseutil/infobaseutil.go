@@ -0,0 +1,88 @@
+// Package infobaseutil содержит общие функции команд nr-infobase-create,
+// nr-infobase-update и nr-infobase-drop: определение сервера 1C и параметров
+// СУБД из конфигурации и предложение изменений dbconfig.yaml через Pull Request.
+package infobaseutil
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/pkg/apperrors"
+)
+
+// DefaultDBMS — тип СУБД по умолчанию.
+const DefaultDBMS = "MSSQLServer"
+
+// ResolveServer возвращает сервер 1C информационной базы: BR_INFOBASE_SERVER,
+// иначе one-server из DbConfig, иначе RacConfig.RacServer.
+func ResolveServer(cfg *config.Config) (string, error) {
+	if server := os.Getenv(constants.EnvInfobaseServer); server != "" {
+		return server, nil
+	}
+	if server := cfg.GetOneServer(cfg.InfobaseName); server != "" {
+		return server, nil
+	}
+	if cfg.RacConfig != nil && cfg.RacConfig.RacServer != "" {
+		return cfg.RacConfig.RacServer, nil
+	}
+	return "", fmt.Errorf("не удалось определить сервер 1C для информационной базы '%s': укажите %s",
+		cfg.InfobaseName, constants.EnvInfobaseServer)
+}
+
+// DBCredentials возвращает пользователя и пароль СУБД (users.mssql, passwords.mssql).
+func DBCredentials(cfg *config.Config) (user, password string) {
+	if cfg.AppConfig != nil {
+		user = cfg.AppConfig.Users.Mssql
+	}
+	if cfg.SecretConfig != nil {
+		password = cfg.SecretConfig.Passwords.Mssql
+	}
+	return user, password
+}
+
+// ParseDropMode разбирает режим удаления из BR_INFOBASE_DROP_MODE (пустой — keep).
+func ParseDropMode() (rac.InfobaseDropMode, error) {
+	v := strings.ToLower(strings.TrimSpace(os.Getenv(constants.EnvInfobaseDropMode)))
+	switch rac.InfobaseDropMode(v) {
+	case "", rac.InfobaseDropKeepDatabase:
+		return rac.InfobaseDropKeepDatabase, nil
+	case rac.InfobaseDropDatabase, rac.InfobaseDropClearDatabase:
+		return rac.InfobaseDropMode(v), nil
+	}
+	return "", fmt.Errorf("недопустимое значение %s: %q (ожидается keep, drop или clear)", constants.EnvInfobaseDropMode, v)
+}
+
+// DbConfigPREnabled сообщает, нужно ли предлагать изменение dbconfig.yaml (BR_INFOBASE_DBCONFIG_PR).
+func DbConfigPREnabled() bool {
+	v, err := strconv.ParseBool(os.Getenv(constants.EnvInfobaseDbConfigPR))
+	return err != nil || v
+}
+
+// PlanParams возвращает параметры информационной базы для плана dry-run.
+// Пароль СУБД в план не попадает.
+func PlanParams(server string, params rac.InfobaseParams) map[string]any {
+	p := map[string]any{
+		"server":    server,
+		"infobase":  params.Name,
+		"dbms":      params.DBMS,
+		"db_server": params.DBServer,
+		"db_name":   params.DBName,
+		"db_user":   params.DBUser,
+	}
+	if params.DBPassword != "" {
+		p["db_password"] = "***"
+	}
+	return p
+}
+
+// IsNotFound сообщает, что RAC не нашёл информационную базу (код RAC.NOT_FOUND).
+func IsNotFound(err error) bool {
+	var coded apperrors.Coded
+	return errors.As(err, &coded) && coded.ErrorCode() == rac.ErrRACNotFound
+}
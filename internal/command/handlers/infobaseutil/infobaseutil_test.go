@@ -0,0 +1,102 @@
+package infobaseutil
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/pkg/apperrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveServer(t *testing.T) {
+	cfg := &config.Config{
+		InfobaseName: "erp_test",
+		DbConfig:     map[string]*config.DatabaseInfo{"erp_test": {OneServer: "srv-db"}},
+		RacConfig:    &config.RacConfig{RacServer: "srv-rac"},
+	}
+
+	t.Setenv(constants.EnvInfobaseServer, "")
+	server, err := ResolveServer(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "srv-db", server)
+
+	t.Setenv(constants.EnvInfobaseServer, "srv-env")
+	server, err = ResolveServer(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "srv-env", server)
+
+	t.Setenv(constants.EnvInfobaseServer, "")
+	cfg.InfobaseName = "new_base"
+	server, err = ResolveServer(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "srv-rac", server)
+
+	cfg.RacConfig = nil
+	_, err = ResolveServer(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), constants.EnvInfobaseServer)
+}
+
+func TestDBCredentials(t *testing.T) {
+	user, password := DBCredentials(&config.Config{})
+	assert.Empty(t, user)
+	assert.Empty(t, password)
+
+	cfg := &config.Config{AppConfig: &config.AppConfig{}, SecretConfig: &config.SecretConfig{}}
+	cfg.AppConfig.Users.Mssql = "sa"
+	cfg.SecretConfig.Passwords.Mssql = "secret"
+	user, password = DBCredentials(cfg)
+	assert.Equal(t, "sa", user)
+	assert.Equal(t, "secret", password)
+}
+
+func TestParseDropMode(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    rac.InfobaseDropMode
+		wantErr bool
+	}{
+		{value: "", want: rac.InfobaseDropKeepDatabase},
+		{value: "keep", want: rac.InfobaseDropKeepDatabase},
+		{value: "DROP", want: rac.InfobaseDropDatabase},
+		{value: "clear", want: rac.InfobaseDropClearDatabase},
+		{value: "purge", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv(constants.EnvInfobaseDropMode, tt.value)
+			mode, err := ParseDropMode()
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, mode)
+		})
+	}
+}
+
+func TestDbConfigPREnabled(t *testing.T) {
+	t.Setenv(constants.EnvInfobaseDbConfigPR, "")
+	assert.True(t, DbConfigPREnabled())
+	t.Setenv(constants.EnvInfobaseDbConfigPR, "false")
+	assert.False(t, DbConfigPREnabled())
+	t.Setenv(constants.EnvInfobaseDbConfigPR, "true")
+	assert.True(t, DbConfigPREnabled())
+}
+
+func TestPlanParams_MasksPassword(t *testing.T) {
+	p := PlanParams("srv", rac.InfobaseParams{Name: "erp_test", DBUser: "sa", DBPassword: "secret"})
+	assert.Equal(t, "***", p["db_password"])
+	assert.NotContains(t, p, "secret")
+}
+
+func TestIsNotFound(t *testing.T) {
+	assert.True(t, IsNotFound(apperrors.NewAppError(rac.ErrRACNotFound, "информационная база не найдена", nil)))
+	assert.False(t, IsNotFound(apperrors.NewAppError(rac.ErrRACExec, "ошибка выполнения RAC", nil)))
+	assert.False(t, IsNotFound(errors.New("connection refused")))
+}
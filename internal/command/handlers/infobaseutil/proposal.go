@@ -0,0 +1,223 @@
+package infobaseutil
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/entity/gitea"
+)
+
+// DbConfigRepository — операции Gitea, необходимые для предложения изменения dbconfig.yaml.
+// Реализуется *gitea.API, привязанным к репозиторию и ветке dbconfig.yaml.
+type DbConfigRepository interface {
+	GetConfigData(ctx context.Context, l *slog.Logger, filename string) ([]byte, error)
+	GetRepositoryContents(ctx context.Context, filepath, branch string) ([]gitea.FileInfo, error)
+	SetRepositoryStateWithNewBranch(ctx context.Context, l *slog.Logger, operations []gitea.ChangeFileOperation, baseBranch, newBranch, commitMessage string) (string, error)
+	CreatePRWithOptions(ctx context.Context, opts gitea.CreatePROptions) (*gitea.PRResponse, error)
+}
+
+// DbConfigProposal — результат предложения изменения dbconfig.yaml.
+type DbConfigProposal struct {
+	// Repository — репозиторий dbconfig.yaml (owner/repo)
+	Repository string `json:"repository,omitempty"`
+	// Path — путь к dbconfig.yaml в репозитории
+	Path string `json:"path,omitempty"`
+	// Branch — ветка с изменением
+	Branch string `json:"branch,omitempty"`
+	// PRNumber — номер созданного Pull Request
+	PRNumber int64 `json:"pr_number,omitempty"`
+	// PRURL — ссылка на Pull Request
+	PRURL string `json:"pr_url,omitempty"`
+	// Skipped — причина, по которой Pull Request не создавался
+	Skipped string `json:"skipped,omitempty"`
+	// Error — ошибка создания Pull Request (операция над базой при этом выполнена)
+	Error string `json:"error,omitempty"`
+}
+
+// WriteText выводит результат в человекочитаемом формате (nil — ничего не выводится).
+func (p *DbConfigProposal) WriteText(w io.Writer) error {
+	if p == nil {
+		return nil
+	}
+	var err error
+	switch {
+	case p.Error != "":
+		_, err = fmt.Fprintf(w, "dbconfig.yaml: изменение не предложено: %s\n", p.Error)
+	case p.Skipped != "":
+		_, err = fmt.Fprintf(w, "dbconfig.yaml: %s\n", p.Skipped)
+	default:
+		_, err = fmt.Fprintf(w, "dbconfig.yaml: предложен Pull Request #%d %s\n", p.PRNumber, p.PRURL)
+	}
+	return err
+}
+
+// DbConfigProposer предлагает изменения dbconfig.yaml через Pull Request в Gitea.
+// Ошибки не прерывают команду: они возвращаются в DbConfigProposal.Error,
+// так как операция над информационной базой к этому моменту уже выполнена.
+type DbConfigProposer struct {
+	// NewRepository — опциональная фабрика клиента Gitea (nil в production, mock в тестах)
+	NewRepository func(cfg *config.Config, loc *DbConfigLocation) DbConfigRepository
+	// Now — опциональный источник текущего времени (nil в production)
+	Now func() time.Time
+}
+
+// Propose создаёт ветку с изменением dbconfig.yaml и открывает Pull Request.
+// command и traceID попадают в описание Pull Request.
+func (p *DbConfigProposer) Propose(ctx context.Context, l *slog.Logger, cfg *config.Config, change DbConfigChange, command, traceID string) *DbConfigProposal {
+	if !DbConfigPREnabled() {
+		return &DbConfigProposal{Skipped: "предложение изменений dbconfig.yaml отключено"}
+	}
+	if cfg.GiteaURL == "" || cfg.AccessToken == "" {
+		l.Warn("Gitea не настроен, изменение dbconfig.yaml не предложено")
+		return &DbConfigProposal{Skipped: "Gitea не настроен"}
+	}
+
+	loc, err := ResolveDbConfigLocation(cfg)
+	if err != nil {
+		l.Warn("Не удалось определить расположение dbconfig.yaml", slog.String("error", err.Error()))
+		return &DbConfigProposal{Error: err.Error()}
+	}
+	result := &DbConfigProposal{Repository: loc.FullName(), Path: loc.Path}
+
+	newRepo := p.NewRepository
+	if newRepo == nil {
+		newRepo = newGiteaRepository
+	}
+	repo := newRepo(cfg, loc)
+
+	fail := func(msg string, err error) *DbConfigProposal {
+		l.Warn(msg, slog.String("repository", result.Repository), slog.String("error", err.Error()))
+		result.Error = fmt.Sprintf("%s: %v", msg, err)
+		return result
+	}
+
+	data, err := repo.GetConfigData(ctx, l, loc.Path)
+	if err != nil {
+		return fail("Не удалось прочитать dbconfig.yaml", err)
+	}
+	updated, changed, err := ApplyDbConfigChange(data, change)
+	if err != nil {
+		return fail("Не удалось изменить dbconfig.yaml", err)
+	}
+	if !changed {
+		result.Skipped = "dbconfig.yaml уже содержит изменение"
+		return result
+	}
+
+	sha, err := fileSHA(ctx, repo, loc)
+	if err != nil {
+		return fail("Не удалось получить SHA dbconfig.yaml", err)
+	}
+
+	now := time.Now()
+	if p.Now != nil {
+		now = p.Now()
+	}
+	result.Branch = fmt.Sprintf("dbconfig/%s-%s-%s", change.Action, branchSafe(change.Infobase), now.Format("20060102150405"))
+	title := fmt.Sprintf("chore(dbconfig): %s %s", change.Action, change.Infobase)
+
+	ops := []gitea.ChangeFileOperation{{
+		Operation: "update",
+		Path:      loc.Path,
+		Content:   base64.StdEncoding.EncodeToString(updated),
+		SHA:       sha,
+	}}
+	if _, err := repo.SetRepositoryStateWithNewBranch(ctx, l, ops, loc.Branch, result.Branch, title); err != nil {
+		return fail("Не удалось создать ветку с изменением dbconfig.yaml", err)
+	}
+
+	pr, err := repo.CreatePRWithOptions(ctx, gitea.CreatePROptions{
+		Title: title,
+		Body:  buildPRBody(change, command, traceID),
+		Head:  result.Branch,
+		Base:  loc.Branch,
+	})
+	if err != nil {
+		return fail("Не удалось создать Pull Request с изменением dbconfig.yaml", err)
+	}
+	result.PRNumber = pr.Number
+	result.PRURL = pr.HTMLURL
+
+	l.Info("Предложено изменение dbconfig.yaml",
+		slog.String("repository", result.Repository),
+		slog.Int64("pr_number", pr.Number),
+		slog.String("pr_url", pr.HTMLURL))
+	return result
+}
+
+// newGiteaRepository создаёт клиент Gitea для репозитория dbconfig.yaml.
+func newGiteaRepository(cfg *config.Config, loc *DbConfigLocation) DbConfigRepository {
+	return gitea.NewGiteaAPI(gitea.Config{
+		GiteaURL:    cfg.GiteaURL,
+		Owner:       loc.Owner,
+		Repo:        loc.Repo,
+		AccessToken: cfg.AccessToken,
+		BaseBranch:  loc.Branch,
+	})
+}
+
+// fileSHA возвращает SHA dbconfig.yaml из содержимого родительского каталога.
+func fileSHA(ctx context.Context, repo DbConfigRepository, loc *DbConfigLocation) (string, error) {
+	dir := path.Dir(loc.Path)
+	if dir == "." {
+		dir = ""
+	}
+	files, err := repo.GetRepositoryContents(ctx, dir, loc.Branch)
+	if err != nil {
+		return "", err
+	}
+	for _, f := range files {
+		if f.Path == loc.Path {
+			return f.SHA, nil
+		}
+	}
+	return "", fmt.Errorf("файл %s не найден в ветке %s", loc.Path, loc.Branch)
+}
+
+// branchSafe приводит имя информационной базы к виду, допустимому в имени ветки.
+func branchSafe(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r == '~' || r == '^' || r == ':' || r == '?' || r == '*' || r == '[' || r == '\\' {
+			return '-'
+		}
+		return r
+	}, strings.ToLower(name))
+}
+
+// buildPRBody формирует markdown описание Pull Request.
+func buildPRBody(change DbConfigChange, command, traceID string) string {
+	var sb strings.Builder
+	sb.WriteString("## dbconfig.yaml\n\n")
+	fmt.Fprintf(&sb, "**Информационная база:** %s\n", change.Infobase)
+	switch change.Action {
+	case DbConfigAdd:
+		sb.WriteString("**Изменение:** добавление записи\n\n")
+	case DbConfigUpdate:
+		sb.WriteString("**Изменение:** обновление серверов\n\n")
+	case DbConfigRemove:
+		sb.WriteString("**Изменение:** удаление записи\n\n")
+	}
+	if change.Action != DbConfigRemove {
+		sb.WriteString("```yaml\n")
+		fmt.Fprintf(&sb, "%s:\n", change.Infobase)
+		if change.Info.OneServer != "" {
+			fmt.Fprintf(&sb, "  one-server: %s\n", change.Info.OneServer)
+		}
+		if change.Action == DbConfigAdd {
+			fmt.Fprintf(&sb, "  prod: %t\n", change.Info.Prod)
+		}
+		if change.Info.DbServer != "" {
+			fmt.Fprintf(&sb, "  dbserver: %s\n", change.Info.DbServer)
+		}
+		sb.WriteString("```\n\n")
+	}
+	fmt.Fprintf(&sb, "Создано командой `%s` (trace_id: %s).\n", command, traceID)
+	return sb.String()
+}
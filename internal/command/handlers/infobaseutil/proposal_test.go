@@ -0,0 +1,148 @@
+package infobaseutil
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/entity/gitea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRepository — репозиторий dbconfig.yaml в памяти.
+type fakeRepository struct {
+	data      []byte
+	files     []gitea.FileInfo
+	ops       []gitea.ChangeFileOperation
+	base      string
+	newBranch string
+	pr        *gitea.CreatePROptions
+	prErr     error
+}
+
+func (f *fakeRepository) GetConfigData(context.Context, *slog.Logger, string) ([]byte, error) {
+	return f.data, nil
+}
+
+func (f *fakeRepository) GetRepositoryContents(context.Context, string, string) ([]gitea.FileInfo, error) {
+	return f.files, nil
+}
+
+func (f *fakeRepository) SetRepositoryStateWithNewBranch(_ context.Context, _ *slog.Logger, ops []gitea.ChangeFileOperation, base, newBranch, _ string) (string, error) {
+	f.ops, f.base, f.newBranch = ops, base, newBranch
+	return "commit-sha", nil
+}
+
+func (f *fakeRepository) CreatePRWithOptions(_ context.Context, opts gitea.CreatePROptions) (*gitea.PRResponse, error) {
+	if f.prErr != nil {
+		return nil, f.prErr
+	}
+	f.pr = &opts
+	return &gitea.PRResponse{Number: 42, HTMLURL: "https://gitea.example.com/ops/config/pulls/42"}, nil
+}
+
+func proposalConfig() *config.Config {
+	return &config.Config{
+		GiteaURL:     "https://gitea.example.com",
+		AccessToken:  "token",
+		ConfigDbData: "https://gitea.example.com/api/v1/repos/ops/config/contents/dbconfig.yaml?ref=main",
+	}
+}
+
+func newProposer(repo *fakeRepository) *DbConfigProposer {
+	return &DbConfigProposer{
+		NewRepository: func(*config.Config, *DbConfigLocation) DbConfigRepository { return repo },
+		Now:           func() time.Time { return time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC) },
+	}
+}
+
+func TestDbConfigProposer_Propose(t *testing.T) {
+	t.Setenv(constants.EnvInfobaseDbConfigPR, "")
+	repo := &fakeRepository{
+		data:  []byte(sampleDbConfig),
+		files: []gitea.FileInfo{{Path: "app.yaml", SHA: "a"}, {Path: "dbconfig.yaml", SHA: "file-sha"}},
+	}
+
+	result := newProposer(repo).Propose(context.Background(), slog.Default(), proposalConfig(), DbConfigChange{
+		Action:   DbConfigAdd,
+		Infobase: "zup_test",
+		Info:     config.DatabaseInfo{OneServer: "srv-1c", DbServer: "sql-test"},
+	}, constants.ActNRInfobaseCreate, "trace-1")
+
+	assert.Empty(t, result.Error)
+	assert.Equal(t, "ops/config", result.Repository)
+	assert.Equal(t, "dbconfig/add-zup_test-20261017120000", result.Branch)
+	assert.Equal(t, int64(42), result.PRNumber)
+
+	require.Len(t, repo.ops, 1)
+	assert.Equal(t, "update", repo.ops[0].Operation)
+	assert.Equal(t, "file-sha", repo.ops[0].SHA)
+	content, err := base64.StdEncoding.DecodeString(repo.ops[0].Content)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "zup_test:")
+	assert.Equal(t, "main", repo.base)
+
+	require.NotNil(t, repo.pr)
+	assert.Equal(t, "main", repo.pr.Base)
+	assert.Equal(t, result.Branch, repo.pr.Head)
+	assert.Contains(t, repo.pr.Body, "trace-1")
+	assert.Contains(t, repo.pr.Body, constants.ActNRInfobaseCreate)
+}
+
+func TestDbConfigProposer_AlreadyUpToDate(t *testing.T) {
+	t.Setenv(constants.EnvInfobaseDbConfigPR, "")
+	repo := &fakeRepository{data: []byte(sampleDbConfig)}
+
+	result := newProposer(repo).Propose(context.Background(), slog.Default(), proposalConfig(), DbConfigChange{
+		Action: DbConfigRemove, Infobase: "zup_test",
+	}, constants.ActNRInfobaseDrop, "trace-1")
+
+	assert.NotEmpty(t, result.Skipped)
+	assert.Nil(t, repo.ops)
+}
+
+func TestDbConfigProposer_Disabled(t *testing.T) {
+	t.Setenv(constants.EnvInfobaseDbConfigPR, "false")
+	repo := &fakeRepository{data: []byte(sampleDbConfig)}
+
+	result := newProposer(repo).Propose(context.Background(), slog.Default(), proposalConfig(), DbConfigChange{
+		Action: DbConfigRemove, Infobase: "erp_test",
+	}, constants.ActNRInfobaseDrop, "trace-1")
+
+	assert.NotEmpty(t, result.Skipped)
+	assert.Nil(t, repo.ops)
+}
+
+func TestDbConfigProposer_GiteaNotConfigured(t *testing.T) {
+	t.Setenv(constants.EnvInfobaseDbConfigPR, "")
+	cfg := proposalConfig()
+	cfg.AccessToken = ""
+
+	result := newProposer(&fakeRepository{}).Propose(context.Background(), slog.Default(), cfg, DbConfigChange{
+		Action: DbConfigRemove, Infobase: "erp_test",
+	}, constants.ActNRInfobaseDrop, "trace-1")
+
+	assert.Equal(t, "Gitea не настроен", result.Skipped)
+}
+
+func TestDbConfigProposer_PRFailed(t *testing.T) {
+	t.Setenv(constants.EnvInfobaseDbConfigPR, "")
+	repo := &fakeRepository{
+		data:  []byte(sampleDbConfig),
+		files: []gitea.FileInfo{{Path: "dbconfig.yaml", SHA: "file-sha"}},
+		prErr: errors.New("403 forbidden"),
+	}
+
+	result := newProposer(repo).Propose(context.Background(), slog.Default(), proposalConfig(), DbConfigChange{
+		Action: DbConfigRemove, Infobase: "erp_test",
+	}, constants.ActNRInfobaseDrop, "trace-1")
+
+	assert.Contains(t, result.Error, "403 forbidden")
+	assert.NotEmpty(t, result.Branch, "ветка создана до ошибки PR")
+}
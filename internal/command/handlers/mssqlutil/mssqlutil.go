@@ -0,0 +1,82 @@
+// Package mssqlutil предоставляет общие для NR-команд утилиты работы с MSSQL:
+// параметры клиента из конфигурации приложения и сведения о восстановлении баз.
+package mssqlutil
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Kargones/apk-ci/internal/adapter/mssql"
+	"github.com/Kargones/apk-ci/internal/config"
+)
+
+// DefaultTimeout — таймаут подключения по умолчанию.
+const DefaultTimeout = 30 * time.Second
+
+// Options возвращает параметры подключения к серверу server (база master)
+// с учётными данными из конфигурации (users.mssql, по умолчанию gitops).
+func Options(cfg *config.Config, server string, timeout time.Duration) mssql.ClientOptions {
+	opts := mssql.ClientOptions{
+		Server:   server,
+		Port:     1433,
+		User:     "gitops",
+		Database: "master",
+		Timeout:  timeout,
+	}
+	if cfg.AppConfig != nil && cfg.AppConfig.Users.Mssql != "" {
+		opts.User = cfg.AppConfig.Users.Mssql
+	}
+	if cfg.SecretConfig != nil {
+		opts.Password = cfg.SecretConfig.Passwords.Mssql
+	}
+	return opts
+}
+
+// NewClient создаёт MSSQL клиент для сервера server.
+func NewClient(cfg *config.Config, server string, timeout time.Duration) (mssql.Client, error) {
+	if cfg.AppConfig == nil {
+		return nil, fmt.Errorf("конфигурация приложения не загружена")
+	}
+	return mssql.NewClient(Options(cfg, server, timeout))
+}
+
+// LastRestore подключается клиентом client и возвращает последнее восстановление
+// базы database (nil — база не восстанавливалась). Времена переводятся в ServerTime.
+func LastRestore(ctx context.Context, client mssql.Client, database string) (*mssql.LastRestore, error) {
+	history, ok := client.(mssql.RestoreHistoryProvider)
+	if !ok {
+		return nil, fmt.Errorf("MSSQL клиент не поддерживает историю восстановлений")
+	}
+	if err := client.Connect(ctx); err != nil {
+		return nil, err
+	}
+	defer func() { _ = client.Close() }() //nolint:errcheck // ошибка закрытия не влияет на результат чтения
+
+	last, err := history.GetLastRestore(ctx, database)
+	if err != nil || last == nil {
+		return nil, err
+	}
+	last.RestoredAt = ServerTime(last.RestoredAt)
+	last.DataAsOf = ServerTime(last.DataAsOf)
+	return last, nil
+}
+
+// ServerLocation возвращает часовой пояс серверов СУБД контура (Europe/Moscow, при отсутствии tzdata — UTC).
+func ServerLocation() *time.Location {
+	loc, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// ServerTime переводит значение datetime SQL Server в часовой пояс ServerLocation.
+// Колонки datetime хранят время сервера без смещения, а драйвер возвращает их как UTC:
+// показания часов сохраняются, меняется только часовой пояс. Нулевое время не изменяется.
+func ServerTime(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), ServerLocation())
+}
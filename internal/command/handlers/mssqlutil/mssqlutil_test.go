@@ -0,0 +1,67 @@
+package mssqlutil
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Kargones/apk-ci/internal/adapter/mssql"
+	"github.com/Kargones/apk-ci/internal/adapter/mssql/mssqltest"
+	"github.com/Kargones/apk-ci/internal/config"
+)
+
+func TestOptions(t *testing.T) {
+	cfg := &config.Config{AppConfig: &config.AppConfig{}, SecretConfig: &config.SecretConfig{}}
+	opts := Options(cfg, "srv-sql", DefaultTimeout)
+	if opts.User != "gitops" || opts.Database != "master" || opts.Port != 1433 || opts.Server != "srv-sql" {
+		t.Errorf("Options() = %+v", opts)
+	}
+
+	cfg.AppConfig.Users.Mssql = "sa"
+	cfg.SecretConfig.Passwords.Mssql = "secret"
+	opts = Options(cfg, "srv-sql", time.Second)
+	if opts.User != "sa" || opts.Password != "secret" || opts.Timeout != time.Second {
+		t.Errorf("Options() = %+v", opts)
+	}
+}
+
+func TestNewClient_NoAppConfig(t *testing.T) {
+	if _, err := NewClient(&config.Config{}, "srv-sql", DefaultTimeout); err == nil {
+		t.Error("NewClient() без AppConfig должен вернуть ошибку")
+	}
+}
+
+func TestLastRestore(t *testing.T) {
+	restored := time.Date(2026, 10, 15, 5, 0, 0, 0, time.UTC)
+	closed := false
+	client := &mssqltest.MockMSSQLClient{
+		CloseFunc: func() error { closed = true; return nil },
+		GetLastRestoreFunc: func(_ context.Context, database string) (*mssql.LastRestore, error) {
+			return &mssql.LastRestore{Database: database, RestoredAt: restored}, nil
+		},
+	}
+
+	last, err := LastRestore(context.Background(), client, "ERP_TEST")
+	if err != nil {
+		t.Fatalf("LastRestore() error = %v", err)
+	}
+	if last.RestoredAt.Hour() != 5 || last.RestoredAt.Location().String() != ServerLocation().String() {
+		t.Errorf("RestoredAt = %v, ожидалось 05:00 по времени сервера", last.RestoredAt)
+	}
+	if !last.DataAsOf.IsZero() {
+		t.Errorf("DataAsOf = %v, ожидалось нулевое время", last.DataAsOf)
+	}
+	if !closed {
+		t.Error("соединение не закрыто")
+	}
+
+	client.ConnectFunc = func(context.Context) error { return errors.New("login failed") }
+	if _, err := LastRestore(context.Background(), client, "ERP_TEST"); err == nil {
+		t.Error("LastRestore() должен вернуть ошибку подключения")
+	}
+
+	if _, err := LastRestore(context.Background(), struct{ mssql.Client }{client}, "ERP_TEST"); err == nil {
+		t.Error("LastRestore() для клиента без истории должен вернуть ошибку")
+	}
+}
@@ -0,0 +1,51 @@
+package racutil
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+)
+
+// DefaultLeaseTTL — срок аренды сервисного режима по умолчанию.
+const DefaultLeaseTTL = 4 * time.Hour
+
+// LeaseTTL возвращает срок аренды сервисного режима из BR_SERVICE_MODE_LEASE_TTL.
+// Пустое значение — DefaultLeaseTTL, 0 — аренда не записывается.
+func LeaseTTL() (time.Duration, error) {
+	v := os.Getenv(constants.EnvServiceModeLeaseTTL)
+	if v == "" {
+		return DefaultLeaseTTL, nil
+	}
+	if v == "0" {
+		return 0, nil
+	}
+	ttl, err := time.ParseDuration(v)
+	if err != nil || ttl < 0 {
+		return 0, fmt.Errorf("недопустимое значение %s: %q (ожидается длительность, например 4h)", constants.EnvServiceModeLeaseTTL, v)
+	}
+	return ttl, nil
+}
+
+// LeaseOwner возвращает владельца аренды сервисного режима. Владелец должен
+// совпадать у шагов одного запуска пайплайна (enable и disable выполняются
+// разными процессами), поэтому по умолчанию это запуск workflow
+// (GITHUB_REPOSITORY#GITHUB_RUN_ID), а вне workflow — пользователь или имя хоста.
+func LeaseOwner(cfg *config.Config) string {
+	if owner := os.Getenv(constants.EnvServiceModeLeaseOwner); owner != "" {
+		return owner
+	}
+	if runID := os.Getenv("GITHUB_RUN_ID"); runID != "" {
+		return os.Getenv("GITHUB_REPOSITORY") + "#" + runID
+	}
+	if cfg != nil && cfg.Actor != "" {
+		return cfg.Actor
+	}
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		return "apk-ci"
+	}
+	return host
+}
@@ -1,25 +1,100 @@
 // Package racutil предоставляет общие утилиты для создания RAC клиента
-// из конфигурации приложения. Вынесен из отдельных handler-пакетов для
-// устранения дублирования кода (бывший TODO H-2).
+// и аренды сервисного режима из конфигурации приложения. Вынесен из
+// отдельных handler-пакетов для устранения дублирования кода (бывший TODO H-2).
 package racutil
 
 import (
 	"fmt"
 	"log/slog"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac/ras"
 	"github.com/Kargones/apk-ci/internal/config"
 )
 
+// ErrNativeUnsupported — команда использует возможности RAC клиента,
+// не реализованные клиентом протокола RAS (implementations.rac = "native").
+const ErrNativeUnsupported = "RAC.NATIVE_UNSUPPORTED"
+
+// Capability — необязательная возможность RAC клиента, определяемая
+// приведением типа к интерфейсу пакета rac.
+type Capability string
+
+// Необязательные возможности RAC клиента.
+const (
+	// CapInventory — перечень кластеров, баз, серверов и процессов (rac.InventoryProvider)
+	CapInventory Capability = "инвентаризация кластера"
+	// CapLicenses — лицензии сессий (rac.LicenseProvider)
+	CapLicenses Capability = "лицензии сессий"
+	// CapLocks — перечень блокировок (rac.LockProvider)
+	CapLocks Capability = "блокировки информационной базы"
+	// CapInfobaseManager — регистрация и изменение информационных баз (rac.InfobaseManager)
+	CapInfobaseManager Capability = "управление информационными базами"
+)
+
+// supports проверяет, реализует ли client интерфейс возможности c.
+func supports(client rac.Client, c Capability) bool {
+	var ok bool
+	switch c {
+	case CapInventory:
+		_, ok = client.(rac.InventoryProvider)
+	case CapLicenses:
+		_, ok = client.(rac.LicenseProvider)
+	case CapLocks:
+		_, ok = client.(rac.LockProvider)
+	case CapInfobaseManager:
+		_, ok = client.(rac.InfobaseManager)
+	}
+	return ok
+}
+
+// IsNative проверяет, выбран ли клиент протокола RAS (implementations.rac = "native").
+func IsNative(cfg *config.Config) bool {
+	return cfg != nil && cfg.ImplementationsConfig != nil && cfg.ImplementationsConfig.RAC == "native"
+}
+
+// RequireCapabilities проверяет до обращения к RAS, что выбранный клиент
+// поддерживает возможности caps. Утилита rac поддерживает все возможности;
+// для implementations.rac = "native" возвращается ошибка с перечнем
+// недостающих, чтобы команда не выполнялась частично или с пустыми данными.
+func RequireCapabilities(cfg *config.Config, caps ...Capability) error {
+	if !IsNative(cfg) {
+		return nil
+	}
+	var native rac.Client = (*ras.Client)(nil)
+	missing := make([]string, 0, len(caps))
+	for _, c := range caps {
+		if !supports(native, c) {
+			missing = append(missing, string(c))
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return fmt.Errorf("клиент RAS (implementations.rac = native) не поддерживает: %s; используйте implementations.rac = rac",
+		strings.Join(missing, ", "))
+}
+
 // NewClient создаёт RAC клиент из конфигурации.
 // Логика определения сервера, порта, таймаута и credentials
 // извлечена из handler-пакетов servicemodestatushandler,
 // servicemodeenablehandler, servicemodedisablehandler и forcedisconnecthandler.
 func NewClient(cfg *config.Config) (rac.Client, error) {
+	server, err := ResolveServer(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return NewClientForServer(cfg, server)
+}
+
+// ResolveServer возвращает сервер 1C информационной базы cfg.InfobaseName:
+// из DbConfig, а при отсутствии записи — из RacConfig.
+func ResolveServer(cfg *config.Config) (string, error) {
 	if cfg.AppConfig == nil {
-		return nil, fmt.Errorf("конфигурация приложения не загружена")
+		return "", fmt.Errorf("конфигурация приложения не загружена")
 	}
 
 	// Получение сервера 1C для информационной базы
@@ -29,15 +104,32 @@ func NewClient(cfg *config.Config) (rac.Client, error) {
 		if cfg.RacConfig != nil && cfg.RacConfig.RacServer != "" {
 			server = cfg.RacConfig.RacServer
 		} else {
-			return nil, fmt.Errorf("не удалось определить сервер для информационной базы '%s'", cfg.InfobaseName)
+			return "", fmt.Errorf("не удалось определить сервер для информационной базы '%s'", cfg.InfobaseName)
 		}
 	}
+	return server, nil
+}
+
+// Port возвращает порт RAS из конфигурации (по умолчанию 1545).
+func Port(cfg *config.Config) string {
+	if cfg.AppConfig == nil || cfg.AppConfig.Rac.Port == 0 {
+		return "1545"
+	}
+	return strconv.Itoa(cfg.AppConfig.Rac.Port)
+}
 
-	port := strconv.Itoa(cfg.AppConfig.Rac.Port)
-	if port == "0" {
-		port = "1545"
+// NewClientForServer создаёт RAC клиент для сервера 1C server
+// с портом, таймаутом и credentials из конфигурации.
+// Используется командами, обходящими несколько информационных баз.
+// При implementations.rac = "native" возвращается клиент протокола RAS
+// без запуска утилиты rac.
+func NewClientForServer(cfg *config.Config, server string) (rac.Client, error) {
+	if cfg.AppConfig == nil {
+		return nil, fmt.Errorf("конфигурация приложения не загружена")
 	}
 
+	port := Port(cfg)
+
 	timeout := time.Duration(cfg.AppConfig.Rac.Timeout) * time.Second
 	if timeout == 0 {
 		timeout = 30 * time.Second
@@ -53,10 +145,12 @@ func NewClient(cfg *config.Config) (rac.Client, error) {
 		Server:       server,
 		Port:         port,
 		Timeout:      timeout,
+		Retries:      cfg.AppConfig.Rac.Retries,
 		ClusterUser:  cfg.AppConfig.Users.Rac,
 		ClusterPass:  "",
 		InfobaseUser: cfg.AppConfig.Users.Db,
 		InfobasePass: "",
+		Platform:     cfg.PlatformResolver(),
 	}
 
 	// Пароли из SecretConfig
@@ -73,5 +167,17 @@ func NewClient(cfg *config.Config) (rac.Client, error) {
 		slog.Default().Warn("RAC: указан пользователь базы, но пароль пуст — возможны ошибки аутентификации")
 	}
 
+	if IsNative(cfg) {
+		return ras.NewClient(ras.ClientOptions{
+			Server:       opts.Server,
+			Port:         opts.Port,
+			Timeout:      opts.Timeout,
+			ClusterUser:  opts.ClusterUser,
+			ClusterPass:  opts.ClusterPass,
+			InfobaseUser: opts.InfobaseUser,
+			InfobasePass: opts.InfobasePass,
+		})
+	}
+
 	return rac.NewClient(opts)
 }
@@ -2,9 +2,14 @@ package racutil
 
 import (
 	"os"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac/ras"
 	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
 )
 
 // helper: creates a temp file to act as a fake RAC binary.
@@ -347,3 +352,134 @@ func TestNewClient_TableDriven(t *testing.T) {
 		})
 	}
 }
+
+func TestNewClientForServer(t *testing.T) {
+	racPath := createFakeRACBinary(t)
+	cfg := validConfig(racPath, "")
+	cfg.DbConfig = nil
+
+	client, err := NewClientForServer(cfg, "srv-other")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if client == nil {
+		t.Fatal("expected non-nil client")
+	}
+
+	if _, err := NewClientForServer(&config.Config{}, "srv-other"); err == nil {
+		t.Fatal("expected error for nil AppConfig")
+	}
+}
+
+func TestNewClientForServer_Native(t *testing.T) {
+	cfg := validConfig("", "")
+	cfg.ImplementationsConfig = &config.ImplementationsConfig{RAC: "native"}
+
+	client, err := NewClientForServer(cfg, "srv-other")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := client.(*ras.Client); !ok {
+		t.Fatalf("expected *ras.Client, got %T", client)
+	}
+	if _, ok := client.(rac.ServiceModeScheduler); !ok {
+		t.Fatal("native client must support scheduled service mode")
+	}
+}
+
+func TestRequireCapabilities(t *testing.T) {
+	all := []Capability{CapInventory, CapLicenses, CapLocks, CapInfobaseManager}
+
+	cfg := validConfig("", "")
+	if err := RequireCapabilities(cfg, all...); err != nil {
+		t.Fatalf("rac supports all capabilities, got: %v", err)
+	}
+
+	cfg.ImplementationsConfig = &config.ImplementationsConfig{RAC: "native"}
+	if err := RequireCapabilities(cfg); err != nil {
+		t.Fatalf("no capabilities required, got: %v", err)
+	}
+	for _, c := range all {
+		err := RequireCapabilities(cfg, c)
+		if err == nil {
+			t.Fatalf("native client must reject %q", c)
+		}
+		if !strings.Contains(err.Error(), string(c)) {
+			t.Errorf("error must name %q: %v", c, err)
+		}
+	}
+}
+
+func TestResolveServer(t *testing.T) {
+	cfg := validConfig("", "db-server-1")
+	if got, err := ResolveServer(cfg); err != nil || got != "db-server-1" {
+		t.Fatalf("ResolveServer() = %q, %v; want db-server-1", got, err)
+	}
+
+	cfg.DbConfig = nil
+	cfg.RacConfig = &config.RacConfig{RacServer: "rac-fallback-server"}
+	if got, err := ResolveServer(cfg); err != nil || got != "rac-fallback-server" {
+		t.Fatalf("ResolveServer() = %q, %v; want rac-fallback-server", got, err)
+	}
+
+	cfg.RacConfig = nil
+	if _, err := ResolveServer(cfg); err == nil {
+		t.Fatal("expected error when server cannot be determined")
+	}
+}
+
+func TestPort(t *testing.T) {
+	cfg := validConfig("", "")
+	if got := Port(cfg); got != "1545" {
+		t.Errorf("Port() = %q, want 1545", got)
+	}
+	cfg.AppConfig.Rac.Port = 2545
+	if got := Port(cfg); got != "2545" {
+		t.Errorf("Port() = %q, want 2545", got)
+	}
+	if got := Port(&config.Config{}); got != "1545" {
+		t.Errorf("Port() without AppConfig = %q, want 1545", got)
+	}
+}
+
+func TestLeaseTTL(t *testing.T) {
+	t.Setenv(constants.EnvServiceModeLeaseTTL, "")
+	ttl, err := LeaseTTL()
+	if err != nil || ttl != DefaultLeaseTTL {
+		t.Fatalf("expected default TTL, got %v, %v", ttl, err)
+	}
+
+	t.Setenv(constants.EnvServiceModeLeaseTTL, "0")
+	if ttl, err = LeaseTTL(); err != nil || ttl != 0 {
+		t.Fatalf("expected disabled lease, got %v, %v", ttl, err)
+	}
+
+	t.Setenv(constants.EnvServiceModeLeaseTTL, "90m")
+	if ttl, err = LeaseTTL(); err != nil || ttl != 90*time.Minute {
+		t.Fatalf("expected 90m, got %v, %v", ttl, err)
+	}
+
+	t.Setenv(constants.EnvServiceModeLeaseTTL, "долго")
+	if _, err = LeaseTTL(); err == nil {
+		t.Fatal("expected error for invalid TTL")
+	}
+}
+
+func TestLeaseOwner(t *testing.T) {
+	t.Setenv(constants.EnvServiceModeLeaseOwner, "")
+	t.Setenv("GITHUB_RUN_ID", "")
+	if got := LeaseOwner(&config.Config{Actor: "deployer"}); got != "deployer" {
+		t.Fatalf("expected actor, got %q", got)
+	}
+
+	t.Setenv("GITHUB_REPOSITORY", "erp/main")
+	t.Setenv("GITHUB_RUN_ID", "1542")
+	if got := LeaseOwner(&config.Config{Actor: "deployer"}); got != "erp/main#1542" {
+		t.Fatalf("expected workflow run, got %q", got)
+	}
+
+	t.Setenv(constants.EnvServiceModeLeaseOwner, "night-release")
+	if got := LeaseOwner(nil); got != "night-release" {
+		t.Fatalf("expected explicit owner, got %q", got)
+	}
+}
@@ -4,11 +4,15 @@
 package handlers
 
 import (
+	"github.com/Kargones/apk-ci/internal/command/handlers/clusterhealthhandler"
+	"github.com/Kargones/apk-ci/internal/command/handlers/clusterinventoryhandler"
 	"github.com/Kargones/apk-ci/internal/command/handlers/converthandler"
 	"github.com/Kargones/apk-ci/internal/command/handlers/convertpipelinehandler"
 	"github.com/Kargones/apk-ci/internal/command/handlers/createstoreshandler"
 	"github.com/Kargones/apk-ci/internal/command/handlers/createtempdbhandler"
 	"github.com/Kargones/apk-ci/internal/command/handlers/dbrestorehandler"
+	"github.com/Kargones/apk-ci/internal/command/handlers/dbrestorehistoryhandler"
+	"github.com/Kargones/apk-ci/internal/command/handlers/dbsnapshothandler"
 	"github.com/Kargones/apk-ci/internal/command/handlers/dbupdatehandler"
 	"github.com/Kargones/apk-ci/internal/command/handlers/deprecatedaudithandler"
 	"github.com/Kargones/apk-ci/internal/command/handlers/executeepfhandler"
@@ -18,16 +22,26 @@ import (
 	"github.com/Kargones/apk-ci/internal/command/handlers/gitea/actionmenu"
 	"github.com/Kargones/apk-ci/internal/command/handlers/gitea/testmerge"
 	"github.com/Kargones/apk-ci/internal/command/handlers/help"
+	"github.com/Kargones/apk-ci/internal/command/handlers/infobasecreatehandler"
+	"github.com/Kargones/apk-ci/internal/command/handlers/infobasedrophandler"
+	"github.com/Kargones/apk-ci/internal/command/handlers/infobaseupdatehandler"
 	"github.com/Kargones/apk-ci/internal/command/handlers/migratehandler"
+	"github.com/Kargones/apk-ci/internal/command/handlers/scheduledjobshandler"
 	"github.com/Kargones/apk-ci/internal/command/handlers/servicemodedisablehandler"
 	"github.com/Kargones/apk-ci/internal/command/handlers/servicemodeenablehandler"
+	"github.com/Kargones/apk-ci/internal/command/handlers/servicemodereaperhandler"
+	"github.com/Kargones/apk-ci/internal/command/handlers/servicemodeschedulehandler"
 	"github.com/Kargones/apk-ci/internal/command/handlers/servicemodestatushandler"
+	"github.com/Kargones/apk-ci/internal/command/handlers/sessionsreporthandler"
 	"github.com/Kargones/apk-ci/internal/command/handlers/sonarqube/projectupdate"
 	"github.com/Kargones/apk-ci/internal/command/handlers/sonarqube/reportbranch"
 	"github.com/Kargones/apk-ci/internal/command/handlers/sonarqube/scanbranch"
 	"github.com/Kargones/apk-ci/internal/command/handlers/sonarqube/scanpr"
 	"github.com/Kargones/apk-ci/internal/command/handlers/store2dbhandler"
+	"github.com/Kargones/apk-ci/internal/command/handlers/store2githandler"
 	"github.com/Kargones/apk-ci/internal/command/handlers/storebindhandler"
+	"github.com/Kargones/apk-ci/internal/command/handlers/storehistoryhandler"
+	"github.com/Kargones/apk-ci/internal/command/handlers/storerestorebackuphandler"
 	"github.com/Kargones/apk-ci/internal/command/handlers/version"
 )
 
@@ -35,6 +49,12 @@ import (
 // Call this once from main() before using any commands.
 // Returns an error if any handler registration fails.
 func RegisterAll() error {
+	if err := clusterhealthhandler.RegisterCmd(); err != nil {
+		return err
+	}
+	if err := clusterinventoryhandler.RegisterCmd(); err != nil {
+		return err
+	}
 	if err := converthandler.RegisterCmd(); err != nil {
 		return err
 	}
@@ -50,6 +70,12 @@ func RegisterAll() error {
 	if err := dbrestorehandler.RegisterCmd(); err != nil {
 		return err
 	}
+	if err := dbrestorehistoryhandler.RegisterCmd(); err != nil {
+		return err
+	}
+	if err := dbsnapshothandler.RegisterCmd(); err != nil {
+		return err
+	}
 	if err := dbupdatehandler.RegisterCmd(); err != nil {
 		return err
 	}
@@ -77,18 +103,39 @@ func RegisterAll() error {
 	if err := help.RegisterCmd(); err != nil {
 		return err
 	}
+	if err := infobasecreatehandler.RegisterCmd(); err != nil {
+		return err
+	}
+	if err := infobasedrophandler.RegisterCmd(); err != nil {
+		return err
+	}
+	if err := infobaseupdatehandler.RegisterCmd(); err != nil {
+		return err
+	}
 	if err := migratehandler.RegisterCmd(); err != nil {
 		return err
 	}
+	if err := scheduledjobshandler.RegisterCmd(); err != nil {
+		return err
+	}
 	if err := servicemodedisablehandler.RegisterCmd(); err != nil {
 		return err
 	}
 	if err := servicemodeenablehandler.RegisterCmd(); err != nil {
 		return err
 	}
+	if err := servicemodereaperhandler.RegisterCmd(); err != nil {
+		return err
+	}
+	if err := servicemodeschedulehandler.RegisterCmd(); err != nil {
+		return err
+	}
 	if err := servicemodestatushandler.RegisterCmd(); err != nil {
 		return err
 	}
+	if err := sessionsreporthandler.RegisterCmd(); err != nil {
+		return err
+	}
 	if err := projectupdate.RegisterCmd(); err != nil {
 		return err
 	}
@@ -104,9 +151,18 @@ func RegisterAll() error {
 	if err := store2dbhandler.RegisterCmd(); err != nil {
 		return err
 	}
+	if err := store2githandler.RegisterCmd(); err != nil {
+		return err
+	}
 	if err := storebindhandler.RegisterCmd(); err != nil {
 		return err
 	}
+	if err := storehistoryhandler.RegisterCmd(); err != nil {
+		return err
+	}
+	if err := storerestorebackuphandler.RegisterCmd(); err != nil {
+		return err
+	}
 	if err := version.RegisterCmd(); err != nil {
 		return err
 	}
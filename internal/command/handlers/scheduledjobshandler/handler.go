@@ -0,0 +1,402 @@
+// Package scheduledjobshandler реализует NR-команду nr-scheduled-jobs
+// для блокировки регламентных заданий информационной базы независимо от
+// сервисного режима, со снимком исходного состояния и его восстановлением.
+//
+// RAC управляет только общим флагом scheduled-jobs-deny информационной базы:
+// перечень и блокировка отдельных заданий через сервер администрирования
+// недоступны, поэтому команда работает на уровне базы.
+package scheduledjobshandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
+	"github.com/Kargones/apk-ci/internal/command"
+	"github.com/Kargones/apk-ci/internal/command/handlers/racutil"
+	errhandler "github.com/Kargones/apk-ci/internal/command/handlers/shared"
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/pkg/dryrun"
+	"github.com/Kargones/apk-ci/internal/pkg/output"
+	"github.com/Kargones/apk-ci/internal/pkg/tracing"
+)
+
+// Действия команды (BR_SCHEDULED_JOBS_ACTION).
+const (
+	// ActionStatus — вывод текущего состояния и снимка (по умолчанию)
+	ActionStatus = "status"
+	// ActionBlock — снимок исходного состояния и блокировка заданий
+	ActionBlock = "block"
+	// ActionUnblock — безусловное снятие блокировки
+	ActionUnblock = "unblock"
+	// ActionRestore — восстановление состояния из снимка
+	ActionRestore = "restore"
+)
+
+// Compile-time interface check.
+var _ command.Handler = (*ScheduledJobsHandler)(nil)
+
+func RegisterCmd() error {
+	return command.Register(&ScheduledJobsHandler{})
+}
+
+// Snapshot — состояние регламентных заданий до блокировки пайплайном.
+type Snapshot struct {
+	// Infobase — имя информационной базы
+	Infobase string `json:"infobase"`
+	// Blocked — были ли задания заблокированы до вызова block
+	Blocked bool `json:"blocked"`
+	// TakenAt — время снимка
+	TakenAt time.Time `json:"taken_at"`
+}
+
+// ScheduledJobsData содержит данные ответа nr-scheduled-jobs.
+type ScheduledJobsData struct {
+	// Action — выполненное действие
+	Action string `json:"action"`
+	// InfobaseName — имя информационной базы
+	InfobaseName string `json:"infobase_name"`
+	// BlockedBefore — были ли задания заблокированы до вызова
+	BlockedBefore bool `json:"blocked_before"`
+	// Blocked — заблокированы ли задания после вызова
+	Blocked bool `json:"blocked"`
+	// StateChanged — было ли произведено реальное изменение состояния
+	StateChanged bool `json:"state_changed"`
+	// DryRun — изменения не выполнялись (BR_DRY_RUN)
+	DryRun bool `json:"dry_run,omitempty"`
+	// SnapshotPath — путь к файлу снимка
+	SnapshotPath string `json:"snapshot_path,omitempty"`
+	// Snapshot — снимок исходного состояния (nil — снимка нет)
+	Snapshot *Snapshot `json:"snapshot,omitempty"`
+	// SnapshotKept — block не перезаписал снимок предыдущего вызова
+	SnapshotKept bool `json:"snapshot_kept,omitempty"`
+}
+
+// writeText выводит результат в человекочитаемом формате.
+func (d *ScheduledJobsData) writeText(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "Регламентные задания: %s\nИнформационная база: %s\n",
+		jobsState(d.Blocked), d.InfobaseName); err != nil {
+		return err
+	}
+
+	if d.Action != ActionStatus {
+		change := "не изменялось"
+		switch {
+		case d.DryRun && d.Blocked != d.BlockedBefore:
+			change = fmt.Sprintf("будет изменено: %s → %s (dry-run)", jobsState(d.BlockedBefore), jobsState(d.Blocked))
+		case d.StateChanged:
+			change = fmt.Sprintf("%s → %s", jobsState(d.BlockedBefore), jobsState(d.Blocked))
+		}
+		if _, err := fmt.Fprintf(w, "Действие: %s, состояние %s\n", d.Action, change); err != nil {
+			return err
+		}
+	}
+
+	if d.Snapshot != nil {
+		note := ""
+		if d.SnapshotKept {
+			note = ", сохранён предыдущий снимок"
+		}
+		if _, err := fmt.Fprintf(w, "Снимок: %s (до блокировки: %s, %s%s)\n",
+			d.SnapshotPath, jobsState(d.Snapshot.Blocked), d.Snapshot.TakenAt.Format(time.RFC3339), note); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func jobsState(blocked bool) string {
+	if blocked {
+		return "ЗАБЛОКИРОВАНЫ"
+	}
+	return "РАЗРЕШЕНЫ"
+}
+
+// ScheduledJobsHandler обрабатывает команду nr-scheduled-jobs.
+type ScheduledJobsHandler struct {
+	// racClient — опциональный RAC клиент (nil в production, mock в тестах)
+	racClient rac.Client
+	// now — источник текущего времени (nil — time.Now)
+	now func() time.Time
+}
+
+// Name возвращает имя команды.
+func (h *ScheduledJobsHandler) Name() string {
+	return constants.ActNRScheduledJobs
+}
+
+// Description возвращает описание команды для вывода в help.
+func (h *ScheduledJobsHandler) Description() string {
+	return "Блокировка регламентных заданий со снимком и восстановлением исходного состояния"
+}
+
+// Execute выполняет команду nr-scheduled-jobs.
+func (h *ScheduledJobsHandler) Execute(ctx context.Context, cfg *config.Config) error {
+	start := time.Now()
+
+	traceID := tracing.TraceIDFromContext(ctx)
+	if traceID == "" {
+		traceID = tracing.GenerateTraceID()
+	}
+
+	format := os.Getenv("BR_OUTPUT_FORMAT")
+
+	// Plan-only не поддерживается; dry-run имеет приоритет над plan-only.
+	if !dryrun.IsDryRun() && dryrun.IsPlanOnly() {
+		return dryrun.WritePlanOnlyUnsupported(os.Stdout, constants.ActNRScheduledJobs)
+	}
+
+	log := slog.Default().With(slog.String("trace_id", traceID), slog.String("command", constants.ActNRScheduledJobs))
+
+	if cfg == nil || cfg.InfobaseName == "" {
+		log.Error("Не указано имя информационной базы")
+		return h.writeError(format, traceID, start,
+			"CONFIG.INFOBASE_MISSING",
+			"Не указано имя информационной базы (BR_INFOBASE_NAME)")
+	}
+
+	action := strings.ToLower(strings.TrimSpace(os.Getenv(constants.EnvScheduledJobsAction)))
+	if action == "" {
+		action = ActionStatus
+	}
+	switch action {
+	case ActionStatus, ActionBlock, ActionUnblock, ActionRestore:
+	default:
+		return h.writeError(format, traceID, start, "CONFIG.INVALID_ACTION",
+			fmt.Sprintf("Недопустимое значение %s: %q, допустимые: status, block, unblock, restore",
+				constants.EnvScheduledJobsAction, action))
+	}
+
+	// Снимок хранится только по явно заданному пути: block и restore обычно
+	// выполняются разными заданиями пайплайна, возможно на разных агентах,
+	// и локальный файл в TmpDir при restore не был бы найден.
+	snapshotPath := os.Getenv(constants.EnvScheduledJobsSnapshot)
+	if snapshotPath == "" && (action == ActionBlock || action == ActionRestore) {
+		return h.writeError(format, traceID, start, "CONFIG.SNAPSHOT_PATH_MISSING",
+			fmt.Sprintf("Не задан путь к снимку состояния: укажите в %s файл на общем для агентов ресурсе",
+				constants.EnvScheduledJobsSnapshot))
+	}
+
+	log = log.With(slog.String("infobase", cfg.InfobaseName), slog.String("action", action))
+	log.Info("Начало обработки команды управления регламентными заданиями")
+
+	racClient := h.racClient
+	if racClient == nil {
+		var err error
+		racClient, err = racutil.NewClient(cfg)
+		if err != nil {
+			log.Error("Не удалось создать RAC клиент", slog.String("error", err.Error()))
+			return h.writeError(format, traceID, start,
+				"RAC.CLIENT_CREATE_FAILED",
+				fmt.Sprintf("Не удалось создать RAC клиент: %v", err))
+		}
+	}
+	jobs, ok := racClient.(rac.ScheduledJobsController)
+	if !ok {
+		return h.writeError(format, traceID, start, "RAC.JOBS_UNSUPPORTED",
+			"RAC клиент не поддерживает управление регламентными заданиями")
+	}
+
+	clusterInfo, err := racClient.GetClusterInfo(ctx)
+	if err != nil {
+		log.Error("Не удалось получить информацию о кластере", slog.String("error", err.Error()))
+		return h.writeError(format, traceID, start,
+			"RAC.CLUSTER_FAILED",
+			fmt.Sprintf("Не удалось получить информацию о кластере: %v", err))
+	}
+
+	infobaseInfo, err := racClient.GetInfobaseInfo(ctx, clusterInfo.UUID, cfg.InfobaseName)
+	if err != nil {
+		log.Error("Не удалось получить информацию об информационной базе", slog.String("error", err.Error()))
+		return h.writeError(format, traceID, start,
+			"RAC.INFOBASE_FAILED",
+			fmt.Sprintf("Не удалось получить информацию об информационной базе: %v", err))
+	}
+
+	status, err := racClient.GetServiceModeStatus(ctx, clusterInfo.UUID, infobaseInfo.UUID)
+	if err != nil {
+		log.Error("Не удалось получить состояние регламентных заданий", slog.String("error", err.Error()))
+		return h.writeError(format, traceID, start,
+			"RAC.STATUS_FAILED",
+			fmt.Sprintf("Не удалось получить состояние регламентных заданий: %v", err))
+	}
+
+	snapshot, err := readSnapshot(snapshotPath)
+	if err != nil {
+		log.Error("Не удалось прочитать снимок", slog.String("path", snapshotPath), slog.String("error", err.Error()))
+		return h.writeError(format, traceID, start, "JOBS.SNAPSHOT_FAILED",
+			fmt.Sprintf("Не удалось прочитать снимок %s: %v", snapshotPath, err))
+	}
+	if snapshot != nil && snapshot.Infobase != cfg.InfobaseName {
+		return h.writeError(format, traceID, start, "JOBS.SNAPSHOT_MISMATCH",
+			fmt.Sprintf("Снимок %s относится к базе %s, а не %s", snapshotPath, snapshot.Infobase, cfg.InfobaseName))
+	}
+
+	data := &ScheduledJobsData{
+		Action:        action,
+		InfobaseName:  cfg.InfobaseName,
+		BlockedBefore: status.ScheduledJobsBlocked,
+		Blocked:       status.ScheduledJobsBlocked,
+		DryRun:        dryrun.IsDryRun(),
+		SnapshotPath:  snapshotPath,
+		Snapshot:      snapshot,
+	}
+
+	target := status.ScheduledJobsBlocked
+	switch action {
+	case ActionBlock:
+		// Повторный block не перезаписывает снимок: исходным остаётся
+		// состояние до первой блокировки пайплайном.
+		if snapshot != nil {
+			data.SnapshotKept = true
+		} else {
+			data.Snapshot = &Snapshot{Infobase: cfg.InfobaseName, Blocked: status.ScheduledJobsBlocked, TakenAt: h.clock()}
+			if !data.DryRun {
+				if err := writeSnapshot(snapshotPath, data.Snapshot); err != nil {
+					log.Error("Не удалось записать снимок", slog.String("path", snapshotPath), slog.String("error", err.Error()))
+					return h.writeError(format, traceID, start, "JOBS.SNAPSHOT_FAILED",
+						fmt.Sprintf("Не удалось записать снимок %s: %v", snapshotPath, err))
+				}
+			}
+		}
+		target = true
+	case ActionUnblock:
+		target = false
+	case ActionRestore:
+		if snapshot == nil {
+			return h.writeError(format, traceID, start, "JOBS.SNAPSHOT_MISSING",
+				fmt.Sprintf("Снимок %s не найден: восстанавливать нечего (block не выполнялся)", snapshotPath))
+		}
+		target = snapshot.Blocked
+	}
+
+	data.Blocked = target
+	if target != status.ScheduledJobsBlocked && !data.DryRun {
+		log.Info("Изменение блокировки регламентных заданий", slog.Bool("deny", target))
+		if err := jobs.SetScheduledJobsDeny(ctx, clusterInfo.UUID, infobaseInfo.UUID, target); err != nil {
+			log.Error("Не удалось изменить блокировку регламентных заданий", slog.String("error", err.Error()))
+			return h.writeError(format, traceID, start,
+				"RAC.JOBS_FAILED",
+				fmt.Sprintf("Не удалось изменить блокировку регламентных заданий: %v", err))
+		}
+		data.StateChanged = true
+	}
+
+	// Снимок удаляется только после успешного восстановления
+	if action == ActionRestore && !data.DryRun {
+		if err := os.Remove(snapshotPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			log.Warn("Не удалось удалить снимок", slog.String("path", snapshotPath), slog.String("error", err.Error()))
+		}
+	}
+
+	log.Info("Команда управления регламентными заданиями выполнена",
+		slog.Bool("blocked_before", data.BlockedBefore),
+		slog.Bool("blocked", data.Blocked),
+		slog.Bool("state_changed", data.StateChanged))
+
+	return h.writeSuccess(format, traceID, start, data)
+}
+
+func (h *ScheduledJobsHandler) clock() time.Time {
+	if h.now != nil {
+		return h.now()
+	}
+	return time.Now()
+}
+
+// readSnapshot читает снимок; отсутствующий файл — не ошибка (nil, nil).
+func readSnapshot(path string) (*Snapshot, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // путь задаётся конфигурацией
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// writeSnapshot атомарно записывает снимок (через временный файл).
+func writeSnapshot(path string, s *Snapshot) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// writeSuccess выводит успешный результат.
+func (h *ScheduledJobsHandler) writeSuccess(format, traceID string, start time.Time, data *ScheduledJobsData) error {
+	if format != output.FormatJSON {
+		return data.writeText(os.Stdout)
+	}
+
+	result := &output.Result{
+		Status:  output.StatusSuccess,
+		Command: constants.ActNRScheduledJobs,
+		Data:    data,
+		Metadata: &output.Metadata{
+			DurationMs: time.Since(start).Milliseconds(),
+			TraceID:    traceID,
+			APIVersion: constants.APIVersion,
+		},
+	}
+
+	writer := output.NewWriter(format)
+	return writer.Write(os.Stdout, result)
+}
+
+// writeError выводит структурированную ошибку и возвращает error.
+func (h *ScheduledJobsHandler) writeError(format, traceID string, start time.Time, code, message string) error {
+	// Текстовый формат — человекочитаемый вывод ошибки
+	if format != output.FormatJSON {
+		return errhandler.HandleError(message, code)
+	}
+
+	result := &output.Result{
+		Status:  output.StatusError,
+		Command: constants.ActNRScheduledJobs,
+		Error: &output.ErrorInfo{
+			Code:    code,
+			Message: message,
+		},
+		Metadata: &output.Metadata{
+			DurationMs: time.Since(start).Milliseconds(),
+			TraceID:    traceID,
+			APIVersion: constants.APIVersion,
+		},
+	}
+
+	writer := output.NewWriter(format)
+	if writeErr := writer.Write(os.Stdout, result); writeErr != nil {
+		slog.Default().Error("Не удалось записать JSON-ответ об ошибке",
+			slog.String("trace_id", traceID),
+			slog.String("error", writeErr.Error()))
+	}
+
+	return fmt.Errorf("%s: %s", code, message)
+}
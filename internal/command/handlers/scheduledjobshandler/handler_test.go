@@ -0,0 +1,246 @@
+package scheduledjobshandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac/ractest"
+	"github.com/Kargones/apk-ci/internal/command"
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/pkg/output"
+	"github.com/Kargones/apk-ci/internal/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var snapshotNow = time.Date(2026, 3, 14, 17, 0, 0, 0, time.Local)
+
+func TestScheduledJobsHandler_Registration(t *testing.T) {
+	h, ok := command.Get(constants.ActNRScheduledJobs)
+	require.True(t, ok)
+	assert.Equal(t, constants.ActNRScheduledJobs, h.Name())
+	assert.NotEmpty(t, h.Description())
+}
+
+// jobsMock — mock RAC, хранящий флаг блокировки регламентных заданий.
+type jobsMock struct {
+	*ractest.MockRACClient
+	blocked bool
+	calls   []bool
+}
+
+func newJobsMock(blocked bool) *jobsMock {
+	m := &jobsMock{MockRACClient: ractest.NewMockRACClient(), blocked: blocked}
+	m.GetServiceModeStatusFunc = func(context.Context, string, string) (*rac.ServiceModeStatus, error) {
+		return &rac.ServiceModeStatus{ScheduledJobsBlocked: m.blocked}, nil
+	}
+	m.SetScheduledJobsDenyFunc = func(_ context.Context, _, _ string, deny bool) error {
+		m.calls = append(m.calls, deny)
+		m.blocked = deny
+		return nil
+	}
+	return m
+}
+
+// jobsConfig возвращает конфигурацию базы erp и задаёт путь к снимку.
+func jobsConfig(t *testing.T) *config.Config {
+	t.Helper()
+	t.Setenv(constants.EnvScheduledJobsSnapshot, filepath.Join(t.TempDir(), "scheduled-jobs-erp.json"))
+	return &config.Config{InfobaseName: "erp", TmpDir: t.TempDir()}
+}
+
+func run(t *testing.T, mock rac.Client, cfg *config.Config, action string) (*ScheduledJobsData, string, error) {
+	t.Helper()
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+	t.Setenv(constants.EnvScheduledJobsAction, action)
+
+	h := &ScheduledJobsHandler{racClient: mock, now: func() time.Time { return snapshotNow }}
+	var execErr error
+	out := testutil.CaptureStdout(t, func() {
+		execErr = h.Execute(context.Background(), cfg)
+	})
+	if execErr != nil {
+		return nil, out, execErr
+	}
+
+	var result struct {
+		output.Result
+		Data ScheduledJobsData `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+	return &result.Data, out, nil
+}
+
+func TestScheduledJobsHandler_BlockRestore(t *testing.T) {
+	t.Setenv(constants.EnvDryRun, "")
+	cfg := jobsConfig(t)
+	mock := newJobsMock(false)
+
+	data, _, err := run(t, mock, cfg, ActionBlock)
+	require.NoError(t, err)
+	assert.True(t, data.StateChanged)
+	assert.False(t, data.BlockedBefore)
+	assert.True(t, data.Blocked)
+	require.NotNil(t, data.Snapshot)
+	assert.False(t, data.Snapshot.Blocked)
+	assert.True(t, snapshotNow.Equal(data.Snapshot.TakenAt))
+	assert.FileExists(t, os.Getenv(constants.EnvScheduledJobsSnapshot))
+	assert.Equal(t, os.Getenv(constants.EnvScheduledJobsSnapshot), data.SnapshotPath)
+
+	// Повторная блокировка не перезаписывает исходное состояние
+	data, _, err = run(t, mock, cfg, ActionBlock)
+	require.NoError(t, err)
+	assert.False(t, data.StateChanged)
+	assert.True(t, data.SnapshotKept)
+	assert.False(t, data.Snapshot.Blocked)
+
+	data, _, err = run(t, mock, cfg, ActionRestore)
+	require.NoError(t, err)
+	assert.True(t, data.StateChanged)
+	assert.False(t, data.Blocked)
+	assert.False(t, mock.blocked)
+	assert.Equal(t, []bool{true, false}, mock.calls)
+	assert.NoFileExists(t, data.SnapshotPath, "снимок удаляется после восстановления")
+}
+
+// TestScheduledJobsHandler_RestoreKeepsManualBlock — задания, заблокированные вручную
+// до пайплайна, остаются заблокированными после restore.
+func TestScheduledJobsHandler_RestoreKeepsManualBlock(t *testing.T) {
+	t.Setenv(constants.EnvDryRun, "")
+	cfg := jobsConfig(t)
+	mock := newJobsMock(true)
+
+	data, _, err := run(t, mock, cfg, ActionBlock)
+	require.NoError(t, err)
+	assert.False(t, data.StateChanged)
+	assert.True(t, data.Snapshot.Blocked)
+
+	data, _, err = run(t, mock, cfg, ActionRestore)
+	require.NoError(t, err)
+	assert.False(t, data.StateChanged)
+	assert.True(t, data.Blocked)
+	assert.Empty(t, mock.calls)
+}
+
+func TestScheduledJobsHandler_RestoreWithoutSnapshot(t *testing.T) {
+	t.Setenv(constants.EnvDryRun, "")
+	mock := newJobsMock(true)
+
+	_, _, err := run(t, mock, jobsConfig(t), ActionRestore)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "JOBS.SNAPSHOT_MISSING")
+	assert.Empty(t, mock.calls)
+}
+
+func TestScheduledJobsHandler_SnapshotMismatch(t *testing.T) {
+	t.Setenv(constants.EnvDryRun, "")
+	cfg := jobsConfig(t)
+	path := filepath.Join(t.TempDir(), "jobs.json")
+	require.NoError(t, writeSnapshot(path, &Snapshot{Infobase: "zup", Blocked: true}))
+	t.Setenv(constants.EnvScheduledJobsSnapshot, path)
+
+	_, _, err := run(t, newJobsMock(false), cfg, ActionRestore)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "JOBS.SNAPSHOT_MISMATCH")
+}
+
+func TestScheduledJobsHandler_Unblock(t *testing.T) {
+	t.Setenv(constants.EnvDryRun, "")
+	mock := newJobsMock(true)
+
+	data, _, err := run(t, mock, jobsConfig(t), ActionUnblock)
+	require.NoError(t, err)
+	assert.True(t, data.StateChanged)
+	assert.False(t, mock.blocked)
+}
+
+func TestScheduledJobsHandler_DryRun(t *testing.T) {
+	t.Setenv(constants.EnvDryRun, "true")
+	cfg := jobsConfig(t)
+	mock := newJobsMock(false)
+
+	data, _, err := run(t, mock, cfg, ActionBlock)
+	require.NoError(t, err)
+	assert.True(t, data.DryRun)
+	assert.False(t, data.StateChanged)
+	assert.True(t, data.Blocked)
+	assert.Empty(t, mock.calls)
+	assert.NoFileExists(t, data.SnapshotPath)
+}
+
+func TestScheduledJobsHandler_Status(t *testing.T) {
+	t.Setenv(constants.EnvDryRun, "")
+	mock := newJobsMock(true)
+
+	data, _, err := run(t, mock, &config.Config{InfobaseName: "erp"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, ActionStatus, data.Action)
+	assert.True(t, data.Blocked)
+	assert.Nil(t, data.Snapshot)
+	assert.Empty(t, mock.calls)
+}
+
+func TestScheduledJobsHandler_TextOutput(t *testing.T) {
+	t.Setenv(constants.EnvDryRun, "")
+	t.Setenv("BR_OUTPUT_FORMAT", "text")
+	t.Setenv(constants.EnvScheduledJobsAction, ActionBlock)
+
+	h := &ScheduledJobsHandler{racClient: newJobsMock(false), now: func() time.Time { return snapshotNow }}
+	out := testutil.CaptureStdout(t, func() {
+		require.NoError(t, h.Execute(context.Background(), jobsConfig(t)))
+	})
+	assert.Contains(t, out, "Регламентные задания: ЗАБЛОКИРОВАНЫ")
+	assert.Contains(t, out, "РАЗРЕШЕНЫ → ЗАБЛОКИРОВАНЫ")
+	assert.Contains(t, out, "до блокировки: РАЗРЕШЕНЫ")
+}
+
+func TestScheduledJobsHandler_Errors(t *testing.T) {
+	t.Setenv(constants.EnvDryRun, "")
+
+	_, _, err := run(t, newJobsMock(false), &config.Config{}, ActionBlock)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "CONFIG.INFOBASE_MISSING")
+
+	_, _, err = run(t, newJobsMock(false), jobsConfig(t), "pause")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "CONFIG.INVALID_ACTION")
+
+	// TmpDir не используется как место снимка: restore может выполняться на другом агенте
+	t.Setenv(constants.EnvScheduledJobsSnapshot, "")
+	_, _, err = run(t, newJobsMock(false), &config.Config{InfobaseName: "erp", TmpDir: t.TempDir()}, ActionBlock)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "CONFIG.SNAPSHOT_PATH_MISSING")
+
+	failing := newJobsMock(false)
+	failing.SetScheduledJobsDenyFunc = func(context.Context, string, string, bool) error {
+		return errors.New("access denied")
+	}
+	_, out, err := run(t, failing, jobsConfig(t), ActionBlock)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "RAC.JOBS_FAILED")
+	assert.Contains(t, out, "access denied")
+}
+
+func TestSnapshot_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "jobs.json")
+	s, err := readSnapshot(path)
+	require.NoError(t, err)
+	assert.Nil(t, s)
+
+	require.NoError(t, writeSnapshot(path, &Snapshot{Infobase: "erp", Blocked: true, TakenAt: snapshotNow}))
+	s, err = readSnapshot(path)
+	require.NoError(t, err)
+	assert.Equal(t, "erp", s.Infobase)
+	assert.True(t, s.Blocked)
+
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+	_, err = readSnapshot(path)
+	assert.Error(t, err)
+}
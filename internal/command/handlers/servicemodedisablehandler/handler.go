@@ -8,6 +8,7 @@ import (
 	"io"
 	"log/slog"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
@@ -38,6 +39,10 @@ type ServiceModeDisableData struct {
 	ScheduledJobsUnblocked bool `json:"scheduled_jobs_unblocked"`
 	// InfobaseName — имя информационной базы
 	InfobaseName string `json:"infobase_name"`
+	// ReleasedLeaseOwner — владелец снятой аренды (пусто — аренды не было)
+	ReleasedLeaseOwner string `json:"released_lease_owner,omitempty"`
+	// Forced — снята чужая действующая аренда (BR_SERVICE_MODE_FORCE)
+	Forced bool `json:"forced,omitempty"`
 }
 
 // writeText выводит результат отключения сервисного режима в человекочитаемом формате.
@@ -52,6 +57,16 @@ func (d *ServiceModeDisableData) writeText(w io.Writer) error {
 		return err
 	}
 
+	if d.ReleasedLeaseOwner != "" {
+		forced := ""
+		if d.Forced {
+			forced = " (принудительно)"
+		}
+		if _, err := fmt.Fprintf(w, "Аренда снята: %s%s\n", d.ReleasedLeaseOwner, forced); err != nil {
+			return err
+		}
+	}
+
 	if !d.AlreadyDisabled {
 		if d.ScheduledJobsUnblocked {
 			if _, err := fmt.Fprintln(w, "Регламентные задания: разблокированы"); err != nil {
@@ -170,6 +185,30 @@ func (h *ServiceModeDisableHandler) Execute(ctx context.Context, cfg *config.Con
 		return h.outputResult(format, data, traceID, start)
 	}
 
+	// Блокировку, удерживаемую действующей арендой другого запуска, снимает
+	// только её владелец или принудительное отключение (BR_SERVICE_MODE_FORCE)
+	var releasedLease string
+	var forced bool
+	if status != nil {
+		if lease, ok := rac.ParseLease(status.DeniedParameter); ok {
+			owner := racutil.LeaseOwner(cfg)
+			if lease.Owner != owner && !lease.Expired(time.Now()) {
+				if !isForced() {
+					log.Error("Сервисный режим удерживается арендой другого запуска",
+						slog.String("lease_owner", lease.Owner),
+						slog.String("lease_expires", lease.Expires.Format(time.RFC3339)))
+					return h.writeError(format, traceID, start,
+						"SERVICE_MODE.LEASE_HELD",
+						fmt.Sprintf("Сервисный режим удерживается арендой %s (trace_id %s) до %s; для отключения задайте %s=true",
+							lease.Owner, lease.TraceID, lease.Expires.Local().Format(time.RFC3339), constants.EnvServiceModeForce))
+				}
+				log.Warn("Принудительное снятие аренды другого запуска", slog.String("lease_owner", lease.Owner))
+				forced = true
+			}
+			releasedLease = lease.Owner
+		}
+	}
+
 	// Отключение сервисного режима
 	log.Info("Вызов DisableServiceMode")
 	err = racClient.DisableServiceMode(ctx, clusterInfo.UUID, infobaseInfo.UUID)
@@ -208,11 +247,19 @@ func (h *ServiceModeDisableHandler) Execute(ctx context.Context, cfg *config.Con
 		StateChanged:           true,
 		ScheduledJobsUnblocked: scheduledJobsUnblocked,
 		InfobaseName:           cfg.InfobaseName,
+		ReleasedLeaseOwner:     releasedLease,
+		Forced:                 forced,
 	}
 
 	return h.outputResult(format, data, traceID, start)
 }
 
+// isForced сообщает, разрешено ли снятие чужой аренды (BR_SERVICE_MODE_FORCE).
+func isForced() bool {
+	v, err := strconv.ParseBool(os.Getenv(constants.EnvServiceModeForce))
+	return err == nil && v
+}
+
 // outputResult форматирует и выводит результат.
 func (h *ServiceModeDisableHandler) outputResult(format string, data *ServiceModeDisableData, traceID string, start time.Time) error {
 	// Текстовый формат
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
 	"github.com/Kargones/apk-ci/internal/adapter/onec/rac/ractest"
@@ -489,3 +490,73 @@ func newDisableMock(scheduledJobsUnblocked bool) *ractest.MockRACClient {
 	}
 	return mock
 }
+
+// newLeaseMock создаёт mock с включённым сервисным режимом под арендой lease.
+func newLeaseMock(lease *rac.Lease, disabled *atomic.Bool) *ractest.MockRACClient {
+	mock := ractest.NewMockRACClient()
+	mock.GetServiceModeStatusFunc = func(_ context.Context, _, _ string) (*rac.ServiceModeStatus, error) {
+		if disabled.Load() {
+			return &rac.ServiceModeStatus{}, nil
+		}
+		return &rac.ServiceModeStatus{Enabled: true, ScheduledJobsBlocked: true, DeniedParameter: lease.String()}, nil
+	}
+	mock.DisableServiceModeFunc = func(_ context.Context, _, _ string) error {
+		disabled.Store(true)
+		return nil
+	}
+	return mock
+}
+
+func TestServiceModeDisableHandler_Execute_Lease(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+	t.Setenv(constants.EnvServiceModeLeaseOwner, "erp/main#2")
+	t.Setenv(constants.EnvServiceModeForce, "")
+	cfg := &config.Config{InfobaseName: "TestBase"}
+
+	tests := []struct {
+		name      string
+		lease     rac.Lease
+		force     string
+		wantErr   bool
+		wantForce bool
+	}{
+		{"own_lease", rac.Lease{Owner: "erp/main#2", Expires: time.Now().Add(time.Hour)}, "", false, false},
+		{"expired_foreign_lease", rac.Lease{Owner: "erp/main#1", Expires: time.Now().Add(-time.Hour)}, "", false, false},
+		{"active_foreign_lease", rac.Lease{Owner: "erp/main#1", TraceID: "abc", Expires: time.Now().Add(time.Hour)}, "", true, false},
+		{"active_foreign_lease_forced", rac.Lease{Owner: "erp/main#1", Expires: time.Now().Add(time.Hour)}, "true", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(constants.EnvServiceModeForce, tt.force)
+			var disabled atomic.Bool
+			h := &ServiceModeDisableHandler{racClient: newLeaseMock(&tt.lease, &disabled)}
+
+			var execErr error
+			out := testutil.CaptureStdout(t, func() {
+				execErr = h.Execute(context.Background(), cfg)
+			})
+
+			if tt.wantErr {
+				require.Error(t, execErr)
+				assert.Contains(t, execErr.Error(), "SERVICE_MODE.LEASE_HELD")
+				assert.Contains(t, out, "erp/main#1")
+				assert.False(t, disabled.Load(), "чужая аренда не снимается")
+				return
+			}
+			require.NoError(t, execErr)
+			assert.True(t, disabled.Load())
+
+			var result output.Result
+			require.NoError(t, json.Unmarshal([]byte(out), &result))
+			dataMap, ok := result.Data.(map[string]any)
+			require.True(t, ok)
+			assert.Equal(t, tt.lease.Owner, dataMap["released_lease_owner"])
+			if tt.wantForce {
+				assert.Equal(t, true, dataMap["forced"])
+			} else {
+				assert.Nil(t, dataMap["forced"])
+			}
+		})
+	}
+}
@@ -44,12 +44,27 @@ type ServiceModeEnableData struct {
 	TerminatedSessionsCount int `json:"terminated_sessions_count"`
 	// InfobaseName — имя информационной базы
 	InfobaseName string `json:"infobase_name"`
+	// Scheduled — сервисный режим запланирован на будущее (окно ещё не началось)
+	Scheduled bool `json:"scheduled"`
+	// DeniedFrom — начало окна блокировки сеансов (RFC3339)
+	DeniedFrom string `json:"denied_from,omitempty"`
+	// DeniedTo — окончание окна блокировки сеансов (RFC3339)
+	DeniedTo string `json:"denied_to,omitempty"`
+	// LeaseOwner — владелец аренды сервисного режима (пусто — аренды нет)
+	LeaseOwner string `json:"lease_owner,omitempty"`
+	// LeaseExpires — окончание аренды (RFC3339)
+	LeaseExpires string `json:"lease_expires,omitempty"`
 }
 
 // writeText выводит результат включения сервисного режима в человекочитаемом формате.
 func (d *ServiceModeEnableData) writeText(w io.Writer) error {
 	enabledText := "ВКЛЮЧЁН"
-	if d.AlreadyEnabled {
+	switch {
+	case d.Scheduled && d.AlreadyEnabled:
+		enabledText = "ЗАПЛАНИРОВАН (уже был запланирован)"
+	case d.Scheduled:
+		enabledText = "ЗАПЛАНИРОВАН"
+	case d.AlreadyEnabled:
 		enabledText = "ВКЛЮЧЁН (уже был включён)"
 	}
 
@@ -59,17 +74,119 @@ func (d *ServiceModeEnableData) writeText(w io.Writer) error {
 		return err
 	}
 
+	if d.DeniedFrom != "" || d.DeniedTo != "" {
+		if _, err = fmt.Fprintf(w, "Окно: %s\n", formatWindow(d.DeniedFrom, d.DeniedTo)); err != nil {
+			return err
+		}
+	}
+
+	if d.LeaseOwner != "" {
+		if _, err = fmt.Fprintf(w, "Аренда: %s до %s\n", d.LeaseOwner, d.LeaseExpires); err != nil {
+			return err
+		}
+	}
+
 	if !d.AlreadyEnabled {
-		if _, err = fmt.Fprintln(w, "Регламентные задания: заблокированы"); err != nil {
+		jobsText := "заблокированы"
+		if !d.ScheduledJobsBlocked {
+			jobsText = "будут заблокированы nr-service-mode-reaper после начала окна"
+		}
+		if _, err = fmt.Fprintln(w, "Регламентные задания: "+jobsText); err != nil {
 			return err
 		}
-		if d.TerminatedSessionsCount > 0 {
+		if !d.Scheduled && d.TerminatedSessionsCount > 0 {
 			_, err = fmt.Fprintf(w, "Завершено сессий: %d\n", d.TerminatedSessionsCount)
 		}
 	}
 	return err
 }
 
+// formatWindow возвращает окно сервисного режима для текстового вывода.
+func formatWindow(from, to string) string {
+	if from == "" {
+		from = "сейчас"
+	}
+	if to == "" {
+		to = "до отключения"
+	}
+	return from + " — " + to
+}
+
+// windowLayouts — допустимые форматы BR_SERVICE_MODE_FROM/BR_SERVICE_MODE_TO.
+// Значения без часового пояса интерпретируются в локальном времени (как и в RAC).
+var windowLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+// parseWindowTime разбирает время начала или окончания окна.
+func parseWindowTime(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	for _, layout := range windowLayouts {
+		if t, err := time.ParseInLocation(layout, v, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("недопустимое время %q, ожидается RFC3339 или ГГГГ-ММ-ДД ЧЧ:ММ[:СС]", v)
+}
+
+// parseWindow разбирает окно сервисного режима из значений from и to.
+// to может быть длительностью (например, 2h30m), отсчитываемой от начала окна.
+// Пустые значения: from — немедленно, to — до явного отключения.
+func parseWindow(from, to string, now time.Time) (rac.ServiceModeWindow, error) {
+	var window rac.ServiceModeWindow
+	var err error
+	if from != "" {
+		if window.From, err = parseWindowTime(from); err != nil {
+			return window, fmt.Errorf("%s: %w", constants.EnvServiceModeFrom, err)
+		}
+	}
+	if to == "" {
+		return window, nil
+	}
+
+	begin := now
+	if !window.From.IsZero() {
+		begin = window.From
+	}
+	if d, durErr := time.ParseDuration(to); durErr == nil {
+		window.To = begin.Add(d)
+	} else if window.To, err = parseWindowTime(to); err != nil {
+		return window, fmt.Errorf("%s: %w", constants.EnvServiceModeTo, err)
+	}
+
+	if !window.To.After(begin) {
+		return window, fmt.Errorf("окончание окна %s должно быть позже начала %s",
+			window.To.Format(time.RFC3339), begin.Format(time.RFC3339))
+	}
+	if !window.To.After(now) {
+		return window, fmt.Errorf("окно сервисного режима уже завершилось (%s)", window.To.Format(time.RFC3339))
+	}
+	return window, nil
+}
+
+// sameWindow сообщает, установлено ли в status запрошенное окно window.
+func sameWindow(status *rac.ServiceModeStatus, window rac.ServiceModeWindow, now time.Time) bool {
+	if !status.Enabled || !status.DeniedTo.Equal(window.To) {
+		return false
+	}
+	if window.From.IsZero() {
+		return status.Active(now)
+	}
+	return status.DeniedFrom.Equal(window.From)
+}
+
+// formatTime возвращает время в RFC3339 или пустую строку для нулевого времени.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
 // ServiceModeEnableHandler обрабатывает команду nr-service-mode-enable.
 type ServiceModeEnableHandler struct {
 	// racClient — опциональный RAC клиент (nil в production, mock в тестах)
@@ -132,10 +249,41 @@ func (h *ServiceModeEnableHandler) Execute(ctx context.Context, cfg *config.Conf
 
 	terminateSessions := cfg.TerminateSessions
 
+	// Окно сервисного режима (BR_SERVICE_MODE_FROM/BR_SERVICE_MODE_TO)
+	window, err := parseWindow(os.Getenv(constants.EnvServiceModeFrom), os.Getenv(constants.EnvServiceModeTo), start)
+	if err != nil {
+		log.Error("Некорректное окно сервисного режима", slog.String("error", err.Error()))
+		return h.writeError(format, traceID, start,
+			"CONFIG.SERVICE_MODE_WINDOW_INVALID",
+			fmt.Sprintf("Некорректное окно сервисного режима: %v", err))
+	}
+	windowed := !window.From.IsZero() || !window.To.IsZero()
+	scheduled := window.From.After(start)
+
+	// Аренда сервисного режима: освобождается nr-service-mode-reaper после истечения,
+	// если пайплайн упал до nr-service-mode-disable. Аренда не истекает раньше
+	// окончания окна: иначе reaper снял бы блокировку посреди объявленного окна.
+	leaseTTL, err := racutil.LeaseTTL()
+	if err != nil {
+		log.Error("Некорректный срок аренды", slog.String("error", err.Error()))
+		return h.writeError(format, traceID, start, "CONFIG.INVALID_LEASE_TTL", err.Error())
+	}
+	var lease *rac.Lease
+	if leaseTTL > 0 {
+		begin := start
+		if scheduled {
+			begin = window.From
+		}
+		expires := begin.Add(leaseTTL)
+		if window.To.After(expires) {
+			expires = window.To
+		}
+		lease = &rac.Lease{Owner: racutil.LeaseOwner(cfg), TraceID: traceID, Expires: expires}
+	}
+
 	// Получение или создание RAC клиента
 	racClient := h.racClient
 	if racClient == nil {
-		var err error
 		racClient, err = racutil.NewClient(cfg)
 		if err != nil {
 			log.Error("Не удалось создать RAC клиент", slog.String("error", err.Error()))
@@ -178,7 +326,11 @@ func (h *ServiceModeEnableHandler) Execute(ctx context.Context, cfg *config.Conf
 			slog.Int("active_sessions", status.ActiveSessions))
 	}
 
-	if status != nil && status.Enabled {
+	alreadyEnabled := status != nil && status.Active(start)
+	if windowed {
+		alreadyEnabled = status != nil && sameWindow(status, window, start)
+	}
+	if alreadyEnabled {
 		log.Info("Сервисный режим уже включён", slog.String("infobase", cfg.InfobaseName))
 		data := &ServiceModeEnableData{
 			Enabled:              true,
@@ -188,10 +340,45 @@ func (h *ServiceModeEnableHandler) Execute(ctx context.Context, cfg *config.Conf
 			PermissionCode:       permissionCode,
 			ScheduledJobsBlocked: status.ScheduledJobsBlocked,
 			InfobaseName:         cfg.InfobaseName,
+			Scheduled:            status.Scheduled(start),
+			DeniedFrom:           formatTime(status.DeniedFrom),
+			DeniedTo:             formatTime(status.DeniedTo),
+		}
+		// Аренду продлевает только её владелец; блокировку, установленную
+		// вручную или другим пайплайном, не присваиваем
+		if held, ok := rac.ParseLease(status.DeniedParameter); ok {
+			if lease != nil && held.Owner == lease.Owner {
+				h.writeLease(ctx, log, racClient, clusterInfo.UUID, infobaseInfo.UUID, lease, data)
+			} else {
+				data.LeaseOwner = held.Owner
+				data.LeaseExpires = formatTime(held.Expires)
+			}
 		}
 		return h.outputResult(format, data, traceID, start)
 	}
 
+	if windowed {
+		data := &ServiceModeEnableData{
+			Enabled:        true,
+			StateChanged:   true,
+			Message:        message,
+			PermissionCode: permissionCode,
+			InfobaseName:   cfg.InfobaseName,
+			Scheduled:      scheduled,
+		}
+		if code, err := h.applyWindow(ctx, log, racClient, clusterInfo.UUID, infobaseInfo.UUID, window, status, terminateSessions, data); err != nil {
+			log.Error("Не удалось установить окно сервисного режима", slog.String("error", err.Error()))
+			return h.writeError(format, traceID, start, code, err.Error())
+		}
+		log.Info("Окно сервисного режима установлено",
+			slog.Bool("scheduled", data.Scheduled),
+			slog.String("denied_from", data.DeniedFrom),
+			slog.String("denied_to", data.DeniedTo),
+			slog.Int("terminated_sessions_count", data.TerminatedSessionsCount))
+		h.writeLease(ctx, log, racClient, clusterInfo.UUID, infobaseInfo.UUID, lease, data)
+		return h.outputResult(format, data, traceID, start)
+	}
+
 	// Подсчёт сессий для отчёта (до включения).
 	// Значение приблизительное — берётся из GetServiceModeStatus до вызова EnableServiceMode.
 	var sessionsCount int
@@ -248,10 +435,92 @@ func (h *ServiceModeEnableHandler) Execute(ctx context.Context, cfg *config.Conf
 		TerminatedSessionsCount: sessionsCount,
 		InfobaseName:            cfg.InfobaseName,
 	}
+	h.writeLease(ctx, log, racClient, clusterInfo.UUID, infobaseInfo.UUID, lease, data)
 
 	return h.outputResult(format, data, traceID, start)
 }
 
+// writeLease записывает аренду lease в параметр блокировки и заполняет data.
+// Ошибка записи не откатывает сервисный режим: блокировка остаётся без аренды,
+// как при ручном включении, и снимается только nr-service-mode-disable.
+func (h *ServiceModeEnableHandler) writeLease(ctx context.Context, log *slog.Logger, racClient rac.Client,
+	clusterUUID, infobaseUUID string, lease *rac.Lease, data *ServiceModeEnableData) {
+	if lease == nil {
+		return
+	}
+	leaser, ok := racClient.(rac.ServiceModeLeaser)
+	if !ok {
+		log.Warn("RAC клиент не поддерживает аренду сервисного режима")
+		return
+	}
+	if err := leaser.SetServiceModeLease(ctx, clusterUUID, infobaseUUID, lease.String()); err != nil {
+		log.Warn("Не удалось записать аренду сервисного режима", slog.String("error", err.Error()))
+		return
+	}
+	log.Info("Аренда сервисного режима записана",
+		slog.String("owner", lease.Owner),
+		slog.String("expires", formatTime(lease.Expires)))
+	data.LeaseOwner = lease.Owner
+	data.LeaseExpires = formatTime(lease.Expires)
+}
+
+// applyWindow устанавливает окно сервисного режима через rac.ServiceModeScheduler
+// и заполняет data. Для запланированного окна (data.Scheduled) сессии не завершаются —
+// RAC запретит новые сеансы с наступлением DeniedFrom; верификация сравнивает установленное окно.
+// При ошибке возвращает код ошибки для writeError.
+func (h *ServiceModeEnableHandler) applyWindow(ctx context.Context, log *slog.Logger, racClient rac.Client,
+	clusterUUID, infobaseUUID string, window rac.ServiceModeWindow, status *rac.ServiceModeStatus,
+	terminateSessions bool, data *ServiceModeEnableData) (string, error) {
+	scheduler, ok := racClient.(rac.ServiceModeScheduler)
+	if !ok {
+		return "RAC.SCHEDULE_UNSUPPORTED", fmt.Errorf("RAC клиент не поддерживает окно сервисного режима (denied-from/denied-to)")
+	}
+
+	log.Info("Вызов ScheduleServiceMode",
+		slog.String("from", formatTime(window.From)),
+		slog.String("to", formatTime(window.To)),
+		slog.Bool("scheduled", data.Scheduled))
+	if err := scheduler.ScheduleServiceMode(ctx, clusterUUID, infobaseUUID, window); err != nil {
+		return "RAC.ENABLE_FAILED", fmt.Errorf("не удалось включить сервисный режим: %w", err)
+	}
+
+	if !data.Scheduled && terminateSessions {
+		if status != nil {
+			data.TerminatedSessionsCount = status.ActiveSessions
+		}
+		// Ошибка завершения сессий не откатывает сервисный режим (как в EnableServiceMode)
+		if err := racClient.TerminateAllSessions(ctx, clusterUUID, infobaseUUID); err != nil {
+			log.Warn("Ошибка завершения сессий после включения сервисного режима", slog.String("error", err.Error()))
+		}
+	}
+
+	if !data.Scheduled {
+		if err := racClient.VerifyServiceMode(ctx, clusterUUID, infobaseUUID, true); err != nil {
+			return "RAC.VERIFY_FAILED", fmt.Errorf("верификация сервисного режима не прошла: %w", err)
+		}
+	}
+
+	data.ScheduledJobsBlocked = !data.Scheduled
+	data.DeniedFrom = formatTime(window.From)
+	data.DeniedTo = formatTime(window.To)
+	postStatus, err := racClient.GetServiceModeStatus(ctx, clusterUUID, infobaseUUID)
+	switch {
+	case err != nil && data.Scheduled:
+		return "RAC.VERIFY_FAILED", fmt.Errorf("верификация сервисного режима не прошла: %w", err)
+	case err != nil:
+		log.Warn("Не удалось проверить статус регламентных заданий после включения",
+			slog.String("error", err.Error()))
+	case data.Scheduled && !sameWindow(postStatus, window, time.Now()):
+		return "RAC.VERIFY_FAILED", fmt.Errorf("верификация сервисного режима не прошла: установлено окно %s, ожидалось %s",
+			formatWindow(formatTime(postStatus.DeniedFrom), formatTime(postStatus.DeniedTo)),
+			formatWindow(data.DeniedFrom, data.DeniedTo))
+	default:
+		data.ScheduledJobsBlocked = postStatus.ScheduledJobsBlocked
+		data.DeniedFrom = formatTime(postStatus.DeniedFrom)
+	}
+	return "", nil
+}
+
 // outputResult форматирует и выводит результат.
 func (h *ServiceModeEnableHandler) outputResult(format string, data *ServiceModeEnableData, traceID string, start time.Time) error {
 	// Текстовый формат
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
 	"github.com/Kargones/apk-ci/internal/adapter/onec/rac/ractest"
@@ -481,3 +482,297 @@ func newEnableMock(serviceEnabled bool, activeSessions int) *ractest.MockRACClie
 	}
 	return mock
 }
+
+// newScheduleMock создаёт mock, который сохраняет окно ScheduleServiceMode
+// и возвращает его в последующих GetServiceModeStatus.
+func newScheduleMock(terminated *atomic.Int32) *ractest.MockRACClient {
+	mock := ractest.NewMockRACClient()
+	var window atomic.Pointer[rac.ServiceModeWindow]
+	mock.GetServiceModeStatusFunc = func(_ context.Context, _, _ string) (*rac.ServiceModeStatus, error) {
+		w := window.Load()
+		if w == nil {
+			return &rac.ServiceModeStatus{ActiveSessions: 2}, nil
+		}
+		return &rac.ServiceModeStatus{
+			Enabled:              true,
+			Message:              "Система находится в режиме обслуживания",
+			ScheduledJobsBlocked: !w.From.After(time.Now()),
+			DeniedFrom:           w.From,
+			DeniedTo:             w.To,
+		}, nil
+	}
+	mock.ScheduleServiceModeFunc = func(_ context.Context, _, _ string, w rac.ServiceModeWindow) error {
+		if w.From.IsZero() {
+			w.From = time.Now().Truncate(time.Second)
+		}
+		window.Store(&w)
+		return nil
+	}
+	mock.TerminateAllSessionsFunc = func(_ context.Context, _, _ string) error {
+		terminated.Add(1)
+		return nil
+	}
+	mock.VerifyServiceModeFunc = func(_ context.Context, _, _ string, _ bool) error {
+		return nil
+	}
+	return mock
+}
+
+func TestParseWindow(t *testing.T) {
+	now := time.Date(2026, 3, 14, 12, 0, 0, 0, time.Local)
+	from := time.Date(2026, 3, 14, 22, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name     string
+		from, to string
+		want     rac.ServiceModeWindow
+		wantErr  bool
+	}{
+		{name: "без окна"},
+		{name: "начало и длительность", from: "2026-03-14 22:00", to: "4h",
+			want: rac.ServiceModeWindow{From: from, To: from.Add(4 * time.Hour)}},
+		{name: "начало и окончание", from: "2026-03-14T22:00:00", to: "2026-03-15 02:00:00",
+			want: rac.ServiceModeWindow{From: from, To: from.Add(4 * time.Hour)}},
+		{name: "только длительность", to: "30m", want: rac.ServiceModeWindow{To: now.Add(30 * time.Minute)}},
+		{name: "RFC3339", from: from.Format(time.RFC3339), want: rac.ServiceModeWindow{From: from}},
+		{name: "окончание раньше начала", from: "2026-03-14 22:00", to: "2026-03-14 21:00", wantErr: true},
+		{name: "окно завершилось", from: "2026-03-14 08:00", to: "2026-03-14 10:00", wantErr: true},
+		{name: "неверный формат", from: "завтра", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseWindow(tt.from, tt.to, now)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tt.want.From.Equal(got.From), "From = %v", got.From)
+			assert.True(t, tt.want.To.Equal(got.To), "To = %v", got.To)
+		})
+	}
+}
+
+func TestServiceModeEnableHandler_Execute_ScheduledWindow(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+	from := time.Now().Add(2 * time.Hour).Truncate(time.Minute)
+	t.Setenv(constants.EnvServiceModeFrom, from.Format("2006-01-02 15:04"))
+	t.Setenv(constants.EnvServiceModeTo, "3h")
+
+	var terminated atomic.Int32
+	h := &ServiceModeEnableHandler{racClient: newScheduleMock(&terminated)}
+	cfg := &config.Config{InfobaseName: "TestBase", TerminateSessions: true}
+
+	var execErr error
+	out := testutil.CaptureStdout(t, func() {
+		execErr = h.Execute(context.Background(), cfg)
+	})
+	require.NoError(t, execErr)
+
+	var result output.Result
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+	dataMap, ok := result.Data.(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, true, dataMap["scheduled"])
+	assert.Equal(t, from.Format(time.RFC3339), dataMap["denied_from"])
+	assert.Equal(t, from.Add(3*time.Hour).Format(time.RFC3339), dataMap["denied_to"])
+	assert.Equal(t, false, dataMap["scheduled_jobs_blocked"], "задания будущего окна не блокируются заранее")
+	assert.Equal(t, float64(0), dataMap["terminated_sessions_count"])
+	assert.Equal(t, int32(0), terminated.Load(), "сессии не завершаются до начала окна")
+}
+
+func TestServiceModeEnableHandler_Execute_ScheduledWindow_Repeat(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "text")
+	from := time.Now().Add(2 * time.Hour).Truncate(time.Minute)
+	t.Setenv(constants.EnvServiceModeFrom, from.Format("2006-01-02 15:04"))
+	t.Setenv(constants.EnvServiceModeTo, "1h")
+
+	var terminated atomic.Int32
+	h := &ServiceModeEnableHandler{racClient: newScheduleMock(&terminated)}
+	cfg := &config.Config{InfobaseName: "TestBase"}
+
+	out := testutil.CaptureStdout(t, func() {
+		require.NoError(t, h.Execute(context.Background(), cfg))
+	})
+	assert.Contains(t, out, "Сервисный режим: ЗАПЛАНИРОВАН\n")
+	assert.Contains(t, out, "Окно: "+from.Format(time.RFC3339))
+	assert.Contains(t, out, "Регламентные задания: будут заблокированы nr-service-mode-reaper после начала окна")
+
+	out = testutil.CaptureStdout(t, func() {
+		require.NoError(t, h.Execute(context.Background(), cfg))
+	})
+	assert.Contains(t, out, "ЗАПЛАНИРОВАН (уже был запланирован)")
+}
+
+func TestServiceModeEnableHandler_Execute_ActiveWindow(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+	t.Setenv(constants.EnvServiceModeTo, "90m")
+
+	var terminated atomic.Int32
+	h := &ServiceModeEnableHandler{racClient: newScheduleMock(&terminated)}
+	cfg := &config.Config{InfobaseName: "TestBase", TerminateSessions: true}
+
+	var execErr error
+	out := testutil.CaptureStdout(t, func() {
+		execErr = h.Execute(context.Background(), cfg)
+	})
+	require.NoError(t, execErr)
+
+	var result output.Result
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+	dataMap, ok := result.Data.(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, false, dataMap["scheduled"])
+	assert.Equal(t, true, dataMap["scheduled_jobs_blocked"])
+	assert.NotEmpty(t, dataMap["denied_to"])
+	assert.Equal(t, float64(2), dataMap["terminated_sessions_count"])
+	assert.Equal(t, int32(1), terminated.Load())
+}
+
+func TestServiceModeEnableHandler_Execute_InvalidWindow(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+	t.Setenv(constants.EnvServiceModeFrom, "2026-13-45 25:00")
+
+	h := &ServiceModeEnableHandler{racClient: ractest.NewMockRACClient()}
+	cfg := &config.Config{InfobaseName: "TestBase"}
+
+	var execErr error
+	out := testutil.CaptureStdout(t, func() {
+		execErr = h.Execute(context.Background(), cfg)
+	})
+	require.Error(t, execErr)
+
+	var result output.Result
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+	require.NotNil(t, result.Error)
+	assert.Equal(t, "CONFIG.SERVICE_MODE_WINDOW_INVALID", result.Error.Code)
+	assert.Contains(t, result.Error.Message, constants.EnvServiceModeFrom)
+}
+
+func TestServiceModeEnableHandler_Execute_WritesLease(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+	t.Setenv(constants.EnvServiceModeLeaseOwner, "erp/main#2")
+	t.Setenv(constants.EnvServiceModeLeaseTTL, "2h")
+
+	mockClient := newEnableMock(false, 0)
+	var parameter string
+	mockClient.SetServiceModeLeaseFunc = func(_ context.Context, _, _, p string) error {
+		parameter = p
+		return nil
+	}
+	h := &ServiceModeEnableHandler{racClient: mockClient}
+
+	before := time.Now()
+	var execErr error
+	out := testutil.CaptureStdout(t, func() {
+		execErr = h.Execute(context.Background(), &config.Config{InfobaseName: "TestBase"})
+	})
+	require.NoError(t, execErr)
+
+	lease, ok := rac.ParseLease(parameter)
+	require.True(t, ok, "аренда записана в параметр блокировки")
+	assert.Equal(t, "erp/main#2", lease.Owner)
+	assert.NotEmpty(t, lease.TraceID)
+	assert.WithinDuration(t, before.Add(2*time.Hour), lease.Expires, time.Minute)
+
+	var result output.Result
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+	dataMap, ok := result.Data.(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, "erp/main#2", dataMap["lease_owner"])
+	assert.NotEmpty(t, dataMap["lease_expires"])
+}
+
+func TestServiceModeEnableHandler_Execute_LeaseCoversWindow(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+	t.Setenv(constants.EnvServiceModeLeaseTTL, "")
+	t.Setenv(constants.EnvServiceModeTo, "8h")
+
+	mockClient := newEnableMock(false, 0)
+	var parameter string
+	mockClient.SetServiceModeLeaseFunc = func(_ context.Context, _, _, p string) error {
+		parameter = p
+		return nil
+	}
+	h := &ServiceModeEnableHandler{racClient: mockClient}
+
+	before := time.Now()
+	testutil.CaptureStdout(t, func() {
+		require.NoError(t, h.Execute(context.Background(), &config.Config{InfobaseName: "TestBase"}))
+	})
+
+	lease, ok := rac.ParseLease(parameter)
+	require.True(t, ok)
+	assert.WithinDuration(t, before.Add(8*time.Hour), lease.Expires, time.Minute,
+		"аренда по умолчанию (%s) продлевается до окончания окна", racutil.DefaultLeaseTTL)
+}
+
+func TestServiceModeEnableHandler_Execute_LeaseDisabled(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+	t.Setenv(constants.EnvServiceModeLeaseTTL, "0")
+
+	mockClient := newEnableMock(false, 0)
+	mockClient.SetServiceModeLeaseFunc = func(context.Context, string, string, string) error {
+		t.Fatal("аренда не должна записываться при TTL 0")
+		return nil
+	}
+	h := &ServiceModeEnableHandler{racClient: mockClient}
+	testutil.CaptureStdout(t, func() {
+		require.NoError(t, h.Execute(context.Background(), &config.Config{InfobaseName: "TestBase"}))
+	})
+}
+
+func TestServiceModeEnableHandler_Execute_AlreadyEnabled_Lease(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+	t.Setenv(constants.EnvServiceModeLeaseOwner, "erp/main#2")
+	t.Setenv(constants.EnvServiceModeLeaseTTL, "")
+
+	foreign := &rac.Lease{Owner: "erp/main#1", Expires: time.Now().Add(time.Hour)}
+	for _, tt := range []struct {
+		name      string
+		owner     string
+		wantWrite bool
+	}{
+		{"own_lease_renewed", "erp/main#2", true},
+		{"foreign_lease_kept", foreign.Owner, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			held := &rac.Lease{Owner: tt.owner, Expires: time.Now().Add(time.Hour)}
+			mockClient := ractest.NewMockRACClient()
+			mockClient.GetServiceModeStatusFunc = func(context.Context, string, string) (*rac.ServiceModeStatus, error) {
+				return &rac.ServiceModeStatus{Enabled: true, DeniedParameter: held.String()}, nil
+			}
+			written := false
+			mockClient.SetServiceModeLeaseFunc = func(context.Context, string, string, string) error {
+				written = true
+				return nil
+			}
+			h := &ServiceModeEnableHandler{racClient: mockClient}
+
+			out := testutil.CaptureStdout(t, func() {
+				require.NoError(t, h.Execute(context.Background(), &config.Config{InfobaseName: "TestBase"}))
+			})
+			assert.Equal(t, tt.wantWrite, written)
+
+			var result output.Result
+			require.NoError(t, json.Unmarshal([]byte(out), &result))
+			dataMap, ok := result.Data.(map[string]any)
+			require.True(t, ok)
+			assert.Equal(t, true, dataMap["already_enabled"])
+			assert.Equal(t, tt.owner, dataMap["lease_owner"])
+		})
+	}
+}
+
+func TestServiceModeEnableHandler_Execute_InvalidLeaseTTL(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+	t.Setenv(constants.EnvServiceModeLeaseTTL, "forever")
+
+	h := &ServiceModeEnableHandler{racClient: newEnableMock(false, 0)}
+	var execErr error
+	testutil.CaptureStdout(t, func() {
+		execErr = h.Execute(context.Background(), &config.Config{InfobaseName: "TestBase"})
+	})
+	require.Error(t, execErr)
+	assert.Contains(t, execErr.Error(), "CONFIG.INVALID_LEASE_TTL")
+}
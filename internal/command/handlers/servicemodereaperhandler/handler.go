@@ -0,0 +1,418 @@
+// Package servicemodereaperhandler реализует NR-команду nr-service-mode-reaper
+// для отключения сервисного режима, аренда которого истекла: пайплайн включил
+// режим (nr-service-mode-enable) и завершился аварийно до nr-service-mode-disable.
+//
+// Команда также сопровождает окна сервисного режима (denied-from/denied-to),
+// у которых RAC не может запланировать блокировку регламентных заданий:
+// после начала окна задания блокируются, после окончания окна режим отключается.
+package servicemodereaperhandler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
+	"github.com/Kargones/apk-ci/internal/command"
+	"github.com/Kargones/apk-ci/internal/command/handlers/racutil"
+	errhandler "github.com/Kargones/apk-ci/internal/command/handlers/shared"
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/pkg/dryrun"
+	"github.com/Kargones/apk-ci/internal/pkg/output"
+	"github.com/Kargones/apk-ci/internal/pkg/tracing"
+)
+
+// Compile-time interface check.
+var _ command.Handler = (*ServiceModeReaperHandler)(nil)
+
+func RegisterCmd() error {
+	return command.Register(&ServiceModeReaperHandler{})
+}
+
+// LeaseInfo — аренда сервисного режима информационной базы.
+type LeaseInfo struct {
+	Infobase string `json:"infobase"`
+	Server   string `json:"server"`
+	Owner    string `json:"owner"`
+	TraceID  string `json:"trace_id,omitempty"`
+	// Expires — окончание аренды (RFC3339)
+	Expires string `json:"expires"`
+	// Reason — причина отключения: ReasonLeaseExpired или ReasonWindowEnded
+	Reason string `json:"reason,omitempty"`
+	// Released — сервисный режим отключён (false в dry-run)
+	Released bool `json:"released,omitempty"`
+}
+
+// Причины отключения сервисного режима (LeaseInfo.Reason).
+const (
+	// ReasonLeaseExpired — истекла аренда
+	ReasonLeaseExpired = "lease_expired"
+	// ReasonWindowEnded — завершилось окно denied-to; блокировка регламентных заданий
+	// окна не имеет и без отключения осталась бы включённой
+	ReasonWindowEnded = "window_ended"
+)
+
+// InfobaseError — ошибка проверки или отключения информационной базы.
+type InfobaseError struct {
+	Infobase string `json:"infobase"`
+	Server   string `json:"server"`
+	Error    string `json:"error"`
+}
+
+// ServiceModeReaperData содержит данные ответа nr-service-mode-reaper.
+type ServiceModeReaperData struct {
+	// CheckedAt — время проверки (RFC3339)
+	CheckedAt string `json:"checked_at"`
+	// Checked — количество проверенных информационных баз
+	Checked int `json:"checked"`
+	// Expired — истёкшие аренды (отключённые или, в dry-run, подлежащие отключению)
+	Expired []LeaseInfo `json:"expired"`
+	// Active — действующие аренды
+	Active []LeaseInfo `json:"active"`
+	// JobsBlocked — начавшиеся окна, в которых заблокированы регламентные задания
+	// (в dry-run — подлежащие блокировке)
+	JobsBlocked []LeaseInfo `json:"jobs_blocked,omitempty"`
+	// StateChanged — был ли отключён хотя бы один сервисный режим
+	StateChanged bool `json:"state_changed"`
+	// DryRun — отключение не выполнялось (BR_DRY_RUN)
+	DryRun bool `json:"dry_run,omitempty"`
+	// Errors — базы, которые не удалось проверить или отключить
+	Errors []InfobaseError `json:"errors,omitempty"`
+}
+
+// writeText выводит результат в человекочитаемом формате.
+func (d *ServiceModeReaperData) writeText(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "Проверено информационных баз: %d\n", d.Checked); err != nil {
+		return err
+	}
+
+	title := "Отключён сервисный режим с истёкшей арендой или окном"
+	if d.DryRun {
+		title = "Будет отключён сервисный режим с истёкшей арендой или окном (dry-run)"
+	}
+	if err := writeLeases(w, title, d.Expired); err != nil {
+		return err
+	}
+	if err := writeLeases(w, "Действующие аренды", d.Active); err != nil {
+		return err
+	}
+	if len(d.JobsBlocked) > 0 {
+		title := "Заблокированы регламентные задания начавшихся окон"
+		if d.DryRun {
+			title = "Будут заблокированы регламентные задания начавшихся окон (dry-run)"
+		}
+		if err := writeLeases(w, title, d.JobsBlocked); err != nil {
+			return err
+		}
+	}
+
+	if len(d.Errors) > 0 {
+		if _, err := fmt.Fprintf(w, "Ошибки:\n"); err != nil {
+			return err
+		}
+		for _, e := range d.Errors {
+			if _, err := fmt.Fprintf(w, "  %s (%s): %s\n", e.Infobase, e.Server, e.Error); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func writeLeases(w io.Writer, title string, leases []LeaseInfo) error {
+	if _, err := fmt.Fprintf(w, "%s: %d\n", title, len(leases)); err != nil {
+		return err
+	}
+	for _, l := range leases {
+		if _, err := fmt.Fprintf(w, "  %s (%s): %s до %s\n", l.Infobase, l.Server, l.Owner, l.Expires); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ServiceModeReaperHandler обрабатывает команду nr-service-mode-reaper.
+// Проверяются все базы DbConfig с one-server; блокировки без аренды
+// (включённые вручную) не изменяются. Сервисный режим с арендой отключается,
+// если истекла аренда или завершилось окно (DeniedTo).
+type ServiceModeReaperHandler struct {
+	// clientFactory — опциональная фабрика RAC клиента по серверу (nil в production, mock в тестах)
+	clientFactory func(cfg *config.Config, server string) (rac.Client, error)
+	// now — опциональный источник текущего времени (nil в production)
+	now func() time.Time
+}
+
+// Name возвращает имя команды.
+func (h *ServiceModeReaperHandler) Name() string {
+	return constants.ActNRServiceModeReaper
+}
+
+// Description возвращает описание команды для вывода в help.
+func (h *ServiceModeReaperHandler) Description() string {
+	return "Отключение сервисного режима с истёкшей арендой или окном и блокировка заданий начавшихся окон"
+}
+
+// Execute выполняет команду nr-service-mode-reaper.
+func (h *ServiceModeReaperHandler) Execute(ctx context.Context, cfg *config.Config) error {
+	start := time.Now()
+
+	traceID := tracing.TraceIDFromContext(ctx)
+	if traceID == "" {
+		traceID = tracing.GenerateTraceID()
+	}
+
+	format := os.Getenv("BR_OUTPUT_FORMAT")
+
+	// Plan-only не поддерживается; dry-run имеет приоритет над plan-only.
+	if !dryrun.IsDryRun() && dryrun.IsPlanOnly() {
+		return dryrun.WritePlanOnlyUnsupported(os.Stdout, constants.ActNRServiceModeReaper)
+	}
+
+	log := slog.Default().With(slog.String("trace_id", traceID), slog.String("command", constants.ActNRServiceModeReaper))
+
+	servers := infobasesByServer(cfg)
+	if len(servers) == 0 {
+		log.Error("В DbConfig нет информационных баз с сервером 1C")
+		return h.writeError(format, traceID, start,
+			"CONFIG.DBCONFIG_MISSING",
+			"В DbConfig нет информационных баз с сервером 1C (one-server)")
+	}
+
+	newClient := h.clientFactory
+	if newClient == nil {
+		newClient = racutil.NewClientForServer
+	}
+	now := time.Now()
+	if h.now != nil {
+		now = h.now()
+	}
+
+	data := &ServiceModeReaperData{
+		CheckedAt: now.Format(time.RFC3339),
+		Expired:   make([]LeaseInfo, 0),
+		Active:    make([]LeaseInfo, 0),
+		DryRun:    dryrun.IsDryRun(),
+	}
+	releaseFailed := false
+	blockFailed := false
+
+	serverNames := make([]string, 0, len(servers))
+	for server := range servers {
+		serverNames = append(serverNames, server)
+	}
+	sort.Strings(serverNames)
+
+	for _, server := range serverNames {
+		infobases := servers[server]
+		client, err := newClient(cfg, server)
+		var clusterInfo *rac.ClusterInfo
+		if err == nil {
+			clusterInfo, err = client.GetClusterInfo(ctx)
+		}
+		if err != nil {
+			log.Warn("Сервер 1C недоступен", slog.String("server", server), slog.String("error", err.Error()))
+			for _, name := range infobases {
+				data.Errors = append(data.Errors, InfobaseError{Infobase: name, Server: server, Error: err.Error()})
+			}
+			continue
+		}
+
+		for _, name := range infobases {
+			infobaseInfo, err := client.GetInfobaseInfo(ctx, clusterInfo.UUID, name)
+			var status *rac.ServiceModeStatus
+			if err == nil {
+				status, err = client.GetServiceModeStatus(ctx, clusterInfo.UUID, infobaseInfo.UUID)
+			}
+			if err != nil {
+				log.Warn("Не удалось получить статус сервисного режима",
+					slog.String("infobase", name), slog.String("error", err.Error()))
+				data.Errors = append(data.Errors, InfobaseError{Infobase: name, Server: server, Error: err.Error()})
+				continue
+			}
+			data.Checked++
+
+			lease, ok := rac.ParseLease(status.DeniedParameter)
+			if !ok || !status.Enabled {
+				continue
+			}
+			info := LeaseInfo{
+				Infobase: name,
+				Server:   server,
+				Owner:    lease.Owner,
+				TraceID:  lease.TraceID,
+				Expires:  lease.Expires.Local().Format(time.RFC3339),
+			}
+			switch {
+			case lease.Expired(now):
+				info.Reason = ReasonLeaseExpired
+			case status.Ended(now):
+				info.Reason = ReasonWindowEnded
+			default:
+				data.Active = append(data.Active, info)
+				if status.Active(now) && !status.ScheduledJobsBlocked {
+					if err := h.blockJobs(ctx, log, client, clusterInfo.UUID, infobaseInfo.UUID, data.DryRun); err != nil {
+						data.Errors = append(data.Errors, InfobaseError{Infobase: name, Server: server, Error: err.Error()})
+						blockFailed = true
+						continue
+					}
+					data.JobsBlocked = append(data.JobsBlocked, info)
+					data.StateChanged = data.StateChanged || !data.DryRun
+				}
+				continue
+			}
+
+			log.Warn("Сервисный режим подлежит отключению",
+				slog.String("infobase", name),
+				slog.String("reason", info.Reason),
+				slog.String("lease_owner", lease.Owner),
+				slog.String("lease_trace_id", lease.TraceID),
+				slog.String("lease_expires", info.Expires))
+			if !data.DryRun {
+				err := client.DisableServiceMode(ctx, clusterInfo.UUID, infobaseInfo.UUID)
+				if err == nil {
+					err = client.VerifyServiceMode(ctx, clusterInfo.UUID, infobaseInfo.UUID, false)
+				}
+				if err != nil {
+					log.Error("Не удалось отключить сервисный режим",
+						slog.String("infobase", name), slog.String("error", err.Error()))
+					data.Errors = append(data.Errors, InfobaseError{Infobase: name, Server: server, Error: err.Error()})
+					releaseFailed = true
+					continue
+				}
+				info.Released = true
+				data.StateChanged = true
+				log.Info("Сервисный режим отключён", slog.String("infobase", name), slog.String("reason", info.Reason))
+			}
+			data.Expired = append(data.Expired, info)
+		}
+	}
+
+	log.Info("Проверка аренд сервисного режима завершена",
+		slog.Int("checked", data.Checked),
+		slog.Int("expired", len(data.Expired)),
+		slog.Int("active", len(data.Active)),
+		slog.Int("jobs_blocked", len(data.JobsBlocked)),
+		slog.Int("errors", len(data.Errors)))
+
+	// Неснятая истёкшая аренда или недоступность всех баз — ошибка команды:
+	// блокировка остаётся, и планировщик должен об этом узнать
+	if releaseFailed {
+		return h.writeError(format, traceID, start, "RAC.DISABLE_FAILED",
+			"Не удалось отключить сервисный режим с истёкшей арендой: "+formatErrors(data.Errors))
+	}
+	if blockFailed {
+		return h.writeError(format, traceID, start, "RAC.JOBS_DENY_FAILED",
+			"Не удалось заблокировать регламентные задания в начавшемся окне: "+formatErrors(data.Errors))
+	}
+	if data.Checked == 0 {
+		return h.writeError(format, traceID, start, "RAC.REAPER_FAILED",
+			"Не удалось проверить ни одной информационной базы: "+formatErrors(data.Errors))
+	}
+
+	return h.writeSuccess(format, traceID, start, data)
+}
+
+// blockJobs блокирует регламентные задания в начавшемся окне сервисного режима:
+// ScheduleServiceMode не блокирует их заранее (rac.ServiceModeScheduler).
+// В dry-run только проверяет поддержку блокировки клиентом.
+func (h *ServiceModeReaperHandler) blockJobs(ctx context.Context, log *slog.Logger, client rac.Client, clusterUUID, infobaseUUID string, dryRun bool) error {
+	jobs, ok := client.(rac.ScheduledJobsController)
+	if !ok {
+		return fmt.Errorf("RAC клиент не поддерживает блокировку регламентных заданий")
+	}
+	if dryRun {
+		return nil
+	}
+	if err := jobs.SetScheduledJobsDeny(ctx, clusterUUID, infobaseUUID, true); err != nil {
+		return err
+	}
+	log.Info("Регламентные задания заблокированы: окно сервисного режима началось",
+		slog.String("infobase_uuid", infobaseUUID))
+	return nil
+}
+
+// formatErrors возвращает ошибки баз одной строкой.
+func formatErrors(errs []InfobaseError) string {
+	parts := make([]string, 0, len(errs))
+	for _, e := range errs {
+		parts = append(parts, fmt.Sprintf("%s (%s): %s", e.Infobase, e.Server, e.Error))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// infobasesByServer группирует базы DbConfig с one-server по серверу 1C.
+func infobasesByServer(cfg *config.Config) map[string][]string {
+	servers := make(map[string][]string)
+	if cfg == nil {
+		return servers
+	}
+	names := make([]string, 0, len(cfg.DbConfig))
+	for name := range cfg.DbConfig {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if info := cfg.DbConfig[name]; info != nil && info.OneServer != "" {
+			servers[info.OneServer] = append(servers[info.OneServer], name)
+		}
+	}
+	return servers
+}
+
+// writeSuccess выводит успешный результат.
+func (h *ServiceModeReaperHandler) writeSuccess(format, traceID string, start time.Time, data *ServiceModeReaperData) error {
+	if format != output.FormatJSON {
+		return data.writeText(os.Stdout)
+	}
+
+	result := &output.Result{
+		Status:  output.StatusSuccess,
+		Command: constants.ActNRServiceModeReaper,
+		Data:    data,
+		Metadata: &output.Metadata{
+			DurationMs: time.Since(start).Milliseconds(),
+			TraceID:    traceID,
+			APIVersion: constants.APIVersion,
+		},
+	}
+
+	writer := output.NewWriter(format)
+	return writer.Write(os.Stdout, result)
+}
+
+// writeError выводит структурированную ошибку и возвращает error.
+func (h *ServiceModeReaperHandler) writeError(format, traceID string, start time.Time, code, message string) error {
+	// Текстовый формат — человекочитаемый вывод ошибки
+	if format != output.FormatJSON {
+		return errhandler.HandleError(message, code)
+	}
+
+	result := &output.Result{
+		Status:  output.StatusError,
+		Command: constants.ActNRServiceModeReaper,
+		Error: &output.ErrorInfo{
+			Code:    code,
+			Message: message,
+		},
+		Metadata: &output.Metadata{
+			DurationMs: time.Since(start).Milliseconds(),
+			TraceID:    traceID,
+			APIVersion: constants.APIVersion,
+		},
+	}
+
+	writer := output.NewWriter(format)
+	if writeErr := writer.Write(os.Stdout, result); writeErr != nil {
+		slog.Default().Error("Не удалось записать JSON-ответ об ошибке",
+			slog.String("trace_id", traceID),
+			slog.String("error", writeErr.Error()))
+	}
+
+	return fmt.Errorf("%s: %s", code, message)
+}
@@ -0,0 +1,239 @@
+package servicemodereaperhandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac/ractest"
+	"github.com/Kargones/apk-ci/internal/command"
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/pkg/output"
+	"github.com/Kargones/apk-ci/internal/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var reaperNow = time.Date(2026, 3, 14, 17, 0, 0, 0, time.Local)
+
+func TestServiceModeReaperHandler_Registration(t *testing.T) {
+	h, ok := command.Get(constants.ActNRServiceModeReaper)
+	require.True(t, ok)
+	assert.Equal(t, constants.ActNRServiceModeReaper, h.Name())
+	assert.NotEmpty(t, h.Description())
+}
+
+// infobaseState — состояние сервисного режима базы на mock-сервере.
+type infobaseState struct {
+	enabled   bool
+	parameter string
+	// from, to — окно denied-from/denied-to; jobs — регламентные задания заблокированы
+	from, to time.Time
+	jobs     bool
+}
+
+// newServerMock создаёт mock сервера 1C с базами states; DisableServiceMode снимает блокировку.
+func newServerMock(states map[string]*infobaseState) *ractest.MockRACClient {
+	mock := ractest.NewMockRACClient()
+	mock.GetInfobaseInfoFunc = func(_ context.Context, _, name string) (*rac.InfobaseInfo, error) {
+		if _, ok := states[name]; !ok {
+			return nil, errors.New("информационная база не найдена")
+		}
+		return &rac.InfobaseInfo{UUID: name, Name: name}, nil
+	}
+	mock.GetServiceModeStatusFunc = func(_ context.Context, _, uuid string) (*rac.ServiceModeStatus, error) {
+		s := states[uuid]
+		return &rac.ServiceModeStatus{Enabled: s.enabled, DeniedParameter: s.parameter,
+			DeniedFrom: s.from, DeniedTo: s.to, ScheduledJobsBlocked: s.jobs}, nil
+	}
+	mock.DisableServiceModeFunc = func(_ context.Context, _, uuid string) error {
+		states[uuid].enabled = false
+		states[uuid].parameter = ""
+		states[uuid].jobs = false
+		return nil
+	}
+	mock.SetScheduledJobsDenyFunc = func(_ context.Context, _, uuid string, deny bool) error {
+		states[uuid].jobs = deny
+		return nil
+	}
+	mock.VerifyServiceModeFunc = func(_ context.Context, _, uuid string, expected bool) error {
+		if states[uuid].enabled != expected {
+			return errors.New("статус не совпадает")
+		}
+		return nil
+	}
+	return mock
+}
+
+func lease(owner string, expires time.Time) string {
+	return (&rac.Lease{Owner: owner, TraceID: "trace-" + owner, Expires: expires}).String()
+}
+
+func reaperConfig() *config.Config {
+	return &config.Config{DbConfig: map[string]*config.DatabaseInfo{
+		"erp":      {OneServer: "srv-a"},
+		"zup":      {OneServer: "srv-a"},
+		"manual":   {OneServer: "srv-a"},
+		"offline":  {OneServer: "srv-b"},
+		"noserver": {},
+	}}
+}
+
+func newHandler(clients map[string]rac.Client) *ServiceModeReaperHandler {
+	return &ServiceModeReaperHandler{
+		clientFactory: func(_ *config.Config, server string) (rac.Client, error) {
+			if c, ok := clients[server]; ok {
+				return c, nil
+			}
+			return nil, errors.New("connection refused")
+		},
+		now: func() time.Time { return reaperNow },
+	}
+}
+
+func run(t *testing.T, h *ServiceModeReaperHandler, cfg *config.Config) (*ServiceModeReaperData, string, error) {
+	t.Helper()
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+	var execErr error
+	out := testutil.CaptureStdout(t, func() {
+		execErr = h.Execute(context.Background(), cfg)
+	})
+	if execErr != nil {
+		return nil, out, execErr
+	}
+	var result struct {
+		output.Result
+		Data ServiceModeReaperData `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+	return &result.Data, out, nil
+}
+
+func TestServiceModeReaperHandler_ReleasesExpiredLeases(t *testing.T) {
+	t.Setenv(constants.EnvDryRun, "")
+	states := map[string]*infobaseState{
+		"erp":    {enabled: true, parameter: lease("erp/main#1", reaperNow.Add(-time.Minute))},
+		"zup":    {enabled: true, parameter: lease("zup/main#7", reaperNow.Add(time.Hour))},
+		"manual": {enabled: true},
+	}
+
+	data, _, err := run(t, newHandler(map[string]rac.Client{"srv-a": newServerMock(states)}), reaperConfig())
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, data.Checked)
+	require.Len(t, data.Expired, 1)
+	assert.Equal(t, "erp", data.Expired[0].Infobase)
+	assert.Equal(t, "erp/main#1", data.Expired[0].Owner)
+	assert.Equal(t, ReasonLeaseExpired, data.Expired[0].Reason)
+	assert.True(t, data.Expired[0].Released)
+	assert.True(t, data.StateChanged)
+	require.Len(t, data.Active, 1)
+	assert.Equal(t, "zup", data.Active[0].Infobase)
+	require.Len(t, data.Errors, 1, "сервер srv-b недоступен")
+	assert.Equal(t, "offline", data.Errors[0].Infobase)
+
+	assert.False(t, states["erp"].enabled)
+	assert.True(t, states["zup"].enabled)
+	assert.True(t, states["manual"].enabled, "блокировка без аренды не снимается")
+}
+
+// TestServiceModeReaperHandler_ScheduledWindow проверяет сопровождение окна сервисного
+// режима: до начала окна задания не блокируются, после начала — блокируются,
+// после окончания (при действующей аренде) режим отключается и задания разблокируются.
+func TestServiceModeReaperHandler_ScheduledWindow(t *testing.T) {
+	t.Setenv(constants.EnvDryRun, "")
+	leaseExpires := reaperNow.Add(8 * time.Hour)
+	states := map[string]*infobaseState{
+		"erp": {enabled: true, parameter: lease("erp/main#1", leaseExpires),
+			from: reaperNow.Add(time.Hour), to: reaperNow.Add(3 * time.Hour)},
+	}
+	h := newHandler(map[string]rac.Client{"srv-a": newServerMock(states)})
+	at := func(now time.Time) *ServiceModeReaperData {
+		t.Helper()
+		h.now = func() time.Time { return now }
+		data, _, err := run(t, h, reaperConfig())
+		require.NoError(t, err)
+		return data
+	}
+
+	data := at(reaperNow)
+	assert.Empty(t, data.JobsBlocked, "окно ещё не началось")
+	assert.False(t, states["erp"].jobs)
+
+	data = at(reaperNow.Add(2 * time.Hour))
+	require.Len(t, data.JobsBlocked, 1)
+	assert.Equal(t, "erp", data.JobsBlocked[0].Infobase)
+	assert.True(t, data.StateChanged)
+	assert.True(t, states["erp"].jobs, "окно началось — задания заблокированы")
+
+	data = at(reaperNow.Add(4 * time.Hour))
+	require.Len(t, data.Expired, 1, "окно завершилось при действующей аренде")
+	assert.Equal(t, ReasonWindowEnded, data.Expired[0].Reason)
+	assert.True(t, data.Expired[0].Released)
+	assert.False(t, states["erp"].enabled)
+	assert.False(t, states["erp"].jobs, "scheduled-jobs-deny снят после окончания окна")
+}
+
+func TestServiceModeReaperHandler_DryRun(t *testing.T) {
+	t.Setenv(constants.EnvDryRun, "true")
+	states := map[string]*infobaseState{
+		"erp": {enabled: true, parameter: lease("erp/main#1", reaperNow.Add(-time.Minute))},
+	}
+
+	data, _, err := run(t, newHandler(map[string]rac.Client{"srv-a": newServerMock(states)}), reaperConfig())
+	require.NoError(t, err)
+	require.Len(t, data.Expired, 1)
+	assert.False(t, data.Expired[0].Released)
+	assert.False(t, data.StateChanged)
+	assert.True(t, states["erp"].enabled)
+}
+
+func TestServiceModeReaperHandler_DisableFailed(t *testing.T) {
+	t.Setenv(constants.EnvDryRun, "")
+	states := map[string]*infobaseState{
+		"erp": {enabled: true, parameter: lease("erp/main#1", reaperNow.Add(-time.Minute))},
+	}
+	mock := newServerMock(states)
+	mock.DisableServiceModeFunc = func(context.Context, string, string) error {
+		return errors.New("недостаточно прав")
+	}
+
+	_, out, err := run(t, newHandler(map[string]rac.Client{"srv-a": mock}), reaperConfig())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "RAC.DISABLE_FAILED")
+	assert.Contains(t, out, "недостаточно прав")
+}
+
+func TestServiceModeReaperHandler_AllUnavailable(t *testing.T) {
+	t.Setenv(constants.EnvDryRun, "")
+
+	_, _, err := run(t, newHandler(nil), reaperConfig())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "RAC.REAPER_FAILED")
+}
+
+func TestServiceModeReaperHandler_NoInfobases(t *testing.T) {
+	_, _, err := run(t, newHandler(nil), &config.Config{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "CONFIG.DBCONFIG_MISSING")
+}
+
+func TestServiceModeReaperHandler_TextOutput(t *testing.T) {
+	t.Setenv(constants.EnvDryRun, "")
+	t.Setenv("BR_OUTPUT_FORMAT", "text")
+	states := map[string]*infobaseState{
+		"erp": {enabled: true, parameter: lease("erp/main#1", reaperNow.Add(-time.Minute))},
+	}
+
+	h := newHandler(map[string]rac.Client{"srv-a": newServerMock(states)})
+	out := testutil.CaptureStdout(t, func() {
+		require.NoError(t, h.Execute(context.Background(), reaperConfig()))
+	})
+	assert.Contains(t, out, "Отключён сервисный режим с истёкшей арендой или окном: 1")
+	assert.Contains(t, out, "erp (srv-a): erp/main#1")
+	assert.Contains(t, out, "offline (srv-b): connection refused")
+}
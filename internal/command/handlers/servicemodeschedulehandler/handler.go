@@ -0,0 +1,311 @@
+// Package servicemodeschedulehandler реализует NR-команду nr-service-mode-schedule
+// для вывода действующих и запланированных окон сервисного режима
+// по всем информационным базам из DbConfig.
+package servicemodeschedulehandler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
+	"github.com/Kargones/apk-ci/internal/command"
+	"github.com/Kargones/apk-ci/internal/command/handlers/racutil"
+	errhandler "github.com/Kargones/apk-ci/internal/command/handlers/shared"
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/pkg/dryrun"
+	"github.com/Kargones/apk-ci/internal/pkg/output"
+	"github.com/Kargones/apk-ci/internal/pkg/tracing"
+)
+
+// Compile-time interface check.
+var _ command.Handler = (*ServiceModeScheduleHandler)(nil)
+
+func RegisterCmd() error {
+	return command.Register(&ServiceModeScheduleHandler{})
+}
+
+// WindowInfo — окно сервисного режима информационной базы.
+type WindowInfo struct {
+	// Infobase — имя информационной базы
+	Infobase string `json:"infobase"`
+	// Server — сервер 1C
+	Server string `json:"server"`
+	// From — начало окна (RFC3339), пустая строка если не задано
+	From string `json:"from,omitempty"`
+	// To — окончание окна (RFC3339), пустая строка — до явного отключения
+	To string `json:"to,omitempty"`
+	// Active — окно уже действует
+	Active bool `json:"active"`
+	// Message — сообщение блокировки
+	Message string `json:"message"`
+	// ScheduledJobsBlocked — заблокированы ли регламентные задания
+	ScheduledJobsBlocked bool `json:"scheduled_jobs_blocked"`
+
+	from time.Time
+}
+
+// InfobaseError — ошибка получения статуса информационной базы.
+type InfobaseError struct {
+	// Infobase — имя информационной базы
+	Infobase string `json:"infobase"`
+	// Server — сервер 1C
+	Server string `json:"server"`
+	// Error — текст ошибки
+	Error string `json:"error"`
+}
+
+// ServiceModeScheduleData содержит данные ответа nr-service-mode-schedule.
+type ServiceModeScheduleData struct {
+	// Windows — действующие и запланированные окна, по возрастанию начала
+	Windows []WindowInfo `json:"windows"`
+	// CheckedInfobases — количество баз, статус которых получен
+	CheckedInfobases int `json:"checked_infobases"`
+	// Errors — базы, статус которых получить не удалось
+	Errors []InfobaseError `json:"errors,omitempty"`
+}
+
+// writeText выводит окна сервисного режима в человекочитаемом формате.
+func (d *ServiceModeScheduleData) writeText(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "Окна сервисного режима: %d (проверено баз: %d)\n",
+		len(d.Windows), d.CheckedInfobases); err != nil {
+		return err
+	}
+	for _, win := range d.Windows {
+		from, to := win.From, win.To
+		if from == "" {
+			from = "не задано"
+		}
+		if to == "" {
+			to = "до отключения"
+		}
+		state := "запланировано"
+		if win.Active {
+			state = "ДЕЙСТВУЕТ"
+		}
+		if _, err := fmt.Fprintf(w, "  %s — %s  %s (%s) [%s] \"%s\"\n",
+			from, to, win.Infobase, win.Server, state, win.Message); err != nil {
+			return err
+		}
+	}
+	if len(d.Errors) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprintln(w, "Ошибки:"); err != nil {
+		return err
+	}
+	for _, e := range d.Errors {
+		if _, err := fmt.Fprintf(w, "  %s (%s): %s\n", e.Infobase, e.Server, e.Error); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ServiceModeScheduleHandler обрабатывает команду nr-service-mode-schedule.
+// Обходит все базы DbConfig с заданным one-server, группируя их по серверу 1C.
+type ServiceModeScheduleHandler struct {
+	// clientFactory — опциональная фабрика RAC клиента по серверу (nil в production, mock в тестах)
+	clientFactory func(cfg *config.Config, server string) (rac.Client, error)
+}
+
+// Name возвращает имя команды.
+func (h *ServiceModeScheduleHandler) Name() string {
+	return constants.ActNRServiceModeSchedule
+}
+
+// Description возвращает описание команды для вывода в help.
+func (h *ServiceModeScheduleHandler) Description() string {
+	return "Окна сервисного режима по всем информационным базам"
+}
+
+// Execute выполняет команду nr-service-mode-schedule.
+func (h *ServiceModeScheduleHandler) Execute(ctx context.Context, cfg *config.Config) error {
+	start := time.Now()
+
+	traceID := tracing.TraceIDFromContext(ctx)
+	if traceID == "" {
+		traceID = tracing.GenerateTraceID()
+	}
+
+	format := os.Getenv("BR_OUTPUT_FORMAT")
+
+	// Plan-only не поддерживается; dry-run имеет приоритет над plan-only.
+	if !dryrun.IsDryRun() && dryrun.IsPlanOnly() {
+		return dryrun.WritePlanOnlyUnsupported(os.Stdout, constants.ActNRServiceModeSchedule)
+	}
+
+	log := slog.Default().With(slog.String("trace_id", traceID), slog.String("command", constants.ActNRServiceModeSchedule))
+
+	servers := infobasesByServer(cfg)
+	if len(servers) == 0 {
+		log.Error("В DbConfig нет информационных баз с сервером 1C")
+		return h.writeError(format, traceID, start,
+			"CONFIG.DBCONFIG_MISSING",
+			"В DbConfig нет информационных баз с сервером 1C (one-server)")
+	}
+
+	newClient := h.clientFactory
+	if newClient == nil {
+		newClient = racutil.NewClientForServer
+	}
+
+	data := &ServiceModeScheduleData{Windows: make([]WindowInfo, 0)}
+	serverNames := make([]string, 0, len(servers))
+	for server := range servers {
+		serverNames = append(serverNames, server)
+	}
+	sort.Strings(serverNames)
+
+	for _, server := range serverNames {
+		infobases := servers[server]
+		client, err := newClient(cfg, server)
+		var clusterInfo *rac.ClusterInfo
+		if err == nil {
+			clusterInfo, err = client.GetClusterInfo(ctx)
+		}
+		if err != nil {
+			log.Warn("Сервер 1C недоступен", slog.String("server", server), slog.String("error", err.Error()))
+			for _, name := range infobases {
+				data.Errors = append(data.Errors, InfobaseError{Infobase: name, Server: server, Error: err.Error()})
+			}
+			continue
+		}
+
+		for _, name := range infobases {
+			status, err := infobaseStatus(ctx, client, clusterInfo.UUID, name)
+			if err != nil {
+				log.Warn("Не удалось получить статус сервисного режима",
+					slog.String("infobase", name), slog.String("error", err.Error()))
+				data.Errors = append(data.Errors, InfobaseError{Infobase: name, Server: server, Error: err.Error()})
+				continue
+			}
+			data.CheckedInfobases++
+			if !status.Enabled || (!status.DeniedTo.IsZero() && !status.DeniedTo.After(start)) {
+				continue
+			}
+			data.Windows = append(data.Windows, WindowInfo{
+				Infobase:             name,
+				Server:               server,
+				From:                 formatTime(status.DeniedFrom),
+				To:                   formatTime(status.DeniedTo),
+				Active:               status.Active(start),
+				Message:              status.Message,
+				ScheduledJobsBlocked: status.ScheduledJobsBlocked,
+				from:                 status.DeniedFrom,
+			})
+		}
+	}
+
+	if data.CheckedInfobases == 0 {
+		log.Error("Не удалось получить статус ни одной информационной базы")
+		return h.writeError(format, traceID, start,
+			"RAC.SCHEDULE_FAILED",
+			fmt.Sprintf("Не удалось получить статус ни одной информационной базы: %s", data.Errors[0].Error))
+	}
+
+	sort.SliceStable(data.Windows, func(i, j int) bool {
+		return data.Windows[i].from.Before(data.Windows[j].from)
+	})
+
+	log.Info("Окна сервисного режима получены",
+		slog.Int("windows", len(data.Windows)),
+		slog.Int("checked_infobases", data.CheckedInfobases),
+		slog.Int("errors", len(data.Errors)))
+
+	return h.writeSuccess(format, traceID, start, data)
+}
+
+// infobasesByServer группирует имена баз DbConfig по серверу 1C (one-server).
+// Имена внутри сервера отсортированы; базы без one-server пропускаются.
+func infobasesByServer(cfg *config.Config) map[string][]string {
+	servers := make(map[string][]string)
+	if cfg == nil {
+		return servers
+	}
+	names := make([]string, 0, len(cfg.DbConfig))
+	for name := range cfg.DbConfig {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if info := cfg.DbConfig[name]; info != nil && info.OneServer != "" {
+			servers[info.OneServer] = append(servers[info.OneServer], name)
+		}
+	}
+	return servers
+}
+
+// infobaseStatus возвращает статус сервисного режима информационной базы name.
+func infobaseStatus(ctx context.Context, client rac.Client, clusterUUID, name string) (*rac.ServiceModeStatus, error) {
+	infobaseInfo, err := client.GetInfobaseInfo(ctx, clusterUUID, name)
+	if err != nil {
+		return nil, err
+	}
+	return client.GetServiceModeStatus(ctx, clusterUUID, infobaseInfo.UUID)
+}
+
+// formatTime возвращает время в RFC3339 или пустую строку для нулевого времени.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// writeSuccess выводит успешный результат.
+func (h *ServiceModeScheduleHandler) writeSuccess(format, traceID string, start time.Time, data *ServiceModeScheduleData) error {
+	if format != output.FormatJSON {
+		return data.writeText(os.Stdout)
+	}
+
+	result := &output.Result{
+		Status:  output.StatusSuccess,
+		Command: constants.ActNRServiceModeSchedule,
+		Data:    data,
+		Metadata: &output.Metadata{
+			DurationMs: time.Since(start).Milliseconds(),
+			TraceID:    traceID,
+			APIVersion: constants.APIVersion,
+		},
+	}
+
+	writer := output.NewWriter(format)
+	return writer.Write(os.Stdout, result)
+}
+
+// writeError выводит структурированную ошибку и возвращает error.
+func (h *ServiceModeScheduleHandler) writeError(format, traceID string, start time.Time, code, message string) error {
+	// Текстовый формат — человекочитаемый вывод ошибки
+	if format != output.FormatJSON {
+		return errhandler.HandleError(message, code)
+	}
+
+	result := &output.Result{
+		Status:  output.StatusError,
+		Command: constants.ActNRServiceModeSchedule,
+		Error: &output.ErrorInfo{
+			Code:    code,
+			Message: message,
+		},
+		Metadata: &output.Metadata{
+			DurationMs: time.Since(start).Milliseconds(),
+			TraceID:    traceID,
+			APIVersion: constants.APIVersion,
+		},
+	}
+
+	writer := output.NewWriter(format)
+	if writeErr := writer.Write(os.Stdout, result); writeErr != nil {
+		slog.Default().Error("Не удалось записать JSON-ответ об ошибке",
+			slog.String("trace_id", traceID),
+			slog.String("error", writeErr.Error()))
+	}
+
+	return fmt.Errorf("%s: %s", code, message)
+}
@@ -0,0 +1,153 @@
+package servicemodeschedulehandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac/ractest"
+	"github.com/Kargones/apk-ci/internal/command"
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/pkg/output"
+	"github.com/Kargones/apk-ci/internal/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceModeScheduleHandler_Registration(t *testing.T) {
+	h, ok := command.Get(constants.ActNRServiceModeSchedule)
+	require.True(t, ok)
+	assert.Equal(t, constants.ActNRServiceModeSchedule, h.Name())
+	assert.NotEmpty(t, h.Description())
+}
+
+// newScheduleMock создаёт mock сервера 1C со статусами баз statuses.
+func newScheduleMock(statuses map[string]*rac.ServiceModeStatus) *ractest.MockRACClient {
+	mock := ractest.NewMockRACClient()
+	mock.GetInfobaseInfoFunc = func(_ context.Context, _, name string) (*rac.InfobaseInfo, error) {
+		if _, ok := statuses[name]; !ok {
+			return nil, errors.New("информационная база не найдена")
+		}
+		return &rac.InfobaseInfo{UUID: name, Name: name}, nil
+	}
+	mock.GetServiceModeStatusFunc = func(_ context.Context, _, uuid string) (*rac.ServiceModeStatus, error) {
+		return statuses[uuid], nil
+	}
+	return mock
+}
+
+func scheduleConfig() *config.Config {
+	return &config.Config{DbConfig: map[string]*config.DatabaseInfo{
+		"erp_test":  {OneServer: "srv-a"},
+		"erp_prod":  {OneServer: "srv-a", Prod: true},
+		"zup_test":  {OneServer: "srv-b"},
+		"lost_test": {OneServer: "srv-b"},
+		"sql_only":  {DbServer: "sql-1"},
+	}}
+}
+
+func TestServiceModeScheduleHandler_Execute_JSONOutput(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+
+	now := time.Now().Truncate(time.Second)
+	clients := map[string]rac.Client{
+		"srv-a": newScheduleMock(map[string]*rac.ServiceModeStatus{
+			"erp_test": {Enabled: true, Message: "Обновление", DeniedFrom: now.Add(3 * time.Hour), DeniedTo: now.Add(5 * time.Hour)},
+			"erp_prod": {Enabled: true, Message: "Сейчас", ScheduledJobsBlocked: true, DeniedFrom: now.Add(-time.Hour)},
+		}),
+		"srv-b": newScheduleMock(map[string]*rac.ServiceModeStatus{
+			"zup_test": {Enabled: true, DeniedFrom: now.Add(-3 * time.Hour), DeniedTo: now.Add(-time.Hour)},
+		}),
+	}
+	h := &ServiceModeScheduleHandler{clientFactory: func(_ *config.Config, server string) (rac.Client, error) {
+		return clients[server], nil
+	}}
+
+	var execErr error
+	out := testutil.CaptureStdout(t, func() {
+		execErr = h.Execute(context.Background(), scheduleConfig())
+	})
+	require.NoError(t, execErr)
+
+	var result struct {
+		Status string                  `json:"status"`
+		Data   ServiceModeScheduleData `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+	assert.Equal(t, "success", result.Status)
+	assert.Equal(t, 3, result.Data.CheckedInfobases)
+
+	require.Len(t, result.Data.Windows, 2, "завершившееся окно zup_test не выводится")
+	assert.Equal(t, "erp_prod", result.Data.Windows[0].Infobase)
+	assert.True(t, result.Data.Windows[0].Active)
+	assert.Empty(t, result.Data.Windows[0].To)
+	assert.Equal(t, "erp_test", result.Data.Windows[1].Infobase)
+	assert.False(t, result.Data.Windows[1].Active)
+	assert.Equal(t, now.Add(3*time.Hour).Format(time.RFC3339), result.Data.Windows[1].From)
+
+	require.Len(t, result.Data.Errors, 1)
+	assert.Equal(t, "lost_test", result.Data.Errors[0].Infobase)
+	assert.Equal(t, "srv-b", result.Data.Errors[0].Server)
+}
+
+func TestServiceModeScheduleHandler_Execute_TextOutput(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "text")
+
+	from := time.Now().Add(time.Hour).Truncate(time.Second)
+	mock := newScheduleMock(map[string]*rac.ServiceModeStatus{
+		"erp_test": {Enabled: true, Message: "Обновление", DeniedFrom: from},
+		"erp_prod": {},
+	})
+	h := &ServiceModeScheduleHandler{clientFactory: func(_ *config.Config, server string) (rac.Client, error) {
+		if server == "srv-b" {
+			return nil, errors.New("connection refused")
+		}
+		return mock, nil
+	}}
+
+	out := testutil.CaptureStdout(t, func() {
+		require.NoError(t, h.Execute(context.Background(), scheduleConfig()))
+	})
+	assert.Contains(t, out, "Окна сервисного режима: 1 (проверено баз: 2)")
+	assert.Contains(t, out, from.Format(time.RFC3339)+" — до отключения  erp_test (srv-a) [запланировано]")
+	assert.Contains(t, out, "zup_test (srv-b): connection refused")
+}
+
+func TestServiceModeScheduleHandler_Execute_AllServersFailed(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+
+	h := &ServiceModeScheduleHandler{clientFactory: func(_ *config.Config, _ string) (rac.Client, error) {
+		return nil, errors.New("connection refused")
+	}}
+
+	var execErr error
+	out := testutil.CaptureStdout(t, func() {
+		execErr = h.Execute(context.Background(), scheduleConfig())
+	})
+	require.Error(t, execErr)
+
+	var result output.Result
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+	require.NotNil(t, result.Error)
+	assert.Equal(t, "RAC.SCHEDULE_FAILED", result.Error.Code)
+}
+
+func TestServiceModeScheduleHandler_Execute_NoDbConfig(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+
+	h := &ServiceModeScheduleHandler{}
+	var execErr error
+	out := testutil.CaptureStdout(t, func() {
+		execErr = h.Execute(context.Background(), &config.Config{})
+	})
+	require.Error(t, execErr)
+
+	var result output.Result
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+	require.NotNil(t, result.Error)
+	assert.Equal(t, "CONFIG.DBCONFIG_MISSING", result.Error.Code)
+}
@@ -10,8 +10,10 @@ import (
 	"os"
 	"time"
 
+	"github.com/Kargones/apk-ci/internal/adapter/mssql"
 	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
 	"github.com/Kargones/apk-ci/internal/command"
+	"github.com/Kargones/apk-ci/internal/command/handlers/mssqlutil"
 	"github.com/Kargones/apk-ci/internal/command/handlers/racutil"
 	"github.com/Kargones/apk-ci/internal/config"
 	"github.com/Kargones/apk-ci/internal/constants"
@@ -21,6 +23,10 @@ import (
 	errhandler "github.com/Kargones/apk-ci/internal/command/handlers/shared"
 )
 
+// lastRestoreTimeout — таймаут запроса последнего восстановления: сведение
+// дополнительное и не должно задерживать проверку статуса.
+const lastRestoreTimeout = 5 * time.Second
+
 func RegisterCmd() error {
 	// Deprecated: alias "service-mode-status" retained for backward compatibility. Remove in v2.0.0 (Epic 7).
 	return command.RegisterWithAlias(&ServiceModeStatusHandler{}, constants.ActServiceModeStatus)
@@ -40,12 +46,30 @@ type SessionInfoData struct {
 	AppID string `json:"app_id"`
 }
 
+// LastRestoreData содержит сведения о последнем восстановлении базы из резервной копии.
+type LastRestoreData struct {
+	// RestoredAt — время восстановления (RFC3339)
+	RestoredAt string `json:"restored_at"`
+	// DataAsOf — момент, на который восстановлены данные (RFC3339), пустая строка если не определён
+	DataAsOf string `json:"data_as_of,omitempty"`
+	// SrcServer — сервер-источник резервной копии
+	SrcServer string `json:"src_server"`
+	// SrcDB — база-источник
+	SrcDB string `json:"src_db"`
+}
+
 // ServiceModeStatusData содержит данные ответа о статусе сервисного режима.
 // Примечание: поле StateChanged отсутствует, т.к. status — read-only операция,
 // не изменяющая состояние системы.
 type ServiceModeStatusData struct {
-	// Enabled — включён ли сервисный режим
+	// Enabled — действует ли сервисный режим сейчас (с учётом окна denied-from/denied-to)
 	Enabled bool `json:"enabled"`
+	// Scheduled — сервисный режим запланирован на будущее (окно ещё не началось)
+	Scheduled bool `json:"scheduled"`
+	// DeniedFrom — начало окна блокировки сеансов (RFC3339), пустая строка если не задано
+	DeniedFrom string `json:"denied_from,omitempty"`
+	// DeniedTo — окончание окна блокировки сеансов (RFC3339), пустая строка если не задано
+	DeniedTo string `json:"denied_to,omitempty"`
 	// Message — сообщение блокировки
 	Message string `json:"message"`
 	// ScheduledJobsBlocked — заблокированы ли регламентные задания
@@ -56,6 +80,8 @@ type ServiceModeStatusData struct {
 	InfobaseName string `json:"infobase_name"`
 	// Sessions — список активных сессий
 	Sessions []SessionInfoData `json:"sessions"`
+	// LastRestore — последнее восстановление тестовой базы (nil — не восстанавливалась или неизвестно)
+	LastRestore *LastRestoreData `json:"last_restore,omitempty"`
 }
 
 // writeText выводит статус сервисного режима в человекочитаемом формате.
@@ -63,6 +89,8 @@ func (d *ServiceModeStatusData) writeText(w io.Writer) error {
 	enabledText := "ВЫКЛЮЧЕН"
 	if d.Enabled {
 		enabledText = "ВКЛЮЧЁН"
+	} else if d.Scheduled {
+		enabledText = "ЗАПЛАНИРОВАН"
 	}
 	jobsText := "разблокированы"
 	if d.ScheduledJobsBlocked {
@@ -76,6 +104,29 @@ func (d *ServiceModeStatusData) writeText(w io.Writer) error {
 		return err
 	}
 
+	if d.DeniedFrom != "" || d.DeniedTo != "" {
+		from, to := d.DeniedFrom, d.DeniedTo
+		if from == "" {
+			from = "не задано"
+		}
+		if to == "" {
+			to = "до отключения"
+		}
+		if _, err = fmt.Fprintf(w, "Окно: %s — %s\n", from, to); err != nil {
+			return err
+		}
+	}
+
+	if d.LastRestore != nil {
+		details := "источник " + d.LastRestore.SrcServer + "/" + d.LastRestore.SrcDB
+		if d.LastRestore.DataAsOf != "" {
+			details = "данные на " + d.LastRestore.DataAsOf + ", " + details
+		}
+		if _, err = fmt.Fprintf(w, "Последнее восстановление: %s (%s)\n", d.LastRestore.RestoredAt, details); err != nil {
+			return err
+		}
+	}
+
 	// Детали сессий
 	if _, err = fmt.Fprintln(w, "Детали сессий:"); err != nil {
 		return err
@@ -108,6 +159,8 @@ func (d *ServiceModeStatusData) writeText(w io.Writer) error {
 type ServiceModeStatusHandler struct {
 	// racClient — опциональный RAC клиент (nil в production, mock в тестах)
 	racClient rac.Client
+	// mssqlFactory — опциональная фабрика MSSQL клиента (nil в production, mock в тестах)
+	mssqlFactory func(opts mssql.ClientOptions) (mssql.Client, error)
 }
 
 // Name возвращает имя команды.
@@ -197,6 +250,8 @@ func (h *ServiceModeStatusHandler) Execute(ctx context.Context, cfg *config.Conf
 	// Логирование текущего состояния для диагностики (AC-3 единообразие с enable/disable)
 	log.Info("Текущее состояние информационной базы",
 		slog.Bool("enabled", status.Enabled),
+		slog.Time("denied_from", status.DeniedFrom),
+		slog.Time("denied_to", status.DeniedTo),
 		slog.Bool("scheduled_jobs_blocked", status.ScheduledJobsBlocked),
 		slog.Int("active_sessions", status.ActiveSessions),
 		slog.String("message", status.Message))
@@ -208,33 +263,31 @@ func (h *ServiceModeStatusHandler) Execute(ctx context.Context, cfg *config.Conf
 		log.Warn("Не удалось получить список сессий", slog.String("error", err.Error()))
 	} else {
 		for _, s := range sessions {
-			// Форматируем время только если оно не нулевое (RAC может не вернуть время)
-			startedAt := ""
-			if !s.StartedAt.IsZero() {
-				startedAt = s.StartedAt.Format(time.RFC3339)
-			}
-			lastActiveAt := ""
-			if !s.LastActiveAt.IsZero() {
-				lastActiveAt = s.LastActiveAt.Format(time.RFC3339)
-			}
 			sessionsData = append(sessionsData, SessionInfoData{
 				UserName:     s.UserName,
 				Host:         s.Host,
-				StartedAt:    startedAt,
-				LastActiveAt: lastActiveAt,
+				StartedAt:    formatTime(s.StartedAt),
+				LastActiveAt: formatTime(s.LastActiveAt),
 				AppID:        s.AppID,
 			})
 		}
 	}
 
 	// Формирование данных ответа
+	// Enabled отражает фактическое действие режима: блокировка с окном
+	// denied-from/denied-to действует только внутри окна.
+	now := time.Now()
 	data := &ServiceModeStatusData{
-		Enabled:              status.Enabled,
+		Enabled:              status.Active(now),
+		Scheduled:            status.Scheduled(now),
+		DeniedFrom:           formatTime(status.DeniedFrom),
+		DeniedTo:             formatTime(status.DeniedTo),
 		Message:              status.Message,
 		ScheduledJobsBlocked: status.ScheduledJobsBlocked,
 		ActiveSessions:       status.ActiveSessions,
 		InfobaseName:         cfg.InfobaseName,
 		Sessions:             sessionsData,
+		LastRestore:          h.lastRestore(ctx, cfg, log),
 	}
 
 	// Текстовый формат
@@ -258,6 +311,54 @@ func (h *ServiceModeStatusHandler) Execute(ctx context.Context, cfg *config.Conf
 	return writer.Write(os.Stdout, result)
 }
 
+// lastRestore возвращает последнее восстановление тестовой базы по msdb
+// сервера СУБД из dbconfig (graceful degradation: при ошибке — nil).
+func (h *ServiceModeStatusHandler) lastRestore(ctx context.Context, cfg *config.Config, log *slog.Logger) *LastRestoreData {
+	server := cfg.GetDbServer(cfg.InfobaseName)
+	if server == "" || cfg.IsProductionDb(cfg.InfobaseName) {
+		return nil
+	}
+
+	newClient := h.mssqlFactory
+	if newClient == nil {
+		if cfg.AppConfig == nil {
+			return nil
+		}
+		newClient = mssql.NewClient
+	}
+	client, err := newClient(mssqlutil.Options(cfg, server, lastRestoreTimeout))
+	if err != nil {
+		log.Warn("Не удалось создать MSSQL клиент", slog.String("error", err.Error()))
+		return nil
+	}
+
+	queryCtx, cancel := context.WithTimeout(ctx, lastRestoreTimeout)
+	defer cancel()
+	last, err := mssqlutil.LastRestore(queryCtx, client, cfg.InfobaseName)
+	if err != nil {
+		log.Warn("Не удалось получить последнее восстановление базы", slog.String("error", err.Error()))
+		return nil
+	}
+	if last == nil {
+		return nil
+	}
+	return &LastRestoreData{
+		RestoredAt: formatTime(last.RestoredAt),
+		DataAsOf:   formatTime(last.DataAsOf),
+		SrcServer:  last.SrcServer,
+		SrcDB:      last.SrcDB,
+	}
+}
+
+// formatTime возвращает время в RFC3339 или пустую строку,
+// если время не определено (RAC может не вернуть время).
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
 // writeError выводит структурированную ошибку и возвращает error.
 func (h *ServiceModeStatusHandler) writeError(format, traceID string, start time.Time, code, message string) error {
 	// Текстовый формат — человекочитаемый вывод ошибки, консистентный со стилем writeText
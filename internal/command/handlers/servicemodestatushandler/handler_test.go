@@ -4,12 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 	"testing"
 	"time"
 
+	"github.com/Kargones/apk-ci/internal/adapter/mssql"
+	"github.com/Kargones/apk-ci/internal/adapter/mssql/mssqltest"
 	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
 	"github.com/Kargones/apk-ci/internal/adapter/onec/rac/ractest"
 	"github.com/Kargones/apk-ci/internal/command"
+	"github.com/Kargones/apk-ci/internal/command/handlers/mssqlutil"
 	"github.com/Kargones/apk-ci/internal/command/handlers/racutil"
 	"github.com/Kargones/apk-ci/internal/config"
 	"github.com/Kargones/apk-ci/internal/constants"
@@ -337,6 +341,59 @@ func TestServiceModeStatusHandler_Execute_DisabledMode(t *testing.T) {
 
 // === Story 2.4: Session Info тесты ===
 
+func TestServiceModeStatusHandler_Execute_ScheduledWindow(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+
+	from := time.Now().Add(2 * time.Hour).Truncate(time.Second)
+	to := from.Add(4 * time.Hour)
+	mockClient := ractest.NewMockRACClient()
+	mockClient.GetServiceModeStatusFunc = func(_ context.Context, _, _ string) (*rac.ServiceModeStatus, error) {
+		return &rac.ServiceModeStatus{
+			Enabled:    true,
+			Message:    "Плановое обновление",
+			DeniedFrom: from,
+			DeniedTo:   to,
+		}, nil
+	}
+
+	h := &ServiceModeStatusHandler{racClient: mockClient}
+	cfg := &config.Config{InfobaseName: "TestBase"}
+
+	var execErr error
+	out := testutil.CaptureStdout(t, func() {
+		execErr = h.Execute(context.Background(), cfg)
+	})
+	require.NoError(t, execErr)
+
+	var result output.Result
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+	dataMap, ok := result.Data.(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, false, dataMap["enabled"], "окно ещё не началось")
+	assert.Equal(t, true, dataMap["scheduled"])
+	assert.Equal(t, from.Format(time.RFC3339), dataMap["denied_from"])
+	assert.Equal(t, to.Format(time.RFC3339), dataMap["denied_to"])
+}
+
+func TestServiceModeStatusHandler_Execute_ActiveWindow_TextOutput(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "text")
+
+	from := time.Now().Add(-time.Hour).Truncate(time.Second)
+	mockClient := ractest.NewMockRACClient()
+	mockClient.GetServiceModeStatusFunc = func(_ context.Context, _, _ string) (*rac.ServiceModeStatus, error) {
+		return &rac.ServiceModeStatus{Enabled: true, ScheduledJobsBlocked: true, DeniedFrom: from}, nil
+	}
+
+	h := &ServiceModeStatusHandler{racClient: mockClient}
+	cfg := &config.Config{InfobaseName: "TestBase"}
+
+	out := testutil.CaptureStdout(t, func() {
+		require.NoError(t, h.Execute(context.Background(), cfg))
+	})
+	assert.Contains(t, out, "Сервисный режим: ВКЛЮЧЁН")
+	assert.Contains(t, out, "Окно: "+from.Format(time.RFC3339)+" — до отключения")
+}
+
 func TestServiceModeStatusHandler_Execute_JSONOutput_WithSessions(t *testing.T) {
 	t.Setenv("BR_OUTPUT_FORMAT", "json")
 
@@ -574,6 +631,104 @@ func TestServiceModeStatusHandler_Execute_SessionsFetchError(t *testing.T) {
 	})
 }
 
+// ==== LAST RESTORE TESTS ====
+
+// TestServiceModeStatusHandler_Execute_LastRestore проверяет вывод последнего
+// восстановления тестовой базы и пропуск продуктивных баз и ошибок MSSQL.
+func TestServiceModeStatusHandler_Execute_LastRestore(t *testing.T) {
+	restoredAt := time.Date(2026, 10, 10, 9, 25, 0, 0, time.UTC)
+	dataAsOf := time.Date(2026, 10, 10, 3, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		prod       bool
+		connectErr error
+		want       *LastRestoreData
+	}{
+		{
+			name: "тестовая база",
+			want: &LastRestoreData{
+				RestoredAt: mssqlutil.ServerTime(restoredAt).Format(time.RFC3339),
+				DataAsOf:   mssqlutil.ServerTime(dataAsOf).Format(time.RFC3339),
+				SrcServer:  "srv-prod",
+				SrcDB:      "ERP",
+			},
+		},
+		{name: "продуктивная база", prod: true},
+		{name: "сервер СУБД недоступен", connectErr: fmt.Errorf("login failed")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("BR_OUTPUT_FORMAT", "json")
+
+			sql := mssqltest.NewMockMSSQLClient()
+			sql.ConnectFunc = func(context.Context) error { return tt.connectErr }
+			sql.GetLastRestoreFunc = func(_ context.Context, database string) (*mssql.LastRestore, error) {
+				return &mssql.LastRestore{
+					Database:   database,
+					RestoredAt: restoredAt,
+					DataAsOf:   dataAsOf,
+					SrcServer:  "srv-prod",
+					SrcDB:      "ERP",
+				}, nil
+			}
+			var server string
+			h := &ServiceModeStatusHandler{
+				racClient: ractest.NewMockRACClient(),
+				mssqlFactory: func(opts mssql.ClientOptions) (mssql.Client, error) {
+					server = opts.Server
+					return sql, nil
+				},
+			}
+			cfg := &config.Config{
+				InfobaseName: "TestBase",
+				DbConfig:     map[string]*config.DatabaseInfo{"TestBase": {DbServer: "srv-sql", Prod: tt.prod}},
+			}
+
+			var execErr error
+			out := testutil.CaptureStdout(t, func() {
+				execErr = h.Execute(context.Background(), cfg)
+			})
+			require.NoError(t, execErr)
+
+			var result struct {
+				output.Result
+				Data ServiceModeStatusData `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal([]byte(out), &result))
+			if tt.prod {
+				assert.Empty(t, server, "MSSQL не опрашивается для продуктивной базы")
+			}
+			if tt.want == nil {
+				assert.Nil(t, result.Data.LastRestore)
+				return
+			}
+			assert.Equal(t, "srv-sql", server)
+			assert.Equal(t, tt.want, result.Data.LastRestore)
+		})
+	}
+}
+
+// TestServiceModeStatusData_WriteText_LastRestore проверяет строку последнего восстановления.
+func TestServiceModeStatusData_WriteText_LastRestore(t *testing.T) {
+	data := &ServiceModeStatusData{
+		InfobaseName: "TestBase",
+		LastRestore: &LastRestoreData{
+			RestoredAt: "2026-10-10T09:25:00+03:00",
+			DataAsOf:   "2026-10-10T03:00:00+03:00",
+			SrcServer:  "srv-prod",
+			SrcDB:      "ERP",
+		},
+	}
+
+	out := testutil.CaptureStdout(t, func() {
+		require.NoError(t, data.writeText(os.Stdout))
+	})
+	assert.Contains(t, out,
+		"Последнее восстановление: 2026-10-10T09:25:00+03:00 (данные на 2026-10-10T03:00:00+03:00, источник srv-prod/ERP)")
+}
+
 // ==== PLAN-ONLY TESTS (Story 7.3) ====
 
 // TestServiceModeStatusHandler_PlanOnly проверяет что при BR_PLAN_ONLY=true
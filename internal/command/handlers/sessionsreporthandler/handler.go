@@ -0,0 +1,498 @@
+// Package sessionsreporthandler реализует NR-команду nr-sessions-report
+// для отчёта по сессиям информационных баз: количество сессий по пользователям
+// и приложениям, долго простаивающие сессии и занятые лицензии.
+package sessionsreporthandler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
+	"github.com/Kargones/apk-ci/internal/command"
+	"github.com/Kargones/apk-ci/internal/command/handlers/racutil"
+	errhandler "github.com/Kargones/apk-ci/internal/command/handlers/shared"
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/pkg/dryrun"
+	"github.com/Kargones/apk-ci/internal/pkg/metrics"
+	"github.com/Kargones/apk-ci/internal/pkg/output"
+	"github.com/Kargones/apk-ci/internal/pkg/tracing"
+)
+
+// defaultIdleMin — порог простоя сессии по умолчанию, минуты.
+const defaultIdleMin = 30
+
+// designerAppID — AppID сессии конфигуратора: простаивающий конфигуратор
+// может удерживать блокировку конфигурации и сорвать развёртывание.
+const designerAppID = "Designer"
+
+// Compile-time interface check.
+var _ command.Handler = (*SessionsReportHandler)(nil)
+
+func RegisterCmd() error {
+	return command.Register(&SessionsReportHandler{})
+}
+
+// Count — количество сессий по ключу (пользователь, приложение, тип лицензии).
+type Count struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// InfobaseReport — снимок сессий информационной базы.
+type InfobaseReport struct {
+	// Infobase — имя информационной базы
+	Infobase string `json:"infobase"`
+	// Server — сервер 1C
+	Server string `json:"server"`
+	// Sessions — количество сессий
+	Sessions int `json:"sessions"`
+	// Licenses — количество сессий, занимающих лицензию
+	Licenses int `json:"licenses"`
+	// Idle — количество сессий, простаивающих дольше порога
+	Idle int `json:"idle"`
+	// ByUser — сессии по пользователям
+	ByUser []Count `json:"by_user"`
+	// ByApp — сессии по приложениям (AppID)
+	ByApp []Count `json:"by_app"`
+}
+
+// IdleSession — сессия, простаивающая дольше порога.
+type IdleSession struct {
+	Infobase     string `json:"infobase"`
+	Server       string `json:"server"`
+	SessionID    string `json:"session_id"`
+	UserName     string `json:"user_name"`
+	AppID        string `json:"app_id"`
+	Host         string `json:"host"`
+	StartedAt    string `json:"started_at,omitempty"`
+	LastActiveAt string `json:"last_active_at,omitempty"`
+	// IdleMin — минут без активности
+	IdleMin int `json:"idle_min"`
+}
+
+// InfobaseError — ошибка получения сессий информационной базы.
+type InfobaseError struct {
+	Infobase string `json:"infobase"`
+	Server   string `json:"server"`
+	Error    string `json:"error"`
+}
+
+// SessionsReportData содержит данные ответа nr-sessions-report.
+type SessionsReportData struct {
+	// SampledAt — время снимка (ISO 8601)
+	SampledAt string `json:"sampled_at"`
+	// IdleThresholdMin — порог простоя, минуты
+	IdleThresholdMin int `json:"idle_threshold_min"`
+	// Infobases — снимки сессий по информационным базам
+	Infobases []InfobaseReport `json:"infobases"`
+	// TotalSessions — всего сессий
+	TotalSessions int `json:"total_sessions"`
+	// TotalLicenses — всего занятых лицензий
+	TotalLicenses int `json:"total_licenses"`
+	// ByUser — сессии по пользователям во всех базах
+	ByUser []Count `json:"by_user"`
+	// ByApp — сессии по приложениям во всех базах
+	ByApp []Count `json:"by_app"`
+	// LicensesByType — занятые лицензии по типу (soft, HASP)
+	LicensesByType []Count `json:"licenses_by_type"`
+	// IdleSessions — простаивающие сессии, от самой долгой
+	IdleSessions []IdleSession `json:"idle_sessions"`
+	// Errors — базы, сессии которых получить не удалось
+	Errors []InfobaseError `json:"errors,omitempty"`
+}
+
+// writeText выводит отчёт в человекочитаемом формате.
+func (d *SessionsReportData) writeText(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "Сессии информационных баз (порог простоя %d мин)\n", d.IdleThresholdMin); err != nil {
+		return err
+	}
+	for _, ib := range d.Infobases {
+		if _, err := fmt.Fprintf(w, "  %s (%s): сессий %d, лицензий %d, простаивают %d\n",
+			ib.Infobase, ib.Server, ib.Sessions, ib.Licenses, ib.Idle); err != nil {
+			return err
+		}
+		if ib.Sessions == 0 {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "    По приложениям: %s\n    По пользователям: %s\n",
+			formatCounts(ib.ByApp), formatCounts(ib.ByUser)); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(w, "Всего сессий: %d, лицензий: %d\n", d.TotalSessions, d.TotalLicenses); err != nil {
+		return err
+	}
+	if len(d.LicensesByType) > 0 {
+		if _, err := fmt.Fprintf(w, "Лицензии по типу: %s\n", formatCounts(d.LicensesByType)); err != nil {
+			return err
+		}
+	}
+
+	if len(d.IdleSessions) > 0 {
+		if _, err := fmt.Fprintf(w, "\nПростаивающие сессии:\n"); err != nil {
+			return err
+		}
+		for _, s := range d.IdleSessions {
+			mark := ""
+			if strings.EqualFold(s.AppID, designerAppID) {
+				mark = " [конфигуратор]"
+			}
+			if _, err := fmt.Fprintf(w, "  %s: %s, %s, %s — %d мин%s\n",
+				s.Infobase, s.UserName, s.AppID, s.Host, s.IdleMin, mark); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(d.Errors) > 0 {
+		if _, err := fmt.Fprintf(w, "\nОшибки:\n"); err != nil {
+			return err
+		}
+		for _, e := range d.Errors {
+			if _, err := fmt.Fprintf(w, "  %s (%s): %s\n", e.Infobase, e.Server, e.Error); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// SessionsReportHandler обрабатывает команду nr-sessions-report.
+// При заданном BR_INFOBASE_NAME отчёт строится по одной базе, иначе — по всем базам DbConfig.
+type SessionsReportHandler struct {
+	// clientFactory — опциональная фабрика RAC клиента по серверу (nil в production, mock в тестах)
+	clientFactory func(cfg *config.Config, server string) (rac.Client, error)
+	// now — опциональный источник текущего времени (nil в production)
+	now func() time.Time
+}
+
+// Name возвращает имя команды.
+func (h *SessionsReportHandler) Name() string {
+	return constants.ActNRSessionsReport
+}
+
+// Description возвращает описание команды для вывода в help.
+func (h *SessionsReportHandler) Description() string {
+	return "Отчёт по сессиям информационных баз: пользователи, приложения, простой, лицензии"
+}
+
+// Execute выполняет команду nr-sessions-report.
+func (h *SessionsReportHandler) Execute(ctx context.Context, cfg *config.Config) error {
+	start := time.Now()
+
+	traceID := tracing.TraceIDFromContext(ctx)
+	if traceID == "" {
+		traceID = tracing.GenerateTraceID()
+	}
+
+	format := os.Getenv("BR_OUTPUT_FORMAT")
+
+	// Plan-only не поддерживается; dry-run имеет приоритет над plan-only.
+	if !dryrun.IsDryRun() && dryrun.IsPlanOnly() {
+		return dryrun.WritePlanOnlyUnsupported(os.Stdout, constants.ActNRSessionsReport)
+	}
+
+	log := slog.Default().With(slog.String("trace_id", traceID), slog.String("command", constants.ActNRSessionsReport))
+
+	idleMin := defaultIdleMin
+	if v := os.Getenv(constants.EnvSessionsIdleMin); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			log.Error("Некорректный порог простоя", slog.String("value", v))
+			return h.writeError(format, traceID, start,
+				"CONFIG.INVALID_IDLE",
+				fmt.Sprintf("Некорректное значение %s: %q (ожидается положительное число минут)", constants.EnvSessionsIdleMin, v))
+		}
+		idleMin = n
+	}
+
+	servers := targetInfobases(cfg)
+	if len(servers) == 0 {
+		log.Error("Не определены информационные базы для отчёта")
+		return h.writeError(format, traceID, start,
+			"CONFIG.DBCONFIG_MISSING",
+			"Не определены информационные базы с сервером 1C (BR_INFOBASE_NAME или one-server в DbConfig)")
+	}
+
+	if err := racutil.RequireCapabilities(cfg, racutil.CapLicenses); err != nil {
+		log.Error("RAC клиент не поддерживает лицензии сессий", slog.String("error", err.Error()))
+		return h.writeError(format, traceID, start, racutil.ErrNativeUnsupported, err.Error())
+	}
+
+	newClient := h.clientFactory
+	if newClient == nil {
+		newClient = racutil.NewClientForServer
+	}
+	now := time.Now()
+	if h.now != nil {
+		now = h.now()
+	}
+	threshold := time.Duration(idleMin) * time.Minute
+
+	data := &SessionsReportData{
+		SampledAt:        now.Format(time.RFC3339),
+		IdleThresholdMin: idleMin,
+		Infobases:        make([]InfobaseReport, 0),
+		IdleSessions:     make([]IdleSession, 0),
+	}
+	byUser := make(map[string]int)
+	byApp := make(map[string]int)
+	byLicense := make(map[string]int)
+
+	serverNames := make([]string, 0, len(servers))
+	for server := range servers {
+		serverNames = append(serverNames, server)
+	}
+	sort.Strings(serverNames)
+
+	for _, server := range serverNames {
+		infobases := servers[server]
+		client, err := newClient(cfg, server)
+		var clusterInfo *rac.ClusterInfo
+		if err == nil {
+			clusterInfo, err = client.GetClusterInfo(ctx)
+		}
+		if err != nil {
+			log.Warn("Сервер 1C недоступен", slog.String("server", server), slog.String("error", err.Error()))
+			for _, name := range infobases {
+				data.Errors = append(data.Errors, InfobaseError{Infobase: name, Server: server, Error: err.Error()})
+			}
+			continue
+		}
+
+		for _, name := range infobases {
+			infobaseInfo, err := client.GetInfobaseInfo(ctx, clusterInfo.UUID, name)
+			var sessions []rac.SessionInfo
+			if err == nil {
+				sessions, err = client.GetSessions(ctx, clusterInfo.UUID, infobaseInfo.UUID)
+			}
+			if err != nil {
+				log.Warn("Не удалось получить сессии информационной базы",
+					slog.String("infobase", name), slog.String("error", err.Error()))
+				data.Errors = append(data.Errors, InfobaseError{Infobase: name, Server: server, Error: err.Error()})
+				continue
+			}
+
+			report := InfobaseReport{Infobase: name, Server: server, Sessions: len(sessions)}
+			ibUsers := make(map[string]int)
+			ibApps := make(map[string]int)
+			for _, s := range sessions {
+				ibUsers[s.UserName]++
+				ibApps[s.AppID]++
+				byUser[s.UserName]++
+				byApp[s.AppID]++
+
+				idle := idleFor(s, now)
+				if idle < threshold {
+					continue
+				}
+				report.Idle++
+				data.IdleSessions = append(data.IdleSessions, IdleSession{
+					Infobase:     name,
+					Server:       server,
+					SessionID:    s.SessionID,
+					UserName:     s.UserName,
+					AppID:        s.AppID,
+					Host:         s.Host,
+					StartedAt:    formatTime(s.StartedAt),
+					LastActiveAt: formatTime(s.LastActiveAt),
+					IdleMin:      int(idle / time.Minute),
+				})
+			}
+			report.ByUser = sortedCounts(ibUsers)
+			report.ByApp = sortedCounts(ibApps)
+
+			// Лицензии не критичны для отчёта: при ошибке учитываются как 0
+			if lp, ok := client.(rac.LicenseProvider); ok {
+				licenses, err := lp.GetSessionLicenses(ctx, clusterInfo.UUID, infobaseInfo.UUID)
+				if err != nil {
+					log.Warn("Не удалось получить лицензии сессий",
+						slog.String("infobase", name), slog.String("error", err.Error()))
+				}
+				report.Licenses = len(licenses)
+				for _, l := range licenses {
+					byLicense[l.LicenseType]++
+				}
+			}
+
+			data.TotalSessions += report.Sessions
+			data.TotalLicenses += report.Licenses
+			data.Infobases = append(data.Infobases, report)
+			recordSessions(cfg, report, ibApps)
+		}
+	}
+
+	if len(data.Infobases) == 0 {
+		log.Error("Не удалось получить сессии ни одной информационной базы")
+		return h.writeError(format, traceID, start,
+			"RAC.SESSIONS_FAILED",
+			fmt.Sprintf("Не удалось получить сессии ни одной информационной базы: %s", data.Errors[0].Error))
+	}
+
+	data.ByUser = sortedCounts(byUser)
+	data.ByApp = sortedCounts(byApp)
+	data.LicensesByType = sortedCounts(byLicense)
+	sort.SliceStable(data.IdleSessions, func(i, j int) bool {
+		return data.IdleSessions[i].IdleMin > data.IdleSessions[j].IdleMin
+	})
+
+	log.Info("Отчёт по сессиям сформирован",
+		slog.Int("infobases", len(data.Infobases)),
+		slog.Int("sessions", data.TotalSessions),
+		slog.Int("idle", len(data.IdleSessions)),
+		slog.Int("licenses", data.TotalLicenses),
+		slog.Int("errors", len(data.Errors)))
+
+	return h.writeSuccess(format, traceID, start, data)
+}
+
+// targetInfobases группирует базы отчёта по серверу 1C.
+// При заданном cfg.InfobaseName — только она (сервер из DbConfig или RacConfig),
+// иначе все базы DbConfig с one-server.
+func targetInfobases(cfg *config.Config) map[string][]string {
+	servers := make(map[string][]string)
+	if cfg == nil {
+		return servers
+	}
+	if cfg.InfobaseName != "" {
+		server := cfg.GetOneServer(cfg.InfobaseName)
+		if server == "" && cfg.RacConfig != nil {
+			server = cfg.RacConfig.RacServer
+		}
+		if server != "" {
+			servers[server] = []string{cfg.InfobaseName}
+		}
+		return servers
+	}
+	names := make([]string, 0, len(cfg.DbConfig))
+	for name := range cfg.DbConfig {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if info := cfg.DbConfig[name]; info != nil && info.OneServer != "" {
+			servers[info.OneServer] = append(servers[info.OneServer], name)
+		}
+	}
+	return servers
+}
+
+// idleFor возвращает время простоя сессии: от последней активности,
+// а при её отсутствии — от начала сессии. Без обоих времён простой не определён (0).
+func idleFor(s rac.SessionInfo, now time.Time) time.Duration {
+	last := s.LastActiveAt
+	if last.IsZero() {
+		last = s.StartedAt
+	}
+	if last.IsZero() || last.After(now) {
+		return 0
+	}
+	return now.Sub(last)
+}
+
+// recordSessions экспортирует gauges сессий базы в Prometheus,
+// если метрики включены (отправляются в Pushgateway по завершении команды).
+func recordSessions(cfg *config.Config, report InfobaseReport, byApp map[string]int) {
+	if cfg == nil || cfg.MetricsCollector == nil {
+		return
+	}
+	if recorder, ok := cfg.MetricsCollector.(metrics.SessionRecorder); ok {
+		recorder.RecordSessions(report.Infobase, metrics.SessionStats{
+			ByApp:    byApp,
+			Idle:     report.Idle,
+			Licenses: report.Licenses,
+		})
+	}
+}
+
+// sortedCounts преобразует map в список по убыванию количества (при равенстве — по имени).
+func sortedCounts(m map[string]int) []Count {
+	counts := make([]Count, 0, len(m))
+	for name, n := range m {
+		counts = append(counts, Count{Name: name, Count: n})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Name < counts[j].Name
+	})
+	return counts
+}
+
+// formatCounts форматирует список количеств как "имя N, имя M".
+func formatCounts(counts []Count) string {
+	parts := make([]string, 0, len(counts))
+	for _, c := range counts {
+		parts = append(parts, fmt.Sprintf("%s %d", c.Name, c.Count))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatTime возвращает время в RFC3339 или пустую строку для нулевого времени.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// writeSuccess выводит успешный результат.
+func (h *SessionsReportHandler) writeSuccess(format, traceID string, start time.Time, data *SessionsReportData) error {
+	if format != output.FormatJSON {
+		return data.writeText(os.Stdout)
+	}
+
+	result := &output.Result{
+		Status:  output.StatusSuccess,
+		Command: constants.ActNRSessionsReport,
+		Data:    data,
+		Metadata: &output.Metadata{
+			DurationMs: time.Since(start).Milliseconds(),
+			TraceID:    traceID,
+			APIVersion: constants.APIVersion,
+		},
+	}
+
+	writer := output.NewWriter(format)
+	return writer.Write(os.Stdout, result)
+}
+
+// writeError выводит структурированную ошибку и возвращает error.
+func (h *SessionsReportHandler) writeError(format, traceID string, start time.Time, code, message string) error {
+	// Текстовый формат — человекочитаемый вывод ошибки
+	if format != output.FormatJSON {
+		return errhandler.HandleError(message, code)
+	}
+
+	result := &output.Result{
+		Status:  output.StatusError,
+		Command: constants.ActNRSessionsReport,
+		Error: &output.ErrorInfo{
+			Code:    code,
+			Message: message,
+		},
+		Metadata: &output.Metadata{
+			DurationMs: time.Since(start).Milliseconds(),
+			TraceID:    traceID,
+			APIVersion: constants.APIVersion,
+		},
+	}
+
+	writer := output.NewWriter(format)
+	if writeErr := writer.Write(os.Stdout, result); writeErr != nil {
+		slog.Default().Error("Не удалось записать JSON-ответ об ошибке",
+			slog.String("trace_id", traceID),
+			slog.String("error", writeErr.Error()))
+	}
+
+	return fmt.Errorf("%s: %s", code, message)
+}
@@ -0,0 +1,230 @@
+package sessionsreporthandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac/ractest"
+	"github.com/Kargones/apk-ci/internal/command"
+	"github.com/Kargones/apk-ci/internal/command/handlers/racutil"
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/pkg/metrics"
+	"github.com/Kargones/apk-ci/internal/pkg/output"
+	"github.com/Kargones/apk-ci/internal/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var reportNow = time.Date(2026, 3, 14, 17, 0, 0, 0, time.Local)
+
+func TestSessionsReportHandler_Registration(t *testing.T) {
+	h, ok := command.Get(constants.ActNRSessionsReport)
+	require.True(t, ok)
+	assert.Equal(t, constants.ActNRSessionsReport, h.Name())
+	assert.NotEmpty(t, h.Description())
+}
+
+// newSessionsMock создаёт mock сервера 1C с сессиями баз sessions.
+func newSessionsMock(sessions map[string][]rac.SessionInfo) *ractest.MockRACClient {
+	mock := ractest.NewMockRACClient()
+	mock.GetInfobaseInfoFunc = func(_ context.Context, _, name string) (*rac.InfobaseInfo, error) {
+		if _, ok := sessions[name]; !ok {
+			return nil, errors.New("информационная база не найдена")
+		}
+		return &rac.InfobaseInfo{UUID: name, Name: name}, nil
+	}
+	mock.GetSessionsFunc = func(_ context.Context, _, uuid string) ([]rac.SessionInfo, error) {
+		return sessions[uuid], nil
+	}
+	mock.GetSessionLicensesFunc = func(_ context.Context, _, uuid string) ([]rac.SessionLicense, error) {
+		var licenses []rac.SessionLicense
+		for _, s := range sessions[uuid] {
+			if s.AppID != "BackgroundJob" {
+				licenses = append(licenses, rac.SessionLicense{SessionID: s.SessionID, LicenseType: "soft"})
+			}
+		}
+		return licenses, nil
+	}
+	return mock
+}
+
+func session(id, user, app string, idle time.Duration) rac.SessionInfo {
+	return rac.SessionInfo{
+		SessionID:    id,
+		UserName:     user,
+		AppID:        app,
+		Host:         "ws-" + id,
+		StartedAt:    reportNow.Add(-8 * time.Hour),
+		LastActiveAt: reportNow.Add(-idle),
+	}
+}
+
+// recordingCollector — Collector, запоминающий gauges сессий.
+type recordingCollector struct {
+	*metrics.NopCollector
+	stats map[string]metrics.SessionStats
+}
+
+func (c *recordingCollector) RecordSessions(infobase string, stats metrics.SessionStats) {
+	c.stats[infobase] = stats
+}
+
+func reportConfig() *config.Config {
+	return &config.Config{DbConfig: map[string]*config.DatabaseInfo{
+		"erp":      {OneServer: "srv-a"},
+		"zup":      {OneServer: "srv-a"},
+		"offline":  {OneServer: "srv-b"},
+		"sql_only": {DbServer: "sql-1"},
+	}}
+}
+
+func newHandler(clients map[string]rac.Client) *SessionsReportHandler {
+	return &SessionsReportHandler{
+		clientFactory: func(_ *config.Config, server string) (rac.Client, error) {
+			if c, ok := clients[server]; ok {
+				return c, nil
+			}
+			return nil, errors.New("connection refused")
+		},
+		now: func() time.Time { return reportNow },
+	}
+}
+
+func run(t *testing.T, h *SessionsReportHandler, cfg *config.Config) (string, error) {
+	t.Helper()
+	var execErr error
+	out := testutil.CaptureStdout(t, func() {
+		execErr = h.Execute(context.Background(), cfg)
+	})
+	return out, execErr
+}
+
+func TestSessionsReportHandler_Execute_JSONOutput(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+	t.Setenv(constants.EnvSessionsIdleMin, "60")
+
+	h := newHandler(map[string]rac.Client{
+		"srv-a": newSessionsMock(map[string][]rac.SessionInfo{
+			"erp": {
+				session("1", "Иванов", "1CV8C", 5*time.Minute),
+				session("2", "Иванов", "1CV8C", 2*time.Hour),
+				session("3", "deploy", "Designer", 3*time.Hour),
+				session("4", "", "BackgroundJob", 0),
+			},
+			"zup": {session("5", "Петров", "1CV8C", 61*time.Minute)},
+		}),
+	})
+	collector := &recordingCollector{NopCollector: metrics.NewNopCollector(), stats: map[string]metrics.SessionStats{}}
+	cfg := reportConfig()
+	cfg.MetricsCollector = collector
+
+	out, err := run(t, h, cfg)
+	require.NoError(t, err)
+
+	var result struct {
+		Status string             `json:"status"`
+		Data   SessionsReportData `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &result), out)
+	assert.Equal(t, output.StatusSuccess, result.Status)
+
+	data := result.Data
+	assert.Equal(t, 60, data.IdleThresholdMin)
+	assert.Equal(t, 5, data.TotalSessions)
+	assert.Equal(t, 4, data.TotalLicenses)
+	require.Len(t, data.Infobases, 2)
+	assert.Equal(t, InfobaseReport{
+		Infobase: "erp", Server: "srv-a", Sessions: 4, Licenses: 3, Idle: 2,
+		ByUser: []Count{{"Иванов", 2}, {"", 1}, {"deploy", 1}},
+		ByApp:  []Count{{"1CV8C", 2}, {"BackgroundJob", 1}, {"Designer", 1}},
+	}, data.Infobases[0])
+	assert.Equal(t, []Count{{"1CV8C", 3}, {"BackgroundJob", 1}, {"Designer", 1}}, data.ByApp)
+	assert.Equal(t, []Count{{"soft", 4}}, data.LicensesByType)
+
+	require.Len(t, data.IdleSessions, 3)
+	assert.Equal(t, "3", data.IdleSessions[0].SessionID, "самая долгая сессия первой")
+	assert.Equal(t, 180, data.IdleSessions[0].IdleMin)
+	assert.Equal(t, "Designer", data.IdleSessions[0].AppID)
+	assert.Equal(t, "5", data.IdleSessions[2].SessionID)
+
+	require.Len(t, data.Errors, 1)
+	assert.Equal(t, InfobaseError{Infobase: "offline", Server: "srv-b", Error: "connection refused"}, data.Errors[0])
+
+	assert.Equal(t, metrics.SessionStats{
+		ByApp:    map[string]int{"1CV8C": 2, "BackgroundJob": 1, "Designer": 1},
+		Idle:     2,
+		Licenses: 3,
+	}, collector.stats["erp"])
+	assert.Equal(t, 1, collector.stats["zup"].Licenses)
+}
+
+func TestSessionsReportHandler_Execute_TextOutput(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "text")
+
+	h := newHandler(map[string]rac.Client{
+		"srv-a": newSessionsMock(map[string][]rac.SessionInfo{
+			"erp": {
+				session("1", "Иванов", "1CV8C", 5*time.Minute),
+				session("3", "deploy", "Designer", 95*time.Minute),
+			},
+		}),
+	})
+	cfg := reportConfig()
+	cfg.InfobaseName = "erp"
+
+	out, err := run(t, h, cfg)
+	require.NoError(t, err)
+	assert.Contains(t, out, "порог простоя 30 мин")
+	assert.Contains(t, out, "erp (srv-a): сессий 2, лицензий 2, простаивают 1")
+	assert.Contains(t, out, "По приложениям: 1CV8C 1, Designer 1")
+	assert.Contains(t, out, "erp: deploy, Designer, ws-3 — 95 мин [конфигуратор]")
+	assert.NotContains(t, out, "zup")
+}
+
+func TestSessionsReportHandler_Execute_InvalidIdle(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+	t.Setenv(constants.EnvSessionsIdleMin, "0")
+
+	_, err := run(t, newHandler(nil), reportConfig())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "CONFIG.INVALID_IDLE")
+}
+
+func TestSessionsReportHandler_Execute_NoInfobases(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+
+	_, err := run(t, newHandler(nil), &config.Config{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "CONFIG.DBCONFIG_MISSING")
+}
+
+func TestSessionsReportHandler_Execute_NativeUnsupported(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+	cfg := reportConfig()
+	cfg.ImplementationsConfig = &config.ImplementationsConfig{RAC: "native"}
+
+	_, err := run(t, newHandler(nil), cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), racutil.ErrNativeUnsupported)
+}
+
+func TestSessionsReportHandler_Execute_AllFailed(t *testing.T) {
+	t.Setenv("BR_OUTPUT_FORMAT", "json")
+
+	out, err := run(t, newHandler(nil), reportConfig())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "RAC.SESSIONS_FAILED")
+	assert.Contains(t, out, "connection refused")
+}
+
+func TestIdleFor(t *testing.T) {
+	assert.Equal(t, time.Hour, idleFor(rac.SessionInfo{LastActiveAt: reportNow.Add(-time.Hour)}, reportNow))
+	assert.Equal(t, 2*time.Hour, idleFor(rac.SessionInfo{StartedAt: reportNow.Add(-2 * time.Hour)}, reportNow))
+	assert.Zero(t, idleFor(rac.SessionInfo{}, reportNow))
+	assert.Zero(t, idleFor(rac.SessionInfo{LastActiveAt: reportNow.Add(time.Minute)}, reportNow))
+}
@@ -56,6 +56,32 @@ type ExtensionLoadResult struct {
 	Error string `json:"error,omitempty"`
 }
 
+// extensionLoadResults формирует результаты загрузки расширений из результатов
+// пула convert.Config. Если convert.Config не вернул результатов (например, loader
+// в тестах), успешно загруженными считаются все расширения fallback.
+func extensionLoadResults(cc *convert.Config, fallback []string) []ExtensionLoadResult {
+	var results []ExtensionLoadResult
+	if cc != nil {
+		for _, r := range cc.ExtensionResults() {
+			results = append(results, ExtensionLoadResult{
+				Name:    r.Name,
+				Success: r.Success,
+				Error:   r.Error,
+			})
+		}
+	}
+	if len(results) > 0 {
+		return results
+	}
+	for _, ext := range fallback {
+		results = append(results, ExtensionLoadResult{
+			Name:    ext,
+			Success: true,
+		})
+	}
+	return results
+}
+
 // writeText выводит результат в человекочитаемом формате.
 func (d *Store2DbData) writeText(w io.Writer) error {
 	statusText := "успешно"
@@ -211,11 +237,13 @@ func (h *Store2DbHandler) Execute(ctx context.Context, cfg *config.Config) error
 	log.Info("applying: применение конфигурации к базе данных")
 
 	// Выполнение привязки хранилища (основная конфигурация + расширения)
-	// H-2 fix: StoreBind обрабатывает main + extensions внутренне.
-	// При ошибке невозможно определить какие расширения успешны (legacy API ограничение).
+	// H-2 fix: StoreBind обрабатывает main + extensions внутренне,
+	// результаты по каждому расширению доступны через cc.ExtensionResults().
 	err = loader.StoreBind(ctxPtr, cc, log, cfg)
 	if err != nil {
-		log.Error("Ошибка привязки хранилища", slog.String("error", err.Error()))
+		log.Error("Ошибка привязки хранилища",
+			slog.String("error", err.Error()),
+			slog.Any("extensions", extensionLoadResults(cc, nil)))
 		return h.writeError(format, traceID, start, "ERR_STORE_OP", err.Error())
 	}
 
@@ -230,17 +258,7 @@ func (h *Store2DbHandler) Execute(ctx context.Context, cfg *config.Config) error
 	}
 
 	// Добавление результатов загрузки расширений (AC-7)
-	// H-2 note: если StoreBind успешен, все расширения загружены успешно
-	// (legacy API не предоставляет детализацию по каждому расширению)
-	if len(cfg.AddArray) > 0 {
-		data.ExtensionsLoaded = make([]ExtensionLoadResult, 0, len(cfg.AddArray))
-		for _, ext := range cfg.AddArray {
-			data.ExtensionsLoaded = append(data.ExtensionsLoaded, ExtensionLoadResult{
-				Name:    ext,
-				Success: true,
-			})
-		}
-	}
+	data.ExtensionsLoaded = extensionLoadResults(cc, cfg.AddArray)
 
 	log.Info("Загрузка конфигурации из хранилища завершена",
 		slog.Bool("state_changed", data.StateChanged),
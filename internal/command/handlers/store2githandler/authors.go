@@ -0,0 +1,70 @@
+package store2githandler
+
+import (
+	"fmt"
+	"net/mail"
+	"os"
+	"strings"
+
+	"github.com/Kargones/apk-ci/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultEmailDomain — домен адреса автора, если GitConfig.UserEmail не задан.
+const defaultEmailDomain = "localhost"
+
+// authorMap — соответствие пользователей хранилища авторам git ("Имя <email>").
+type authorMap map[string]string
+
+// loadAuthors читает YAML-файл соответствия авторов (BR_STORE2GIT_AUTHORS):
+//
+//	Иванов: Иван Иванов <ivanov@example.com>
+//
+// Пустой путь — соответствие не задано.
+func loadAuthors(path string) (authorMap, error) {
+	authors := authorMap{}
+	if path == "" {
+		return authors, nil
+	}
+	raw, err := os.ReadFile(path) //nolint:gosec // путь задаётся пользователем CI
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать файл авторов: %w", err)
+	}
+	if err := yaml.Unmarshal(raw, &authors); err != nil {
+		return nil, fmt.Errorf("некорректный файл авторов %s: %w", path, err)
+	}
+	for user, author := range authors {
+		if _, err := mail.ParseAddress(author); err != nil {
+			return nil, fmt.Errorf("некорректный автор %q для пользователя %s: ожидается \"Имя <email>\"", author, user)
+		}
+	}
+	return authors, nil
+}
+
+// author возвращает автора git для пользователя хранилища. Пользователь,
+// отсутствующий в соответствии, получает адрес <пользователь>@<домен>.
+func (a authorMap) author(user, domain string) string {
+	if author, ok := a[user]; ok {
+		return author
+	}
+	for name, author := range a {
+		if strings.EqualFold(name, user) {
+			return author
+		}
+	}
+	name := strings.TrimSpace(user)
+	if name == "" {
+		name = "unknown"
+	}
+	return fmt.Sprintf("%s <%s@%s>", name, strings.Join(strings.Fields(name), "."), domain)
+}
+
+// emailDomain возвращает домен адресов авторов из GitConfig.UserEmail.
+func emailDomain(cfg *config.Config) string {
+	if cfg.GitConfig != nil {
+		if _, domain, ok := strings.Cut(cfg.GitConfig.UserEmail, "@"); ok && domain != "" {
+			return domain
+		}
+	}
+	return defaultEmailDomain
+}
@@ -0,0 +1,513 @@
+// Package store2githandler реализует NR-команду nr-store2git
+// для выгрузки истории хранилища конфигурации 1C в git.
+package store2githandler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Kargones/apk-ci/internal/command"
+	"github.com/Kargones/apk-ci/internal/command/handlers/shared"
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/entity/one/store"
+	"github.com/Kargones/apk-ci/internal/pkg/dryrun"
+	"github.com/Kargones/apk-ci/internal/pkg/output"
+	"github.com/Kargones/apk-ci/internal/pkg/tracing"
+)
+
+const (
+	// defaultBranch — ветка выгрузки по умолчанию (BR_STORE2GIT_BRANCH).
+	defaultBranch = "store2git"
+	// tagPrefix — префикс тегов, отмечающих выгруженные версии хранилища.
+	// Тег версии N основной конфигурации — store/vN, расширения — store/<имя>/vN.
+	tagPrefix = "store/"
+)
+
+// Compile-time interface check.
+var _ command.Handler = (*Store2GitHandler)(nil)
+
+func RegisterCmd() error {
+	return command.Register(&Store2GitHandler{})
+}
+
+// ExportedVersion — версия хранилища, выгруженная в git.
+type ExportedVersion struct {
+	// Version — номер версии хранилища
+	Version int `json:"version"`
+	// Commit — хеш созданного git-коммита
+	Commit string `json:"commit"`
+	// Author — автор коммита
+	Author string `json:"author"`
+	// Date — дата создания версии
+	Date time.Time `json:"date"`
+}
+
+// Store2GitData содержит данные ответа nr-store2git.
+type Store2GitData struct {
+	// StateChanged — созданы ли новые коммиты
+	StateChanged bool `json:"state_changed"`
+	// DryRun — выполнен ли только расчёт выгружаемых версий
+	DryRun bool `json:"dry_run,omitempty"`
+	// Branch — ветка выгрузки
+	Branch string `json:"branch"`
+	// Extension — расширение (пусто для основной конфигурации)
+	Extension string `json:"extension,omitempty"`
+	// Store — хранилище из заголовка отчёта
+	Store string `json:"store,omitempty"`
+	// PreviousVersion — последняя выгруженная версия до запуска (0 — выгрузок не было)
+	PreviousVersion int `json:"previous_version"`
+	// LastVersion — последняя выгруженная версия после запуска
+	LastVersion int `json:"last_version"`
+	// Exported — выгруженные версии
+	Exported []ExportedVersion `json:"exported"`
+	// Planned — версии, которые будут выгружены (dry-run)
+	Planned []int `json:"planned,omitempty"`
+	// Skipped — версии, созданные из git (nr-git2store): не выгружаются,
+	// тег ставится на их исходный коммит
+	Skipped []int `json:"skipped,omitempty"`
+	// Remaining — версии, не выгруженные из-за BR_STORE2GIT_LIMIT
+	Remaining int `json:"remaining"`
+	// DurationMs — длительность операции в миллисекундах
+	DurationMs int64 `json:"duration_ms"`
+}
+
+// writeText выводит результат в человекочитаемом формате.
+func (d *Store2GitData) writeText(w io.Writer) error {
+	title := "Выгрузка истории хранилища в git"
+	if d.DryRun {
+		title += " (dry-run)"
+	}
+	if _, err := fmt.Fprintf(w, "%s: ветка %s\n", title, d.Branch); err != nil {
+		return err
+	}
+	if d.Extension != "" {
+		if _, err := fmt.Fprintf(w, "Расширение: %s\n", d.Extension); err != nil {
+			return err
+		}
+	}
+	if d.DryRun {
+		if _, err := fmt.Fprintf(w, "К выгрузке версий: %d %v\n", len(d.Planned), d.Planned); err != nil {
+			return err
+		}
+	} else {
+		if _, err := fmt.Fprintf(w, "Выгружено версий: %d (версии %d → %d)\n", len(d.Exported), d.PreviousVersion, d.LastVersion); err != nil {
+			return err
+		}
+		for _, v := range d.Exported {
+			if _, err := fmt.Fprintf(w, "  %d → %.12s %s\n", v.Version, v.Commit, v.Author); err != nil {
+				return err
+			}
+		}
+	}
+	if len(d.Skipped) > 0 {
+		if _, err := fmt.Fprintf(w, "Версии из git (тег на исходный коммит): %v\n", d.Skipped); err != nil {
+			return err
+		}
+	}
+	if d.Remaining > 0 {
+		if _, err := fmt.Fprintf(w, "Осталось версий: %d\n", d.Remaining); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// VersionCommit — параметры коммита одной версии хранилища.
+type VersionCommit struct {
+	// Message — сообщение коммита
+	Message string
+	// Author — автор в формате "Имя <email>"
+	Author string
+	// Date — дата авторства и фиксации
+	Date time.Time
+	// Tag — тег, отмечающий выгруженную версию
+	Tag string
+	// TagMessage — сообщение тега
+	TagMessage string
+}
+
+// Repository — git-репозиторий, в который выгружается история (для тестируемости).
+type Repository interface {
+	// Open клонирует репозиторий и переключается на ветку branch, создавая её при отсутствии
+	Open(ctx context.Context, l *slog.Logger, cfg *config.Config, branch string) error
+	// Path возвращает путь к рабочему каталогу репозитория
+	Path() string
+	// ListTags возвращает имена тегов по шаблону git tag --list
+	ListTags(ctx context.Context, pattern string) ([]string, error)
+	// Commit фиксирует содержимое рабочего каталога и ставит тег версии
+	Commit(ctx context.Context, l *slog.Logger, c VersionCommit) (string, error)
+	// TagVersion ставит тег версии на существующий коммит commit
+	TagVersion(ctx context.Context, l *slog.Logger, tag, commit, message string) error
+	// Push отправляет ветку и теги в origin
+	Push(ctx context.Context, l *slog.Logger) error
+}
+
+// StoreSource — хранилище конфигурации, из которого выгружаются версии (для тестируемости).
+type StoreSource interface {
+	// Open создаёт временную базу и подключает её к хранилищу
+	Open(ctx context.Context, l *slog.Logger, cfg *config.Config, extension string) error
+	// Report формирует отчёт хранилища начиная с версии fromVersion
+	Report(ctx context.Context, l *slog.Logger, cfg *config.Config, fromVersion int) (*store.Report, error)
+	// Checkout обновляет конфигурацию временной базы до версии хранилища
+	Checkout(ctx context.Context, l *slog.Logger, cfg *config.Config, version int) error
+	// Export выгружает конфигурацию временной базы в каталог outputPath
+	Export(ctx context.Context, l *slog.Logger, cfg *config.Config, outputPath string) error
+	// Close удаляет временную базу
+	Close(l *slog.Logger)
+}
+
+// Store2GitHandler обрабатывает команду nr-store2git.
+//
+// Для каждой ещё не выгруженной версии хранилища конфигурация временной базы
+// обновляется до этой версии, выгружается в src/cfg (src/cfe/<имя> для расширения)
+// и фиксируется коммитом с автором, датой и комментарием версии. Выгруженная
+// версия отмечается тегом store/vN, по которому следующий запуск продолжает выгрузку.
+// Версии, созданные из git (nr-git2store), не выгружаются: тег store/vN ставится
+// на их исходный коммит, чтобы отметка выгрузки оставалась монотонной.
+type Store2GitHandler struct {
+	// repo — опциональный git-репозиторий (nil в production, mock в тестах)
+	repo Repository
+	// source — опциональный источник версий (nil в production, mock в тестах)
+	source StoreSource
+}
+
+// Name возвращает имя команды.
+func (h *Store2GitHandler) Name() string {
+	return constants.ActNRStore2git
+}
+
+// Description возвращает описание команды для вывода в help.
+func (h *Store2GitHandler) Description() string {
+	return "Выгрузка истории хранилища конфигурации 1C в коммиты git"
+}
+
+// Execute выполняет команду nr-store2git.
+func (h *Store2GitHandler) Execute(ctx context.Context, cfg *config.Config) error {
+	start := time.Now()
+
+	traceID := tracing.TraceIDFromContext(ctx)
+	if traceID == "" {
+		traceID = tracing.GenerateTraceID()
+	}
+
+	format := os.Getenv("BR_OUTPUT_FORMAT")
+
+	// Plan-only не поддерживается; dry-run имеет приоритет над plan-only.
+	if !dryrun.IsDryRun() && dryrun.IsPlanOnly() {
+		return dryrun.WritePlanOnlyUnsupported(os.Stdout, constants.ActNRStore2git)
+	}
+
+	log := slog.Default().With(
+		slog.String("trace_id", traceID),
+		slog.String("command", constants.ActNRStore2git),
+	)
+
+	if cfg == nil || cfg.Owner == "" || cfg.Repo == "" {
+		return h.writeError(format, traceID, start, shared.ErrMissingOwnerRepo,
+			"Не указан репозиторий (owner/repo)")
+	}
+
+	branch := os.Getenv(constants.EnvStore2gitBranch)
+	if branch == "" {
+		branch = defaultBranch
+	}
+	extension := os.Getenv(constants.EnvStore2gitExtension)
+	limit := 0
+	if v := os.Getenv(constants.EnvStore2gitLimit); v != "" {
+		var err error
+		if limit, err = strconv.Atoi(v); err != nil || limit < 0 {
+			return h.writeError(format, traceID, start, "CONFIG.INVALID_PARAM",
+				fmt.Sprintf("некорректное значение %s: %s", constants.EnvStore2gitLimit, v))
+		}
+	}
+	authors, err := loadAuthors(os.Getenv(constants.EnvStore2gitAuthors))
+	if err != nil {
+		return h.writeError(format, traceID, start, "CONFIG.INVALID_PARAM", err.Error())
+	}
+
+	log = log.With(slog.String("branch", branch), slog.String("extension", extension))
+	data := &Store2GitData{Branch: branch, Extension: extension, Exported: []ExportedVersion{}, DryRun: dryrun.IsDryRun()}
+
+	repo := h.repo
+	if repo == nil {
+		repo = &gitRepository{}
+	}
+	log.Info("Клонирование репозитория")
+	if err := repo.Open(ctx, log, cfg, branch); err != nil {
+		log.Error("Ошибка подготовки репозитория", slog.String("error", err.Error()))
+		return h.writeError(format, traceID, start, "GIT.CLONE_FAILED", err.Error())
+	}
+
+	prefix := versionTagPrefix(extension)
+	tags, err := repo.ListTags(ctx, prefix+"*")
+	if err != nil {
+		return h.writeError(format, traceID, start, "GIT.TAGS_FAILED", err.Error())
+	}
+	data.PreviousVersion = lastExportedVersion(tags, prefix)
+	data.LastVersion = data.PreviousVersion
+	log.Info("Последняя выгруженная версия хранилища", slog.Int("version", data.PreviousVersion))
+
+	source := h.source
+	if source == nil {
+		source = &storeSource{}
+	}
+	log.Info("Подключение временной базы к хранилищу")
+	defer source.Close(log)
+	if err := source.Open(ctx, log, cfg, extension); err != nil {
+		log.Error("Ошибка подключения к хранилищу", slog.String("error", err.Error()))
+		return h.writeError(format, traceID, start, "STORE.BIND_FAILED", err.Error())
+	}
+
+	rep, err := source.Report(ctx, log, cfg, data.PreviousVersion+1)
+	if err != nil {
+		log.Error("Ошибка формирования отчета хранилища", slog.String("error", err.Error()))
+		return h.writeError(format, traceID, start, "STORE.REPORT_FAILED", err.Error())
+	}
+	data.Store = rep.Store
+
+	pending, remaining := pendingVersions(rep, data.PreviousVersion, limit)
+	data.Remaining = remaining
+	for _, v := range pending {
+		if v.GitCommit != "" {
+			data.Skipped = append(data.Skipped, v.Number)
+		} else if data.DryRun {
+			data.Planned = append(data.Planned, v.Number)
+		}
+	}
+
+	if data.DryRun {
+		data.DurationMs = time.Since(start).Milliseconds()
+		return h.writeSuccess(format, traceID, start, data)
+	}
+
+	outputPath := filepath.Join(repo.Path(), sourceRelPath(extension))
+	domain := emailDomain(cfg)
+	for _, v := range pending {
+		vlog := log.With(slog.Int("version", v.Number))
+		var code string
+		var err error
+		if v.GitCommit != "" {
+			code, err = tagSkippedVersion(ctx, vlog, repo, v, prefix, data)
+		} else {
+			code, err = h.exportVersion(ctx, vlog, cfg, repo, source, v, outputPath, prefix, authors.author(v.User, domain), data)
+		}
+		if err != nil {
+			vlog.Error("Ошибка выгрузки версии хранилища", slog.String("error", err.Error()))
+			// Уже созданные коммиты и теги отправляются, чтобы следующий запуск продолжил с места ошибки.
+			if data.LastVersion > data.PreviousVersion {
+				if pushErr := repo.Push(ctx, log); pushErr != nil {
+					log.Error("Не удалось отправить выгруженные версии", slog.String("error", pushErr.Error()))
+				}
+			}
+			return h.writeError(format, traceID, start, code,
+				fmt.Sprintf("версия %d: %s (выгружено версий: %d)", v.Number, err.Error(), len(data.Exported)))
+		}
+	}
+
+	if data.LastVersion > data.PreviousVersion {
+		log.Info("Отправка выгруженных версий",
+			slog.Int("count", len(data.Exported)),
+			slog.Int("skipped", len(data.Skipped)))
+		if err := repo.Push(ctx, log); err != nil {
+			log.Error("Ошибка отправки изменений", slog.String("error", err.Error()))
+			return h.writeError(format, traceID, start, "GIT.PUSH_FAILED", err.Error())
+		}
+		data.StateChanged = true
+	}
+	data.DurationMs = time.Since(start).Milliseconds()
+
+	log.Info("Выгрузка истории хранилища завершена",
+		slog.Int("exported", len(data.Exported)),
+		slog.Int("last_version", data.LastVersion),
+		slog.Int("remaining", data.Remaining))
+
+	return h.writeSuccess(format, traceID, start, data)
+}
+
+// exportVersion выгружает одну версию хранилища и фиксирует её коммитом.
+// Возвращает код ошибки для ответа.
+func (h *Store2GitHandler) exportVersion(ctx context.Context, log *slog.Logger, cfg *config.Config, repo Repository, source StoreSource,
+	v store.ReportVersion, outputPath, prefix, author string, data *Store2GitData) (string, error) {
+	log.Info("Получение версии хранилища")
+	if err := source.Checkout(ctx, log, cfg, v.Number); err != nil {
+		return "STORE.CHECKOUT_FAILED", err
+	}
+	// Каталог выгрузки очищается, чтобы удалённые из конфигурации объекты попали в коммит.
+	if err := os.RemoveAll(outputPath); err != nil {
+		return "STORE.EXPORT_FAILED", err
+	}
+	if err := os.MkdirAll(outputPath, constants.DirPermStandard); err != nil {
+		return "STORE.EXPORT_FAILED", err
+	}
+	if err := source.Export(ctx, log, cfg, outputPath); err != nil {
+		return "STORE.EXPORT_FAILED", err
+	}
+	hash, err := repo.Commit(ctx, log, VersionCommit{
+		Message:    commitMessage(v),
+		Author:     author,
+		Date:       v.Date,
+		Tag:        prefix + strconv.Itoa(v.Number),
+		TagMessage: fmt.Sprintf("Версия хранилища %d", v.Number),
+	})
+	if err != nil {
+		return "GIT.COMMIT_FAILED", err
+	}
+	data.Exported = append(data.Exported, ExportedVersion{Version: v.Number, Commit: hash, Author: author, Date: v.Date})
+	data.LastVersion = v.Number
+	return "", nil
+}
+
+// tagSkippedVersion отмечает версию, созданную из git, тегом на её исходном коммите.
+// Без тега последняя выгруженная версия отставала бы от хранилища, а версия
+// из git, оказавшаяся последней, предлагалась бы к выгрузке при каждом запуске.
+func tagSkippedVersion(ctx context.Context, log *slog.Logger, repo Repository, v store.ReportVersion,
+	prefix string, data *Store2GitData) (string, error) {
+	log.Info("Версия создана из git, выгрузка не требуется", slog.String("commit", v.GitCommit))
+	tag := prefix + strconv.Itoa(v.Number)
+	if err := repo.TagVersion(ctx, log, tag, v.GitCommit, fmt.Sprintf("Версия хранилища %d (из git)", v.Number)); err != nil {
+		return "GIT.TAG_FAILED", err
+	}
+	data.LastVersion = v.Number
+	return "", nil
+}
+
+// versionTagPrefix возвращает префикс тегов выгруженных версий.
+func versionTagPrefix(extension string) string {
+	if extension == "" {
+		return tagPrefix + "v"
+	}
+	return tagPrefix + extension + "/v"
+}
+
+// lastExportedVersion возвращает максимальный номер версии среди тегов с префиксом prefix.
+func lastExportedVersion(tags []string, prefix string) int {
+	last := 0
+	for _, tag := range tags {
+		n, err := strconv.Atoi(strings.TrimPrefix(tag, prefix))
+		if err != nil || !strings.HasPrefix(tag, prefix) {
+			continue
+		}
+		last = max(last, n)
+	}
+	return last
+}
+
+// pendingVersions возвращает версии после previous по возрастанию и количество
+// версий, не вошедших из-за limit (0 — без ограничения).
+//
+// Версии, созданные из git (nr-git2store), входят в результат для тега, но не
+// учитываются в limit. Результат обрывается перед первой выгружаемой версией
+// сверх limit: тег более поздней версии из git сдвинул бы отметку выгрузки
+// за невыгруженную версию.
+func pendingVersions(rep *store.Report, previous, limit int) ([]store.ReportVersion, int) {
+	var versions []store.ReportVersion
+	for _, v := range rep.Versions {
+		if v.Number > previous {
+			versions = append(versions, v)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Number < versions[j].Number })
+
+	exports := 0
+	for i, v := range versions {
+		if v.GitCommit != "" {
+			continue
+		}
+		if limit > 0 && exports == limit {
+			remaining := 0
+			for _, rest := range versions[i:] {
+				if rest.GitCommit == "" {
+					remaining++
+				}
+			}
+			return versions[:i], remaining
+		}
+		exports++
+	}
+	return versions, 0
+}
+
+// sourceRelPath возвращает каталог исходников в репозитории (как в convert.LoadFromConfig).
+func sourceRelPath(extension string) string {
+	if extension == "" {
+		return filepath.Join("src", "cfg")
+	}
+	return filepath.Join("src", "cfe", extension)
+}
+
+// commitMessage формирует сообщение коммита из комментария и реквизитов версии.
+func commitMessage(v store.ReportVersion) string {
+	subject := strings.TrimSpace(v.Comment)
+	if subject == "" {
+		subject = fmt.Sprintf("Версия хранилища %d", v.Number)
+	}
+	lines := []string{subject, "", fmt.Sprintf("Версия хранилища: %d", v.Number)}
+	if v.ConfVersion != "" {
+		lines = append(lines, "Версия конфигурации: "+v.ConfVersion)
+	}
+	lines = append(lines, "Пользователь хранилища: "+v.User)
+	for _, label := range v.Labels {
+		lines = append(lines, "Метка: "+label.Name)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// writeSuccess выводит успешный результат.
+func (h *Store2GitHandler) writeSuccess(format, traceID string, start time.Time, data *Store2GitData) error {
+	if format != output.FormatJSON {
+		return data.writeText(os.Stdout)
+	}
+
+	result := &output.Result{
+		Status:  output.StatusSuccess,
+		Command: constants.ActNRStore2git,
+		Data:    data,
+		Metadata: &output.Metadata{
+			DurationMs: time.Since(start).Milliseconds(),
+			TraceID:    traceID,
+			APIVersion: constants.APIVersion,
+		},
+	}
+
+	writer := output.NewWriter(format)
+	return writer.Write(os.Stdout, result)
+}
+
+// writeError выводит структурированную ошибку и возвращает error.
+func (h *Store2GitHandler) writeError(format, traceID string, start time.Time, code, message string) error {
+	if format != output.FormatJSON {
+		return fmt.Errorf("%s: %s", code, message)
+	}
+
+	result := &output.Result{
+		Status:  output.StatusError,
+		Command: constants.ActNRStore2git,
+		Error: &output.ErrorInfo{
+			Code:    code,
+			Message: message,
+		},
+		Metadata: &output.Metadata{
+			DurationMs: time.Since(start).Milliseconds(),
+			TraceID:    traceID,
+			APIVersion: constants.APIVersion,
+		},
+	}
+
+	writer := output.NewWriter(format)
+	if writeErr := writer.Write(os.Stdout, result); writeErr != nil {
+		slog.Default().Error("Не удалось записать JSON-ответ об ошибке",
+			slog.String("trace_id", traceID),
+			slog.String("error", writeErr.Error()))
+	}
+
+	return fmt.Errorf("%s: %s", code, message)
+}
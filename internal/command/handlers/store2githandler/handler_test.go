@@ -0,0 +1,449 @@
+package store2githandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Kargones/apk-ci/internal/command"
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/entity/one/store"
+	"github.com/Kargones/apk-ci/internal/pkg/output"
+	"github.com/Kargones/apk-ci/internal/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testReport = `Хранилище:	tcp://server/gitops/owner/repo
+
+Версия:	1
+Пользователь:	Иванов
+Дата создания:	01.03.2024
+Время создания:	10:00:00
+Комментарий:
+Начальная версия
+Метка:	Релиз 1.0
+Добавлены	Справочник.Склады
+
+Версия:	2
+Пользователь:	runner
+Дата создания:	02.03.2024
+Время создания:	11:00:00
+Комментарий:
+Версия создана автоматически
+Коммит abcdef1
+Изменены	Справочник.Склады
+
+Версия:	3
+Пользователь:	Петров
+Дата создания:	05.03.2024
+Время создания:	12:00:00
+Изменены	Документ.Заказ
+`
+
+// commitRecord — зафиксированный mockRepository коммит.
+type commitRecord struct {
+	VersionCommit
+	// content — содержимое выгрузки на момент коммита
+	content string
+	// stale — остался ли файл предыдущей выгрузки
+	stale bool
+}
+
+// mockRepository — мок Repository с рабочим каталогом во временной директории.
+type mockRepository struct {
+	path      string
+	sourceDir string
+	branch    string
+	tags      []string
+	commits   []commitRecord
+	tagged    map[string]string
+	pushes    int
+	openErr   error
+	commitErr error
+	tagErr    error
+	pushErr   error
+}
+
+func (m *mockRepository) Open(_ context.Context, _ *slog.Logger, _ *config.Config, branch string) error {
+	m.branch = branch
+	return m.openErr
+}
+
+func (m *mockRepository) Path() string { return m.path }
+
+func (m *mockRepository) ListTags(_ context.Context, pattern string) ([]string, error) {
+	var result []string
+	for _, tag := range m.tags {
+		if strings.HasPrefix(tag, strings.TrimSuffix(pattern, "*")) {
+			result = append(result, tag)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockRepository) Commit(_ context.Context, _ *slog.Logger, c VersionCommit) (string, error) {
+	if m.commitErr != nil {
+		return "", m.commitErr
+	}
+	content, err := os.ReadFile(filepath.Join(m.path, m.sourceDir, "Configuration.xml"))
+	if err != nil {
+		return "", err
+	}
+	_, staleErr := os.Stat(filepath.Join(m.path, m.sourceDir, "stale.xml"))
+	m.commits = append(m.commits, commitRecord{VersionCommit: c, content: string(content), stale: staleErr == nil})
+	m.tags = append(m.tags, c.Tag)
+	return "hash" + strconv.Itoa(len(m.commits)), nil
+}
+
+func (m *mockRepository) TagVersion(_ context.Context, _ *slog.Logger, tag, commit, _ string) error {
+	if m.tagErr != nil {
+		return m.tagErr
+	}
+	if m.tagged == nil {
+		m.tagged = make(map[string]string)
+	}
+	m.tagged[tag] = commit
+	m.tags = append(m.tags, tag)
+	return nil
+}
+
+func (m *mockRepository) Push(_ context.Context, _ *slog.Logger) error {
+	m.pushes++
+	return m.pushErr
+}
+
+// mockSource — мок StoreSource: выгрузка содержит номер текущей версии.
+type mockSource struct {
+	extension   string
+	fromVersion int
+	current     int
+	checkouts   []int
+	closed      bool
+	openErr     error
+	reportErr   error
+	checkoutErr map[int]error
+}
+
+func (m *mockSource) Open(_ context.Context, _ *slog.Logger, _ *config.Config, extension string) error {
+	m.extension = extension
+	return m.openErr
+}
+
+func (m *mockSource) Report(_ context.Context, _ *slog.Logger, _ *config.Config, fromVersion int) (*store.Report, error) {
+	m.fromVersion = fromVersion
+	if m.reportErr != nil {
+		return nil, m.reportErr
+	}
+	rep, err := store.ParseReportReader(strings.NewReader(testReport))
+	if err != nil {
+		return nil, err
+	}
+	versions := rep.Versions[:0]
+	for _, v := range rep.Versions {
+		if v.Number >= fromVersion {
+			versions = append(versions, v)
+		}
+	}
+	rep.Versions = versions
+	return rep, nil
+}
+
+func (m *mockSource) Checkout(_ context.Context, _ *slog.Logger, _ *config.Config, version int) error {
+	if err := m.checkoutErr[version]; err != nil {
+		return err
+	}
+	m.current = version
+	m.checkouts = append(m.checkouts, version)
+	return nil
+}
+
+func (m *mockSource) Export(_ context.Context, _ *slog.Logger, _ *config.Config, outputPath string) error {
+	return os.WriteFile(filepath.Join(outputPath, "Configuration.xml"), []byte("v"+strconv.Itoa(m.current)), 0o600)
+}
+
+func (m *mockSource) Close(_ *slog.Logger) { m.closed = true }
+
+// setupEnv сбрасывает переменные окружения, влияющие на handler.
+func setupEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("BR_OUTPUT_FORMAT", output.FormatJSON)
+	for _, name := range []string{
+		constants.EnvStore2gitBranch, constants.EnvStore2gitExtension, constants.EnvStore2gitLimit,
+		constants.EnvStore2gitAuthors, constants.EnvDryRun, constants.EnvPlanOnly,
+	} {
+		t.Setenv(name, "")
+	}
+}
+
+func newMocks(t *testing.T) (*mockRepository, *mockSource) {
+	t.Helper()
+	return &mockRepository{path: t.TempDir(), sourceDir: filepath.Join("src", "cfg")}, &mockSource{}
+}
+
+func testConfig() *config.Config {
+	return &config.Config{Owner: "owner", Repo: "repo", GitConfig: &config.GitConfig{UserEmail: "runner@example.com"}}
+}
+
+// execute выполняет handler и разбирает JSON ответ.
+func execute(t *testing.T, h *Store2GitHandler, cfg *config.Config) (output.Result, Store2GitData, error) {
+	t.Helper()
+	var execErr error
+	out := testutil.CaptureStdout(t, func() {
+		execErr = h.Execute(context.Background(), cfg)
+	})
+	var result output.Result
+	require.NoError(t, json.Unmarshal([]byte(out), &result), out)
+	var data Store2GitData
+	if result.Data != nil {
+		raw, err := json.Marshal(result.Data)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(raw, &data))
+	}
+	return result, data, execErr
+}
+
+func TestRegistration(t *testing.T) {
+	h, ok := command.Get(constants.ActNRStore2git)
+	require.True(t, ok)
+	assert.Equal(t, constants.ActNRStore2git, h.Name())
+}
+
+func TestExecute_FullExport(t *testing.T) {
+	setupEnv(t)
+	repo, source := newMocks(t)
+	// Файл, отсутствующий в выгрузке версии, не должен попасть в коммит.
+	require.NoError(t, os.MkdirAll(filepath.Join(repo.path, repo.sourceDir), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(repo.path, repo.sourceDir, "stale.xml"), []byte("old"), 0o600))
+
+	result, data, err := execute(t, &Store2GitHandler{repo: repo, source: source}, testConfig())
+
+	require.NoError(t, err)
+	assert.Equal(t, output.StatusSuccess, result.Status)
+	assert.Equal(t, defaultBranch, repo.branch)
+	assert.Equal(t, 1, source.fromVersion)
+	assert.True(t, source.closed)
+	assert.Equal(t, []int{1, 3}, source.checkouts)
+	assert.Equal(t, []int{2}, data.Skipped)
+	assert.Equal(t, map[string]string{"store/v2": "abcdef1"}, repo.tagged, "версия из git отмечается тегом на исходном коммите")
+	assert.Equal(t, 0, data.PreviousVersion)
+	assert.Equal(t, 3, data.LastVersion)
+	assert.True(t, data.StateChanged)
+	assert.Equal(t, 1, repo.pushes)
+
+	require.Len(t, repo.commits, 2)
+	first := repo.commits[0]
+	assert.Equal(t, "v1", first.content)
+	assert.False(t, first.stale)
+	assert.Equal(t, "store/v1", first.Tag)
+	assert.Equal(t, "Иванов <Иванов@example.com>", first.Author)
+	assert.Equal(t, "2024-03-01 10:00:00", first.Date.Format("2006-01-02 15:04:05"))
+	assert.True(t, strings.HasPrefix(first.Message, "Начальная версия\n\nВерсия хранилища: 1\n"), first.Message)
+	assert.Contains(t, first.Message, "Метка: Релиз 1.0")
+
+	second := repo.commits[1]
+	assert.Equal(t, "v3", second.content)
+	assert.Equal(t, "store/v3", second.Tag)
+	assert.True(t, strings.HasPrefix(second.Message, "Версия хранилища 3\n"), second.Message)
+
+	require.Len(t, data.Exported, 2)
+	assert.Equal(t, ExportedVersion{Version: 3, Commit: "hash2", Author: "Петров <Петров@example.com>", Date: second.Date}, data.Exported[1])
+}
+
+func TestExecute_Incremental(t *testing.T) {
+	setupEnv(t)
+	repo, source := newMocks(t)
+	repo.tags = []string{"store/v1", "store/ExtA/v7", "release-1"}
+
+	_, data, err := execute(t, &Store2GitHandler{repo: repo, source: source}, testConfig())
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, source.fromVersion)
+	assert.Equal(t, 1, data.PreviousVersion)
+	assert.Equal(t, []int{3}, source.checkouts)
+	require.Len(t, repo.commits, 1)
+	assert.Equal(t, "store/v3", repo.commits[0].Tag)
+}
+
+func TestExecute_NothingToExport(t *testing.T) {
+	setupEnv(t)
+	repo, source := newMocks(t)
+	repo.tags = []string{"store/v3"}
+
+	_, data, err := execute(t, &Store2GitHandler{repo: repo, source: source}, testConfig())
+
+	require.NoError(t, err)
+	assert.False(t, data.StateChanged)
+	assert.Empty(t, data.Exported)
+	assert.Equal(t, 3, data.LastVersion)
+	assert.Equal(t, 0, repo.pushes)
+}
+
+func TestExecute_ExtensionAndAuthors(t *testing.T) {
+	setupEnv(t)
+	repo, source := newMocks(t)
+	repo.sourceDir = filepath.Join("src", "cfe", "ExtA")
+	authorsPath := filepath.Join(t.TempDir(), "authors.yaml")
+	require.NoError(t, os.WriteFile(authorsPath, []byte("иванов: Иван Иванов <ivanov@corp.example>\n"), 0o600))
+	t.Setenv(constants.EnvStore2gitExtension, "ExtA")
+	t.Setenv(constants.EnvStore2gitAuthors, authorsPath)
+	t.Setenv(constants.EnvStore2gitBranch, "history")
+
+	_, data, err := execute(t, &Store2GitHandler{repo: repo, source: source}, testConfig())
+
+	require.NoError(t, err)
+	assert.Equal(t, "ExtA", source.extension)
+	assert.Equal(t, "history", repo.branch)
+	assert.Equal(t, "ExtA", data.Extension)
+	require.Len(t, repo.commits, 2)
+	assert.Equal(t, "store/ExtA/v1", repo.commits[0].Tag)
+	assert.Equal(t, "Иван Иванов <ivanov@corp.example>", repo.commits[0].Author)
+}
+
+func TestExecute_Limit(t *testing.T) {
+	setupEnv(t)
+	repo, source := newMocks(t)
+	t.Setenv(constants.EnvStore2gitLimit, "1")
+
+	_, data, err := execute(t, &Store2GitHandler{repo: repo, source: source}, testConfig())
+
+	require.NoError(t, err)
+	assert.Equal(t, []int{1}, source.checkouts)
+	assert.Equal(t, 2, data.LastVersion, "версия из git до невыгруженной версии отмечается тегом")
+	assert.Equal(t, []int{2}, data.Skipped)
+	assert.Equal(t, 1, data.Remaining)
+}
+
+func TestExecute_LastVersionFromGit(t *testing.T) {
+	setupEnv(t)
+	repo, source := newMocks(t)
+	repo.tags = []string{"store/v1"}
+	source.checkoutErr = map[int]error{3: errors.New("хранилище недоступно")}
+
+	result, _, err := execute(t, &Store2GitHandler{repo: repo, source: source}, testConfig())
+
+	require.Error(t, err)
+	assert.Equal(t, "STORE.CHECKOUT_FAILED", result.Error.Code)
+	assert.Contains(t, repo.tagged, "store/v2")
+	assert.Equal(t, 1, repo.pushes, "тег версии из git отправляется и без новых коммитов")
+	assert.Equal(t, 2, lastExportedVersion(repo.tags, versionTagPrefix("")))
+}
+
+func TestPendingVersions(t *testing.T) {
+	rep := &store.Report{Versions: []store.ReportVersion{
+		{Number: 4}, {Number: 2, GitCommit: "aa"}, {Number: 3}, {Number: 5, GitCommit: "bb"}, {Number: 1},
+	}}
+	numbers := func(vs []store.ReportVersion) []int {
+		var n []int
+		for _, v := range vs {
+			n = append(n, v.Number)
+		}
+		return n
+	}
+
+	pending, remaining := pendingVersions(rep, 1, 0)
+	assert.Equal(t, []int{2, 3, 4, 5}, numbers(pending))
+	assert.Zero(t, remaining)
+
+	pending, remaining = pendingVersions(rep, 0, 2)
+	assert.Equal(t, []int{1, 2, 3}, numbers(pending), "версия 5 из git не отмечается раньше версии 4")
+	assert.Equal(t, 1, remaining)
+}
+
+func TestExecute_DryRun(t *testing.T) {
+	setupEnv(t)
+	repo, source := newMocks(t)
+	t.Setenv(constants.EnvDryRun, "true")
+
+	_, data, err := execute(t, &Store2GitHandler{repo: repo, source: source}, testConfig())
+
+	require.NoError(t, err)
+	assert.True(t, data.DryRun)
+	assert.Equal(t, []int{1, 3}, data.Planned)
+	assert.Empty(t, source.checkouts)
+	assert.Empty(t, repo.commits)
+	assert.Equal(t, 0, repo.pushes)
+}
+
+func TestExecute_PartialFailurePushesExported(t *testing.T) {
+	setupEnv(t)
+	repo, source := newMocks(t)
+	source.checkoutErr = map[int]error{3: errors.New("хранилище недоступно")}
+
+	result, _, err := execute(t, &Store2GitHandler{repo: repo, source: source}, testConfig())
+
+	require.Error(t, err)
+	assert.Equal(t, "STORE.CHECKOUT_FAILED", result.Error.Code)
+	assert.Contains(t, result.Error.Message, "версия 3")
+	assert.Len(t, repo.commits, 1)
+	assert.Equal(t, 1, repo.pushes, "уже выгруженные версии должны быть отправлены")
+	assert.True(t, source.closed)
+}
+
+func TestExecute_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		cfg      *config.Config
+		setup    func(*mockRepository, *mockSource)
+		wantCode string
+	}{
+		{"no repo", nil, &config.Config{}, nil, "CONFIG.MISSING_OWNER_REPO"},
+		{"bad limit", map[string]string{constants.EnvStore2gitLimit: "-1"}, testConfig(), nil, "CONFIG.INVALID_PARAM"},
+		{"missing authors", map[string]string{constants.EnvStore2gitAuthors: "/nonexistent/authors.yaml"}, testConfig(), nil, "CONFIG.INVALID_PARAM"},
+		{"clone", nil, testConfig(), func(r *mockRepository, _ *mockSource) { r.openErr = errors.New("auth") }, "GIT.CLONE_FAILED"},
+		{"bind", nil, testConfig(), func(_ *mockRepository, s *mockSource) { s.openErr = errors.New("bind") }, "STORE.BIND_FAILED"},
+		{"report", nil, testConfig(), func(_ *mockRepository, s *mockSource) { s.reportErr = errors.New("report") }, "STORE.REPORT_FAILED"},
+		{"commit", nil, testConfig(), func(r *mockRepository, _ *mockSource) { r.commitErr = errors.New("commit") }, "GIT.COMMIT_FAILED"},
+		{"tag", nil, testConfig(), func(r *mockRepository, _ *mockSource) { r.tagErr = errors.New("tag") }, "GIT.TAG_FAILED"},
+		{"push", nil, testConfig(), func(r *mockRepository, _ *mockSource) { r.pushErr = errors.New("push") }, "GIT.PUSH_FAILED"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			setupEnv(t)
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			repo, source := newMocks(t)
+			if tc.setup != nil {
+				tc.setup(repo, source)
+			}
+
+			result, _, err := execute(t, &Store2GitHandler{repo: repo, source: source}, tc.cfg)
+
+			require.Error(t, err)
+			require.NotNil(t, result.Error)
+			assert.Equal(t, tc.wantCode, result.Error.Code)
+		})
+	}
+}
+
+func TestExecute_TextOutput(t *testing.T) {
+	setupEnv(t)
+	t.Setenv("BR_OUTPUT_FORMAT", "")
+	repo, source := newMocks(t)
+
+	var err error
+	out := testutil.CaptureStdout(t, func() {
+		err = (&Store2GitHandler{repo: repo, source: source}).Execute(context.Background(), testConfig())
+	})
+
+	require.NoError(t, err)
+	assert.Contains(t, out, "Выгружено версий: 2 (версии 0 → 3)")
+	assert.Contains(t, out, "3 → hash2 Петров")
+	assert.Contains(t, out, "Версии из git (тег на исходный коммит): [2]")
+}
+
+func TestLastExportedVersion(t *testing.T) {
+	tags := []string{"store/v2", "store/v10", "store/vExt/v30", "store/v", "store/v3"}
+	assert.Equal(t, 10, lastExportedVersion(tags, versionTagPrefix("")))
+	assert.Equal(t, 30, lastExportedVersion(tags, versionTagPrefix("vExt")))
+	assert.Equal(t, 0, lastExportedVersion(nil, versionTagPrefix("")))
+}
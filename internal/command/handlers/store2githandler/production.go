@@ -0,0 +1,179 @@
+package store2githandler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Kargones/apk-ci/internal/adapter/onec"
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/entity/one/designer"
+	"github.com/Kargones/apk-ci/internal/entity/one/store"
+	"github.com/Kargones/apk-ci/internal/git"
+)
+
+// gitRepository — production реализация Repository на основе git.Git.
+type gitRepository struct {
+	git *git.Git
+}
+
+// Open клонирует репозиторий во временный каталог WorkDir и переключается на ветку.
+// SECURITY: Token включается в URL для аутентификации git clone (как в nr-git2store).
+func (r *gitRepository) Open(ctx context.Context, l *slog.Logger, cfg *config.Config, branch string) error {
+	repPath, err := os.MkdirTemp(cfg.WorkDir, "s")
+	if err != nil {
+		return err
+	}
+	connectString := strings.Replace(cfg.GiteaURL, "https://", "https://"+url.PathEscape(cfg.AccessToken)+":@", 1)
+
+	var timeout time.Duration
+	if cfg.GitConfig != nil {
+		timeout = cfg.GitConfig.Timeout
+	}
+	r.git = &git.Git{
+		RepURL:  connectString + "/" + cfg.Owner + "/" + cfg.Repo + ".git",
+		RepPath: repPath,
+		WorkDir: cfg.WorkDir,
+		Token:   cfg.AccessToken,
+		Timeout: timeout,
+	}
+	// Ветка выгрузки может отсутствовать на сервере: клонируется ветка по умолчанию.
+	if err := r.git.Clone(ctx, l); err != nil {
+		return err
+	}
+	if cfg.GitConfig != nil {
+		if err := r.git.SetUser(ctx, l, cfg.GitConfig.UserName, cfg.GitConfig.UserEmail); err != nil {
+			return err
+		}
+	}
+	r.git.Branch = branch
+	return r.git.Switch(ctx, l)
+}
+
+// Path возвращает путь к рабочему каталогу репозитория.
+func (r *gitRepository) Path() string {
+	return r.git.RepPath
+}
+
+// ListTags возвращает имена тегов по шаблону.
+func (r *gitRepository) ListTags(ctx context.Context, pattern string) ([]string, error) {
+	return r.git.ListTags(ctx, pattern)
+}
+
+// Commit индексирует рабочий каталог, создаёт коммит версии и ставит тег.
+func (r *gitRepository) Commit(ctx context.Context, l *slog.Logger, c VersionCommit) (string, error) {
+	if err := r.git.Add(ctx, l); err != nil {
+		return "", err
+	}
+	hash, err := r.git.CommitAs(ctx, l, c.Message, c.Author, c.Date)
+	if err != nil {
+		return "", err
+	}
+	if err := r.git.Tag(ctx, l, c.Tag, c.TagMessage); err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+// TagVersion ставит тег версии на существующий коммит.
+func (r *gitRepository) TagVersion(ctx context.Context, l *slog.Logger, tag, commit, message string) error {
+	return r.git.TagCommit(ctx, l, tag, commit, message)
+}
+
+// Push отправляет ветку вместе с тегами версий.
+func (r *gitRepository) Push(ctx context.Context, l *slog.Logger) error {
+	return r.git.PushWithTags(ctx, l)
+}
+
+// storeSource — production реализация StoreSource через временную файловую базу.
+type storeSource struct {
+	extension string
+	storeRoot string
+	st        store.Store
+	odb       designer.OneDb
+	dbPath    string
+}
+
+// Open создаёт временную базу и подключает её к хранилищу проекта.
+func (s *storeSource) Open(ctx context.Context, l *slog.Logger, cfg *config.Config, extension string) error {
+	s.extension = extension
+	s.storeRoot = constants.StoreRoot + cfg.Owner + "/" + cfg.Repo
+	s.dbPath = filepath.Join(cfg.TmpDir, "temp_db_"+time.Now().Format("20060102_150405"))
+
+	var extensions []string
+	s.st = store.Store{Path: "Main"}
+	if extension != "" {
+		extensions = append(extensions, extension)
+		s.st = store.Store{Name: extension, Path: "add/" + extension}
+	}
+	if cfg.AppConfig != nil {
+		s.st.User = cfg.AppConfig.Users.StoreAdmin
+	}
+	if cfg.SecretConfig != nil {
+		s.st.Pass = cfg.SecretConfig.Passwords.StoreAdminPassword
+	}
+
+	odb, err := designer.CreateTempDb(ctx, l, cfg, s.dbPath, extensions)
+	if err != nil {
+		return err
+	}
+	s.odb = odb
+
+	if extension == "" {
+		return s.st.Bind(ctx, l, cfg, s.odb.FullConnectString, s.storeRoot, true)
+	}
+	return s.st.BindAdd(ctx, l, cfg, s.odb.FullConnectString, s.storeRoot, extension)
+}
+
+// Report формирует отчёт хранилища начиная с версии fromVersion.
+func (s *storeSource) Report(ctx context.Context, l *slog.Logger, cfg *config.Config, fromVersion int) (*store.Report, error) {
+	return s.st.Report(ctx, l, cfg, s.odb.FullConnectString, s.storeRoot, fromVersion, s.extension)
+}
+
+// Checkout обновляет конфигурацию временной базы до версии хранилища.
+func (s *storeSource) Checkout(ctx context.Context, l *slog.Logger, cfg *config.Config, version int) error {
+	return s.st.UpdateCfgVersion(ctx, l, cfg, s.odb.FullConnectString, s.storeRoot, version, s.extension)
+}
+
+// Export выгружает конфигурацию временной базы в outputPath через ConfigExporter,
+// выбранный config_export. Версия платформы выбирается по тому же требованию,
+// что и для операций designer с временной базой.
+func (s *storeSource) Export(ctx context.Context, l *slog.Logger, cfg *config.Config, outputPath string) error {
+	req, err := s.odb.PlatformRequirement(ctx, cfg, "")
+	if err != nil {
+		return err
+	}
+	exporter, err := onec.NewFactory(cfg).WithRequirement(req).NewConfigExporter()
+	if err != nil {
+		return err
+	}
+	opts := onec.ExportOptions{
+		ConnectString: s.odb.FullConnectString,
+		OutputPath:    outputPath,
+		Extension:     s.extension,
+	}
+	result, err := exporter.Export(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if !result.Success {
+		return fmt.Errorf("выгрузка конфигурации не выполнена: %s", strings.Join(result.Messages, "; "))
+	}
+	return nil
+}
+
+// Close удаляет временную базу.
+func (s *storeSource) Close(l *slog.Logger) {
+	if s.dbPath == "" {
+		return
+	}
+	if err := os.RemoveAll(s.dbPath); err != nil {
+		l.Warn("Не удалось удалить временную базу", slog.String("path", s.dbPath), slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,368 @@
+// Package storehistoryhandler реализует NR-команду nr-store-history
+// для вывода истории версий хранилища конфигурации 1C.
+package storehistoryhandler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Kargones/apk-ci/internal/command"
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/entity/one/convert"
+	"github.com/Kargones/apk-ci/internal/entity/one/store"
+	"github.com/Kargones/apk-ci/internal/pkg/dryrun"
+	"github.com/Kargones/apk-ci/internal/pkg/output"
+	"github.com/Kargones/apk-ci/internal/pkg/tracing"
+)
+
+// Compile-time interface check.
+var _ command.Handler = (*StoreHistoryHandler)(nil)
+
+func RegisterCmd() error {
+	return command.Register(&StoreHistoryHandler{})
+}
+
+// HistoryFilter — применённые фильтры (для вывода).
+type HistoryFilter struct {
+	Author string     `json:"author,omitempty"`
+	Since  *time.Time `json:"since,omitempty"`
+	Until  *time.Time `json:"until,omitempty"`
+	Object string     `json:"object,omitempty"`
+}
+
+// StoreHistoryData содержит данные ответа nr-store-history.
+type StoreHistoryData struct {
+	// Store — хранилище из заголовка отчёта
+	Store string `json:"store,omitempty"`
+	// Extension — расширение (пусто для основной конфигурации)
+	Extension string `json:"extension,omitempty"`
+	// Source — источник отчёта: файл BR_STORE_REPORT_FILE или "1cv8"
+	Source string `json:"source"`
+	// Filter — применённые фильтры
+	Filter HistoryFilter `json:"filter"`
+	// TotalVersions — количество версий в отчёте до фильтрации
+	TotalVersions int `json:"total_versions"`
+	// Versions — версии, прошедшие фильтр
+	Versions []store.ReportVersion `json:"versions"`
+	// Authors — авторы отобранных версий
+	Authors []string `json:"authors"`
+	// DurationMs — длительность операции в миллисекундах
+	DurationMs int64 `json:"duration_ms"`
+}
+
+// writeText выводит результат в человекочитаемом формате.
+func (d *StoreHistoryData) writeText(w io.Writer) error {
+	title := d.Store
+	if d.Extension != "" {
+		title += " (расширение " + d.Extension + ")"
+	}
+	if _, err := fmt.Fprintf(w, "История хранилища %s: %d из %d версий\n", title, len(d.Versions), d.TotalVersions); err != nil {
+		return err
+	}
+	for _, v := range d.Versions {
+		header := fmt.Sprintf("\nВерсия %d", v.Number)
+		if v.ConfVersion != "" {
+			header += " (" + v.ConfVersion + ")"
+		}
+		header += " — " + v.User
+		if !v.Date.IsZero() {
+			header += ", " + v.Date.Format("2006-01-02 15:04:05")
+		}
+		if v.GitCommit != "" {
+			header += " [git " + v.GitCommit + "]"
+		}
+		if _, err := fmt.Fprintln(w, header); err != nil {
+			return err
+		}
+		for _, l := range v.Labels {
+			if _, err := fmt.Fprintf(w, "  Метка: %s\n", l.Name); err != nil {
+				return err
+			}
+		}
+		if v.Comment != "" {
+			if _, err := fmt.Fprintf(w, "  %s\n", strings.ReplaceAll(v.Comment, "\n", "\n  ")); err != nil {
+				return err
+			}
+		}
+		for _, group := range []struct {
+			mark    string
+			objects []string
+		}{{"+", v.Added}, {"~", v.Changed}, {"-", v.Deleted}} {
+			for _, o := range group.objects {
+				if _, err := fmt.Fprintf(w, "  %s %s\n", group.mark, o); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ReportReader — интерфейс получения отчёта хранилища (для тестируемости).
+type ReportReader interface {
+	// ReadReport формирует отчёт хранилища основной конфигурации или расширения
+	ReadReport(ctx context.Context, l *slog.Logger, cfg *config.Config, extension string, fromVersion int) (*store.Report, error)
+}
+
+// defaultReportReader — production реализация через 1cv8 DESIGNER /ConfigurationRepositoryReport.
+type defaultReportReader struct{}
+
+// ReadReport загружает конфигурацию конвертации и строит отчёт хранилища.
+func (d *defaultReportReader) ReadReport(ctx context.Context, l *slog.Logger, cfg *config.Config, extension string, fromVersion int) (*store.Report, error) {
+	cc, err := convert.LoadFromConfig(ctx, l, cfg)
+	if err != nil {
+		return nil, err
+	}
+	for _, cp := range cc.Pair {
+		if extension == "" && cp.Source.Main {
+			return cp.Store.Report(ctx, l, cfg, cc.OneDB.FullConnectString, cc.StoreRoot, fromVersion)
+		}
+		if extension != "" && !cp.Source.Main && cp.Source.Name == extension {
+			return cp.Store.Report(ctx, l, cfg, cc.OneDB.FullConnectString, cc.StoreRoot, fromVersion, extension)
+		}
+	}
+	if extension == "" {
+		return nil, fmt.Errorf("в конфигурации проекта не найдено хранилище основной конфигурации")
+	}
+	return nil, fmt.Errorf("в конфигурации проекта не найдено хранилище расширения %s", extension)
+}
+
+// StoreHistoryHandler обрабатывает команду nr-store-history.
+//
+// Отчёт берётся из BR_STORE_REPORT_FILE либо строится через 1cv8 для
+// BR_INFOBASE_NAME. Фильтры: BR_STORE_HISTORY_AUTHOR, BR_STORE_HISTORY_SINCE,
+// BR_STORE_HISTORY_UNTIL (YYYY-MM-DD, DD.MM.YYYY или RFC3339), BR_STORE_HISTORY_OBJECT.
+type StoreHistoryHandler struct {
+	// reportReader — опциональный источник отчёта (nil в production, mock в тестах)
+	reportReader ReportReader
+}
+
+// Name возвращает имя команды.
+func (h *StoreHistoryHandler) Name() string {
+	return constants.ActNRStoreHistory
+}
+
+// Description возвращает описание команды для вывода в help.
+func (h *StoreHistoryHandler) Description() string {
+	return "История версий хранилища конфигурации 1C"
+}
+
+// Execute выполняет команду nr-store-history.
+func (h *StoreHistoryHandler) Execute(ctx context.Context, cfg *config.Config) error {
+	start := time.Now()
+
+	traceID := tracing.TraceIDFromContext(ctx)
+	if traceID == "" {
+		traceID = tracing.GenerateTraceID()
+	}
+
+	format := os.Getenv("BR_OUTPUT_FORMAT")
+
+	// Plan-only не поддерживается; dry-run имеет приоритет над plan-only.
+	if !dryrun.IsDryRun() && dryrun.IsPlanOnly() {
+		return dryrun.WritePlanOnlyUnsupported(os.Stdout, constants.ActNRStoreHistory)
+	}
+
+	log := slog.Default().With(
+		slog.String("trace_id", traceID),
+		slog.String("command", constants.ActNRStoreHistory),
+	)
+
+	filter, applied, err := filterFromEnv()
+	if err != nil {
+		return h.writeError(format, traceID, start, "CONFIG.INVALID_FILTER", err.Error())
+	}
+	fromVersion := 0
+	if v := os.Getenv(constants.EnvStoreHistoryFromVersion); v != "" {
+		if fromVersion, err = strconv.Atoi(v); err != nil || fromVersion < 0 {
+			return h.writeError(format, traceID, start, "CONFIG.INVALID_FILTER",
+				fmt.Sprintf("некорректное значение %s: %s", constants.EnvStoreHistoryFromVersion, v))
+		}
+	}
+	extension := os.Getenv(constants.EnvStoreHistoryExtension)
+
+	data := &StoreHistoryData{Extension: extension, Filter: applied}
+	var rep *store.Report
+	if path := os.Getenv(constants.EnvStoreReportFile); path != "" {
+		data.Source = path
+		log.Info("Чтение отчета хранилища из файла", slog.String("path", path))
+		if rep, err = readReportFile(path); err != nil {
+			log.Error("Ошибка разбора отчета хранилища", slog.String("error", err.Error()))
+			return h.writeError(format, traceID, start, "STORE.REPORT_PARSE_FAILED", err.Error())
+		}
+	} else {
+		if cfg == nil || cfg.InfobaseName == "" {
+			return h.writeError(format, traceID, start, "CONFIG.INFOBASE_MISSING",
+				"Не указано имя информационной базы (BR_INFOBASE_NAME) или файл отчета (BR_STORE_REPORT_FILE)")
+		}
+		data.Source = "1cv8"
+		reader := h.reportReader
+		if reader == nil {
+			reader = &defaultReportReader{}
+		}
+		log.Info("Формирование отчета хранилища",
+			slog.String("infobase", cfg.InfobaseName),
+			slog.String("extension", extension),
+			slog.Int("from_version", fromVersion))
+		if rep, err = reader.ReadReport(ctx, log, cfg, extension, fromVersion); err != nil {
+			log.Error("Ошибка формирования отчета хранилища", slog.String("error", err.Error()))
+			return h.writeError(format, traceID, start, "STORE.REPORT_FAILED", err.Error())
+		}
+	}
+
+	data.Store = rep.Store
+	data.TotalVersions = len(rep.Versions)
+	data.Versions = rep.Filter(filter)
+	if fromVersion > 0 {
+		filtered := data.Versions[:0]
+		for _, v := range data.Versions {
+			if v.Number >= fromVersion {
+				filtered = append(filtered, v)
+			}
+		}
+		data.Versions = filtered
+	}
+	data.Authors = authors(data.Versions)
+	data.DurationMs = time.Since(start).Milliseconds()
+
+	log.Info("История хранилища сформирована",
+		slog.Int("total_versions", data.TotalVersions),
+		slog.Int("versions", len(data.Versions)))
+
+	return h.writeSuccess(format, traceID, start, data)
+}
+
+// readReportFile разбирает txt-отчёт хранилища из файла.
+func readReportFile(path string) (*store.Report, error) {
+	f, err := os.Open(path) //nolint:gosec // путь к отчёту задаётся пользователем CI
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть отчет хранилища: %w", err)
+	}
+	defer func() { _ = f.Close() }() //nolint:errcheck // файл только для чтения
+	return store.ParseReportReader(f)
+}
+
+// filterFromEnv читает фильтры из переменных окружения.
+func filterFromEnv() (store.ReportFilter, HistoryFilter, error) {
+	f := store.ReportFilter{
+		Author: os.Getenv(constants.EnvStoreHistoryAuthor),
+		Object: os.Getenv(constants.EnvStoreHistoryObject),
+	}
+	applied := HistoryFilter{Author: f.Author, Object: f.Object}
+	if v := os.Getenv(constants.EnvStoreHistorySince); v != "" {
+		t, _, err := parseDate(v)
+		if err != nil {
+			return f, applied, fmt.Errorf("некорректное значение %s: %w", constants.EnvStoreHistorySince, err)
+		}
+		f.Since = t
+		applied.Since = &t
+	}
+	if v := os.Getenv(constants.EnvStoreHistoryUntil); v != "" {
+		t, dateOnly, err := parseDate(v)
+		if err != nil {
+			return f, applied, fmt.Errorf("некорректное значение %s: %w", constants.EnvStoreHistoryUntil, err)
+		}
+		// Дата без времени включает весь день.
+		if dateOnly {
+			t = t.Add(24*time.Hour - time.Second)
+		}
+		f.Until = t
+		applied.Until = &t
+	}
+	if !f.Since.IsZero() && !f.Until.IsZero() && f.Until.Before(f.Since) {
+		return f, applied, fmt.Errorf("%s раньше %s", constants.EnvStoreHistoryUntil, constants.EnvStoreHistorySince)
+	}
+	return f, applied, nil
+}
+
+// parseDate разбирает дату фильтра. Время в отчёте хранилища не содержит
+// часового пояса, поэтому даты без пояса сравниваются как UTC.
+func parseDate(v string) (time.Time, bool, error) {
+	for _, layout := range []string{"2006-01-02", "02.01.2006"} {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t, true, nil
+		}
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "02.01.2006 15:04:05"} {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t, false, nil
+		}
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("ожидается YYYY-MM-DD, DD.MM.YYYY или RFC3339: %s", v)
+	}
+	// Приводим к «локальному» времени отчёта без пояса.
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC), false, nil
+}
+
+// authors возвращает уникальных авторов версий в порядке первого появления.
+func authors(versions []store.ReportVersion) []string {
+	result := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, v := range versions {
+		if v.User != "" && !seen[v.User] {
+			seen[v.User] = true
+			result = append(result, v.User)
+		}
+	}
+	return result
+}
+
+// writeSuccess выводит успешный результат.
+func (h *StoreHistoryHandler) writeSuccess(format, traceID string, start time.Time, data *StoreHistoryData) error {
+	if format != output.FormatJSON {
+		return data.writeText(os.Stdout)
+	}
+
+	result := &output.Result{
+		Status:  output.StatusSuccess,
+		Command: constants.ActNRStoreHistory,
+		Data:    data,
+		Metadata: &output.Metadata{
+			DurationMs: time.Since(start).Milliseconds(),
+			TraceID:    traceID,
+			APIVersion: constants.APIVersion,
+		},
+	}
+
+	writer := output.NewWriter(format)
+	return writer.Write(os.Stdout, result)
+}
+
+// writeError выводит структурированную ошибку и возвращает error.
+func (h *StoreHistoryHandler) writeError(format, traceID string, start time.Time, code, message string) error {
+	if format != output.FormatJSON {
+		return fmt.Errorf("%s: %s", code, message)
+	}
+
+	result := &output.Result{
+		Status:  output.StatusError,
+		Command: constants.ActNRStoreHistory,
+		Error: &output.ErrorInfo{
+			Code:    code,
+			Message: message,
+		},
+		Metadata: &output.Metadata{
+			DurationMs: time.Since(start).Milliseconds(),
+			TraceID:    traceID,
+			APIVersion: constants.APIVersion,
+		},
+	}
+
+	writer := output.NewWriter(format)
+	if writeErr := writer.Write(os.Stdout, result); writeErr != nil {
+		slog.Default().Error("Не удалось записать JSON-ответ об ошибке",
+			slog.String("trace_id", traceID),
+			slog.String("error", writeErr.Error()))
+	}
+
+	return fmt.Errorf("%s: %s", code, message)
+}
@@ -0,0 +1,221 @@
+package storehistoryhandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Kargones/apk-ci/internal/command"
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/entity/one/store"
+	"github.com/Kargones/apk-ci/internal/pkg/output"
+	"github.com/Kargones/apk-ci/internal/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testReport = `Хранилище:	tcp://server/gitops/owner/repo
+
+Версия:	1
+Пользователь:	Иванов
+Дата создания:	01.03.2024
+Время создания:	10:00:00
+Комментарий:
+Версия создана автоматически
+Коммит abcdef1
+Добавлены	Справочник.Склады
+
+Версия:	2
+Пользователь:	Петров
+Дата создания:	05.03.2024
+Время создания:	12:00:00
+Изменены	Документ.Заказ.Форма.ФормаДокумента
+
+Версия:	3
+Пользователь:	Иванов
+Дата создания:	10.03.2024
+Время создания:	08:00:00
+Изменены	Документ.Заказ
+`
+
+// mockReportReader — мок для ReportReader.
+type mockReportReader struct {
+	extension   string
+	fromVersion int
+	err         error
+}
+
+func (m *mockReportReader) ReadReport(_ context.Context, _ *slog.Logger, _ *config.Config, extension string, fromVersion int) (*store.Report, error) {
+	m.extension, m.fromVersion = extension, fromVersion
+	if m.err != nil {
+		return nil, m.err
+	}
+	return store.ParseReportReader(strings.NewReader(testReport))
+}
+
+// setupEnv сбрасывает переменные окружения, влияющие на handler.
+func setupEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("BR_OUTPUT_FORMAT", output.FormatJSON)
+	for _, name := range []string{
+		constants.EnvStoreReportFile, constants.EnvStoreHistoryExtension, constants.EnvStoreHistoryFromVersion,
+		constants.EnvStoreHistoryAuthor, constants.EnvStoreHistorySince, constants.EnvStoreHistoryUntil,
+		constants.EnvStoreHistoryObject, constants.EnvDryRun, constants.EnvPlanOnly,
+	} {
+		t.Setenv(name, "")
+	}
+}
+
+// writeReportFile записывает тестовый отчёт и указывает на него через BR_STORE_REPORT_FILE.
+func writeReportFile(t *testing.T) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "report.txt")
+	require.NoError(t, os.WriteFile(path, []byte(testReport), 0o600))
+	t.Setenv(constants.EnvStoreReportFile, path)
+}
+
+// execute выполняет handler и разбирает JSON ответ.
+func execute(t *testing.T, h *StoreHistoryHandler, cfg *config.Config) (output.Result, StoreHistoryData, error) {
+	t.Helper()
+	var execErr error
+	out := testutil.CaptureStdout(t, func() {
+		execErr = h.Execute(context.Background(), cfg)
+	})
+	var result output.Result
+	require.NoError(t, json.Unmarshal([]byte(out), &result), out)
+	var data StoreHistoryData
+	if result.Data != nil {
+		raw, err := json.Marshal(result.Data)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(raw, &data))
+	}
+	return result, data, execErr
+}
+
+func versionNumbers(data StoreHistoryData) []int {
+	numbers := make([]int, 0, len(data.Versions))
+	for _, v := range data.Versions {
+		numbers = append(numbers, v.Number)
+	}
+	return numbers
+}
+
+func TestRegistration(t *testing.T) {
+	h, ok := command.Get(constants.ActNRStoreHistory)
+	require.True(t, ok)
+	assert.Equal(t, constants.ActNRStoreHistory, h.Name())
+}
+
+func TestExecute_FromFile(t *testing.T) {
+	setupEnv(t)
+	writeReportFile(t)
+
+	result, data, err := execute(t, &StoreHistoryHandler{}, &config.Config{})
+
+	require.NoError(t, err)
+	assert.Equal(t, output.StatusSuccess, result.Status)
+	assert.Equal(t, "tcp://server/gitops/owner/repo", data.Store)
+	assert.Equal(t, 3, data.TotalVersions)
+	assert.Equal(t, []int{1, 2, 3}, versionNumbers(data))
+	assert.Equal(t, []string{"Иванов", "Петров"}, data.Authors)
+	assert.Equal(t, "abcdef1", data.Versions[0].GitCommit)
+}
+
+func TestExecute_Filters(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want []int
+	}{
+		{"author", map[string]string{constants.EnvStoreHistoryAuthor: "иванов"}, []int{1, 3}},
+		{"date range", map[string]string{constants.EnvStoreHistorySince: "2024-03-02", constants.EnvStoreHistoryUntil: "05.03.2024"}, []int{2}},
+		{"object", map[string]string{constants.EnvStoreHistoryObject: "Документ.Заказ"}, []int{2, 3}},
+		{"from version", map[string]string{constants.EnvStoreHistoryFromVersion: "2"}, []int{2, 3}},
+		{"combined", map[string]string{constants.EnvStoreHistoryAuthor: "Иванов", constants.EnvStoreHistoryObject: "Документ.Заказ"}, []int{3}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			setupEnv(t)
+			writeReportFile(t)
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+
+			_, data, err := execute(t, &StoreHistoryHandler{}, &config.Config{})
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, versionNumbers(data))
+			assert.Equal(t, 3, data.TotalVersions)
+		})
+	}
+}
+
+func TestExecute_ReportReader(t *testing.T) {
+	setupEnv(t)
+	t.Setenv(constants.EnvStoreHistoryExtension, "ExtA")
+	t.Setenv(constants.EnvStoreHistoryFromVersion, "3")
+	reader := &mockReportReader{}
+	h := &StoreHistoryHandler{reportReader: reader}
+
+	_, data, err := execute(t, h, &config.Config{InfobaseName: "Base"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "ExtA", reader.extension)
+	assert.Equal(t, 3, reader.fromVersion)
+	assert.Equal(t, "1cv8", data.Source)
+	assert.Equal(t, "ExtA", data.Extension)
+}
+
+func TestExecute_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		reader   ReportReader
+		cfg      *config.Config
+		wantCode string
+	}{
+		{"no infobase", nil, nil, &config.Config{}, "CONFIG.INFOBASE_MISSING"},
+		{"bad since", map[string]string{constants.EnvStoreHistorySince: "вчера"}, nil, &config.Config{}, "CONFIG.INVALID_FILTER"},
+		{"until before since", map[string]string{constants.EnvStoreHistorySince: "2024-03-05", constants.EnvStoreHistoryUntil: "2024-03-01"}, nil, &config.Config{}, "CONFIG.INVALID_FILTER"},
+		{"bad from version", map[string]string{constants.EnvStoreHistoryFromVersion: "x"}, nil, &config.Config{}, "CONFIG.INVALID_FILTER"},
+		{"missing file", map[string]string{constants.EnvStoreReportFile: "/nonexistent/report.txt"}, nil, &config.Config{}, "STORE.REPORT_PARSE_FAILED"},
+		{"reader error", nil, &mockReportReader{err: errors.New("1cv8 failed")}, &config.Config{InfobaseName: "Base"}, "STORE.REPORT_FAILED"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			setupEnv(t)
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+
+			result, _, err := execute(t, &StoreHistoryHandler{reportReader: tc.reader}, tc.cfg)
+
+			require.Error(t, err)
+			require.NotNil(t, result.Error)
+			assert.Equal(t, tc.wantCode, result.Error.Code)
+		})
+	}
+}
+
+func TestExecute_TextOutput(t *testing.T) {
+	setupEnv(t)
+	writeReportFile(t)
+	t.Setenv("BR_OUTPUT_FORMAT", "")
+
+	var err error
+	out := testutil.CaptureStdout(t, func() {
+		err = (&StoreHistoryHandler{}).Execute(context.Background(), &config.Config{})
+	})
+
+	require.NoError(t, err)
+	assert.Contains(t, out, "3 из 3 версий")
+	assert.Contains(t, out, "Версия 1 — Иванов, 2024-03-01 10:00:00 [git abcdef1]")
+	assert.Contains(t, out, "+ Справочник.Склады")
+	assert.Contains(t, out, "~ Документ.Заказ")
+}
@@ -0,0 +1,268 @@
+// Package storerestorebackuphandler реализует NR-команду nr-store-restore-backup
+// для просмотра и восстановления backup хранилища, созданных nr-git2store.
+package storerestorebackuphandler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/Kargones/apk-ci/internal/command"
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/entity/one/store"
+	"github.com/Kargones/apk-ci/internal/pkg/dryrun"
+	"github.com/Kargones/apk-ci/internal/pkg/output"
+	"github.com/Kargones/apk-ci/internal/pkg/tracing"
+)
+
+// Compile-time interface check.
+var _ command.Handler = (*StoreRestoreBackupHandler)(nil)
+
+func RegisterCmd() error {
+	return command.Register(&StoreRestoreBackupHandler{})
+}
+
+// StoreRestoreBackupData содержит данные ответа nr-store-restore-backup.
+type StoreRestoreBackupData struct {
+	// StateChanged — было ли восстановлено хранилище
+	StateChanged bool `json:"state_changed"`
+	// DryRun — восстановление не выполнялось (BR_DRY_RUN)
+	DryRun bool `json:"dry_run,omitempty"`
+	// Backups — найденные backup (режим просмотра)
+	Backups []store.Backup `json:"backups"`
+	// Restored — восстановленный backup (режим восстановления)
+	Restored *store.Backup `json:"restored,omitempty"`
+	// StoreRoot — каталог хранилища, в который выполнено восстановление
+	StoreRoot string `json:"store_root,omitempty"`
+	// DurationMs — длительность операции в миллисекундах
+	DurationMs int64 `json:"duration_ms"`
+}
+
+// writeText выводит результат в человекочитаемом формате.
+func (d *StoreRestoreBackupData) writeText(w io.Writer) error {
+	if d.Restored != nil {
+		action := "восстановлено"
+		if d.DryRun {
+			action = "будет восстановлено (dry-run)"
+		}
+		_, err := fmt.Fprintf(w, "Хранилище %s: %s\nBackup: %s (%s)\n",
+			action, d.StoreRoot, d.Restored.Path, d.Restored.Created.Format(time.RFC3339))
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "Backup хранилища: %d\n", len(d.Backups)); err != nil {
+		return err
+	}
+	for _, b := range d.Backups {
+		kind := "только метаданные"
+		if b.Restorable {
+			kind = "копия каталога"
+		}
+		if _, err := fmt.Fprintf(w, "  %s  %s/%s  %s  [%s]\n    %s\n",
+			b.Created.Format(time.RFC3339), b.Owner, b.Repo, b.StoreRoot, kind, b.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BackupStore — интерфейс для операций с backup хранилища (для тестируемости).
+type BackupStore interface {
+	// List возвращает backup из каталога
+	List(dir string) ([]store.Backup, error)
+	// Read читает метаданные backup
+	Read(path string) (*store.Backup, error)
+	// Restore восстанавливает каталог хранилища из backup
+	Restore(backupPath, storeRoot string) error
+}
+
+// defaultBackupStore — production реализация BackupStore.
+type defaultBackupStore struct{}
+
+func (defaultBackupStore) List(dir string) ([]store.Backup, error) { return store.ListBackups(dir) }
+
+func (defaultBackupStore) Read(path string) (*store.Backup, error) { return store.ReadBackup(path) }
+
+func (defaultBackupStore) Restore(backupPath, storeRoot string) error {
+	return store.RestoreBackup(backupPath, storeRoot)
+}
+
+// StoreRestoreBackupHandler обрабатывает команду nr-store-restore-backup.
+//
+// Без BR_BACKUP_PATH выводит список backup в TmpDir (для BR_OWNER/BR_REPO, если заданы).
+// С BR_BACKUP_PATH восстанавливает каталог хранилища из backup: в каталог,
+// записанный в метаданных, либо в BR_STORE_ROOT.
+type StoreRestoreBackupHandler struct {
+	// backups — опциональная реализация BackupStore (nil в production, mock в тестах)
+	backups BackupStore
+}
+
+// Name возвращает имя команды.
+func (h *StoreRestoreBackupHandler) Name() string {
+	return constants.ActNRStoreRestoreBackup
+}
+
+// Description возвращает описание команды для вывода в help.
+func (h *StoreRestoreBackupHandler) Description() string {
+	return "Просмотр и восстановление backup хранилища 1C"
+}
+
+// Execute выполняет команду nr-store-restore-backup.
+func (h *StoreRestoreBackupHandler) Execute(ctx context.Context, cfg *config.Config) error {
+	start := time.Now()
+
+	traceID := tracing.TraceIDFromContext(ctx)
+	if traceID == "" {
+		traceID = tracing.GenerateTraceID()
+	}
+
+	format := os.Getenv("BR_OUTPUT_FORMAT")
+
+	// Plan-only не поддерживается; dry-run имеет приоритет над plan-only.
+	if !dryrun.IsDryRun() && dryrun.IsPlanOnly() {
+		return dryrun.WritePlanOnlyUnsupported(os.Stdout, constants.ActNRStoreRestoreBackup)
+	}
+
+	log := slog.Default().With(
+		slog.String("trace_id", traceID),
+		slog.String("command", constants.ActNRStoreRestoreBackup),
+	)
+
+	backups := h.backups
+	if backups == nil {
+		backups = defaultBackupStore{}
+	}
+
+	backupPath := os.Getenv(constants.EnvBackupPath)
+	if backupPath == "" {
+		return h.list(backups, cfg, log, format, traceID, start)
+	}
+
+	backup, err := backups.Read(backupPath)
+	if err != nil {
+		log.Error("Backup не найден", slog.String("path", backupPath), slog.String("error", err.Error()))
+		return h.writeError(format, traceID, start, "STORE.BACKUP_NOT_FOUND", err.Error())
+	}
+
+	storeRoot := os.Getenv(constants.EnvStoreRoot)
+	if storeRoot == "" {
+		storeRoot = backup.StoreRoot
+	}
+	if storeRoot == "" {
+		return h.writeError(format, traceID, start, "STORE.ROOT_MISSING",
+			"В метаданных backup не указан каталог хранилища, задайте BR_STORE_ROOT")
+	}
+	if !backup.Restorable || !store.IsLocalStore(storeRoot) {
+		return h.writeError(format, traceID, start, "STORE.BACKUP_NOT_RESTORABLE",
+			fmt.Sprintf("backup %s не содержит копии каталога хранилища %s: восстановите вручную через 1cv8 DESIGNER", backup.Path, storeRoot))
+	}
+
+	data := &StoreRestoreBackupData{
+		Backups:   []store.Backup{},
+		Restored:  backup,
+		StoreRoot: storeRoot,
+		DryRun:    dryrun.IsDryRun(),
+	}
+
+	if !data.DryRun {
+		log.Info("Восстановление хранилища из backup",
+			slog.String("backup_path", backup.Path), slog.String("store_root", storeRoot))
+		if err := backups.Restore(backup.Path, storeRoot); err != nil {
+			log.Error("Ошибка восстановления хранилища", slog.String("error", err.Error()))
+			if errors.Is(err, store.ErrBackupNotRestorable) {
+				return h.writeError(format, traceID, start, "STORE.BACKUP_NOT_RESTORABLE", err.Error())
+			}
+			return h.writeError(format, traceID, start, "STORE.RESTORE_FAILED", err.Error())
+		}
+		data.StateChanged = true
+	}
+
+	data.DurationMs = time.Since(start).Milliseconds()
+	log.Info("Восстановление хранилища завершено",
+		slog.Bool("state_changed", data.StateChanged),
+		slog.Int64("duration_ms", data.DurationMs))
+
+	return h.writeSuccess(format, traceID, start, data)
+}
+
+// list выводит backup из TmpDir, отфильтрованные по owner/repo из конфигурации.
+func (h *StoreRestoreBackupHandler) list(backups BackupStore, cfg *config.Config, log *slog.Logger, format, traceID string, start time.Time) error {
+	if cfg == nil || cfg.TmpDir == "" {
+		return h.writeError(format, traceID, start, "CONFIG.TMPDIR_MISSING",
+			"Не задан каталог временных файлов (TmpDir), в котором хранятся backup")
+	}
+
+	all, err := backups.List(cfg.TmpDir)
+	if err != nil {
+		log.Error("Ошибка чтения каталога backup", slog.String("error", err.Error()))
+		return h.writeError(format, traceID, start, "STORE.BACKUP_LIST_FAILED", err.Error())
+	}
+
+	data := &StoreRestoreBackupData{Backups: make([]store.Backup, 0, len(all))}
+	for _, b := range all {
+		if (cfg.Owner != "" && b.Owner != cfg.Owner) || (cfg.Repo != "" && b.Repo != cfg.Repo) {
+			continue
+		}
+		data.Backups = append(data.Backups, b)
+	}
+	data.DurationMs = time.Since(start).Milliseconds()
+
+	log.Info("Список backup хранилища", slog.Int("count", len(data.Backups)))
+	return h.writeSuccess(format, traceID, start, data)
+}
+
+// writeSuccess выводит успешный результат.
+func (h *StoreRestoreBackupHandler) writeSuccess(format, traceID string, start time.Time, data *StoreRestoreBackupData) error {
+	if format != output.FormatJSON {
+		return data.writeText(os.Stdout)
+	}
+
+	result := &output.Result{
+		Status:  output.StatusSuccess,
+		Command: constants.ActNRStoreRestoreBackup,
+		Data:    data,
+		Metadata: &output.Metadata{
+			DurationMs: time.Since(start).Milliseconds(),
+			TraceID:    traceID,
+			APIVersion: constants.APIVersion,
+		},
+	}
+
+	writer := output.NewWriter(format)
+	return writer.Write(os.Stdout, result)
+}
+
+// writeError выводит структурированную ошибку и возвращает error.
+func (h *StoreRestoreBackupHandler) writeError(format, traceID string, start time.Time, code, message string) error {
+	if format != output.FormatJSON {
+		return fmt.Errorf("%s: %s", code, message)
+	}
+
+	result := &output.Result{
+		Status:  output.StatusError,
+		Command: constants.ActNRStoreRestoreBackup,
+		Error: &output.ErrorInfo{
+			Code:    code,
+			Message: message,
+		},
+		Metadata: &output.Metadata{
+			DurationMs: time.Since(start).Milliseconds(),
+			TraceID:    traceID,
+			APIVersion: constants.APIVersion,
+		},
+	}
+
+	writer := output.NewWriter(format)
+	if writeErr := writer.Write(os.Stdout, result); writeErr != nil {
+		slog.Default().Error("Не удалось записать JSON-ответ об ошибке",
+			slog.String("trace_id", traceID),
+			slog.String("error", writeErr.Error()))
+	}
+
+	return fmt.Errorf("%s: %s", code, message)
+}
@@ -0,0 +1,189 @@
+package storerestorebackuphandler
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Kargones/apk-ci/internal/command"
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/entity/one/store"
+	"github.com/Kargones/apk-ci/internal/pkg/output"
+	"github.com/Kargones/apk-ci/internal/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupEnv сбрасывает переменные окружения, влияющие на handler.
+func setupEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("BR_OUTPUT_FORMAT", output.FormatJSON)
+	t.Setenv(constants.EnvBackupPath, "")
+	t.Setenv(constants.EnvStoreRoot, "")
+	t.Setenv(constants.EnvDryRun, "")
+	t.Setenv(constants.EnvPlanOnly, "")
+}
+
+// createFileStoreBackup создаёт файловое хранилище и его backup.
+func createFileStoreBackup(t *testing.T, owner, repo string) (cfg *config.Config, storeRoot, backupPath string) {
+	t.Helper()
+	storeRoot = filepath.Join(t.TempDir(), "store")
+	require.NoError(t, os.MkdirAll(storeRoot, 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(storeRoot, "1cv8ddb.1CD"), []byte("v1"), 0o600))
+	cfg = &config.Config{TmpDir: t.TempDir(), Owner: owner, Repo: repo, InfobaseName: "Base"}
+	backupPath, err := store.CreateBackup(cfg, storeRoot)
+	require.NoError(t, err)
+	return cfg, storeRoot, backupPath
+}
+
+// parseResult разбирает JSON ответ handler.
+func parseResult(t *testing.T, out string) (output.Result, StoreRestoreBackupData) {
+	t.Helper()
+	var result output.Result
+	require.NoError(t, json.Unmarshal([]byte(out), &result), out)
+	var data StoreRestoreBackupData
+	if result.Data != nil {
+		raw, err := json.Marshal(result.Data)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(raw, &data))
+	}
+	return result, data
+}
+
+func TestRegistration(t *testing.T) {
+	h, ok := command.Get(constants.ActNRStoreRestoreBackup)
+	require.True(t, ok)
+	assert.Equal(t, constants.ActNRStoreRestoreBackup, h.Name())
+	assert.NotEmpty(t, h.Description())
+}
+
+func TestExecute_ListBackups(t *testing.T) {
+	setupEnv(t)
+	cfg, _, backupPath := createFileStoreBackup(t, "owner", "repo")
+	// backup другого репозитория не должен попадать в список
+	require.NoError(t, os.MkdirAll(filepath.Join(cfg.TmpDir, "backup_20200101_000000"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(cfg.TmpDir, "backup_20200101_000000", store.BackupInfoFile),
+		[]byte("Store Root: tcp://server/store\nOwner: owner\nRepo: other\n"), 0o600))
+
+	h := &StoreRestoreBackupHandler{}
+	var execErr error
+	out := testutil.CaptureStdout(t, func() {
+		execErr = h.Execute(context.Background(), cfg)
+	})
+
+	require.NoError(t, execErr)
+	result, data := parseResult(t, out)
+	assert.Equal(t, output.StatusSuccess, result.Status)
+	require.Len(t, data.Backups, 1)
+	assert.Equal(t, backupPath, data.Backups[0].Path)
+	assert.True(t, data.Backups[0].Restorable)
+	assert.False(t, data.StateChanged)
+}
+
+func TestExecute_Restore(t *testing.T) {
+	setupEnv(t)
+	cfg, storeRoot, backupPath := createFileStoreBackup(t, "owner", "repo")
+	require.NoError(t, os.WriteFile(filepath.Join(storeRoot, "1cv8ddb.1CD"), []byte("broken"), 0o600))
+	t.Setenv(constants.EnvBackupPath, backupPath)
+
+	h := &StoreRestoreBackupHandler{}
+	var execErr error
+	out := testutil.CaptureStdout(t, func() {
+		execErr = h.Execute(context.Background(), cfg)
+	})
+
+	require.NoError(t, execErr)
+	_, data := parseResult(t, out)
+	assert.True(t, data.StateChanged)
+	assert.Equal(t, storeRoot, data.StoreRoot)
+	content, err := os.ReadFile(filepath.Join(storeRoot, "1cv8ddb.1CD"))
+	require.NoError(t, err)
+	assert.Equal(t, "v1", string(content))
+}
+
+func TestExecute_RestoreDryRun(t *testing.T) {
+	setupEnv(t)
+	cfg, storeRoot, backupPath := createFileStoreBackup(t, "owner", "repo")
+	require.NoError(t, os.WriteFile(filepath.Join(storeRoot, "1cv8ddb.1CD"), []byte("current"), 0o600))
+	t.Setenv(constants.EnvBackupPath, backupPath)
+	t.Setenv(constants.EnvDryRun, "true")
+
+	h := &StoreRestoreBackupHandler{}
+	var execErr error
+	out := testutil.CaptureStdout(t, func() {
+		execErr = h.Execute(context.Background(), cfg)
+	})
+
+	require.NoError(t, execErr)
+	_, data := parseResult(t, out)
+	assert.True(t, data.DryRun)
+	assert.False(t, data.StateChanged)
+	content, err := os.ReadFile(filepath.Join(storeRoot, "1cv8ddb.1CD"))
+	require.NoError(t, err)
+	assert.Equal(t, "current", string(content))
+}
+
+func TestExecute_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(t *testing.T) *config.Config
+		wantCode string
+	}{
+		{
+			name: "backup not found",
+			setup: func(t *testing.T) *config.Config {
+				t.Setenv(constants.EnvBackupPath, filepath.Join(t.TempDir(), "missing"))
+				return &config.Config{}
+			},
+			wantCode: "STORE.BACKUP_NOT_FOUND",
+		},
+		{
+			name: "metadata only backup",
+			setup: func(t *testing.T) *config.Config {
+				cfg := &config.Config{TmpDir: t.TempDir()}
+				path, err := store.CreateBackup(cfg, "tcp://server/store")
+				require.NoError(t, err)
+				t.Setenv(constants.EnvBackupPath, path)
+				return cfg
+			},
+			wantCode: "STORE.BACKUP_NOT_RESTORABLE",
+		},
+		{
+			name: "no tmp dir",
+			setup: func(t *testing.T) *config.Config {
+				return &config.Config{}
+			},
+			wantCode: "CONFIG.TMPDIR_MISSING",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			setupEnv(t)
+			cfg := tc.setup(t)
+			h := &StoreRestoreBackupHandler{}
+			var execErr error
+			out := testutil.CaptureStdout(t, func() {
+				execErr = h.Execute(context.Background(), cfg)
+			})
+
+			require.Error(t, execErr)
+			result, _ := parseResult(t, out)
+			assert.Equal(t, output.StatusError, result.Status)
+			require.NotNil(t, result.Error)
+			assert.Equal(t, tc.wantCode, result.Error.Code)
+		})
+	}
+}
+
+func TestExecute_PlanOnlyUnsupported(t *testing.T) {
+	setupEnv(t)
+	t.Setenv(constants.EnvPlanOnly, "true")
+	h := &StoreRestoreBackupHandler{}
+	out := testutil.CaptureStdout(t, func() {
+		_ = h.Execute(context.Background(), &config.Config{})
+	})
+	assert.Contains(t, out, constants.ActNRStoreRestoreBackup)
+}
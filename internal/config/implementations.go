@@ -20,11 +20,29 @@ type ImplementationsConfig struct {
 	// DBCreate определяет инструмент для создания базы данных.
 	// Допустимые значения: 1cv8 (default), "ibcmd"
 	DBCreate string `yaml:"db_create" env:"BR_IMPL_DB_CREATE" env-default:"1cv8"`
+
+	// RAC определяет клиент администрирования кластера 1C.
+	// Допустимые значения: "rac" (default) — утилита rac, "native" — протокол RAS напрямую.
+	// Клиент RAS реализует статус и сервисный режим, сеансы и регламентные задания;
+	// команды инвентаризации, отчёта по сеансам, проверки кластера и управления
+	// базами с "native" завершаются ошибкой RAC.NATIVE_UNSUPPORTED.
+	RAC string `yaml:"rac" env:"BR_IMPL_RAC" env-default:"rac"`
+
+	// DBRestore определяет способ восстановления базы данных MSSQL (nr-dbrestore).
+	// Допустимые значения: "procedure" (default) — хранимая процедура sp_DBRestorePSFromHistoryD,
+	// "native" — цепочка RESTORE по истории msdb без серверных процедур
+	DBRestore string `yaml:"db_restore" env:"BR_IMPL_DB_RESTORE" env-default:"procedure"`
 }
 // Validate проверяет корректность значений ImplementationsConfig.
 // Возвращает ошибку если значения не соответствуют допустимым.
 const defaultImpl1cv8 = "1cv8"
 
+// defaultImplRAC — клиент кластера по умолчанию (утилита rac).
+const defaultImplRAC = "rac"
+
+// defaultImplDBRestore — восстановление через хранимую процедуру по умолчанию.
+const defaultImplDBRestore = "procedure"
+
 func (c *ImplementationsConfig) Validate() error {
 	// Применяем defaults для пустых значений
 	if c.ConfigExport == "" {
@@ -33,9 +51,17 @@ func (c *ImplementationsConfig) Validate() error {
 	if c.DBCreate == "" {
 		c.DBCreate = defaultImpl1cv8
 	}
+	if c.RAC == "" {
+		c.RAC = defaultImplRAC
+	}
+	if c.DBRestore == "" {
+		c.DBRestore = defaultImplDBRestore
+	}
 
 	validConfigExport := map[string]bool{defaultImpl1cv8: true, "ibcmd": true, "native": true}
 	validDBCreate := map[string]bool{defaultImpl1cv8: true, "ibcmd": true}
+	validRAC := map[string]bool{defaultImplRAC: true, "native": true}
+	validDBRestore := map[string]bool{defaultImplDBRestore: true, "native": true}
 
 	if !validConfigExport[c.ConfigExport] {
 		return fmt.Errorf("недопустимое значение ConfigExport: %q, допустимые: 1cv8, ibcmd, native", c.ConfigExport)
@@ -43,6 +69,12 @@ func (c *ImplementationsConfig) Validate() error {
 	if !validDBCreate[c.DBCreate] {
 		return fmt.Errorf("недопустимое значение DBCreate: %q, допустимые: 1cv8, ibcmd", c.DBCreate)
 	}
+	if !validRAC[c.RAC] {
+		return fmt.Errorf("недопустимое значение RAC: %q, допустимые: rac, native", c.RAC)
+	}
+	if !validDBRestore[c.DBRestore] {
+		return fmt.Errorf("недопустимое значение DBRestore: %q, допустимые: procedure, native", c.DBRestore)
+	}
 	return nil
 }
 // loadImplementationsConfig загружает конфигурацию реализаций из AppConfig, переменных окружения или устанавливает значения по умолчанию
@@ -60,6 +92,8 @@ func loadImplementationsConfig(l *slog.Logger, cfg *Config) (*ImplementationsCon
 		l.Info("Implementations конфигурация загружена из AppConfig",
 			slog.String("config_export", implConfig.ConfigExport),
 			slog.String("db_create", implConfig.DBCreate),
+			slog.String("rac", implConfig.RAC),
+			slog.String("db_restore", implConfig.DBRestore),
 		)
 		return implConfig, nil
 	}
@@ -86,5 +120,7 @@ func getDefaultImplementationsConfig() *ImplementationsConfig {
 	return &ImplementationsConfig{
 		ConfigExport: defaultImpl1cv8,
 		DBCreate:     defaultImpl1cv8,
+		RAC:          defaultImplRAC,
+		DBRestore:    defaultImplDBRestore,
 	}
 }
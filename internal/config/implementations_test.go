@@ -42,6 +42,8 @@ func TestImplementationsConfig_Defaults(t *testing.T) {
 	require.NotNil(t, impl)
 	assert.Equal(t, "1cv8", impl.ConfigExport, "default ConfigExport должен быть '1cv8'")
 	assert.Equal(t, "1cv8", impl.DBCreate, "default DBCreate должен быть '1cv8'")
+	assert.Equal(t, "rac", impl.RAC, "default RAC должен быть 'rac'")
+	assert.Equal(t, "procedure", impl.DBRestore, "default DBRestore должен быть 'procedure'")
 }
 
 // TestImplementationsConfig_EnvOverride проверяет что env vars переопределяют файл (AC4)
@@ -180,6 +182,82 @@ func TestImplementationsConfig_Validate(t *testing.T) {
 	}
 }
 
+// TestImplementationsConfig_RAC проверяет выбор клиента кластера 1C
+func TestImplementationsConfig_RAC(t *testing.T) {
+	tests := []struct {
+		name    string
+		rac     string
+		want    string
+		wantErr bool
+	}{
+		{"empty_defaults_to_rac", "", "rac", false},
+		{"rac", "rac", "rac", false},
+		{"native", "native", "native", false},
+		{"invalid", "ibcmd", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			impl := &ImplementationsConfig{RAC: tt.rac}
+			err := impl.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, impl.RAC)
+		})
+	}
+
+	t.Run("env_override", func(t *testing.T) {
+		t.Setenv("BR_IMPL_RAC", "native")
+		logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+		implConfig, err := loadImplementationsConfig(logger, &Config{})
+
+		require.NoError(t, err)
+		assert.Equal(t, "native", implConfig.RAC)
+	})
+}
+
+// TestImplementationsConfig_DBRestore проверяет выбор способа восстановления MSSQL
+func TestImplementationsConfig_DBRestore(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"empty_defaults_to_procedure", "", "procedure", false},
+		{"procedure", "procedure", "procedure", false},
+		{"native", "native", "native", false},
+		{"invalid", "sqlcmd", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			impl := &ImplementationsConfig{DBRestore: tt.value}
+			err := impl.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, impl.DBRestore)
+		})
+	}
+
+	t.Run("env_override", func(t *testing.T) {
+		t.Setenv("BR_IMPL_DB_RESTORE", "native")
+		logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+		implConfig, err := loadImplementationsConfig(logger, &Config{})
+
+		require.NoError(t, err)
+		assert.Equal(t, "native", implConfig.DBRestore)
+	})
+}
+
 // TestLoggingConfig_EnvOverride проверяет переопределение через BR_LOG_* переменные
 func TestLoggingConfig_EnvOverride(t *testing.T) {
 	// Arrange - устанавливаем переменные окружения
@@ -168,6 +168,18 @@ func loadAllSubConfigs(ctx context.Context, l *slog.Logger, cfg *Config) {
 		cfg.ImplementationsConfig = getDefaultImplementationsConfig()
 	}
 
+	// Загрузка конфигурации параллельной обработки расширений
+	if cfg.ParallelConfig, err = loadParallelConfig(l, cfg); err != nil {
+		l.Warn("ошибка загрузки конфигурации параллельной обработки", slog.String("error", err.Error()))
+		cfg.ParallelConfig = getDefaultParallelConfig()
+	}
+
+	// Загрузка конфигурации выбора платформы
+	if cfg.PlatformConfig, err = loadPlatformConfig(l, cfg); err != nil {
+		l.Warn("ошибка загрузки конфигурации платформы", slog.String("error", err.Error()))
+		cfg.PlatformConfig = getDefaultPlatformConfig()
+	}
+
 	// Загрузка конфигурации RAC
 	if cfg.RacConfig, err = loadRacConfig(l, cfg); err != nil {
 		l.Warn("ошибка загрузки конфигурации RAC", slog.String("error", err.Error()))
@@ -205,6 +217,26 @@ func validateLoadedConfigs(ctx context.Context, l *slog.Logger, cfg *Config) {
 		}
 	}
 
+	// Валидация параллельной обработки расширений
+	if cfg.ParallelConfig != nil {
+		if err := cfg.ParallelConfig.Validate(); err != nil {
+			l.Warn("невалидная конфигурация параллельной обработки, используются значения по умолчанию",
+				slog.String("error", err.Error()),
+			)
+			cfg.ParallelConfig = getDefaultParallelConfig()
+		}
+	}
+
+	// Валидация выбора платформы
+	if cfg.PlatformConfig != nil {
+		if err := cfg.PlatformConfig.Validate(); err != nil {
+			l.Warn("невалидная конфигурация платформы, используются значения по умолчанию",
+				slog.String("error", err.Error()),
+			)
+			cfg.PlatformConfig = getDefaultPlatformConfig()
+		}
+	}
+
 	// Fail-fast валидация алертинга
 	if cfg.AlertingConfig != nil && cfg.AlertingConfig.Enabled {
 		if valErr := validateAlertingConfig(cfg.AlertingConfig); valErr != nil {
@@ -398,6 +430,9 @@ func loadProjectConfig(ctx context.Context, l *slog.Logger, cfg *Config) (*Proje
 	if err = yaml.Unmarshal(data, &projectConfig); err != nil {
 		return nil, fmt.Errorf("ошибка парсинга project.yaml: %w", err)
 	}
+	if err = projectConfig.Sanitize.Validate(); err != nil {
+		return nil, fmt.Errorf("ошибка секции sanitize в project.yaml: %w", err)
+	}
 
 	return &projectConfig, nil
 }
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/ilyakaznacheev/cleanenv"
+)
+
+// defaultExtensionErrorMode — режим обработки ошибок расширений по умолчанию.
+const defaultExtensionErrorMode = "fail-fast"
+
+// ParallelConfig содержит настройки обработки расширений пулом.
+// Все операции над расширениями (LoadAdd, DumpAdd, LockAdd, UnlockAdd, MergeAdd,
+// StoreCommitAdd) открывают конфигуратор одной базы и выполняются последовательно:
+// ExtensionConcurrency > 1 ускорения не даёт. Пул определяет порядок обработки
+// ошибок (ErrorMode) и результаты по расширениям.
+type ParallelConfig struct {
+	// ExtensionConcurrency — максимальное число одновременно обрабатываемых расширений.
+	// 1 (default) — последовательная обработка. Операции над расширениями монопольны
+	// для базы, поэтому значение больше 1 обработку не ускоряет.
+	ExtensionConcurrency int `yaml:"extension_concurrency" env:"BR_EXTENSION_CONCURRENCY" env-default:"1"`
+
+	// ErrorMode определяет реакцию на ошибку расширения.
+	// Допустимые значения: "fail-fast" (default) — прервать обработку остальных,
+	// "collect-all" — обработать все расширения и вернуть все ошибки.
+	ErrorMode string `yaml:"error_mode" env:"BR_EXTENSION_ERROR_MODE" env-default:"fail-fast"`
+}
+
+// Validate проверяет корректность значений ParallelConfig.
+// Пустые значения заменяются значениями по умолчанию.
+func (c *ParallelConfig) Validate() error {
+	if c.ExtensionConcurrency == 0 {
+		c.ExtensionConcurrency = 1
+	}
+	if c.ErrorMode == "" {
+		c.ErrorMode = defaultExtensionErrorMode
+	}
+
+	if c.ExtensionConcurrency < 0 {
+		return fmt.Errorf("недопустимое значение ExtensionConcurrency: %d, ожидается число >= 1", c.ExtensionConcurrency)
+	}
+	if c.ErrorMode != defaultExtensionErrorMode && c.ErrorMode != "collect-all" {
+		return fmt.Errorf("недопустимое значение ErrorMode: %q, допустимые: fail-fast, collect-all", c.ErrorMode)
+	}
+	return nil
+}
+
+// loadParallelConfig загружает настройки параллельной обработки из AppConfig, переменных окружения или устанавливает значения по умолчанию
+//
+//nolint:dupl // similar structure to loadImplementationsConfig
+func loadParallelConfig(l *slog.Logger, cfg *Config) (*ParallelConfig, error) {
+	if cfg.AppConfig != nil && (cfg.AppConfig.Parallel != ParallelConfig{}) {
+		parallelConfig := &cfg.AppConfig.Parallel
+		if err := cleanenv.ReadEnv(parallelConfig); err != nil {
+			l.Warn("Ошибка загрузки Parallel конфигурации из переменных окружения",
+				slog.String("error", err.Error()),
+			)
+		}
+		l.Info("Parallel конфигурация загружена из AppConfig",
+			slog.Int("extension_concurrency", parallelConfig.ExtensionConcurrency),
+			slog.String("error_mode", parallelConfig.ErrorMode),
+		)
+		return parallelConfig, nil
+	}
+
+	parallelConfig := getDefaultParallelConfig()
+	if err := cleanenv.ReadEnv(parallelConfig); err != nil {
+		l.Warn("Ошибка загрузки Parallel конфигурации из переменных окружения",
+			slog.String("error", err.Error()),
+		)
+	}
+
+	l.Debug("Parallel конфигурация: используются значения по умолчанию",
+		slog.Int("extension_concurrency", parallelConfig.ExtensionConcurrency),
+		slog.String("error_mode", parallelConfig.ErrorMode),
+	)
+
+	return parallelConfig, nil
+}
+
+// getDefaultParallelConfig возвращает настройки параллельной обработки по умолчанию
+// (последовательная обработка, прерывание на первой ошибке).
+func getDefaultParallelConfig() *ParallelConfig {
+	return &ParallelConfig{
+		ExtensionConcurrency: 1,
+		ErrorMode:            defaultExtensionErrorMode,
+	}
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// TestParallelConfig_Parse проверяет парсинг YAML с секцией parallel
+func TestParallelConfig_Parse(t *testing.T) {
+	yamlData := `
+parallel:
+  extension_concurrency: 4
+  error_mode: collect-all
+`
+	var cfg AppConfig
+	err := yaml.Unmarshal([]byte(yamlData), &cfg)
+
+	require.NoError(t, err)
+	assert.Equal(t, 4, cfg.Parallel.ExtensionConcurrency)
+	assert.Equal(t, "collect-all", cfg.Parallel.ErrorMode)
+}
+
+// TestParallelConfig_Defaults проверяет что по умолчанию обработка последовательная
+func TestParallelConfig_Defaults(t *testing.T) {
+	p := getDefaultParallelConfig()
+
+	require.NotNil(t, p)
+	assert.Equal(t, 1, p.ExtensionConcurrency)
+	assert.Equal(t, "fail-fast", p.ErrorMode)
+}
+
+// TestParallelConfig_EnvOverride проверяет что env vars переопределяют AppConfig
+func TestParallelConfig_EnvOverride(t *testing.T) {
+	t.Setenv("BR_EXTENSION_CONCURRENCY", "8")
+	t.Setenv("BR_EXTENSION_ERROR_MODE", "collect-all")
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	cfg := &Config{AppConfig: &AppConfig{Parallel: ParallelConfig{ExtensionConcurrency: 2}}}
+
+	p, err := loadParallelConfig(logger, cfg)
+
+	require.NoError(t, err)
+	assert.Equal(t, 8, p.ExtensionConcurrency)
+	assert.Equal(t, "collect-all", p.ErrorMode)
+}
+
+// TestParallelConfig_Validate проверяет валидацию настроек параллельной обработки
+func TestParallelConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     ParallelConfig
+		wantErr bool
+	}{
+		{name: "empty uses defaults", cfg: ParallelConfig{}},
+		{name: "valid collect-all", cfg: ParallelConfig{ExtensionConcurrency: 4, ErrorMode: "collect-all"}},
+		{name: "negative concurrency", cfg: ParallelConfig{ExtensionConcurrency: -1}, wantErr: true},
+		{name: "unknown mode", cfg: ParallelConfig{ExtensionConcurrency: 2, ErrorMode: "ignore"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.GreaterOrEqual(t, tt.cfg.ExtensionConcurrency, 1)
+			assert.NotEmpty(t, tt.cfg.ErrorMode)
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/pkg/platform"
+	"github.com/ilyakaznacheev/cleanenv"
+)
+
+// PlatformConfig содержит настройки выбора версии платформы 1С
+// при нескольких установленных версиях.
+type PlatformConfig struct {
+	// AutoDetect включает выбор версии по установленным платформам в BasePath:
+	// по режиму совместимости конфигурации или версии сервера 1С (через RAC).
+	// false (default) — используются пути из AppConfig.Paths.
+	AutoDetect bool `yaml:"auto_detect" env:"BR_PLATFORM_AUTODETECT"`
+
+	// BasePath — каталог с установленными версиями платформы (по подкаталогу на версию).
+	BasePath string `yaml:"base_path" env:"BR_PLATFORM_BASE_PATH" env-default:"/opt/1cv8/x86_64"`
+
+	// Version — закреплённая версия платформы (например, 8.3.24.1467).
+	// Включает выбор из BasePath даже без AutoDetect.
+	Version string `yaml:"version" env:"BR_PLATFORM_VERSION"`
+}
+
+// Validate проверяет корректность значений PlatformConfig.
+// Пустые значения заменяются значениями по умолчанию.
+func (c *PlatformConfig) Validate() error {
+	if c.BasePath == "" {
+		c.BasePath = constants.OneCBasePath
+	}
+	if c.Version != "" {
+		v, err := platform.ParseVersion(c.Version)
+		if err != nil {
+			return err
+		}
+		if v.Build == 0 {
+			return fmt.Errorf("недопустимое значение Version: %q, ожидается полная версия платформы (8.3.27.1606)", c.Version)
+		}
+	}
+	return nil
+}
+
+// Enabled сообщает, выбирается ли версия платформы из BasePath.
+func (c *PlatformConfig) Enabled() bool {
+	return c != nil && (c.AutoDetect || c.Version != "")
+}
+
+// PlatformResolver возвращает Resolver установленных версий платформы
+// или nil, если выбор версии не включён (используются AppConfig.Paths).
+func (cfg *Config) PlatformResolver() *platform.Resolver {
+	if !cfg.PlatformConfig.Enabled() {
+		return nil
+	}
+	// Version проверен в Validate: пустое значение — версия не закреплена.
+	pinned, _ := platform.ParseVersion(cfg.PlatformConfig.Version)
+	basePath := cfg.PlatformConfig.BasePath
+	if basePath == "" {
+		basePath = constants.OneCBasePath
+	}
+	return platform.NewResolver(basePath, pinned)
+}
+
+// PlatformTool возвращает путь к исполняемому файлу платформы tool для требования req.
+// Если выбор версии не включён, возвращается путь из AppConfig.Paths.
+func (cfg *Config) PlatformTool(tool platform.Tool, req platform.Requirement) (string, error) {
+	if resolver := cfg.PlatformResolver(); resolver != nil {
+		return resolver.ResolvePath(tool, req)
+	}
+	if cfg.AppConfig == nil {
+		return "", nil
+	}
+	switch tool {
+	case platform.Tool1cv8:
+		return cfg.AppConfig.Paths.Bin1cv8, nil
+	case platform.ToolIbcmd:
+		return cfg.AppConfig.Paths.BinIbcmd, nil
+	case platform.ToolRac:
+		return cfg.AppConfig.Paths.Rac, nil
+	default:
+		return "", fmt.Errorf("неизвестный инструмент платформы: %s", tool)
+	}
+}
+
+// loadPlatformConfig загружает настройки выбора платформы из AppConfig, переменных окружения или устанавливает значения по умолчанию
+//
+//nolint:dupl // similar structure to loadParallelConfig
+func loadPlatformConfig(l *slog.Logger, cfg *Config) (*PlatformConfig, error) {
+	if cfg.AppConfig != nil && (cfg.AppConfig.Platform != PlatformConfig{}) {
+		platformConfig := &cfg.AppConfig.Platform
+		if err := cleanenv.ReadEnv(platformConfig); err != nil {
+			l.Warn("Ошибка загрузки Platform конфигурации из переменных окружения",
+				slog.String("error", err.Error()),
+			)
+		}
+		l.Info("Platform конфигурация загружена из AppConfig",
+			slog.Bool("auto_detect", platformConfig.AutoDetect),
+			slog.String("base_path", platformConfig.BasePath),
+			slog.String("version", platformConfig.Version),
+		)
+		return platformConfig, nil
+	}
+
+	platformConfig := getDefaultPlatformConfig()
+	if err := cleanenv.ReadEnv(platformConfig); err != nil {
+		l.Warn("Ошибка загрузки Platform конфигурации из переменных окружения",
+			slog.String("error", err.Error()),
+		)
+	}
+
+	l.Debug("Platform конфигурация: используются значения по умолчанию",
+		slog.Bool("auto_detect", platformConfig.AutoDetect),
+		slog.String("base_path", platformConfig.BasePath),
+		slog.String("version", platformConfig.Version),
+	)
+
+	return platformConfig, nil
+}
+
+// getDefaultPlatformConfig возвращает настройки выбора платформы по умолчанию
+// (выбор версии отключён, используются AppConfig.Paths).
+func getDefaultPlatformConfig() *PlatformConfig {
+	return &PlatformConfig{
+		BasePath: constants.OneCBasePath,
+	}
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/pkg/platform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// TestPlatformConfig_Parse проверяет парсинг YAML с секцией platform
+func TestPlatformConfig_Parse(t *testing.T) {
+	yamlData := `
+platform:
+  auto_detect: true
+  base_path: /opt/1cv8/x86_64
+  version: 8.3.24.1467
+`
+	var cfg AppConfig
+	err := yaml.Unmarshal([]byte(yamlData), &cfg)
+
+	require.NoError(t, err)
+	assert.True(t, cfg.Platform.AutoDetect)
+	assert.Equal(t, "/opt/1cv8/x86_64", cfg.Platform.BasePath)
+	assert.Equal(t, "8.3.24.1467", cfg.Platform.Version)
+}
+
+// TestPlatformConfig_EnvOverride проверяет что env vars переопределяют значения по умолчанию
+func TestPlatformConfig_EnvOverride(t *testing.T) {
+	t.Setenv("BR_PLATFORM_AUTODETECT", "true")
+	t.Setenv("BR_PLATFORM_VERSION", "8.3.27.1606")
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	p, err := loadPlatformConfig(logger, &Config{})
+
+	require.NoError(t, err)
+	assert.True(t, p.AutoDetect)
+	assert.Equal(t, "8.3.27.1606", p.Version)
+	assert.Equal(t, constants.OneCBasePath, p.BasePath)
+}
+
+// TestPlatformConfig_Validate проверяет валидацию закреплённой версии платформы
+func TestPlatformConfig_Validate(t *testing.T) {
+	assert.NoError(t, (&PlatformConfig{}).Validate())
+	assert.NoError(t, (&PlatformConfig{Version: "8.3.24.1467"}).Validate())
+	assert.Error(t, (&PlatformConfig{Version: "8.3.24"}).Validate())
+	assert.Error(t, (&PlatformConfig{Version: "latest"}).Validate())
+}
+
+// TestConfig_PlatformTool проверяет выбор исполняемого файла платформы
+func TestConfig_PlatformTool(t *testing.T) {
+	cfg := &Config{AppConfig: &AppConfig{}}
+	cfg.AppConfig.Paths.Rac = "/configured/rac"
+
+	path, err := cfg.PlatformTool(platform.ToolRac, platform.Requirement{})
+	require.NoError(t, err)
+	assert.Equal(t, "/configured/rac", path, "без выбора версии используется AppConfig.Paths")
+	assert.Nil(t, cfg.PlatformResolver())
+
+	base := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(base, "8.3.24.1467"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(base, "8.3.24.1467", "rac"), nil, 0o755)) //nolint:gosec // тестовая заглушка
+	cfg.PlatformConfig = &PlatformConfig{BasePath: base, Version: "8.3.24.1467"}
+
+	path, err = cfg.PlatformTool(platform.ToolRac, platform.Requirement{})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(base, "8.3.24.1467", "rac"), path)
+}
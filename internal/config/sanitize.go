@@ -0,0 +1,106 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SanitizeConfig содержит профили обезличивания данных, применяемые
+// после восстановления тестовой базы (nr-dbrestore). Задаётся в project.yaml:
+//
+//	sanitize:
+//	  profiles:
+//	    - name: personal-data
+//	      mask:
+//	        - object: Справочник.Контрагенты
+//	          attribute: Телефон
+//	        - object: Справочник.Контрагенты
+//	          attribute: КонтактнаяИнформация.Представление
+//	          value: "+7 000 000-00-00"
+//	      exchange-plans: ["*"]
+//	      disable-scheduled-jobs: true
+type SanitizeConfig struct {
+	// Profiles — профили в порядке применения
+	Profiles []SanitizeProfile `yaml:"profiles"`
+}
+
+// SanitizeProfile — декларативный набор операций обезличивания.
+type SanitizeProfile struct {
+	// Name — имя профиля (отображается в отчёте)
+	Name string `yaml:"name"`
+	// Databases — тестовые базы, к которым применяется профиль (пусто — все)
+	Databases []string `yaml:"databases"`
+	// Mask — реквизиты объектов 1С, значения которых заменяются
+	Mask []SanitizeMask `yaml:"mask"`
+	// ExchangePlans — планы обмена, очереди изменений которых очищаются ("*" — все)
+	ExchangePlans []string `yaml:"exchange-plans"`
+	// DisableScheduledJobs — отключить использование регламентных заданий
+	DisableScheduledJobs bool `yaml:"disable-scheduled-jobs"`
+	// SQL — произвольные инструкции T-SQL, выполняемые в восстановленной базе
+	SQL []string `yaml:"sql"`
+}
+
+// SanitizeMask описывает маскирование реквизита объекта метаданных.
+type SanitizeMask struct {
+	// Object — объект метаданных: "Справочник.Контрагенты", "Документ.ЗаказКлиента"
+	Object string `yaml:"object"`
+	// Attribute — реквизит объекта или "ТабличнаяЧасть.Реквизит"
+	Attribute string `yaml:"attribute"`
+	// Value — значение, записываемое вместо исходного (по умолчанию пустая строка)
+	Value string `yaml:"value"`
+}
+
+// ProfilesFor возвращает профили, применимые к базе dbName.
+func (c *SanitizeConfig) ProfilesFor(dbName string) []SanitizeProfile {
+	if c == nil {
+		return nil
+	}
+	var profiles []SanitizeProfile
+	for _, p := range c.Profiles {
+		if len(p.Databases) == 0 || slices.Contains(p.Databases, dbName) {
+			profiles = append(profiles, p)
+		}
+	}
+	return profiles
+}
+
+// Validate проверяет корректность профилей обезличивания.
+func (c *SanitizeConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	names := make(map[string]bool, len(c.Profiles))
+	for i, p := range c.Profiles {
+		if p.Name == "" {
+			return fmt.Errorf("sanitize.profiles[%d]: не указано имя профиля", i)
+		}
+		if names[p.Name] {
+			return fmt.Errorf("sanitize.profiles[%d]: повторяющееся имя профиля %q", i, p.Name)
+		}
+		names[p.Name] = true
+
+		if len(p.Mask) == 0 && len(p.ExchangePlans) == 0 && !p.DisableScheduledJobs && len(p.SQL) == 0 {
+			return fmt.Errorf("профиль %q: не задано ни одной операции", p.Name)
+		}
+		for j, m := range p.Mask {
+			if _, name, ok := strings.Cut(m.Object, "."); !ok || name == "" {
+				return fmt.Errorf("профиль %q, mask[%d]: объект %q должен иметь вид Вид.Имя", p.Name, j, m.Object)
+			}
+			if m.Attribute == "" {
+				return fmt.Errorf("профиль %q, mask[%d]: не указан реквизит объекта %s", p.Name, j, m.Object)
+			}
+		}
+		for _, plan := range p.ExchangePlans {
+			if plan == "" {
+				return fmt.Errorf("профиль %q: пустое имя плана обмена", p.Name)
+			}
+		}
+		for j, stmt := range p.SQL {
+			if strings.TrimSpace(stmt) == "" {
+				return fmt.Errorf("профиль %q, sql[%d]: пустая инструкция", p.Name, j)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// TestSanitizeConfig_Parse проверяет парсинг секции sanitize из project.yaml
+func TestSanitizeConfig_Parse(t *testing.T) {
+	yamlData := `
+prod:
+  ERP:
+    related:
+      ERP_TEST:
+sanitize:
+  profiles:
+    - name: personal-data
+      databases: [ERP_TEST]
+      mask:
+        - object: Справочник.Контрагенты
+          attribute: Телефон
+          value: "+7 000 000-00-00"
+      exchange-plans: ["*"]
+      disable-scheduled-jobs: true
+      sql:
+        - DELETE FROM _InfoRg123
+`
+	var cfg ProjectConfig
+	require.NoError(t, yaml.Unmarshal([]byte(yamlData), &cfg))
+	require.NotNil(t, cfg.Sanitize)
+	require.NoError(t, cfg.Sanitize.Validate())
+
+	require.Len(t, cfg.Sanitize.Profiles, 1)
+	p := cfg.Sanitize.Profiles[0]
+	assert.Equal(t, "personal-data", p.Name)
+	assert.Equal(t, []SanitizeMask{{Object: "Справочник.Контрагенты", Attribute: "Телефон", Value: "+7 000 000-00-00"}}, p.Mask)
+	assert.Equal(t, []string{"*"}, p.ExchangePlans)
+	assert.True(t, p.DisableScheduledJobs)
+	assert.Equal(t, []string{"DELETE FROM _InfoRg123"}, p.SQL)
+}
+
+// TestSanitizeConfig_ProfilesFor проверяет выбор профилей по целевой базе
+func TestSanitizeConfig_ProfilesFor(t *testing.T) {
+	cfg := &SanitizeConfig{Profiles: []SanitizeProfile{
+		{Name: "all", DisableScheduledJobs: true},
+		{Name: "erp", Databases: []string{"ERP_TEST"}, DisableScheduledJobs: true},
+	}}
+
+	var names []string
+	for _, p := range cfg.ProfilesFor("ERP_TEST") {
+		names = append(names, p.Name)
+	}
+	assert.Equal(t, []string{"all", "erp"}, names)
+	assert.Len(t, cfg.ProfilesFor("ZUP_TEST"), 1)
+
+	var empty *SanitizeConfig
+	assert.Empty(t, empty.ProfilesFor("ERP_TEST"))
+	assert.NoError(t, empty.Validate())
+}
+
+// TestSanitizeConfig_Validate проверяет обнаружение ошибок в профилях
+func TestSanitizeConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile SanitizeProfile
+		wantErr string
+	}{
+		{name: "без имени", profile: SanitizeProfile{SQL: []string{"SELECT 1"}}, wantErr: "не указано имя"},
+		{name: "без операций", profile: SanitizeProfile{Name: "p"}, wantErr: "не задано ни одной операции"},
+		{
+			name:    "объект без вида",
+			profile: SanitizeProfile{Name: "p", Mask: []SanitizeMask{{Object: "Контрагенты", Attribute: "Телефон"}}},
+			wantErr: "Вид.Имя",
+		},
+		{
+			name:    "без реквизита",
+			profile: SanitizeProfile{Name: "p", Mask: []SanitizeMask{{Object: "Справочник.Контрагенты"}}},
+			wantErr: "не указан реквизит",
+		},
+		{name: "пустой план обмена", profile: SanitizeProfile{Name: "p", ExchangePlans: []string{""}}, wantErr: "пустое имя плана"},
+		{name: "пустая инструкция", profile: SanitizeProfile{Name: "p", SQL: []string{" "}}, wantErr: "пустая инструкция"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &SanitizeConfig{Profiles: []SanitizeProfile{tt.profile}}
+			err := cfg.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	dup := &SanitizeConfig{Profiles: []SanitizeProfile{
+		{Name: "p", DisableScheduledJobs: true},
+		{Name: "p", DisableScheduledJobs: true},
+	}}
+	assert.ErrorContains(t, dup.Validate(), "повторяющееся имя")
+}
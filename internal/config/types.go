@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/Kargones/apk-ci/internal/pkg/alerting"
+	"github.com/Kargones/apk-ci/internal/pkg/metrics"
 )
 
 // Все константы перенесены в пакет constants
@@ -47,6 +48,8 @@ type AppConfig struct {
 
 	Logging         LoggingConfig         `yaml:"logging"`
 	Implementations ImplementationsConfig `yaml:"implementations"`
+	Parallel        ParallelConfig        `yaml:"parallel"`
+	Platform        PlatformConfig        `yaml:"platform"`
 	Alerting        AlertingConfig        `yaml:"alerting"`
 	Metrics         MetricsConfig         `yaml:"metrics"`
 	Tracing         TracingConfig         `yaml:"tracing"`
@@ -62,6 +65,8 @@ type ProjectConfig struct {
 		AddDisable []string               `yaml:"add-disable"`
 		Related    map[string]interface{} `yaml:"related"`
 	} `yaml:"prod"`
+	// Sanitize — профили обезличивания данных тестовых баз после восстановления
+	Sanitize *SanitizeConfig `yaml:"sanitize"`
 }
 // SecretConfig представляет секретные данные из файла secret.yaml.
 // Содержит пароли для различных сервисов и токены доступа к внешним системам.
@@ -168,6 +173,12 @@ type Config struct {
 	// Implementations настройки (выбор реализаций операций)
 	ImplementationsConfig *ImplementationsConfig
 
+	// Parallel настройки (параллельная обработка расширений)
+	ParallelConfig *ParallelConfig
+
+	// Platform настройки (выбор версии платформы 1С)
+	PlatformConfig *PlatformConfig
+
 	// RAC настройки
 	RacConfig *RacConfig
 
@@ -183,6 +194,9 @@ type Config struct {
 	// Alerter для отправки алертов из handlers (DI-injected, #59)
 	Alerter alerting.Alerter
 
+	// MetricsCollector для экспорта метрик из handlers (DI-injected, nil вне main)
+	MetricsCollector metrics.Collector
+
 	// MenuMain содержит шаблон главного меню как массив строк
 	MenuMain []string
 
@@ -109,6 +109,28 @@ const (
 	ActNRForceDisconnectSessions = "nr-force-disconnect-sessions"
 	// ActNRServiceModeDisable - действие отключения сервисного режима (NR-команда)
 	ActNRServiceModeDisable = "nr-service-mode-disable"
+	// ActNRServiceModeSchedule - действие вывода запланированных окон сервисного режима (NR-команда)
+	ActNRServiceModeSchedule = "nr-service-mode-schedule"
+	// ActNRClusterInventory - действие инвентаризации информационных баз серверов 1C (NR-команда)
+	ActNRClusterInventory = "nr-cluster-inventory"
+	// ActNRSessionsReport - действие отчёта по сессиям информационных баз (NR-команда)
+	ActNRSessionsReport = "nr-sessions-report"
+	// ActNRScheduledJobs - действие блокировки и восстановления регламентных заданий (NR-команда)
+	ActNRScheduledJobs = "nr-scheduled-jobs"
+	// ActNRServiceModeReaper - действие отключения сервисного режима с истёкшей арендой (NR-команда)
+	ActNRServiceModeReaper = "nr-service-mode-reaper"
+	// ActNRInfobaseCreate - действие регистрации информационной базы в кластере 1C (NR-команда)
+	ActNRInfobaseCreate = "nr-infobase-create"
+	// ActNRInfobaseUpdate - действие изменения параметров СУБД информационной базы (NR-команда)
+	ActNRInfobaseUpdate = "nr-infobase-update"
+	// ActNRInfobaseDrop - действие удаления информационной базы из кластера 1C (NR-команда)
+	ActNRInfobaseDrop = "nr-infobase-drop"
+	// ActNRClusterHealth - действие проверки готовности кластера 1C и СУБД к развёртыванию (NR-команда)
+	ActNRClusterHealth = "nr-cluster-health"
+	// ActNRDbSnapshot - действие управления моментальными снимками тестовой базы MSSQL (NR-команда)
+	ActNRDbSnapshot = "nr-db-snapshot"
+	// ActNRDbrestoreHistory - действие вывода истории восстановлений тестовых баз (NR-команда)
+	ActNRDbrestoreHistory = "nr-dbrestore-history"
 	// ActHelp - действие вывода списка доступных команд
 	ActHelp = "help"
 	// ActNRDbrestore - действие восстановления базы данных (NR-команда)
@@ -151,13 +173,20 @@ const (
 
 	// ActNRConvertPipeline - действие пайплайна конвертации (NR-команда)
 	ActNRConvertPipeline = "nr-convert-pipeline"
+
+	// ActNRStoreRestoreBackup - действие просмотра и восстановления backup хранилища (NR-команда)
+	ActNRStoreRestoreBackup = "nr-store-restore-backup"
+	// ActNRStoreHistory - действие вывода истории версий хранилища (NR-команда)
+	ActNRStoreHistory = "nr-store-history"
+	// ActNRStore2git - действие выгрузки истории хранилища в git (NR-команда)
+	ActNRStore2git = "nr-store2git"
 )
 
 // Константы переменных окружения
 const (
 	// EnvDryRun - имя переменной окружения для активации dry-run режима
 	EnvDryRun = "BR_DRY_RUN"
-	// EnvShadowRun - имя переменной окружения для активации shadow-run режима
+	// EnvShadowRun - имя переменной окружения shadow-run режима (не поддерживается, выводится предупреждение)
 	EnvShadowRun = "BR_SHADOW_RUN"
 	// EnvPlanOnly - имя переменной окружения для активации plan-only режима
 	EnvPlanOnly = "BR_PLAN_ONLY"
@@ -167,6 +196,92 @@ const (
 	EnvMigratePath = "BR_MIGRATE_PATH"
 	// EnvMigrateNoBackup - отключение создания .bak файлов при миграции
 	EnvMigrateNoBackup = "BR_MIGRATE_NO_BACKUP"
+	// EnvResume - checkpoint nr-git2store в WorkDir и продолжение с последнего завершённого этапа (требует хеш коммита)
+	EnvResume = "BR_RESUME"
+	// EnvBackupPath - каталог backup хранилища для nr-store-restore-backup
+	EnvBackupPath = "BR_BACKUP_PATH"
+	// EnvStoreBackupKeep - количество хранимых backup хранилища nr-git2store в TmpDir (0 — без удаления)
+	EnvStoreBackupKeep = "BR_STORE_BACKUP_KEEP"
+	// EnvStoreRoot - переопределение каталога хранилища для nr-store-restore-backup
+	EnvStoreRoot = "BR_STORE_ROOT"
+	// EnvStoreReportFile - готовый txt-отчет хранилища для nr-store-history (без запуска 1cv8)
+	EnvStoreReportFile = "BR_STORE_REPORT_FILE"
+	// EnvStoreHistoryExtension - расширение, историю хранилища которого выводит nr-store-history
+	EnvStoreHistoryExtension = "BR_STORE_HISTORY_EXTENSION"
+	// EnvStoreHistoryFromVersion - начальная версия отчета nr-store-history
+	EnvStoreHistoryFromVersion = "BR_STORE_HISTORY_FROM_VERSION"
+	// EnvStoreHistoryAuthor - фильтр nr-store-history по пользователю хранилища
+	EnvStoreHistoryAuthor = "BR_STORE_HISTORY_AUTHOR"
+	// EnvStoreHistorySince - фильтр nr-store-history: версии не раньше даты
+	EnvStoreHistorySince = "BR_STORE_HISTORY_SINCE"
+	// EnvStoreHistoryUntil - фильтр nr-store-history: версии не позже даты
+	EnvStoreHistoryUntil = "BR_STORE_HISTORY_UNTIL"
+	// EnvStoreHistoryObject - фильтр nr-store-history по объекту метаданных
+	EnvStoreHistoryObject = "BR_STORE_HISTORY_OBJECT"
+	// EnvStore2gitBranch - ветка, в которую nr-store2git выгружает историю хранилища
+	EnvStore2gitBranch = "BR_STORE2GIT_BRANCH"
+	// EnvStore2gitExtension - расширение, история хранилища которого выгружается nr-store2git
+	EnvStore2gitExtension = "BR_STORE2GIT_EXTENSION"
+	// EnvStore2gitLimit - максимальное количество версий, выгружаемых nr-store2git за запуск
+	EnvStore2gitLimit = "BR_STORE2GIT_LIMIT"
+	// EnvStore2gitAuthors - YAML-файл соответствия пользователей хранилища авторам git
+	EnvStore2gitAuthors = "BR_STORE2GIT_AUTHORS"
+	// EnvServiceModeFrom - начало окна сервисного режима для nr-service-mode-enable (RAC --denied-from)
+	EnvServiceModeFrom = "BR_SERVICE_MODE_FROM"
+	// EnvServiceModeTo - окончание окна сервисного режима (время или длительность от начала, RAC --denied-to)
+	EnvServiceModeTo = "BR_SERVICE_MODE_TO"
+	// EnvDisconnectDrainSec - период мягкого завершения сессий nr-force-disconnect-sessions (drain), секунды
+	EnvDisconnectDrainSec = "BR_DISCONNECT_DRAIN_SEC"
+	// EnvDisconnectPollSec - интервал опроса сессий в режиме drain, секунды
+	EnvDisconnectPollSec = "BR_DISCONNECT_POLL_SEC"
+	// EnvDisconnectKeepApps - AppID сессий, которые не завершаются (через запятую, например Designer)
+	EnvDisconnectKeepApps = "BR_DISCONNECT_KEEP_APPS"
+	// EnvDisconnectKeepUsers - пользователи, сессии которых не завершаются (через запятую)
+	EnvDisconnectKeepUsers = "BR_DISCONNECT_KEEP_USERS"
+	// EnvSessionsIdleMin - порог простоя сессии для nr-sessions-report, минуты
+	EnvSessionsIdleMin = "BR_SESSIONS_IDLE_MIN"
+	// EnvScheduledJobsAction - действие nr-scheduled-jobs: status, block, unblock, restore
+	EnvScheduledJobsAction = "BR_SCHEDULED_JOBS_ACTION"
+	// EnvScheduledJobsSnapshot - путь к файлу снимка состояния регламентных заданий на общем для агентов ресурсе (обязателен для block/restore)
+	EnvScheduledJobsSnapshot = "BR_SCHEDULED_JOBS_SNAPSHOT"
+	// EnvServiceModeLeaseTTL - срок аренды сервисного режима (длительность, 0 — без аренды; аренда не истекает раньше окончания окна)
+	EnvServiceModeLeaseTTL = "BR_SERVICE_MODE_LEASE_TTL"
+	// EnvServiceModeLeaseOwner - владелец аренды сервисного режима (по умолчанию — запуск workflow)
+	EnvServiceModeLeaseOwner = "BR_SERVICE_MODE_LEASE_OWNER"
+	// EnvServiceModeForce - отключение сервисного режима, удерживаемого чужой арендой
+	EnvServiceModeForce = "BR_SERVICE_MODE_FORCE"
+	// EnvInfobaseServer - сервер 1C для nr-infobase-* (по умолчанию — one-server из DbConfig)
+	EnvInfobaseServer = "BR_INFOBASE_SERVER"
+	// EnvInfobaseDBMS - тип СУБД информационной базы (по умолчанию MSSQLServer)
+	EnvInfobaseDBMS = "BR_INFOBASE_DBMS"
+	// EnvInfobaseDbServer - сервер СУБД информационной базы (по умолчанию — dbserver из DbConfig)
+	EnvInfobaseDbServer = "BR_INFOBASE_DB_SERVER"
+	// EnvInfobaseDbName - имя базы данных СУБД (по умолчанию — имя информационной базы)
+	EnvInfobaseDbName = "BR_INFOBASE_DB_NAME"
+	// EnvInfobaseCreateDatabase - создать базу данных СУБД при регистрации информационной базы
+	EnvInfobaseCreateDatabase = "BR_INFOBASE_CREATE_DATABASE"
+	// EnvInfobaseDropMode - режим nr-infobase-drop: keep (по умолчанию), drop, clear
+	EnvInfobaseDropMode = "BR_INFOBASE_DROP_MODE"
+	// EnvInfobaseDbConfigPR - предлагать изменение dbconfig.yaml через Pull Request (по умолчанию true)
+	EnvInfobaseDbConfigPR = "BR_INFOBASE_DBCONFIG_PR"
+	// EnvHealthRphostMemoryMB - порог памяти рабочего процесса (МБ) для предупреждения nr-cluster-health
+	EnvHealthRphostMemoryMB = "BR_HEALTH_RPHOST_MEMORY_MB"
+	// EnvHealthRphostConnections - порог соединений рабочего процесса для предупреждения nr-cluster-health
+	EnvHealthRphostConnections = "BR_HEALTH_RPHOST_CONNECTIONS"
+	// EnvDbSnapshotAction - действие nr-db-snapshot: status, create, revert, drop
+	EnvDbSnapshotAction = "BR_DB_SNAPSHOT_ACTION"
+	// EnvDbSnapshotName - имя снимка базы данных (по умолчанию <база>_snapshot)
+	EnvDbSnapshotName = "BR_DB_SNAPSHOT_NAME"
+	// EnvDbSnapshotServiceMode - включать сервисный режим на время возврата к снимку (по умолчанию true)
+	EnvDbSnapshotServiceMode = "BR_DB_SNAPSHOT_SERVICE_MODE"
+	// EnvDbrestoreHistoryUser - фильтр nr-dbrestore-history по пользователю (подстрока)
+	EnvDbrestoreHistoryUser = "BR_DBRESTORE_HISTORY_USER"
+	// EnvDbrestoreHistoryFrom - начало периода nr-dbrestore-history (дата или RFC3339)
+	EnvDbrestoreHistoryFrom = "BR_DBRESTORE_HISTORY_FROM"
+	// EnvDbrestoreHistoryTo - окончание периода nr-dbrestore-history (дата включительно или RFC3339)
+	EnvDbrestoreHistoryTo = "BR_DBRESTORE_HISTORY_TO"
+	// EnvDbrestoreHistoryLimit - максимальное число записей nr-dbrestore-history (по умолчанию 50)
+	EnvDbrestoreHistoryLimit = "BR_DBRESTORE_HISTORY_LIMIT"
 )
 
 // Константы заголовков задач
@@ -240,6 +355,10 @@ const (
 const (
 	// SearchMsgStoreLockOk - сообщение об успешном захвате объектов в хранилище
 	SearchMsgStoreLockOk = "Захват объектов в хранилище успешно завершен"
+	// SearchMsgStoreUnlockOk - сообщение об успешном освобождении объектов в хранилище
+	SearchMsgStoreUnlockOk = "Отмена захвата объектов в хранилище успешно завершена"
+	// SearchMsgStoreReportOk - сообщение об успешном построении отчета по хранилищу
+	SearchMsgStoreReportOk = "Отчет успешно построен"
 	// SearchMsgStoreMergeOk - сообщение об успешном объединении конфигураций
 	SearchMsgStoreMergeOk = "Объединение конфигураций успешно завершено"
 	// SearchMsgStoreBindOk - сообщение об успешном подключении к хранилищу
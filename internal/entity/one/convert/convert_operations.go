@@ -7,9 +7,12 @@ import (
 	"log/slog"
 	"os"
 	"path"
+	"time"
 
 	"github.com/Kargones/apk-ci/internal/config"
 	"github.com/Kargones/apk-ci/internal/constants"
+	"github.com/Kargones/apk-ci/internal/entity/one/store"
+	"github.com/Kargones/apk-ci/internal/pkg/workerpool"
 )
 
 // ToDo: Обобщить функцию загрузки конфигурации конвертации для любого типа базы данных
@@ -47,7 +50,8 @@ func (cc *Config) InitDb(ctx context.Context, l *slog.Logger, cfg *config.Config
 }
 
 // LoadDb загружает конфигурацию из файлов в базу данных.
-// Последовательно загружает основные конфигурации и дополнительные расширения.
+// Сначала загружает основные конфигурации, затем расширения через пул
+// (загрузка изменяет конфигурацию базы и выполняется последовательно).
 // Параметры:
 //   - ctx: контекст выполнения операции
 //   - l: логгер для записи отладочной информации
@@ -56,30 +60,25 @@ func (cc *Config) InitDb(ctx context.Context, l *slog.Logger, cfg *config.Config
 // Возвращает:
 //   - error: ошибка загрузки конфигурации, nil при успехе
 func (cc *Config) LoadDb(ctx context.Context, l *slog.Logger, cfg *config.Config) error {
-	var err error
+	cc.extensionResults = nil
 	for _, cp := range cc.Pair {
 		if !cp.Source.Main {
 			continue
 		}
-		err = cc.OneDB.Load(ctx, l, cfg, path.Join(cfg.RepPath, cp.Source.RelPath))
+		err := cc.OneDB.Load(ctx, l, cfg, path.Join(cfg.RepPath, cp.Source.RelPath))
 		if err != nil {
 			return err
 		}
 	}
-	for _, cp := range cc.Pair {
-		if cp.Source.Main {
-			continue
-		}
-		err = cc.OneDB.LoadAdd(ctx, l, cfg, path.Join(cfg.RepPath, cp.Source.RelPath), cp.Source.Name)
-		if err != nil {
-			return err
-		}
-	}
-	return err
+	return cc.runExtensions(ctx, l, extensionOptions(cfg), "LoadAdd", true, func(ctx context.Context, cp Pair) error {
+		return cc.OneDB.LoadAdd(ctx, l, cfg, path.Join(cfg.RepPath, cp.Source.RelPath), cp.Source.Name)
+	})
 }
 
 // DumpDb выгружает конфигурацию из базы данных в файлы.
-// Выполняет выгрузку основной конфигурации и всех дополнительных расширений.
+// Выполняет выгрузку основной конфигурации, затем всех расширений. Выгрузка
+// расширения (DESIGNER /DumpDBCfg) открывает конфигуратор той же базы, поэтому
+// расширения выгружаются последовательно независимо от ExtensionConcurrency.
 // Параметры:
 //   - ctx: контекст выполнения операции
 //   - l: логгер для записи отладочной информации
@@ -88,24 +87,19 @@ func (cc *Config) LoadDb(ctx context.Context, l *slog.Logger, cfg *config.Config
 // Возвращает:
 //   - error: ошибка выгрузки конфигурации, nil при успехе
 func (cc *Config) DumpDb(ctx context.Context, l *slog.Logger, cfg *config.Config) error {
+	cc.extensionResults = nil
 	err := cc.OneDB.Dump(ctx, l, cfg)
 	if err != nil {
 		return err
 	}
-	for _, cp := range cc.Pair {
-		if cp.Source.Main {
-			continue
-		}
-		err = cc.OneDB.DumpAdd(ctx, l, cfg, cp.Source.Name)
-		if err != nil {
-			return err
-		}
-	}
-	return err
+	return cc.runExtensions(ctx, l, extensionOptions(cfg), "DumpAdd", true, func(ctx context.Context, cp Pair) error {
+		return cc.OneDB.DumpAdd(ctx, l, cfg, cp.Source.Name)
+	})
 }
 
 // StoreLock блокирует объекты в хранилище конфигурации для редактирования.
-// Выполняет блокировку основных конфигураций и дополнительных расширений.
+// Выполняет блокировку основных конфигураций, затем расширений. Захват объектов
+// идёт через сеанс конфигуратора той же базы, поэтому расширения обрабатываются последовательно.
 // Параметры:
 //   - ctx: контекст выполнения операции
 //   - l: логгер для записи отладочной информации
@@ -114,31 +108,57 @@ func (cc *Config) DumpDb(ctx context.Context, l *slog.Logger, cfg *config.Config
 // Возвращает:
 //   - error: ошибка блокировки объектов, nil при успехе
 func (cc *Config) StoreLock(ctx context.Context, l *slog.Logger, cfg *config.Config) error {
-	var err error
+	cc.extensionResults = nil
 	for _, cp := range cc.Pair {
 		if !cp.Source.Main {
 			continue
 		}
-		err = cp.Store.Lock(ctx, l, cfg, cc.OneDB.FullConnectString, cc.StoreRoot)
+		err := cp.Store.Lock(ctx, l, cfg, cc.OneDB.FullConnectString, cc.StoreRoot)
 		if err != nil {
 			return err
 		}
 	}
+	return cc.runExtensions(ctx, l, extensionOptions(cfg), "LockAdd", true, func(ctx context.Context, cp Pair) error {
+		return cp.Store.LockAdd(ctx, l, cfg, cc.OneDB.FullConnectString, cc.StoreRoot, cp.Source.Name)
+	})
+}
 
+// StoreUnlock освобождает объекты, захваченные в хранилище конфигурации.
+// Используется при откате git2store. В отличие от StoreLock, обрабатывает
+// все пары до конца (расширения — в режиме collect-all) и возвращает первую ошибку,
+// чтобы освободить максимум объектов.
+// Параметры:
+//   - ctx: контекст выполнения операции
+//   - l: логгер для записи отладочной информации
+//   - cfg: основная конфигурация приложения
+//
+// Возвращает:
+//   - error: первую ошибку освобождения объектов или nil
+func (cc *Config) StoreUnlock(ctx context.Context, l *slog.Logger, cfg *config.Config) error {
+	var firstErr error
 	for _, cp := range cc.Pair {
-		if cp.Source.Main {
+		if !cp.Source.Main {
 			continue
 		}
-		err = cp.Store.LockAdd(ctx, l, cfg, cc.OneDB.FullConnectString, cc.StoreRoot, cp.Source.Name)
-		if err != nil {
-			return err
+		if err := cp.Store.Unlock(ctx, l, cfg, cc.OneDB.FullConnectString, cc.StoreRoot); err != nil && firstErr == nil {
+			firstErr = err
 		}
 	}
-	return err
+
+	opts := extensionOptions(cfg)
+	opts.Mode = workerpool.ModeCollectAll
+	err := cc.runExtensions(ctx, l, opts, "UnlockAdd", true, func(ctx context.Context, cp Pair) error {
+		return cp.Store.UnlockAdd(ctx, l, cfg, cc.OneDB.FullConnectString, cc.StoreRoot, cp.Source.Name)
+	})
+	if firstErr == nil {
+		firstErr = err
+	}
+	return firstErr
 }
 
 // StoreBind привязывает хранилища конфигурации к базе данных.
 // Устанавливает связь между файловыми хранилищами и базой данных для синхронизации.
+// Привязка изменяет конфигурацию базы, поэтому расширения привязываются последовательно.
 // Параметры:
 //   - ctx: контекст выполнения операции
 //   - l: логгер для записи отладочной информации
@@ -147,6 +167,7 @@ func (cc *Config) StoreLock(ctx context.Context, l *slog.Logger, cfg *config.Con
 // Возвращает:
 //   - error: ошибка привязки хранилищ, nil при успехе
 func (cc *Config) StoreBind(ctx context.Context, l *slog.Logger, cfg *config.Config) error {
+	cc.extensionResults = nil
 	for _, cp := range cc.Pair {
 		if !cp.Source.Main {
 			continue
@@ -160,19 +181,12 @@ func (cc *Config) StoreBind(ctx context.Context, l *slog.Logger, cfg *config.Con
 		}
 	}
 
-	for _, cp := range cc.Pair {
-		if cp.Source.Main {
-			continue
-		}
+	return cc.runExtensions(ctx, l, extensionOptions(cfg), "BindAdd", true, func(ctx context.Context, cp Pair) error {
 		l.Debug("Привязка хранилища расширения к базе данных",
 			slog.String("Имя хранилища", cp.Source.Name),
 		)
-		bindAddErr := cp.Store.BindAdd(ctx, l, cfg, cc.OneDB.FullConnectString, cc.StoreRoot, cp.Source.Name)
-		if bindAddErr != nil {
-			return bindAddErr
-		}
-	}
-	return nil
+		return cp.Store.BindAdd(ctx, l, cfg, cc.OneDB.FullConnectString, cc.StoreRoot, cp.Source.Name)
+	})
 }
 
 // DbUpdate обновляет конфигурацию базы данных из привязанных хранилищ.
@@ -242,7 +256,8 @@ func (cc *Config) StoreUnBind(ctx context.Context, l *slog.Logger, cfg *config.C
 }
 
 // StoreCommit фиксирует изменения в хранилище конфигурации.
-// Создает новую версию в хранилище с автоматически сгенерированным комментарием.
+// Создает новую версию в хранилище с комментарием, содержащим репозиторий и коммит.
+// Помещение расширений открывает конфигуратор той же базы, поэтому выполняется последовательно.
 // Параметры:
 //   - ctx: контекст выполнения операции
 //   - l: логгер для записи отладочной информации
@@ -251,34 +266,28 @@ func (cc *Config) StoreUnBind(ctx context.Context, l *slog.Logger, cfg *config.C
 // Возвращает:
 //   - error: ошибка фиксации изменений, nil при успехе
 func (cc *Config) StoreCommit(ctx context.Context, l *slog.Logger, cfg *config.Config) error {
-	var err error
-	comment := "Версия создана автоматически\nИсходный репозиторий ххх\nДата создания ххх"
+	cc.extensionResults = nil
+	// Хеш коммита в комментарии позволяет сопоставить версию хранилища с git (nr-store-history).
+	comment := store.CommitComment(cfg.Owner, cfg.Repo, cfg.CommitHash, time.Now())
 	for _, cp := range cc.Pair {
 		if !cp.Source.Main {
 			continue
 		}
-		err = cp.Store.StoreCommit(ctx, l, cfg, cc.OneDB.FullConnectString, cc.StoreRoot, comment)
-		if err != nil {
-			return err
-		}
-	}
-
-	for _, cp := range cc.Pair {
-		if cp.Source.Main {
-			continue
-		}
-		err = cp.Store.StoreCommitAdd(ctx, l, cfg, cc.OneDB.FullConnectString, cc.StoreRoot, comment, cp.Source.Name)
+		err := cp.Store.StoreCommit(ctx, l, cfg, cc.OneDB.FullConnectString, cc.StoreRoot, comment)
 		if err != nil {
 			return err
 		}
 	}
-	return err
+	return cc.runExtensions(ctx, l, extensionOptions(cfg), "StoreCommitAdd", true, func(ctx context.Context, cp Pair) error {
+		return cp.Store.StoreCommitAdd(ctx, l, cfg, cc.OneDB.FullConnectString, cc.StoreRoot, comment, cp.Source.Name)
+	})
 }
 
 // func (s *Store) StoreCommitAdd(ctx context.Context, l *slog.Logger, cfg *config.Config, dbConnectString string, storeRoot string, comment string, addName string) error {
 
 // Merge выполняет слияние конфигурации с хранилищем.
 // Объединяет изменения из рабочей директории с версией в хранилище конфигурации.
+// Слияние изменяет конфигурацию базы, поэтому расширения обрабатываются последовательно.
 // Параметры:
 //   - ctx: контекст выполнения операции
 //   - l: логгер для записи отладочной информации
@@ -287,6 +296,7 @@ func (cc *Config) StoreCommit(ctx context.Context, l *slog.Logger, cfg *config.C
 // Возвращает:
 //   - error: ошибка слияния конфигурации, nil при успехе
 func (cc *Config) Merge(ctx context.Context, l *slog.Logger, cfg *config.Config) error {
+	cc.extensionResults = nil
 	mergeSettingFileName, err := mergeSetting(cfg)
 	if err != nil {
 		l.Error("ошибка создания файла настроек слияния",
@@ -310,16 +320,9 @@ func (cc *Config) Merge(ctx context.Context, l *slog.Logger, cfg *config.Config)
 		}
 	}
 
-	for _, cp := range cc.Pair {
-		if cp.Source.Main {
-			continue
-		}
-		err = cp.Store.MergeAdd(ctx, l, cfg, cc.OneDB.FullConnectString, path.Join(cfg.WorkDir, cp.Source.Name+".cfe"), mergeSettingFileName, cc.StoreRoot, cp.Source.Name)
-		if err != nil {
-			return err
-		}
-	}
-	return err
+	return cc.runExtensions(ctx, l, extensionOptions(cfg), "MergeAdd", true, func(ctx context.Context, cp Pair) error {
+		return cp.Store.MergeAdd(ctx, l, cfg, cc.OneDB.FullConnectString, path.Join(cfg.WorkDir, cp.Source.Name+".cfe"), mergeSettingFileName, cc.StoreRoot, cp.Source.Name)
+	})
 }
 
 // Save сохраняет текущую конфигурацию конвертации в указанный файл.
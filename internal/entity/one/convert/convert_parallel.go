@@ -0,0 +1,95 @@
+package convert
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/pkg/workerpool"
+)
+
+// extensionOp — операция над одним расширением (парой без признака Main).
+type extensionOp func(ctx context.Context, cp Pair) error
+
+// ExtensionResults возвращает результаты обработки расширений последней
+// операции (LoadDb, DumpDb, StoreBind, StoreLock, Merge, StoreCommit и т.д.)
+// в порядке сопоставлений. nil — операция не обрабатывала расширения.
+func (cc *Config) ExtensionResults() []workerpool.Result {
+	return cc.extensionResults
+}
+
+// extensionOptions возвращает параметры пула обработки расширений из cfg.ParallelConfig.
+// Без настроек расширения обрабатываются последовательно с прерыванием на первой ошибке.
+func extensionOptions(cfg *config.Config) workerpool.Options {
+	opts := workerpool.Options{Concurrency: 1, Mode: workerpool.ModeFailFast}
+	if cfg == nil || cfg.ParallelConfig == nil {
+		return opts
+	}
+	if cfg.ParallelConfig.ExtensionConcurrency > 1 {
+		opts.Concurrency = cfg.ParallelConfig.ExtensionConcurrency
+	}
+	if mode, err := workerpool.ParseMode(cfg.ParallelConfig.ErrorMode); err == nil {
+		opts.Mode = mode
+	}
+	return opts
+}
+
+// runExtensions выполняет op для всех расширений через ограниченный пул и
+// сохраняет результаты для ExtensionResults.
+// exclusive — операция изменяет конфигурацию информационной базы и требует
+// монопольного доступа к ней: такие задачи над одной базой выполняются
+// последовательно независимо от ExtensionConcurrency.
+func (cc *Config) runExtensions(ctx context.Context, l *slog.Logger, opts workerpool.Options, operation string, exclusive bool, op extensionOp) error {
+	var tasks []workerpool.Task
+	for _, cp := range cc.Pair {
+		if cp.Source.Main {
+			continue
+		}
+		task := workerpool.Task{
+			Name: cp.Source.Name,
+			Run: func(ctx context.Context) error {
+				return op(ctx, cp)
+			},
+		}
+		if exclusive {
+			task.LockKey = cc.OneDB.FullConnectString
+		}
+		tasks = append(tasks, task)
+	}
+	if len(tasks) == 0 {
+		cc.extensionResults = nil
+		return nil
+	}
+
+	l.Debug("Обработка расширений",
+		slog.String("operation", operation),
+		slog.Int("extensions", len(tasks)),
+		slog.Int("concurrency", opts.Concurrency),
+		slog.String("mode", string(opts.Mode)),
+		slog.Bool("exclusive", exclusive),
+	)
+	results, err := workerpool.Run(ctx, opts, tasks)
+	cc.extensionResults = results
+	for _, r := range results {
+		switch {
+		case r.Success:
+			l.Debug("Расширение обработано",
+				slog.String("operation", operation),
+				slog.String("extension", r.Name),
+				slog.Int64("duration_ms", r.DurationMs),
+			)
+		case r.Skipped:
+			l.Warn("Расширение не обработано: выполнение прервано",
+				slog.String("operation", operation),
+				slog.String("extension", r.Name),
+			)
+		default:
+			l.Error("Ошибка обработки расширения",
+				slog.String("operation", operation),
+				slog.String("extension", r.Name),
+				slog.String("error", r.Error),
+			)
+		}
+	}
+	return err
+}
@@ -0,0 +1,115 @@
+package convert
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/entity/one/designer"
+	"github.com/Kargones/apk-ci/internal/pkg/workerpool"
+)
+
+func parallelTestConfig() *Config {
+	return &Config{
+		OneDB: designer.OneDb{FullConnectString: "/F /tmp/db"},
+		Pair: []Pair{
+			{Source: Source{Name: "Main", Main: true}},
+			{Source: Source{Name: "ext1"}},
+			{Source: Source{Name: "ext2"}},
+			{Source: Source{Name: "ext3"}},
+		},
+	}
+}
+
+func TestExtensionOptions(t *testing.T) {
+	opts := extensionOptions(&config.Config{})
+	if opts.Concurrency != 1 || opts.Mode != workerpool.ModeFailFast {
+		t.Errorf("defaults = %+v, want concurrency 1 fail-fast", opts)
+	}
+
+	opts = extensionOptions(&config.Config{ParallelConfig: &config.ParallelConfig{ExtensionConcurrency: 4, ErrorMode: "collect-all"}})
+	if opts.Concurrency != 4 || opts.Mode != workerpool.ModeCollectAll {
+		t.Errorf("opts = %+v, want concurrency 4 collect-all", opts)
+	}
+}
+
+func TestRunExtensions_SkipsMainAndRecordsResults(t *testing.T) {
+	cc := parallelTestConfig()
+	var mu sync.Mutex
+	var seen []string
+
+	err := cc.runExtensions(context.Background(), slog.Default(), workerpool.Options{Concurrency: 3, Mode: workerpool.ModeCollectAll}, "DumpAdd", false,
+		func(_ context.Context, cp Pair) error {
+			mu.Lock()
+			seen = append(seen, cp.Source.Name)
+			mu.Unlock()
+			if cp.Source.Name == "ext2" {
+				return errors.New("dump failed")
+			}
+			return nil
+		})
+
+	if err == nil || err.Error() != "ext2: dump failed" {
+		t.Fatalf("err = %v, want ext2: dump failed", err)
+	}
+	if len(seen) != 3 {
+		t.Errorf("seen = %v, want 3 extensions without Main", seen)
+	}
+	results := cc.ExtensionResults()
+	if len(results) != 3 {
+		t.Fatalf("results = %d, want 3", len(results))
+	}
+	for i, want := range []struct {
+		name    string
+		success bool
+	}{{"ext1", true}, {"ext2", false}, {"ext3", true}} {
+		if results[i].Name != want.name || results[i].Success != want.success {
+			t.Errorf("results[%d] = %+v, want %s success=%v", i, results[i], want.name, want.success)
+		}
+	}
+}
+
+func TestRunExtensions_ExclusiveSerializes(t *testing.T) {
+	cc := parallelTestConfig()
+	var running, peak atomic.Int32
+
+	err := cc.runExtensions(context.Background(), slog.Default(), workerpool.Options{Concurrency: 3}, "LoadAdd", true,
+		func(context.Context, Pair) error {
+			n := running.Add(1)
+			if n > peak.Load() {
+				peak.Store(n)
+			}
+			time.Sleep(5 * time.Millisecond)
+			running.Add(-1)
+			return nil
+		})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if peak.Load() != 1 {
+		t.Errorf("peak = %d, exclusive operations must not overlap", peak.Load())
+	}
+}
+
+func TestRunExtensions_NoExtensions(t *testing.T) {
+	cc := &Config{
+		Pair:             []Pair{{Source: Source{Name: "Main", Main: true}}},
+		extensionResults: []workerpool.Result{{Name: "stale"}},
+	}
+
+	err := cc.runExtensions(context.Background(), slog.Default(), workerpool.Options{Concurrency: 2}, "DumpAdd", false,
+		func(context.Context, Pair) error { return nil })
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cc.ExtensionResults() != nil {
+		t.Errorf("ExtensionResults() = %v, want nil", cc.ExtensionResults())
+	}
+}
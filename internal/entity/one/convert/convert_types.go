@@ -4,6 +4,7 @@ package convert
 import (
 	"github.com/Kargones/apk-ci/internal/entity/one/designer"
 	"github.com/Kargones/apk-ci/internal/entity/one/store"
+	"github.com/Kargones/apk-ci/internal/pkg/workerpool"
 )
 
 // MergeSettingsString - строка настроек слияния конфигураций.
@@ -24,6 +25,9 @@ type Config struct {
 	StoreRoot string         `json:"Корень хранилища"`
 	OneDB     designer.OneDb `json:"Параметры подключения"`
 	Pair      []Pair         `json:"Сопоставления"`
+
+	// extensionResults — результаты обработки расширений последней операции.
+	extensionResults []workerpool.Result
 }
 
 // Pair представляет пару "источник-назначение" для операции конвертации.
@@ -6,7 +6,6 @@ import (
 	"log/slog"
 	"os"
 	"path"
-	"strings"
 
 	"github.com/Kargones/apk-ci/internal/config"
 	"github.com/Kargones/apk-ci/internal/constants"
@@ -43,16 +42,6 @@ func (odb *OneDb) Create(ctx context.Context, l *slog.Logger, cfg *config.Config
 		)
 		return err
 	}
-	// Проверяем успешность создания базы данных (поддерживаем русский и английский варианты)
-	if !strings.Contains(string(r.ConsoleOut), constants.SearchMsgBaseCreateOk) &&
-		!strings.Contains(string(r.ConsoleOut), constants.SearchMsgBaseCreateOkEn) {
-		badOutErr := fmt.Errorf("неопознанная ошибка создания базы данных: %s", string(r.ConsoleOut))
-		l.Error("Неопознанная ошибка создания базы данных",
-			slog.String("Путь", dbPath),
-			slog.String("Error", badOutErr.Error()),
-		)
-		return badOutErr
-	}
 	odb.DbConnectString = dbPath
 	odb.FullConnectString = "/F " + dbPath
 	return err
@@ -88,13 +77,6 @@ func (odb *OneDb) Add(ctx context.Context, l *slog.Logger, cfg *config.Config, n
 		)
 		return err
 	}
-	if !strings.Contains(string(r.ConsoleOut), constants.SearchMsgBaseAddOk) {
-		l.Error("Неопознанная ошибка добавления расширения",
-			slog.String("Путь", odb.DbConnectString),
-			slog.String("Имя", nameAdd),
-		)
-		return err
-	}
 	return err
 }
 
@@ -171,14 +153,6 @@ func CreateTempDb(ctx context.Context, logger *slog.Logger, cfg *config.Config,
 		return odb, fmt.Errorf("ошибка создания временной базы данных: %w", err)
 	}
 
-	// Проверяем успешность создания базы данных (поддерживаем русский и английский варианты)
-	if !strings.Contains(string(r.ConsoleOut), constants.SearchMsgBaseCreateOk) &&
-		!strings.Contains(string(r.ConsoleOut), constants.SearchMsgBaseCreateOkEn) {
-		logger.Error("Неопознанная ошибка создания временной базы данных",
-			slog.String("output", string(r.ConsoleOut)))
-		return odb, fmt.Errorf("неопознанная ошибка создания временной базы данных: %s", string(r.ConsoleOut))
-	}
-
 	logger.Debug("База данных успешно создана", slog.String("db_path", dbPath))
 
 	// Заполняем структуру OneDb
@@ -219,14 +193,6 @@ func CreateTempDb(ctx context.Context, logger *slog.Logger, cfg *config.Config,
 			return odb, fmt.Errorf("ошибка добавления расширения '%s': %w", extensionName, err)
 		}
 
-		// Проверяем успешность добавления расширения
-		if !strings.Contains(string(extRunner.ConsoleOut), constants.SearchMsgBaseAddOk) {
-			logger.Error("Неопознанная ошибка добавления расширения",
-				slog.String("extension", extensionName),
-				slog.String("output", string(extRunner.ConsoleOut)))
-			return odb, fmt.Errorf("неопознанная ошибка добавления расширения '%s': %s", extensionName, string(extRunner.ConsoleOut))
-		}
-
 		logger.Debug("Расширение успешно добавлено",
 			slog.String("extension", extensionName),
 			slog.String("db_path", odb.DbConnectString))
@@ -4,10 +4,8 @@ import (
 	"context"
 	"log/slog"
 	"path"
-	"strings"
 
 	"github.com/Kargones/apk-ci/internal/config"
-	"github.com/Kargones/apk-ci/internal/constants"
 	"github.com/Kargones/apk-ci/internal/util/runner"
 )
 
@@ -25,7 +23,15 @@ import (
 func (odb *OneDb) Load(ctx context.Context, l *slog.Logger, cfg *config.Config, sourcePath string, extensionName ...string) error {
 	r := runner.Runner{}
 	r.TmpDir = cfg.WorkDir
-	r.RunString = cfg.AppConfig.Paths.Bin1cv8
+	bin, err := odb.bin1cv8(ctx, l, cfg, sourcePath)
+	if err != nil {
+		l.Error("Ошибка выбора версии платформы",
+			slog.String("Путь", odb.DbConnectString),
+			slog.String("Error", err.Error()),
+		)
+		return err
+	}
+	r.RunString = bin
 	r.WorkDir = cfg.WorkDir
 	r.Params = append(r.Params, "@")
 	r.Params = append(r.Params, "DESIGNER")
@@ -42,6 +48,7 @@ func (odb *OneDb) Load(ctx context.Context, l *slog.Logger, cfg *config.Config,
 	// r.Params = append(r.Params, "/UpdateDBCfg")
 
 	addDisableParam(&r)
+	r.Params = append(r.Params, "/DumpResult")
 	r.Params = append(r.Params, "/Out")
 
 	// Устанавливаем соответствующее сообщение в зависимости от типа загрузки
@@ -51,7 +58,7 @@ func (odb *OneDb) Load(ctx context.Context, l *slog.Logger, cfg *config.Config,
 		r.Params = append(r.Params, "/c Загрузка из файлов основной конфигурации")
 	}
 
-	_, err := r.RunCommand(ctx, l)
+	_, err = r.RunCommand(ctx, l)
 	if err != nil {
 		if len(extensionName) > 0 && extensionName[0] != "" {
 			l.Error("Ошибка загрузки файлов расширения",
@@ -68,31 +75,6 @@ func (odb *OneDb) Load(ctx context.Context, l *slog.Logger, cfg *config.Config,
 		}
 		return err
 	}
-
-	// Проверяем результат выполнения
-	if len(extensionName) > 0 && extensionName[0] != "" {
-		if !strings.Contains(string(r.FileOut), constants.SearchMsgBaseLoadOk) &&
-			!strings.Contains(string(r.FileOut), constants.SearchMsgEmptyFile) &&
-			!strings.Contains(string(r.FileOut), constants.InvalidLink) {
-			l.Error("Неопознанная ошибка загрузки файлов расширения",
-				slog.String("Путь", odb.DbConnectString),
-				slog.String("Источник файлов", sourcePath),
-			)
-			// ToDo: дополнить игнорирование предупреждений
-			// return err
-		}
-	} else {
-		if !strings.Contains(string(r.FileOut), constants.SearchMsgBaseLoadOk) &&
-			!strings.Contains(string(r.FileOut), constants.SearchMsgEmptyFile) &&
-			!strings.Contains(string(r.FileOut), constants.InvalidLink) {
-			l.Error("Неопознанная ошибка загрузки файлов конфигурации",
-				slog.String("Путь", odb.DbConnectString),
-				slog.String("FileOut", string(r.FileOut)),
-			)
-			// ToDo: дополнить игнорирование предупреждений
-			// return err
-		}
-	}
 	return err
 }
 
@@ -125,7 +107,15 @@ func (odb *OneDb) LoadAdd(ctx context.Context, l *slog.Logger, cfg *config.Confi
 func (odb *OneDb) UpdateCfg(ctx context.Context, l *slog.Logger, cfg *config.Config, _ string, extensionName ...string) error {
 	r := runner.Runner{}
 	r.TmpDir = cfg.WorkDir
-	r.RunString = cfg.AppConfig.Paths.Bin1cv8
+	bin, err := odb.bin1cv8(ctx, l, cfg, "")
+	if err != nil {
+		l.Error("Ошибка выбора версии платформы",
+			slog.String("Путь", odb.DbConnectString),
+			slog.String("Error", err.Error()),
+		)
+		return err
+	}
+	r.RunString = bin
 	r.WorkDir = cfg.WorkDir
 	r.Params = append(r.Params, "@")
 	r.Params = append(r.Params, "DESIGNER")
@@ -139,6 +129,7 @@ func (odb *OneDb) UpdateCfg(ctx context.Context, l *slog.Logger, cfg *config.Con
 	}
 
 	addDisableParam(&r)
+	r.Params = append(r.Params, "/DumpResult")
 	r.Params = append(r.Params, "/Out")
 
 	// Устанавливаем соответствующее сообщение в зависимости от типа обновления
@@ -148,7 +139,7 @@ func (odb *OneDb) UpdateCfg(ctx context.Context, l *slog.Logger, cfg *config.Con
 		r.Params = append(r.Params, "/c Обновление основной конфигурации")
 	}
 
-	_, err := r.RunCommand(ctx, l)
+	_, err = r.RunCommand(ctx, l)
 	if err != nil {
 		l.Error("Ошибка обновления конфигурации расширения",
 			slog.String("Путь", odb.DbConnectString),
@@ -156,13 +147,6 @@ func (odb *OneDb) UpdateCfg(ctx context.Context, l *slog.Logger, cfg *config.Con
 		)
 		return err
 	}
-	if !strings.Contains(string(r.FileOut), constants.SearchMsgBaseLoadOk) && !strings.Contains(string(r.FileOut), constants.SearchMsgEmptyFile) {
-		l.Error("Неопознанная ошибка обновления конфигурации расширения",
-			slog.String("Путь", odb.DbConnectString),
-			slog.String("Вывод файла", runner.TrimOut(r.FileOut)),
-		)
-		return err
-	}
 	return err
 }
 
@@ -196,7 +180,15 @@ func (odb *OneDb) Dump(ctx context.Context, l *slog.Logger, cfg *config.Config,
 	var fileName string
 	r := runner.Runner{}
 	r.TmpDir = cfg.WorkDir
-	r.RunString = cfg.AppConfig.Paths.Bin1cv8
+	bin, err := odb.bin1cv8(ctx, l, cfg, "")
+	if err != nil {
+		l.Error("Ошибка выбора версии платформы",
+			slog.String("Путь", odb.DbConnectString),
+			slog.String("Error", err.Error()),
+		)
+		return err
+	}
+	r.RunString = bin
 	r.WorkDir = cfg.WorkDir
 	r.Params = append(r.Params, "@")
 	r.Params = append(r.Params, "DESIGNER")
@@ -215,6 +207,7 @@ func (odb *OneDb) Dump(ctx context.Context, l *slog.Logger, cfg *config.Config,
 	}
 
 	addDisableParam(&r)
+	r.Params = append(r.Params, "/DumpResult")
 	r.Params = append(r.Params, "/Out")
 
 	// Устанавливаем соответствующее сообщение в зависимости от типа выгрузки
@@ -224,7 +217,7 @@ func (odb *OneDb) Dump(ctx context.Context, l *slog.Logger, cfg *config.Config,
 		r.Params = append(r.Params, "/c Выгрузка основной конфигурации")
 	}
 
-	_, err := r.RunCommand(ctx, l)
+	_, err = r.RunCommand(ctx, l)
 	if err != nil {
 		if len(extensionName) > 0 && extensionName[0] != "" {
 			l.Error("Ошибка выгрузки расширения",
@@ -243,21 +236,6 @@ func (odb *OneDb) Dump(ctx context.Context, l *slog.Logger, cfg *config.Config,
 		return err
 	}
 
-	if !strings.Contains(string(r.FileOut), constants.SearchMsgBaseDumpOk) {
-		if len(extensionName) > 0 && extensionName[0] != "" {
-			l.Error("Неопознанная ошибка выгрузки расширения",
-				slog.String("Путь", odb.DbConnectString),
-				slog.String("Имя расширения", extensionName[0]),
-				slog.String("Имя файла", fileName),
-			)
-		} else {
-			l.Error("Неопознанная ошибка выгрузки основной конфигурации",
-				slog.String("Путь", odb.DbConnectString),
-				slog.String("Имя файла", fileName),
-			)
-		}
-		return err
-	}
 	return err
 }
 
@@ -0,0 +1,101 @@
+package designer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/pkg/platform"
+)
+
+// bin1cv8 возвращает путь к 1cv8 версии платформы, требуемой базой и конфигурацией.
+// При выключенном выборе версии (config.PlatformConfig) возвращает AppConfig.Paths.Bin1cv8.
+func (odb *OneDb) bin1cv8(ctx context.Context, l *slog.Logger, cfg *config.Config, sourcePath string) (string, error) {
+	if !cfg.PlatformConfig.Enabled() {
+		return cfg.AppConfig.Paths.Bin1cv8, nil
+	}
+
+	req, err := odb.PlatformRequirement(ctx, cfg, sourcePath)
+	if err != nil {
+		return "", err
+	}
+	bin, err := cfg.PlatformTool(platform.Tool1cv8, req)
+	if err != nil {
+		return "", err
+	}
+	l.Debug("Выбрана версия платформы",
+		slog.String("bin", bin),
+		slog.String("requirement", req.String()),
+	)
+	return bin, nil
+}
+
+// PlatformRequirement возвращает требование к версии платформы для операций с базой:
+//   - серверная база (AutoDetect) — точная версия агента сервера 1С через RAC;
+//   - sourcePath с Configuration.xml — не ниже режима совместимости конфигурации.
+//
+// При выключенном выборе версии возвращает пустое требование.
+func (odb *OneDb) PlatformRequirement(ctx context.Context, cfg *config.Config, sourcePath string) (platform.Requirement, error) {
+	var req platform.Requirement
+	if !cfg.PlatformConfig.Enabled() {
+		return req, nil
+	}
+	if sourcePath != "" {
+		compat, err := platform.ReadCompatibilityMode(sourcePath)
+		if err != nil {
+			return req, err
+		}
+		req.Minimum = compat
+		req.Source = "режим совместимости Configuration.xml"
+	}
+	if odb.ServerDb && cfg.PlatformConfig.AutoDetect {
+		server := odb.serverHost()
+		serverReq, err := serverRequirement(ctx, cfg, server)
+		if err != nil {
+			return req, fmt.Errorf("не удалось определить версию сервера 1С %s: %w", server, err)
+		}
+		req.Exact = serverReq.Exact
+		req.Source = serverReq.Source
+	}
+	return req, nil
+}
+
+// serverHost возвращает имя сервера 1С из строки соединения "/S host[:port]\base".
+func (odb *OneDb) serverHost() string {
+	s := strings.TrimSpace(strings.TrimPrefix(odb.DbConnectString, "/S "))
+	if i := strings.IndexAny(s, `\/`); i >= 0 {
+		s = s[:i]
+	}
+	if i := strings.LastIndex(s, ":"); i >= 0 {
+		s = s[:i]
+	}
+	return s
+}
+
+// serverRequirement запрашивает версию агента сервера 1С через RAC
+// (rac любой установленной версии поддерживает команду agent version).
+func serverRequirement(ctx context.Context, cfg *config.Config, server string) (platform.Requirement, error) {
+	if server == "" {
+		return platform.Requirement{}, fmt.Errorf("сервер 1С не указан в строке соединения")
+	}
+	opts := rac.ClientOptions{
+		Server:      server,
+		Platform:    cfg.PlatformResolver(),
+		ClusterUser: cfg.AppConfig.Users.Rac,
+	}
+	if cfg.AppConfig.Rac.Port != 0 {
+		opts.Port = strconv.Itoa(cfg.AppConfig.Rac.Port)
+	}
+	if cfg.SecretConfig != nil {
+		opts.ClusterPass = cfg.SecretConfig.Passwords.Rac
+	}
+	client, err := rac.NewClient(opts)
+	if err != nil {
+		return platform.Requirement{}, err
+	}
+	return rac.ServerRequirement(ctx, client, server)
+}
@@ -0,0 +1,91 @@
+package designer
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Kargones/apk-ci/internal/config"
+)
+
+// installStub создаёт каталог версии платформы с заглушкой исполняемого файла.
+func installStub(t *testing.T, base, version, tool, script string) {
+	t.Helper()
+	dir := filepath.Join(base, version)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, tool), []byte(script), 0o755); err != nil { //nolint:gosec // тестовая заглушка
+		t.Fatal(err)
+	}
+}
+
+func TestOneDb_ServerHost(t *testing.T) {
+	tests := map[string]string{
+		`/S srv-app\erp`:      "srv-app",
+		`/S srv-app:1541\erp`: "srv-app",
+	}
+	for conn, want := range tests {
+		odb := &OneDb{DbConnectString: conn}
+		if got := odb.serverHost(); got != want {
+			t.Errorf("serverHost(%q) = %q, want %q", conn, got, want)
+		}
+	}
+}
+
+func TestOneDb_Bin1cv8(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	base := t.TempDir()
+	installStub(t, base, "8.3.21.1895", "1cv8", "#!/bin/sh\n")
+	installStub(t, base, "8.3.24.1467", "1cv8", "#!/bin/sh\n")
+	installStub(t, base, "8.3.27.1606", "1cv8", "#!/bin/sh\n")
+	// rac отвечает версией агента сервера 1С
+	installStub(t, base, "8.3.27.1606", "rac", "#!/bin/sh\necho 8.3.24.1467\n")
+
+	src := t.TempDir()
+	xmlData := `<MetaDataObject><Configuration><Properties><CompatibilityMode>Version8_3_22</CompatibilityMode></Properties></Configuration></MetaDataObject>`
+	if err := os.WriteFile(filepath.Join(src, "Configuration.xml"), []byte(xmlData), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	newCfg := func(pc *config.PlatformConfig) *config.Config {
+		cfg := &config.Config{AppConfig: &config.AppConfig{}, PlatformConfig: pc}
+		cfg.AppConfig.Paths.Bin1cv8 = "/configured/1cv8"
+		return cfg
+	}
+
+	t.Run("disabled uses configured path", func(t *testing.T) {
+		bin, err := (&OneDb{}).bin1cv8(context.Background(), logger, newCfg(nil), src)
+		if err != nil || bin != "/configured/1cv8" {
+			t.Errorf("bin1cv8() = %q, %v", bin, err)
+		}
+	})
+
+	t.Run("file db uses newest compatible", func(t *testing.T) {
+		cfg := newCfg(&config.PlatformConfig{AutoDetect: true, BasePath: base})
+		bin, err := (&OneDb{DbConnectString: "/F /tmp/db"}).bin1cv8(context.Background(), logger, cfg, src)
+		if err != nil || bin != filepath.Join(base, "8.3.27.1606", "1cv8") {
+			t.Errorf("bin1cv8() = %q, %v", bin, err)
+		}
+	})
+
+	t.Run("server db uses server version", func(t *testing.T) {
+		cfg := newCfg(&config.PlatformConfig{AutoDetect: true, BasePath: base})
+		odb := &OneDb{DbConnectString: `/S srv-app\erp`, ServerDb: true}
+		bin, err := odb.bin1cv8(context.Background(), logger, cfg, src)
+		if err != nil || bin != filepath.Join(base, "8.3.24.1467", "1cv8") {
+			t.Errorf("bin1cv8() = %q, %v", bin, err)
+		}
+	})
+
+	t.Run("pinned below compatibility mode", func(t *testing.T) {
+		cfg := newCfg(&config.PlatformConfig{BasePath: base, Version: "8.3.21.1895"})
+		_, err := (&OneDb{}).bin1cv8(context.Background(), logger, cfg, src)
+		if err == nil || !strings.Contains(err.Error(), "Configuration.xml") {
+			t.Errorf("bin1cv8() error = %v, want compatibility mode mismatch", err)
+		}
+	})
+}
@@ -0,0 +1,277 @@
+package store
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Kargones/apk-ci/internal/config"
+	"github.com/Kargones/apk-ci/internal/constants"
+)
+
+const (
+	// BackupPrefix — префикс каталогов backup хранилища в TmpDir.
+	BackupPrefix = "backup_"
+	// BackupInfoFile — файл метаданных backup.
+	BackupInfoFile = "backup_info.txt"
+	// BackupStoreDir — каталог с копией файлового хранилища внутри backup.
+	BackupStoreDir = "store"
+	// backupTimeFormat — формат времени в имени каталога backup. Наносекунды исключают
+	// совпадение имён при запусках в одну секунду; фиксированная ширина сохраняет
+	// сортировку по имени в порядке создания.
+	backupTimeFormat = "20060102_150405.000000000"
+	// DefaultBackupKeep — количество хранимых backup одного хранилища по умолчанию.
+	DefaultBackupKeep = 5
+)
+
+// ErrBackupNotRestorable — backup не содержит копии хранилища
+// (серверное хранилище tcp:// сохраняется только метаданными).
+var ErrBackupNotRestorable = errors.New("backup не содержит копии каталога хранилища")
+
+// Backup описывает резервную копию хранилища, созданную перед git2store.
+type Backup struct {
+	// Path — каталог backup.
+	Path string `json:"path"`
+	// Created — время создания.
+	Created time.Time `json:"created"`
+	// StoreRoot — корень хранилища, для которого создан backup.
+	StoreRoot string `json:"store_root"`
+	// Infobase — информационная база git2store.
+	Infobase string `json:"infobase,omitempty"`
+	// Owner, Repo — репозиторий Gitea.
+	Owner string `json:"owner,omitempty"`
+	Repo  string `json:"repo,omitempty"`
+	// Restorable — backup содержит копию каталога хранилища.
+	Restorable bool `json:"restorable"`
+}
+
+// IsLocalStore проверяет, является ли хранилище файловым (не tcp://, http://).
+func IsLocalStore(storeRoot string) bool {
+	return storeRoot != "" && !strings.Contains(storeRoot, "://")
+}
+
+// CreateBackup создаёт резервную копию хранилища в cfg.TmpDir.
+//
+// Всегда записывает backup_info.txt с метаданными. Для файлового хранилища
+// дополнительно копирует его каталог в <backup>/store — такой backup можно
+// восстановить через RestoreBackup. Серверное хранилище (tcp://) копированию
+// не подлежит: backup содержит только метаданные для ручного восстановления.
+func CreateBackup(cfg *config.Config, storeRoot string) (string, error) {
+	now := time.Now()
+	if err := os.MkdirAll(cfg.TmpDir, constants.DirPermExec); err != nil {
+		return "", fmt.Errorf("не удалось создать директорию backup: %w", err)
+	}
+	// Mkdir, а не MkdirAll: существующий каталог означает совпадение имён, а не общий backup
+	backupDir := filepath.Join(cfg.TmpDir, BackupPrefix+now.Format(backupTimeFormat))
+	if err := os.Mkdir(backupDir, constants.DirPermExec); err != nil {
+		return "", fmt.Errorf("не удалось создать директорию backup: %w", err)
+	}
+
+	storeCopy := ""
+	if IsLocalStore(storeRoot) {
+		if _, err := os.Stat(storeRoot); err == nil {
+			if err := copyTree(storeRoot, filepath.Join(backupDir, BackupStoreDir)); err != nil {
+				return "", fmt.Errorf("не удалось скопировать каталог хранилища: %w", err)
+			}
+			storeCopy = BackupStoreDir
+		}
+	}
+
+	note := "\nВНИМАНИЕ: Это метаданные для ручного восстановления.\n" +
+		"Для восстановления используйте 1cv8 DESIGNER или ibcmd.\n"
+	if storeCopy != "" {
+		note = "\nКопия каталога хранилища: " + storeCopy + "\n" +
+			"Для восстановления используйте nr-store-restore-backup.\n"
+	}
+
+	backupInfo := fmt.Sprintf("=== BACKUP METADATA ===\n"+
+		"Store Root: %s\n"+
+		"Created: %s\n"+
+		"Infobase: %s\n"+
+		"Owner: %s\n"+
+		"Repo: %s\n"+
+		"Store Copy: %s\n"+
+		"%s",
+		storeRoot, now.Format(time.RFC3339),
+		cfg.InfobaseName, cfg.Owner, cfg.Repo, storeCopy, note)
+	if err := os.WriteFile(filepath.Join(backupDir, BackupInfoFile), []byte(backupInfo), constants.FilePermReadWrite); err != nil {
+		return "", fmt.Errorf("не удалось записать информацию о backup: %w", err)
+	}
+
+	return backupDir, nil
+}
+
+// ReadBackup читает метаданные backup из каталога.
+func ReadBackup(path string) (*Backup, error) {
+	f, err := os.Open(filepath.Join(path, BackupInfoFile)) //nolint:gosec // путь к backup задаётся администратором
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать метаданные backup %s: %w", path, err)
+	}
+	defer func() { _ = f.Close() }() //nolint:errcheck // файл только для чтения
+
+	b := &Backup{Path: path}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ": ")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "Store Root":
+			b.StoreRoot = value
+		case "Created":
+			b.Created, _ = time.Parse(time.RFC3339, value) //nolint:errcheck // нулевое время для повреждённых метаданных
+		case "Infobase":
+			b.Infobase = value
+		case "Owner":
+			b.Owner = value
+		case "Repo":
+			b.Repo = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("не удалось прочитать метаданные backup %s: %w", path, err)
+	}
+	if info, err := os.Stat(filepath.Join(path, BackupStoreDir)); err == nil && info.IsDir() {
+		b.Restorable = true
+	}
+	return b, nil
+}
+
+// ListBackups возвращает backup-каталоги из dir (новые первыми).
+// Каталоги без метаданных пропускаются.
+func ListBackups(dir string) ([]Backup, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать каталог backup %s: %w", dir, err)
+	}
+	backups := make([]Backup, 0)
+	for _, e := range entries {
+		if !e.IsDir() || !strings.HasPrefix(e.Name(), BackupPrefix) {
+			continue
+		}
+		b, err := ReadBackup(filepath.Join(dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		backups = append(backups, *b)
+	}
+	sort.SliceStable(backups, func(i, j int) bool {
+		return backups[i].Path > backups[j].Path
+	})
+	return backups, nil
+}
+
+// PruneBackups удаляет из dir старые backup хранилища storeRoot, оставляя keep новейших.
+// Backup других хранилищ не затрагиваются; keep <= 0 отключает удаление.
+// Возвращает пути удалённых каталогов.
+func PruneBackups(dir, storeRoot string, keep int) ([]string, error) {
+	if keep <= 0 {
+		return nil, nil
+	}
+	backups, err := ListBackups(dir)
+	if err != nil {
+		return nil, err
+	}
+	pruned := make([]string, 0)
+	kept := 0
+	for _, b := range backups {
+		if b.StoreRoot != storeRoot {
+			continue
+		}
+		if kept < keep {
+			kept++
+			continue
+		}
+		if err := os.RemoveAll(b.Path); err != nil {
+			return pruned, fmt.Errorf("не удалось удалить backup %s: %w", b.Path, err)
+		}
+		pruned = append(pruned, b.Path)
+	}
+	return pruned, nil
+}
+
+// RestoreBackup восстанавливает каталог хранилища storeRoot из backup.
+//
+// Текущий каталог переименовывается, на его место копируется <backup>/store.
+// При ошибке копирования исходный каталог возвращается на место.
+// Возвращает ErrBackupNotRestorable, если backup не содержит копии хранилища.
+func RestoreBackup(backupPath, storeRoot string) error {
+	if !IsLocalStore(storeRoot) {
+		return fmt.Errorf("%w: серверное хранилище %s", ErrBackupNotRestorable, storeRoot)
+	}
+	src := filepath.Join(backupPath, BackupStoreDir)
+	if info, err := os.Stat(src); err != nil || !info.IsDir() {
+		return fmt.Errorf("%w: %s", ErrBackupNotRestorable, backupPath)
+	}
+
+	displaced := ""
+	if _, err := os.Stat(storeRoot); err == nil {
+		displaced = storeRoot + ".before-restore-" + time.Now().Format(backupTimeFormat)
+		if err := os.Rename(storeRoot, displaced); err != nil {
+			return fmt.Errorf("не удалось переместить текущий каталог хранилища: %w", err)
+		}
+	}
+
+	if err := copyTree(src, storeRoot); err != nil {
+		_ = os.RemoveAll(storeRoot) //nolint:errcheck // откат частичной копии
+		if displaced != "" {
+			if renameErr := os.Rename(displaced, storeRoot); renameErr != nil {
+				return fmt.Errorf("не удалось восстановить хранилище: %w; исходный каталог сохранён в %s", err, displaced)
+			}
+		}
+		return fmt.Errorf("не удалось восстановить хранилище: %w", err)
+	}
+
+	if displaced != "" {
+		_ = os.RemoveAll(displaced) //nolint:errcheck // каталог уже заменён копией из backup
+	}
+	return nil
+}
+
+// copyTree рекурсивно копирует каталог src в dst.
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, constants.DirPermStandard)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		return copyFile(path, target)
+	})
+}
+
+// copyFile копирует обычный файл.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src) //nolint:gosec // путь внутри копируемого каталога
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }() //nolint:errcheck // файл только для чтения
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, constants.FilePermReadWrite) //nolint:gosec // путь внутри каталога назначения
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close() //nolint:errcheck // ошибка копирования важнее
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,179 @@
+package store
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Kargones/apk-ci/internal/config"
+)
+
+func writeStoreFile(t *testing.T, root, name, content string) {
+	t.Helper()
+	path := filepath.Join(root, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readStoreFile(t *testing.T, root, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(root, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestCreateBackup_FileStore(t *testing.T) {
+	storeRoot := filepath.Join(t.TempDir(), "store")
+	writeStoreFile(t, storeRoot, "1cv8ddb.1CD", "v1")
+	writeStoreFile(t, storeRoot, "data/objects", "obj")
+	cfg := &config.Config{TmpDir: t.TempDir(), InfobaseName: "Base", Owner: "owner", Repo: "repo"}
+
+	path, err := CreateBackup(cfg, storeRoot)
+	if err != nil {
+		t.Fatalf("CreateBackup() error: %v", err)
+	}
+
+	b, err := ReadBackup(path)
+	if err != nil {
+		t.Fatalf("ReadBackup() error: %v", err)
+	}
+	if !b.Restorable || b.StoreRoot != storeRoot || b.Owner != "owner" || b.Repo != "repo" || b.Infobase != "Base" {
+		t.Errorf("unexpected backup metadata: %+v", b)
+	}
+	if b.Created.IsZero() {
+		t.Error("Created must be parsed")
+	}
+	if got := readStoreFile(t, filepath.Join(path, BackupStoreDir), "data/objects"); got != "obj" {
+		t.Errorf("store copy content = %q", got)
+	}
+}
+
+func TestCreateBackup_ServerStore(t *testing.T) {
+	cfg := &config.Config{TmpDir: t.TempDir()}
+
+	path, err := CreateBackup(cfg, "tcp://server/store")
+	if err != nil {
+		t.Fatalf("CreateBackup() error: %v", err)
+	}
+
+	b, err := ReadBackup(path)
+	if err != nil {
+		t.Fatalf("ReadBackup() error: %v", err)
+	}
+	if b.Restorable {
+		t.Error("server store backup must be metadata only")
+	}
+	if err := RestoreBackup(path, "tcp://server/store"); !errors.Is(err, ErrBackupNotRestorable) {
+		t.Errorf("expected ErrBackupNotRestorable, got %v", err)
+	}
+}
+
+func TestCreateBackup_UniqueNames(t *testing.T) {
+	cfg := &config.Config{TmpDir: t.TempDir()}
+
+	seen := make(map[string]bool)
+	for range 5 {
+		path, err := CreateBackup(cfg, "tcp://server/store")
+		if err != nil {
+			t.Fatalf("CreateBackup() error: %v", err)
+		}
+		if seen[path] {
+			t.Fatalf("backup path reused: %s", path)
+		}
+		seen[path] = true
+	}
+
+	backups, err := ListBackups(cfg.TmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 5 {
+		t.Fatalf("expected 5 backups, got %d", len(backups))
+	}
+	for i := 1; i < len(backups); i++ {
+		if !backups[i-1].Created.After(backups[i].Created) && !backups[i-1].Created.Equal(backups[i].Created) {
+			t.Errorf("backups must be listed newest first: %v", backups)
+		}
+	}
+}
+
+func TestRestoreBackup(t *testing.T) {
+	storeRoot := filepath.Join(t.TempDir(), "store")
+	writeStoreFile(t, storeRoot, "1cv8ddb.1CD", "v1")
+	path, err := CreateBackup(&config.Config{TmpDir: t.TempDir()}, storeRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeStoreFile(t, storeRoot, "1cv8ddb.1CD", "half-applied")
+	writeStoreFile(t, storeRoot, "lock", "locked")
+
+	if err := RestoreBackup(path, storeRoot); err != nil {
+		t.Fatalf("RestoreBackup() error: %v", err)
+	}
+
+	if got := readStoreFile(t, storeRoot, "1cv8ddb.1CD"); got != "v1" {
+		t.Errorf("store content = %q, want v1", got)
+	}
+	if _, err := os.Stat(filepath.Join(storeRoot, "lock")); !os.IsNotExist(err) {
+		t.Error("files created after backup must be removed")
+	}
+	siblings, _ := filepath.Glob(storeRoot + ".before-restore-*")
+	if len(siblings) != 0 {
+		t.Errorf("displaced store must be removed: %v", siblings)
+	}
+}
+
+func TestListBackups(t *testing.T) {
+	dir := t.TempDir()
+	writeStoreFile(t, dir, "backup_20240101_000000/"+BackupInfoFile, "Store Root: /a\nOwner: o\n")
+	writeStoreFile(t, dir, "backup_20240102_000000/"+BackupInfoFile, "Store Root: /b\nOwner: o\n")
+	if err := os.MkdirAll(filepath.Join(dir, "backup_broken"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "temp_db_1"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	backups, err := ListBackups(dir)
+	if err != nil {
+		t.Fatalf("ListBackups() error: %v", err)
+	}
+	if len(backups) != 2 || backups[0].StoreRoot != "/b" || backups[1].StoreRoot != "/a" {
+		t.Errorf("unexpected backups (newest first expected): %+v", backups)
+	}
+}
+
+func TestPruneBackups(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"backup_20240101_000000", "backup_20240102_000000", "backup_20240103_000000"} {
+		writeStoreFile(t, dir, name+"/"+BackupInfoFile, "Store Root: /a\n")
+	}
+	writeStoreFile(t, dir, "backup_20231231_000000/"+BackupInfoFile, "Store Root: /b\n")
+
+	pruned, err := PruneBackups(dir, "/a", 2)
+	if err != nil {
+		t.Fatalf("PruneBackups() error: %v", err)
+	}
+	if len(pruned) != 1 || filepath.Base(pruned[0]) != "backup_20240101_000000" {
+		t.Errorf("only the oldest backup of /a must be pruned, got %v", pruned)
+	}
+
+	backups, err := ListBackups(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 3 || backups[2].StoreRoot != "/b" {
+		t.Errorf("backups of other stores must be kept: %+v", backups)
+	}
+
+	if pruned, err := PruneBackups(dir, "/a", 0); err != nil || len(pruned) != 0 {
+		t.Errorf("keep=0 must disable pruning, got %v, %v", pruned, err)
+	}
+}
@@ -6,10 +6,8 @@ import (
 	"fmt"
 	"log/slog"
 	"path/filepath"
-	"strings"
 
 	"github.com/Kargones/apk-ci/internal/config"
-	"github.com/Kargones/apk-ci/internal/constants"
 	"github.com/Kargones/apk-ci/internal/util/runner"
 )
 
@@ -67,6 +65,7 @@ func CreateStores(ctx context.Context, l *slog.Logger, cfg *config.Config, store
 	r.Params = append(r.Params, "ObjectIsEditableSupportEnabled")
 	r.Params = append(r.Params, "-ChangesNotRecommendedRule")
 	r.Params = append(r.Params, "ObjectIsEditableSupportEnabled")
+	r.Params = append(r.Params, "/DumpResult")
 	r.Params = append(r.Params, "/Out")
 	r.Params = append(r.Params, "/c Создание хранилища конфигурации")
 
@@ -79,15 +78,7 @@ func CreateStores(ctx context.Context, l *slog.Logger, cfg *config.Config, store
 			slog.String("Строка подключения", dbConnectString),
 			slog.String("Error", err.Error()),
 		)
-		return fmt.Errorf("ошибка создания хранилища конфигурации")
-	}
-	if !strings.Contains(string(r.FileOut), constants.SearchMsgStoreCreateOk) {
-		l.Error("Неопознанная ошибка создания хранилища конфигурации",
-			slog.String("Путь", mainStore.Path),
-			slog.String("Строка подключения", dbConnectString),
-			slog.String("Вывод в файл", runner.TrimOut(r.FileOut)),
-		)
-		return fmt.Errorf("ошибка создания хранилища конфигурации")
+		return fmt.Errorf("ошибка создания хранилища конфигурации: %w", err)
 	}
 
 	l.Debug("Основное хранилище конфигурации успешно создано",
@@ -139,6 +130,7 @@ func CreateStores(ctx context.Context, l *slog.Logger, cfg *config.Config, store
 		rAdd.Params = append(rAdd.Params, "ObjectIsEditableSupportEnabled")
 		rAdd.Params = append(rAdd.Params, "-ChangesNotRecommendedRule")
 		rAdd.Params = append(rAdd.Params, "ObjectIsEditableSupportEnabled")
+		rAdd.Params = append(rAdd.Params, "/DumpResult")
 		rAdd.Params = append(rAdd.Params, "/Out")
 		rAdd.Params = append(rAdd.Params, "/c Создание хранилища конфигурации")
 
@@ -151,15 +143,7 @@ func CreateStores(ctx context.Context, l *slog.Logger, cfg *config.Config, store
 				slog.String("Строка подключения", dbConnectString),
 				slog.String("Error", err.Error()),
 			)
-			return fmt.Errorf("ошибка создания хранилища конфигурации")
-		}
-		if !strings.Contains(string(rAdd.FileOut), constants.SearchMsgStoreCreateOk) {
-			l.Error("Неопознанная ошибка создания хранилища конфигурации",
-				slog.String("Путь", addStore.Path),
-				slog.String("Строка подключения", dbConnectString),
-				slog.String("Вывод в файл", runner.TrimOut(rAdd.FileOut)),
-			)
-			return fmt.Errorf("ошибка создания хранилища конфигурации")
+			return fmt.Errorf("ошибка создания хранилища конфигурации: %w", err)
 		}
 
 		l.Debug("Хранилище расширения успешно создано",
@@ -5,11 +5,9 @@ import (
 	"fmt"
 	"log/slog"
 	"path"
-	"strings"
+	"strconv"
 
 	"github.com/Kargones/apk-ci/internal/config"
-	"github.com/Kargones/apk-ci/internal/constants"
-	"github.com/Kargones/apk-ci/internal/util/runner"
 )
 
 // Lock блокирует хранилище конфигурации для эксклюзивного доступа.
@@ -42,13 +40,6 @@ func (s *Store) Lock(ctx context.Context, l *slog.Logger, cfg *config.Config, db
 		)
 		return err
 	}
-	if !strings.Contains(string(r.FileOut), constants.SearchMsgStoreLockOk) {
-		l.Error("Неопознанная ошибка захвата основной конфигурации",
-			slog.String("Путь", s.Path),
-			slog.String("Вывод в файл", runner.TrimOut(r.FileOut)),
-		)
-		return err
-	}
 	return err
 }
 
@@ -84,14 +75,76 @@ func (s *Store) LockAdd(ctx context.Context, l *slog.Logger, cfg *config.Config,
 		)
 		return err
 	}
-	if !strings.Contains(string(r.FileOut), constants.SearchMsgStoreLockOk) {
-		l.Error("Неопознанная ошибка захвата расширения",
+	return err
+}
+
+// Unlock освобождает объекты основной конфигурации, захваченные в хранилище.
+// Используется при откате git2store: флаг -force отменяет захват
+// без помещения изменений.
+// Параметры:
+//   - ctx: контекст выполнения
+//   - l: логгер для записи сообщений
+//   - cfg: конфигурация приложения
+//   - dbConnectString: строка подключения к базе данных
+//   - storeRoot: корневой путь к хранилищу
+//
+// Возвращает:
+//   - error: ошибку, если операция не удалась
+func (s *Store) Unlock(ctx context.Context, l *slog.Logger, cfg *config.Config, dbConnectString string, storeRoot string) error {
+	var tPath string
+	tPath, s.Path = fullPathStore(storeRoot, s.Path)
+	defer func() {
+		s.Path = tPath
+	}()
+	r := s.GetStoreParam(dbConnectString, cfg)
+	r.Params = append(r.Params, "/ConfigurationRepositoryUnlock")
+	r.Params = append(r.Params, "-force")
+	r.Params = append(r.Params, "/Out")
+	r.Params = append(r.Params, "/c Освобождение объектов основной конфигурации")
+	_, err := r.RunCommand(ctx, l)
+	if err != nil {
+		l.Error("Ошибка освобождения объектов основной конфигурации",
 			slog.String("Путь", s.Path),
-			slog.String("Вывод в файл", runner.TrimOut(r.FileOut)),
+			slog.String("Error", err.Error()),
 		)
 		return err
 	}
-	return err
+	return nil
+}
+
+// UnlockAdd освобождает объекты расширения, захваченные в хранилище.
+// Параметры:
+//   - ctx: контекст выполнения
+//   - l: логгер для записи сообщений
+//   - cfg: конфигурация приложения
+//   - dbConnectString: строка подключения к базе данных
+//   - storeRoot: корневой путь к хранилищу
+//   - addName: имя расширения
+//
+// Возвращает:
+//   - error: ошибку, если операция не удалась
+func (s *Store) UnlockAdd(ctx context.Context, l *slog.Logger, cfg *config.Config, dbConnectString string, storeRoot string, addName string) error {
+	var tPath string
+	tPath, s.Path = fullPathStore(storeRoot, s.Path)
+	defer func() {
+		s.Path = tPath
+	}()
+	r := s.GetStoreParam(dbConnectString, cfg)
+	r.Params = append(r.Params, "/ConfigurationRepositoryUnlock")
+	r.Params = append(r.Params, "-Extension")
+	r.Params = append(r.Params, addName)
+	r.Params = append(r.Params, "-force")
+	r.Params = append(r.Params, "/Out")
+	r.Params = append(r.Params, "/c Освобождение объектов расширения")
+	_, err := r.RunCommand(ctx, l)
+	if err != nil {
+		l.Error("Ошибка освобождения объектов расширения",
+			slog.String("Путь", s.Path),
+			slog.String("Error", err.Error()),
+		)
+		return err
+	}
+	return nil
 }
 
 // Create создает новое хранилище для основной конфигурации.
@@ -123,15 +176,7 @@ func (s *Store) Create(ctx context.Context, l *slog.Logger, cfg *config.Config,
 			slog.String("Строка подключения", dbConnectString),
 			slog.String("Error", err.Error()),
 		)
-		return fmt.Errorf("ошибка создания хранилища конфигурации")
-	}
-	if !strings.Contains(string(r.FileOut), constants.SearchMsgStoreCreateOk) {
-		l.Error("Неопознанная ошибка создания хранилища конфигурации",
-			slog.String("Путь", s.Path),
-			slog.String("Строка подключения", dbConnectString),
-			slog.String("Вывод в файл", runner.TrimOut(r.FileOut)),
-		)
-		return fmt.Errorf("ошибка создания хранилища конфигурации")
+		return fmt.Errorf("ошибка создания хранилища конфигурации: %w", err)
 	}
 	return err
 }
@@ -167,15 +212,7 @@ func (s *Store) CreateAdd(ctx context.Context, l *slog.Logger, cfg *config.Confi
 			slog.String("Строка подключения", dbConnectString),
 			slog.String("Error", err.Error()),
 		)
-		return fmt.Errorf("ошибка создания хранилища конфигурации")
-	}
-	if !strings.Contains(string(r.FileOut), constants.SearchMsgStoreCreateOk) {
-		l.Error("Неопознанная ошибка создания хранилища конфигурации",
-			slog.String("Путь", s.Path),
-			slog.String("Строка подключения", dbConnectString),
-			slog.String("Вывод в файл", runner.TrimOut(r.FileOut)),
-		)
-		return fmt.Errorf("ошибка создания хранилища конфигурации")
+		return fmt.Errorf("ошибка создания хранилища конфигурации: %w", err)
 	}
 	return err
 }
@@ -214,7 +251,7 @@ func (s *Store) Check(ctx context.Context, l *slog.Logger, cfg *config.Config, d
 				slog.String("Строка подключения", dbConnectString),
 				slog.String("Error", err.Error()),
 			)
-			return fmt.Errorf("ошибка создания хранилища конфигурации")
+			return fmt.Errorf("ошибка создания хранилища конфигурации: %w", err)
 		}
 	}
 
@@ -242,7 +279,7 @@ func (s *Store) CheckAdd(ctx context.Context, l *slog.Logger, cfg *config.Config
 					slog.String("Строка подключения", dbConnectString),
 					slog.String("Error", err.Error()),
 				)
-				return fmt.Errorf("ошибка создания хранилища конфигурации")
+				return fmt.Errorf("ошибка создания хранилища конфигурации: %w", err)
 			}
 		}
 	}
@@ -292,13 +329,6 @@ func (s *Store) Bind(ctx context.Context, l *slog.Logger, cfg *config.Config, db
 		)
 		return err
 	}
-	if !strings.Contains(string(r.FileOut), constants.SearchMsgStoreBindOk) {
-		l.Error("Неопознанная ошибка подключения основной конфигурации к хранилищу",
-			slog.String("Путь", s.Path),
-			slog.String("Вывод в файл", runner.TrimOut(r.FileOut)),
-		)
-		return err
-	}
 
 	// Обновление конфигурации из хранилища
 	r = s.GetStoreParam(dbConnectString, cfg)
@@ -315,13 +345,6 @@ func (s *Store) Bind(ctx context.Context, l *slog.Logger, cfg *config.Config, db
 		)
 		return err
 	}
-	if !strings.Contains(string(r.FileOut), constants.SearchMsgStoreUpdateCfgOk) {
-		l.Error("Неопознанная ошибка обновления конфигурации из хранилища",
-			slog.String("Путь", s.Path),
-			slog.String("Вывод в файл", runner.TrimOut(r.FileOut)),
-		)
-		return err
-	}
 
 	return err
 }
@@ -371,13 +394,6 @@ func (s *Store) BindAdd(ctx context.Context, l *slog.Logger, cfg *config.Config,
 		)
 		return err
 	}
-	if !strings.Contains(string(r.FileOut), constants.SearchMsgStoreBindOk) {
-		l.Error("Неопознанная ошибка подключения расширения к хранилищу",
-			slog.String("Путь", s.Path),
-			slog.String("Вывод в файл", runner.TrimOut(r.FileOut)),
-		)
-		return err
-	}
 
 	// Обновление конфигурации расширения из хранилища
 	r = s.GetStoreParam(dbConnectString, cfg)
@@ -396,13 +412,6 @@ func (s *Store) BindAdd(ctx context.Context, l *slog.Logger, cfg *config.Config,
 		)
 		return err
 	}
-	if !strings.Contains(string(r.FileOut), constants.SearchMsgStoreUpdateCfgOk) {
-		l.Error("Неопознанная ошибка обновления расширения из хранилища",
-			slog.String("Путь", s.Path),
-			slog.String("Вывод в файл", runner.TrimOut(r.FileOut)),
-		)
-		return err
-	}
 
 	return err
 }
@@ -437,13 +446,6 @@ func (s *Store) UnBind(ctx context.Context, l *slog.Logger, cfg *config.Config,
 		)
 		return err
 	}
-	if !strings.Contains(string(r.FileOut), constants.SearchMsgStoreUnBindOk) {
-		l.Error("Неопознанная ошибка отключения конфигурации от хранилища",
-			slog.String("Путь", s.Path),
-			slog.String("Вывод в файл", runner.TrimOut(r.FileOut)),
-		)
-		return err
-	}
 	return err
 }
 
@@ -480,13 +482,6 @@ func (s *Store) UnBindAdd(ctx context.Context, l *slog.Logger, cfg *config.Confi
 		)
 		return err
 	}
-	if !strings.Contains(string(r.FileOut), constants.SearchMsgStoreUnBindOk) {
-		l.Error("Неопознанная ошибка отключения расширения от хранилища",
-			slog.String("Путь", s.Path),
-			slog.String("Вывод в файл", runner.TrimOut(r.FileOut)),
-		)
-		return err
-	}
 	return err
 }
 
@@ -528,13 +523,6 @@ func (s *Store) Merge(ctx context.Context, l *slog.Logger, cfg *config.Config, d
 		)
 		return err
 	}
-	if !strings.Contains(string(r.FileOut), constants.SearchMsgStoreMergeOk) {
-		l.Error("Неопознанная ошибка загрузки изменений основной конфигурации",
-			slog.String("Путь", s.Path),
-			slog.String("Вывод в файл", runner.TrimOut(r.FileOut)),
-		)
-		return err
-	}
 	return err
 }
 
@@ -581,13 +569,6 @@ func (s *Store) MergeAdd(ctx context.Context, l *slog.Logger, cfg *config.Config
 		)
 		return err
 	}
-	if !strings.Contains(string(r.FileOut), constants.SearchMsgStoreMergeOk) {
-		l.Error("Неопознанная ошибка загрузки изменений в расширение",
-			slog.String("Путь", s.Path),
-			slog.String("Вывод в файл", runner.TrimOut(r.FileOut)),
-		)
-		return err
-	}
 	return err
 }
 
@@ -632,13 +613,6 @@ func (s *Store) StoreCommit(ctx context.Context, l *slog.Logger, cfg *config.Con
 		)
 		return err
 	}
-	if !strings.Contains(string(r.FileOut), constants.SearchMsgStoreCommitOk) {
-		l.Error("Неопознанная ошибка создания версии хранилища",
-			slog.String("Путь", s.Path),
-			slog.String("Вывод в файл", runner.TrimOut(r.FileOut)),
-		)
-		return err
-	}
 	return err
 }
 
@@ -658,3 +632,46 @@ func (s *Store) StoreCommit(ctx context.Context, l *slog.Logger, cfg *config.Con
 func (s *Store) StoreCommitAdd(ctx context.Context, l *slog.Logger, cfg *config.Config, dbConnectString string, storeRoot string, comment string, addName string) error {
 	return s.StoreCommit(ctx, l, cfg, dbConnectString, storeRoot, comment, addName)
 }
+
+// UpdateCfgVersion обновляет конфигурацию базы данных до указанной версии хранилища.
+// База данных должна быть предварительно подключена к хранилищу (см. Bind, BindAdd).
+// Параметры:
+//   - ctx: контекст выполнения
+//   - l: логгер для записи сообщений
+//   - cfg: конфигурация приложения
+//   - dbConnectString: строка подключения к базе данных
+//   - storeRoot: корневой путь к хранилищу
+//   - version: номер версии хранилища
+//   - addName: опциональное имя расширения
+//
+// Возвращает:
+//   - error: ошибку, если операция не удалась
+func (s *Store) UpdateCfgVersion(ctx context.Context, l *slog.Logger, cfg *config.Config, dbConnectString string, storeRoot string, version int, addName ...string) error {
+	var tPath string
+	tPath, s.Path = fullPathStore(storeRoot, s.Path)
+	defer func() {
+		s.Path = tPath
+	}()
+	r := s.GetStoreParam(dbConnectString, cfg)
+	r.Params = append(r.Params, "/ConfigurationRepositoryUpdateCfg")
+	r.Params = append(r.Params, "-v")
+	r.Params = append(r.Params, strconv.Itoa(version))
+	r.Params = append(r.Params, "-revised")
+	r.Params = append(r.Params, "-force")
+	if len(addName) > 0 && addName[0] != "" {
+		r.Params = append(r.Params, "-Extension")
+		r.Params = append(r.Params, addName[0])
+	}
+	r.Params = append(r.Params, "/Out")
+	r.Params = append(r.Params, "/c Получение версии хранилища "+strconv.Itoa(version))
+	_, err := r.RunCommand(ctx, l)
+	if err != nil {
+		l.Error("Ошибка получения версии хранилища",
+			slog.String("Путь", s.Path),
+			slog.Int("Версия", version),
+			slog.String("Error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
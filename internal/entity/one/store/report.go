@@ -4,10 +4,13 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -15,6 +18,76 @@ import (
 	"github.com/Kargones/apk-ci/internal/config"
 )
 
+// reportDateLayout — формат даты и времени в txt-отчёте хранилища.
+const reportDateLayout = "02.01.2006 15:04:05"
+
+// Поля txt-отчёта /ConfigurationRepositoryReport.
+const (
+	reportKeyStore         = "Хранилище"
+	reportKeyReportDate    = "Дата отчета"
+	reportKeyReportTime    = "Время отчета"
+	reportKeyVersion       = "Версия"
+	reportKeyConfVersion   = "Версия конфигурации"
+	reportKeyUser          = "Пользователь"
+	reportKeyDate          = "Дата создания"
+	reportKeyTime          = "Время создания"
+	reportKeyComment       = "Комментарий"
+	reportKeyLabel         = "Метка"
+	reportKeyLabelComment  = "Комментарий метки"
+	reportKeyLabelComment2 = "Комментарий к метке"
+	reportKeyAdded         = "Добавлены"
+	reportKeyChanged       = "Изменены"
+	reportKeyDeleted       = "Удалены"
+)
+
+// commitCommentPrefix — строка комментария версии с хешем git-коммита (см. CommitComment).
+const commitCommentPrefix = "Коммит"
+
+// gitCommitPattern находит хеш git-коммита в комментарии версии хранилища.
+var gitCommitPattern = regexp.MustCompile(`(?mi)^\s*(?:Коммит|commit)\s*:?\s*([0-9a-f]{7,40})\s*$`)
+
+// Label — метка версии хранилища.
+type Label struct {
+	Name    string `json:"name"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// ReportVersion — версия хранилища из отчёта /ConfigurationRepositoryReport.
+type ReportVersion struct {
+	Number      int       `json:"version"`
+	ConfVersion string    `json:"conf_version,omitempty"`
+	User        string    `json:"user"`
+	Date        time.Time `json:"date"`
+	Comment     string    `json:"comment,omitempty"`
+	Labels      []Label   `json:"labels,omitempty"`
+	Added       []string  `json:"added,omitempty"`
+	Changed     []string  `json:"changed,omitempty"`
+	Deleted     []string  `json:"deleted,omitempty"`
+	// GitCommit — хеш git-коммита из комментария версии (для версий, созданных git2store)
+	GitCommit string `json:"git_commit,omitempty"`
+}
+
+// Report — разобранный отчёт хранилища конфигурации.
+type Report struct {
+	// Store — путь к хранилищу из заголовка отчёта
+	Store string `json:"store,omitempty"`
+	// Date — дата построения отчёта
+	Date time.Time `json:"date"`
+	// Versions — версии в порядке следования в отчёте
+	Versions []ReportVersion `json:"versions"`
+}
+
+// ReportFilter — условия отбора версий отчёта. Пустые поля не ограничивают отбор.
+type ReportFilter struct {
+	// Author — имя пользователя хранилища (без учёта регистра)
+	Author string
+	// Since, Until — границы даты создания версии (включительно)
+	Since time.Time
+	Until time.Time
+	// Object — объект метаданных; совпадает сам объект и вложенные (Справочник.Х → Справочник.Х.Форма.Y)
+	Object string
+}
+
 // ReadReport читает отчет из хранилища конфигурации.
 // Функция формирует отчет о версиях конфигурации в хранилище,
 // начиная с указанной версии, и возвращает записи, пользователей и максимальную версию.
@@ -30,35 +103,80 @@ import (
 //   - int: максимальная версия в хранилище
 //   - error: ошибку, если операция не удалась
 func (s *Store) ReadReport(ctx context.Context, l *slog.Logger, dbConnectString string, cfg *config.Config, startVersion int) ([]Record, []User, int, error) {
-	r := s.GetStoreParam(dbConnectString, cfg)
-	r.Params = append(r.Params, "/ConfigurationRepositoryReport")
-	repPath := filepath.Join(r.TmpDir, s.Name+".txt")
-	r.Params = append(r.Params, repPath)
-	r.Params = append(r.Params, "-NBegin")
-	r.Params = append(r.Params, fmt.Sprint(startVersion))
-	r.Params = append(r.Params, "-ReportFormat")
-	r.Params = append(r.Params, "txt")
+	var addName []string
 	if s.Name != "Main" {
-		r.Params = append(r.Params, "-Extension")
-		r.Params = append(r.Params, s.Name)
+		addName = append(addName, s.Name)
+	}
+	rep, err := s.report(ctx, l, cfg, dbConnectString, s.Path, startVersion, addName...)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return rep.Records(), rep.Users(), rep.MaxVersion(), nil
+}
+
+// Report формирует отчет хранилища /ConfigurationRepositoryReport и разбирает его.
+// Параметры:
+//   - ctx: контекст выполнения
+//   - l: логгер для записи сообщений
+//   - cfg: конфигурация приложения
+//   - dbConnectString: строка подключения к базе данных
+//   - storeRoot: корневой путь к хранилищу
+//   - startVersion: начальная версия отчета (0 — с первой версии)
+//   - addName: опциональное имя расширения
+//
+// Возвращает:
+//   - *Report: разобранный отчет
+//   - error: ошибку формирования или разбора отчета
+func (s *Store) Report(ctx context.Context, l *slog.Logger, cfg *config.Config, dbConnectString, storeRoot string, startVersion int, addName ...string) (*Report, error) {
+	_, fullPath := fullPathStore(storeRoot, s.Path)
+	return s.report(ctx, l, cfg, dbConnectString, fullPath, startVersion, addName...)
+}
+
+// report формирует txt-отчет для хранилища по полному пути storePath.
+func (s *Store) report(ctx context.Context, l *slog.Logger, cfg *config.Config, dbConnectString, storePath string, startVersion int, addName ...string) (*Report, error) {
+	tPath := s.Path
+	s.Path = storePath
+	defer func() {
+		s.Path = tPath
+	}()
+	r := s.GetStoreParam(dbConnectString, cfg)
+	reportName := s.Name
+	if reportName == "" {
+		reportName = "Main"
 	}
-	r.Params = append(r.Params, "/Out")
-	r.Params = append(r.Params, "/c Отчет хранилища "+s.Name)
-	bOut, err := r.RunCommand(ctx, l)
+	repPath := filepath.Join(r.TmpDir, reportName+".txt")
+	r.Params = append(r.Params, "/ConfigurationRepositoryReport", repPath)
+	if startVersion > 0 {
+		r.Params = append(r.Params, "-NBegin", strconv.Itoa(startVersion))
+	}
+	r.Params = append(r.Params, "-ReportFormat", "txt")
+	if len(addName) > 0 && addName[0] != "" {
+		r.Params = append(r.Params, "-Extension", addName[0])
+	}
+	r.Params = append(r.Params, "/Out", "/c Отчет хранилища "+reportName)
+
+	_, err := r.RunCommand(ctx, l)
+	if err != nil {
+		l.Error("Ошибка выполнения команды формирования отчета", slog.String("storeName", reportName), slog.String("error", err.Error()))
+		return nil, fmt.Errorf("ошибка формирования отчета хранилища %s: %w", reportName, err)
+	}
+
+	file, err := os.Open(repPath) //nolint:gosec // путь формируется из WorkDir
 	if err != nil {
-		l.Error("Ошибка выполнения команды формирования отчета", slog.String("storeName", s.Name), slog.String("error", err.Error()))
-		return nil, nil, 0, fmt.Errorf("ошибка формирования отчета хранилища %s: %w", s.Name, err)
+		return nil, fmt.Errorf("не удалось открыть отчет хранилища %s: %w", reportName, err)
 	}
-	if len(bOut) > 44 && string(bOut[3:45]) != "Отчет успешно построен" {
-		l.Error("Ошибка формирования отчета", slog.String("storeName", s.Name), slog.String("output", string(bOut)))
+	defer func() { _ = file.Close() }() //nolint:errcheck // файл только для чтения
+
+	rep, err := ParseReportReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка разбора отчета хранилища %s: %w", reportName, err)
 	}
-	records, userList, maxVersion, err := ParseReport(repPath)
-	return records, userList, maxVersion, nil
+	return rep, nil
 }
 
 // ParseReport парсит отчет хранилища конфигурации из файла.
 // Функция читает файл отчета и извлекает информацию о версиях хранилища и пользователях.
-// TODO: переделать на чтение из буфера байт.
+// Полный разбор отчета (метки, списки объектов) — см. ParseReportReader.
 // Параметры:
 //   - path: путь к файлу отчета
 //
@@ -68,9 +186,6 @@ func (s *Store) ReadReport(ctx context.Context, l *slog.Logger, dbConnectString
 //   - int: максимальный номер версии
 //   - error: ошибку, если операция не удалась
 func ParseReport(path string) ([]Record, []User, int, error) {
-	maxVersion := 0
-	records := []Record{}
-	userList := []User{}
 	// Валидация пути к файлу
 	if strings.Contains(path, "..") || path == "" {
 		return nil, nil, 0, fmt.Errorf("небезопасный путь к файлу: %s", path)
@@ -85,68 +200,305 @@ func ParseReport(path string) ([]Record, []User, int, error) {
 			log.Printf("Error closing file: %v", closeErr)
 		}
 	}()
-	sr := Record{}
-	dc := ""
-	scanner := bufio.NewScanner(file)
+
+	rep, err := ParseReportReader(file)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return rep.Records(), rep.Users(), rep.MaxVersion(), nil
+}
+
+// reportSection — текущий многострочный блок отчёта.
+type reportSection int
+
+const (
+	sectionNone reportSection = iota
+	sectionComment
+	sectionLabelComment
+	sectionAdded
+	sectionChanged
+	sectionDeleted
+)
+
+// reportParser — состояние разбора txt-отчёта.
+type reportParser struct {
+	rep        *Report
+	cur        *ReportVersion
+	section    reportSection
+	date       string
+	reportDate string
+	comment    []string
+}
+
+// ParseReportReader разбирает txt-отчет /ConfigurationRepositoryReport.
+//
+// Отчет состоит из заголовка (хранилище, дата отчета) и блоков версий.
+// Поля версии записываются как "Имя:<TAB>значение"; комментарий и списки
+// объектов (Добавлены/Изменены/Удалены) продолжаются на следующих строках
+// до начала следующего поля. Неизвестные поля и версии с некорректным
+// номером пропускаются.
+func ParseReportReader(r io.Reader) (*Report, error) {
+	p := &reportParser{rep: &Report{Versions: []ReportVersion{}}}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	first := true
 	for scanner.Scan() {
-		t := scanner.Text()
-		if t == "Комментарий:" {
-			scanner.Scan()
-			comment := scanner.Text()
-			sr.Comment = comment
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if first {
+			line = strings.TrimPrefix(line, "\uFEFF")
+			first = false
+		}
+		p.line(line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scanner error: %w", err)
+	}
+	p.finishVersion()
+	return p.rep, nil
+}
+
+// splitReportLine выделяет известное поле отчёта: "Имя:<TAB>значение", "Имя: значение"
+// или заголовок списка объектов "Добавлены<TAB>объект".
+func splitReportLine(line string) (string, string, bool) {
+	if line == "" || line[0] == '\t' || line[0] == ' ' {
+		return "", "", false
+	}
+	key, value, _ := strings.Cut(line, "\t")
+	key = strings.TrimSpace(key)
+	if k, v, ok := strings.Cut(key, ":"); ok {
+		key = k
+		if strings.TrimSpace(v) != "" {
+			value = v + value
+		}
+	}
+	switch key {
+	case reportKeyStore, reportKeyReportDate, reportKeyReportTime, reportKeyVersion, reportKeyConfVersion,
+		reportKeyUser, reportKeyDate, reportKeyTime, reportKeyComment, reportKeyLabel,
+		reportKeyLabelComment, reportKeyLabelComment2, reportKeyAdded, reportKeyChanged, reportKeyDeleted:
+		return key, strings.TrimSpace(value), true
+	}
+	return "", "", false
+}
+
+// line обрабатывает одну строку отчёта.
+func (p *reportParser) line(line string) {
+	key, value, ok := splitReportLine(line)
+	if !ok {
+		p.continuation(line)
+		return
+	}
+	p.closeSection()
+
+	if key == reportKeyVersion {
+		p.finishVersion()
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return
+		}
+		p.cur = &ReportVersion{Number: n}
+		return
+	}
+
+	if p.cur == nil {
+		p.header(key, value)
+		return
+	}
+
+	switch key {
+	case reportKeyConfVersion:
+		p.cur.ConfVersion = value
+	case reportKeyUser:
+		p.cur.User = value
+	case reportKeyDate:
+		p.date = value
+	case reportKeyTime:
+		if t, err := time.Parse(reportDateLayout, p.date+" "+value); err == nil {
+			p.cur.Date = t
+		}
+	case reportKeyComment:
+		p.startSection(sectionComment, value)
+	case reportKeyLabel:
+		p.cur.Labels = append(p.cur.Labels, Label{Name: value})
+	case reportKeyLabelComment, reportKeyLabelComment2:
+		p.startSection(sectionLabelComment, value)
+	case reportKeyAdded:
+		p.startSection(sectionAdded, value)
+	case reportKeyChanged:
+		p.startSection(sectionChanged, value)
+	case reportKeyDeleted:
+		p.startSection(sectionDeleted, value)
+	}
+}
+
+// header обрабатывает поля заголовка отчёта (до первой версии).
+func (p *reportParser) header(key, value string) {
+	switch key {
+	case reportKeyStore:
+		p.rep.Store = value
+	case reportKeyReportDate:
+		p.reportDate = value
+	case reportKeyReportTime:
+		if t, err := time.Parse(reportDateLayout, p.reportDate+" "+value); err == nil {
+			p.rep.Date = t
+		}
+	}
+}
+
+// startSection начинает многострочный блок; value — значение в строке заголовка.
+func (p *reportParser) startSection(section reportSection, value string) {
+	p.section = section
+	p.comment = p.comment[:0]
+	if value != "" {
+		p.continuation(value)
+	}
+}
+
+// continuation добавляет строку к текущему многострочному блоку.
+func (p *reportParser) continuation(line string) {
+	if p.cur == nil {
+		return
+	}
+	switch p.section {
+	case sectionComment, sectionLabelComment:
+		p.comment = append(p.comment, strings.TrimSpace(line))
+	case sectionAdded, sectionChanged, sectionDeleted:
+		name := strings.TrimSpace(line)
+		if name == "" {
+			return
+		}
+		switch p.section {
+		case sectionAdded:
+			p.cur.Added = append(p.cur.Added, name)
+		case sectionChanged:
+			p.cur.Changed = append(p.cur.Changed, name)
+		default:
+			p.cur.Deleted = append(p.cur.Deleted, name)
+		}
+	}
+}
+
+// closeSection завершает многострочный блок комментария.
+func (p *reportParser) closeSection() {
+	if p.cur != nil {
+		text := strings.TrimSpace(strings.Join(p.comment, "\n"))
+		switch p.section {
+		case sectionComment:
+			p.cur.Comment = text
+		case sectionLabelComment:
+			if n := len(p.cur.Labels); n > 0 {
+				p.cur.Labels[n-1].Comment = text
+			}
+		}
+	}
+	p.section = sectionNone
+	p.comment = p.comment[:0]
+}
+
+// finishVersion добавляет текущую версию в отчёт.
+func (p *reportParser) finishVersion() {
+	p.closeSection()
+	if p.cur == nil {
+		return
+	}
+	if m := gitCommitPattern.FindStringSubmatch(p.cur.Comment); m != nil {
+		p.cur.GitCommit = strings.ToLower(m[1])
+	}
+	p.rep.Versions = append(p.rep.Versions, *p.cur)
+	p.cur = nil
+	p.date = ""
+}
+
+// Records возвращает версии отчёта в формате Record.
+func (r *Report) Records() []Record {
+	records := make([]Record, 0, len(r.Versions))
+	for _, v := range r.Versions {
+		records = append(records, Record{
+			Version:     v.Number,
+			ConfVersion: v.ConfVersion,
+			User:        v.User,
+			Date:        v.Date,
+			Comment:     v.Comment,
+		})
+	}
+	return records
+}
+
+// Users возвращает уникальных пользователей в порядке первого появления.
+func (r *Report) Users() []User {
+	users := []User{}
+	seen := make(map[string]bool)
+	for _, v := range r.Versions {
+		if v.User == "" || seen[v.User] {
 			continue
 		}
-		as := strings.Split(t, "\t")
-		if len(as) < 2 {
+		seen[v.User] = true
+		users = append(users, User{StoreUserName: v.User})
+	}
+	return users
+}
+
+// MaxVersion возвращает максимальный номер версии (0 для пустого отчёта).
+func (r *Report) MaxVersion() int {
+	maxVersion := 0
+	for _, v := range r.Versions {
+		maxVersion = max(maxVersion, v.Number)
+	}
+	return maxVersion
+}
+
+// Filter возвращает версии, удовлетворяющие условиям f.
+func (r *Report) Filter(f ReportFilter) []ReportVersion {
+	result := make([]ReportVersion, 0, len(r.Versions))
+	for _, v := range r.Versions {
+		if f.Author != "" && !strings.EqualFold(v.User, f.Author) {
 			continue
 		}
-		switch as[0] {
-		case "Версия:":
-			if sr.Version != 0 {
-				records = append(records, sr)
-				sr = Record{}
-			}
-			i, parseErr := strconv.Atoi(as[1])
-			if parseErr != nil {
-				continue
-			}
-			sr.Version = i
-			maxVersion = i
-		case "Версия конфигурации:":
-			sr.ConfVersion = as[1]
-		case "Пользователь:":
-			sr.User = as[1]
-			exist := false
-			for _, v := range userList {
-				if v.StoreUserName == as[1] {
-					exist = true
-					break
-				}
-			}
-			if !exist {
-				cu := User{}
-				cu.StoreUserName = as[1]
-				userList = append(userList, cu)
-			}
-		case "Дата создания:":
-			dc = as[1]
-		case "Время создания:":
-			dc += " " + as[1]
-			sr.Date, err = time.Parse("02.01.2006 15:04:05", dc)
-			if err != nil {
-				dc = ""
-				continue
-			}
+		if !f.Since.IsZero() && v.Date.Before(f.Since) {
+			continue
+		}
+		if !f.Until.IsZero() && v.Date.After(f.Until) {
+			continue
 		}
+		if f.Object != "" && !v.TouchesObject(f.Object) {
+			continue
+		}
+		result = append(result, v)
 	}
+	return result
+}
+
+// Objects возвращает отсортированный список объектов, затронутых версией.
+func (v *ReportVersion) Objects() []string {
+	objects := make([]string, 0, len(v.Added)+len(v.Changed)+len(v.Deleted))
+	objects = append(objects, v.Added...)
+	objects = append(objects, v.Changed...)
+	objects = append(objects, v.Deleted...)
+	sort.Strings(objects)
+	return objects
+}
 
-	// Append the last record if it exists
-	if sr.Version != 0 {
-		records = append(records, sr)
+// TouchesObject проверяет, затрагивает ли версия объект или вложенные в него объекты.
+func (v *ReportVersion) TouchesObject(object string) bool {
+	for _, o := range v.Objects() {
+		if strings.EqualFold(o, object) ||
+			(len(o) > len(object) && o[len(object)] == '.' && strings.EqualFold(o[:len(object)], object)) {
+			return true
+		}
 	}
+	return false
+}
 
-	if scanErr := scanner.Err(); scanErr != nil {
-		return nil, nil, 0, fmt.Errorf("scanner error: %w", scanErr)
+// CommitComment формирует комментарий версии хранилища, создаваемой из git.
+// Строка "Коммит <hash>" позволяет сопоставить версию хранилища с git-коммитом
+// (см. ReportVersion.GitCommit).
+func CommitComment(owner, repo, commit string, created time.Time) string {
+	lines := []string{"Версия создана автоматически"}
+	if owner != "" || repo != "" {
+		lines = append(lines, "Исходный репозиторий "+owner+"/"+repo)
+	}
+	if commit != "" {
+		lines = append(lines, commitCommentPrefix+" "+commit)
 	}
-	return records, userList, maxVersion, nil
+	lines = append(lines, "Дата создания "+created.Format(reportDateLayout))
+	return strings.Join(lines, "\n")
 }
@@ -0,0 +1,147 @@
+package store
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleReport = "\uFEFFОтчет по версиям хранилища\r\n" + `
+Хранилище:	tcp://server/gitops/owner/repo
+Дата отчета:	10.03.2024
+Время отчета:	09:15:00
+
+Версия:	11
+Версия конфигурации:	1.0.0.11
+Пользователь:	Иванов
+Дата создания:	01.03.2024
+Время создания:	10:20:30
+Комментарий:
+Версия создана автоматически
+Исходный репозиторий owner/repo
+Коммит 0123abcd4567ef
+Метка:	Релиз 1.0
+Комментарий метки:	Сборка для прода
+Добавлены	Справочник.Склады
+	Справочник.Склады.Форма.ФормаЭлемента
+Изменены	Справочник.Номенклатура
+	Документ.Заказ.Модуль объекта
+Удалены	Отчет.Старый
+
+Версия:	12
+Пользователь:	Петров
+Дата создания:	05.03.2024
+Время создания:	18:00:00
+Комментарий:
+Исправлена ошибка: расчет
+цен
+
+Изменены	Документ.Заказ
+`
+
+func TestParseReportReader(t *testing.T) {
+	rep, err := ParseReportReader(strings.NewReader(sampleReport))
+	if err != nil {
+		t.Fatalf("ParseReportReader() error: %v", err)
+	}
+	if rep.Store != "tcp://server/gitops/owner/repo" {
+		t.Errorf("Store = %q", rep.Store)
+	}
+	if want := time.Date(2024, 3, 10, 9, 15, 0, 0, time.UTC); !rep.Date.Equal(want) {
+		t.Errorf("Date = %v, want %v", rep.Date, want)
+	}
+	if len(rep.Versions) != 2 {
+		t.Fatalf("got %d versions, want 2", len(rep.Versions))
+	}
+
+	v := rep.Versions[0]
+	if v.Number != 11 || v.ConfVersion != "1.0.0.11" || v.User != "Иванов" {
+		t.Errorf("unexpected version header: %+v", v)
+	}
+	if want := time.Date(2024, 3, 1, 10, 20, 30, 0, time.UTC); !v.Date.Equal(want) {
+		t.Errorf("Date = %v, want %v", v.Date, want)
+	}
+	if !strings.HasPrefix(v.Comment, "Версия создана автоматически\n") || strings.Contains(v.Comment, "Метка") {
+		t.Errorf("Comment = %q", v.Comment)
+	}
+	if v.GitCommit != "0123abcd4567ef" {
+		t.Errorf("GitCommit = %q", v.GitCommit)
+	}
+	if len(v.Labels) != 1 || v.Labels[0].Name != "Релиз 1.0" || v.Labels[0].Comment != "Сборка для прода" {
+		t.Errorf("Labels = %+v", v.Labels)
+	}
+	if strings.Join(v.Added, ",") != "Справочник.Склады,Справочник.Склады.Форма.ФормаЭлемента" {
+		t.Errorf("Added = %v", v.Added)
+	}
+	if strings.Join(v.Changed, ",") != "Справочник.Номенклатура,Документ.Заказ.Модуль объекта" {
+		t.Errorf("Changed = %v", v.Changed)
+	}
+	if strings.Join(v.Deleted, ",") != "Отчет.Старый" {
+		t.Errorf("Deleted = %v", v.Deleted)
+	}
+
+	v = rep.Versions[1]
+	if v.Comment != "Исправлена ошибка: расчет\nцен" {
+		t.Errorf("multiline comment = %q", v.Comment)
+	}
+	if v.GitCommit != "" || len(v.Changed) != 1 {
+		t.Errorf("unexpected second version: %+v", v)
+	}
+
+	if rep.MaxVersion() != 12 || len(rep.Users()) != 2 || len(rep.Records()) != 2 {
+		t.Errorf("MaxVersion=%d Users=%v", rep.MaxVersion(), rep.Users())
+	}
+}
+
+func TestReportFilter(t *testing.T) {
+	rep, err := ParseReportReader(strings.NewReader(sampleReport))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		filter ReportFilter
+		want   []int
+	}{
+		{"empty", ReportFilter{}, []int{11, 12}},
+		{"author", ReportFilter{Author: "петров"}, []int{12}},
+		{"since", ReportFilter{Since: time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)}, []int{12}},
+		{"until", ReportFilter{Until: time.Date(2024, 3, 1, 23, 59, 59, 0, time.UTC)}, []int{11}},
+		{"object exact", ReportFilter{Object: "Отчет.Старый"}, []int{11}},
+		{"object nested", ReportFilter{Object: "Документ.Заказ"}, []int{11, 12}},
+		{"object prefix only", ReportFilter{Object: "Справочник.Скл"}, nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []int
+			for _, v := range rep.Filter(tc.filter) {
+				got = append(got, v.Number)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("Filter() = %v, want %v", got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Errorf("Filter() = %v, want %v", got, tc.want)
+				}
+			}
+		})
+	}
+}
+
+func TestCommitComment(t *testing.T) {
+	created := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	comment := CommitComment("owner", "repo", "ABCDEF1234", created)
+
+	rep, err := ParseReportReader(strings.NewReader("Версия:\t1\nКомментарий:\n" + comment + "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Versions[0].GitCommit != "abcdef1234" {
+		t.Errorf("commit hash must be extracted from generated comment, got %q", rep.Versions[0].GitCommit)
+	}
+	if strings.Contains(CommitComment("owner", "repo", "", created), commitCommentPrefix) {
+		t.Error("comment without commit must not contain commit line")
+	}
+}
@@ -65,6 +65,8 @@ func (s *Store) GetStoreParam(dbConnectString string, cfg *config.Config) runner
 	r.Params = append(r.Params, "/ConfigurationRepositoryP")
 	r.Params = append(r.Params, s.Pass)
 	addDisableParam(&r)
+	// Код результата /DumpResult подтверждает успех независимо от языка платформы.
+	r.Params = append(r.Params, "/DumpResult")
 
 	return r
 }
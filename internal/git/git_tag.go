@@ -0,0 +1,185 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// commitDateLayout — формат даты для GIT_AUTHOR_DATE/GIT_COMMITTER_DATE.
+const commitDateLayout = time.RFC3339
+
+// CommitAs создает коммит от имени указанного автора с указанной датой.
+// В отличие от Commit допускает пустой коммит: версия хранилища без изменений
+// выгружаемых файлов (например, только метка) также должна попасть в историю.
+// Параметры:
+//   - ctx: контекст выполнения операции
+//   - l: логгер для записи сообщений
+//   - comment: сообщение коммита
+//   - author: автор в формате "Имя <email>"
+//   - date: дата авторства и фиксации коммита
+//
+// Возвращает:
+//   - string: хеш созданного коммита
+//   - error: ошибка создания коммита или nil при успехе
+func (g *Git) CommitAs(ctx context.Context, l *slog.Logger, comment, author string, date time.Time) (string, error) {
+	timeout := g.Timeout
+	if timeout == 0 {
+		timeout = 30 * time.Minute // Значение по умолчанию
+	}
+	cmdCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if strings.TrimSpace(comment) == "" {
+		comment = "(без комментария)"
+	}
+	args := []string{"commit", "--allow-empty", "--author", author, "-m", comment}
+	// #nosec G204 - GitCommand является константой, args формируется из проверенных значений
+	cmd := exec.CommandContext(cmdCtx, GitCommand, args...)
+	cmd.Dir = g.RepPath
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_DATE="+date.Format(commitDateLayout),
+		"GIT_COMMITTER_DATE="+date.Format(commitDateLayout),
+	)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if cmdCtx.Err() == context.DeadlineExceeded {
+			l.Error("Таймаут при создании коммита",
+				slog.String("repPath", g.RepPath),
+				slog.String("author", author),
+				slog.Duration("timeout", timeout))
+			return "", fmt.Errorf("таймаут при создании коммита")
+		}
+		l.Error("Ошибка создания коммита",
+			slog.String("repPath", g.RepPath),
+			slog.String("author", author),
+			slog.String("output", string(output)),
+			slog.String("error", err.Error()))
+		return "", fmt.Errorf("ошибка создания коммита: %w", err)
+	}
+	return g.revParse(cmdCtx, "HEAD")
+}
+
+// Tag создает аннотированный тег на текущем коммите.
+// Параметры:
+//   - ctx: контекст выполнения операции
+//   - l: логгер для записи сообщений
+//   - name: имя тега
+//   - message: сообщение тега
+//
+// Возвращает:
+//   - error: ошибка создания тега или nil при успехе
+func (g *Git) Tag(ctx context.Context, l *slog.Logger, name, message string) error {
+	return g.TagCommit(ctx, l, name, "HEAD", message)
+}
+
+// TagCommit создает аннотированный тег на коммите commit.
+// Параметры:
+//   - ctx: контекст выполнения операции
+//   - l: логгер для записи сообщений
+//   - name: имя тега
+//   - commit: хеш коммита (допускается сокращённый)
+//   - message: сообщение тега
+//
+// Возвращает:
+//   - error: ошибка создания тега или nil при успехе (в том числе если коммита нет в клоне)
+func (g *Git) TagCommit(ctx context.Context, l *slog.Logger, name, commit, message string) error {
+	// #nosec G204 - GitCommand является константой, args формируется из проверенных значений
+	cmd := exec.CommandContext(ctx, GitCommand, "tag", "-a", name, "-m", message, commit+"^{commit}")
+	cmd.Dir = g.RepPath
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		l.Error("Ошибка создания тега",
+			slog.String("repPath", g.RepPath),
+			slog.String("tag", name),
+			slog.String("commit", commit),
+			slog.String("output", string(output)),
+			slog.String("error", err.Error()))
+		return fmt.Errorf("ошибка создания тега %s: %w", name, err)
+	}
+	return nil
+}
+
+// ListTags возвращает имена тегов, соответствующих шаблону git tag --list.
+// Параметры:
+//   - ctx: контекст выполнения операции
+//   - pattern: шаблон имени тега (например, "store/v*")
+//
+// Возвращает:
+//   - []string: имена найденных тегов
+//   - error: ошибка чтения тегов или nil при успехе
+func (g *Git) ListTags(ctx context.Context, pattern string) ([]string, error) {
+	// #nosec G204 - GitCommand является константой, args формируется из проверенных значений
+	cmd := exec.CommandContext(ctx, GitCommand, "tag", "--list", pattern)
+	cmd.Dir = g.RepPath
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения тегов: %w", err)
+	}
+	var tags []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			tags = append(tags, line)
+		}
+	}
+	return tags, nil
+}
+
+// PushWithTags отправляет ветку вместе с аннотированными тегами её коммитов.
+// Параметры:
+//   - ctx: контекст выполнения операции
+//   - l: логгер для записи сообщений
+//
+// Возвращает:
+//   - error: ошибка отправки или nil при успехе
+func (g *Git) PushWithTags(ctx context.Context, l *slog.Logger) error {
+	timeout := g.Timeout
+	if timeout == 0 {
+		timeout = 60 * time.Minute // Значение по умолчанию для push операций
+	}
+	cmdCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	args := []string{"push", "--follow-tags", "origin", g.Branch}
+	// #nosec G204 - GitCommand является константой, args формируется из проверенных значений
+	cmd := exec.CommandContext(cmdCtx, GitCommand, args...)
+	cmd.Dir = g.RepPath
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if cmdCtx.Err() == context.DeadlineExceeded {
+			l.Error("Таймаут при отправке изменений",
+				slog.String("repPath", g.RepPath),
+				slog.String("branch", g.Branch),
+				slog.Duration("timeout", timeout))
+			return fmt.Errorf("таймаут при отправке изменений")
+		}
+		l.Error("Ошибка отправки изменений",
+			slog.String("repPath", g.RepPath),
+			slog.String("branch", g.Branch),
+			slog.String("output", string(output)),
+			slog.String("error", err.Error()))
+		return fmt.Errorf("ошибка отправки изменений: %w", err)
+	}
+	return nil
+}
+
+// revParse возвращает хеш коммита для указанной ссылки.
+func (g *Git) revParse(ctx context.Context, ref string) (string, error) {
+	// #nosec G204 - GitCommand является константой, args формируется из проверенных значений
+	cmd := exec.CommandContext(ctx, GitCommand, "rev-parse", ref)
+	cmd.Dir = g.RepPath
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("ошибка получения хеша %s: %w", ref, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,112 @@
+package git
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+// initTestRepo создает пустой git-репозиторий с настроенным пользователем.
+func initTestRepo(t *testing.T) *Git {
+	t.Helper()
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.name", "Runner"},
+		{"config", "user.email", "runner@example.com"},
+	} {
+		cmd := exec.Command(GitCommand, args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	return &Git{RepPath: dir}
+}
+
+func gitOutput(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command(GitCommand, args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git %v: %v", args, err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestCommitAsAndTags(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	g := initTestRepo(t)
+	date := time.Date(2024, 3, 1, 10, 20, 30, 0, time.UTC)
+
+	hash, err := g.CommitAs(ctx, logger, "Версия 1", "Иванов <ivanov@example.com>", date)
+	if err != nil {
+		t.Fatalf("CommitAs() error: %v", err)
+	}
+	if len(hash) != 40 {
+		t.Errorf("unexpected commit hash %q", hash)
+	}
+	if got := gitOutput(t, g.RepPath, "log", "-1", "--format=%an|%ae|%aI|%cI|%s"); got != "Иванов|ivanov@example.com|2024-03-01T10:20:30+00:00|2024-03-01T10:20:30+00:00|Версия 1" {
+		t.Errorf("commit metadata = %q", got)
+	}
+
+	if err := g.Tag(ctx, logger, "store/v1", "Версия хранилища 1"); err != nil {
+		t.Fatalf("Tag() error: %v", err)
+	}
+	if err := g.Tag(ctx, logger, "store/v1", "повтор"); err == nil {
+		t.Error("duplicate tag must fail")
+	}
+	if err := g.Tag(ctx, logger, "other", "other"); err != nil {
+		t.Fatal(err)
+	}
+
+	tags, err := g.ListTags(ctx, "store/v*")
+	if err != nil {
+		t.Fatalf("ListTags() error: %v", err)
+	}
+	if len(tags) != 1 || tags[0] != "store/v1" {
+		t.Errorf("ListTags() = %v", tags)
+	}
+}
+
+func TestCommitAsEmptyComment(t *testing.T) {
+	g := initTestRepo(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	if _, err := g.CommitAs(context.Background(), logger, " ", "A <a@example.com>", time.Now()); err != nil {
+		t.Fatalf("CommitAs() with empty comment error: %v", err)
+	}
+	if got := gitOutput(t, g.RepPath, "log", "-1", "--format=%s"); got != "(без комментария)" {
+		t.Errorf("subject = %q", got)
+	}
+}
+
+func TestTagCommit(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	g := initTestRepo(t)
+
+	first, err := g.CommitAs(ctx, logger, "Версия 1", "A <a@example.com>", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.CommitAs(ctx, logger, "Версия 2", "A <a@example.com>", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := g.TagCommit(ctx, logger, "store/v1", first[:7], "Версия хранилища 1"); err != nil {
+		t.Fatalf("TagCommit() error: %v", err)
+	}
+	if got := gitOutput(t, g.RepPath, "rev-list", "-n", "1", "store/v1"); got != first {
+		t.Errorf("tag points to %s, want %s", got, first)
+	}
+	if err := g.TagCommit(ctx, logger, "store/v9", "0123456789abcdef", "нет коммита"); err == nil {
+		t.Error("tag of a missing commit must fail")
+	}
+}
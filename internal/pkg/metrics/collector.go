@@ -31,3 +31,21 @@ type Collector interface {
 	// M-2/Review #10: явная документация паттерна "всегда nil".
 	Push(ctx context.Context) error
 }
+
+// SessionStats — снимок сессий информационной базы для экспорта в Prometheus.
+type SessionStats struct {
+	// ByApp — количество сессий по AppID (1CV8C, Designer, BackgroundJob и др.)
+	ByApp map[string]int
+	// Idle — количество сессий без активности дольше порога
+	Idle int
+	// Licenses — количество сессий, занимающих лицензию
+	Licenses int
+}
+
+// SessionRecorder — опциональный интерфейс Collector для gauges сессий 1C.
+// Проверяется через type assertion: collector.(metrics.SessionRecorder).
+type SessionRecorder interface {
+	// RecordSessions устанавливает gauges сессий информационной базы.
+	// Значения отправляются в Pushgateway вместе с метриками команды при Push.
+	RecordSessions(infobase string, stats SessionStats)
+}
@@ -25,3 +25,6 @@ func (c *NopCollector) RecordCommandEnd(command, infobase string, duration time.
 func (c *NopCollector) Push(ctx context.Context) error {
 	return nil
 }
+
+// RecordSessions — no-op, ничего не делает.
+func (c *NopCollector) RecordSessions(infobase string, stats SessionStats) {}
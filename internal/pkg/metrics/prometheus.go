@@ -25,6 +25,9 @@ type PrometheusCollector struct {
 	commandDuration *prometheus.HistogramVec
 	commandSuccess  *prometheus.CounterVec
 	commandError    *prometheus.CounterVec
+	sessions        *prometheus.GaugeVec
+	sessionsIdle    *prometheus.GaugeVec
+	sessionLicenses *prometheus.GaugeVec
 
 	// Instance label (hostname)
 	instance string
@@ -35,6 +38,7 @@ type PrometheusCollector struct {
 //   - apk_ci_command_duration_seconds (histogram)
 //   - apk_ci_command_success_total (counter)
 //   - apk_ci_command_error_total (counter)
+//   - apk_ci_sessions, apk_ci_sessions_idle, apk_ci_session_licenses (gauges, nr-sessions-report)
 func NewPrometheusCollector(config Config, logger logging.Logger) (*PrometheusCollector, error) {
 	if err := config.Validate(); err != nil {
 		return nil, err
@@ -88,10 +92,36 @@ func NewPrometheusCollector(config Config, logger logging.Logger) (*PrometheusCo
 		[]string{"command", "infobase"},
 	)
 
+	// Gauges сессий 1C (заполняются только nr-sessions-report)
+	sessions := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "apk_ci",
+			Name:      "sessions",
+			Help:      "Number of 1C infobase sessions by application",
+		},
+		[]string{"infobase", "app"},
+	)
+	sessionsIdle := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "apk_ci",
+			Name:      "sessions_idle",
+			Help:      "Number of 1C infobase sessions idle longer than the report threshold",
+		},
+		[]string{"infobase"},
+	)
+	sessionLicenses := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "apk_ci",
+			Name:      "session_licenses",
+			Help:      "Number of 1C infobase sessions consuming a license",
+		},
+		[]string{"infobase"},
+	)
+
 	// Регистрируем все метрики атомарно.
 	// Используем Register вместо MustRegister для избежания panic.
 	// Ошибка возможна только при дублировании имён метрик в одном registry.
-	collectors := []prometheus.Collector{commandDuration, commandSuccess, commandError}
+	collectors := []prometheus.Collector{commandDuration, commandSuccess, commandError, sessions, sessionsIdle, sessionLicenses}
 	for _, c := range collectors {
 		if err := registry.Register(c); err != nil {
 			return nil, fmt.Errorf("ошибка регистрации метрики: %w", err)
@@ -105,6 +135,9 @@ func NewPrometheusCollector(config Config, logger logging.Logger) (*PrometheusCo
 		commandDuration: commandDuration,
 		commandSuccess:  commandSuccess,
 		commandError:    commandError,
+		sessions:        sessions,
+		sessionsIdle:    sessionsIdle,
+		sessionLicenses: sessionLicenses,
 		instance:        instance,
 	}, nil
 }
@@ -171,6 +204,23 @@ func (c *PrometheusCollector) RecordCommandEnd(command, infobase string, duratio
 	)
 }
 
+// RecordSessions устанавливает gauges сессий информационной базы.
+func (c *PrometheusCollector) RecordSessions(infobase string, stats SessionStats) {
+	infobase = sanitizeLabel(infobase)
+
+	for app, n := range stats.ByApp {
+		c.sessions.WithLabelValues(infobase, sanitizeLabel(app)).Set(float64(n))
+	}
+	c.sessionsIdle.WithLabelValues(infobase).Set(float64(stats.Idle))
+	c.sessionLicenses.WithLabelValues(infobase).Set(float64(stats.Licenses))
+
+	c.logger.Debug("metrics: sessions recorded",
+		"infobase", infobase,
+		"idle", stats.Idle,
+		"licenses", stats.Licenses,
+	)
+}
+
 // Push отправляет метрики в Pushgateway.
 // Возвращает nil даже при ошибке — ошибки логируются (AC8).
 func (c *PrometheusCollector) Push(ctx context.Context) error {
@@ -363,6 +363,49 @@ func TestPrometheusCollector_ContextCancellation(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+// TestPrometheusCollector_RecordSessions проверяет gauges сессий nr-sessions-report.
+func TestPrometheusCollector_RecordSessions(t *testing.T) {
+	config := Config{
+		Enabled:        true,
+		PushgatewayURL: "http://localhost:9091",
+		JobName:        "test-job",
+		Timeout:        10 * time.Second,
+	}
+
+	collector, err := NewPrometheusCollector(config, logging.NewNopLogger())
+	require.NoError(t, err)
+
+	var _ SessionRecorder = collector
+	var _ SessionRecorder = NewNopCollector()
+
+	collector.RecordSessions("TestDB", SessionStats{
+		ByApp:    map[string]int{"1CV8C": 12, "Designer": 2},
+		Idle:     3,
+		Licenses: 13,
+	})
+
+	metrics, err := collector.GetRegistry().Gather()
+	require.NoError(t, err)
+
+	values := make(map[string]float64)
+	for _, m := range metrics {
+		for _, metric := range m.GetMetric() {
+			key := m.GetName()
+			for _, l := range metric.GetLabel() {
+				if l.GetName() == "app" {
+					key += "/" + l.GetValue()
+				}
+			}
+			values[key] = metric.GetGauge().GetValue()
+		}
+	}
+
+	assert.Equal(t, 12.0, values["apk_ci_sessions/1CV8C"])
+	assert.Equal(t, 2.0, values["apk_ci_sessions/Designer"])
+	assert.Equal(t, 3.0, values["apk_ci_sessions_idle"])
+	assert.Equal(t, 13.0, values["apk_ci_session_licenses"])
+}
+
 // TestNopCollector проверяет NopCollector.
 func TestNopCollector(t *testing.T) {
 	collector := NewNopCollector()
@@ -370,6 +413,7 @@ func TestNopCollector(t *testing.T) {
 	// Все методы должны работать без паники
 	collector.RecordCommandStart("test", "db")
 	collector.RecordCommandEnd("test", "db", time.Second, true)
+	collector.RecordSessions("db", SessionStats{ByApp: map[string]int{"1CV8C": 1}})
 	err := collector.Push(context.Background())
 	assert.NoError(t, err)
 }
@@ -0,0 +1,205 @@
+package platform
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Kargones/apk-ci/internal/pkg/apperrors"
+)
+
+// installPlatform создаёт каталог версии с заглушками исполняемых файлов.
+func installPlatform(t *testing.T, base, version string, tools ...Tool) {
+	t.Helper()
+	dir := filepath.Join(base, version)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, tool := range tools {
+		path := Installation{Dir: dir}.Path(tool)
+		if err := os.WriteFile(path, []byte("#!/bin/sh\n"), 0o755); err != nil { //nolint:gosec // тестовая заглушка
+			t.Fatal(err)
+		}
+	}
+}
+
+func appErrorCode(err error) string {
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) {
+		return appErr.Code
+	}
+	return ""
+}
+
+func TestParseVersion(t *testing.T) {
+	v, err := ParseVersion("8.3.27.1606")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != (Version{8, 3, 27, 1606}) || v.String() != "8.3.27.1606" {
+		t.Errorf("ParseVersion() = %v", v)
+	}
+
+	v, err = ParseVersion("8.3.24")
+	if err != nil || v.String() != "8.3.24" {
+		t.Errorf("ParseVersion(8.3.24) = %v, %v", v, err)
+	}
+
+	for _, bad := range []string{"", "8.3", "8.3.x.1", "8.3.27.1606.1"} {
+		if _, err := ParseVersion(bad); err == nil {
+			t.Errorf("ParseVersion(%q) expected error", bad)
+		}
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	a := Version{8, 3, 24, 1467}
+	b := Version{8, 3, 27, 1606}
+	if a.Compare(b) != -1 || b.Compare(a) != 1 || a.Compare(a) != 0 {
+		t.Error("unexpected Compare result")
+	}
+	if (Version{8, 3, 24, 0}).Compare(a) != -1 {
+		t.Error("partial version must be lower than any build of the release")
+	}
+}
+
+func TestParseCompatibilityMode(t *testing.T) {
+	tests := []struct {
+		mode    string
+		want    string
+		wantErr bool
+	}{
+		{mode: "Version8_3_24", want: "8.3.24"},
+		{mode: "Версия8_3_21", want: "8.3.21"},
+		{mode: "DontUse", want: "0.0.0"},
+		{mode: "", want: "0.0.0"},
+		{mode: "8_3_24", wantErr: true},
+	}
+	for _, tt := range tests {
+		v, err := ParseCompatibilityMode(tt.mode)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseCompatibilityMode(%q) expected error", tt.mode)
+			}
+			continue
+		}
+		if err != nil || v.String() != tt.want {
+			t.Errorf("ParseCompatibilityMode(%q) = %v, %v; want %s", tt.mode, v, err, tt.want)
+		}
+	}
+}
+
+func TestReadCompatibilityMode(t *testing.T) {
+	dir := t.TempDir()
+	xmlData := `<?xml version="1.0" encoding="UTF-8"?>
+<MetaDataObject xmlns="http://v8.1c.ru/8.3/MDClasses">
+	<Configuration uuid="00000000-0000-0000-0000-000000000000">
+		<Properties>
+			<Name>Main</Name>
+			<CompatibilityMode>Version8_3_24</CompatibilityMode>
+		</Properties>
+	</Configuration>
+</MetaDataObject>`
+	if err := os.WriteFile(filepath.Join(dir, "Configuration.xml"), []byte(xmlData), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := ReadCompatibilityMode(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.String() != "8.3.24" {
+		t.Errorf("ReadCompatibilityMode() = %v, want 8.3.24", v)
+	}
+
+	v, err = ReadCompatibilityMode(t.TempDir())
+	if err != nil || !v.IsZero() {
+		t.Errorf("missing Configuration.xml: %v, %v; want zero version", v, err)
+	}
+}
+
+func TestScan(t *testing.T) {
+	base := t.TempDir()
+	installPlatform(t, base, "8.3.27.1606", Tool1cv8)
+	installPlatform(t, base, "8.3.24.1467", Tool1cv8)
+	installPlatform(t, base, "common")
+
+	installs, err := Scan(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(installs) != 2 || installs[0].Version.String() != "8.3.24.1467" {
+		t.Errorf("Scan() = %+v, want two versions in ascending order", installs)
+	}
+
+	if _, err := Scan(filepath.Join(base, "missing")); appErrorCode(err) != ErrPlatformNotFound {
+		t.Errorf("Scan(missing) error = %v", err)
+	}
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	base := t.TempDir()
+	installPlatform(t, base, "8.3.21.1895", Tool1cv8, ToolIbcmd, ToolRac)
+	installPlatform(t, base, "8.3.24.1467", Tool1cv8, ToolIbcmd, ToolRac)
+	installPlatform(t, base, "8.3.27.1606", Tool1cv8, ToolRac)
+
+	tests := []struct {
+		name     string
+		pinned   string
+		tool     Tool
+		req      Requirement
+		want     string
+		wantCode string
+	}{
+		{name: "newest by default", tool: Tool1cv8, want: "8.3.27.1606"},
+		{name: "newest with tool", tool: ToolIbcmd, want: "8.3.24.1467"},
+		{name: "minimum satisfied", tool: Tool1cv8, req: Requirement{Minimum: Version{8, 3, 22, 0}}, want: "8.3.27.1606"},
+		{name: "exact from server", tool: Tool1cv8, req: Requirement{Exact: Version{8, 3, 21, 1895}}, want: "8.3.21.1895"},
+		{name: "pinned", pinned: "8.3.24.1467", tool: ToolRac, want: "8.3.24.1467"},
+		{name: "exact not installed", tool: Tool1cv8, req: Requirement{Exact: Version{8, 3, 25, 1000}, Source: "сервер 1С srv"}, wantCode: ErrPlatformNoMatch},
+		{name: "minimum too high", tool: ToolIbcmd, req: Requirement{Minimum: Version{8, 3, 27, 0}}, wantCode: ErrPlatformNoMatch},
+		{name: "pinned conflicts with server", pinned: "8.3.24.1467", tool: Tool1cv8, req: Requirement{Exact: Version{8, 3, 27, 1606}}, wantCode: ErrPlatformNoMatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pinned Version
+			if tt.pinned != "" {
+				pinned, _ = ParseVersion(tt.pinned)
+			}
+			inst, err := NewResolver(base, pinned).Resolve(tt.tool, tt.req)
+			if tt.wantCode != "" {
+				if appErrorCode(err) != tt.wantCode {
+					t.Fatalf("err = %v, want %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if inst.Version.String() != tt.want {
+				t.Errorf("Resolve() = %s, want %s", inst.Version, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolver_ErrorListsInstalledVersions(t *testing.T) {
+	base := t.TempDir()
+	installPlatform(t, base, "8.3.24.1467", Tool1cv8)
+
+	_, err := NewResolver(base, Version{}).ResolvePath(Tool1cv8, Requirement{Exact: Version{8, 3, 27, 1606}, Source: "сервер 1С srv"})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for _, want := range []string{"8.3.27.1606", "сервер 1С srv", "установлены: 8.3.24.1467"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q must contain %q", err, want)
+		}
+	}
+
+	if _, err := NewResolver(base, Version{}).ResolvePath(ToolRac, Requirement{}); appErrorCode(err) != ErrPlatformNotFound {
+		t.Errorf("missing tool error = %v, want %s", err, ErrPlatformNotFound)
+	}
+}
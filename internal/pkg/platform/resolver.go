@@ -0,0 +1,180 @@
+package platform
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/Kargones/apk-ci/internal/pkg/apperrors"
+)
+
+// Коды ошибок выбора платформы.
+const (
+	ErrPlatformNotFound = "PLATFORM.NOT_FOUND" // Не найдено ни одной установленной версии с нужным инструментом
+	ErrPlatformNoMatch  = "PLATFORM.NO_MATCH"  // Нет установленной версии, удовлетворяющей требованию
+)
+
+// Tool — исполняемый файл платформы.
+type Tool string
+
+// Исполняемые файлы платформы.
+const (
+	Tool1cv8  Tool = "1cv8"
+	ToolIbcmd Tool = "ibcmd"
+	ToolRac   Tool = "rac"
+)
+
+// Installation — установленная версия платформы.
+type Installation struct {
+	// Version — версия платформы
+	Version Version
+	// Dir — каталог установки (например, /opt/1cv8/x86_64/8.3.27.1606)
+	Dir string
+}
+
+// Path возвращает путь к исполняемому файлу tool в каталоге установки.
+func (i Installation) Path(tool Tool) string {
+	name := string(tool)
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return filepath.Join(i.Dir, name)
+}
+
+// Has сообщает, установлен ли tool в этой версии платформы.
+func (i Installation) Has(tool Tool) bool {
+	st, err := os.Stat(i.Path(tool))
+	return err == nil && !st.IsDir()
+}
+
+// Scan возвращает установленные версии платформы из basePath
+// (подкаталоги с именем-версией), отсортированные по возрастанию версии.
+func Scan(basePath string) ([]Installation, error) {
+	entries, err := os.ReadDir(basePath)
+	if err != nil {
+		return nil, apperrors.NewAppError(ErrPlatformNotFound,
+			fmt.Sprintf("не удалось прочитать каталог установки платформы %s", basePath), err)
+	}
+	var installs []Installation
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		v, err := ParseVersion(e.Name())
+		if err != nil || v.Build == 0 {
+			continue
+		}
+		installs = append(installs, Installation{Version: v, Dir: filepath.Join(basePath, e.Name())})
+	}
+	sort.Slice(installs, func(i, j int) bool {
+		return installs[i].Version.Compare(installs[j].Version) < 0
+	})
+	return installs, nil
+}
+
+// Requirement — требование к версии платформы.
+type Requirement struct {
+	// Exact — точная версия (версия сервера 1С, полученная через RAC)
+	Exact Version
+	// Minimum — минимальная версия (режим совместимости конфигурации)
+	Minimum Version
+	// Source — источник требования для сообщений об ошибках
+	// (например, "Configuration.xml" или "сервер 1С srv-app")
+	Source string
+}
+
+// satisfiedBy сообщает, удовлетворяет ли версия v требованию.
+func (r Requirement) satisfiedBy(v Version) bool {
+	if !r.Exact.IsZero() && v.Compare(r.Exact) != 0 {
+		return false
+	}
+	return r.Minimum.IsZero() || v.Compare(r.Minimum) >= 0
+}
+
+// String описывает требование для сообщений об ошибках.
+func (r Requirement) String() string {
+	var s string
+	switch {
+	case !r.Exact.IsZero():
+		s = "версия " + r.Exact.String()
+	case !r.Minimum.IsZero():
+		s = "версия не ниже " + r.Minimum.String()
+	default:
+		return "любая версия"
+	}
+	if r.Source != "" {
+		s += " (" + r.Source + ")"
+	}
+	return s
+}
+
+// Resolver выбирает установленную версию платформы по требованию.
+type Resolver struct {
+	// BasePath — каталог с установленными версиями платформы
+	BasePath string
+	// Pinned — версия, закреплённая конфигурацией (нулевая — не закреплена)
+	Pinned Version
+}
+
+// NewResolver создаёт Resolver для каталога basePath.
+func NewResolver(basePath string, pinned Version) *Resolver {
+	return &Resolver{BasePath: basePath, Pinned: pinned}
+}
+
+// Resolve выбирает установленную версию платформы, содержащую tool и
+// удовлетворяющую требованию req и закреплённой версии.
+// Без точного требования выбирается наиболее новая подходящая версия.
+// Если подходящей версии нет, возвращается ошибка с перечнем установленных версий.
+func (r *Resolver) Resolve(tool Tool, req Requirement) (Installation, error) {
+	installs, err := Scan(r.BasePath)
+	if err != nil {
+		return Installation{}, err
+	}
+	var withTool []Installation
+	for _, inst := range installs {
+		if inst.Has(tool) {
+			withTool = append(withTool, inst)
+		}
+	}
+	if len(withTool) == 0 {
+		return Installation{}, apperrors.NewAppError(ErrPlatformNotFound,
+			fmt.Sprintf("в %s не найдено установленных версий платформы с %s", r.BasePath, tool), nil)
+	}
+
+	if !r.Pinned.IsZero() {
+		if !req.satisfiedBy(r.Pinned) {
+			return Installation{}, apperrors.NewAppError(ErrPlatformNoMatch,
+				fmt.Sprintf("закреплённая версия платформы %s не удовлетворяет требованию: %s", r.Pinned, req), nil)
+		}
+		req.Exact = r.Pinned
+	}
+
+	for i := len(withTool) - 1; i >= 0; i-- {
+		if req.satisfiedBy(withTool[i].Version) {
+			return withTool[i], nil
+		}
+	}
+	return Installation{}, apperrors.NewAppError(ErrPlatformNoMatch,
+		fmt.Sprintf("для %s требуется %s, установлены: %s", tool, req, versionList(withTool)), nil)
+}
+
+// ResolvePath выбирает версию платформы и возвращает путь к tool.
+func (r *Resolver) ResolvePath(tool Tool, req Requirement) (string, error) {
+	inst, err := r.Resolve(tool, req)
+	if err != nil {
+		return "", err
+	}
+	return inst.Path(tool), nil
+}
+
+// versionList перечисляет версии установок через запятую.
+func versionList(installs []Installation) string {
+	names := make([]string, len(installs))
+	for i, inst := range installs {
+		names[i] = inst.Version.String()
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,132 @@
+// Package platform определяет установленные версии платформы 1С:Предприятие
+// и выбирает исполняемые файлы (1cv8, ibcmd, rac) версии, которая требуется
+// информационной базе или конфигурации.
+//
+// Требование к версии задаётся режимом совместимости из Configuration.xml
+// (минимальная версия) или версией сервера 1С, полученной через RAC (точная версия):
+// подключение к серверной базе платформой другой версии приводит к ошибкам
+// и может повредить информационную базу.
+package platform
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Version — версия платформы 1С (например, 8.3.27.1606).
+// Build = 0 означает неполную версию (8.3.24 из режима совместимости).
+type Version struct {
+	Major   int
+	Minor   int
+	Release int
+	Build   int
+}
+
+// ParseVersion разбирает строку версии из 3 или 4 чисел, разделённых точками.
+func ParseVersion(s string) (Version, error) {
+	parts := strings.Split(strings.TrimSpace(s), ".")
+	if len(parts) < 3 || len(parts) > 4 {
+		return Version{}, fmt.Errorf("некорректная версия платформы %q: ожидается формат 8.3.27[.1606]", s)
+	}
+	nums := make([]int, 4)
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return Version{}, fmt.Errorf("некорректная версия платформы %q: ожидается формат 8.3.27[.1606]", s)
+		}
+		nums[i] = n
+	}
+	return Version{Major: nums[0], Minor: nums[1], Release: nums[2], Build: nums[3]}, nil
+}
+
+// IsZero сообщает, что версия не задана.
+func (v Version) IsZero() bool {
+	return v == Version{}
+}
+
+// String возвращает версию в формате 8.3.27.1606 (или 8.3.27 для неполной версии).
+func (v Version) String() string {
+	if v.Build == 0 {
+		return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Release)
+	}
+	return fmt.Sprintf("%d.%d.%d.%d", v.Major, v.Minor, v.Release, v.Build)
+}
+
+// Compare сравнивает версии: -1 если v < o, 0 если равны, 1 если v > o.
+func (v Version) Compare(o Version) int {
+	for _, d := range [...]int{v.Major - o.Major, v.Minor - o.Minor, v.Release - o.Release, v.Build - o.Build} {
+		switch {
+		case d < 0:
+			return -1
+		case d > 0:
+			return 1
+		}
+	}
+	return 0
+}
+
+// ParseCompatibilityMode разбирает значение режима совместимости конфигурации
+// (Version8_3_24 или Версия8_3_24). Режим "не использовать" и пустое значение
+// возвращают нулевую версию — требования к платформе нет.
+func ParseCompatibilityMode(mode string) (Version, error) {
+	mode = strings.TrimSpace(mode)
+	switch mode {
+	case "", "DontUse", "НеИспользовать":
+		return Version{}, nil
+	}
+	num := strings.TrimPrefix(strings.TrimPrefix(mode, "Version"), "Версия")
+	if num == mode {
+		return Version{}, fmt.Errorf("некорректный режим совместимости %q", mode)
+	}
+	return ParseVersion(strings.ReplaceAll(num, "_", "."))
+}
+
+// ReadCompatibilityMode читает режим совместимости из Configuration.xml
+// в каталоге выгрузки конфигурации configDir.
+// Отсутствие файла или элемента CompatibilityMode не является ошибкой:
+// возвращается нулевая версия.
+func ReadCompatibilityMode(configDir string) (Version, error) {
+	f, err := os.Open(filepath.Join(configDir, "Configuration.xml")) //nolint:gosec // путь к каталогу выгрузки задаётся конфигурацией
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return Version{}, nil
+		}
+		return Version{}, fmt.Errorf("ошибка чтения Configuration.xml: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	mode, err := compatibilityModeFromXML(f)
+	if err != nil {
+		return Version{}, err
+	}
+	return ParseCompatibilityMode(mode)
+}
+
+// compatibilityModeFromXML возвращает значение первого элемента CompatibilityMode.
+func compatibilityModeFromXML(r io.Reader) (string, error) {
+	dec := xml.NewDecoder(r)
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return "", nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("ошибка разбора Configuration.xml: %w", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "CompatibilityMode" {
+			continue
+		}
+		var mode string
+		if err := dec.DecodeElement(&mode, &start); err != nil {
+			return "", fmt.Errorf("ошибка разбора Configuration.xml: %w", err)
+		}
+		return mode, nil
+	}
+}
@@ -14,6 +14,9 @@ type JSONProgress struct {
 	encoder   *json.Encoder
 	startTime time.Time
 	lastEmit  time.Time
+	// eta — оценка оставшегося времени от источника прогресса (etaSet — задана через SetETA)
+	eta    time.Duration
+	etaSet bool
 }
 
 // NewJSONProgress создаёт новый JSON progress.
@@ -67,8 +70,12 @@ func (p *JSONProgress) Update(current int64, message string) {
 		}
 		percentPtr = &percent
 
-		// ETA вычисляем только если есть прогресс
-		if current > 0 {
+		// ETA от источника прогресса приоритетнее расчёта по скорости
+		if p.etaSet {
+			eta := int64(p.eta.Seconds())
+			etaPtr = &eta
+		} else if current > 0 {
+			// ETA вычисляем только если есть прогресс
 			eta := p.calculateETASeconds(current)
 			if eta > 0 {
 				etaPtr = &eta
@@ -87,6 +94,12 @@ func (p *JSONProgress) Update(current int64, message string) {
 	}
 }
 
+// SetETA устанавливает оценку оставшегося времени от источника прогресса.
+func (p *JSONProgress) SetETA(eta time.Duration) {
+	p.eta = eta
+	p.etaSet = true
+}
+
 // SetTotal устанавливает общее количество единиц работы.
 func (p *JSONProgress) SetTotal(total int64) {
 	p.opts.Total = total
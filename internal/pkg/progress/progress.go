@@ -22,6 +22,21 @@ type Progress interface {
 	SetTotal(total int64)
 }
 
+// ETASetter — необязательное расширение Progress: оценка оставшегося времени,
+// полученная от источника прогресса (например, сервера СУБД), вместо расчёта
+// по скорости выполнения. Проверяется через type assertion (см. SetETA).
+type ETASetter interface {
+	// SetETA устанавливает оставшееся время; используется до следующего вызова SetETA.
+	SetETA(eta time.Duration)
+}
+
+// SetETA передаёт p оценку оставшегося времени, если реализация её поддерживает.
+func SetETA(p Progress, eta time.Duration) {
+	if s, ok := p.(ETASetter); ok {
+		s.SetETA(eta)
+	}
+}
+
 // Options конфигурирует progress bar.
 type Options struct {
 	// Total — общее количество единиц работы (0 = indeterminate)
@@ -169,6 +169,19 @@ func TestTTYProgressETA(t *testing.T) {
 	}
 }
 
+// TestTTYProgressExternalETA проверяет, что ETA от источника прогресса заменяет расчёт по скорости.
+func TestTTYProgressExternalETA(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewTTYProgress(Options{Total: 100, Output: &buf, ShowETA: true})
+	p.Start("Тест")
+	SetETA(p, 63*time.Minute)
+	p.Update(99, "Тест")
+
+	if output := buf.String(); !strings.Contains(output, "ETA: 1h 3m") {
+		t.Errorf("Output should contain 'ETA: 1h 3m', got: %s", output)
+	}
+}
+
 // TestNonTTYProgressReportsEvery10Percent проверяет что progress выводит каждые 10%.
 // CRITICAL-2 fix: NonTTYProgress теперь использует slog, поэтому тест проверяет
 // что lastReportedPercent обновляется корректно.
@@ -286,6 +299,33 @@ func TestJSONEvents(t *testing.T) {
 	}
 }
 
+// TestJSONProgressExternalETA проверяет передачу ETA от источника прогресса в событие progress.
+func TestJSONProgressExternalETA(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewJSONProgress(Options{Total: 1000, Output: &buf})
+	p.Start("Тест")
+	SetETA(p, 90*time.Second)
+	p.Update(995, "RESTORE DATABASE")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 JSON events, got %d", len(lines))
+	}
+	var event Event
+	if err := json.Unmarshal([]byte(lines[1]), &event); err != nil {
+		t.Fatalf("Failed to parse progress event: %v", err)
+	}
+	if event.Percent == nil || *event.Percent != 99 {
+		t.Errorf("percent = %v, want 99", event.Percent)
+	}
+	if event.ETASeconds == nil || *event.ETASeconds != 90 {
+		t.Errorf("eta_seconds = %v, want 90", event.ETASeconds)
+	}
+
+	// Реализации без поддержки ETA не паникуют
+	SetETA(&NoopProgress{}, time.Second)
+}
+
 // TestSpinnerProgressIndeterminate проверяет работу spinner для неизвестного total.
 // CRITICAL-2 update: SpinnerProgress в non-TTY режиме использует slog вместо buffer,
 // поэтому тест проверяет что методы вызываются без паники.
@@ -18,6 +18,9 @@ type TTYProgress struct {
 	current   int64
 	lastDraw  time.Time
 	message   string
+	// eta — оценка оставшегося времени от источника прогресса (etaSet — задана через SetETA)
+	eta    time.Duration
+	etaSet bool
 }
 
 // NewTTYProgress создаёт новый TTY progress bar.
@@ -59,6 +62,14 @@ func (p *TTYProgress) SetTotal(total int64) {
 	p.opts.Total = total
 }
 
+// SetETA устанавливает оценку оставшегося времени от источника прогресса.
+func (p *TTYProgress) SetETA(eta time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.eta = eta
+	p.etaSet = true
+}
+
 // Finish завершает progress bar и выводит финальный статус.
 func (p *TTYProgress) Finish() {
 	p.mu.Lock()
@@ -137,6 +148,13 @@ func (p *TTYProgress) renderBar(percent int) string {
 
 // calculateETA вычисляет оставшееся время на основе прошедшего времени и текущего прогресса.
 func (p *TTYProgress) calculateETA() string {
+	if p.etaSet {
+		if remaining := p.eta.Round(time.Second); remaining >= time.Second {
+			return FormatDuration(remaining)
+		}
+		return "<1s"
+	}
+
 	if p.current == 0 {
 		return "вычисляется..."
 	}
@@ -0,0 +1,203 @@
+// Package workerpool предоставляет ограниченный пул для параллельного выполнения
+// независимых задач (например, операций над расширениями конфигурации 1С).
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Mode определяет поведение пула при ошибке задачи.
+type Mode string
+
+const (
+	// ModeFailFast — первая ошибка отменяет контекст, ещё не начатые задачи пропускаются.
+	ModeFailFast Mode = "fail-fast"
+	// ModeCollectAll — выполняются все задачи, ошибки собираются.
+	ModeCollectAll Mode = "collect-all"
+)
+
+// skippedMessage — описание задачи, не выполнявшейся из-за прерывания пула.
+const skippedMessage = "задача не выполнялась: выполнение прервано"
+
+// ParseMode разбирает режим обработки ошибок. Пустая строка — ModeFailFast.
+func ParseMode(s string) (Mode, error) {
+	switch Mode(s) {
+	case "", ModeFailFast:
+		return ModeFailFast, nil
+	case ModeCollectAll:
+		return ModeCollectAll, nil
+	default:
+		return "", fmt.Errorf("недопустимый режим обработки ошибок: %q, допустимые: %s, %s", s, ModeFailFast, ModeCollectAll)
+	}
+}
+
+// Task — задача пула.
+type Task struct {
+	// Name — имя задачи в результатах (например, имя расширения).
+	Name string
+	// LockKey — ключ эксклюзивного ресурса. Задачи с одинаковым непустым
+	// ключом выполняются строго последовательно (например, операции,
+	// требующие монопольного доступа к одной информационной базе).
+	LockKey string
+	// Run выполняет задачу.
+	Run func(ctx context.Context) error
+}
+
+// Result — результат выполнения задачи.
+type Result struct {
+	Name       string `json:"name"`
+	Success    bool   `json:"success"`
+	Skipped    bool   `json:"skipped,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+	// Err — исходная ошибка задачи (не сериализуется).
+	Err error `json:"-"`
+}
+
+// Options — параметры выполнения пула.
+type Options struct {
+	// Concurrency — максимальное число одновременно выполняемых задач (минимум 1).
+	Concurrency int
+	// Mode — режим обработки ошибок.
+	Mode Mode
+}
+
+// Run выполняет задачи не более чем в opts.Concurrency потоков и возвращает
+// результаты в порядке задач. В режиме ModeFailFast возвращается первая ошибка,
+// в режиме ModeCollectAll — объединение всех ошибок в порядке задач.
+// Каждая ошибка дополняется именем задачи.
+func Run(ctx context.Context, opts Options, tasks []Task) ([]Result, error) {
+	results := make([]Result, len(tasks))
+	if len(tasks) == 0 {
+		return results, nil
+	}
+
+	workers := opts.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(tasks) {
+		workers = len(tasks)
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		locks    keyedMutex
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	queue := make(chan int)
+
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				results[i] = runTask(runCtx, &locks, tasks[i])
+				if results[i].Err == nil || opts.Mode == ModeCollectAll {
+					continue
+				}
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("%s: %w", tasks[i].Name, results[i].Err)
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	next := 0
+feed:
+	for ; next < len(tasks); next++ {
+		select {
+		case queue <- next:
+		case <-runCtx.Done():
+			break feed
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	for i := next; i < len(tasks); i++ {
+		results[i] = Result{Name: tasks[i].Name, Skipped: true, Error: skippedMessage}
+	}
+
+	if opts.Mode == ModeCollectAll {
+		var errs []error
+		for i, r := range results {
+			if r.Err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", tasks[i].Name, r.Err))
+			}
+		}
+		firstErr = errors.Join(errs...)
+	}
+	if firstErr == nil && ctx.Err() != nil && hasSkipped(results) {
+		// Контекст вызывающего отменён до начала части задач.
+		return results, ctx.Err()
+	}
+	return results, firstErr
+}
+
+// hasSkipped сообщает, есть ли среди результатов невыполненные задачи.
+func hasSkipped(results []Result) bool {
+	for _, r := range results {
+		if r.Skipped {
+			return true
+		}
+	}
+	return false
+}
+
+// runTask выполняет задачу под блокировкой её ключа.
+func runTask(ctx context.Context, locks *keyedMutex, t Task) Result {
+	if t.LockKey != "" {
+		unlock := locks.lock(t.LockKey)
+		defer unlock()
+	}
+	res := Result{Name: t.Name}
+	if err := ctx.Err(); err != nil {
+		res.Skipped = true
+		res.Error = skippedMessage
+		return res
+	}
+	start := time.Now()
+	err := t.Run(ctx)
+	res.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		res.Err = err
+		res.Error = err.Error()
+		return res
+	}
+	res.Success = true
+	return res
+}
+
+// keyedMutex — набор мьютексов по ключу.
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// lock захватывает мьютекс ключа и возвращает функцию освобождения.
+func (k *keyedMutex) lock(key string) func() {
+	k.mu.Lock()
+	if k.locks == nil {
+		k.locks = make(map[string]*sync.Mutex)
+	}
+	m, ok := k.locks[key]
+	if !ok {
+		m = &sync.Mutex{}
+		k.locks[key] = m
+	}
+	k.mu.Unlock()
+	m.Lock()
+	return m.Unlock
+}
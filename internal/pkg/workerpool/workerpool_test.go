@@ -0,0 +1,159 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMode(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Mode
+		wantErr bool
+	}{
+		{input: "", want: ModeFailFast},
+		{input: "fail-fast", want: ModeFailFast},
+		{input: "collect-all", want: ModeCollectAll},
+		{input: "ignore", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseMode(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRun_Empty(t *testing.T) {
+	results, err := Run(context.Background(), Options{Concurrency: 4}, nil)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestRun_ConcurrencyLimit(t *testing.T) {
+	var running, peak atomic.Int32
+	tasks := make([]Task, 8)
+	for i := range tasks {
+		tasks[i] = Task{
+			Name: fmt.Sprintf("ext%d", i),
+			Run: func(context.Context) error {
+				n := running.Add(1)
+				for {
+					p := peak.Load()
+					if n <= p || peak.CompareAndSwap(p, n) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				running.Add(-1)
+				return nil
+			},
+		}
+	}
+
+	results, err := Run(context.Background(), Options{Concurrency: 3}, tasks)
+	require.NoError(t, err)
+	require.Len(t, results, len(tasks))
+	for i, r := range results {
+		assert.Equal(t, tasks[i].Name, r.Name)
+		assert.True(t, r.Success)
+	}
+	assert.LessOrEqual(t, peak.Load(), int32(3))
+	assert.Greater(t, peak.Load(), int32(1))
+}
+
+func TestRun_LockKeySerializes(t *testing.T) {
+	var running, peak atomic.Int32
+	tasks := make([]Task, 4)
+	for i := range tasks {
+		tasks[i] = Task{
+			Name:    fmt.Sprintf("ext%d", i),
+			LockKey: "/F /tmp/db",
+			Run: func(context.Context) error {
+				n := running.Add(1)
+				if n > peak.Load() {
+					peak.Store(n)
+				}
+				time.Sleep(5 * time.Millisecond)
+				running.Add(-1)
+				return nil
+			},
+		}
+	}
+
+	_, err := Run(context.Background(), Options{Concurrency: 4}, tasks)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), peak.Load())
+}
+
+func TestRun_FailFast(t *testing.T) {
+	errBoom := errors.New("boom")
+	var executed atomic.Int32
+	tasks := []Task{
+		{Name: "a", Run: func(context.Context) error { executed.Add(1); return errBoom }},
+		{Name: "b", Run: func(context.Context) error { executed.Add(1); return nil }},
+		{Name: "c", Run: func(context.Context) error { executed.Add(1); return nil }},
+	}
+
+	results, err := Run(context.Background(), Options{Concurrency: 1, Mode: ModeFailFast}, tasks)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, errBoom)
+	assert.Contains(t, err.Error(), "a: boom")
+	assert.Equal(t, int32(1), executed.Load())
+
+	require.Len(t, results, 3)
+	assert.False(t, results[0].Success)
+	assert.Equal(t, "boom", results[0].Error)
+	assert.True(t, results[1].Skipped)
+	assert.True(t, results[2].Skipped)
+}
+
+func TestRun_CollectAll(t *testing.T) {
+	tasks := []Task{
+		{Name: "a", Run: func(context.Context) error { return errors.New("first") }},
+		{Name: "b", Run: func(context.Context) error { return nil }},
+		{Name: "c", Run: func(context.Context) error { return errors.New("third") }},
+	}
+
+	results, err := Run(context.Background(), Options{Concurrency: 2, Mode: ModeCollectAll}, tasks)
+	require.Error(t, err)
+	assert.Equal(t, "a: first\nc: third", err.Error())
+
+	require.Len(t, results, 3)
+	assert.False(t, results[0].Success)
+	assert.True(t, results[1].Success)
+	assert.False(t, results[2].Success)
+	for _, r := range results {
+		assert.False(t, r.Skipped)
+	}
+}
+
+func TestRun_CancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var executed atomic.Int32
+	tasks := []Task{
+		{Name: "a", Run: func(context.Context) error { executed.Add(1); return nil }},
+		{Name: "b", Run: func(context.Context) error { executed.Add(1); return nil }},
+	}
+
+	results, err := Run(ctx, Options{Concurrency: 2, Mode: ModeCollectAll}, tasks)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int32(0), executed.Load())
+	for _, r := range results {
+		assert.True(t, r.Skipped)
+	}
+}
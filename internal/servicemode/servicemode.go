@@ -1,14 +1,18 @@
-// Package servicemode предоставляет функциональность для управления режимом сервиса
+// Package servicemode предоставляет функциональность для управления режимом сервиса.
+// Операции выполняются через rac.Client из internal/adapter/onec/rac — тот же клиент,
+// что используют NR-команды, поэтому повторы, перекодировка вывода rac и правила
+// блокировки (сообщение, регламентные задания) совпадают для обоих путей.
 package servicemode
 
 import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strconv"
 	"time"
 
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
 	"github.com/Kargones/apk-ci/internal/config"
-	"github.com/Kargones/apk-ci/internal/rac"
 )
 
 // Manager представляет интерфейс для управления сервисным режимом информационных баз.
@@ -19,16 +23,6 @@ type Manager interface {
 	GetServiceModeStatus(ctx context.Context, infobaseName string) (*rac.ServiceModeStatus, error)
 }
 
-// RacClientInterface представляет интерфейс для RAC клиента.
-// Используется для абстракции RAC операций и возможности тестирования с моками.
-type RacClientInterface interface {
-	GetClusterUUID(ctx context.Context) (string, error)
-	GetInfobaseUUID(ctx context.Context, clusterUUID, infobaseName string) (string, error)
-	EnableServiceMode(ctx context.Context, clusterUUID, infobaseUUID string, terminateSessions bool) error
-	DisableServiceMode(ctx context.Context, clusterUUID, infobaseUUID string) error
-	GetServiceModeStatus(ctx context.Context, clusterUUID, infobaseUUID string) (*rac.ServiceModeStatus, error)
-}
-
 // Logger представляет интерфейс для записи логов различных уровней.
 // Используется для унифицированного логирования в компонентах управления сервисным режимом.
 type Logger interface {
@@ -94,19 +88,20 @@ type RacConfig struct {
 // Инкапсулирует RAC клиент и предоставляет высокоуровневые методы управления.
 type Client struct {
 	config    *RacConfig
-	racClient RacClientInterface
+	racClient rac.Client
 	logger    Logger
 }
 
 // NewClient создает новый экземпляр клиента для управления сервисным режимом.
-// Инициализирует RAC клиент с переданной конфигурацией и логгером.
+// Создаёт RAC клиент адаптера с переданной конфигурацией и логгером.
 // Параметры:
 //   - racConfig: конфигурация подключения к RAC
 //   - logger: интерфейс для логирования операций
 //
 // Возвращает:
 //   - *Client: новый экземпляр клиента для управления сервисным режимом
-func NewClient(racConfig RacConfig, logger Logger) *Client {
+//   - error: ошибка создания RAC клиента (путь к rac не найден, сервер не указан)
+func NewClient(racConfig RacConfig, logger Logger) (*Client, error) {
 	// Логируем параметры конфигурации RAC клиента, если logger не nil
 	if logger != nil {
 		logger.Debug("Creating new RAC client for service mode",
@@ -121,50 +116,45 @@ func NewClient(racConfig RacConfig, logger Logger) *Client {
 			"hasRacPassword", racConfig.RacPassword != "")
 	}
 
-	// Преобразуем Logger в *slog.Logger для RAC клиента
-	var slogLogger *slog.Logger
-	if logger != nil {
-		if sl, ok := logger.(*SlogLogger); ok {
-			slogLogger = sl.Logger
-		} else {
-			// Если передан не SlogLogger, создаем дефолтный
-			slogLogger = slog.Default()
-		}
-	} else {
-		// Если logger равен nil, создаем дефолтный
-		slogLogger = slog.Default()
+	// Для SlogLogger RAC клиент пишет в тот же *slog.Logger, иначе — в slog.Default()
+	slogLogger := slog.Default()
+	if sl, ok := logger.(*SlogLogger); ok && sl.Logger != nil {
+		slogLogger = sl.Logger
 	}
 
-	racClient := rac.NewClient(
-		racConfig.RacPath,
-		racConfig.RacServer,
-		racConfig.RacPort,
-		racConfig.RacUser,
-		racConfig.RacPassword,
-		racConfig.DbUser,
-		racConfig.DbPassword,
-		racConfig.RacTimeout,
-		racConfig.RacRetries,
-		slogLogger,
-	)
+	opts := rac.ClientOptions{
+		RACPath:      racConfig.RacPath,
+		Server:       racConfig.RacServer,
+		Timeout:      racConfig.RacTimeout,
+		Retries:      racConfig.RacRetries,
+		ClusterUser:  racConfig.RacUser,
+		ClusterPass:  racConfig.RacPassword,
+		InfobaseUser: racConfig.DbUser,
+		InfobasePass: racConfig.DbPassword,
+		Logger:       slogLogger,
+	}
+	if racConfig.RacPort != 0 {
+		opts.Port = strconv.Itoa(racConfig.RacPort)
+	}
 
-	return &Client{
-		config:    &racConfig,
-		racClient: racClient,
-		logger:    logger,
+	racClient, err := rac.NewClient(opts)
+	if err != nil {
+		return nil, err
 	}
+
+	return NewClientWithRacClient(racConfig, racClient, logger), nil
 }
 
 // NewClientWithRacClient создает новый экземпляр клиента с переданным RAC клиентом.
-// Используется для тестирования с мок-объектами.
+// Используется для тестирования с ractest.MockRACClient и для клиента протокола RAS.
 // Параметры:
 //   - racConfig: конфигурация подключения к RAC
-//   - racClient: интерфейс RAC клиента (может быть мок-объектом)
+//   - racClient: RAC клиент адаптера (может быть мок-объектом)
 //   - logger: интерфейс для логирования операций
 //
 // Возвращает:
 //   - *Client: новый экземпляр клиента для управления сервисным режимом
-func NewClientWithRacClient(racConfig RacConfig, racClient RacClientInterface, logger Logger) *Client {
+func NewClientWithRacClient(racConfig RacConfig, racClient rac.Client, logger Logger) *Client {
 	return &Client{
 		config:    &racConfig,
 		racClient: racClient,
@@ -199,7 +189,11 @@ func ManageServiceMode(ctx context.Context, action, infobaseName string, termina
 		return err
 	}
 
-	client := NewClient(config, logger)
+	client, err := NewClient(config, logger)
+	if err != nil {
+		logger.Error("Failed to create RAC client", "error", err, "infobaseName", infobaseName)
+		return err
+	}
 
 	switch action {
 	case "enable":
@@ -242,7 +236,7 @@ func ManageServiceMode(ctx context.Context, action, infobaseName string, termina
 			"active_sessions", status.ActiveSessions)
 		return nil
 	}
-	
+
 	// Этот код никогда не должен выполняться, так как все валидные действия обработаны выше
 	return fmt.Errorf("unexpected error in action handling")
 }
@@ -260,7 +254,7 @@ func LoadServiceModeConfigForDb(dbName string, cfg *config.Config) (RacConfig, e
 	if cfg == nil {
 		return RacConfig{}, fmt.Errorf("config is nil")
 	}
-	
+
 	smc, err := cfg.LoadServiceModeConfig(dbName)
 	if err != nil {
 		return RacConfig{}, err
@@ -279,8 +273,36 @@ func LoadServiceModeConfigForDb(dbName string, cfg *config.Config) (RacConfig, e
 	}, nil
 }
 
-// EnableServiceMode включает сервисный режим для указанной информационной базы.
-// Получает UUID кластера и информационной базы, затем включает блокировку через RAC клиент.
+// resolveInfobase находит UUID кластера и информационной базы по имени базы.
+func (c *Client) resolveInfobase(ctx context.Context, infobaseName string) (clusterUUID, infobaseUUID string, err error) {
+	if infobaseName == "" {
+		return "", "", fmt.Errorf("infobase name cannot be empty")
+	}
+
+	cluster, err := c.racClient.GetClusterInfo(ctx)
+	if err != nil {
+		c.logger.Error("Failed to get cluster UUID",
+			"error", err,
+			"infobase", infobaseName)
+		return "", "", fmt.Errorf("failed to get cluster UUID: %w", err)
+	}
+	c.logger.Debug("Successfully obtained cluster UUID", "clusterUUID", cluster.UUID)
+
+	infobase, err := c.racClient.GetInfobaseInfo(ctx, cluster.UUID, infobaseName)
+	if err != nil {
+		c.logger.Error("Failed to get infobase UUID",
+			"error", err,
+			"infobase", infobaseName,
+			"clusterUUID", cluster.UUID)
+		return "", "", fmt.Errorf("failed to get infobase UUID: %w", err)
+	}
+	c.logger.Debug("Successfully obtained infobase UUID", "infobaseUUID", infobase.UUID)
+
+	return cluster.UUID, infobase.UUID, nil
+}
+
+// EnableServiceMode включает сервисный режим для указанной информационной базы
+// и проверяет, что блокировка установлена.
 // Параметры:
 //   - ctx: контекст выполнения операции
 //   - infobaseName: имя информационной базы
@@ -289,47 +311,27 @@ func LoadServiceModeConfigForDb(dbName string, cfg *config.Config) (RacConfig, e
 // Возвращает:
 //   - error: ошибка выполнения операции или nil при успехе
 func (c *Client) EnableServiceMode(ctx context.Context, infobaseName string, terminateSessions bool) error {
-	if infobaseName == "" {
-		return fmt.Errorf("infobase name cannot be empty")
-	}
-
 	c.logger.Debug("Enabling service mode",
 		"infobase", infobaseName,
 		"terminateSessions", terminateSessions)
 
-	// Получаем UUID кластера
-	c.logger.Debug("Getting cluster UUID for service mode operation")
-	clusterUUID, err := c.racClient.GetClusterUUID(ctx)
+	clusterUUID, infobaseUUID, err := c.resolveInfobase(ctx, infobaseName)
 	if err != nil {
-		c.logger.Error("Failed to get cluster UUID for service mode",
-			"error", err,
-			"infobase", infobaseName)
-		return fmt.Errorf("failed to get cluster UUID: %w", err)
+		return err
 	}
-	c.logger.Debug("Successfully obtained cluster UUID", "clusterUUID", clusterUUID)
 
-	// Получаем UUID информационной базы
-	c.logger.Debug("Getting infobase UUID", "infobaseName", infobaseName, "clusterUUID", clusterUUID)
-	infobaseUUID, err := c.racClient.GetInfobaseUUID(ctx, clusterUUID, infobaseName)
-	if err != nil {
-		c.logger.Error("Failed to get infobase UUID for service mode",
+	if err := c.racClient.EnableServiceMode(ctx, clusterUUID, infobaseUUID, terminateSessions); err != nil {
+		c.logger.Error("Failed to enable service mode with RAC client",
 			"error", err,
-			"infobase", infobaseName,
-			"clusterUUID", clusterUUID)
-		return fmt.Errorf("failed to get infobase UUID: %w", err)
+			"clusterUUID", clusterUUID,
+			"infobaseUUID", infobaseUUID)
+		return err
 	}
-	c.logger.Debug("Successfully obtained infobase UUID", "infobaseUUID", infobaseUUID)
-
-	// Включаем сервисный режим
-	c.logger.Debug("Enabling service mode with RAC client",
-		"clusterUUID", clusterUUID,
-		"infobaseUUID", infobaseUUID,
-		"terminateSessions", terminateSessions)
-	return c.racClient.EnableServiceMode(ctx, clusterUUID, infobaseUUID, terminateSessions)
+	return c.racClient.VerifyServiceMode(ctx, clusterUUID, infobaseUUID, true)
 }
 
-// DisableServiceMode отключает сервисный режим для указанной информационной базы.
-// Получает UUID кластера и информационной базы, затем снимает блокировку через RAC клиент.
+// DisableServiceMode отключает сервисный режим для указанной информационной базы
+// и проверяет, что блокировка снята.
 // Параметры:
 //   - ctx: контекст выполнения операции
 //   - infobaseName: имя информационной базы
@@ -337,53 +339,24 @@ func (c *Client) EnableServiceMode(ctx context.Context, infobaseName string, ter
 // Возвращает:
 //   - error: ошибка выполнения операции или nil при успехе
 func (c *Client) DisableServiceMode(ctx context.Context, infobaseName string) error {
-	if infobaseName == "" {
-		return fmt.Errorf("infobase name cannot be empty")
-	}
-
 	c.logger.Debug("Disabling service mode", "infobase", infobaseName)
 
-	// Получаем UUID кластера
-	c.logger.Debug("Getting cluster UUID for service mode disable operation")
-	clusterUUID, err := c.racClient.GetClusterUUID(ctx)
-	if err != nil {
-		c.logger.Error("Failed to get cluster UUID for service mode disable",
-			"error", err,
-			"infobase", infobaseName)
-		return fmt.Errorf("failed to get cluster UUID: %w", err)
-	}
-	c.logger.Debug("Successfully obtained cluster UUID for disable", "clusterUUID", clusterUUID)
-
-	// Получаем UUID информационной базы
-	c.logger.Debug("Getting infobase UUID for disable", "infobaseName", infobaseName, "clusterUUID", clusterUUID)
-	infobaseUUID, err := c.racClient.GetInfobaseUUID(ctx, clusterUUID, infobaseName)
+	clusterUUID, infobaseUUID, err := c.resolveInfobase(ctx, infobaseName)
 	if err != nil {
-		c.logger.Error("Failed to get infobase UUID for service mode disable",
-			"error", err,
-			"infobase", infobaseName,
-			"clusterUUID", clusterUUID)
-		return fmt.Errorf("failed to get infobase UUID: %w", err)
+		return err
 	}
-	c.logger.Debug("Successfully obtained infobase UUID for disable", "infobaseUUID", infobaseUUID)
 
-	// Отключаем сервисный режим
-	c.logger.Debug("Disabling service mode with RAC client",
-		"clusterUUID", clusterUUID,
-		"infobaseUUID", infobaseUUID)
-	err = c.racClient.DisableServiceMode(ctx, clusterUUID, infobaseUUID)
-	if err != nil {
+	if err := c.racClient.DisableServiceMode(ctx, clusterUUID, infobaseUUID); err != nil {
 		c.logger.Error("Failed to disable service mode with RAC client",
 			"error", err,
 			"clusterUUID", clusterUUID,
 			"infobaseUUID", infobaseUUID)
 		return err
 	}
-	c.logger.Debug("Service mode disabled successfully with RAC client")
-	return nil
+	return c.racClient.VerifyServiceMode(ctx, clusterUUID, infobaseUUID, false)
 }
 
 // GetServiceModeStatus получает текущий статус сервисного режима для указанной информационной базы.
-// Получает UUID кластера и информационной базы, затем запрашивает статус через RAC клиент.
 // Параметры:
 //   - ctx: контекст выполнения операции
 //   - infobaseName: имя информационной базы
@@ -392,39 +365,13 @@ func (c *Client) DisableServiceMode(ctx context.Context, infobaseName string) er
 //   - *rac.ServiceModeStatus: структура с информацией о статусе сервисного режима
 //   - error: ошибка выполнения операции или nil при успехе
 func (c *Client) GetServiceModeStatus(ctx context.Context, infobaseName string) (*rac.ServiceModeStatus, error) {
-	if infobaseName == "" {
-		return nil, fmt.Errorf("infobase name cannot be empty")
-	}
-
 	c.logger.Debug("Getting service mode status", "infobase", infobaseName)
 
-	// Получаем UUID кластера
-	c.logger.Debug("Getting cluster UUID for service mode status operation")
-	clusterUUID, err := c.racClient.GetClusterUUID(ctx)
+	clusterUUID, infobaseUUID, err := c.resolveInfobase(ctx, infobaseName)
 	if err != nil {
-		c.logger.Error("Failed to get cluster UUID for service mode status",
-			"error", err,
-			"infobase", infobaseName)
-		return nil, fmt.Errorf("failed to get cluster UUID: %w", err)
-	}
-	c.logger.Debug("Successfully obtained cluster UUID for status", "clusterUUID", clusterUUID)
-
-	// Получаем UUID информационной базы
-	c.logger.Debug("Getting infobase UUID for status", "infobaseName", infobaseName, "clusterUUID", clusterUUID)
-	infobaseUUID, err := c.racClient.GetInfobaseUUID(ctx, clusterUUID, infobaseName)
-	if err != nil {
-		c.logger.Error("Failed to get infobase UUID for service mode status",
-			"error", err,
-			"infobase", infobaseName,
-			"clusterUUID", clusterUUID)
-		return nil, fmt.Errorf("failed to get infobase UUID: %w", err)
+		return nil, err
 	}
-	c.logger.Debug("Successfully obtained infobase UUID for status", "infobaseUUID", infobaseUUID)
 
-	// Получаем статус сервисного режима
-	c.logger.Debug("Getting service mode status with RAC client",
-		"clusterUUID", clusterUUID,
-		"infobaseUUID", infobaseUUID)
 	status, err := c.racClient.GetServiceModeStatus(ctx, clusterUUID, infobaseUUID)
 	if err != nil {
 		c.logger.Error("Failed to get service mode status with RAC client",
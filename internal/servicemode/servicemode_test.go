@@ -3,13 +3,16 @@ package servicemode
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac"
+	"github.com/Kargones/apk-ci/internal/adapter/onec/rac/ractest"
 	"github.com/Kargones/apk-ci/internal/config"
-	"github.com/Kargones/apk-ci/internal/rac"
 )
 
 // MockLogger реализует интерфейс Logger для тестирования
@@ -41,51 +44,16 @@ func (m *MockLogger) Debug(msg string, args ...any) {
 	m.DebugCalls = append(m.DebugCalls, LogCall{Msg: msg, Args: args})
 }
 
-// MockRacClient представляет мок-объект для RAC клиента
-type MockRacClient struct {
-	GetClusterUUIDFunc        func(ctx context.Context) (string, error)
-	GetInfobaseUUIDFunc       func(ctx context.Context, clusterUUID, infobaseName string) (string, error)
-	EnableServiceModeFunc     func(ctx context.Context, clusterUUID, infobaseUUID string, terminateSessions bool) error
-	DisableServiceModeFunc    func(ctx context.Context, clusterUUID, infobaseUUID string) error
-	GetServiceModeStatusFunc  func(ctx context.Context, clusterUUID, infobaseUUID string) (*rac.ServiceModeStatus, error)
-}
-
-func (m *MockRacClient) GetClusterUUID(ctx context.Context) (string, error) {
-	if m.GetClusterUUIDFunc != nil {
-		return m.GetClusterUUIDFunc(ctx)
-	}
-	return "test-cluster-uuid", nil //nolint:goconst // test value
-}
-
-func (m *MockRacClient) GetInfobaseUUID(ctx context.Context, clusterUUID, infobaseName string) (string, error) {
-	if m.GetInfobaseUUIDFunc != nil {
-		return m.GetInfobaseUUIDFunc(ctx, clusterUUID, infobaseName)
-	}
-	return "test-infobase-uuid", nil //nolint:goconst // test value
-}
-
-func (m *MockRacClient) EnableServiceMode(ctx context.Context, clusterUUID, infobaseUUID string, terminateSessions bool) error {
-	if m.EnableServiceModeFunc != nil {
-		return m.EnableServiceModeFunc(ctx, clusterUUID, infobaseUUID, terminateSessions)
+// fakeRacPath создаёт исполняемый файл-заглушку rac (NewClient проверяет существование пути).
+func fakeRacPath(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rac")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\nexit 0\n"), 0755); err != nil { //nolint:gosec // тестовый скрипт
+		t.Fatalf("Failed to create fake rac: %v", err)
 	}
-	return nil
+	return path
 }
 
-func (m *MockRacClient) DisableServiceMode(ctx context.Context, clusterUUID, infobaseUUID string) error {
-	if m.DisableServiceModeFunc != nil {
-		return m.DisableServiceModeFunc(ctx, clusterUUID, infobaseUUID)
-	}
-	return nil
-}
-
-func (m *MockRacClient) GetServiceModeStatus(ctx context.Context, clusterUUID, infobaseUUID string) (*rac.ServiceModeStatus, error) {
-	if m.GetServiceModeStatusFunc != nil {
-		return m.GetServiceModeStatusFunc(ctx, clusterUUID, infobaseUUID)
-	}
-	return &rac.ServiceModeStatus{Enabled: false}, nil
-}
-
-// TestSlogLogger тестирует адаптер SlogLogger
 func TestSlogLogger(t *testing.T) {
 	// Создаем временный файл для логов
 	tmpFile, err := os.CreateTemp("", "test_log_*.json")
@@ -133,7 +101,7 @@ func TestSlogLogger(t *testing.T) {
 // TestNewClient тестирует создание нового клиента
 func TestNewClient(t *testing.T) {
 	config := RacConfig{
-		RacPath:     "/usr/bin/rac",
+		RacPath:     fakeRacPath(t),
 		RacServer:   "localhost",
 		RacPort:     1545,
 		RacUser:     "admin",
@@ -145,7 +113,10 @@ func TestNewClient(t *testing.T) {
 	}
 
 	logger := &MockLogger{}
-	client := NewClient(config, logger)
+	client, err := NewClient(config, logger)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
 
 	if client == nil {
 		t.Fatal("Expected non-nil client")
@@ -213,7 +184,7 @@ func TestManagerInterface(t *testing.T) {
 
 	// Создаем клиент для проверки интерфейса
 	config := RacConfig{
-		RacPath:     "/usr/bin/echo",
+		RacPath:     fakeRacPath(t),
 		RacServer:   "localhost",
 		RacPort:     1545,
 		RacUser:     "admin",
@@ -225,17 +196,16 @@ func TestManagerInterface(t *testing.T) {
 	}
 
 	logger := &MockLogger{}
-	client := NewClient(config, logger)
-
-	if client == nil {
-		t.Error("Expected non-nil client")
+	client, err := NewClient(config, logger)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
 	}
 
 	// Проверяем, что клиент реализует все методы интерфейса Manager
 	ctx := context.Background()
-	
+
 	// Тестируем валидацию входных параметров без реальных подключений
-	err := client.EnableServiceMode(ctx, "", false)
+	err = client.EnableServiceMode(ctx, "", false)
 	if err == nil {
 		t.Error("Expected error for empty infobase name")
 	}
@@ -500,7 +470,7 @@ func TestClient_EnableServiceMode(t *testing.T) {
 	logger := &MockLogger{}
 
 	// Создаем мок RAC клиент
-	mockRacClient := &MockRacClient{}
+	mockRacClient := &ractest.MockRACClient{}
 
 	// Создаем конфигурацию для тестов
 	config := &RacConfig{
@@ -532,15 +502,15 @@ func TestClient_EnableServiceMode(t *testing.T) {
 // TestClient_DisableServiceMode тестирует отключение сервисного режима
 func TestClient_DisableServiceMode(t *testing.T) {
 	// Создаем мок RAC клиент
-	mockRacClient := &MockRacClient{}
-	
+	mockRacClient := &ractest.MockRACClient{}
+
 	// Создаем конфигурацию для тестов
 	config := &RacConfig{
 		RacPath:   "/test/rac",
 		RacServer: "localhost",
 		RacPort:   1545,
 	}
-	
+
 	// Создаем клиент с мок RAC клиентом
 	client := &Client{
 		config:    config,
@@ -552,10 +522,10 @@ func TestClient_DisableServiceMode(t *testing.T) {
 
 	t.Run("successful disable", func(t *testing.T) {
 		// Настраиваем мок-клиент для возврата ошибки подключения
-		mockRacClient.GetClusterUUIDFunc = func(ctx context.Context) (string, error) {
-			return "", errors.New("connection failed")
+		mockRacClient.GetClusterInfoFunc = func(ctx context.Context) (*rac.ClusterInfo, error) {
+			return nil, errors.New("connection failed")
 		}
-		
+
 		err := client.DisableServiceMode(ctx, "testdb")
 		if err == nil {
 			t.Error("Expected error due to RAC connection failure")
@@ -573,15 +543,15 @@ func TestClient_DisableServiceMode(t *testing.T) {
 // TestClient_GetServiceModeStatus тестирует получение статуса сервисного режима
 func TestClient_GetServiceModeStatus(t *testing.T) {
 	// Создаем мок RAC клиент
-	mockRacClient := &MockRacClient{}
-	
+	mockRacClient := &ractest.MockRACClient{}
+
 	// Создаем конфигурацию для тестов
 	config := &RacConfig{
 		RacPath:   "/test/rac",
 		RacServer: "localhost",
 		RacPort:   1545,
 	}
-	
+
 	// Создаем клиент с мок RAC клиентом
 	client := &Client{
 		config:    config,
@@ -596,7 +566,7 @@ func TestClient_GetServiceModeStatus(t *testing.T) {
 		mockRacClient.GetServiceModeStatusFunc = func(ctx context.Context, clusterUUID, infobaseUUID string) (*rac.ServiceModeStatus, error) {
 			return &rac.ServiceModeStatus{Enabled: true}, nil
 		}
-		
+
 		status, err := client.GetServiceModeStatus(ctx, "testdb")
 		if err != nil {
 			t.Errorf("Unexpected error: %v", err)
@@ -623,7 +593,7 @@ func TestNewClient_DetailedValidation(t *testing.T) {
 
 	t.Run("valid config", func(t *testing.T) {
 		config := RacConfig{
-			RacPath:     "/usr/bin/rac",
+			RacPath:     fakeRacPath(t),
 			RacServer:   "localhost",
 			RacPort:     1545,
 			RacUser:     "admin",
@@ -634,16 +604,15 @@ func TestNewClient_DetailedValidation(t *testing.T) {
 			RacRetries:  3,
 		}
 
-		client := NewClient(config, logger)
-		
-		if client == nil {
-			t.Fatal("Expected non-nil client")
+		client, err := NewClient(config, logger)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
 		}
-		
+
 		if client.logger != logger {
 			t.Error("Expected logger to be set correctly")
 		}
-		
+
 		if client.racClient == nil {
 			t.Error("Expected racClient to be initialized")
 		}
@@ -651,40 +620,51 @@ func TestNewClient_DetailedValidation(t *testing.T) {
 
 	t.Run("nil logger", func(t *testing.T) {
 		config := RacConfig{
-			RacPath:   "/usr/bin/rac",
+			RacPath:   fakeRacPath(t),
 			RacServer: "localhost",
 			RacPort:   1545,
 		}
 
-		client := NewClient(config, nil)
-		
-		if client == nil {
-			t.Fatal("Expected non-nil client even with nil logger")
+		client, err := NewClient(config, nil)
+		if err != nil || client == nil {
+			t.Fatalf("Expected non-nil client even with nil logger, got error: %v", err)
 		}
 	})
 
 	t.Run("empty config", func(t *testing.T) {
 		config := RacConfig{}
 
-		client := NewClient(config, logger)
+		client, err := NewClient(config, logger)
+		if err == nil {
+			t.Error("Expected error for empty RAC path")
+		}
+		if client != nil {
+			t.Error("Expected nil client for invalid config")
+		}
+	})
+
+	t.Run("missing rac binary", func(t *testing.T) {
+		config := RacConfig{
+			RacPath:   filepath.Join(t.TempDir(), "rac"),
+			RacServer: "localhost",
+		}
 
-		if client == nil {
-			t.Fatal("Expected non-nil client even with empty config")
+		if _, err := NewClient(config, logger); err == nil {
+			t.Error("Expected error for missing rac binary")
 		}
 	})
 
 	t.Run("slog logger", func(t *testing.T) {
 		config := RacConfig{
-			RacPath:   "/usr/bin/rac",
+			RacPath:   fakeRacPath(t),
 			RacServer: "localhost",
 			RacPort:   1545,
 		}
 
 		slogLogger := &SlogLogger{Logger: slog.Default()}
-		client := NewClient(config, slogLogger)
-
-		if client == nil {
-			t.Fatal("Expected non-nil client with SlogLogger")
+		client, err := NewClient(config, slogLogger)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
 		}
 
 		if client.logger != slogLogger {
@@ -707,7 +687,7 @@ func TestNewClientWithRacClient(t *testing.T) {
 		RacRetries:  3,
 	}
 
-	mockRacClient := &MockRacClient{}
+	mockRacClient := &ractest.MockRACClient{}
 	mockLogger := &MockLogger{}
 
 	client := NewClientWithRacClient(config, mockRacClient, mockLogger)
@@ -716,7 +696,7 @@ func TestNewClientWithRacClient(t *testing.T) {
 		t.Fatal("Expected non-nil client")
 	}
 
-	if client.config.RacPath != config.RacPath || 
+	if client.config.RacPath != config.RacPath ||
 		client.config.RacServer != config.RacServer ||
 		client.config.RacPort != config.RacPort {
 		t.Error("Expected config to be set correctly")
@@ -746,16 +726,19 @@ func TestClient_MethodsWithMockedRacClient(t *testing.T) {
 	logger := &MockLogger{}
 
 	t.Run("successful enable service mode", func(t *testing.T) {
-		mockRacClient := &MockRacClient{
-			GetClusterUUIDFunc: func(ctx context.Context) (string, error) {
-				return "test-cluster-uuid", nil
+		mockRacClient := &ractest.MockRACClient{
+			GetClusterInfoFunc: func(ctx context.Context) (*rac.ClusterInfo, error) {
+				return &rac.ClusterInfo{UUID: "test-cluster-uuid"}, nil
 			},
-			GetInfobaseUUIDFunc: func(ctx context.Context, clusterUUID, infobaseName string) (string, error) {
-				return "test-infobase-uuid", nil
+			GetInfobaseInfoFunc: func(ctx context.Context, clusterUUID, infobaseName string) (*rac.InfobaseInfo, error) {
+				return &rac.InfobaseInfo{UUID: "test-infobase-uuid", Name: infobaseName}, nil
 			},
 			EnableServiceModeFunc: func(ctx context.Context, clusterUUID, infobaseUUID string, terminateSessions bool) error {
 				return nil
 			},
+			VerifyServiceModeFunc: func(ctx context.Context, clusterUUID, infobaseUUID string, expectedEnabled bool) error {
+				return nil
+			},
 		}
 
 		client := &Client{
@@ -770,12 +753,12 @@ func TestClient_MethodsWithMockedRacClient(t *testing.T) {
 	})
 
 	t.Run("successful disable service mode", func(t *testing.T) {
-		mockRacClient := &MockRacClient{
-			GetClusterUUIDFunc: func(ctx context.Context) (string, error) {
-				return "test-cluster-uuid", nil
+		mockRacClient := &ractest.MockRACClient{
+			GetClusterInfoFunc: func(ctx context.Context) (*rac.ClusterInfo, error) {
+				return &rac.ClusterInfo{UUID: "test-cluster-uuid"}, nil
 			},
-			GetInfobaseUUIDFunc: func(ctx context.Context, clusterUUID, infobaseName string) (string, error) {
-				return "test-infobase-uuid", nil
+			GetInfobaseInfoFunc: func(ctx context.Context, clusterUUID, infobaseName string) (*rac.InfobaseInfo, error) {
+				return &rac.InfobaseInfo{UUID: "test-infobase-uuid", Name: infobaseName}, nil
 			},
 			DisableServiceModeFunc: func(ctx context.Context, clusterUUID, infobaseUUID string) error {
 				return nil
@@ -794,12 +777,12 @@ func TestClient_MethodsWithMockedRacClient(t *testing.T) {
 	})
 
 	t.Run("successful get service mode status", func(t *testing.T) {
-		mockRacClient := &MockRacClient{
-			GetClusterUUIDFunc: func(ctx context.Context) (string, error) {
-				return "test-cluster-uuid", nil
+		mockRacClient := &ractest.MockRACClient{
+			GetClusterInfoFunc: func(ctx context.Context) (*rac.ClusterInfo, error) {
+				return &rac.ClusterInfo{UUID: "test-cluster-uuid"}, nil
 			},
-			GetInfobaseUUIDFunc: func(ctx context.Context, clusterUUID, infobaseName string) (string, error) {
-				return "test-infobase-uuid", nil
+			GetInfobaseInfoFunc: func(ctx context.Context, clusterUUID, infobaseName string) (*rac.InfobaseInfo, error) {
+				return &rac.InfobaseInfo{UUID: "test-infobase-uuid", Name: infobaseName}, nil
 			},
 			GetServiceModeStatusFunc: func(ctx context.Context, clusterUUID, infobaseUUID string) (*rac.ServiceModeStatus, error) {
 				return &rac.ServiceModeStatus{Enabled: true}, nil
@@ -824,9 +807,9 @@ func TestClient_MethodsWithMockedRacClient(t *testing.T) {
 	})
 
 	t.Run("error getting cluster UUID", func(t *testing.T) {
-		mockRacClient := &MockRacClient{
-			GetClusterUUIDFunc: func(ctx context.Context) (string, error) {
-				return "", errors.New("cluster not found")
+		mockRacClient := &ractest.MockRACClient{
+			GetClusterInfoFunc: func(ctx context.Context) (*rac.ClusterInfo, error) {
+				return nil, errors.New("cluster not found")
 			},
 		}
 
@@ -842,12 +825,12 @@ func TestClient_MethodsWithMockedRacClient(t *testing.T) {
 	})
 
 	t.Run("error getting infobase UUID", func(t *testing.T) {
-		mockRacClient := &MockRacClient{
-			GetClusterUUIDFunc: func(ctx context.Context) (string, error) {
-				return "test-cluster-uuid", nil
+		mockRacClient := &ractest.MockRACClient{
+			GetClusterInfoFunc: func(ctx context.Context) (*rac.ClusterInfo, error) {
+				return &rac.ClusterInfo{UUID: "test-cluster-uuid"}, nil
 			},
-			GetInfobaseUUIDFunc: func(ctx context.Context, clusterUUID, infobaseName string) (string, error) {
-				return "", errors.New("infobase not found")
+			GetInfobaseInfoFunc: func(ctx context.Context, clusterUUID, infobaseName string) (*rac.InfobaseInfo, error) {
+				return nil, errors.New("infobase not found")
 			},
 		}
 
@@ -863,12 +846,12 @@ func TestClient_MethodsWithMockedRacClient(t *testing.T) {
 	})
 
 	t.Run("error enabling service mode", func(t *testing.T) {
-		mockRacClient := &MockRacClient{
-			GetClusterUUIDFunc: func(ctx context.Context) (string, error) {
-				return "test-cluster-uuid", nil
+		mockRacClient := &ractest.MockRACClient{
+			GetClusterInfoFunc: func(ctx context.Context) (*rac.ClusterInfo, error) {
+				return &rac.ClusterInfo{UUID: "test-cluster-uuid"}, nil
 			},
-			GetInfobaseUUIDFunc: func(ctx context.Context, clusterUUID, infobaseName string) (string, error) {
-				return "test-infobase-uuid", nil
+			GetInfobaseInfoFunc: func(ctx context.Context, clusterUUID, infobaseName string) (*rac.InfobaseInfo, error) {
+				return &rac.InfobaseInfo{UUID: "test-infobase-uuid", Name: infobaseName}, nil
 			},
 			EnableServiceModeFunc: func(ctx context.Context, clusterUUID, infobaseUUID string, terminateSessions bool) error {
 				return errors.New("enable service mode error")
@@ -887,12 +870,12 @@ func TestClient_MethodsWithMockedRacClient(t *testing.T) {
 	})
 
 	t.Run("error disabling service mode", func(t *testing.T) {
-		mockRacClient := &MockRacClient{
-			GetClusterUUIDFunc: func(ctx context.Context) (string, error) {
-				return "test-cluster-uuid", nil
+		mockRacClient := &ractest.MockRACClient{
+			GetClusterInfoFunc: func(ctx context.Context) (*rac.ClusterInfo, error) {
+				return &rac.ClusterInfo{UUID: "test-cluster-uuid"}, nil
 			},
-			GetInfobaseUUIDFunc: func(ctx context.Context, clusterUUID, infobaseName string) (string, error) {
-				return "test-infobase-uuid", nil
+			GetInfobaseInfoFunc: func(ctx context.Context, clusterUUID, infobaseName string) (*rac.InfobaseInfo, error) {
+				return &rac.InfobaseInfo{UUID: "test-infobase-uuid", Name: infobaseName}, nil
 			},
 			DisableServiceModeFunc: func(ctx context.Context, clusterUUID, infobaseUUID string) error {
 				return errors.New("disable service mode error")
@@ -911,12 +894,12 @@ func TestClient_MethodsWithMockedRacClient(t *testing.T) {
 	})
 
 	t.Run("error getting service mode status", func(t *testing.T) {
-		mockRacClient := &MockRacClient{
-			GetClusterUUIDFunc: func(ctx context.Context) (string, error) {
-				return "test-cluster-uuid", nil
+		mockRacClient := &ractest.MockRACClient{
+			GetClusterInfoFunc: func(ctx context.Context) (*rac.ClusterInfo, error) {
+				return &rac.ClusterInfo{UUID: "test-cluster-uuid"}, nil
 			},
-			GetInfobaseUUIDFunc: func(ctx context.Context, clusterUUID, infobaseName string) (string, error) {
-				return "test-infobase-uuid", nil
+			GetInfobaseInfoFunc: func(ctx context.Context, clusterUUID, infobaseName string) (*rac.InfobaseInfo, error) {
+				return &rac.InfobaseInfo{UUID: "test-infobase-uuid", Name: infobaseName}, nil
 			},
 			GetServiceModeStatusFunc: func(ctx context.Context, clusterUUID, infobaseUUID string) (*rac.ServiceModeStatus, error) {
 				return nil, errors.New("get status error")
@@ -933,4 +916,54 @@ func TestClient_MethodsWithMockedRacClient(t *testing.T) {
 			t.Error("Expected error for get service mode status failure")
 		}
 	})
-}
\ No newline at end of file
+}
+
+// TestClient_UsesAdapterClient проверяет, что операции выполняются через rac.Client адаптера:
+// UUID разрешаются по имени базы, после изменения состояние проверяется VerifyServiceMode.
+func TestClient_UsesAdapterClient(t *testing.T) {
+	ctx := context.Background()
+
+	var calls []string
+	mockRacClient := &ractest.MockRACClient{
+		GetClusterInfoFunc: func(ctx context.Context) (*rac.ClusterInfo, error) {
+			return &rac.ClusterInfo{UUID: "cluster-1"}, nil
+		},
+		GetInfobaseInfoFunc: func(ctx context.Context, clusterUUID, infobaseName string) (*rac.InfobaseInfo, error) {
+			return &rac.InfobaseInfo{UUID: clusterUUID + "/" + infobaseName, Name: infobaseName}, nil
+		},
+		EnableServiceModeFunc: func(ctx context.Context, clusterUUID, infobaseUUID string, terminateSessions bool) error {
+			calls = append(calls, fmt.Sprintf("enable %s terminate=%v", infobaseUUID, terminateSessions))
+			return nil
+		},
+		DisableServiceModeFunc: func(ctx context.Context, clusterUUID, infobaseUUID string) error {
+			calls = append(calls, "disable "+infobaseUUID)
+			return nil
+		},
+		VerifyServiceModeFunc: func(ctx context.Context, clusterUUID, infobaseUUID string, expectedEnabled bool) error {
+			calls = append(calls, fmt.Sprintf("verify %s %v", infobaseUUID, expectedEnabled))
+			if !expectedEnabled {
+				return errors.New("service mode still enabled")
+			}
+			return nil
+		},
+	}
+	client := NewClientWithRacClient(RacConfig{}, mockRacClient, &MockLogger{})
+
+	if err := client.EnableServiceMode(ctx, "erp", true); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	err := client.DisableServiceMode(ctx, "erp")
+	if err == nil || err.Error() != "service mode still enabled" {
+		t.Errorf("Expected verify error, got: %v", err)
+	}
+
+	want := []string{
+		"enable cluster-1/erp terminate=true",
+		"verify cluster-1/erp true",
+		"disable cluster-1/erp",
+		"verify cluster-1/erp false",
+	}
+	if fmt.Sprint(calls) != fmt.Sprint(want) {
+		t.Errorf("Expected calls %v, got %v", want, calls)
+	}
+}
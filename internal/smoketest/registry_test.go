@@ -28,7 +28,18 @@ var allNRCommands = []struct {
 	{constants.ActNRServiceModeStatus, "nr-service-mode-status"},
 	{constants.ActNRServiceModeEnable, "nr-service-mode-enable"},
 	{constants.ActNRServiceModeDisable, "nr-service-mode-disable"},
+	{constants.ActNRServiceModeSchedule, "nr-service-mode-schedule"},
 	{constants.ActNRForceDisconnectSessions, "nr-force-disconnect-sessions"},
+	{constants.ActNRClusterInventory, "nr-cluster-inventory"},
+	{constants.ActNRSessionsReport, "nr-sessions-report"},
+	{constants.ActNRScheduledJobs, "nr-scheduled-jobs"},
+	{constants.ActNRServiceModeReaper, "nr-service-mode-reaper"},
+	{constants.ActNRInfobaseCreate, "nr-infobase-create"},
+	{constants.ActNRInfobaseUpdate, "nr-infobase-update"},
+	{constants.ActNRInfobaseDrop, "nr-infobase-drop"},
+	{constants.ActNRClusterHealth, "nr-cluster-health"},
+	{constants.ActNRDbSnapshot, "nr-db-snapshot"},
+	{constants.ActNRDbrestoreHistory, "nr-dbrestore-history"},
 	{constants.ActNRDbrestore, "nr-dbrestore"},
 	{constants.ActNRDbupdate, "nr-dbupdate"},
 	{constants.ActNRCreateTempDb, "nr-create-temp-db"},
@@ -48,6 +59,9 @@ var allNRCommands = []struct {
 	{constants.ActNRDeprecatedAudit, "nr-deprecated-audit"},
 	{constants.ActNRExtensionPublish, "nr-extension-publish"},
 	{constants.ActNRConvertPipeline, "nr-convert-pipeline"},
+	{constants.ActNRStoreRestoreBackup, "nr-store-restore-backup"},
+	{constants.ActNRStoreHistory, "nr-store-history"},
+	{constants.ActNRStore2git, "nr-store2git"},
 	{constants.ActHelp, "help"},
 }
 
@@ -184,6 +198,20 @@ var noLegacyCommands = map[string]bool{
 	constants.ActNRDeprecatedAudit:         true,
 	constants.ActHelp:                      true,
 	constants.ActNRConvertPipeline:         true,
+	constants.ActNRStoreRestoreBackup:      true,
+	constants.ActNRStoreHistory:            true,
+	constants.ActNRStore2git:               true,
+	constants.ActNRServiceModeSchedule:     true,
+	constants.ActNRClusterInventory:        true,
+	constants.ActNRSessionsReport:          true,
+	constants.ActNRScheduledJobs:           true,
+	constants.ActNRServiceModeReaper:       true,
+	constants.ActNRInfobaseCreate:          true,
+	constants.ActNRInfobaseUpdate:          true,
+	constants.ActNRInfobaseDrop:            true,
+	constants.ActNRClusterHealth:           true,
+	constants.ActNRDbSnapshot:              true,
+	constants.ActNRDbrestoreHistory:        true,
 }
 
 // TestRegistry_AllNRCommandsHaveDeprecatedAlias проверяет что все NR-команды
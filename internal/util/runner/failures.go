@@ -0,0 +1,235 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// FailureKind — класс сбоя процесса платформы 1С (1cv8, ibcmd, rac).
+type FailureKind string
+
+// Классы сбоев. Значения используются в кодах ошибок PROCESS.<KIND>.
+const (
+	// FailureObjectCaptured — объект хранилища захвачен другим пользователем.
+	FailureObjectCaptured FailureKind = "OBJECT_CAPTURED"
+	// FailureLockConflict — конфликт блокировок данных или таймаут ожидания блокировки.
+	FailureLockConflict FailureKind = "LOCK_CONFLICT"
+	// FailureInfobaseLocked — информационная база заблокирована (монопольный режим, запрет сеансов).
+	FailureInfobaseLocked FailureKind = "INFOBASE_LOCKED"
+	// FailureLicense — не удалось получить лицензию.
+	FailureLicense FailureKind = "LICENSE_UNAVAILABLE"
+	// FailureWrongPassword — ошибка аутентификации пользователя базы или хранилища.
+	FailureWrongPassword FailureKind = "WRONG_PASSWORD"
+	// FailureTimeout — процесс остановлен по таймауту.
+	FailureTimeout FailureKind = "TIMEOUT"
+	// FailureCancelled — процесс остановлен отменой контекста.
+	FailureCancelled FailureKind = "CANCELLED"
+	// FailureExitCode — ненулевой код завершения (или /DumpResult) без известного сообщения.
+	FailureExitCode FailureKind = "EXIT_CODE"
+)
+
+// Sentinel-ошибки для errors.Is: ProcessError соответствует ошибке своего класса.
+var (
+	ErrObjectCaptured = errors.New("объект захвачен другим пользователем")
+	ErrLockConflict   = errors.New("конфликт блокировок")
+	ErrInfobaseLocked = errors.New("информационная база заблокирована")
+	ErrLicense        = errors.New("лицензия недоступна")
+	ErrWrongPassword  = errors.New("неверное имя пользователя или пароль")
+	ErrTimeout        = errors.New("превышено время выполнения процесса")
+	ErrCancelled      = errors.New("процесс остановлен отменой операции")
+	ErrExitCode       = errors.New("процесс завершился с ошибкой")
+)
+
+// sentinels сопоставляет классы сбоев sentinel-ошибкам.
+var sentinels = map[FailureKind]error{
+	FailureObjectCaptured: ErrObjectCaptured,
+	FailureLockConflict:   ErrLockConflict,
+	FailureInfobaseLocked: ErrInfobaseLocked,
+	FailureLicense:        ErrLicense,
+	FailureWrongPassword:  ErrWrongPassword,
+	FailureTimeout:        ErrTimeout,
+	FailureCancelled:      ErrCancelled,
+	FailureExitCode:       ErrExitCode,
+}
+
+// failurePatterns — известные сообщения платформы (RU и EN) в нижнем регистре.
+// Порядок важен: более специфичные классы проверяются первыми
+// (захват объекта хранилища — частный случай сообщения о блокировке).
+var failurePatterns = []struct {
+	kind     FailureKind
+	patterns []string
+}{
+	{FailureObjectCaptured, []string{
+		"захвачен другим пользователем",
+		"захвачены другим пользователем",
+		"объект уже захвачен",
+		"объекты уже захвачены",
+		"locked by another user",
+		"captured by another user",
+		"object is already locked",
+	}},
+	{FailureWrongPassword, []string{
+		"идентификация пользователя не выполнена",
+		"неправильное имя или пароль",
+		"неверный пароль",
+		"неправильный пароль",
+		"ошибка аутентификации",
+		"user authentication failed",
+		"invalid user name or password",
+		"incorrect user name or password",
+		"wrong password",
+		"incorrect password",
+	}},
+	{FailureLicense, []string{
+		"не обнаружена лицензия",
+		"не найдена лицензия",
+		"лицензия не обнаружена",
+		"лицензия не найдена",
+		"нет свободной лицензии",
+		"license not found",
+		"no license found",
+		"no free license",
+		"license is not available",
+	}},
+	{FailureInfobaseLocked, []string{
+		"установки монопольного режима",
+		"установить монопольный режим",
+		"информационная база заблокирована",
+		"начало сеанса с информационной базой запрещено",
+		"база данных заблокирована",
+		"set exclusive mode",
+		"setting exclusive mode",
+		"infobase is locked",
+		"session start is prohibited",
+		"database is locked",
+	}},
+	{FailureLockConflict, []string{
+		"конфликт блокировок",
+		"ошибка блокировки",
+		"превышено максимальное время ожидания предоставления блокировки",
+		"lock conflict",
+		"lock request timeout",
+		"lock timeout",
+		"deadlock",
+	}},
+}
+
+// Classify определяет класс сбоя по выводу процесса (/Out, консоль).
+// Возвращает класс и строку вывода, в которой найдено сообщение;
+// пустой класс — известных сообщений об ошибке нет.
+func Classify(out []byte) (FailureKind, string) {
+	out = bytes.TrimPrefix(out, []byte("\uFEFF"))
+	lines := strings.Split(string(out), "\n")
+	for _, fp := range failurePatterns {
+		for _, line := range lines {
+			lower := strings.ToLower(line)
+			for _, p := range fp.patterns {
+				if strings.Contains(lower, p) {
+					return fp.kind, strings.TrimSpace(line)
+				}
+			}
+		}
+	}
+	return "", ""
+}
+
+// ProcessError — типизированная ошибка процесса платформы 1С.
+// Поддерживает errors.Is с sentinel-ошибками класса (ErrObjectCaptured и т.д.)
+// и реализует apperrors.Coded.
+type ProcessError struct {
+	// Kind — класс сбоя
+	Kind FailureKind
+	// Tool — имя исполняемого файла (1cv8, ibcmd, rac)
+	Tool string
+	// ExitCode — код завершения процесса (-1 если процесс не завершился сам)
+	ExitCode int
+	// Detail — строка вывода с сообщением об ошибке
+	Detail string
+	// Cause — исходная ошибка запуска (если есть)
+	Cause error
+}
+
+// Error реализует интерфейс error.
+func (e *ProcessError) Error() string {
+	msg := fmt.Sprintf("[%s] %s: %s", e.ErrorCode(), e.Tool, sentinels[e.Kind])
+	if e.ExitCode > 0 {
+		msg += fmt.Sprintf(" (код %d)", e.ExitCode)
+	}
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	return msg
+}
+
+// ErrorCode возвращает машиночитаемый код ошибки PROCESS.<KIND>.
+func (e *ProcessError) ErrorCode() string {
+	return "PROCESS." + string(e.Kind)
+}
+
+// Unwrap возвращает исходную ошибку запуска.
+func (e *ProcessError) Unwrap() error {
+	return e.Cause
+}
+
+// Is сопоставляет ошибку sentinel-ошибке её класса.
+func (e *ProcessError) Is(target error) bool {
+	return sentinels[e.Kind] == target
+}
+
+// Interpret разбирает результат процесса платформы: отмену контекста,
+// код завершения и /DumpResult (dumpResult < 0 — не запрашивался).
+// Возвращает *ProcessError или nil.
+//
+// Успех определяется только кодом завершения и /DumpResult, поэтому не зависит
+// от языка интерфейса платформы. Вывод процесса (/Out, консоль) используется
+// лишь для определения класса уже обнаруженного сбоя.
+func Interpret(ctx context.Context, tool string, runErr error, outFile, console []byte, dumpResult int) error {
+	tool = toolName(tool)
+	if ctx != nil && runErr != nil {
+		switch {
+		case errors.Is(ctx.Err(), context.DeadlineExceeded):
+			return &ProcessError{Kind: FailureTimeout, Tool: tool, ExitCode: -1, Cause: runErr}
+		case errors.Is(ctx.Err(), context.Canceled):
+			return &ProcessError{Kind: FailureCancelled, Tool: tool, ExitCode: -1, Cause: runErr}
+		}
+	}
+
+	var exitCode int
+	switch {
+	case runErr != nil:
+		exitCode = -1
+		var exitErr *exec.ExitError
+		if errors.As(runErr, &exitErr) {
+			exitCode = exitErr.ExitCode()
+		}
+	case dumpResult > 0:
+		exitCode = dumpResult
+	default:
+		return nil
+	}
+
+	out := append(append([]byte{}, outFile...), console...)
+	kind, detail := Classify(out)
+	if kind == "" {
+		kind = FailureExitCode
+		detail = lastLine(out)
+	}
+	return &ProcessError{Kind: kind, Tool: tool, ExitCode: exitCode, Detail: detail, Cause: runErr}
+}
+
+// toolName возвращает короткое имя исполняемого файла.
+func toolName(bin string) string {
+	name := filepath.Base(strings.ReplaceAll(bin, "\\", "/"))
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// lastLine возвращает последнюю непустую строку вывода.
+func lastLine(out []byte) string {
+	lines := strings.Split(strings.TrimSpace(string(bytes.TrimPrefix(out, []byte("\uFEFF")))), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
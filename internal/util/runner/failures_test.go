@@ -0,0 +1,149 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		out  string
+		want FailureKind
+	}{
+		{"empty", "", ""},
+		{"success ru", "Обновление конфигурации успешно завершено", ""},
+		{"object captured ru", "Ошибка захвата объектов\nОбъект Справочник.Номенклатура захвачен другим пользователем", FailureObjectCaptured},
+		{"object captured en", "Object Catalog.Items is locked by another user", FailureObjectCaptured},
+		{"wrong password ru", "\uFEFFИдентификация пользователя не выполнена", FailureWrongPassword},
+		{"wrong password en", "Invalid user name or password", FailureWrongPassword},
+		{"license ru", "Не обнаружена лицензия для использования программы", FailureLicense},
+		{"license en", "License not found", FailureLicense},
+		{"infobase locked ru", "Ошибка установки монопольного режима", FailureInfobaseLocked},
+		{"infobase locked en", "Session start is prohibited", FailureInfobaseLocked},
+		{"lock conflict ru", "Конфликт блокировок при выполнении транзакции", FailureLockConflict},
+		{"lock conflict en", "Lock request timeout", FailureLockConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, detail := Classify([]byte(tt.out))
+			if got != tt.want {
+				t.Errorf("Classify() = %q, want %q", got, tt.want)
+			}
+			if tt.want != "" && detail == "" {
+				t.Error("expected matching line in detail")
+			}
+		})
+	}
+}
+
+func TestInterpret(t *testing.T) {
+	exitErr := func(t *testing.T) error {
+		t.Helper()
+		err := exec.Command("sh", "-c", "exit 3").Run()
+		if err == nil {
+			t.Skip("sh is not available")
+		}
+		return err
+	}
+
+	t.Run("success", func(t *testing.T) {
+		if err := Interpret(context.Background(), "1cv8", nil, []byte("ok"), nil, -1); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("dump result zero overrides messages", func(t *testing.T) {
+		out := []byte("Конфликт блокировок, повтор выполнен")
+		if err := Interpret(context.Background(), "1cv8", nil, out, nil, 0); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("dump result failure", func(t *testing.T) {
+		err := Interpret(context.Background(), "/opt/1cv8/1cv8", nil, []byte("Что-то пошло не так"), nil, 1)
+		var pe *ProcessError
+		if !errors.As(err, &pe) {
+			t.Fatalf("err = %v, want *ProcessError", err)
+		}
+		if pe.Kind != FailureExitCode || pe.ExitCode != 1 || pe.Tool != "1cv8" {
+			t.Errorf("pe = %+v", pe)
+		}
+		if pe.Detail != "Что-то пошло не так" {
+			t.Errorf("Detail = %q", pe.Detail)
+		}
+	})
+
+	t.Run("exit code classified from console", func(t *testing.T) {
+		err := Interpret(context.Background(), "ibcmd.exe", exitErr(t), nil, []byte("License not found"), -1)
+		if !errors.Is(err, ErrLicense) {
+			t.Fatalf("err = %v, want ErrLicense", err)
+		}
+		var pe *ProcessError
+		errors.As(err, &pe)
+		if pe.ExitCode != 3 || pe.Tool != "ibcmd" {
+			t.Errorf("pe = %+v", pe)
+		}
+		if pe.ErrorCode() != "PROCESS.LICENSE_UNAVAILABLE" {
+			t.Errorf("ErrorCode() = %q", pe.ErrorCode())
+		}
+		if !strings.Contains(err.Error(), "(код 3)") {
+			t.Errorf("Error() = %q", err.Error())
+		}
+	})
+
+	t.Run("out file ignored on zero exit", func(t *testing.T) {
+		out := []byte("Объект Справочник.Номенклатура захвачен другим пользователем")
+		if err := Interpret(context.Background(), "1cv8", nil, out, nil, -1); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("console ignored on zero exit", func(t *testing.T) {
+		if err := Interpret(context.Background(), "rac", nil, nil, []byte("infobase is locked"), -1); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 0)
+		defer cancel()
+		<-ctx.Done()
+		err := Interpret(ctx, "1cv8", errors.New("signal: killed"), nil, nil, -1)
+		if !errors.Is(err, ErrTimeout) {
+			t.Errorf("err = %v, want ErrTimeout", err)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := Interpret(ctx, "1cv8", errors.New("signal: killed"), nil, nil, -1)
+		if !errors.Is(err, ErrCancelled) {
+			t.Errorf("err = %v, want ErrCancelled", err)
+		}
+		if errors.Is(err, ErrTimeout) {
+			t.Error("cancelled error must not match ErrTimeout")
+		}
+	})
+}
+
+func TestParseDumpResult(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"0", 0},
+		{"\uFEFF1\r\n", 1},
+		{"", -1},
+		{"abc", -1},
+	}
+	for _, tt := range tests {
+		if got := parseDumpResult([]byte(tt.in)); got != tt.want {
+			t.Errorf("parseDumpResult(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package runner
+
+import "os/exec"
+
+// configureProcess ограничивает ожидание вывода после остановки процесса.
+// Завершение группы процессов поддерживается только на unix.
+func configureProcess(cmd *exec.Cmd) {
+	cmd.WaitDelay = processWaitDelay
+}
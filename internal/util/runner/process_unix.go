@@ -0,0 +1,19 @@
+//go:build unix
+
+package runner
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// configureProcess запускает процесс в отдельной группе и при отмене контекста
+// завершает всю группу: 1cv8 порождает дочерние процессы, которые иначе
+// продолжают работу и удерживают блокировки после остановки родителя.
+func configureProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = processWaitDelay
+}
@@ -25,6 +25,10 @@ type Runner struct {
 	OutFileName string
 	ConsoleOut  []byte
 	FileOut     []byte
+	// DumpResultFileName — файл /DumpResult (создаётся для параметра "/DumpResult")
+	DumpResultFileName string
+	// DumpResult — код из файла /DumpResult после RunCommand (-1 если не запрашивался)
+	DumpResult int
 }
 
 // ClearParams очищает все параметры команды.
@@ -51,6 +55,14 @@ func (r *Runner) processParamValue(value string, l *slog.Logger) (fileValue stri
 		}
 		return "/Out " + r.OutFileName, nil, nil
 	}
+	if value == "/DumpResult" {
+		name, err := createTempFile(r.TmpDir, "*.res")
+		if err != nil {
+			return "", nil, err
+		}
+		r.DumpResultFileName = name
+		return "/DumpResult " + name, nil, nil
+	}
 	return value, nil, nil
 }
 
@@ -127,9 +139,12 @@ func (r *Runner) readOutputFile(l *slog.Logger) []byte {
 	return fileOutContent
 }
 
-// RunCommand выполняет команду и возвращает результат.
+// RunCommand выполняет команду через Supervisor и возвращает содержимое /Out.
+// Ошибка — *ProcessError: успех определяется кодом завершения и /DumpResult.
 func (r *Runner) RunCommand(ctx context.Context, l *slog.Logger) ([]byte, error) {
 	var lParams []string
+	r.DumpResult = -1
+	r.FileOut = nil
 
 	if len(r.Params) > 0 && r.Params[0] == "@" {
 		var err error
@@ -150,28 +165,26 @@ func (r *Runner) RunCommand(ctx context.Context, l *slog.Logger) ([]byte, error)
 		return nil, err
 	}
 
-	// #nosec G204 - parameters are validated above
-	cmd := exec.CommandContext(ctx, r.RunString, r.Params...)
-
+	spec := Spec{
+		Bin:            r.RunString,
+		Args:           r.Params,
+		WorkDir:        r.WorkDir,
+		DumpResultFile: r.DumpResultFileName,
+	}
+	if exists(r.OutFileName) {
+		spec.OutFile = r.OutFileName
+	}
 	if len(r.Params) > 0 && r.Params[0] == "@" {
-		cmd.Env = appendEnviron("DISPLAY=:99", "XAUTHORITY=/tmp/.Xauth99")
+		spec.Env = []string{"DISPLAY=:99", "XAUTHORITY=/tmp/.Xauth99"}
 	}
-	cmd.Dir = r.WorkDir
 
-	var err error
-	r.ConsoleOut, err = cmd.Output()
-
-	fileOutContent := r.readOutputFile(l)
-
-	if err != nil {
-		errText := TrimOut(r.ConsoleOut) + "\n" + string(fileOutContent)
-		l.Error("Runner",
-			slog.String("Ошибка при запуске", err.Error()),
-			slog.String("Исполняемый файл", r.RunString),
-			slog.String("WorkDir", r.WorkDir),
-			slog.String("Параметры", fmt.Sprint(r.Params)),
-			slog.String("Ошибка при запуске", errText),
-		)
+	res, err := (&Supervisor{}).Run(ctx, l, spec)
+	r.ConsoleOut = res.Stdout
+	r.FileOut = res.Out
+	r.DumpResult = res.DumpResult
+	if r.DumpResultFileName != "" {
+		removeTempFile(l, r.DumpResultFileName)
+		r.DumpResultFileName = ""
 	}
 	l.Debug("Runner",
 		slog.String("Вывод консоли", TrimOut(r.ConsoleOut)),
@@ -2,6 +2,7 @@ package runner
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"os"
 	"path/filepath"
@@ -240,3 +241,70 @@ func TestPrepareAtParams_WithSlashOutError(t *testing.T) {
 		t.Logf("Got error (checking /Out handling): %v", err)
 	}
 }
+
+func TestRunCommand_DumpResult(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	tmpDir := t.TempDir()
+
+	dumpFile := filepath.Join(tmpDir, "result.res")
+	os.WriteFile(dumpFile, []byte("0"), 0644)
+
+	r := &Runner{
+		RunString:          "/usr/bin/echo",
+		Params:             []string{"hello"},
+		WorkDir:            tmpDir,
+		TmpDir:             tmpDir,
+		DumpResultFileName: dumpFile,
+	}
+
+	_, err := r.RunCommand(context.Background(), logger)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.DumpResult != 0 {
+		t.Errorf("DumpResult = %d, want 0", r.DumpResult)
+	}
+	if r.DumpResultFileName != "" || exists(dumpFile) {
+		t.Error("DumpResult file must be removed after run")
+	}
+}
+
+func TestRunCommand_DumpResultFailure(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	tmpDir := t.TempDir()
+
+	dumpFile := filepath.Join(tmpDir, "result.res")
+	os.WriteFile(dumpFile, []byte("1"), 0644)
+	outFile := filepath.Join(tmpDir, "result.out")
+	os.WriteFile(outFile, []byte("Объект Справочник.Номенклатура захвачен другим пользователем"), 0644)
+
+	r := &Runner{
+		RunString:          "/usr/bin/echo",
+		Params:             []string{"hello"},
+		WorkDir:            tmpDir,
+		TmpDir:             tmpDir,
+		OutFileName:        outFile,
+		DumpResultFileName: dumpFile,
+	}
+
+	_, err := r.RunCommand(context.Background(), logger)
+	if !errors.Is(err, ErrObjectCaptured) {
+		t.Fatalf("err = %v, want ErrObjectCaptured", err)
+	}
+	if !strings.Contains(string(r.FileOut), "захвачен") || strings.TrimSpace(string(r.ConsoleOut)) != "hello" {
+		t.Errorf("FileOut = %q, ConsoleOut = %q", r.FileOut, r.ConsoleOut)
+	}
+}
+
+func TestProcessParamValue_DumpResult(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	r := &Runner{TmpDir: t.TempDir()}
+
+	fv, _, err := r.processParamValue("/DumpResult", logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.DumpResultFileName == "" || fv != "/DumpResult "+r.DumpResultFileName {
+		t.Errorf("unexpected fileValue %q for %q", fv, r.DumpResultFileName)
+	}
+}
@@ -0,0 +1,229 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// processWaitDelay — время ожидания закрытия потоков вывода после остановки процесса.
+const processWaitDelay = 10 * time.Second
+
+// Spec описывает запуск процесса платформы 1С (1cv8, ibcmd, rac).
+type Spec struct {
+	// Bin — путь к исполняемому файлу
+	Bin string
+	// Args — аргументы командной строки (без /Out и /DumpResult)
+	Args []string
+	// WorkDir — рабочий каталог процесса
+	WorkDir string
+	// TmpDir — каталог для файлов /Out и /DumpResult (по умолчанию WorkDir)
+	TmpDir string
+	// Env — дополнительные переменные окружения в формате KEY=VALUE
+	Env []string
+	// Timeout — жёсткий таймаут процесса (0 — таймаут Supervisor)
+	Timeout time.Duration
+	// CaptureOut — добавить /Out <файл> и вернуть его содержимое (1cv8)
+	CaptureOut bool
+	// CaptureDumpResult — добавить /DumpResult <файл> и вернуть код результата (1cv8)
+	CaptureDumpResult bool
+	// OutFile — файл /Out, уже переданный в Args (например, через файл параметров "@"):
+	// читается после завершения процесса, не удаляется
+	OutFile string
+	// DumpResultFile — файл /DumpResult, уже переданный в Args: читается после завершения процесса, не удаляется
+	DumpResultFile string
+}
+
+// Result — результат выполнения процесса.
+type Result struct {
+	// Tool — короткое имя исполняемого файла
+	Tool string
+	// ExitCode — код завершения (-1 если процесс остановлен или не запущен)
+	ExitCode int
+	// Stdout — стандартный вывод процесса
+	Stdout []byte
+	// Stderr — поток ошибок процесса
+	Stderr []byte
+	// Out — содержимое файла /Out
+	Out []byte
+	// DumpResult — код из файла /DumpResult (-1 если не запрашивался или не записан)
+	DumpResult int
+	// Duration — длительность выполнения
+	Duration time.Duration
+}
+
+// Supervisor запускает процессы платформы 1С: изолирует их в группе процессов
+// (остановка по отмене контекста завершает и дочерние процессы), применяет
+// жёсткий таймаут, собирает /Out и /DumpResult и возвращает типизированные
+// ошибки (*ProcessError).
+type Supervisor struct {
+	// Timeout — таймаут по умолчанию для Spec без собственного таймаута (0 — без ограничения)
+	Timeout time.Duration
+}
+
+// Run запускает процесс по spec и ожидает его завершения.
+// Result возвращается и при ошибке процесса (для диагностики вывода).
+func (s *Supervisor) Run(ctx context.Context, l *slog.Logger, spec Spec) (*Result, error) {
+	res := &Result{Tool: toolName(spec.Bin), ExitCode: -1, DumpResult: -1}
+	if spec.Bin == "" {
+		return res, errors.New("executable path is empty")
+	}
+
+	timeout := spec.Timeout
+	if timeout == 0 {
+		timeout = s.Timeout
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	tmpDir := spec.TmpDir
+	if tmpDir == "" {
+		tmpDir = spec.WorkDir
+	}
+	args := append([]string{}, spec.Args...)
+	outFile, dumpFile := spec.OutFile, spec.DumpResultFile
+	if spec.CaptureOut {
+		name, err := createTempFile(tmpDir, "*.out")
+		if err != nil {
+			return res, err
+		}
+		defer removeTempFile(l, name)
+		outFile = name
+		args = append(args, "/Out", outFile)
+	}
+	if spec.CaptureDumpResult {
+		name, err := createTempFile(tmpDir, "*.res")
+		if err != nil {
+			return res, err
+		}
+		defer removeTempFile(l, name)
+		dumpFile = name
+		args = append(args, "/DumpResult", dumpFile)
+	}
+
+	l.Debug("Запуск процесса платформы",
+		slog.String("tool", res.Tool),
+		slog.String("WorkDir", spec.WorkDir),
+		slog.String("Параметры", fmt.Sprint(maskArgs(args))),
+		slog.Duration("timeout", timeout),
+	)
+
+	// #nosec G204 - исполняемый файл и аргументы задаются конфигурацией приложения
+	cmd := exec.CommandContext(ctx, spec.Bin, args...)
+	cmd.Dir = spec.WorkDir
+	if len(spec.Env) > 0 {
+		cmd.Env = appendEnviron(spec.Env...)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	configureProcess(cmd)
+
+	start := time.Now()
+	runErr := cmd.Run()
+	res.Duration = time.Since(start)
+	res.Stdout, res.Stderr = stdout.Bytes(), stderr.Bytes()
+	if cmd.ProcessState != nil {
+		res.ExitCode = cmd.ProcessState.ExitCode()
+	}
+	if outFile != "" {
+		res.Out = readFileQuiet(l, outFile)
+	}
+	if dumpFile != "" {
+		res.DumpResult = parseDumpResult(readFileQuiet(l, dumpFile))
+	}
+
+	err := Interpret(ctx, spec.Bin, runErr, res.Out, append(append([]byte{}, res.Stdout...), res.Stderr...), res.DumpResult)
+	if err != nil {
+		l.Error("Процесс платформы завершился с ошибкой",
+			slog.String("tool", res.Tool),
+			slog.Int("exit_code", res.ExitCode),
+			slog.Int("dump_result", res.DumpResult),
+			slog.Duration("duration", res.Duration),
+			slog.String("error", err.Error()),
+			slog.String("Вывод в файл", TrimOut(res.Out)),
+			slog.String("Вывод консоли", TrimOut(res.Stdout)),
+			slog.String("Поток ошибок", TrimOut(res.Stderr)),
+		)
+		return res, err
+	}
+	l.Debug("Процесс платформы завершён",
+		slog.String("tool", res.Tool),
+		slog.Duration("duration", res.Duration),
+	)
+	return res, nil
+}
+
+// parseDumpResult разбирает содержимое файла /DumpResult (число, обычно 0 или 1).
+func parseDumpResult(b []byte) int {
+	v, err := strconv.Atoi(strings.TrimSpace(string(bytes.TrimPrefix(b, []byte("\uFEFF")))))
+	if err != nil {
+		return -1
+	}
+	return v
+}
+
+// createTempFile создаёт пустой временный файл и возвращает его имя.
+func createTempFile(dir, pattern string) (string, error) {
+	f, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	name := f.Name()
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("failed to close temp file: %w", err)
+	}
+	return name, nil
+}
+
+// removeTempFile удаляет временный файл процесса.
+func removeTempFile(l *slog.Logger, name string) {
+	if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+		l.Warn("Failed to remove temp file", slog.String("file", name), slog.String("error", err.Error()))
+	}
+}
+
+// readFileQuiet читает файл вывода; ошибка чтения логируется.
+func readFileQuiet(l *slog.Logger, name string) []byte {
+	b, err := os.ReadFile(name) //nolint:gosec // файл создан супервизором
+	if err != nil {
+		l.Warn("Не удалось прочитать файл вывода процесса", slog.String("file", name), slog.String("error", err.Error()))
+		return nil
+	}
+	return b
+}
+
+// maskArgs маскирует пароли в аргументах для логирования.
+func maskArgs(args []string) []string {
+	masked := make([]string, len(args))
+	for i, a := range args {
+		switch {
+		case i > 0 && isPasswordFlag(args[i-1]):
+			masked[i] = maskedValue
+		case strings.HasPrefix(a, "--") && strings.Contains(a, "=") && isPasswordFlag(a[:strings.Index(a, "=")]):
+			masked[i] = a[:strings.Index(a, "=")+1] + maskedValue
+		default:
+			masked[i] = maskPasswordInParam(a)
+		}
+	}
+	return masked
+}
+
+// isPasswordFlag сообщает, является ли аргумент ключом пароля 1cv8/ibcmd/rac.
+func isPasswordFlag(flag string) bool {
+	switch flag {
+	case "/P", "/ConfigurationRepositoryP", "--password", "--db-pwd":
+		return true
+	}
+	return strings.HasPrefix(flag, "--") && strings.HasSuffix(flag, "-pwd")
+}
@@ -0,0 +1,121 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func requireShell(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not supported on windows")
+	}
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh is not available")
+	}
+	return sh
+}
+
+func TestSupervisor_Run_Success(t *testing.T) {
+	sh := requireShell(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	s := &Supervisor{}
+
+	res, err := s.Run(context.Background(), logger, Spec{Bin: sh, Args: []string{"-c", "echo hello"}, WorkDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.ExitCode != 0 || res.Tool != "sh" {
+		t.Errorf("res = %+v", res)
+	}
+	if strings.TrimSpace(string(res.Stdout)) != "hello" {
+		t.Errorf("Stdout = %q, want hello", res.Stdout)
+	}
+}
+
+func TestSupervisor_Run_CaptureFiles(t *testing.T) {
+	sh := requireShell(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	tmpDir := t.TempDir()
+	s := &Supervisor{}
+
+	// Скрипт эмулирует 1cv8: пишет сообщение в /Out и код в /DumpResult.
+	script := `while [ $# -gt 0 ]; do
+  case "$1" in
+    /Out) echo "Объект Документ.Заказ захвачен другим пользователем" > "$2"; shift ;;
+    /DumpResult) echo 1 > "$2"; shift ;;
+  esac
+  shift
+done`
+	res, err := s.Run(context.Background(), logger, Spec{
+		Bin:               sh,
+		Args:              []string{"-c", script, "sh"},
+		WorkDir:           tmpDir,
+		CaptureOut:        true,
+		CaptureDumpResult: true,
+	})
+	if !errors.Is(err, ErrObjectCaptured) {
+		t.Fatalf("err = %v, want ErrObjectCaptured", err)
+	}
+	if res.DumpResult != 1 {
+		t.Errorf("DumpResult = %d, want 1", res.DumpResult)
+	}
+	if !strings.Contains(string(res.Out), "захвачен") {
+		t.Errorf("Out = %q", res.Out)
+	}
+
+	entries, _ := os.ReadDir(tmpDir)
+	if len(entries) != 0 {
+		t.Errorf("temp files were not removed: %v", entries)
+	}
+}
+
+func TestSupervisor_Run_TimeoutKillsProcessGroup(t *testing.T) {
+	sh := requireShell(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	marker := filepath.Join(t.TempDir(), "child-finished")
+	s := &Supervisor{Timeout: 200 * time.Millisecond}
+
+	// Дочерний процесс создаёт маркер, только если переживёт остановку родителя.
+	start := time.Now()
+	_, err := s.Run(context.Background(), logger, Spec{
+		Bin:  sh,
+		Args: []string{"-c", "(sleep 1; touch " + marker + ") & sleep 5"},
+	})
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("err = %v, want ErrTimeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("process was not killed on timeout, elapsed %v", elapsed)
+	}
+
+	time.Sleep(1500 * time.Millisecond)
+	if _, statErr := os.Stat(marker); statErr == nil {
+		t.Error("child process survived timeout")
+	}
+}
+
+func TestSupervisor_Run_EmptyBin(t *testing.T) {
+	s := &Supervisor{}
+	if _, err := s.Run(context.Background(), slog.Default(), Spec{}); err == nil {
+		t.Error("expected error for empty executable")
+	}
+}
+
+func TestMaskArgs(t *testing.T) {
+	args := []string{"DESIGNER", "/N", "admin", "/P", "secret", "--db-pwd=dbsecret", "--password", "pwd", "/ConfigurationRepositoryP", "repo"}
+	want := "DESIGNER /N admin /P ***** --db-pwd=***** --password ***** /ConfigurationRepositoryP *****"
+
+	if got := strings.Join(maskArgs(args), " "); got != want {
+		t.Errorf("maskArgs() = %q, want %q", got, want)
+	}
+}